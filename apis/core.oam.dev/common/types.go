@@ -18,6 +18,7 @@ package common
 
 import (
 	runtimev1alpha1 "github.com/crossplane/crossplane-runtime/apis/core/v1alpha1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 
 	"github.com/oam-dev/kubevela/apis/standard.oam.dev/v1alpha1"
@@ -41,6 +42,8 @@ const (
 	StringType  ParameterValueType = "string"
 	NumberType  ParameterValueType = "number"
 	BooleanType ParameterValueType = "boolean"
+	ObjectType  ParameterValueType = "object"
+	ArrayType   ParameterValueType = "array"
 )
 
 // A KubeParameter defines a configurable parameter of a component.
@@ -48,17 +51,39 @@ type KubeParameter struct {
 	// Name of this parameter
 	Name string `json:"name"`
 
-	// +kubebuilder:validation:Enum:=string;number;boolean
-	// ValueType indicates the type of the parameter value, and
-	// only supports basic data types: string, number, boolean.
+	// +kubebuilder:validation:Enum:=string;number;boolean;object;array
+	// ValueType indicates the type of the parameter value, it supports basic
+	// data types: string, number, boolean, and structured types: object, array.
 	ValueType ParameterValueType `json:"type"`
 
 	// FieldPaths specifies an array of fields within this workload that will be
 	// overwritten by the value of this parameter. 	All fields must be of the
 	// same type. Fields are specified as JSON field paths without a leading
-	// dot, for example 'spec.replicas'.
+	// dot, for example 'spec.replicas'. An array index or a wildcard is supported
+	// to set elements of an array, for example 'spec.containers[0].image' or
+	// 'spec.containers[*].imagePullPolicy'.
 	FieldPaths []string `json:"fieldPaths"`
 
+	// Default is the value of this parameter if it's not set in application.
+	// +optional
+	Default *apiextensionsv1.JSON `json:"default,omitempty"`
+
+	// Enum restricts the value of this parameter to one of the values.
+	// +optional
+	Enum []apiextensionsv1.JSON `json:"enum,omitempty"`
+
+	// Pattern is a regular expression that a string parameter must match.
+	// +optional
+	Pattern *string `json:"pattern,omitempty"`
+
+	// Minimum is the lower bound of a number parameter.
+	// +optional
+	Minimum *int64 `json:"minimum,omitempty"`
+
+	// Maximum is the upper bound of a number parameter.
+	// +optional
+	Maximum *int64 `json:"maximum,omitempty"`
+
 	// +kubebuilder:default:=false
 	// Required specifies whether or not a value for this parameter must be
 	// supplied when authoring an Application.
@@ -167,6 +192,8 @@ const (
 	ApplicationRunning ApplicationPhase = "running"
 	// ApplicationHealthChecking means the app finished rendering and applied result to the cluster, but still unhealthy
 	ApplicationHealthChecking ApplicationPhase = "healthChecking"
+	// ApplicationWaitingForWindow means the new revision of the app is held until a deployment window allows it
+	ApplicationWaitingForWindow ApplicationPhase = "waitingForWindow"
 )
 
 // ApplicationComponentStatus record the health status of App component
@@ -213,6 +240,10 @@ type AppStatus struct {
 
 	Phase ApplicationPhase `json:"status,omitempty"`
 
+	// ObservedGeneration is the generation of the application reconciled, the phase is of the observed generation
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
 	// Components record the related Components created by Application Controller
 	Components []runtimev1alpha1.TypedReference `json:"components,omitempty"`
 
@@ -225,6 +256,17 @@ type AppStatus struct {
 	// LatestRevision of the application configuration it generates
 	// +optional
 	LatestRevision *Revision `json:"latestRevision,omitempty"`
+
+	// ReadinessGates record the status of the readiness gates of the application
+	// +optional
+	ReadinessGates []ReadinessGateStatus `json:"readinessGates,omitempty"`
+}
+
+// ReadinessGateStatus records the status of a readiness gate
+type ReadinessGateStatus struct {
+	Name    string `json:"name"`
+	Ready   bool   `json:"ready"`
+	Message string `json:"message,omitempty"`
 }
 
 // DefinitionType describes the type of DefinitionRevision.
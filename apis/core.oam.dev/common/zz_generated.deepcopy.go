@@ -22,6 +22,7 @@ package common
 
 import (
 	"github.com/crossplane/crossplane-runtime/apis/core/v1alpha1"
+	"k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 )
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
@@ -67,6 +68,11 @@ func (in *AppStatus) DeepCopyInto(out *AppStatus) {
 		*out = new(Revision)
 		**out = **in
 	}
+	if in.ReadinessGates != nil {
+		in, out := &in.ReadinessGates, &out.ReadinessGates
+		*out = make([]ReadinessGateStatus, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AppStatus.
@@ -220,6 +226,33 @@ func (in *KubeParameter) DeepCopyInto(out *KubeParameter) {
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.Default != nil {
+		in, out := &in.Default, &out.Default
+		*out = new(v1.JSON)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Enum != nil {
+		in, out := &in.Enum, &out.Enum
+		*out = make([]v1.JSON, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Pattern != nil {
+		in, out := &in.Pattern, &out.Pattern
+		*out = new(string)
+		**out = **in
+	}
+	if in.Minimum != nil {
+		in, out := &in.Minimum, &out.Minimum
+		*out = new(int64)
+		**out = **in
+	}
+	if in.Maximum != nil {
+		in, out := &in.Maximum, &out.Maximum
+		*out = new(int64)
+		**out = **in
+	}
 	if in.Required != nil {
 		in, out := &in.Required, &out.Required
 		*out = new(bool)
@@ -258,6 +291,21 @@ func (in *RawComponent) DeepCopy() *RawComponent {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReadinessGateStatus) DeepCopyInto(out *ReadinessGateStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReadinessGateStatus.
+func (in *ReadinessGateStatus) DeepCopy() *ReadinessGateStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ReadinessGateStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Revision) DeepCopyInto(out *Revision) {
 	*out = *in
@@ -27,11 +27,30 @@ import (
 // EDIT THIS FILE!  THIS IS SCAFFOLDING FOR YOU TO OWN!
 // NOTE: json tags are required.  Any new fields you add must have json tags for the fields to be serialized.
 
+// DeletionPolicy decides what happens to the resources rendered from a component or trait when it's removed from
+// the application or the application is deleted.
+// +kubebuilder:validation:Enum=Delete;Orphan;Retain
+type DeletionPolicy string
+
+const (
+	// DeletionPolicyDelete deletes the resources, it's the default policy
+	DeletionPolicyDelete DeletionPolicy = "Delete"
+	// DeletionPolicyOrphan keeps the resources and releases them from the application, they're no longer tracked
+	DeletionPolicyOrphan DeletionPolicy = "Orphan"
+	// DeletionPolicyRetain keeps the resources until they're deleted manually, they're still tracked while the
+	// application exists
+	DeletionPolicyRetain DeletionPolicy = "Retain"
+)
+
 // ApplicationTrait defines the trait of application
 type ApplicationTrait struct {
 	Type string `json:"type"`
 	// +kubebuilder:pruning:PreserveUnknownFields
 	Properties runtime.RawExtension `json:"properties,omitempty"`
+
+	// DeletionPolicy of the resources rendered from the trait, it's the same as the component if empty
+	// +optional
+	DeletionPolicy DeletionPolicy `json:"deletionPolicy,omitempty"`
 }
 
 // ApplicationComponent describe the component of application
@@ -48,6 +67,10 @@ type ApplicationComponent struct {
 	// scopes in ApplicationComponent defines the component-level scopes
 	// the format is <scope-type:scope-instance-name> pairs, the key represents type of `ScopeDefinition` while the value represent the name of scope instance.
 	Scopes map[string]string `json:"scopes,omitempty"`
+
+	// DeletionPolicy of the resources rendered from the component, it's Delete if empty
+	// +optional
+	DeletionPolicy DeletionPolicy `json:"deletionPolicy,omitempty"`
 }
 
 // AppPolicy defines a global policy for all components in the app.
@@ -69,6 +92,42 @@ type WorkflowStep struct {
 	Properties runtime.RawExtension `json:"properties,omitempty"`
 }
 
+// AppHealth defines how the health of an application is evaluated.
+type AppHealth struct {
+	// Policy is a CUE template which evaluates the health of the application by `isHealth` and explains it by an
+	// optional `message`. Status of components and traits are available as `context.components`, e.g.
+	// `context.components["cache"].healthy` and `context.components["web"].traits["ingress"].message`.
+	// The application is healthy only if all components and traits are healthy if the policy is empty.
+	// +optional
+	Policy string `json:"policy,omitempty"`
+
+	// ReadinessGates are external conditions the application waits for before it's running, all of them must be ready.
+	// +optional
+	ReadinessGates []ReadinessGate `json:"readinessGates,omitempty"`
+}
+
+// ReadinessGate checks the readiness of a K8s resource, which is not necessarily managed by the application.
+type ReadinessGate struct {
+	// Name of the readiness gate
+	Name string `json:"name"`
+
+	// APIVersion of the resource
+	APIVersion string `json:"apiVersion"`
+	// Kind of the resource
+	Kind string `json:"kind"`
+	// Name of the resource, the resource must be in the namespace of the application
+	ResourceName string `json:"resourceName"`
+
+	// ConditionType is the type of the condition in `status.conditions` of the resource, the gate is ready if
+	// its status is True, e.g. `Complete` for a Job
+	// +optional
+	ConditionType string `json:"conditionType,omitempty"`
+
+	// HealthPolicy is a CUE template which evaluates the readiness by `isHealth` with the resource as `context.output`
+	// +optional
+	HealthPolicy string `json:"healthPolicy,omitempty"`
+}
+
 // ApplicationSpec is the spec of Application
 type ApplicationSpec struct {
 	Components []ApplicationComponent `json:"components"`
@@ -91,6 +150,11 @@ type ApplicationSpec struct {
 	// The controller simply replace the old resources with the new one if there is no rollout plan involved
 	// +optional
 	RolloutPlan *v1alpha1.RolloutPlan `json:"rolloutPlan,omitempty"`
+
+	// Health defines the application-level health policy and readiness gates, the application is running only if
+	// it's healthy and all readiness gates are ready
+	// +optional
+	Health *AppHealth `json:"health,omitempty"`
 }
 
 // +kubebuilder:object:root=true
@@ -50,6 +50,57 @@ type ApplicationRevisionSpec struct {
 	// +kubebuilder:validation:EmbeddedResource
 	// +kubebuilder:pruning:PreserveUnknownFields
 	ApplicationConfiguration runtime.RawExtension `json:"applicationConfiguration"`
+
+	// Change records who made the change of this revision, when and why, and what is changed from the previous revision
+	// +optional
+	Change *RevisionChange `json:"change,omitempty"`
+}
+
+// ChangeAction is the action of a change to a component
+type ChangeAction string
+
+const (
+	// ComponentAdded means the component is added to the application
+	ComponentAdded ChangeAction = "Added"
+	// ComponentRemoved means the component is removed from the application
+	ComponentRemoved ChangeAction = "Removed"
+	// ComponentModified means the spec of the component is modified
+	ComponentModified ChangeAction = "Modified"
+)
+
+// RevisionChange is the audit record of a revision
+type RevisionChange struct {
+	// User is the user who changed the spec of application, it's recorded by the admission webhook of Application.
+	// It's empty if only definitions are changed.
+	User string `json:"user,omitempty"`
+
+	// Time is when the change was made
+	Time metav1.Time `json:"time,omitempty"`
+
+	// Message describes why the change was made, it's set by the annotation app.oam.dev/change-message of application
+	Message string `json:"message,omitempty"`
+
+	// PreviousRevision is the name of the revision which this revision is compared with
+	PreviousRevision string `json:"previousRevision,omitempty"`
+
+	// Components summarizes the changes to components of the application
+	Components []ComponentChange `json:"components,omitempty"`
+
+	// Definitions lists the definitions whose spec is changed, e.g. traitDefinition/scaler
+	Definitions []string `json:"definitions,omitempty"`
+}
+
+// ComponentChange summarizes the change to a component
+type ComponentChange struct {
+	// Name is the name of the component
+	Name string `json:"name"`
+
+	// Action is how the component is changed
+	// +kubebuilder:validation:Enum=Added;Removed;Modified
+	Action ChangeAction `json:"action"`
+
+	// Fields are the paths of changed fields of a modified component, e.g. properties.image or traits.scaler
+	Fields []string `json:"fields,omitempty"`
 }
 
 // +kubebuilder:object:root=true
@@ -57,6 +108,8 @@ type ApplicationRevisionSpec struct {
 // ApplicationRevision is the Schema for the ApplicationRevision API
 // +kubebuilder:storageversion
 // +kubebuilder:resource:categories={oam},shortName=apprev
+// +kubebuilder:printcolumn:name="USER",type=string,JSONPath=".spec.change.user"
+// +kubebuilder:printcolumn:name="MESSAGE",type=string,JSONPath=".spec.change.message",priority=1
 // +kubebuilder:printcolumn:name="AGE",type=date,JSONPath=".metadata.creationTimestamp"
 type ApplicationRevision struct {
 	metav1.TypeMeta   `json:",inline"`
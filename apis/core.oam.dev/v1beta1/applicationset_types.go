@@ -0,0 +1,243 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	runtimev1alpha1 "github.com/crossplane/crossplane-runtime/apis/core/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/common"
+)
+
+// ApplicationSetStrategyType decides how the applications of an ApplicationSet are updated
+// +kubebuilder:validation:Enum=AllAtOnce;Progressive
+type ApplicationSetStrategyType string
+
+const (
+	// AllAtOnceStrategy updates all the applications at once
+	AllAtOnceStrategy ApplicationSetStrategyType = "AllAtOnce"
+	// ProgressiveStrategy updates the applications in the order of generated entries, a limited number at a time.
+	// An application is updating until it's running with the updated spec.
+	ProgressiveStrategy ApplicationSetStrategyType = "Progressive"
+)
+
+// ApplicationSetPhase is the phase of an ApplicationSet
+type ApplicationSetPhase string
+
+const (
+	// ApplicationSetProgressing means some applications are not updated or not running yet
+	ApplicationSetProgressing ApplicationSetPhase = "progressing"
+	// ApplicationSetRunning means all the applications are updated and running
+	ApplicationSetRunning ApplicationSetPhase = "running"
+	// ApplicationSetFailed means the applications cannot be generated or applied
+	ApplicationSetFailed ApplicationSetPhase = "failed"
+)
+
+// ApplicationSetParameter is a parameter of the application template, the value of an entry overwrites the fields of
+// the template
+type ApplicationSetParameter struct {
+	// Name of the parameter, entries specify the values of parameters by name
+	Name string `json:"name"`
+
+	// FieldPaths specifies the fields of the application template overwritten by the value, e.g.
+	// `spec.components[0].properties.image` or `metadata.labels.region`
+	FieldPaths []string `json:"fieldPaths"`
+
+	// Required specifies whether every entry must have a value of the parameter
+	// +optional
+	Required bool `json:"required,omitempty"`
+
+	// Default value of the parameter for entries without a value
+	// +optional
+	Default *intstr.IntOrString `json:"default,omitempty"`
+}
+
+// ApplicationSetParameterValue is the value of a parameter
+type ApplicationSetParameterValue struct {
+	// Name of the parameter
+	Name string `json:"name"`
+
+	// Value of the parameter
+	Value intstr.IntOrString `json:"value"`
+}
+
+// ApplicationSetElement is an entry of the list generator
+type ApplicationSetElement struct {
+	// Name of the entry, it's unique in the ApplicationSet
+	Name string `json:"name"`
+
+	// Values of the parameters
+	// +optional
+	Values []ApplicationSetParameterValue `json:"values,omitempty"`
+}
+
+// ApplicationSetClusterGenerator generates an entry for each Cluster selected
+type ApplicationSetClusterGenerator struct {
+	// Selector selects the Clusters in the namespace of the ApplicationSet by labels, all of them are selected
+	// if it's empty
+	// +optional
+	Selector *metav1.LabelSelector `json:"selector,omitempty"`
+}
+
+// ApplicationSetNamespaceGenerator generates an entry for each namespace selected, either names or selector should
+// be set. Only the namespace of the ApplicationSet, and the namespaces labeled with
+// app.oam.dev/application-set-allowed-namespace=<namespace of the ApplicationSet> could be selected.
+type ApplicationSetNamespaceGenerator struct {
+	// Names of the namespaces
+	// +optional
+	Names []string `json:"names,omitempty"`
+
+	// Selector selects the namespaces by labels, it's used if names is empty. All the namespaces allowing the
+	// ApplicationSet are selected if the selector is empty
+	// +optional
+	Selector *metav1.LabelSelector `json:"selector,omitempty"`
+}
+
+// ApplicationSetGenerator generates the entries of an ApplicationSet, one and only one of its fields should be set.
+// Every entry has the value of parameter `name` which is the name of the entry. The entries generated from Clusters
+// and namespaces are named after them, and have the values of their labels too.
+type ApplicationSetGenerator struct {
+	// List generates an entry for each element
+	// +optional
+	List []ApplicationSetElement `json:"list,omitempty"`
+
+	// Clusters generates an entry for each Cluster selected
+	// +optional
+	Clusters *ApplicationSetClusterGenerator `json:"clusters,omitempty"`
+
+	// Namespaces generates an entry for each namespace selected, its application is created in the namespace
+	// +optional
+	Namespaces *ApplicationSetNamespaceGenerator `json:"namespaces,omitempty"`
+}
+
+// ApplicationSetTemplateMeta is the metadata of the application template
+type ApplicationSetTemplateMeta struct {
+	// +optional
+	Labels map[string]string `json:"labels,omitempty"`
+
+	// +optional
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+// ApplicationSetTemplate is the template of the applications generated
+type ApplicationSetTemplate struct {
+	// +optional
+	Metadata ApplicationSetTemplateMeta `json:"metadata,omitempty"`
+
+	Spec ApplicationSpec `json:"spec"`
+}
+
+// ApplicationSetStrategy decides how the applications are updated when the ApplicationSet changes
+type ApplicationSetStrategy struct {
+	// +kubebuilder:default:=AllAtOnce
+	// Type of the strategy
+	// +optional
+	Type ApplicationSetStrategyType `json:"type,omitempty"`
+
+	// MaxUpdating is the max number of applications updating at the same time in Progressive strategy, it's 1
+	// if it's empty
+	// +optional
+	MaxUpdating *int32 `json:"maxUpdating,omitempty"`
+}
+
+// ApplicationSetSpec defines the applications generated from a template
+type ApplicationSetSpec struct {
+	// Parameters of the application template
+	// +optional
+	Parameters []ApplicationSetParameter `json:"parameters,omitempty"`
+
+	// Generator generates the entries, an application is rendered from the template for each entry
+	Generator ApplicationSetGenerator `json:"generator"`
+
+	// Template of the applications, the application of an entry is named `<ApplicationSet name>-<entry name>`
+	Template ApplicationSetTemplate `json:"template"`
+
+	// Strategy to update the applications
+	// +optional
+	Strategy ApplicationSetStrategy `json:"strategy,omitempty"`
+}
+
+// ApplicationSetApplicationStatus is the status of an application generated
+type ApplicationSetApplicationStatus struct {
+	// Entry is the name of the entry the application is rendered for
+	Entry string `json:"entry"`
+
+	Name string `json:"name"`
+
+	Namespace string `json:"namespace"`
+
+	// Updated means the application has the spec rendered from the latest template
+	Updated bool `json:"updated"`
+
+	// Phase of the application
+	// +optional
+	Phase common.ApplicationPhase `json:"phase,omitempty"`
+}
+
+// ApplicationSetStatus is the aggregated status of the applications generated
+type ApplicationSetStatus struct {
+	runtimev1alpha1.ConditionedStatus `json:",inline"`
+
+	// ObservedGeneration is the generation of the ApplicationSet reconciled
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// Phase of the ApplicationSet
+	// +optional
+	Phase ApplicationSetPhase `json:"phase,omitempty"`
+
+	// Total is the number of applications generated
+	Total int `json:"total"`
+
+	// Updated is the number of applications updated
+	Updated int `json:"updated"`
+
+	// Running is the number of applications updated and running
+	Running int `json:"running"`
+
+	// Applications records the status of the applications generated
+	// +optional
+	Applications []ApplicationSetApplicationStatus `json:"applications,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// ApplicationSet renders and owns an application for each entry generated
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:categories={oam},shortName=appset
+// +kubebuilder:printcolumn:name="TOTAL",type=integer,JSONPath=`.status.total`
+// +kubebuilder:printcolumn:name="UPDATED",type=integer,JSONPath=`.status.updated`
+// +kubebuilder:printcolumn:name="RUNNING",type=integer,JSONPath=`.status.running`
+// +kubebuilder:printcolumn:name="PHASE",type=string,JSONPath=`.status.phase`
+// +kubebuilder:printcolumn:name="AGE",type=date,JSONPath=".metadata.creationTimestamp"
+type ApplicationSet struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   ApplicationSetSpec   `json:"spec,omitempty"`
+	Status ApplicationSetStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// ApplicationSetList contains a list of ApplicationSet
+type ApplicationSetList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ApplicationSet `json:"items"`
+}
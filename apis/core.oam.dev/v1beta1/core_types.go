@@ -244,7 +244,7 @@ type ScopeDefinitionList struct {
 // +kubebuilder:object:root=true
 // +kubebuilder:subresource:status
 
-// An ResourceTracker represents a tracker for track cross namespace resources
+// An ResourceTracker represents a tracker for track resources rendered by an application
 // +kubebuilder:resource:scope=Cluster,categories={oam},shortName=tracker
 type ResourceTracker struct {
 	metav1.TypeMeta   `json:",inline"`
@@ -255,7 +255,24 @@ type ResourceTracker struct {
 
 // ResourceTrackerStatus define the status of resourceTracker
 type ResourceTrackerStatus struct {
-	TrackedResources []TypedReference `json:"trackedResources,omitempty"`
+	TrackedResources []TrackedResource `json:"trackedResources,omitempty"`
+}
+
+// TrackedResource is a resource rendered by an application and tracked by its ResourceTracker
+type TrackedResource struct {
+	TypedReference `json:",inline"`
+
+	// Component the resource is rendered from
+	// +optional
+	Component string `json:"component,omitempty"`
+
+	// DeletionPolicy of the resource, it's Delete if empty
+	// +optional
+	DeletionPolicy DeletionPolicy `json:"deletionPolicy,omitempty"`
+
+	// Retained indicates the resource is removed from the application but kept by the Retain deletion policy
+	// +optional
+	Retained bool `json:"retained,omitempty"`
 }
 
 // A TypedReference refers to an object by Name, Kind, and APIVersion. It is
@@ -270,7 +287,7 @@ type TypedReference struct {
 	// Name of the referenced object.
 	Name string `json:"name"`
 
-	// Namespace of the objects outside the application namespace.
+	// Namespace of the referenced object, it's empty for cluster-scoped objects.
 	// +optional
 	Namespace string `json:"namespace,omitempty"`
 
@@ -0,0 +1,91 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// DeploymentWindowType decides whether changes are allowed or blocked inside a window
+type DeploymentWindowType string
+
+const (
+	// AllowWindow allows changes inside it, changes are held outside of all the allow windows
+	AllowWindow DeploymentWindowType = "Allow"
+	// BlockWindow holds changes inside it, it takes precedence over allow windows
+	BlockWindow DeploymentWindowType = "Block"
+)
+
+// TimeWindow is a recurring period of time
+type TimeWindow struct {
+	// Name of the window, e.g. weekday-nights
+	// +optional
+	Name string `json:"name,omitempty"`
+
+	// +kubebuilder:validation:Enum:=Allow;Block
+	// +kubebuilder:default:=Allow
+	// Type of the window
+	Type DeploymentWindowType `json:"type,omitempty"`
+
+	// Schedule is the cron expression of when the window opens, e.g. `0 22 * * 1-5` opens at 22:00 on weekdays
+	Schedule string `json:"schedule"`
+
+	// Duration is how long the window stays open, e.g. `4h`
+	Duration metav1.Duration `json:"duration"`
+
+	// TimeZone of the schedule in the IANA time zone database, e.g. `Europe/Berlin`, it's UTC if empty
+	// +optional
+	TimeZone string `json:"timeZone,omitempty"`
+}
+
+// DeploymentWindowSpec defines when the applications and rollouts selected may change
+type DeploymentWindowSpec struct {
+	// Applications selects applications in the namespace of deployment window by name, their new revisions and
+	// the batches of their rollout plans are held outside of the windows
+	// +optional
+	Applications []string `json:"applications,omitempty"`
+
+	// AppRollouts selects AppRollouts in the namespace of deployment window by name, their batches are held
+	// outside of the windows.
+	// All applications and AppRollouts in the namespace are selected if both applications and appRollouts are empty.
+	// +optional
+	AppRollouts []string `json:"appRollouts,omitempty"`
+
+	// Windows when changes are allowed or blocked
+	Windows []TimeWindow `json:"windows"`
+}
+
+// +kubebuilder:object:root=true
+
+// DeploymentWindow holds new revisions of applications and batches of rollouts outside of the allowed windows
+// +kubebuilder:resource:categories={oam}
+// +kubebuilder:printcolumn:name="AGE",type=date,JSONPath=".metadata.creationTimestamp"
+type DeploymentWindow struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec DeploymentWindowSpec `json:"spec,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// DeploymentWindowList contains a list of DeploymentWindow
+type DeploymentWindowList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []DeploymentWindow `json:"items"`
+}
@@ -0,0 +1,95 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// EnvironmentSpec defines the desired state of Environment
+type EnvironmentSpec struct {
+	// Namespace is the K8s namespace that applications of this environment are deployed to
+	Namespace string `json:"namespace"`
+
+	// Email is used for production TLS Certificate notification
+	Email string `json:"email,omitempty"`
+
+	// Domain is the base domain of applications in this environment
+	Domain string `json:"domain,omitempty"`
+
+	// Clusters are the names of the default clusters that applications of this environment are deployed to
+	Clusters []string `json:"clusters,omitempty"`
+
+	// Patches are applied to the properties of components and traits
+	// when rendering applications of this environment
+	Patches []EnvironmentPatch `json:"patches,omitempty"`
+}
+
+// EnvironmentPatch patches the properties of the matched components and their traits.
+// A patch without any selector matches all components.
+type EnvironmentPatch struct {
+	// Components selects components by name
+	Components []string `json:"components,omitempty"`
+
+	// Type selects components by component type
+	Type string `json:"type,omitempty"`
+
+	// Properties are merged into the properties of the matched components
+	// +kubebuilder:pruning:PreserveUnknownFields
+	Properties runtime.RawExtension `json:"properties,omitempty"`
+
+	// Traits patch the properties of the traits attached to the matched components
+	Traits []EnvironmentTraitPatch `json:"traits,omitempty"`
+}
+
+// EnvironmentTraitPatch patches the properties of the traits of the given type
+type EnvironmentTraitPatch struct {
+	Type string `json:"type"`
+
+	// Properties are merged into the properties of the matched traits
+	// +kubebuilder:pruning:PreserveUnknownFields
+	Properties runtime.RawExtension `json:"properties,omitempty"`
+}
+
+// EnvironmentStatus defines the observed state of Environment
+type EnvironmentStatus struct {
+}
+
+// +kubebuilder:object:root=true
+
+// Environment is the Schema for the environments API
+// +kubebuilder:resource:scope=Cluster,categories={oam},shortName=env
+// +kubebuilder:printcolumn:name="NAMESPACE",type=string,JSONPath=`.spec.namespace`
+// +kubebuilder:printcolumn:name="DOMAIN",type=string,JSONPath=`.spec.domain`
+// +kubebuilder:printcolumn:name="AGE",type=date,JSONPath=".metadata.creationTimestamp"
+type Environment struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   EnvironmentSpec   `json:"spec,omitempty"`
+	Status EnvironmentStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// EnvironmentList contains a list of Environment
+type EnvironmentList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []Environment `json:"items"`
+}
@@ -0,0 +1,97 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/common"
+)
+
+// NotificationReceiverType is the type of endpoint that notifications are delivered to
+type NotificationReceiverType string
+
+const (
+	// WebhookReceiver receives the event of application in JSON
+	WebhookReceiver NotificationReceiverType = "webhook"
+	// SlackReceiver is a Slack compatible incoming webhook
+	SlackReceiver NotificationReceiverType = "slack"
+	// DingTalkReceiver is a DingTalk compatible robot webhook
+	DingTalkReceiver NotificationReceiverType = "dingtalk"
+)
+
+// NotificationSpec defines the events of applications subscribed and where they are delivered to
+type NotificationSpec struct {
+	// Applications selects applications in the namespace of notification by name,
+	// all applications in the namespace are selected if it's empty
+	Applications []string `json:"applications,omitempty"`
+
+	// Phases are the phases of application whose transitions are notified, e.g. running.
+	// Transitions to all phases are notified if both phases and failures are empty.
+	Phases []common.ApplicationPhase `json:"phases,omitempty"`
+
+	// Failures are the reasons of failure events of application notified, e.g. FailedApply.
+	// All failures are notified if both phases and failures are empty.
+	Failures []string `json:"failures,omitempty"`
+
+	// Template is the Go template of the message, the event of application is the data of template.
+	// A default message is sent if it's empty.
+	Template string `json:"template,omitempty"`
+
+	// Receivers are the endpoints that the notifications are delivered to
+	Receivers []NotificationReceiver `json:"receivers"`
+}
+
+// NotificationReceiver is an endpoint that the notifications are delivered to
+type NotificationReceiver struct {
+	// Name of the receiver, it's unique in a notification
+	Name string `json:"name"`
+
+	// +kubebuilder:validation:Enum:=webhook;slack;dingtalk
+	// +kubebuilder:default:=webhook
+	// Type of the receiver decides the format of the payload
+	Type NotificationReceiverType `json:"type,omitempty"`
+
+	// URL of the endpoint
+	URL string `json:"url,omitempty"`
+
+	// URLSecretRef selects a key of a secret in the namespace of notification whose value is the URL,
+	// it's used if URL is empty as the URL of chat robot usually contains a token
+	URLSecretRef *corev1.SecretKeySelector `json:"urlSecretRef,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// Notification subscribes phase transitions and failures of applications
+// +kubebuilder:resource:categories={oam}
+// +kubebuilder:printcolumn:name="AGE",type=date,JSONPath=".metadata.creationTimestamp"
+type Notification struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec NotificationSpec `json:"spec,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// NotificationList contains a list of Notification
+type NotificationList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []Notification `json:"items"`
+}
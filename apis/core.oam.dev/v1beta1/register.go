@@ -117,6 +117,14 @@ var (
 	AppDeploymentKindVersionKind = SchemeGroupVersion.WithKind(AppDeploymentKind)
 )
 
+// ApplicationSet type metadata.
+var (
+	ApplicationSetKind            = reflect.TypeOf(ApplicationSet{}).Name()
+	ApplicationSetGroupKind       = schema.GroupKind{Group: Group, Kind: ApplicationSetKind}.String()
+	ApplicationSetKindAPIVersion  = ApplicationSetKind + "." + SchemeGroupVersion.String()
+	ApplicationSetKindVersionKind = SchemeGroupVersion.WithKind(ApplicationSetKind)
+)
+
 // Cluster type metadata.
 var (
 	ClusterKind            = reflect.TypeOf(Cluster{}).Name()
@@ -125,6 +133,30 @@ var (
 	ClusterKindVersionKind = SchemeGroupVersion.WithKind(ClusterKind)
 )
 
+// Environment type metadata.
+var (
+	EnvironmentKind            = reflect.TypeOf(Environment{}).Name()
+	EnvironmentGroupKind       = schema.GroupKind{Group: Group, Kind: EnvironmentKind}.String()
+	EnvironmentKindAPIVersion  = EnvironmentKind + "." + SchemeGroupVersion.String()
+	EnvironmentKindVersionKind = SchemeGroupVersion.WithKind(EnvironmentKind)
+)
+
+// Notification type metadata.
+var (
+	NotificationKind            = reflect.TypeOf(Notification{}).Name()
+	NotificationGroupKind       = schema.GroupKind{Group: Group, Kind: NotificationKind}.String()
+	NotificationKindAPIVersion  = NotificationKind + "." + SchemeGroupVersion.String()
+	NotificationKindVersionKind = SchemeGroupVersion.WithKind(NotificationKind)
+)
+
+// DeploymentWindow type metadata.
+var (
+	DeploymentWindowKind            = reflect.TypeOf(DeploymentWindow{}).Name()
+	DeploymentWindowGroupKind       = schema.GroupKind{Group: Group, Kind: DeploymentWindowKind}.String()
+	DeploymentWindowKindAPIVersion  = DeploymentWindowKind + "." + SchemeGroupVersion.String()
+	DeploymentWindowKindVersionKind = SchemeGroupVersion.WithKind(DeploymentWindowKind)
+)
+
 func init() {
 	SchemeBuilder.Register(&ComponentDefinition{}, &ComponentDefinitionList{})
 	SchemeBuilder.Register(&WorkloadDefinition{}, &WorkloadDefinitionList{})
@@ -137,4 +169,8 @@ func init() {
 	SchemeBuilder.Register(&AppDeployment{}, &AppDeploymentList{})
 	SchemeBuilder.Register(&Cluster{}, &ClusterList{})
 	SchemeBuilder.Register(&ResourceTracker{}, &ResourceTrackerList{})
+	SchemeBuilder.Register(&Environment{}, &EnvironmentList{})
+	SchemeBuilder.Register(&Notification{}, &NotificationList{})
+	SchemeBuilder.Register(&ApplicationSet{}, &ApplicationSetList{})
+	SchemeBuilder.Register(&DeploymentWindow{}, &DeploymentWindowList{})
 }
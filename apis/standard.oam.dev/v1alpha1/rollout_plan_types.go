@@ -18,6 +18,7 @@ package v1alpha1
 
 import (
 	runtimev1alpha1 "github.com/crossplane/crossplane-runtime/apis/core/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/intstr"
 )
 
@@ -175,6 +176,11 @@ type RolloutBatch struct {
 	// before moving to the next batch
 	// +optional
 	CanaryMetric []CanaryMetric `json:"canaryMetric,omitempty"`
+
+	// RequireApproval holds the batch until it's approved, a batch is approved once the batchPartition covers it.
+	// Default is false
+	// +optional
+	RequireApproval bool `json:"requireApproval,omitempty"`
 }
 
 // RolloutWebhook holds the reference to external checks used for canary analysis
@@ -280,4 +286,30 @@ type RolloutStatus struct {
 
 	// UpgradedReadyReplicas is the number of Pods upgraded by the rollout controller that have a Ready Condition.
 	UpgradedReadyReplicas int32 `json:"upgradedReadyReplicas"`
+
+	// BatchApprovals records who approved the batches which require approval
+	// +optional
+	BatchApprovals []BatchApproval `json:"batchApprovals,omitempty"`
+}
+
+// BatchApproval records the approval of a rollout batch
+type BatchApproval struct {
+	// Batch is the index of the approved batch, it starts from 0
+	Batch int32 `json:"batch"`
+
+	// Approver is the user who approved the batch, it's empty if the approver is unknown
+	// +optional
+	Approver string `json:"approver,omitempty"`
+
+	// Time is when the batch was approved
+	// +optional
+	Time metav1.Time `json:"time,omitempty"`
+}
+
+// WaitingForApproval returns true if the batch requires approval and the batch partition doesn't cover it yet
+func (r *RolloutPlan) WaitingForApproval(batch int32) bool {
+	if batch < 0 || int(batch) >= len(r.RolloutBatches) || !r.RolloutBatches[batch].RequireApproval {
+		return false
+	}
+	return r.BatchPartition == nil || *r.BatchPartition < batch
 }
@@ -102,6 +102,8 @@ const (
 	BatchInitializing runtimev1alpha1.ConditionType = "BatchInitializing"
 	// BatchPaused
 	BatchPaused runtimev1alpha1.ConditionType = "BatchPaused"
+	// BatchWaitingForWindow means the current batch is held until a deployment window allows it to start
+	BatchWaitingForWindow runtimev1alpha1.ConditionType = "BatchWaitingForWindow"
 	// BatchVerifying
 	BatchVerifying runtimev1alpha1.ConditionType = "BatchVerifying"
 	// BatchRolloutFailed
@@ -216,6 +218,7 @@ func (r *RolloutStatus) ResetStatus() {
 	r.CurrentBatch = 0
 	r.UpgradedReplicas = 0
 	r.UpgradedReadyReplicas = 0
+	r.BatchApprovals = nil
 }
 
 // SetRolloutCondition sets the supplied condition, replacing any existing condition
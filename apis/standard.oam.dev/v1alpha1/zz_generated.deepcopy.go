@@ -26,6 +26,22 @@ import (
 	"k8s.io/apimachinery/pkg/util/intstr"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BatchApproval) DeepCopyInto(out *BatchApproval) {
+	*out = *in
+	in.Time.DeepCopyInto(&out.Time)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BatchApproval.
+func (in *BatchApproval) DeepCopy() *BatchApproval {
+	if in == nil {
+		return nil
+	}
+	out := new(BatchApproval)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *CanaryMetric) DeepCopyInto(out *CanaryMetric) {
 	*out = *in
@@ -277,6 +293,13 @@ func (in *RolloutPlan) DeepCopy() *RolloutPlan {
 func (in *RolloutStatus) DeepCopyInto(out *RolloutStatus) {
 	*out = *in
 	in.ConditionedStatus.DeepCopyInto(&out.ConditionedStatus)
+	if in.BatchApprovals != nil {
+		in, out := &in.BatchApprovals, &out.BatchApprovals
+		*out = make([]BatchApproval, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RolloutStatus.
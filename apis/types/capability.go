@@ -49,6 +49,11 @@ type Capability struct {
 	Description    string             `json:"description,omitempty"`
 	Category       CapabilityCategory `json:"category,omitempty"`
 
+	// Version is the semantic version of the capability package
+	Version string `json:"version,omitempty"`
+	// Dependencies must be installed before the capability
+	Dependencies []Dependency `json:"dependencies,omitempty"`
+
 	// trait only
 	AppliesTo []string `json:"appliesTo,omitempty"`
 
@@ -80,6 +85,29 @@ type Installation struct {
 	// TODO(wonderflow) add raw yaml file support for install capability
 }
 
+// DependencyType defines the type of a capability dependency
+type DependencyType string
+
+const (
+	// DependencyDefinition is another capability from the same cap center
+	DependencyDefinition DependencyType = "definition"
+	// DependencyCRD is a CRD which must exist in the cluster
+	DependencyCRD DependencyType = "crd"
+	// DependencyHelm is a Helm chart which will be installed along with the capability
+	DependencyHelm DependencyType = "helm"
+)
+
+// Dependency defines something a capability relies on
+type Dependency struct {
+	Type DependencyType `json:"type"`
+	// Name is the name of the capability or the CRD
+	Name string `json:"name,omitempty"`
+	// Version is a semantic version constraint of the capability, e.g. ">=1.0.0 <2.0.0"
+	Version string `json:"version,omitempty"`
+	// Helm is the chart to install for a helm dependency
+	Helm *Chart `json:"helm,omitempty"`
+}
+
 // CapType defines the type of capability
 type CapType string
 
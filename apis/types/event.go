@@ -24,6 +24,8 @@ const (
 	ReasonHealthCheck = "HealthChecked"
 	ReasonDeployed    = "Deployed"
 	ReasonRollout     = "Rollout"
+	// ReasonDeploymentWindowOverridden means a change is deployed outside of deployment windows by the override
+	ReasonDeploymentWindowOverridden = "DeploymentWindowOverridden"
 
 	ReasonFailedParse       = "FailedParse"
 	ReasonFailedRender      = "FailedRender"
@@ -31,6 +33,8 @@ const (
 	ReasonFailedHealthCheck = "FailedHealthCheck"
 	ReasonFailedGC          = "FailedGC"
 	ReasonFailedRollout     = "FailedRollout"
+	// ReasonWaitingForWindow means a change is held until a deployment window allows it
+	ReasonWaitingForWindow = "WaitingForWindow"
 )
 
 // event message for Application
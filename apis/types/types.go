@@ -34,6 +34,16 @@ const (
 const (
 	// AnnDescription is the annotation which describe what is the capability used for in a WorkloadDefinition/TraitDefinition Object
 	AnnDescription = "definition.oam.dev/description"
+	// AnnHelmRenderMode is the annotation which specifies how a Helm module ComponentDefinition/WorkloadDefinition is rendered,
+	// it could be "flux" (default) or "inprocess"
+	AnnHelmRenderMode = "definition.oam.dev/helm-render-mode"
+	// AnnVersion is the annotation which records the semantic version of a capability package
+	AnnVersion = "definition.oam.dev/version"
+	// AnnDependencies is the annotation which lists the dependencies of a capability package in YAML,
+	// every item is a Dependency
+	AnnDependencies = "definition.oam.dev/dependencies"
+	// AnnSourceCenter is the annotation which records the cap center a definition is installed from
+	AnnSourceCenter = "definition.oam.dev/source-center"
 )
 
 const (
@@ -49,6 +59,8 @@ type EnvMeta struct {
 	Namespace string `json:"namespace"`
 	Email     string `json:"email,omitempty"`
 	Domain    string `json:"domain,omitempty"`
+	// Clusters are the default clusters that apps of this env are deployed to
+	Clusters []string `json:"clusters,omitempty"`
 
 	Current string `json:"current,omitempty"`
 }
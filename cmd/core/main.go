@@ -34,11 +34,13 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
 
+	"github.com/oam-dev/kubevela/pkg/appfile/helm"
 	standardcontroller "github.com/oam-dev/kubevela/pkg/controller"
 	oamcontroller "github.com/oam-dev/kubevela/pkg/controller/core.oam.dev"
 	oamv1alpha2 "github.com/oam-dev/kubevela/pkg/controller/core.oam.dev/v1alpha2"
 	"github.com/oam-dev/kubevela/pkg/controller/utils"
 	"github.com/oam-dev/kubevela/pkg/dsl/definition"
+	"github.com/oam-dev/kubevela/pkg/notification"
 	"github.com/oam-dev/kubevela/pkg/oam"
 	"github.com/oam-dev/kubevela/pkg/oam/discoverymapper"
 	"github.com/oam-dev/kubevela/pkg/utils/common"
@@ -91,6 +93,21 @@ func main() {
 		"application-revision-limit is the maximum number of application useless revisions that will be maintained, if the useless revisions exceed this number, older ones will be GCed first.The default value is 10.")
 	flag.IntVar(&controllerArgs.DefRevisionLimit, "definition-revision-limit", 20,
 		"definition-revision-limit is the maximum number of component/trait definition useless revisions that will be maintained, if the useless revisions exceed this number, older ones will be GCed first.The default value is 20.")
+	flag.Float64Var(&controllerArgs.DefinitionRerenderQPS, "definition-rerender-qps", 5,
+		"definition-rerender-qps is the maximum rate to re-render applications when a referenced component/trait definition changes, a non-positive value means no limit.")
+	flag.IntVar(&controllerArgs.DefinitionRerenderBurst, "definition-rerender-burst", 10,
+		"definition-rerender-burst is the maximum number of applications re-rendered at once when a referenced component/trait definition changes.")
+	flag.BoolVar(&controllerArgs.LintDefinitions, "lint-definitions", false,
+		"lint-definitions enables linting the CUE templates of component/trait definitions in the admission webhook, problems are logged and recorded in the audit annotations as warnings rather than rejecting the definitions.")
+	controllerArgs.NotificationOptions = notification.DefaultOptions()
+	flag.DurationVar(&controllerArgs.NotificationOptions.DedupWindow, "notification-dedup-window", 10*time.Minute,
+		"notification-dedup-window is the period that the same application notification is delivered to a receiver only once.")
+	flag.Float64Var(&controllerArgs.NotificationOptions.QPS, "notification-qps", 1,
+		"notification-qps is the maximum rate of application notifications delivered to a receiver, a non-positive value means no limit.")
+	flag.IntVar(&controllerArgs.NotificationOptions.Burst, "notification-burst", 5,
+		"notification-burst is the maximum number of application notifications delivered to a receiver at once.")
+	flag.IntVar(&controllerArgs.NotificationOptions.Retries, "notification-retries", 3,
+		"notification-retries is the number of retries if an application notification fails to be delivered.")
 	flag.StringVar(&controllerArgs.CustomRevisionHookURL, "custom-revision-hook-url", "",
 		"custom-revision-hook-url is a webhook url which will let KubeVela core to call with applicationConfiguration and component info and return a customized component revision")
 	flag.BoolVar(&controllerArgs.ApplicationConfigurationInstalled, "app-config-installed", true,
@@ -103,6 +120,7 @@ func main() {
 	flag.DurationVar(&syncPeriod, "informer-re-sync-interval", 60*time.Minute,
 		"controller shared informer lister full re-sync period")
 	flag.StringVar(&oam.SystemDefinitonNamespace, "system-definition-namespace", "vela-system", "define the namespace of the system-level definition")
+	flag.StringVar(&helm.ChartCacheDir, "helm-chart-cache-dir", helm.ChartCacheDir, "The directory to cache charts of Helm components rendered in-process.")
 	flag.Parse()
 
 	// setup logging
@@ -45,9 +45,16 @@ module.exports = {
         },
         'end-user/scopes/appdeploy',
         'end-user/scopes/rollout-plan',
+        'end-user/application-set',
+        'end-user/deployment-window',
+        'end-user/deletion-policy',
+        'end-user/adopt',
+        'end-user/migrate',
         {
           'Observability': [
             'end-user/scopes/health',
+            'end-user/health-policy',
+            'end-user/notification',
           ]
         },
         {
@@ -65,6 +72,7 @@ module.exports = {
         'platform-engineers/overview',
         'platform-engineers/definition-and-templates',
         'platform-engineers/openapi-v3-json-schema',
+        'platform-engineers/apiserver-auth',
         {
           type: 'category',
           label: 'Defining Components',
@@ -166,6 +174,7 @@ module.exports = {
             'cli/vela_system',
             'cli/vela_template',
             'cli/vela_cap',
+            'cli/vela_def',
           ],
         },
         'developers/references/restful-api/rest',
@@ -125,7 +125,7 @@ parameter: {
 	// +usage=Number of CPU units for the service, like `0.5` (0.5 CPU core), `1` (1 CPU core)
 	cpu?: string
 
-	// If addRevisionLabel is true, the appRevision label will be added to the underlying pods 
+	// +usage=If addRevisionLabel is true, the appRevision label will be added to the underlying pods
 	addRevisionLabel: *false | bool
 
 	// +usage=Declare volumes and volumeMounts
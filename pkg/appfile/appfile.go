@@ -17,6 +17,7 @@ limitations under the License.
 package appfile
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"regexp"
@@ -33,10 +34,12 @@ import (
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation/field"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	"github.com/oam-dev/kubevela/apis/core.oam.dev/common"
 	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1alpha2"
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
 	"github.com/oam-dev/kubevela/apis/types"
 	"github.com/oam-dev/kubevela/pkg/appfile/helm"
 	"github.com/oam-dev/kubevela/pkg/dsl/definition"
@@ -53,6 +56,8 @@ const (
 	errTerraformNameOfWriteConnectionSecretToRefNotSet = "the name of writeConnectionSecretToRef of terraform component is not set"
 )
 
+var invalidOutputNameChars = regexp.MustCompile(`[^a-zA-Z0-9_]`)
+
 // WriteConnectionSecretToRefKey is used to create a secret for cloud resource connection
 const WriteConnectionSecretToRefKey = "writeConnectionSecretToRef"
 
@@ -71,6 +76,10 @@ type Workload struct {
 	// RequiredSecrets stores secret names which the workload needs from cloud resource component and its context
 	RequiredSecrets []process.RequiredSecrets
 	UserConfigs     []map[string]string
+	// DeletionPolicy decides whether the resources rendered from the workload are deleted with the application
+	DeletionPolicy v1beta1.DeletionPolicy
+	// propertiesPath locates the properties of the workload in the application
+	propertiesPath *field.Path
 }
 
 // GetUserConfigName get user config from AppFile, it will contain config file in it.
@@ -91,7 +100,15 @@ func (wl *Workload) GetUserConfigName() string {
 
 // EvalContext eval workload template and set result to context
 func (wl *Workload) EvalContext(ctx process.Context) error {
-	return wl.engine.Complete(ctx, wl.FullTemplate.TemplateStr, wl.Params)
+	return propertyError(wl.engine.Complete(ctx, wl.FullTemplate.TemplateStr, wl.Params), wl.propertiesPath, wl.Params)
+}
+
+// setPropertiesPath records where the properties of the workload and its traits are in the application
+func (wl *Workload) setPropertiesPath(compPath *field.Path) {
+	wl.propertiesPath = compPath.Child("properties")
+	for i, tr := range wl.Traits {
+		tr.propertiesPath = compPath.Child("traits").Index(i).Child("properties")
+	}
 }
 
 // EvalStatus eval workload status
@@ -140,11 +157,15 @@ type Trait struct {
 
 	FullTemplate *Template
 	engine       definition.AbstractEngine
+	// DeletionPolicy decides whether the resources rendered from the trait are deleted with the application
+	DeletionPolicy v1beta1.DeletionPolicy
+	// propertiesPath locates the properties of the trait in the application
+	propertiesPath *field.Path
 }
 
 // EvalContext eval trait template and set result to context
 func (trait *Trait) EvalContext(ctx process.Context) error {
-	return trait.engine.Complete(ctx, trait.Template, trait.Params)
+	return propertyError(trait.engine.Complete(ctx, trait.Template, trait.Params), trait.propertiesPath, trait.Params)
 }
 
 // EvalStatus eval trait status
@@ -166,7 +187,7 @@ type Appfile struct {
 }
 
 // GenerateApplicationConfiguration converts an appFile to applicationConfig & Components
-func (af *Appfile) GenerateApplicationConfiguration() (*v1alpha2.ApplicationConfiguration,
+func (af *Appfile) GenerateApplicationConfiguration(ctx context.Context) (*v1alpha2.ApplicationConfiguration,
 	[]*v1alpha2.Component, error) {
 	appconfig := &v1alpha2.ApplicationConfiguration{}
 	appconfig.SetGroupVersionKind(v1alpha2.ApplicationConfigurationGroupVersionKind)
@@ -188,7 +209,7 @@ func (af *Appfile) GenerateApplicationConfiguration() (*v1alpha2.ApplicationConf
 		)
 		switch wl.CapabilityCategory {
 		case types.HelmCategory:
-			comp, acComp, err = generateComponentFromHelmModule(wl, af.Name, af.RevisionName, af.Namespace)
+			comp, acComp, err = generateComponentFromHelmModule(ctx, wl, af.Name, af.RevisionName, af.Namespace)
 			if err != nil {
 				return nil, nil, err
 			}
@@ -208,12 +229,55 @@ func (af *Appfile) GenerateApplicationConfiguration() (*v1alpha2.ApplicationConf
 				return nil, nil, err
 			}
 		}
+		if err := setDeletionPolicy(wl, comp, acComp); err != nil {
+			return nil, nil, err
+		}
 		components = append(components, comp)
 		appconfig.Spec.Components = append(appconfig.Spec.Components, *acComp)
 	}
 	return appconfig, components, nil
 }
 
+// setDeletionPolicy annotates the rendered workload and traits with the deletion policies of the workload and traits,
+// auxiliary workloads follow the policy of the workload
+func setDeletionPolicy(wl *Workload, comp *v1alpha2.Component, acComp *v1alpha2.ApplicationConfigurationComponent) error {
+	policies := map[string]v1beta1.DeletionPolicy{}
+	for _, tr := range wl.Traits {
+		if _, ok := policies[tr.Name]; !ok {
+			policies[tr.Name] = tr.DeletionPolicy
+		}
+	}
+	annotate := func(raw *runtime.RawExtension, policy v1beta1.DeletionPolicy) error {
+		if policy == "" || policy == v1beta1.DeletionPolicyDelete {
+			return nil
+		}
+		u, err := util.RawExtension2Unstructured(raw)
+		if err != nil {
+			return err
+		}
+		util.AddAnnotations(u, map[string]string{oam.AnnotationDeletionPolicy: string(policy)})
+		*raw = util.Object2RawExtension(u)
+		return nil
+	}
+	if err := annotate(&comp.Spec.Workload, wl.DeletionPolicy); err != nil {
+		return errors.Wrapf(err, "set deletion policy of component %s", wl.Name)
+	}
+	for i := range acComp.Traits {
+		t, err := util.RawExtension2Unstructured(&acComp.Traits[i].Trait)
+		if err != nil {
+			return errors.Wrapf(err, "set deletion policy of traits of component %s", wl.Name)
+		}
+		policy, ok := policies[t.GetLabels()[oam.TraitTypeLabel]]
+		if !ok {
+			policy = wl.DeletionPolicy
+		}
+		if err := annotate(&acComp.Traits[i].Trait, policy); err != nil {
+			return errors.Wrapf(err, "set deletion policy of traits of component %s", wl.Name)
+		}
+	}
+	return nil
+}
+
 // PrepareProcessContext prepares a DSL process Context
 func PrepareProcessContext(wl *Workload, applicationName, revision, namespace string) (process.Context, error) {
 	pCtx := NewBasicContext(wl, applicationName, revision, namespace)
@@ -352,25 +416,16 @@ func generateComponentFromKubeModule(wl *Workload, appName, revision, ns string)
 		return nil, nil, errors.WithMessage(err, "cannot set parameters value")
 	}
 
-	// convert structured kube obj into CUE (go ==marshal==> json ==decoder==> cue)
-	objRaw, err := kubeObj.MarshalJSON()
+	cueRaw, err := objectToCUE(kubeObj)
 	if err != nil {
-		return nil, nil, errors.Wrap(err, "cannot marshal kube object")
-	}
-	ins, err := json2cue.Decode(&cue.Runtime{}, "", objRaw)
-	if err != nil {
-		return nil, nil, errors.Wrap(err, "cannot decode object into CUE")
-	}
-	cueRaw, err := format.Node(ins.Value().Syntax())
-	if err != nil {
-		return nil, nil, errors.Wrap(err, "cannot format CUE")
+		return nil, nil, err
 	}
 
 	// NOTE a hack way to enable using CUE capabilities on KUBE schematic workload
 	wl.FullTemplate.TemplateStr = fmt.Sprintf(`
 output: { 
 	%s 
-}`, string(cueRaw))
+}`, cueRaw)
 
 	// re-use the way CUE module generates comp & acComp
 	comp, acComp, err := generateComponentFromCUEModule(wl, appName, revision, ns)
@@ -380,6 +435,23 @@ output: {
 	return comp, acComp, nil
 }
 
+// objectToCUE converts structured kube obj into CUE (go ==marshal==> json ==decoder==> cue)
+func objectToCUE(obj *unstructured.Unstructured) (string, error) {
+	objRaw, err := obj.MarshalJSON()
+	if err != nil {
+		return "", errors.Wrap(err, "cannot marshal kube object")
+	}
+	ins, err := json2cue.Decode(&cue.Runtime{}, "", objRaw)
+	if err != nil {
+		return "", errors.Wrap(err, "cannot decode object into CUE")
+	}
+	cueRaw, err := format.Node(ins.Value().Syntax())
+	if err != nil {
+		return "", errors.Wrap(err, "cannot format CUE")
+	}
+	return string(cueRaw), nil
+}
+
 func generateTerraformConfigurationWorkload(wl *Workload, ns string) (*unstructured.Unstructured, error) {
 	if wl.FullTemplate.Terraform.Configuration == "" {
 		return nil, errors.New(errTerraformConfigurationIsNotSet)
@@ -457,6 +529,9 @@ func resolveKubeParameters(params []common.KubeParameter, settings map[string]in
 		if supported[name] == nil {
 			return nil, errors.Errorf("unsupported parameter %q", name)
 		}
+		if err := validateKubeParameter(supported[name], v); err != nil {
+			return nil, err
+		}
 		// construct helper map
 		values[name] = paramValueSetting{
 			Value:      v,
@@ -465,12 +540,30 @@ func resolveKubeParameters(params []common.KubeParameter, settings map[string]in
 		}
 	}
 
-	// check required parameter
 	for _, p := range params {
-		if p.Required != nil && *p.Required {
-			if _, ok := values[p.Name]; !ok {
-				return nil, errors.Errorf("require parameter %q", p.Name)
+		if _, ok := values[p.Name]; ok {
+			continue
+		}
+		// use default value of parameter not set
+		if p.Default != nil {
+			var v interface{}
+			if err := json.Unmarshal(p.Default.Raw, &v); err != nil {
+				return nil, errors.Wrapf(err, "cannot decode default value of parameter %q", p.Name)
+			}
+			// the default value must satisfy the constraints as well as a value set by users
+			if err := validateKubeParameter(supported[p.Name], v); err != nil {
+				return nil, errors.WithMessage(err, "invalid default value")
+			}
+			values[p.Name] = paramValueSetting{
+				Value:      v,
+				ValueType:  p.ValueType,
+				FieldPaths: p.FieldPaths,
 			}
+			continue
+		}
+		// check required parameter
+		if p.Required != nil && *p.Required {
+			return nil, errors.Errorf("require parameter %q", p.Name)
 		}
 	}
 	return values, nil
@@ -479,31 +572,16 @@ func resolveKubeParameters(params []common.KubeParameter, settings map[string]in
 func setParameterValuesToKubeObj(obj *unstructured.Unstructured, values paramValueSettings) error {
 	paved := fieldpath.Pave(obj.Object)
 	for paramName, v := range values {
-		for _, f := range v.FieldPaths {
-			switch v.ValueType {
-			case common.StringType:
-				vString, ok := v.Value.(string)
-				if !ok {
-					return errors.Errorf(errInvalidValueType, v.ValueType)
-				}
-				if err := paved.SetString(f, vString); err != nil {
-					return errors.Wrapf(err, "cannot set parameter %q to field %q", paramName, f)
-				}
-			case common.NumberType:
-				switch v.Value.(type) {
-				case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64:
-					if err := paved.SetValue(f, v.Value); err != nil {
-						return errors.Wrapf(err, "cannot set parameter %q to field %q", paramName, f)
-					}
-				default:
-					return errors.Errorf(errInvalidValueType, v.ValueType)
-				}
-			case common.BooleanType:
-				vBoolean, ok := v.Value.(bool)
-				if !ok {
-					return errors.Errorf(errInvalidValueType, v.ValueType)
-				}
-				if err := paved.SetValue(f, vBoolean); err != nil {
+		if err := checkKubeParameterType(v.ValueType, v.Value); err != nil {
+			return err
+		}
+		for _, fp := range v.FieldPaths {
+			fieldPaths, err := expandFieldPath(obj.Object, fp)
+			if err != nil {
+				return errors.Wrapf(err, "cannot set parameter %q to field %q", paramName, fp)
+			}
+			for _, f := range fieldPaths {
+				if err := paved.SetValue(f, v.Value); err != nil {
 					return errors.Wrapf(err, "cannot set parameter %q to field %q", paramName, f)
 				}
 			}
@@ -512,12 +590,15 @@ func setParameterValuesToKubeObj(obj *unstructured.Unstructured, values paramVal
 	return nil
 }
 
-func generateComponentFromHelmModule(wl *Workload, appName, revision, ns string) (*v1alpha2.Component, *v1alpha2.ApplicationConfigurationComponent, error) {
+func generateComponentFromHelmModule(ctx context.Context, wl *Workload, appName, revision, ns string) (*v1alpha2.Component, *v1alpha2.ApplicationConfigurationComponent, error) {
 	gv, err := schema.ParseGroupVersion(wl.FullTemplate.Reference.APIVersion)
 	if err != nil {
 		return nil, nil, err
 	}
 	targetWorkloadGVK := gv.WithKind(wl.FullTemplate.Reference.Kind)
+	if wl.FullTemplate.HelmRenderMode == helm.RenderModeInProcess {
+		return generateComponentFromRenderedHelmChart(ctx, wl, targetWorkloadGVK, appName, revision, ns)
+	}
 
 	// NOTE this is a hack way to enable using CUE module capabilities on Helm module workload
 	// construct an empty base workload according to its GVK
@@ -551,3 +632,46 @@ output: {
 	}
 	return comp, acComp, nil
 }
+
+// generateComponentFromRenderedHelmChart templates the chart in-process instead of generating Flux objects.
+// The first rendered object matching the workload GVK becomes the workload and the others become outputs,
+// so that traits could patch them and they are tracked as the auxiliary resources of a CUE module.
+func generateComponentFromRenderedHelmChart(ctx context.Context, wl *Workload, workloadGVK schema.GroupVersionKind, appName, revision, ns string) (*v1alpha2.Component, *v1alpha2.ApplicationConfigurationComponent, error) {
+	objs, err := helm.RenderChart(ctx, wl.FullTemplate.Helm, wl.Name, appName, ns, wl.Params)
+	if err != nil {
+		return nil, nil, errors.WithMessagef(err, "cannot render chart of component %s", wl.Name)
+	}
+	var output string
+	outputs := strings.Builder{}
+	outputNames := map[string]bool{}
+	for _, obj := range objs {
+		cueRaw, err := objectToCUE(obj)
+		if err != nil {
+			return nil, nil, err
+		}
+		if output == "" && obj.GroupVersionKind() == workloadGVK {
+			output = cueRaw
+			continue
+		}
+		// output names are referred as CUE identifiers in the context of traits
+		base := invalidOutputNameChars.ReplaceAllString(strings.ToLower(obj.GetKind())+"_"+obj.GetName(), "_")
+		name := base
+		for i := 2; outputNames[name]; i++ {
+			name = fmt.Sprintf("%s_%d", base, i)
+		}
+		outputNames[name] = true
+		fmt.Fprintf(&outputs, "\t%s: {\n%s\n\t}\n", name, cueRaw)
+	}
+	if output == "" {
+		return nil, nil, errors.Errorf("chart of component %s doesn't render any %s as the workload", wl.Name, workloadGVK.String())
+	}
+
+	// NOTE re-use the way CUE module generates comp & acComp, like KUBE schematic workload does
+	wl.FullTemplate.TemplateStr = fmt.Sprintf(`
+output: {
+	%s
+}
+outputs: {
+%s}`, output, outputs.String())
+	return generateComponentFromCUEModule(wl, appName, revision, ns)
+}
@@ -17,6 +17,7 @@ limitations under the License.
 package appfile
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"testing"
@@ -30,6 +31,7 @@ import (
 	. "github.com/onsi/gomega"
 	"github.com/pkg/errors"
 	"gotest.tools/assert"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -37,6 +39,7 @@ import (
 
 	"github.com/oam-dev/kubevela/apis/core.oam.dev/common"
 	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1alpha2"
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
 	oamtypes "github.com/oam-dev/kubevela/apis/types"
 	"github.com/oam-dev/kubevela/pkg/dsl/definition"
 	"github.com/oam-dev/kubevela/pkg/dsl/process"
@@ -111,7 +114,7 @@ var _ = Describe("Test Helm schematic appfile", func() {
 			},
 		}
 		By("Generate ApplicationConfiguration and Components")
-		ac, components, err := appFile.GenerateApplicationConfiguration()
+		ac, components, err := appFile.GenerateApplicationConfiguration(context.Background())
 		Expect(err).To(BeNil())
 
 		manuscaler := util.Object2RawExtension(&unstructured.Unstructured{
@@ -346,7 +349,7 @@ spec:
 
 	It("Test generate AppConfig resources from Kube schematic", func() {
 		By("Generate ApplicationConfiguration and Components")
-		ac, components, err := testAppfile().GenerateApplicationConfiguration()
+		ac, components, err := testAppfile().GenerateApplicationConfiguration(context.Background())
 		Expect(err).To(BeNil())
 
 		expectAppConfig := &v1alpha2.ApplicationConfiguration{
@@ -396,7 +399,7 @@ spec:
 		appfile := testAppfile()
 		// remove parameter settings
 		appfile.Workloads[0].Params = nil
-		_, _, err := appfile.GenerateApplicationConfiguration()
+		_, _, err := appfile.GenerateApplicationConfiguration(context.Background())
 
 		expectError := errors.WithMessage(errors.New(`require parameter "image"`), "cannot resolve parameter settings")
 		diff := cmp.Diff(expectError, err, test.EquateErrors())
@@ -544,7 +547,7 @@ variable "password" {
 			},
 		}
 
-		acc, comp, err := af.GenerateApplicationConfiguration()
+		acc, comp, err := af.GenerateApplicationConfiguration(context.Background())
 		Expect(acc).Should(Equal(expectedAppConfig))
 		diff := cmp.Diff(comp[0], expectedComponent)
 		Expect(diff).ShouldNot(BeEmpty())
@@ -564,6 +567,27 @@ func TestResolveKubeParameters(t *testing.T) {
 		ValueType:  common.StringType,
 		FieldPaths: []string{"spec"},
 	}
+	enumParam := &common.KubeParameter{
+		Name:       "enumParam",
+		Required:   pointer.BoolPtr(true),
+		ValueType:  common.StringType,
+		FieldPaths: []string{"spec.containers[*].imagePullPolicy"},
+		Default:    &apiextensionsv1.JSON{Raw: []byte(`"IfNotPresent"`)},
+		Enum:       []apiextensionsv1.JSON{{Raw: []byte(`"Always"`)}, {Raw: []byte(`"IfNotPresent"`)}},
+	}
+	patternParam := &common.KubeParameter{
+		Name:       "patternParam",
+		ValueType:  common.StringType,
+		FieldPaths: []string{"spec.containers[0].image"},
+		Pattern:    pointer.StringPtr(`^[a-z/]+:v[0-9.]+$`),
+	}
+	numberParam := &common.KubeParameter{
+		Name:       "numParam",
+		ValueType:  common.NumberType,
+		FieldPaths: []string{"spec.replicas"},
+		Minimum:    pointer.Int64Ptr(1),
+		Maximum:    pointer.Int64Ptr(10),
+	}
 	tests := map[string]struct {
 		reason   string
 		params   []common.KubeParameter
@@ -589,6 +613,64 @@ func TestResolveKubeParameters(t *testing.T) {
 			want:     nil,
 			wantErr:  errors.Errorf("require parameter %q", "reqParam"),
 		},
+		"InvalidType": {
+			reason:   "An error should be returned because the value doesn't match the type",
+			params:   []common.KubeParameter{*stringParam},
+			settings: map[string]interface{}{"strParam": 1},
+			want:     nil,
+			wantErr:  errors.WithMessagef(errors.Errorf(errInvalidValueType, common.StringType), "invalid parameter %q", "strParam"),
+		},
+		"NotInEnum": {
+			reason:   "An error should be returned because the value is not in enum",
+			params:   []common.KubeParameter{*enumParam},
+			settings: map[string]interface{}{"enumParam": "Never"},
+			want:     nil,
+			wantErr:  errors.Errorf("parameter %q must be one of [%s]", "enumParam", `"Always", "IfNotPresent"`),
+		},
+		"PatternMismatch": {
+			reason:   "An error should be returned because the value doesn't match the pattern",
+			params:   []common.KubeParameter{*patternParam},
+			settings: map[string]interface{}{"patternParam": "nginx:latest"},
+			want:     nil,
+			wantErr:  errors.Errorf("parameter %q must match pattern %q", "patternParam", `^[a-z/]+:v[0-9.]+$`),
+		},
+		"OutOfRange": {
+			reason:   "An error should be returned because the value is greater than maximum",
+			params:   []common.KubeParameter{*numberParam},
+			settings: map[string]interface{}{"numParam": float64(11)},
+			want:     nil,
+			wantErr:  errors.Errorf("parameter %q must be less than or equal to %d", "numParam", 10),
+		},
+		"InvalidDefaultValue": {
+			reason: "An error should be returned because the default value is not in enum",
+			params: []common.KubeParameter{{
+				Name:       "enumParam",
+				ValueType:  common.StringType,
+				FieldPaths: enumParam.FieldPaths,
+				Default:    &apiextensionsv1.JSON{Raw: []byte(`"Never"`)},
+				Enum:       enumParam.Enum,
+			}},
+			want: nil,
+			wantErr: errors.WithMessage(errors.Errorf("parameter %q must be one of [%s]", "enumParam", `"Always", "IfNotPresent"`),
+				"invalid default value"),
+		},
+		"DefaultValue": {
+			reason:   "Default value should be used if the required parameter is not set",
+			params:   []common.KubeParameter{*enumParam, *numberParam},
+			settings: map[string]interface{}{"numParam": 5},
+			want: paramValueSettings{
+				"enumParam": paramValueSetting{
+					Value:      "IfNotPresent",
+					ValueType:  common.StringType,
+					FieldPaths: enumParam.FieldPaths,
+				},
+				"numParam": paramValueSetting{
+					Value:      5,
+					ValueType:  common.NumberType,
+					FieldPaths: numberParam.FieldPaths,
+				},
+			},
+		},
 		"Succeed": {
 			reason:   "No error should be returned",
 			params:   []common.KubeParameter{*stringParam, *requiredParam},
@@ -710,6 +792,61 @@ func TestSetParameterValuesToKubeObj(t *testing.T) {
 				},
 			}},
 		},
+		"SucceedWithStructuredValuesAndWildcard": {
+			reason: "Object and array values should be set to fields matched by index or wildcard",
+			obj: unstructured.Unstructured{Object: map[string]interface{}{
+				"spec": map[string]interface{}{
+					"containers": []interface{}{
+						map[string]interface{}{"name": "main"},
+						map[string]interface{}{"name": "sidecar"},
+					},
+				},
+			}},
+			values: paramValueSettings{
+				"pullPolicy": paramValueSetting{
+					Value:      "Always",
+					ValueType:  common.StringType,
+					FieldPaths: []string{"spec.containers[*].imagePullPolicy"},
+				},
+				"env": paramValueSetting{
+					Value:      []interface{}{map[string]interface{}{"name": "LOG_LEVEL", "value": "info"}},
+					ValueType:  common.ArrayType,
+					FieldPaths: []string{"spec.containers[0].env"},
+				},
+				"resources": paramValueSetting{
+					Value:      map[string]interface{}{"limits": map[string]interface{}{"cpu": "100m"}},
+					ValueType:  common.ObjectType,
+					FieldPaths: []string{"spec.containers[1].resources"},
+				},
+			},
+			wantObj: unstructured.Unstructured{Object: map[string]interface{}{
+				"spec": map[string]interface{}{
+					"containers": []interface{}{
+						map[string]interface{}{
+							"name":            "main",
+							"imagePullPolicy": "Always",
+							"env":             []interface{}{map[string]interface{}{"name": "LOG_LEVEL", "value": "info"}},
+						},
+						map[string]interface{}{
+							"name":            "sidecar",
+							"imagePullPolicy": "Always",
+							"resources":       map[string]interface{}{"limits": map[string]interface{}{"cpu": "100m"}},
+						},
+					},
+				},
+			}},
+		},
+		"InvalidArrayType": {
+			reason: "An error should be returned",
+			values: paramValueSettings{
+				"env": paramValueSetting{
+					Value:      map[string]interface{}{"name": "LOG_LEVEL"},
+					ValueType:  common.ArrayType,
+					FieldPaths: []string{"spec.env"},
+				},
+			},
+			wantErr: errors.Errorf(errInvalidValueType, common.ArrayType),
+		},
 	}
 
 	for tcName, tc := range tests {
@@ -967,3 +1104,85 @@ func TestGetUserConfigName(t *testing.T) {
 	wl3 := &Workload{Params: map[string]interface{}{AppfileBuiltinConfig: config}}
 	assert.Equal(t, wl3.GetUserConfigName(), config)
 }
+
+func TestSetDeletionPolicy(t *testing.T) {
+	wl := &Workload{
+		Name:           "web",
+		DeletionPolicy: v1beta1.DeletionPolicyRetain,
+		Traits: []*Trait{
+			{Name: "ingress", DeletionPolicy: v1beta1.DeletionPolicyDelete},
+			{Name: "pvc", DeletionPolicy: v1beta1.DeletionPolicyOrphan},
+		},
+	}
+	trait := func(traitType string) v1alpha2.ComponentTrait {
+		return v1alpha2.ComponentTrait{Trait: runtime.RawExtension{Raw: []byte(fmt.Sprintf(
+			`{"apiVersion":"v1","kind":"Service","metadata":{"labels":{"%s":"%s"}}}`, oam.TraitTypeLabel, traitType))}}
+	}
+	comp := &v1alpha2.Component{Spec: v1alpha2.ComponentSpec{
+		Workload: runtime.RawExtension{Raw: []byte(`{"apiVersion":"apps/v1","kind":"Deployment"}`)},
+	}}
+	acComp := &v1alpha2.ApplicationConfigurationComponent{
+		Traits: []v1alpha2.ComponentTrait{trait("ingress"), trait("pvc"), trait("AuxiliaryWorkload")},
+	}
+	assert.NilError(t, setDeletionPolicy(wl, comp, acComp))
+
+	policyOf := func(raw runtime.RawExtension) string {
+		u, err := util.RawExtension2Unstructured(&raw)
+		assert.NilError(t, err)
+		return u.GetAnnotations()[oam.AnnotationDeletionPolicy]
+	}
+	assert.Equal(t, policyOf(comp.Spec.Workload), "Retain")
+	assert.Equal(t, policyOf(acComp.Traits[0].Trait), "")
+	assert.Equal(t, policyOf(acComp.Traits[1].Trait), "Orphan")
+	assert.Equal(t, policyOf(acComp.Traits[2].Trait), "Retain")
+}
+
+func TestGenerateComponentFromRenderedHelmChart(t *testing.T) {
+	release, _ := yaml.YAMLToJSON([]byte(`chart:
+  spec:
+    chart: hello
+    version: 0.1.0`))
+	repo, _ := yaml.YAMLToJSON([]byte(`url: file://helm/testdata`))
+	wl := &Workload{
+		Name: "web",
+		Type: "hello",
+		FullTemplate: &Template{
+			Reference:      common.WorkloadGVK{APIVersion: "apps/v1", Kind: "Deployment"},
+			Helm:           &common.Helm{Release: runtime.RawExtension{Raw: release}, Repository: runtime.RawExtension{Raw: repo}},
+			HelmRenderMode: "inprocess",
+		},
+		CapabilityCategory: oamtypes.HelmCategory,
+		Params:             map[string]interface{}{"image": "nginx:1.20"},
+		engine:             definition.NewWorkloadAbstractEngine("web", &definition.PackageDiscover{}),
+		Traits: []*Trait{{
+			Name: "labels",
+			Params: map[string]interface{}{
+				"team": "a",
+			},
+			Template: `
+parameter: [string]: string
+patch: spec: template: metadata: labels: parameter
+`,
+			engine: definition.NewTraitAbstractEngine("labels", &definition.PackageDiscover{}),
+		}},
+	}
+	comp, acComp, err := generateComponentFromHelmModule(context.Background(), wl, "myapp", "myapp-v1", "default")
+	assert.NilError(t, err)
+	assert.Assert(t, comp.Spec.Helm == nil)
+
+	workload := &unstructured.Unstructured{}
+	assert.NilError(t, json.Unmarshal(comp.Spec.Workload.Raw, &workload.Object))
+	assert.Equal(t, "Deployment", workload.GetKind())
+	assert.Equal(t, "myapp-web", workload.GetName())
+	podLabels, _, _ := unstructured.NestedStringMap(workload.Object, "spec", "template", "metadata", "labels")
+	assert.DeepEqual(t, map[string]string{"app": "myapp-web", "team": "a"}, podLabels)
+	containers, _, _ := unstructured.NestedSlice(workload.Object, "spec", "template", "spec", "containers")
+	assert.Equal(t, "nginx:1.20", containers[0].(map[string]interface{})["image"])
+	assert.Equal(t, "hello", workload.GetLabels()[oam.WorkloadTypeLabel])
+
+	assert.Equal(t, 1, len(acComp.Traits))
+	service := &unstructured.Unstructured{}
+	assert.NilError(t, json.Unmarshal(acComp.Traits[0].Trait.Raw, &service.Object))
+	assert.Equal(t, "Service", service.GetKind())
+	assert.Equal(t, "service_myapp_web", service.GetLabels()[oam.TraitResource])
+}
@@ -21,8 +21,12 @@ import (
 	"strings"
 
 	v1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
 )
 
 const (
@@ -34,7 +38,7 @@ const (
 )
 
 // ToConfigMap will get the data of the store and upload to configmap.
-// Serverside Application controller can only use the config in appfile context by configmap.
+// Serverside Application controller reads the config in appfile context from the configmap or the secret generated by ToSecret.
 func ToConfigMap(s Store, name, envName string, configData map[string]string) (*v1.ConfigMap, error) {
 	namespace, err := s.Namespace(envName)
 	if err != nil {
@@ -57,7 +61,7 @@ func GenConfigMapName(appName, serviceName, configName string) string {
 	return strings.Join([]string{"kubevela", appName, serviceName, configName}, Splitter)
 }
 
-var _ Store = &Configmap{}
+var _ Manager = &Configmap{}
 
 // Configmap is the configmap implementation of config store
 type Configmap struct {
@@ -78,19 +82,90 @@ func (f *Configmap) GetConfigData(name, envName string) ([]map[string]string, er
 		return nil, err
 	}
 	var data []map[string]string
-	for k, v := range cm.Data {
-		data = append(data, EncodeConfigFormat(k, v))
+	for _, k := range sortedKeys(cm.Data) {
+		data = append(data, EncodeConfigFormat(k, cm.Data[k]))
 	}
 	return data, nil
 }
 
+// SetConfigData will create or update the configmap of the config, the existing data of the config is replaced
+func (f *Configmap) SetConfigData(configName, envName string, data map[string]string) error {
+	namespace, err := f.Namespace(envName)
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
+	cm := new(v1.ConfigMap)
+	err = f.Client.Get(ctx, client.ObjectKey{Name: configName, Namespace: namespace}, cm)
+	if err != nil && !kerrors.IsNotFound(err) {
+		return err
+	}
+	exist := err == nil
+	cm.SetName(configName)
+	cm.SetNamespace(namespace)
+	setUserConfigLabel(cm)
+	cm.Data = data
+	if exist {
+		return f.Client.Update(ctx, cm)
+	}
+	return f.Client.Create(ctx, cm)
+}
+
+// ListConfigs will list the names of all the configs in the namespace of the env
+func (f *Configmap) ListConfigs(envName string) ([]string, error) {
+	namespace, err := f.Namespace(envName)
+	if err != nil {
+		return nil, err
+	}
+	cms := new(v1.ConfigMapList)
+	if err := f.Client.List(context.Background(), cms, client.InNamespace(namespace), client.HasLabels{LabelUserConfig}); err != nil {
+		return nil, err
+	}
+	names := []string{}
+	for _, cm := range cms.Items {
+		names = append(names, cm.Name)
+	}
+	return names, nil
+}
+
+// DeleteConfig will delete the configmap of the config
+func (f *Configmap) DeleteConfig(configName, envName string) error {
+	namespace, err := f.Namespace(envName)
+	if err != nil {
+		return err
+	}
+	cm := &v1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: configName, Namespace: namespace}}
+	return client.IgnoreNotFound(f.Client.Delete(context.Background(), cm))
+}
+
 // Namespace returns the namespace of the config store from env
 func (f *Configmap) Namespace(envName string) (string, error) {
-	// TODO(wonderflow): now we regard env as namespace, it should be fixed when env is store serverside as configmap
-	return envName, nil
+	return envNamespace(f.Client, envName)
 }
 
 // Type returns the type of the config store
 func (Configmap) Type() string {
 	return TypeConfigMap
 }
+
+// envNamespace returns the namespace of an env,
+// the env name is regarded as namespace if the Environment doesn't exist in cluster
+func envNamespace(c client.Reader, envName string) (string, error) {
+	env := new(v1beta1.Environment)
+	if err := c.Get(context.Background(), client.ObjectKey{Name: envName}, env); err != nil {
+		if kerrors.IsNotFound(err) || meta.IsNoMatchError(err) {
+			return envName, nil
+		}
+		return "", err
+	}
+	return env.Spec.Namespace, nil
+}
+
+func setUserConfigLabel(o metav1.Object) {
+	labels := o.GetLabels()
+	if labels == nil {
+		labels = map[string]string{}
+	}
+	labels[LabelUserConfig] = "true"
+	o.SetLabels(labels)
+}
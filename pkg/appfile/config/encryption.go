@@ -0,0 +1,142 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	b64 "encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+)
+
+// EncryptionAESGCM is the only supported encryption algorithm of config data
+const EncryptionAESGCM = "aes-gcm"
+
+// Encryptor does envelope encryption for config data.
+// Every config is encrypted by a random data key, the data key is encrypted by the key of the Encryptor.
+type Encryptor struct {
+	key   []byte
+	keyID string
+}
+
+// NewEncryptor creates an Encryptor with an AES key, the length of the key must be 16, 24 or 32 bytes
+func NewEncryptor(key []byte) (*Encryptor, error) {
+	switch len(key) {
+	case 16, 24, 32:
+	default:
+		return nil, fmt.Errorf("invalid encryption key size %d, must be 16, 24 or 32 bytes", len(key))
+	}
+	sum := sha256.Sum256(key)
+	return &Encryptor{key: key, keyID: hex.EncodeToString(sum[:8])}, nil
+}
+
+// LoadEncryptor creates an Encryptor with the key read from file, the key could be raw bytes or base64 encoded
+func LoadEncryptor(path string) (*Encryptor, error) {
+	data, err := ioutil.ReadFile(filepath.Clean(path))
+	if err != nil {
+		return nil, errors.Wrap(err, "read encryption key")
+	}
+	key := bytes.TrimSpace(data)
+	if decoded, err := b64.StdEncoding.DecodeString(string(key)); err == nil {
+		key = decoded
+	}
+	return NewEncryptor(key)
+}
+
+// KeyID identifies the key of the Encryptor without revealing it
+func (e *Encryptor) KeyID() string {
+	return e.keyID
+}
+
+// Encrypt encrypts the config data with a new data key, it returns the encrypted data and the encrypted data key
+func (e *Encryptor) Encrypt(data map[string]string) (map[string][]byte, []byte, error) {
+	dataKey := make([]byte, 32)
+	if _, err := io.ReadFull(rand.Reader, dataKey); err != nil {
+		return nil, nil, err
+	}
+	wrappedKey, err := seal(e.key, dataKey, []byte(e.keyID))
+	if err != nil {
+		return nil, nil, err
+	}
+	encrypted := make(map[string][]byte, len(data))
+	for k, v := range data {
+		// the key of the entry is used as additional data so that values can't be swapped between keys
+		if encrypted[k], err = seal(dataKey, []byte(v), []byte(k)); err != nil {
+			return nil, nil, err
+		}
+	}
+	return encrypted, wrappedKey, nil
+}
+
+// Decrypt decrypts the config data with the encrypted data key
+func (e *Encryptor) Decrypt(data map[string][]byte, wrappedKey []byte) (map[string]string, error) {
+	dataKey, err := open(e.key, wrappedKey, []byte(e.keyID))
+	if err != nil {
+		return nil, errors.Wrap(err, "decrypt data key")
+	}
+	decrypted := make(map[string]string, len(data))
+	for k, v := range data {
+		plain, err := open(dataKey, v, []byte(k))
+		if err != nil {
+			return nil, errors.Wrapf(err, "decrypt %s", k)
+		}
+		decrypted[k] = string(plain)
+	}
+	return decrypted, nil
+}
+
+// seal encrypts the plaintext with AES-GCM, the random nonce is prepended to the ciphertext
+func seal(key, plaintext, additionalData []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+	return gcm.Seal(nonce, nonce, plaintext, additionalData), nil
+}
+
+func open(key, ciphertext, additionalData []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(ciphertext) < gcm.NonceSize() {
+		return nil, errors.New("ciphertext too short")
+	}
+	nonce, sealed := ciphertext[:gcm.NonceSize()], ciphertext[gcm.NonceSize():]
+	return gcm.Open(nil, nonce, sealed, additionalData)
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
@@ -19,6 +19,10 @@ package config
 import (
 	"bufio"
 	"bytes"
+	b64 "encoding/base64"
+	"fmt"
+	"io/ioutil"
+	"sort"
 
 	"github.com/oam-dev/kubevela/pkg/utils/config"
 	env2 "github.com/oam-dev/kubevela/pkg/utils/env"
@@ -30,7 +34,7 @@ const TypeLocal = "local"
 // Local is the local implementation of config store
 type Local struct{}
 
-var _ Store = &Local{}
+var _ Manager = &Local{}
 
 // GetConfigData will return config data from local
 func (l *Local) GetConfigData(configName, envName string) ([]map[string]string, error) {
@@ -50,6 +54,37 @@ func (l *Local) GetConfigData(configName, envName string) ([]map[string]string,
 	return data, nil
 }
 
+// SetConfigData will write config data into local, the existing data of the config is replaced
+func (l *Local) SetConfigData(configName, envName string, data map[string]string) error {
+	var out bytes.Buffer
+	for _, k := range sortedKeys(data) {
+		out.WriteString(fmt.Sprintf("%s: %s\n", k, b64.StdEncoding.EncodeToString([]byte(data[k]))))
+	}
+	return config.WriteConfig(envName, configName, out.Bytes())
+}
+
+// ListConfigs will list the names of all local configs of the env
+func (l *Local) ListConfigs(envName string) ([]string, error) {
+	d, err := config.GetConfigsDir(envName)
+	if err != nil {
+		return nil, err
+	}
+	files, err := ioutil.ReadDir(d)
+	if err != nil {
+		return nil, err
+	}
+	names := []string{}
+	for _, f := range files {
+		names = append(names, f.Name())
+	}
+	return names, nil
+}
+
+// DeleteConfig will delete the local config
+func (l *Local) DeleteConfig(configName, envName string) error {
+	return config.DeleteConfig(envName, configName)
+}
+
 // Namespace return namespace from env
 func (l *Local) Namespace(envName string) (string, error) {
 	env, err := env2.GetEnvByName(envName)
@@ -63,3 +98,12 @@ func (l *Local) Namespace(envName string) (string, error) {
 func (l *Local) Type() string {
 	return TypeLocal
 }
+
+func sortedKeys(data map[string]string) []string {
+	keys := make([]string, 0, len(data))
+	for k := range data {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,52 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"github.com/pkg/errors"
+)
+
+// Migrate copies all the configs of the env from one store to another, it returns the names of the migrated configs.
+// The configs are deleted from the source store after all of them are copied if deleteSource is true.
+func Migrate(from, to Manager, envName string, deleteSource bool) ([]string, error) {
+	names, err := from.ListConfigs(envName)
+	if err != nil {
+		return nil, errors.WithMessagef(err, "list configs from %s store", from.Type())
+	}
+	for _, name := range names {
+		data, err := from.GetConfigData(name, envName)
+		if err != nil {
+			return nil, errors.WithMessagef(err, "read config %s from %s store", name, from.Type())
+		}
+		decoded, err := DecodeConfigFormat(data)
+		if err != nil {
+			return nil, err
+		}
+		if err := to.SetConfigData(name, envName, decoded); err != nil {
+			return nil, errors.WithMessagef(err, "write config %s to %s store", name, to.Type())
+		}
+	}
+	if !deleteSource {
+		return names, nil
+	}
+	for _, name := range names {
+		if err := from.DeleteConfig(name, envName); err != nil {
+			return names, errors.WithMessagef(err, "delete config %s from %s store", name, from.Type())
+		}
+	}
+	return names, nil
+}
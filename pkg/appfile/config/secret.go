@@ -0,0 +1,234 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"context"
+	b64 "encoding/base64"
+	"fmt"
+
+	"github.com/pkg/errors"
+	v1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// TypeSecret defines the type of Secret config store
+	TypeSecret = "secret"
+
+	// AnnotationEncryption records the algorithm that the data of an encrypted config secret is encrypted with
+	AnnotationEncryption = "config.oam.dev/encryption"
+	// AnnotationDataKey records the encrypted data key of an encrypted config secret
+	AnnotationDataKey = "config.oam.dev/data-key"
+	// AnnotationKeyID records the id of the key that encrypts the data key
+	AnnotationKeyID = "config.oam.dev/key-id"
+	// AnnotationPlaintextExport marks the secret generated for an application from a config, its data is in plaintext
+	AnnotationPlaintextExport = "config.oam.dev/plaintext-export"
+)
+
+// Secret is the config store backed by K8s Secrets,
+// the data is encrypted before it's saved if the Encryptor is set
+type Secret struct {
+	Client    client.Client
+	Encryptor *Encryptor
+
+	// AllowPlaintextExport allows ToSecret to export the encrypted configs into plaintext secrets for applications
+	AllowPlaintextExport bool
+}
+
+var _ Manager = &Secret{}
+
+// GetConfigData returns the data of the config secret, encrypted data is decrypted by the Encryptor
+func (s *Secret) GetConfigData(configName, envName string) ([]map[string]string, error) {
+	namespace, err := s.Namespace(envName)
+	if err != nil {
+		return nil, err
+	}
+	secret := new(v1.Secret)
+	if err := s.Client.Get(context.Background(), client.ObjectKey{Name: configName, Namespace: namespace}, secret); err != nil {
+		return nil, err
+	}
+	data, err := s.decrypt(secret)
+	if err != nil {
+		return nil, errors.WithMessagef(err, "read config %s", configName)
+	}
+	var res []map[string]string
+	for _, k := range sortedKeys(data) {
+		res = append(res, EncodeConfigFormat(k, data[k]))
+	}
+	return res, nil
+}
+
+// SetConfigData will create or update the secret of the config, the existing data of the config is replaced
+func (s *Secret) SetConfigData(configName, envName string, data map[string]string) error {
+	namespace, err := s.Namespace(envName)
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
+	secret := new(v1.Secret)
+	err = s.Client.Get(ctx, client.ObjectKey{Name: configName, Namespace: namespace}, secret)
+	if err != nil && !kerrors.IsNotFound(err) {
+		return err
+	}
+	exist := err == nil
+	secret.SetName(configName)
+	secret.SetNamespace(namespace)
+	secret.Type = v1.SecretTypeOpaque
+	setUserConfigLabel(secret)
+	if err := s.encrypt(secret, data); err != nil {
+		return err
+	}
+	if exist {
+		return s.Client.Update(ctx, secret)
+	}
+	return s.Client.Create(ctx, secret)
+}
+
+// ListConfigs will list the names of all the config secrets in the namespace of the env
+func (s *Secret) ListConfigs(envName string) ([]string, error) {
+	namespace, err := s.Namespace(envName)
+	if err != nil {
+		return nil, err
+	}
+	secrets := new(v1.SecretList)
+	if err := s.Client.List(context.Background(), secrets, client.InNamespace(namespace), client.HasLabels{LabelUserConfig}); err != nil {
+		return nil, err
+	}
+	names := []string{}
+	for _, secret := range secrets.Items {
+		names = append(names, secret.Name)
+	}
+	return names, nil
+}
+
+// DeleteConfig will delete the secret of the config
+func (s *Secret) DeleteConfig(configName, envName string) error {
+	namespace, err := s.Namespace(envName)
+	if err != nil {
+		return err
+	}
+	secret := &v1.Secret{ObjectMeta: metav1.ObjectMeta{Name: configName, Namespace: namespace}}
+	return client.IgnoreNotFound(s.Client.Delete(context.Background(), secret))
+}
+
+// Namespace returns the namespace of the config store from env
+func (s *Secret) Namespace(envName string) (string, error) {
+	return envNamespace(s.Client, envName)
+}
+
+// Type returns the type of this config store implementation
+func (s *Secret) Type() string {
+	return TypeSecret
+}
+
+func (s *Secret) encrypt(secret *v1.Secret, data map[string]string) error {
+	annotations := secret.GetAnnotations()
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	delete(annotations, AnnotationEncryption)
+	delete(annotations, AnnotationDataKey)
+	delete(annotations, AnnotationKeyID)
+	defer secret.SetAnnotations(annotations)
+
+	secret.StringData = nil
+	if s.Encryptor == nil {
+		secret.Data = make(map[string][]byte, len(data))
+		for k, v := range data {
+			secret.Data[k] = []byte(v)
+		}
+		return nil
+	}
+	encrypted, dataKey, err := s.Encryptor.Encrypt(data)
+	if err != nil {
+		return errors.Wrap(err, "encrypt config data")
+	}
+	secret.Data = encrypted
+	annotations[AnnotationEncryption] = EncryptionAESGCM
+	annotations[AnnotationDataKey] = b64.StdEncoding.EncodeToString(dataKey)
+	annotations[AnnotationKeyID] = s.Encryptor.KeyID()
+	return nil
+}
+
+func (s *Secret) decrypt(secret *v1.Secret) (map[string]string, error) {
+	annotations := secret.GetAnnotations()
+	alg, encrypted := annotations[AnnotationEncryption]
+	if !encrypted {
+		data := make(map[string]string, len(secret.Data))
+		for k, v := range secret.Data {
+			data[k] = string(v)
+		}
+		return data, nil
+	}
+	if alg != EncryptionAESGCM {
+		return nil, fmt.Errorf("unsupported encryption %s", alg)
+	}
+	if s.Encryptor == nil {
+		return nil, errors.New("the config is encrypted, please specify the encryption key")
+	}
+	if keyID := annotations[AnnotationKeyID]; keyID != s.Encryptor.KeyID() {
+		return nil, fmt.Errorf("the config is encrypted by key %s, but key %s is specified", keyID, s.Encryptor.KeyID())
+	}
+	dataKey, err := b64.StdEncoding.DecodeString(annotations[AnnotationDataKey])
+	if err != nil {
+		return nil, errors.Wrap(err, "decode data key")
+	}
+	return s.Encryptor.Decrypt(secret.Data, dataKey)
+}
+
+// ToSecret will get the data of the store and generate a secret for the application.
+// The generated secret is not encrypted, so the Application controller and the workloads could read it without the
+// key, it's annotated as a plaintext export. Configs encrypted in the secret store are only exported if the store
+// allows plaintext export explicitly.
+func ToSecret(s Store, name, envName string, configData map[string]string) (*v1.Secret, error) {
+	if store, ok := s.(*Secret); ok && store.Encryptor != nil && !store.AllowPlaintextExport {
+		return nil, fmt.Errorf("the configs are encrypted in the secret store, but secret %s generated for the application stores them in plaintext, "+
+			"plaintext export must be allowed explicitly", name)
+	}
+	namespace, err := s.Namespace(envName)
+	if err != nil {
+		return nil, err
+	}
+	var secret = v1.Secret{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: "v1",
+			Kind:       "Secret",
+		},
+		Type: v1.SecretTypeOpaque,
+	}
+	secret.SetName(name)
+	secret.SetNamespace(namespace)
+	secret.SetAnnotations(map[string]string{AnnotationPlaintextExport: "true"})
+	secret.Data = make(map[string][]byte, len(configData))
+	for k, v := range configData {
+		secret.Data[k] = []byte(v)
+	}
+	return &secret, nil
+}
+
+// GetConfigDataFromCluster reads the config generated for an application,
+// the secret generated by ToSecret is preferred to the configmap generated by ToConfigMap.
+func GetConfigDataFromCluster(c client.Client, configName, envName string) ([]map[string]string, error) {
+	data, err := (&Secret{Client: c}).GetConfigData(configName, envName)
+	if err == nil || !kerrors.IsNotFound(err) {
+		return data, err
+	}
+	return (&Configmap{Client: c}).GetConfigData(configName, envName)
+}
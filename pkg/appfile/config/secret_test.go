@@ -0,0 +1,159 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
+)
+
+func newFakeClient(t *testing.T) client.Client {
+	scheme := runtime.NewScheme()
+	assert.NoError(t, v1.AddToScheme(scheme))
+	assert.NoError(t, v1beta1.SchemeBuilder.AddToScheme(scheme))
+	env := &v1beta1.Environment{
+		ObjectMeta: metav1.ObjectMeta{Name: "prod"},
+		Spec:       v1beta1.EnvironmentSpec{Namespace: "prod-ns"},
+	}
+	return fake.NewFakeClientWithScheme(scheme, env)
+}
+
+func TestEncryptor(t *testing.T) {
+	_, err := NewEncryptor([]byte("short"))
+	assert.Error(t, err)
+
+	e, err := NewEncryptor([]byte("0123456789abcdef0123456789abcdef"))
+	assert.NoError(t, err)
+	data := map[string]string{"user": "admin", "password": "p@ss"}
+	encrypted, dataKey, err := e.Encrypt(data)
+	assert.NoError(t, err)
+	assert.NotEqual(t, "p@ss", string(encrypted["password"]))
+
+	decrypted, err := e.Decrypt(encrypted, dataKey)
+	assert.NoError(t, err)
+	assert.Equal(t, data, decrypted)
+
+	// values can't be moved to another key
+	encrypted["user"], encrypted["password"] = encrypted["password"], encrypted["user"]
+	_, err = e.Decrypt(encrypted, dataKey)
+	assert.Error(t, err)
+
+	other, err := NewEncryptor([]byte("fedcba9876543210"))
+	assert.NoError(t, err)
+	_, err = other.Decrypt(encrypted, dataKey)
+	assert.Error(t, err)
+}
+
+func TestSecretStore(t *testing.T) {
+	c := newFakeClient(t)
+	e, err := NewEncryptor([]byte("0123456789abcdef"))
+	assert.NoError(t, err)
+	store := &Secret{Client: c, Encryptor: e}
+
+	assert.NoError(t, store.SetConfigData("db", "prod", map[string]string{"password": "p@ss", "user": "admin"}))
+	secret := new(v1.Secret)
+	assert.NoError(t, c.Get(context.Background(), client.ObjectKey{Name: "db", Namespace: "prod-ns"}, secret))
+	assert.Equal(t, EncryptionAESGCM, secret.Annotations[AnnotationEncryption])
+	assert.Equal(t, e.KeyID(), secret.Annotations[AnnotationKeyID])
+	assert.Equal(t, "true", secret.Labels[LabelUserConfig])
+	assert.NotEqual(t, "p@ss", string(secret.Data["password"]))
+
+	data, err := store.GetConfigData("db", "prod")
+	assert.NoError(t, err)
+	assert.Equal(t, []map[string]string{
+		{"name": "password", "value": "p@ss"},
+		{"name": "user", "value": "admin"},
+	}, data)
+
+	_, err = (&Secret{Client: c}).GetConfigData("db", "prod")
+	assert.Error(t, err)
+	other, err := NewEncryptor([]byte("fedcba9876543210"))
+	assert.NoError(t, err)
+	_, err = (&Secret{Client: c, Encryptor: other}).GetConfigData("db", "prod")
+	assert.Error(t, err)
+
+	names, err := store.ListConfigs("prod")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"db"}, names)
+
+	assert.NoError(t, store.DeleteConfig("db", "prod"))
+	names, err = store.ListConfigs("prod")
+	assert.NoError(t, err)
+	assert.Empty(t, names)
+}
+
+func TestMigrate(t *testing.T) {
+	c := newFakeClient(t)
+	cmStore := &Configmap{Client: c}
+	assert.NoError(t, cmStore.SetConfigData("db", "prod", map[string]string{"password": "p@ss"}))
+	e, err := NewEncryptor([]byte("0123456789abcdef"))
+	assert.NoError(t, err)
+	secretStore := &Secret{Client: c, Encryptor: e}
+
+	names, err := Migrate(cmStore, secretStore, "prod", true)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"db"}, names)
+
+	data, err := secretStore.GetConfigData("db", "prod")
+	assert.NoError(t, err)
+	assert.Equal(t, []map[string]string{{"name": "password", "value": "p@ss"}}, data)
+	names, err = cmStore.ListConfigs("prod")
+	assert.NoError(t, err)
+	assert.Empty(t, names)
+}
+
+func TestGetConfigDataFromCluster(t *testing.T) {
+	c := newFakeClient(t)
+	cm, err := ToConfigMap(&Configmap{Client: c}, "kubevela-app-web-cfg", "prod", map[string]string{"k": "from-cm"})
+	assert.NoError(t, err)
+	assert.NoError(t, c.Create(context.Background(), cm))
+	data, err := GetConfigDataFromCluster(c, "kubevela-app-web-cfg", "prod")
+	assert.NoError(t, err)
+	assert.Equal(t, []map[string]string{{"name": "k", "value": "from-cm"}}, data)
+
+	secret, err := ToSecret(&Secret{Client: c}, "kubevela-app-web-cfg", "prod", map[string]string{"k": "from-secret"})
+	assert.NoError(t, err)
+	assert.NoError(t, c.Create(context.Background(), secret))
+	assert.Equal(t, "true", secret.Annotations[AnnotationPlaintextExport])
+	data, err = GetConfigDataFromCluster(c, "kubevela-app-web-cfg", "prod")
+	assert.NoError(t, err)
+	assert.Equal(t, []map[string]string{{"name": "k", "value": "from-secret"}}, data)
+}
+
+func TestToSecretOfEncryptedConfigs(t *testing.T) {
+	c := newFakeClient(t)
+	e, err := NewEncryptor([]byte("0123456789abcdef"))
+	assert.NoError(t, err)
+	store := &Secret{Client: c, Encryptor: e}
+	_, err = ToSecret(store, "kubevela-app-web-cfg", "prod", map[string]string{"k": "v"})
+	assert.Error(t, err, "encrypted configs are not exported in plaintext by default")
+
+	store.AllowPlaintextExport = true
+	secret, err := ToSecret(store, "kubevela-app-web-cfg", "prod", map[string]string{"k": "v"})
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("v"), secret.Data["k"])
+	assert.Equal(t, "true", secret.Annotations[AnnotationPlaintextExport])
+}
@@ -25,6 +25,17 @@ type Store interface {
 	Namespace(envName string) (string, error)
 }
 
+// Manager is a config store which could also manage the config data, it's used by `vela config`
+type Manager interface {
+	Store
+	SetConfigData(configName, envName string, data map[string]string) error
+	ListConfigs(envName string) ([]string, error)
+	DeleteConfig(configName, envName string) error
+}
+
+// LabelUserConfig marks the ConfigMaps and Secrets managed by `vela config`
+const LabelUserConfig = "config.oam.dev/user-config"
+
 // TypeFake is a fake type
 const TypeFake = "fake"
 
@@ -0,0 +1,142 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package appfile
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	jsonpatch "github.com/evanphx/json-patch"
+	"github.com/pkg/errors"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
+	"github.com/oam-dev/kubevela/pkg/oam"
+)
+
+// LoadEnvironment gets the Environment that an application is deployed to.
+// The Environment is selected by the env label of the application, or else by the namespace of the application,
+// it fails if more than one Environment has the namespace.
+// It returns nil if the application doesn't belong to any Environment.
+func LoadEnvironment(ctx context.Context, c client.Reader, app *v1beta1.Application) (*v1beta1.Environment, error) {
+	if c == nil {
+		return nil, nil
+	}
+	if envName := app.GetLabels()[oam.LabelAppEnv]; envName != "" {
+		env := new(v1beta1.Environment)
+		if err := c.Get(ctx, client.ObjectKey{Name: envName}, env); err != nil {
+			if kerrors.IsNotFound(err) || meta.IsNoMatchError(err) {
+				return nil, nil
+			}
+			return nil, err
+		}
+		return env, nil
+	}
+	envs := new(v1beta1.EnvironmentList)
+	if err := c.List(ctx, envs); err != nil {
+		if kerrors.IsNotFound(err) || meta.IsNoMatchError(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var matched []*v1beta1.Environment
+	for i := range envs.Items {
+		if envs.Items[i].Spec.Namespace == app.Namespace {
+			matched = append(matched, &envs.Items[i])
+		}
+	}
+	switch len(matched) {
+	case 0:
+		return nil, nil
+	case 1:
+		return matched[0], nil
+	default:
+		names := make([]string, 0, len(matched))
+		for _, env := range matched {
+			names = append(names, env.Name)
+		}
+		sort.Strings(names)
+		return nil, errors.Errorf("namespace %s belongs to more than one environment %s, label the application with %s to select one",
+			app.Namespace, strings.Join(names, ", "), oam.LabelAppEnv)
+	}
+}
+
+// PatchComponent applies the patches of the Environment to a copy of the component.
+// Properties are merged with JSON merge patch (RFC 7386) semantics, patches are applied in order.
+func PatchComponent(env *v1beta1.Environment, comp v1beta1.ApplicationComponent) (v1beta1.ApplicationComponent, error) {
+	patched := *comp.DeepCopy()
+	if env == nil {
+		return patched, nil
+	}
+	for _, p := range env.Spec.Patches {
+		if !matchComponent(p, patched) {
+			continue
+		}
+		props, err := mergeProperties(patched.Properties, p.Properties)
+		if err != nil {
+			return patched, errors.WithMessagef(err, "patch properties of component %s by env %s", comp.Name, env.Name)
+		}
+		patched.Properties = props
+		for _, tp := range p.Traits {
+			for i := range patched.Traits {
+				if patched.Traits[i].Type != tp.Type {
+					continue
+				}
+				props, err := mergeProperties(patched.Traits[i].Properties, tp.Properties)
+				if err != nil {
+					return patched, errors.WithMessagef(err, "patch properties of trait %s of component %s by env %s", tp.Type, comp.Name, env.Name)
+				}
+				patched.Traits[i].Properties = props
+			}
+		}
+	}
+	return patched, nil
+}
+
+func matchComponent(p v1beta1.EnvironmentPatch, comp v1beta1.ApplicationComponent) bool {
+	if p.Type != "" && p.Type != comp.Type {
+		return false
+	}
+	if len(p.Components) == 0 {
+		return true
+	}
+	for _, name := range p.Components {
+		if name == comp.Name {
+			return true
+		}
+	}
+	return false
+}
+
+func mergeProperties(origin, patch runtime.RawExtension) (runtime.RawExtension, error) {
+	if len(patch.Raw) == 0 {
+		return origin, nil
+	}
+	originRaw := origin.Raw
+	if len(originRaw) == 0 {
+		originRaw = []byte("{}")
+	}
+	merged, err := jsonpatch.MergePatch(originRaw, patch.Raw)
+	if err != nil {
+		return origin, err
+	}
+	return runtime.RawExtension{Raw: merged}, nil
+}
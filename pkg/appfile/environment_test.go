@@ -0,0 +1,155 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package appfile
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"gotest.tools/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
+	"github.com/oam-dev/kubevela/pkg/oam"
+)
+
+func TestLoadEnvironment(t *testing.T) {
+	scheme := runtime.NewScheme()
+	assert.NilError(t, v1beta1.SchemeBuilder.AddToScheme(scheme))
+	prod := &v1beta1.Environment{
+		ObjectMeta: metav1.ObjectMeta{Name: "prod"},
+		Spec:       v1beta1.EnvironmentSpec{Namespace: "prod-ns"},
+	}
+	test := &v1beta1.Environment{
+		ObjectMeta: metav1.ObjectMeta{Name: "test"},
+		Spec:       v1beta1.EnvironmentSpec{Namespace: "test-ns"},
+	}
+	staging := &v1beta1.Environment{
+		ObjectMeta: metav1.ObjectMeta{Name: "staging"},
+		Spec:       v1beta1.EnvironmentSpec{Namespace: "shared-ns"},
+	}
+	preview := &v1beta1.Environment{
+		ObjectMeta: metav1.ObjectMeta{Name: "preview"},
+		Spec:       v1beta1.EnvironmentSpec{Namespace: "shared-ns"},
+	}
+	c := fake.NewFakeClientWithScheme(scheme, prod, test, staging, preview)
+
+	testCases := map[string]struct {
+		app     *v1beta1.Application
+		wantEnv string
+		wantErr string
+	}{
+		"select by label": {
+			app: &v1beta1.Application{ObjectMeta: metav1.ObjectMeta{
+				Name: "app", Namespace: "test-ns", Labels: map[string]string{oam.LabelAppEnv: "prod"}}},
+			wantEnv: "prod",
+		},
+		"select by namespace": {
+			app:     &v1beta1.Application{ObjectMeta: metav1.ObjectMeta{Name: "app", Namespace: "test-ns"}},
+			wantEnv: "test",
+		},
+		"label of a non-existing env": {
+			app: &v1beta1.Application{ObjectMeta: metav1.ObjectMeta{
+				Name: "app", Namespace: "test-ns", Labels: map[string]string{oam.LabelAppEnv: "dev"}}},
+		},
+		"no env": {
+			app: &v1beta1.Application{ObjectMeta: metav1.ObjectMeta{Name: "app", Namespace: "default"}},
+		},
+		"ambiguous namespace": {
+			app:     &v1beta1.Application{ObjectMeta: metav1.ObjectMeta{Name: "app", Namespace: "shared-ns"}},
+			wantErr: "namespace shared-ns belongs to more than one environment preview, staging, label the application with app.oam.dev/env to select one",
+		},
+		"ambiguous namespace selected by label": {
+			app: &v1beta1.Application{ObjectMeta: metav1.ObjectMeta{
+				Name: "app", Namespace: "shared-ns", Labels: map[string]string{oam.LabelAppEnv: "staging"}}},
+			wantEnv: "staging",
+		},
+	}
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			env, err := LoadEnvironment(context.Background(), c, tc.app)
+			if tc.wantErr != "" {
+				assert.Error(t, err, tc.wantErr)
+				return
+			}
+			assert.NilError(t, err)
+			if tc.wantEnv == "" {
+				assert.Assert(t, env == nil)
+				return
+			}
+			assert.Equal(t, tc.wantEnv, env.Name)
+		})
+	}
+}
+
+func TestPatchComponent(t *testing.T) {
+	env := &v1beta1.Environment{
+		ObjectMeta: metav1.ObjectMeta{Name: "prod"},
+		Spec: v1beta1.EnvironmentSpec{
+			Namespace: "prod",
+			Patches: []v1beta1.EnvironmentPatch{
+				{
+					Type:       "webservice",
+					Properties: runtime.RawExtension{Raw: []byte(`{"cpu":"1","env":null}`)},
+					Traits: []v1beta1.EnvironmentTraitPatch{{
+						Type:       "scaler",
+						Properties: runtime.RawExtension{Raw: []byte(`{"replicas":5}`)},
+					}},
+				},
+				{
+					Components: []string{"other"},
+					Properties: runtime.RawExtension{Raw: []byte(`{"image":"nginx:prod"}`)},
+				},
+			},
+		},
+	}
+	comp := v1beta1.ApplicationComponent{
+		Name:       "web",
+		Type:       "webservice",
+		Properties: runtime.RawExtension{Raw: []byte(`{"image":"nginx","env":[{"name":"DEBUG","value":"true"}]}`)},
+		Traits: []v1beta1.ApplicationTrait{
+			{Type: "scaler", Properties: runtime.RawExtension{Raw: []byte(`{"replicas":1}`)}},
+			{Type: "ingress", Properties: runtime.RawExtension{Raw: []byte(`{"domain":"test.com"}`)}},
+		},
+	}
+
+	got, err := PatchComponent(env, comp)
+	assert.NilError(t, err)
+	want := v1beta1.ApplicationComponent{
+		Name:       "web",
+		Type:       "webservice",
+		Properties: runtime.RawExtension{Raw: []byte(`{"cpu":"1","image":"nginx"}`)},
+		Traits: []v1beta1.ApplicationTrait{
+			{Type: "scaler", Properties: runtime.RawExtension{Raw: []byte(`{"replicas":5}`)}},
+			{Type: "ingress", Properties: runtime.RawExtension{Raw: []byte(`{"domain":"test.com"}`)}},
+		},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("PatchComponent(...): -want, +got:\n%s", diff)
+	}
+	// the original component must not be changed
+	assert.Equal(t, `{"replicas":1}`, string(comp.Traits[0].Properties.Raw))
+
+	got, err = PatchComponent(nil, comp)
+	assert.NilError(t, err)
+	if diff := cmp.Diff(comp, got); diff != "" {
+		t.Errorf("PatchComponent(nil, ...): -want, +got:\n%s", diff)
+	}
+}
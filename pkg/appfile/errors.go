@@ -0,0 +1,89 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package appfile
+
+import (
+	"errors"
+	"strconv"
+
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	"github.com/oam-dev/kubevela/pkg/dsl/definition"
+)
+
+// PropertyError is returned if the properties of components or traits in an application break the parameter
+// constraints of their definitions, the failures are located at the properties of the application
+type PropertyError struct {
+	Errs  field.ErrorList
+	cause error
+}
+
+// Error returns the failures on properties
+func (e *PropertyError) Error() string {
+	return e.Errs.ToAggregate().Error()
+}
+
+// Unwrap returns the error of evaluating the template
+func (e *PropertyError) Unwrap() error {
+	return e.cause
+}
+
+// PropertyErrors returns the failures on properties of an application in err, it returns nil if err is not caused
+// by the properties
+func PropertyErrors(err error) field.ErrorList {
+	var perr *PropertyError
+	if errors.As(err, &perr) {
+		return perr.Errs
+	}
+	return nil
+}
+
+// propertyError translates the failures on the parameter of a template in err to the properties at path, err is
+// returned as it is if it's not caused by the parameter or the path of properties is unknown
+func propertyError(err error, path *field.Path, properties map[string]interface{}) error {
+	var perr *definition.ParameterErrors
+	if err == nil || path == nil || !errors.As(err, &perr) {
+		return err
+	}
+	var errs field.ErrorList
+	for _, pe := range perr.Errs {
+		fieldPath, value := path, interface{}(properties)
+		for _, name := range pe.Path {
+			if list, ok := value.([]interface{}); ok {
+				if i, convErr := strconv.Atoi(name); convErr == nil {
+					fieldPath, value = fieldPath.Index(i), nil
+					if i < len(list) {
+						value = list[i]
+					}
+					continue
+				}
+			}
+			fieldPath = fieldPath.Child(name)
+			if m, ok := value.(map[string]interface{}); ok {
+				value = m[name]
+			} else {
+				value = nil
+			}
+		}
+		if pe.Required {
+			errs = append(errs, field.Required(fieldPath, ""))
+			continue
+		}
+		errs = append(errs, field.Invalid(fieldPath, value, pe.Message))
+	}
+	return &PropertyError{Errs: errs, cause: err}
+}
@@ -0,0 +1,91 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package appfile
+
+import (
+	"testing"
+
+	"github.com/pkg/errors"
+	"gotest.tools/assert"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	"github.com/oam-dev/kubevela/pkg/dsl/definition"
+	"github.com/oam-dev/kubevela/pkg/dsl/process"
+)
+
+func TestPropertyErrors(t *testing.T) {
+	wl := &Workload{
+		Name: "web",
+		FullTemplate: &Template{TemplateStr: `
+output: {
+	apiVersion: "apps/v1"
+	kind:       "Deployment"
+	spec: template: spec: containers: [{
+		image: parameter.image
+		env:   parameter.env
+	}]
+}
+parameter: {
+	image: string
+	env: [...{name: string, value: string}]
+}`},
+		Params: map[string]interface{}{
+			"env": []interface{}{map[string]interface{}{"name": 1, "value": "v"}},
+		},
+		engine: definition.NewWorkloadAbstractEngine("web", &definition.PackageDiscover{}),
+		Traits: []*Trait{{
+			Name: "expose",
+			Template: `
+outputs: service: {
+	apiVersion: "v1"
+	kind:       "Service"
+	spec: ports: [{port: parameter.port}]
+}
+parameter: port: int & <65536`,
+			Params: map[string]interface{}{"port": 80000},
+			engine: definition.NewTraitAbstractEngine("expose", &definition.PackageDiscover{}),
+		}},
+	}
+
+	ctx := process.NewContext("default", "web", "app", "app-v1")
+	err := wl.EvalContext(ctx)
+	assert.ErrorContains(t, err, "parameter.env.0.name")
+	assert.Assert(t, PropertyErrors(err) == nil)
+
+	compPath := field.NewPath("spec", "components").Index(1)
+	wl.setPropertiesPath(compPath)
+	err = wl.EvalContext(ctx)
+	assert.DeepEqual(t, PropertyErrors(errors.WithMessage(err, "evaluate base template")), field.ErrorList{
+		field.Invalid(compPath.Child("properties", "env").Index(0).Child("name"), 1,
+			"conflicting values string and 1 (mismatched types string and int)"),
+	})
+
+	wl.Params["env"] = []interface{}{map[string]interface{}{"name": "k", "value": "v"}}
+	err = wl.EvalContext(ctx)
+	assert.DeepEqual(t, PropertyErrors(err), field.ErrorList{
+		field.Required(compPath.Child("properties", "image"), ""),
+	})
+	assert.Equal(t, err.Error(), "spec.components[1].properties.image: Required value")
+
+	wl.Params["image"] = "nginx"
+	assert.NilError(t, wl.EvalContext(ctx))
+	err = wl.Traits[0].EvalContext(ctx)
+	assert.DeepEqual(t, PropertyErrors(err), field.ErrorList{
+		field.Invalid(compPath.Child("traits").Index(0).Child("properties", "port"), 80000,
+			"invalid value 80000 (out of bound int & <65536)"),
+	})
+}
@@ -61,15 +61,9 @@ func RenderHelmReleaseAndHelmRepo(helmSpec *common.Helm, compName, appName, ns s
 	helmRelease := generateUnstructuredObj(rlsName, ns, helmapi.HelmReleaseGVK)
 
 	// construct HelmRelease chart values
-	chartValues := map[string]interface{}{}
-	if releaseSpec.Values != nil {
-		if err := json.Unmarshal(releaseSpec.Values.Raw, &chartValues); err != nil {
-			return nil, nil, errors.Wrap(err, "cannot get chart values")
-		}
-	}
-	for k, v := range values {
-		// override values with settings from application
-		chartValues[k] = v
+	chartValues, err := mergeChartValues(releaseSpec, values)
+	if err != nil {
+		return nil, nil, err
 	}
 	if len(chartValues) > 0 {
 		// avoid an empty map
@@ -114,6 +108,21 @@ func setSpecObjIntoUnstructuredObj(spec interface{}, u *unstructured.Unstructure
 	return nil
 }
 
+// mergeChartValues merges the values set in the release spec with the settings from application
+func mergeChartValues(releaseSpec *helmapi.HelmReleaseSpec, values map[string]interface{}) (map[string]interface{}, error) {
+	chartValues := map[string]interface{}{}
+	if releaseSpec.Values != nil {
+		if err := json.Unmarshal(releaseSpec.Values.Raw, &chartValues); err != nil {
+			return nil, errors.Wrap(err, "cannot get chart values")
+		}
+	}
+	for k, v := range values {
+		// override values with settings from application
+		chartValues[k] = v
+	}
+	return chartValues, nil
+}
+
 func decodeHelmSpec(h *common.Helm) (*helmapi.HelmReleaseSpec, *helmapi.HelmRepositorySpec, error) {
 	releaseSpec := &helmapi.HelmReleaseSpec{}
 	if err := json.Unmarshal(h.Release.Raw, releaseSpec); err != nil {
@@ -0,0 +1,277 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package helm
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/ghodss/yaml"
+	"github.com/pkg/errors"
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/chart/loader"
+	"helm.sh/helm/v3/pkg/chartutil"
+	"helm.sh/helm/v3/pkg/engine"
+	"helm.sh/helm/v3/pkg/getter"
+	"helm.sh/helm/v3/pkg/releaseutil"
+	"helm.sh/helm/v3/pkg/repo"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/common"
+)
+
+const (
+	// RenderModeFlux renders a Helm module into Flux2 HelmRelease and HelmRepository, this is the default mode
+	RenderModeFlux = "flux"
+	// RenderModeInProcess templates the chart of a Helm module in-process, the rendered manifests are
+	// applied as the workload and its auxiliary resources
+	RenderModeInProcess = "inprocess"
+)
+
+const (
+	fileRepoPrefix = "file://"
+	ociRepoPrefix  = "oci://"
+	notesFileName  = "NOTES.txt"
+)
+
+// ChartCacheDir is the directory to cache charts fetched for in-process rendering,
+// charts are cached by repository, name and version.
+var ChartCacheDir = filepath.Join(os.TempDir(), "vela-helm-charts")
+
+// chartClient fetches charts from chart repositories, the timeout keeps an unresponsive repository from
+// blocking the reconciliation
+var chartClient = &http.Client{Timeout: 2 * time.Minute}
+
+// RenderChart templates the chart of a Helm module in-process and returns the rendered manifests.
+// Hooks and NOTES.txt of the chart are dropped as they are only meaningful to Helm itself.
+func RenderChart(ctx context.Context, helmSpec *common.Helm, compName, appName, ns string, values map[string]interface{}) ([]*unstructured.Unstructured, error) {
+	releaseSpec, repoSpec, err := decodeHelmSpec(helmSpec)
+	if err != nil {
+		return nil, errors.WithMessage(err, "Helm spec is invalid")
+	}
+	chartSpec := releaseSpec.Chart.Spec
+	ch, err := LoadChart(ctx, repoSpec.URL, chartSpec.Chart, chartSpec.Version)
+	if err != nil {
+		return nil, err
+	}
+	chartValues, err := mergeChartValues(releaseSpec, values)
+	if err != nil {
+		return nil, err
+	}
+	rlsName := releaseSpec.ReleaseName
+	if rlsName == "" {
+		rlsName = fmt.Sprintf("%s-%s", appName, compName)
+	}
+	targetNamespace := releaseSpec.TargetNamespace
+	if targetNamespace == "" {
+		targetNamespace = ns
+	}
+
+	if err := chartutil.ProcessDependencies(ch, chartValues); err != nil {
+		return nil, errors.Wrapf(err, "cannot process dependencies of chart %s", ch.Name())
+	}
+	renderValues, err := chartutil.ToRenderValues(ch, chartValues, chartutil.ReleaseOptions{
+		Name:      rlsName,
+		Namespace: targetNamespace,
+		Revision:  1,
+		IsInstall: true,
+	}, chartutil.DefaultCapabilities)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid values for chart %s", ch.Name())
+	}
+	files, err := engine.Render(ch, renderValues)
+	if err != nil {
+		return nil, errors.Wrapf(err, "cannot render chart %s", ch.Name())
+	}
+	for name := range files {
+		if strings.HasSuffix(name, notesFileName) {
+			delete(files, name)
+		}
+	}
+	_, manifests, err := releaseutil.SortManifests(files, chartutil.DefaultVersionSet, releaseutil.InstallOrder)
+	if err != nil {
+		return nil, errors.Wrapf(err, "cannot parse manifests rendered from chart %s", ch.Name())
+	}
+
+	var objs []*unstructured.Unstructured
+	for _, m := range manifests {
+		obj := &unstructured.Unstructured{}
+		if err := yaml.Unmarshal([]byte(m.Content), &obj.Object); err != nil {
+			return nil, errors.Wrapf(err, "cannot decode manifest %s", m.Name)
+		}
+		if len(obj.Object) == 0 {
+			continue
+		}
+		objs = append(objs, obj)
+	}
+	return objs, nil
+}
+
+// LoadChart loads a chart from a repository or a local chart cache.
+// If the repoURL is prefixed with "oci://", the chart must have been exported into the OCI chart cache
+// as <ChartCacheDir>/oci/<repo>/<chart>-<version>.tgz.
+// Otherwise the chart is fetched from the chart repository and cached by version.
+// Local charts are rejected, the chart is loaded by the controller so it must not read files of the controller.
+func LoadChart(ctx context.Context, repoURL, chartName, version string) (*chart.Chart, error) {
+	switch {
+	case repoURL == "" || strings.HasPrefix(repoURL, fileRepoPrefix):
+		return nil, errors.Errorf("local chart %s is not allowed, the chart must be served by a chart repository", chartName)
+	case strings.HasPrefix(repoURL, ociRepoPrefix):
+		if !isExactVersion(version) {
+			return nil, errors.Errorf("an exact version is required for OCI chart %s", chartName)
+		}
+		path := filepath.Join(ChartCacheDir, "oci", strings.TrimPrefix(repoURL, ociRepoPrefix), chartArchiveName(chartName, version))
+		if _, err := os.Stat(path); err != nil {
+			return nil, errors.Wrapf(err, "chart %s:%s is not found in the OCI chart cache", chartName, version)
+		}
+		return loadLocalChart(path, version)
+	default:
+		return loadRepoChart(ctx, repoURL, chartName, version)
+	}
+}
+
+func loadLocalChart(path, version string) (*chart.Chart, error) {
+	ch, err := loader.Load(path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "cannot load chart from %s", path)
+	}
+	if isExactVersion(version) && ch.Metadata.Version != version {
+		return nil, errors.Errorf("version of chart %s is %s, but %s is required", path, ch.Metadata.Version, version)
+	}
+	return ch, nil
+}
+
+func loadRepoChart(ctx context.Context, repoURL, chartName, version string) (*chart.Chart, error) {
+	cacheDir := filepath.Join(ChartCacheDir, repoCacheName(repoURL))
+	if isExactVersion(version) {
+		cached := filepath.Join(cacheDir, chartArchiveName(chartName, version))
+		if _, err := os.Stat(cached); err == nil {
+			return loadLocalChart(cached, version)
+		}
+	}
+
+	url, err := repo.FindChartInRepoURL(repoURL, chartName, version, "", "", "", chartGetters(ctx))
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot find Chart URL")
+	}
+	data, err := fetchChart(ctx, url)
+	if err != nil {
+		return nil, err
+	}
+	ch, err := loader.LoadArchive(bytes.NewReader(data))
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot load Chart archive")
+	}
+	// the cache is only an optimization, failing to write it shouldn't fail the rendering
+	_ = writeChartCache(filepath.Join(cacheDir, chartArchiveName(chartName, ch.Metadata.Version)), data)
+	return ch, nil
+}
+
+// chartGetters returns the getters fetching chart repositories by the chartClient, the requests are canceled with ctx
+func chartGetters(ctx context.Context) getter.Providers {
+	return getter.Providers{
+		getter.Provider{
+			Schemes: []string{"http", "https"},
+			New: func(...getter.Option) (getter.Getter, error) {
+				return &chartGetter{ctx: ctx}, nil
+			},
+		},
+	}
+}
+
+// chartGetter implements getter.Getter by the chartClient, the options of Helm are ignored as repositories
+// are accessed anonymously
+type chartGetter struct {
+	ctx context.Context
+}
+
+// Get implements getter.Getter
+func (g *chartGetter) Get(url string, _ ...getter.Option) (*bytes.Buffer, error) {
+	data, err := fetchChart(g.ctx, url)
+	if err != nil {
+		return nil, err
+	}
+	return bytes.NewBuffer(data), nil
+}
+
+func fetchChart(ctx context.Context, url string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := chartClient.Do(req)
+	if err != nil {
+		return nil, errors.Wrapf(err, "cannot fetch Chart from remote URL:%s", url)
+	}
+	//nolint:errcheck
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, errors.Errorf("cannot fetch Chart from remote URL:%s, status: %s", url, resp.Status)
+	}
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, errors.Wrapf(err, "cannot fetch Chart from remote URL:%s", url)
+	}
+	return data, nil
+}
+
+// writeChartCache writes the chart archive through a temp file so readers never see a partial archive
+func writeChartCache(path string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0750); err != nil {
+		return err
+	}
+	tmp, err := ioutil.TempFile(filepath.Dir(path), ".chart-")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		_ = os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		_ = os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+func repoCacheName(repoURL string) string {
+	sum := sha256.Sum256([]byte(strings.TrimSuffix(repoURL, "/")))
+	return hex.EncodeToString(sum[:8])
+}
+
+func chartArchiveName(chartName, version string) string {
+	return fmt.Sprintf("%s-%s.tgz", filepath.Base(chartName), version)
+}
+
+// isExactVersion checks whether the version is an exact version rather than a constraint such as ">=1.0.0" or "1.x"
+func isExactVersion(version string) bool {
+	if version == "" || strings.ContainsAny(version, "<>=~^*|, ") {
+		return false
+	}
+	return !strings.HasSuffix(version, ".x") && !strings.HasSuffix(version, ".X")
+}
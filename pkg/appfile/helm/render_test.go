@@ -0,0 +1,158 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package helm
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"helm.sh/helm/v3/pkg/chart/loader"
+	"helm.sh/helm/v3/pkg/chartutil"
+	"helm.sh/helm/v3/pkg/repo"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func setChartCacheDir(t *testing.T) func() {
+	dir, err := ioutil.TempDir("", "vela-chart-cache")
+	assert.NoError(t, err)
+	origin := ChartCacheDir
+	ChartCacheDir = dir
+	return func() {
+		ChartCacheDir = origin
+		_ = os.RemoveAll(dir)
+	}
+}
+
+// serveChartRepo serves the testdata chart in a chart repository, requests counts the requests to the repository
+func serveChartRepo(t *testing.T) (server *httptest.Server, requests *int, cleanup func()) {
+	ch, err := loader.Load("testdata/hello")
+	assert.NoError(t, err)
+	repoDir, err := ioutil.TempDir("", "vela-chart-repo")
+	assert.NoError(t, err)
+	_, err = chartutil.Save(ch, repoDir)
+	assert.NoError(t, err)
+
+	requests = new(int)
+	fileServer := http.FileServer(http.Dir(repoDir))
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*requests++
+		fileServer.ServeHTTP(w, r)
+	}))
+	index := repo.NewIndexFile()
+	index.Add(ch.Metadata, "hello-0.1.0.tgz", server.URL, "")
+	assert.NoError(t, index.WriteFile(filepath.Join(repoDir, "index.yaml"), 0644))
+	return server, requests, func() {
+		server.Close()
+		_ = os.RemoveAll(repoDir)
+	}
+}
+
+func TestRenderChart(t *testing.T) {
+	defer setChartCacheDir(t)()
+	server, _, cleanup := serveChartRepo(t)
+	defer cleanup()
+
+	h := testData("hello", "0.1.0", server.URL)
+	objs, err := RenderChart(context.Background(), h, "web", "myapp", "default", map[string]interface{}{"replicas": 3})
+	assert.NoError(t, err)
+	// the test hook and NOTES.txt are dropped, manifests are sorted in install order
+	assert.Equal(t, 2, len(objs))
+	assert.Equal(t, "Service", objs[0].GetKind())
+	assert.Equal(t, "myapp-web", objs[0].GetName())
+	assert.Equal(t, "Deployment", objs[1].GetKind())
+	replicas, _, _ := unstructured.NestedFieldNoCopy(objs[1].Object, "spec", "replicas")
+	assert.EqualValues(t, 3, replicas)
+	containers, _, _ := unstructured.NestedSlice(objs[1].Object, "spec", "template", "spec", "containers")
+	assert.Equal(t, "nginx:1.19", containers[0].(map[string]interface{})["image"])
+
+	_, err = RenderChart(context.Background(), testData("hello", "0.2.0", server.URL), "web", "myapp", "default", nil)
+	assert.Error(t, err)
+}
+
+func TestLoadLocalChartIsRejected(t *testing.T) {
+	_, err := LoadChart(context.Background(), "file://testdata", "hello", "0.1.0")
+	assert.Error(t, err)
+	_, err = LoadChart(context.Background(), "", "testdata/hello", "0.1.0")
+	assert.Error(t, err)
+}
+
+func TestLoadChartFromOCICache(t *testing.T) {
+	defer setChartCacheDir(t)()
+	_, err := LoadChart(context.Background(), "oci://registry.example.com/charts", "hello", "0.1.0")
+	assert.Error(t, err)
+	_, err = LoadChart(context.Background(), "oci://registry.example.com/charts", "hello", ">0.1.0")
+	assert.Error(t, err)
+
+	ch, err := loader.Load("testdata/hello")
+	assert.NoError(t, err)
+	ociDir := filepath.Join(ChartCacheDir, "oci", "registry.example.com", "charts")
+	assert.NoError(t, os.MkdirAll(ociDir, 0750))
+	_, err = chartutil.Save(ch, ociDir)
+	assert.NoError(t, err)
+	loaded, err := LoadChart(context.Background(), "oci://registry.example.com/charts", "hello", "0.1.0")
+	assert.NoError(t, err)
+	assert.Equal(t, "hello", loaded.Name())
+}
+
+func TestLoadChartFromRepo(t *testing.T) {
+	defer setChartCacheDir(t)()
+	server, requests, cleanup := serveChartRepo(t)
+	defer cleanup()
+
+	loaded, err := LoadChart(context.Background(), server.URL, "hello", ">=0.1.0")
+	assert.NoError(t, err)
+	assert.Equal(t, "0.1.0", loaded.Metadata.Version)
+	assert.Equal(t, 2, *requests)
+	server.Close()
+
+	// the chart is cached by version, no more request is needed
+	loaded, err = LoadChart(context.Background(), server.URL, "hello", "0.1.0")
+	assert.NoError(t, err)
+	assert.Equal(t, "hello", loaded.Name())
+	assert.Equal(t, 2, *requests)
+}
+
+func TestLoadChartIsCanceled(t *testing.T) {
+	defer setChartCacheDir(t)()
+	server, _, cleanup := serveChartRepo(t)
+	defer cleanup()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := LoadChart(ctx, server.URL, "hello", "0.1.0")
+	assert.Error(t, err)
+}
+
+func TestIsExactVersion(t *testing.T) {
+	for version, exact := range map[string]bool{
+		"":              false,
+		"1.0.0":         true,
+		"1.0.0-alpha.1": true,
+		">=1.0.0":       false,
+		"~1.0":          false,
+		"1.x":           false,
+		"1.0.0 - 2.0.0": false,
+	} {
+		assert.Equal(t, exact, isExactVersion(version), version)
+	}
+}
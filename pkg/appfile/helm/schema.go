@@ -21,7 +21,6 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"net/http"
 
 	"cuelang.org/go/cue"
 	"cuelang.org/go/cue/format"
@@ -30,21 +29,11 @@ import (
 	"github.com/getkin/kin-openapi/openapi3"
 	"github.com/pkg/errors"
 	"helm.sh/helm/v3/pkg/chart/loader"
-	"helm.sh/helm/v3/pkg/getter"
 	"helm.sh/helm/v3/pkg/repo"
 
 	"github.com/oam-dev/kubevela/apis/core.oam.dev/common"
 )
 
-var (
-	getters = getter.Providers{
-		getter.Provider{
-			Schemes: []string{"http", "https"},
-			New:     getter.NewHTTPGetter,
-		},
-	}
-)
-
 // GetChartValuesJSONSchema fetched the Chart bundle and get JSON schema of Values
 // file.  If the Chart provides a 'values.json.schema' file, use it directly.
 // Otherwise, try to generate a JSON schema based on the Values file.
@@ -137,21 +126,15 @@ func generateSchemaFromValues(values []byte) ([]byte, error) {
 }
 
 func loadChartFiles(ctx context.Context, repoURL, chart, version string) ([]*loader.BufferedFile, error) {
-	url, err := repo.FindChartInRepoURL(repoURL, chart, version, "", "", "", getters)
+	url, err := repo.FindChartInRepoURL(repoURL, chart, version, "", "", "", chartGetters(ctx))
 	if err != nil {
 		return nil, errors.Wrap(err, "cannot find Chart URL")
 	}
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	data, err := fetchChart(ctx, url)
 	if err != nil {
 		return nil, err
 	}
-	resp, err := http.DefaultClient.Do(req)
-	if err != nil {
-		return nil, errors.Wrapf(err, "cannot fetch Chart from remote URL:%s", url)
-	}
-	//nolint:errcheck
-	defer resp.Body.Close()
-	files, err := loader.LoadArchiveFiles(resp.Body)
+	files, err := loader.LoadArchiveFiles(bytes.NewReader(data))
 	if err != nil {
 		return nil, errors.Wrap(err, "cannot load Chart files")
 	}
@@ -0,0 +1,202 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package appfile
+
+import (
+	"encoding/json"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/crossplane/crossplane-runtime/pkg/fieldpath"
+	"github.com/pkg/errors"
+
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/common"
+)
+
+// wildcard matches all elements of an array or all fields of an object in the field path of kube parameter
+const wildcard = "*"
+
+// checkKubeParameterType checks the value matches the type of kube parameter
+func checkKubeParameterType(valueType common.ParameterValueType, value interface{}) error {
+	var ok bool
+	switch valueType {
+	case common.StringType:
+		_, ok = value.(string)
+	case common.NumberType:
+		_, ok = toFloat64(value)
+	case common.BooleanType:
+		_, ok = value.(bool)
+	case common.ObjectType:
+		_, ok = value.(map[string]interface{})
+	case common.ArrayType:
+		ok = value != nil && reflect.TypeOf(value).Kind() == reflect.Slice
+	default:
+		ok = true
+	}
+	if !ok {
+		return errors.Errorf(errInvalidValueType, valueType)
+	}
+	return nil
+}
+
+// validateKubeParameter checks the value of kube parameter against its type and constraints
+func validateKubeParameter(p *common.KubeParameter, value interface{}) error {
+	if err := checkKubeParameterType(p.ValueType, value); err != nil {
+		return errors.WithMessagef(err, "invalid parameter %q", p.Name)
+	}
+	if len(p.Enum) > 0 {
+		matched := false
+		for _, e := range p.Enum {
+			if jsonEqual(e.Raw, value) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			enum := make([]string, 0, len(p.Enum))
+			for _, e := range p.Enum {
+				enum = append(enum, string(e.Raw))
+			}
+			return errors.Errorf("parameter %q must be one of [%s]", p.Name, strings.Join(enum, ", "))
+		}
+	}
+	if p.Pattern != nil {
+		if s, ok := value.(string); ok {
+			re, err := regexp.Compile(*p.Pattern)
+			if err != nil {
+				return errors.Wrapf(err, "invalid pattern of parameter %q", p.Name)
+			}
+			if !re.MatchString(s) {
+				return errors.Errorf("parameter %q must match pattern %q", p.Name, *p.Pattern)
+			}
+		}
+	}
+	if n, ok := toFloat64(value); ok {
+		if p.Minimum != nil && n < float64(*p.Minimum) {
+			return errors.Errorf("parameter %q must be greater than or equal to %d", p.Name, *p.Minimum)
+		}
+		if p.Maximum != nil && n > float64(*p.Maximum) {
+			return errors.Errorf("parameter %q must be less than or equal to %d", p.Name, *p.Maximum)
+		}
+	}
+	return nil
+}
+
+func toFloat64(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case int:
+		return float64(n), true
+	case int8:
+		return float64(n), true
+	case int16:
+		return float64(n), true
+	case int32:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case uint:
+		return float64(n), true
+	case uint8:
+		return float64(n), true
+	case uint16:
+		return float64(n), true
+	case uint32:
+		return float64(n), true
+	case uint64:
+		return float64(n), true
+	case float32:
+		return float64(n), true
+	case float64:
+		return n, true
+	}
+	return 0, false
+}
+
+// jsonEqual compares a JSON encoded value with a value decoded from JSON or set in Go
+func jsonEqual(raw []byte, value interface{}) bool {
+	var expected, actual interface{}
+	if err := json.Unmarshal(raw, &expected); err != nil {
+		return false
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return false
+	}
+	if err := json.Unmarshal(data, &actual); err != nil {
+		return false
+	}
+	return reflect.DeepEqual(expected, actual)
+}
+
+// expandFieldPath expands wildcards in the field path to the existing elements of arrays or fields of objects, e.g.
+// spec.containers[*].image is expanded to spec.containers[0].image and spec.containers[1].image
+func expandFieldPath(obj map[string]interface{}, path string) ([]string, error) {
+	if !strings.Contains(path, wildcard) {
+		return []string{path}, nil
+	}
+	segments, err := fieldpath.Parse(path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "cannot parse path %q", path)
+	}
+	var paths []string
+	var expand func(v interface{}, prefix, rest fieldpath.Segments)
+	expand = func(v interface{}, prefix, rest fieldpath.Segments) {
+		if len(rest) == 0 {
+			paths = append(paths, prefix.String())
+			return
+		}
+		// copy prefix as it's shared by siblings
+		next := func(seg fieldpath.Segment) fieldpath.Segments {
+			return append(append(fieldpath.Segments{}, prefix...), seg)
+		}
+		seg := rest[0]
+		if seg.Type == fieldpath.SegmentField && seg.Field == wildcard {
+			switch val := v.(type) {
+			case []interface{}:
+				for i, item := range val {
+					expand(item, next(fieldpath.Segment{Type: fieldpath.SegmentIndex, Index: uint(i)}), rest[1:])
+				}
+			case map[string]interface{}:
+				keys := make([]string, 0, len(val))
+				for k := range val {
+					keys = append(keys, k)
+				}
+				sort.Strings(keys)
+				for _, k := range keys {
+					expand(val[k], next(fieldpath.Field(k)), rest[1:])
+				}
+			}
+			return
+		}
+		var child interface{}
+		switch val := v.(type) {
+		case []interface{}:
+			if seg.Type == fieldpath.SegmentIndex && int(seg.Index) < len(val) {
+				child = val[seg.Index]
+			}
+		case map[string]interface{}:
+			if seg.Type == fieldpath.SegmentField {
+				child = val[seg.Field]
+			}
+		}
+		expand(child, next(seg), rest[1:])
+	}
+	expand(obj, nil, segments)
+	return paths, nil
+}
@@ -26,6 +26,7 @@ import (
 	v1 "k8s.io/api/core/v1"
 	kerrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation/field"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1alpha2"
@@ -86,15 +87,30 @@ func (p *Parser) GenerateAppFile(ctx context.Context, app *v1beta1.Application)
 	ns := app.Namespace
 	appName := app.Name
 
+	env, err := LoadEnvironment(ctx, p.client, app)
+	if err != nil {
+		return nil, errors.WithMessagef(err, "load environment of application %s", appName)
+	}
+	// user configs are stored in the namespace of the env, it's the same as the namespace of app without an env
+	envName := ns
+	if env != nil {
+		envName = env.Name
+	}
+
 	appfile := new(Appfile)
 	appfile.Name = appName
 	appfile.Namespace = ns
 	var wds []*Workload
-	for _, comp := range app.Spec.Components {
-		wd, err := p.parseWorkload(ctx, comp, appName, ns)
+	for i, comp := range app.Spec.Components {
+		comp, err := PatchComponent(env, comp)
 		if err != nil {
 			return nil, err
 		}
+		wd, err := p.parseWorkload(ctx, comp, appName, ns, envName)
+		if err != nil {
+			return nil, err
+		}
+		wd.setPropertiesPath(field.NewPath("spec", "components").Index(i))
 		wds = append(wds, wd)
 	}
 	appfile.Workloads = wds
@@ -103,7 +119,7 @@ func (p *Parser) GenerateAppFile(ctx context.Context, app *v1beta1.Application)
 
 // parseWorkload resolve an ApplicationComponent and generate a Workload
 // containing ALL information required by an Appfile.
-func (p *Parser) parseWorkload(ctx context.Context, comp v1beta1.ApplicationComponent, appName, ns string) (*Workload, error) {
+func (p *Parser) parseWorkload(ctx context.Context, comp v1beta1.ApplicationComponent, appName, ns, envName string) (*Workload, error) {
 	templ, err := p.tmplLoader.LoadTemplate(ctx, p.dm, p.client, comp.Type, types.TypeComponentDefinition)
 	if err != nil && !kerrors.IsNotFound(err) {
 		return nil, errors.WithMessagef(err, "fetch type of %s", comp.Name)
@@ -125,6 +141,7 @@ func (p *Parser) parseWorkload(ctx context.Context, comp v1beta1.ApplicationComp
 		FullTemplate:       templ,
 		Params:             settings,
 		engine:             definition.NewWorkloadAbstractEngine(comp.Name, p.pd),
+		DeletionPolicy:     comp.DeletionPolicy,
 	}
 
 	if workload.IsCloudResourceConsumer() {
@@ -137,10 +154,7 @@ func (p *Parser) parseWorkload(ctx context.Context, comp v1beta1.ApplicationComp
 
 	userConfig := workload.GetUserConfigName()
 	if userConfig != "" {
-		cg := config.Configmap{Client: p.client}
-		// TODO(wonderflow): envName should not be namespace when we have serverside env
-		var envName = ns
-		data, err := cg.GetConfigData(config.GenConfigMapName(appName, workload.Name, userConfig), envName)
+		data, err := config.GetConfigDataFromCluster(p.client, config.GenConfigMapName(appName, workload.Name, userConfig), envName)
 		if err != nil {
 			return nil, errors.Wrapf(err, "get config=%s for app=%s in namespace=%s", userConfig, appName, ns)
 		}
@@ -156,6 +170,10 @@ func (p *Parser) parseWorkload(ctx context.Context, comp v1beta1.ApplicationComp
 		if err != nil {
 			return nil, errors.WithMessagef(err, "component(%s) parse trait(%s)", comp.Name, traitValue.Type)
 		}
+		trait.DeletionPolicy = traitValue.DeletionPolicy
+		if trait.DeletionPolicy == "" {
+			trait.DeletionPolicy = comp.DeletionPolicy
+		}
 
 		workload.Traits = append(workload.Traits, trait)
 	}
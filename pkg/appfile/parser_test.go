@@ -246,6 +246,7 @@ var _ = Describe("Test application parser", func() {
 				}
 				return nil
 			},
+			MockList: test.NewMockListFn(nil),
 		}
 
 		appfile, err := NewApplicationParser(&tclient, dm, pd).GenerateAppFile(context.TODO(), &o)
@@ -384,7 +385,7 @@ var _ = Describe("Test appFile parser", func() {
 			Data:       map[string]string{"c1": "v1", "c2": "v2"},
 		}
 		Expect(k8sClient.Create(context.Background(), cm.DeepCopy())).Should(SatisfyAny(BeNil(), &util.AlreadyExistMatcher{}))
-		ac, components, err := TestApp.GenerateApplicationConfiguration()
+		ac, components, err := TestApp.GenerateApplicationConfiguration(context.Background())
 		Expect(err).To(BeNil())
 		manuscaler := util.Object2RawExtension(&unstructured.Unstructured{
 			Object: map[string]interface{}{
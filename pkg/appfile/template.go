@@ -54,6 +54,7 @@ type Template struct {
 	CapabilityCategory types.CapabilityCategory
 	Reference          common.WorkloadGVK
 	Helm               *common.Helm
+	HelmRenderMode     string
 	Kube               *common.Kube
 	Terraform          *common.Terraform
 	// TODO: Add scope definition too
@@ -174,6 +175,7 @@ func newTemplateOfCompDefinition(compDef *v1beta1.ComponentDefinition) (*Templat
 	if compDef.Annotations["type"] == string(types.TerraformCategory) {
 		tmpl.CapabilityCategory = types.TerraformCategory
 	}
+	tmpl.HelmRenderMode = compDef.Annotations[types.AnnHelmRenderMode]
 	return tmpl, nil
 }
 
@@ -194,6 +196,7 @@ func newTemplateOfWorkloadDefinition(wlDef *v1beta1.WorkloadDefinition) (*Templa
 	if err := loadSchematicToTemplate(tmpl, wlDef.Spec.Status, wlDef.Spec.Schematic, wlDef.Spec.Extension); err != nil {
 		return nil, errors.WithMessage(err, "cannot load template")
 	}
+	tmpl.HelmRenderMode = wlDef.Annotations[types.AnnHelmRenderMode]
 	return tmpl, nil
 }
 
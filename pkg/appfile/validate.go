@@ -28,7 +28,14 @@ import (
 // ValidateCUESchematicAppfile validates CUE schematic workloads in an Appfile
 func (p *Parser) ValidateCUESchematicAppfile(a *Appfile) error {
 	for _, wl := range a.Workloads {
-		// because helm & kube schematic has no CUE template
+		// kube schematic has no CUE template, its properties are validated against the parameters
+		if wl.CapabilityCategory == types.KubeCategory && wl.FullTemplate != nil && wl.FullTemplate.Kube != nil {
+			if _, err := resolveKubeParameters(wl.FullTemplate.Kube.Parameters, wl.Params); err != nil {
+				return errors.WithMessagef(err, "invalid properties of component %q", wl.Name)
+			}
+			continue
+		}
+		// because helm schematic has no CUE template
 		// it only validates CUE schematic workload
 		if wl.CapabilityCategory != types.CUECategory {
 			continue
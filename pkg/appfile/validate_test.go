@@ -20,7 +20,9 @@ import (
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/ginkgo/extensions/table"
 	. "github.com/onsi/gomega"
+	"k8s.io/utils/pointer"
 
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/common"
 	"github.com/oam-dev/kubevela/apis/types"
 	"github.com/oam-dev/kubevela/pkg/dsl/definition"
 )
@@ -146,3 +148,33 @@ var _ = Describe("Test validate CUE schematic Appfile", func() {
 		}),
 	)
 })
+
+var _ = Describe("Test validate kube schematic Appfile", func() {
+	It("properties break the constraints of parameters", func() {
+		p := &Parser{}
+		af := &Appfile{
+			Name: "myapp",
+			Workloads: []*Workload{{
+				Name:               "myweb",
+				CapabilityCategory: types.KubeCategory,
+				Params:             map[string]interface{}{"replicas": 20},
+				FullTemplate: &Template{
+					Kube: &common.Kube{
+						Parameters: []common.KubeParameter{{
+							Name:       "replicas",
+							ValueType:  common.NumberType,
+							FieldPaths: []string{"spec.replicas"},
+							Maximum:    pointer.Int64Ptr(10),
+						}},
+					},
+				},
+			}},
+		}
+		err := p.ValidateCUESchematicAppfile(af)
+		Expect(err).Should(HaveOccurred())
+		Expect(err.Error()).Should(Equal(`invalid properties of component "myweb": parameter "replicas" must be less than or equal to 10`))
+
+		af.Workloads[0].Params["replicas"] = 3
+		Expect(p.ValidateCUESchematicAppfile(af)).Should(Succeed())
+	})
+})
@@ -18,13 +18,13 @@ package build
 
 import (
 	"encoding/json"
+	"fmt"
 	"io"
 	"os/exec"
 	"strings"
 
 	"github.com/pkg/errors"
 
-	"github.com/oam-dev/kubevela/pkg/builtin/kind"
 	"github.com/oam-dev/kubevela/pkg/builtin/registry"
 	cmdutil "github.com/oam-dev/kubevela/pkg/utils/util"
 )
@@ -33,6 +33,8 @@ func init() {
 	registry.RegisterTask("build", ImageBuildHandler)
 }
 
+// ImageBuildHandler builds the image of a service with the builder in build section and pushes it, the image of
+// the service is pinned by digest if the digest of the pushed image is known
 func ImageBuildHandler(ctx registry.CallCtx, params interface{}) error {
 	pm, err := json.Marshal(params)
 	if err != nil {
@@ -50,19 +52,41 @@ func ImageBuildHandler(ctx registry.CallCtx, params interface{}) error {
 	if !ok {
 		return errors.New("image must be 'string'")
 	}
-	if err := b.buildImage(ctx.IO(), image); err != nil {
+	builder, err := b.builder()
+	if err != nil {
+		return err
+	}
+	io := ctx.IO()
+	layout, err := builder.Build(io, image)
+	if err != nil {
 		return err
 	}
-	if err := b.pushImage(ctx.IO(), image); err != nil {
+	var digest string
+	if layout != "" {
+		digest, err = b.pushLayout(io, layout, image)
+	} else {
+		digest, err = b.pushImage(io, image)
+	}
+	if err != nil {
 		return err
 	}
+	if digest != "" {
+		pinned := PinDigest(image, digest)
+		io.Infof("image (%s) is pinned as %s\n", image, pinned)
+		ctx.Fill("image", pinned)
+	}
 	return nil
 }
 
 // Build defines the build section of AppFile
 type Build struct {
-	Push   Push   `json:"push,omitempty"`
-	Docker Docker `json:"docker,omitempty"`
+	// Builder is the name of image builder, one of docker (default), buildkit and pack
+	Builder    string     `json:"builder,omitempty"`
+	Push       Push       `json:"push,omitempty"`
+	Docker     Docker     `json:"docker,omitempty"`
+	Buildpacks Buildpacks `json:"buildpacks,omitempty"`
+	// Output is the directory that daemonless builders write the OCI image layout to
+	Output string `json:"output,omitempty"`
 }
 
 // Docker defines the docker build section
@@ -71,12 +95,65 @@ type Docker struct {
 	Context string `json:"context"`
 }
 
+// Buildpacks defines the section to build image with Cloud Native Buildpacks
+type Buildpacks struct {
+	Builder string `json:"builder,omitempty"`
+	Path    string `json:"path,omitempty"`
+}
+
 // Push defines where to push your image
 type Push struct {
 	Local    string `json:"local,omitempty"`
 	Registry string `json:"registry,omitempty"`
 }
 
+// Builder builds the image of a service from source code
+type Builder interface {
+	// Build builds the image and returns the directory of OCI image layout that the image is written to, the image
+	// is stored in the local docker daemon if the directory is empty
+	Build(io cmdutil.IOStreams, image string) (string, error)
+}
+
+// BuilderFactory creates a builder with the build section of a service
+type BuilderFactory func(b *Build) Builder
+
+var builders = map[string]BuilderFactory{}
+
+// RegisterBuilder registers an image builder which can be used by the builder field of build section
+func RegisterBuilder(name string, factory BuilderFactory) {
+	builders[name] = factory
+}
+
+func (b *Build) builder() (Builder, error) {
+	name := b.Builder
+	if name == "" {
+		name = BuilderDocker
+	}
+	factory, ok := builders[name]
+	if !ok {
+		return nil, fmt.Errorf("image builder %s is not supported", name)
+	}
+	return factory(b), nil
+}
+
+// PinDigest replaces the tag of image with digest, e.g. oamdev/testapp:v1 is pinned as oamdev/testapp@sha256:...
+func PinDigest(image, digest string) string {
+	return repository(image) + "@" + digest
+}
+
+// repository returns the image name without tag or digest
+func repository(image string) string {
+	repo := image
+	if i := strings.Index(repo, "@"); i >= 0 {
+		repo = repo[:i]
+	}
+	// the colon of registry host with port is followed by a slash
+	if i := strings.LastIndex(repo, ":"); i > strings.LastIndex(repo, "/") {
+		repo = repo[:i]
+	}
+	return repo
+}
+
 func asyncLog(reader io.Reader, stream cmdutil.IOStreams) {
 	cache := ""
 	buf := make([]byte, 1024)
@@ -98,66 +175,28 @@ func asyncLog(reader io.Reader, stream cmdutil.IOStreams) {
 	}
 }
 
-// buildImage will build a image with name and context.
-func (b *Build) buildImage(io cmdutil.IOStreams, image string) error {
-	//nolint:gosec
-	// keep docker binary command due to the issue #416 https://github.com/oam-dev/kubevela/issues/416
-	cmd := exec.Command("docker", "build", "-t", image, "-f", b.Docker.File, b.Docker.Context)
-	stdout, err := cmd.StdoutPipe()
-	if err != nil {
-		io.Errorf("BuildImage exec command error, message:%s\n", err.Error())
-		return err
-	}
-	stderr, err := cmd.StderrPipe()
-	if err != nil {
-		io.Errorf("BuildImage exec command error, message:%s\n", err.Error())
-		return err
-	}
-	if err := cmd.Start(); err != nil {
-		io.Errorf("BuildImage exec command error, message:%s\n", err.Error())
-		return err
-	}
-	go asyncLog(stdout, io)
-	go asyncLog(stderr, io)
-	if err := cmd.Wait(); err != nil {
-		io.Errorf("BuildImage wait for command execution error:%s", err.Error())
-		return err
-	}
-	return nil
-}
-
-func (b *Build) pushImage(io cmdutil.IOStreams, image string) error {
-	io.Infof("pushing image (%s)...\n", image)
-	switch {
-	case b.Push.Local == "kind":
-		err := kind.LoadDockerImage(image)
-		if err != nil {
-			io.Errorf("pushImage(kind) load docker image error, message:%s", err)
-			return err
-		}
-		return nil
-	default:
-	}
+// runCommand runs a build tool and logs its outputs
+func runCommand(io cmdutil.IOStreams, name string, args ...string) error {
 	//nolint:gosec
-	cmd := exec.Command("docker", "push", image)
+	cmd := exec.Command(name, args...)
 	stdout, err := cmd.StdoutPipe()
 	if err != nil {
-		io.Errorf("pushImage(docker push) exec command error, message:%s\n", err.Error())
+		io.Errorf("%s exec command error, message:%s\n", name, err.Error())
 		return err
 	}
 	stderr, err := cmd.StderrPipe()
 	if err != nil {
-		io.Errorf("pushImage(docker push) exec command error, message:%s\n", err.Error())
+		io.Errorf("%s exec command error, message:%s\n", name, err.Error())
 		return err
 	}
 	if err := cmd.Start(); err != nil {
-		io.Errorf("pushImage(docker push) exec command error, message:%s\n", err.Error())
+		io.Errorf("%s exec command error, message:%s\n", name, err.Error())
 		return err
 	}
 	go asyncLog(stdout, io)
 	go asyncLog(stderr, io)
 	if err := cmd.Wait(); err != nil {
-		io.Errorf("pushImage(docker push) wait for command execution error:%s", err.Error())
+		io.Errorf("%s wait for command execution error:%s", name, err.Error())
 		return err
 	}
 	return nil
@@ -17,6 +17,9 @@ limitations under the License.
 package build
 
 import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/bmizerany/assert"
@@ -77,6 +80,15 @@ func TestBuild(t *testing.T) {
 				},
 			},
 		},
+		{
+			expectErr: "do task build: image builder kaniko is not supported",
+			input: map[string]interface{}{
+				"image": "test.io/app:v1",
+				"build": map[string]interface{}{
+					"builder": "kaniko",
+				},
+			},
+		},
 	}
 
 	for _, tcase := range errTestCase {
@@ -86,3 +98,36 @@ func TestBuild(t *testing.T) {
 	}
 
 }
+
+func TestPinDigest(t *testing.T) {
+	digest := "sha256:2d4e459f4ecb5329407ae3e47cbc107a2fbace221354ca75960af4c047b3cb13"
+	testCases := map[string]string{
+		"oamdev/testapp:v1":                 "oamdev/testapp@" + digest,
+		"oamdev/testapp":                    "oamdev/testapp@" + digest,
+		"localhost:5000/testapp:v1":         "localhost:5000/testapp@" + digest,
+		"localhost:5000/testapp":            "localhost:5000/testapp@" + digest,
+		"oamdev/testapp:v1@sha256:00000000": "oamdev/testapp@" + digest,
+	}
+	for image, expected := range testCases {
+		assert.Equal(t, expected, PinDigest(image, digest))
+	}
+
+	assert.Equal(t, "sha256:abc", repoDigest("nginx:1.19", []string{"docker.io/library/nginx@sha256:abc", ""}))
+	assert.Equal(t, "sha256:def", repoDigest("test.io/app:v1", []string{"other.io/app@sha256:abc", "test.io/app@sha256:def"}))
+	assert.Equal(t, "", repoDigest("test.io/app:v1", nil))
+}
+
+func TestLayoutDigest(t *testing.T) {
+	dir, err := ioutil.TempDir("", "oci-layout")
+	assert.Equal(t, nil, err)
+	defer os.RemoveAll(dir)
+
+	_, err = LayoutDigest(dir)
+	assert.NotEqual(t, nil, err)
+
+	index := `{"schemaVersion":2,"manifests":[{"mediaType":"application/vnd.oci.image.manifest.v1+json","digest":"sha256:abc","size":100}]}`
+	assert.Equal(t, nil, ioutil.WriteFile(filepath.Join(dir, "index.json"), []byte(index), 0600))
+	digest, err := LayoutDigest(dir)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "sha256:abc", digest)
+}
@@ -0,0 +1,119 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package build
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	cmdutil "github.com/oam-dev/kubevela/pkg/utils/util"
+)
+
+const (
+	// BuilderBuildkit builds image with buildkit without docker daemon, the image is written to OCI image layout
+	BuilderBuildkit = "buildkit"
+
+	// DefaultOutputDir is the directory that OCI image layouts are written to if output is not specified
+	DefaultOutputDir = ".vela/oci"
+)
+
+func init() {
+	RegisterBuilder(BuilderBuildkit, func(b *Build) Builder {
+		return &buildkitBuilder{docker: b.Docker, output: b.Output}
+	})
+}
+
+type buildkitBuilder struct {
+	docker Docker
+	output string
+}
+
+// Build builds image from Dockerfile by buildctl, buildctl-daemonless.sh is preferred to run a rootless buildkitd
+// on demand
+func (b *buildkitBuilder) Build(io cmdutil.IOStreams, image string) (string, error) {
+	layout := b.output
+	if layout == "" {
+		layout = filepath.Join(DefaultOutputDir, strings.NewReplacer("/", "_", ":", "_", "@", "_").Replace(image))
+	}
+	if err := os.MkdirAll(layout, 0750); err != nil {
+		return "", err
+	}
+	dockerfile := b.docker.File
+	if dockerfile == "" {
+		dockerfile = "Dockerfile"
+	}
+	context := b.docker.Context
+	if context == "" {
+		context = "."
+	}
+	buildctl := "buildctl"
+	if path, err := exec.LookPath("buildctl-daemonless.sh"); err == nil {
+		buildctl = path
+	}
+	err := runCommand(io, buildctl, "build",
+		"--frontend", "dockerfile.v0",
+		"--local", "context="+context,
+		"--local", "dockerfile="+filepath.Dir(dockerfile),
+		"--opt", "filename="+filepath.Base(dockerfile),
+		"--output", fmt.Sprintf("type=oci,dest=%s,tar=false,name=%s", layout, image))
+	if err != nil {
+		return "", err
+	}
+	return layout, nil
+}
+
+// pushLayout pushes the image in OCI image layout to registry by skopeo and returns the digest of the pushed image
+func (b *Build) pushLayout(io cmdutil.IOStreams, layout, image string) (string, error) {
+	if b.Push.Local == "kind" {
+		return "", fmt.Errorf("image in OCI layout %s cannot be loaded into kind, use docker or pack builder instead", layout)
+	}
+	io.Infof("pushing image (%s) from %s...\n", image, layout)
+	digestFile := filepath.Join(layout, "pushed-digest")
+	if err := runCommand(io, "skopeo", "copy", "--digestfile", digestFile, "oci:"+layout, "docker://"+image); err != nil {
+		return "", err
+	}
+	digest, err := ioutil.ReadFile(filepath.Clean(digestFile))
+	if err != nil {
+		return LayoutDigest(layout)
+	}
+	return strings.TrimSpace(string(digest)), nil
+}
+
+// LayoutDigest returns the digest of the image manifest in an OCI image layout
+func LayoutDigest(layout string) (string, error) {
+	data, err := ioutil.ReadFile(filepath.Clean(filepath.Join(layout, "index.json")))
+	if err != nil {
+		return "", err
+	}
+	index := struct {
+		Manifests []struct {
+			Digest string `json:"digest"`
+		} `json:"manifests"`
+	}{}
+	if err := json.Unmarshal(data, &index); err != nil {
+		return "", err
+	}
+	if len(index.Manifests) != 1 {
+		return "", fmt.Errorf("OCI image layout %s should contain one manifest, got %d", layout, len(index.Manifests))
+	}
+	return index.Manifests[0].Digest, nil
+}
@@ -0,0 +1,106 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package build
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"github.com/oam-dev/kubevela/pkg/builtin/kind"
+	cmdutil "github.com/oam-dev/kubevela/pkg/utils/util"
+)
+
+const (
+	// BuilderDocker builds image with docker daemon
+	BuilderDocker = "docker"
+	// BuilderPack builds image with Cloud Native Buildpacks by pack CLI
+	BuilderPack = "pack"
+
+	// DefaultBuildpacksBuilder is the builder image used by pack if it's not specified
+	DefaultBuildpacksBuilder = "paketobuildpacks/builder:base"
+)
+
+func init() {
+	RegisterBuilder(BuilderDocker, func(b *Build) Builder {
+		return &dockerBuilder{docker: b.Docker}
+	})
+	RegisterBuilder(BuilderPack, func(b *Build) Builder {
+		return &packBuilder{buildpacks: b.Buildpacks}
+	})
+}
+
+type dockerBuilder struct {
+	docker Docker
+}
+
+// Build will build a image with name and context.
+func (d *dockerBuilder) Build(io cmdutil.IOStreams, image string) (string, error) {
+	// keep docker binary command due to the issue #416 https://github.com/oam-dev/kubevela/issues/416
+	return "", runCommand(io, "docker", "build", "-t", image, "-f", d.docker.File, d.docker.Context)
+}
+
+type packBuilder struct {
+	buildpacks Buildpacks
+}
+
+// Build builds image from source code with buildpacks lifecycle, no Dockerfile is needed
+func (p *packBuilder) Build(io cmdutil.IOStreams, image string) (string, error) {
+	builder := p.buildpacks.Builder
+	if builder == "" {
+		builder = DefaultBuildpacksBuilder
+	}
+	path := p.buildpacks.Path
+	if path == "" {
+		path = "."
+	}
+	return "", runCommand(io, "pack", "build", image, "--builder", builder, "--path", path)
+}
+
+// pushImage pushes the image in docker daemon and returns the digest of the pushed image, the digest is empty if the
+// image is loaded into kind as there is no registry
+func (b *Build) pushImage(io cmdutil.IOStreams, image string) (string, error) {
+	io.Infof("pushing image (%s)...\n", image)
+	if b.Push.Local == "kind" {
+		if err := kind.LoadDockerImage(image); err != nil {
+			io.Errorf("pushImage(kind) load docker image error, message:%s", err)
+			return "", err
+		}
+		return "", nil
+	}
+	if err := runCommand(io, "docker", "push", image); err != nil {
+		return "", err
+	}
+	//nolint:gosec
+	out, err := exec.Command("docker", "image", "inspect", "-f", "{{ join .RepoDigests \"\\n\" }}", image).Output()
+	if err != nil {
+		return "", fmt.Errorf("cannot get digest of image %s: %w", image, err)
+	}
+	return repoDigest(image, strings.Split(string(out), "\n")), nil
+}
+
+// repoDigest picks the digest of the repository of image from the repo digests of docker
+func repoDigest(image string, repoDigests []string) string {
+	repo := repository(image)
+	for _, rd := range repoDigests {
+		parts := strings.SplitN(strings.TrimSpace(rd), "@", 2)
+		if len(parts) == 2 && (parts[0] == repo || parts[0] == "docker.io/library/"+repo || parts[0] == "docker.io/"+repo) {
+			return parts[1]
+		}
+	}
+	return ""
+}
@@ -43,11 +43,13 @@ func GetTasks() map[string]Task {
 // CallCtx is task handle context
 type CallCtx interface {
 	LookUp(...string) (interface{}, error)
+	Fill(key string, value interface{})
 	IO() util.IOStreams
 }
 
 type callContext struct {
 	data      map[string]interface{}
+	output    map[string]interface{}
 	ioStreams util.IOStreams
 }
 
@@ -69,6 +71,11 @@ func (ctx *callContext) LookUp(paths ...string) (interface{}, error) {
 	return walkData, nil
 }
 
+// Fill sets a field of the spec returned after tasks are done, e.g. the image built by a task
+func (ctx *callContext) Fill(key string, value interface{}) {
+	ctx.output[key] = value
+}
+
 func lookup(v interface{}, key string) interface{} {
 	val, ok := v.(map[string]interface{})
 	if ok {
@@ -77,10 +84,11 @@ func lookup(v interface{}, key string) interface{} {
 	return nil
 }
 
-func newCallCtx(io util.IOStreams, data map[string]interface{}) CallCtx {
+func newCallCtx(io util.IOStreams, data, output map[string]interface{}) CallCtx {
 	return &callContext{
 		ioStreams: io,
 		data:      data,
+		output:    output,
 	}
 }
 
@@ -88,19 +96,23 @@ func newCallCtx(io util.IOStreams, data map[string]interface{}) CallCtx {
 // Deprecated: Run is deprecated, you should use DoTasks is builtin package, it will automatically register all internal functions
 func Run(spec map[string]interface{}, io util.IOStreams) (map[string]interface{}, error) {
 	var (
-		ctx     = newCallCtx(io, spec)
 		retSpec = map[string]interface{}{}
+		ctx     = newCallCtx(io, spec, retSpec)
 	)
 
 	tasks := GetTasks()
 
+	for key, params := range spec {
+		if _, ok := tasks[key]; !ok {
+			retSpec[key] = params
+		}
+	}
+	// tasks run after other fields are copied so that the fields filled by tasks are not overwritten
 	for key, params := range spec {
 		if do, ok := tasks[key]; ok {
 			if err := do(ctx, params); err != nil {
 				return nil, errors.WithMessagef(err, "do task %s", key)
 			}
-		} else {
-			retSpec[key] = params
 		}
 	}
 	return retSpec, nil
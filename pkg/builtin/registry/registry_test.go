@@ -103,6 +103,27 @@ func TestDoTasks(t *testing.T) {
 	}
 }
 
+func TestFill(t *testing.T) {
+	RegisterTask("pin", func(ctx CallCtx, params interface{}) error {
+		image, err := ctx.LookUp("image")
+		if err != nil {
+			return err
+		}
+		ctx.Fill("image", image.(string)+"@"+params.(string))
+		return nil
+	})
+	ret, err := Run(map[string]interface{}{
+		"image": "testImage",
+		"pin":   "sha256:abc",
+		"cmd":   []string{"sleep", "1000"},
+	}, cmdutil.IOStreams{})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{
+		"image": "testImage@sha256:abc",
+		"cmd":   []string{"sleep", "1000"},
+	}, ret)
+}
+
 func TestRegisterTask(t *testing.T) {
 	RegisterTask("mock", mockTask)
 	RegisterTask("mock1", mockTask)
@@ -18,6 +18,7 @@ package rollout
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"reflect"
 	"time"
@@ -25,6 +26,8 @@ import (
 	"github.com/crossplane/crossplane-runtime/pkg/event"
 	kruisev1 "github.com/openkruise/kruise-api/apps/v1alpha1"
 	apps "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/klog/v2"
@@ -51,6 +54,9 @@ type Controller struct {
 
 	targetWorkload *unstructured.Unstructured
 	sourceWorkload *unstructured.Unstructured
+
+	// windowMessage explains why deployment windows hold the batches not started yet, they are not held if it's empty
+	windowMessage string
 }
 
 // NewRolloutPlanController creates a RolloutPlanController
@@ -72,6 +78,11 @@ func NewRolloutPlanController(client client.Client, parentController oam.Object,
 	}
 }
 
+// WaitForWindow holds the batches not started yet as deployment windows don't allow them, the message explains why
+func (r *Controller) WaitForWindow(message string) {
+	r.windowMessage = message
+}
+
 // Reconcile reconciles a rollout plan
 func (r *Controller) Reconcile(ctx context.Context) (res reconcile.Result, status *v1alpha1.RolloutStatus) {
 	klog.InfoS("Reconcile the rollout plan", "rollout status", r.rolloutStatus,
@@ -166,6 +177,9 @@ func (r *Controller) reconcileBatchInRolling(ctx context.Context, workloadContro
 
 	switch r.rolloutStatus.BatchRollingState {
 	case v1alpha1.BatchInitializingState:
+		if r.waitForWindow() || r.waitForApproval() {
+			return
+		}
 		r.initializeOneBatch(ctx)
 
 	case v1alpha1.BatchInRollingState:
@@ -229,6 +243,58 @@ func (r *Controller) initializeRollout(ctx context.Context) error {
 	return nil
 }
 
+// waitForWindow holds the current batch if deployment windows don't allow it to start
+func (r *Controller) waitForWindow() bool {
+	if r.windowMessage == "" {
+		if cond := r.rolloutStatus.GetCondition(v1alpha1.BatchWaitingForWindow); cond.Status == corev1.ConditionTrue {
+			r.rolloutStatus.SetConditions(v1alpha1.NewNegativeCondition(v1alpha1.BatchWaitingForWindow, ""))
+		}
+		return false
+	}
+	currentBatch := r.rolloutStatus.CurrentBatch
+	klog.InfoS("the current batch is waiting for window", "current batch", currentBatch, "reason", r.windowMessage)
+	r.recorder.Event(r.parentController, event.Normal("Batch waiting for window",
+		fmt.Sprintf("Batch %d is %s", currentBatch, r.windowMessage)))
+	cond := v1alpha1.NewPositiveCondition(v1alpha1.BatchWaitingForWindow)
+	cond.Message = r.windowMessage
+	r.rolloutStatus.SetConditions(cond)
+	return true
+}
+
+// waitForApproval holds the current batch if it requires approval and it's not approved yet,
+// the approval is recorded in the status once the batch is approved
+func (r *Controller) waitForApproval() bool {
+	currentBatch := r.rolloutStatus.CurrentBatch
+	if int(currentBatch) >= len(r.rolloutSpec.RolloutBatches) ||
+		!r.rolloutSpec.RolloutBatches[currentBatch].RequireApproval {
+		return false
+	}
+	if r.rolloutSpec.WaitingForApproval(currentBatch) {
+		klog.InfoS("the current batch is waiting for approval", "current batch", currentBatch)
+		r.recorder.Event(r.parentController, event.Normal("Batch waiting for approval",
+			fmt.Sprintf("Batch %d is waiting for approval", currentBatch)))
+		return true
+	}
+	for _, approval := range r.rolloutStatus.BatchApprovals {
+		if approval.Batch == currentBatch {
+			return false
+		}
+	}
+	approval := v1alpha1.BatchApproval{Batch: currentBatch, Time: metav1.Now()}
+	// the approver is recorded in the annotation by the admission webhook when the batch partition is advanced
+	if value, ok := r.parentController.GetAnnotations()[oam.AnnotationBatchApproval]; ok {
+		var latest v1alpha1.BatchApproval
+		if err := json.Unmarshal([]byte(value), &latest); err != nil {
+			klog.ErrorS(err, "ignore the invalid batch approval annotation", "annotation", value)
+		} else if latest.Batch >= currentBatch {
+			approval.Approver, approval.Time = latest.Approver, latest.Time
+		}
+	}
+	klog.InfoS("the current batch is approved", "current batch", currentBatch, "approver", approval.Approver)
+	r.rolloutStatus.BatchApprovals = append(r.rolloutStatus.BatchApprovals, approval)
+	return false
+}
+
 // all the common initialize work before we rollout one batch of resources
 func (r *Controller) initializeOneBatch(ctx context.Context) {
 	rolloutHooks := r.gatherAllWebhooks()
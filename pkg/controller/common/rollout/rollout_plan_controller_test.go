@@ -19,9 +19,14 @@ package rollout
 import (
 	"testing"
 
+	"github.com/crossplane/crossplane-runtime/pkg/event"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
 	"k8s.io/utils/pointer"
 
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
 	"github.com/oam-dev/kubevela/apis/standard.oam.dev/v1alpha1"
+	"github.com/oam-dev/kubevela/pkg/oam"
 )
 
 func Test_TryMovingToNextBatch(t *testing.T) {
@@ -63,3 +68,101 @@ func Test_TryMovingToNextBatch(t *testing.T) {
 		})
 	}
 }
+
+func Test_WaitForApproval(t *testing.T) {
+	batches := []v1alpha1.RolloutBatch{
+		{Replicas: intstr.FromInt(1)},
+		{Replicas: intstr.FromInt(1), RequireApproval: true},
+	}
+	tests := map[string]struct {
+		batchPartition *int32
+		currentBatch   int32
+		approvals      []v1alpha1.BatchApproval
+		wantWait       bool
+		wantApprover   string
+		wantApprovals  int
+	}{
+		"batch not requiring approval": {
+			currentBatch: 0,
+		},
+		"batch waiting for approval": {
+			currentBatch: 1,
+			wantWait:     true,
+		},
+		"batch held by the partition": {
+			batchPartition: pointer.Int32Ptr(0),
+			currentBatch:   1,
+			wantWait:       true,
+		},
+		"batch approved": {
+			batchPartition: pointer.Int32Ptr(1),
+			currentBatch:   1,
+			wantApprover:   "alice",
+			wantApprovals:  1,
+		},
+		"batch approval recorded": {
+			batchPartition: pointer.Int32Ptr(1),
+			currentBatch:   1,
+			approvals:      []v1alpha1.BatchApproval{{Batch: 1, Approver: "bob"}},
+			wantApprover:   "bob",
+			wantApprovals:  1,
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			parent := &v1beta1.AppRollout{}
+			parent.SetAnnotations(map[string]string{
+				oam.AnnotationBatchApproval: `{"batch":1,"approver":"alice","time":"2021-05-01T00:00:00Z"}`,
+			})
+			r := &Controller{
+				recorder:         event.NewNopRecorder(),
+				parentController: parent,
+				rolloutSpec:      &v1alpha1.RolloutPlan{RolloutBatches: batches, BatchPartition: tt.batchPartition},
+				rolloutStatus:    &v1alpha1.RolloutStatus{CurrentBatch: tt.currentBatch, BatchApprovals: tt.approvals},
+			}
+			if got := r.waitForApproval(); got != tt.wantWait {
+				t.Errorf("\n%s\nwait miss match: want `%t`, got `%t`\n", name, tt.wantWait, got)
+			}
+			approvals := r.rolloutStatus.BatchApprovals
+			if len(approvals) != tt.wantApprovals {
+				t.Fatalf("\n%s\napprovals miss match: want `%d`, got `%d`\n", name, tt.wantApprovals, len(approvals))
+			}
+			if tt.wantApprovals != 0 && approvals[0].Approver != tt.wantApprover {
+				t.Errorf("\n%s\napprover miss match: want `%s`, got `%s`\n", name, tt.wantApprover, approvals[0].Approver)
+			}
+		})
+	}
+}
+
+func Test_WaitForWindow(t *testing.T) {
+	r := &Controller{
+		recorder:         event.NewNopRecorder(),
+		parentController: &v1beta1.AppRollout{},
+		rolloutSpec:      &v1alpha1.RolloutPlan{},
+		rolloutStatus:    &v1alpha1.RolloutStatus{CurrentBatch: 1},
+	}
+	if r.waitForWindow() {
+		t.Errorf("batch is held without deployment windows")
+	}
+	if len(r.rolloutStatus.Conditions) != 0 {
+		t.Errorf("unexpected conditions %v", r.rolloutStatus.Conditions)
+	}
+
+	message := "waiting for window: outside of the allowed deployment windows"
+	r.WaitForWindow(message)
+	if !r.waitForWindow() {
+		t.Errorf("batch is not held by deployment windows")
+	}
+	if cond := r.rolloutStatus.GetCondition(v1alpha1.BatchWaitingForWindow); cond.Status != corev1.ConditionTrue ||
+		cond.Message != message {
+		t.Errorf("condition miss match: got `%v`", cond)
+	}
+
+	r.WaitForWindow("")
+	if r.waitForWindow() {
+		t.Errorf("batch is held after the window opens")
+	}
+	if cond := r.rolloutStatus.GetCondition(v1alpha1.BatchWaitingForWindow); cond.Status != corev1.ConditionFalse {
+		t.Errorf("condition miss match: got `%v`", cond)
+	}
+}
@@ -18,6 +18,7 @@ package core_oam_dev
 
 import (
 	"github.com/oam-dev/kubevela/pkg/dsl/definition"
+	"github.com/oam-dev/kubevela/pkg/notification"
 	"github.com/oam-dev/kubevela/pkg/oam/discoverymapper"
 )
 
@@ -56,6 +57,20 @@ type Args struct {
 	// The default value is 20.
 	DefRevisionLimit int
 
+	// DefinitionRerenderQPS is the maximum rate to re-render Applications when a referenced definition changes,
+	// a non-positive value means no limit.
+	DefinitionRerenderQPS float64
+
+	// DefinitionRerenderBurst is the maximum number of Applications re-rendered at once when a definition changes.
+	DefinitionRerenderBurst int
+
+	// LintDefinitions enables linting the CUE templates of definitions in the admission webhook,
+	// problems are reported as warnings and never reject the definitions.
+	LintDefinitions bool
+
+	// NotificationOptions configures the deliveries of application notifications to chat and webhooks.
+	NotificationOptions notification.Options
+
 	// ApplyMode indicates whether workloads and traits should be
 	// affected if no spec change is made in the ApplicationConfiguration.
 	ApplyMode ApplyOnceOnlyMode
@@ -18,6 +18,7 @@ package application
 
 import (
 	"context"
+	"fmt"
 	"time"
 
 	"github.com/crossplane/crossplane-runtime/apis/core/v1alpha1"
@@ -33,14 +34,19 @@ import (
 	"k8s.io/client-go/util/retry"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
 
 	"github.com/oam-dev/kubevela/apis/core.oam.dev/common"
 	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
 	velatypes "github.com/oam-dev/kubevela/apis/types"
 	"github.com/oam-dev/kubevela/pkg/appfile"
 	core "github.com/oam-dev/kubevela/pkg/controller/core.oam.dev"
+	"github.com/oam-dev/kubevela/pkg/deploymentwindow"
 	"github.com/oam-dev/kubevela/pkg/dsl/definition"
+	"github.com/oam-dev/kubevela/pkg/notification"
+	"github.com/oam-dev/kubevela/pkg/oam"
 	"github.com/oam-dev/kubevela/pkg/oam/discoverymapper"
 	oamutil "github.com/oam-dev/kubevela/pkg/oam/util"
 	"github.com/oam-dev/kubevela/pkg/utils/apply"
@@ -64,10 +70,18 @@ type Reconciler struct {
 	Recorder         event.Recorder
 	applicator       apply.Applicator
 	appRevisionLimit int
+	// defRerenderQPS and defRerenderBurst limit the rate to re-render Applications when a definition changes
+	defRerenderQPS   float64
+	defRerenderBurst int
+	// notifier notifies phase transitions of Applications, failures are notified by Recorder
+	notifier *notification.Notifier
 }
 
 // +kubebuilder:rbac:groups=core.oam.dev,resources=applications,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=core.oam.dev,resources=applications/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=core.oam.dev,resources=notifications,verbs=get;list;watch
+// +kubebuilder:rbac:groups=core.oam.dev,resources=deploymentwindows,verbs=get;list;watch
+// +kubebuilder:rbac:groups="",resources=secrets,verbs=get
 
 // Reconcile process app event
 func (r *Reconciler) Reconcile(req ctrl.Request) (ctrl.Result, error) {
@@ -113,6 +127,7 @@ func (r *Reconciler) Reconcile(req ctrl.Request) (ctrl.Result, error) {
 	applog.Info("Start Rendering")
 
 	app.Status.Phase = common.ApplicationRendering
+	app.Status.ObservedGeneration = app.Generation
 
 	applog.Info("parse template")
 	// parse template
@@ -138,12 +153,38 @@ func (r *Reconciler) Reconcile(req ctrl.Request) (ctrl.Result, error) {
 		return handler.handleErr(err)
 	}
 	r.Recorder.Event(app, event.Normal(velatypes.ReasonParsed, velatypes.MessageParsed))
+
+	// hold the new revision until a deployment window allows it, the resources of the current revision are kept
+	if handler.isNewRevision {
+		now := time.Now()
+		window, err := deploymentwindow.Check(ctx, r, v1beta1.ApplicationKind, app, now)
+		if err != nil {
+			applog.Error(err, "[Handle DeploymentWindow]")
+			app.Status.SetConditions(errorCondition("DeploymentWindow", err))
+			r.Recorder.Event(app, event.Warning(velatypes.ReasonWaitingForWindow, err))
+			return handler.handleErr(err)
+		}
+		if !window.Open {
+			applog.Info("new revision is held by deployment windows", "revision", appRev.Name, "reason", window.Message)
+			app.Status.Phase = common.ApplicationWaitingForWindow
+			app.Status.SetConditions(errorCondition("DeploymentWindow", errors.New(window.Message)))
+			r.Recorder.Event(app, event.Warning(velatypes.ReasonWaitingForWindow, errors.New(window.Message)))
+			return ctrl.Result{RequeueAfter: window.RequeueAfter(now)}, r.UpdateStatus(ctx, app)
+		}
+		if window.Overridden {
+			r.Recorder.Event(app, event.Normal(velatypes.ReasonDeploymentWindowOverridden,
+				fmt.Sprintf("revision %s is deployed outside of deployment windows, overridden by %s until %s",
+					appRev.Name, window.OverriddenBy, window.OverrideUntil.Format(time.RFC3339))))
+		}
+		app.Status.SetConditions(readyCondition("DeploymentWindow"))
+	}
+
 	// Record the revision so it can be used to render data in context.appRevision
 	generatedAppfile.RevisionName = appRev.Name
 
 	applog.Info("build template")
 	// build template to applicationconfig & component
-	ac, comps, err := generatedAppfile.GenerateApplicationConfiguration()
+	ac, comps, err := generatedAppfile.GenerateApplicationConfiguration(ctx)
 	if err != nil {
 		applog.Error(err, "[Handle GenerateApplicationConfiguration]")
 		app.Status.SetConditions(errorCondition("Built", err))
@@ -151,7 +192,7 @@ func (r *Reconciler) Reconcile(req ctrl.Request) (ctrl.Result, error) {
 		return handler.handleErr(err)
 	}
 
-	err = handler.handleResourceTracker(ctx, comps, ac)
+	err = handler.handleResourceTracker(ctx)
 	if err != nil {
 		applog.Error(err, "[Handle resourceTracker]")
 		app.Status.SetConditions(errorCondition("Handle resourceTracker", err))
@@ -208,8 +249,18 @@ func (r *Reconciler) Reconcile(req ctrl.Request) (ctrl.Result, error) {
 		r.Recorder.Event(app, event.Warning(velatypes.ReasonFailedHealthCheck, err))
 		return handler.handleErr(err)
 	}
+	healthy, message, err := handler.healthAggregate(ctx, appCompStatus, healthy)
+	if err != nil {
+		applog.Error(err, "[health aggregate]")
+		app.Status.SetConditions(errorCondition("HealthCheck", err))
+		r.Recorder.Event(app, event.Warning(velatypes.ReasonFailedHealthCheck, err))
+		return handler.handleErr(err)
+	}
 	if !healthy {
-		app.Status.SetConditions(errorCondition("HealthCheck", errors.New("not healthy")))
+		if message == "" {
+			message = "not healthy"
+		}
+		app.Status.SetConditions(errorCondition("HealthCheck", errors.New(message)))
 
 		app.Status.Services = appCompStatus
 		// unhealthy will check again after 10s
@@ -252,35 +303,120 @@ func registerFinalizers(app *v1beta1.Application) bool {
 
 // SetupWithManager install to manager
 func (r *Reconciler) SetupWithManager(mgr ctrl.Manager) error {
+	indexer := mgr.GetFieldIndexer()
+	if err := indexer.IndexField(context.Background(), &v1beta1.Application{}, componentDefinitionIndex, componentDefinitionsOf); err != nil {
+		return err
+	}
+	if err := indexer.IndexField(context.Background(), &v1beta1.Application{}, traitDefinitionIndex, traitDefinitionsOf); err != nil {
+		return err
+	}
 	// If Application Own these two child objects, AC status change will notify application controller and recursively update AC again, and trigger application event again...
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&v1beta1.Application{}).
+		Watches(&source.Kind{Type: &v1beta1.Environment{}}, &handler.EnqueueRequestsFromMapFunc{
+			ToRequests: handler.ToRequestsFunc(r.findEnvironmentApps),
+		}).
+		Watches(&source.Kind{Type: &v1beta1.DeploymentWindow{}}, &handler.EnqueueRequestsFromMapFunc{
+			ToRequests: handler.ToRequestsFunc(r.findDeploymentWindowApps),
+		}).
+		Watches(&source.Kind{Type: &v1beta1.ComponentDefinition{}},
+			newDefinitionChangeHandler(r.Client, r.Log, componentDefinitionIndex, componentDefinitionsOf, r.defRerenderQPS, r.defRerenderBurst)).
+		Watches(&source.Kind{Type: &v1beta1.TraitDefinition{}},
+			newDefinitionChangeHandler(r.Client, r.Log, traitDefinitionIndex, traitDefinitionsOf, r.defRerenderQPS, r.defRerenderBurst)).
 		Complete(r)
 }
 
+// findEnvironmentApps finds the applications of an Environment, they will be rendered again with the new patches
+func (r *Reconciler) findEnvironmentApps(o handler.MapObject) []reconcile.Request {
+	env, ok := o.Object.(*v1beta1.Environment)
+	if !ok {
+		return nil
+	}
+	ctx := context.Background()
+	var requests []reconcile.Request
+	seen := make(map[types.NamespacedName]bool)
+	opts := []client.ListOption{client.MatchingLabels{oam.LabelAppEnv: env.Name}}
+	// an Environment without namespace selects applications by label only, listing in the empty namespace lists all
+	if env.Spec.Namespace != "" {
+		opts = append(opts, client.InNamespace(env.Spec.Namespace))
+	}
+	for _, opt := range opts {
+		apps := new(v1beta1.ApplicationList)
+		if err := r.List(ctx, apps, opt); err != nil {
+			r.Log.Error(err, "cannot list applications of environment", "environment", env.Name)
+			continue
+		}
+		for _, app := range apps.Items {
+			key := types.NamespacedName{Namespace: app.Namespace, Name: app.Name}
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			requests = append(requests, reconcile.Request{NamespacedName: key})
+		}
+	}
+	return requests
+}
+
+// findDeploymentWindowApps finds the applications waiting for a DeploymentWindow, the held revisions may be
+// allowed by the changed windows
+func (r *Reconciler) findDeploymentWindowApps(o handler.MapObject) []reconcile.Request {
+	window, ok := o.Object.(*v1beta1.DeploymentWindow)
+	if !ok {
+		return nil
+	}
+	apps := new(v1beta1.ApplicationList)
+	if err := r.List(context.Background(), apps, client.InNamespace(window.Namespace)); err != nil {
+		r.Log.Error(err, "cannot list applications of deployment window", "deploymentWindow", window.Name)
+		return nil
+	}
+	var requests []reconcile.Request
+	for _, app := range apps.Items {
+		if app.Status.Phase != common.ApplicationWaitingForWindow {
+			continue
+		}
+		requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{Namespace: app.Namespace, Name: app.Name}})
+	}
+	return requests
+}
+
 // UpdateStatus updates v1beta1.Application's Status with retry.RetryOnConflict
 func (r *Reconciler) UpdateStatus(ctx context.Context, app *v1beta1.Application, opts ...client.UpdateOption) error {
 	status := app.DeepCopy().Status
-	return retry.RetryOnConflict(retry.DefaultBackoff, func() (err error) {
+	var previous common.ApplicationPhase
+	err := retry.RetryOnConflict(retry.DefaultBackoff, func() (err error) {
 		if err = r.Get(ctx, types.NamespacedName{Namespace: app.Namespace, Name: app.Name}, app); err != nil {
 			return
 		}
+		previous = app.Status.Phase
 		app.Status = status
 		return r.Status().Update(ctx, app, opts...)
 	})
+	// the phase in a reconciliation is transient, only the persisted transition is notified
+	if err == nil && r.notifier != nil && previous != status.Phase {
+		r.notifier.PhaseChanged(ctx, app, previous)
+	}
+	return err
 }
 
 // Setup adds a controller that reconciles AppRollout.
 func Setup(mgr ctrl.Manager, args core.Args, _ logging.Logger) error {
+	notifier := notification.NewNotifier(mgr.GetClient(), ctrl.Log.WithName("Notification"), args.NotificationOptions)
+	if err := mgr.Add(notifier); err != nil {
+		return err
+	}
 	reconciler := Reconciler{
 		Client:           mgr.GetClient(),
 		Log:              ctrl.Log.WithName("Application"),
 		Scheme:           mgr.GetScheme(),
-		Recorder:         event.NewAPIRecorder(mgr.GetEventRecorderFor("Application")),
+		Recorder:         notification.NewRecorder(event.NewAPIRecorder(mgr.GetEventRecorderFor("Application")), notifier),
 		dm:               args.DiscoveryMapper,
 		pd:               args.PackageDiscover,
 		applicator:       apply.NewAPIApplicator(mgr.GetClient()),
 		appRevisionLimit: args.AppRevisionLimit,
+		defRerenderQPS:   args.DefinitionRerenderQPS,
+		defRerenderBurst: args.DefinitionRerenderBurst,
+		notifier:         notifier,
 	}
 	return reconciler.SetupWithManager(mgr)
 }
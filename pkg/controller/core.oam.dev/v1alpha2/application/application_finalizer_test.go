@@ -91,7 +91,9 @@ var _ = Describe("Test application controller finalizer logic", func() {
 		Expect(len(checkApp.Finalizers)).Should(BeEquivalentTo(0))
 
 		rt := &v1beta1.ResourceTracker{}
-		Expect(k8sClient.Get(ctx, getTrackerKey(checkApp.Namespace, checkApp.Name), rt)).Should(util.NotFoundMatcher{})
+		Expect(k8sClient.Get(ctx, getTrackerKey(checkApp.Namespace, checkApp.Name), rt)).Should(BeNil())
+		Expect(checkApp.Status.ResourceTracker.UID).Should(BeEquivalentTo(rt.UID))
+		Expect(len(rt.Status.TrackedResources)).Should(BeEquivalentTo(1))
 
 		By("add a cross namespace trait for application")
 		updateApp := checkApp.DeepCopy()
@@ -102,25 +104,24 @@ var _ = Describe("Test application controller finalizer logic", func() {
 			},
 		}
 		Expect(k8sClient.Update(ctx, updateApp)).Should(BeNil())
-		// first reconcile will create resourceTracker and set resourceTracker for app status
+		// this reconcile will set finalizer for app
 		_, err = reconciler.Reconcile(ctrl.Request{NamespacedName: appKey})
 		Expect(err).Should(BeNil())
 		checkApp = new(v1beta1.Application)
 		Expect(k8sClient.Get(ctx, appKey, checkApp)).Should(BeNil())
-		Expect(k8sClient.Get(ctx, getTrackerKey(checkApp.Namespace, checkApp.Name), rt)).Should(BeNil())
-		Expect(checkApp.Status.ResourceTracker.UID).Should(BeEquivalentTo(rt.UID))
-		Expect(len(checkApp.Finalizers)).Should(BeEquivalentTo(0))
+		Expect(len(checkApp.Finalizers)).Should(BeEquivalentTo(1))
+		Expect(checkApp.Finalizers[0]).Should(BeEquivalentTo(resourceTrackerFinalizer))
 
-		// second reconcile will set finalizer for app
+		// next reconcile will record the cross namespace trait in resourceTracker
 		_, err = reconciler.Reconcile(ctrl.Request{NamespacedName: appKey})
+		Expect(err).Should(BeNil())
 		checkApp = new(v1beta1.Application)
 		Expect(k8sClient.Get(ctx, appKey, checkApp)).Should(BeNil())
 		Expect(k8sClient.Get(ctx, getTrackerKey(checkApp.Namespace, checkApp.Name), rt)).Should(BeNil())
-		Expect(err).Should(BeNil())
-		Expect(len(checkApp.Finalizers)).Should(BeEquivalentTo(1))
-		Expect(checkApp.Finalizers[0]).Should(BeEquivalentTo(resourceTrackerFinalizer))
+		Expect(checkApp.Status.ResourceTracker.UID).Should(BeEquivalentTo(rt.UID))
+		Expect(len(rt.Status.TrackedResources)).Should(BeEquivalentTo(2))
 
-		By("update app by delete cross namespace trait, will delete resourceTracker and the status of app will flush")
+		By("update app by delete cross namespace trait, the trait will be removed from resourceTracker")
 		checkApp = &v1beta1.Application{}
 		Expect(k8sClient.Get(ctx, appKey, checkApp)).Should(BeNil())
 		updateApp = checkApp.DeepCopy()
@@ -130,8 +131,9 @@ var _ = Describe("Test application controller finalizer logic", func() {
 		Expect(err).Should(BeNil())
 		checkApp = new(v1beta1.Application)
 		Expect(k8sClient.Get(ctx, appKey, checkApp)).Should(BeNil())
-		Expect(k8sClient.Get(ctx, getTrackerKey(checkApp.Namespace, checkApp.Name), rt)).Should(util.NotFoundMatcher{})
-		Expect(checkApp.Status.ResourceTracker).Should(BeNil())
+		Expect(k8sClient.Get(ctx, getTrackerKey(checkApp.Namespace, checkApp.Name), rt)).Should(BeNil())
+		Expect(checkApp.Status.ResourceTracker.UID).Should(BeEquivalentTo(rt.UID))
+		Expect(len(rt.Status.TrackedResources)).Should(BeEquivalentTo(1))
 	})
 
 	It("Test cross namespace workload, then delete the app", func() {
@@ -197,8 +199,10 @@ var _ = Describe("Test application controller finalizer logic", func() {
 		Expect(err).Should(BeNil())
 		checkApp = new(v1beta1.Application)
 		Expect(k8sClient.Get(ctx, appKey, checkApp)).Should(BeNil())
-		Expect(checkApp.Status.ResourceTracker).Should(BeNil())
-		Expect(k8sClient.Get(ctx, getTrackerKey(checkApp.Namespace, checkApp.Name), rt)).Should(util.NotFoundMatcher{})
+		Expect(k8sClient.Get(ctx, getTrackerKey(checkApp.Namespace, checkApp.Name), rt)).Should(BeNil())
+		Expect(checkApp.Status.ResourceTracker.UID).Should(BeEquivalentTo(rt.UID))
+		Expect(len(rt.Status.TrackedResources)).Should(BeEquivalentTo(1))
+		Expect(rt.Status.TrackedResources[0].Namespace).Should(BeEquivalentTo(namespace))
 		Expect(k8sClient.Delete(ctx, checkApp)).Should(BeNil())
 		_, err = reconciler.Reconcile(ctrl.Request{NamespacedName: appKey})
 		Expect(err).Should(BeNil())
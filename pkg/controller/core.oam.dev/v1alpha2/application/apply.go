@@ -35,6 +35,7 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	ctypes "k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/klog/v2"
@@ -77,15 +78,15 @@ func readyCondition(tpy string) runtimev1alpha1.Condition {
 }
 
 type appHandler struct {
-	r                        *Reconciler
-	app                      *v1beta1.Application
-	appfile                  *appfile.Appfile
-	logger                   logr.Logger
-	inplace                  bool
-	isNewRevision            bool
-	revisionHash             string
-	acrossNamespaceResources []v1beta1.TypedReference
-	resourceTracker          *v1beta1.ResourceTracker
+	r                *Reconciler
+	app              *v1beta1.Application
+	appfile          *appfile.Appfile
+	logger           logr.Logger
+	inplace          bool
+	isNewRevision    bool
+	revisionHash     string
+	trackedResources []v1beta1.TrackedResource
+	resourceTracker  *v1beta1.ResourceTracker
 }
 
 // setInplace will mark if the application should upgrade the workload within the same instance(name never changed)
@@ -134,8 +135,7 @@ func (h *appHandler) apply(ctx context.Context, appRev *v1beta1.ApplicationRevis
 
 	for _, comp := range comps {
 		comp.SetOwnerReferences(owners)
-		needTracker, err := h.checkAndSetResourceTracker(&comp.Spec.Workload)
-		if err != nil {
+		if _, err := h.checkAndSetResourceTracker(&comp.Spec.Workload); err != nil {
 			return err
 		}
 		newComp := comp.DeepCopy()
@@ -144,7 +144,8 @@ func (h *appHandler) apply(ctx context.Context, appRev *v1beta1.ApplicationRevis
 		if err != nil {
 			return err
 		}
-		if needTracker {
+		// the workload of a Helm module is rendered by the Helm release
+		if comp.Spec.Helm == nil {
 			if err := h.recodeTrackedWorkload(comp, revisionName); err != nil {
 				return err
 			}
@@ -530,6 +531,11 @@ func (h *appHandler) removeResourceTracker(ctx context.Context) (bool, error) {
 		}
 		return false, err
 	}
+	if hasKeptResources(rt) {
+		if err := h.releaseTrackedResources(ctx, rt); err != nil {
+			return false, err
+		}
+	}
 	rt = &v1beta1.ResourceTracker{
 		ObjectMeta: metav1.ObjectMeta{
 			Name: trackerName,
@@ -545,33 +551,65 @@ func (h *appHandler) removeResourceTracker(ctx context.Context) (bool, error) {
 	return true, nil
 }
 
+func hasKeptResources(rt *v1beta1.ResourceTracker) bool {
+	for _, tr := range rt.Status.TrackedResources {
+		if tr.DeletionPolicy == v1beta1.DeletionPolicyOrphan || tr.DeletionPolicy == v1beta1.DeletionPolicyRetain {
+			return true
+		}
+	}
+	return false
+}
+
+// releaseTrackedResources prepares the deletion of an application having resources to be orphaned or retained.
+// ApplicationContexts are deleted with orphan propagation so that workloads and traits are not cascaded, then tracked
+// resources are deleted or released one by one according to their deletion policies.
+func (h *appHandler) releaseTrackedResources(ctx context.Context, rt *v1beta1.ResourceTracker) error {
+	appContexts := new(v1alpha2.ApplicationContextList)
+	if err := h.r.List(ctx, appContexts, client.InNamespace(h.app.Namespace)); err != nil {
+		return err
+	}
+	for i := range appContexts.Items {
+		appContext := &appContexts.Items[i]
+		if !metav1.IsControlledBy(appContext, h.app) {
+			continue
+		}
+		if err := h.r.Delete(ctx, appContext, client.PropagationPolicy(metav1.DeletePropagationOrphan)); err != nil &&
+			!apierrors.IsNotFound(err) {
+			return err
+		}
+	}
+	for _, tr := range rt.Status.TrackedResources {
+		if _, err := h.collectTrackedResource(ctx, tr); err != nil {
+			return errors.WithMessagef(err, "cannot garbage collect %s %s", tr.Kind, tr.Name)
+		}
+	}
+	return nil
+}
+
 func (h *appHandler) recodeTrackedWorkload(comp *v1alpha2.Component, compRevisionName string) error {
 	workloadName, err := h.getWorkloadName(comp.Spec.Workload, comp.Name, compRevisionName)
 	if err != nil {
 		return err
 	}
-	if err = h.recodeTrackedResource(workloadName, comp.Spec.Workload); err != nil {
+	if err = h.recodeTrackedResource(comp.Name, workloadName, comp.Spec.Workload); err != nil {
 		return err
 	}
 	return nil
 }
 
 // checkResourceTrackerForTrait check component trait namespace, if it's namespace is different with application, set resourceTracker as its ownerReference
-// and recode trait in handler acrossNamespace field
+// and recode all traits in handler trackedResources field
 func (h *appHandler) checkResourceTrackerForTrait(ctx context.Context, comp v1alpha2.ApplicationConfigurationComponent, compName string) error {
 	for i, ct := range comp.Traits {
-		needTracker, err := h.checkAndSetResourceTracker(&comp.Traits[i].Trait)
+		if _, err := h.checkAndSetResourceTracker(&comp.Traits[i].Trait); err != nil {
+			return err
+		}
+		traitName, err := h.getTraitName(ctx, compName, comp.Traits[i].DeepCopy(), &ct.Trait)
 		if err != nil {
 			return err
 		}
-		if needTracker {
-			traitName, err := h.getTraitName(ctx, compName, comp.Traits[i].DeepCopy(), &ct.Trait)
-			if err != nil {
-				return err
-			}
-			if err = h.recodeTrackedResource(traitName, ct.Trait); err != nil {
-				return err
-			}
+		if err = h.recodeTrackedResource(compName, traitName, comp.Traits[i].Trait); err != nil {
+			return err
 		}
 	}
 	return nil
@@ -603,6 +641,10 @@ func (h *appHandler) getTraitName(ctx context.Context, componentName string, ct
 	if err != nil {
 		return "", err
 	}
+	// the specified name is never overridden by applicationContext
+	if trait.GetName() != "" {
+		return trait.GetName(), nil
+	}
 	traitDef, err := oamutil.FetchTraitDefinition(ctx, h.r, h.r.dm, trait)
 	if err != nil {
 		if !apierrors.IsNotFound(err) {
@@ -618,28 +660,50 @@ func (h *appHandler) getTraitName(ctx context.Context, componentName string, ct
 	return traitName, nil
 }
 
-// recodeTrackedResource append cross namespace resource to apphandler's acrossNamespaceResources field
-func (h *appHandler) recodeTrackedResource(resourceName string, resource runtime.RawExtension) error {
+// recodeTrackedResource append the resource rendered from a component to apphandler's trackedResources field
+func (h *appHandler) recodeTrackedResource(compName, resourceName string, resource runtime.RawExtension) error {
 	u, err := oamutil.RawExtension2Unstructured(&resource)
 	if err != nil {
 		return err
 	}
-	tr := new(v1beta1.TypedReference)
-	tr.Name = resourceName
-	tr.Namespace = u.GetNamespace()
-	tr.APIVersion = u.GetAPIVersion()
-	tr.Kind = u.GetKind()
-	h.acrossNamespaceResources = append(h.acrossNamespaceResources, *tr)
+	isNamespacedScope, err := discoverymapper.IsNamespacedScope(h.r.dm, u.GroupVersionKind().GroupKind())
+	if err != nil {
+		return err
+	}
+	tr := v1beta1.TrackedResource{
+		TypedReference: v1beta1.TypedReference{
+			APIVersion: u.GetAPIVersion(),
+			Kind:       u.GetKind(),
+			Name:       resourceName,
+		},
+		Component:      compName,
+		DeletionPolicy: v1beta1.DeletionPolicy(u.GetAnnotations()[oam.AnnotationDeletionPolicy]),
+	}
+	if isNamespacedScope {
+		// resources without namespace are rendered in the namespace of application
+		tr.Namespace = u.GetNamespace()
+		if len(tr.Namespace) == 0 {
+			tr.Namespace = h.app.Namespace
+		}
+	}
+	if len(tr.DeletionPolicy) == 0 {
+		tr.DeletionPolicy = v1beta1.DeletionPolicyDelete
+	}
+	h.trackedResources = append(h.trackedResources, tr)
 	return nil
 }
 
+func trackedResourceKey(ref v1beta1.TypedReference) string {
+	return strings.Join([]string{ref.APIVersion, ref.Kind, ref.Namespace, ref.Name}, "/")
+}
+
 type garbageCollectFunc func(ctx context.Context, h *appHandler) error
 
-// 1. collect useless across-namespace resource
+// 1. collect resources removed from application
 // 2. collect appRevision
 func garbageCollection(ctx context.Context, h *appHandler) error {
 	collectFuncs := []garbageCollectFunc{
-		garbageCollectFunc(gcAcrossNamespaceResource),
+		garbageCollectFunc(gcTrackedResources),
 		garbageCollectFunc(cleanUpApplicationRevision),
 	}
 	for _, collectFunc := range collectFuncs {
@@ -650,9 +714,10 @@ func garbageCollection(ctx context.Context, h *appHandler) error {
 	return nil
 }
 
-// Now if workloads or traits are in the same namespace with application, applicationContext will take over gc workloads and traits.
-// Here we cover the case in witch a cross namespace component or one of its cross namespace trait is removed from an application.
-func gcAcrossNamespaceResource(ctx context.Context, h *appHandler) error {
+// gcTrackedResources compares the resources rendered in this reconcile with the ones recorded in resourceTracker,
+// resources removed from the application are deleted, orphaned or retained according to their deletion policies.
+// Retained resources are still recorded in resourceTracker until they are deleted by users.
+func gcTrackedResources(ctx context.Context, h *appHandler) error {
 	rt := new(v1beta1.ResourceTracker)
 	err := h.r.Get(ctx, ctypes.NamespacedName{Name: h.generateResourceTrackerName()}, rt)
 	if err != nil {
@@ -663,35 +728,39 @@ func gcAcrossNamespaceResource(ctx context.Context, h *appHandler) error {
 		}
 		return err
 	}
-	applied := map[v1beta1.TypedReference]bool{}
-	if len(h.acrossNamespaceResources) == 0 {
-		h.app.Status.ResourceTracker = nil
-		if err := h.r.Delete(ctx, rt); err != nil {
-			return client.IgnoreNotFound(err)
-		}
+	// resources are not rendered if revision-only annotation is set
+	if h.app.GetAnnotations()[oam.AnnotationAppRevisionOnly] == "true" {
 		return nil
 	}
-	for _, resource := range h.acrossNamespaceResources {
-		applied[resource] = true
-	}
-	for _, ref := range rt.Status.TrackedResources {
-		if !applied[ref] {
-			resource := new(unstructured.Unstructured)
-			resource.SetAPIVersion(ref.APIVersion)
-			resource.SetKind(ref.Kind)
-			resource.SetNamespace(ref.Namespace)
-			resource.SetName(ref.Name)
-			err := h.r.Delete(ctx, resource)
-			if err != nil {
-				if apierrors.IsNotFound(err) {
-					continue
-				}
-				return err
-			}
+	applied := make(map[string]bool, len(h.trackedResources))
+	for _, tr := range h.trackedResources {
+		applied[trackedResourceKey(tr.TypedReference)] = true
+	}
+	components := make(map[string]bool, len(h.app.Spec.Components))
+	for _, comp := range h.app.Spec.Components {
+		components[comp.Name] = true
+	}
+	trackedResources := h.trackedResources
+	for _, tr := range rt.Status.TrackedResources {
+		if applied[trackedResourceKey(tr.TypedReference)] {
+			continue
+		}
+		// resources of old revisions are collected by rollout as long as the component exists
+		if !h.inplace && !tr.Retained && components[tr.Component] {
+			trackedResources = append(trackedResources, tr)
+			continue
+		}
+		kept, err := h.collectTrackedResource(ctx, tr)
+		if err != nil {
+			return errors.WithMessagef(err, "cannot garbage collect %s %s", tr.Kind, tr.Name)
+		}
+		if kept {
+			tr.Retained = true
+			trackedResources = append(trackedResources, tr)
 		}
 	}
-	// update resourceTracker status, recode applied across-namespace resources
-	rt.Status.TrackedResources = h.acrossNamespaceResources
+	// update resourceTracker status, recode all rendered and retained resources
+	rt.Status.TrackedResources = trackedResources
 	if err := h.r.Status().Update(ctx, rt); err != nil {
 		return err
 	}
@@ -703,63 +772,82 @@ func gcAcrossNamespaceResource(ctx context.Context, h *appHandler) error {
 	return nil
 }
 
-// handleResourceTracker check the namespace of  all workloads and traits
-// if one resource is across-namespace create resourceTracker and set in appHandler field
-func (h *appHandler) handleResourceTracker(ctx context.Context, components []*v1alpha2.Component, ac *v1alpha2.ApplicationConfiguration) error {
-	resourceTracker := new(v1beta1.ResourceTracker)
-	needTracker := false
-	for _, c := range components {
-		u, err := oamutil.RawExtension2Unstructured(&c.Spec.Workload)
-		if err != nil {
-			return err
+// collectTrackedResource deletes or releases a tracked resource according to its deletion policy, it returns true if
+// the resource is retained and should be still tracked
+func (h *appHandler) collectTrackedResource(ctx context.Context, tr v1beta1.TrackedResource) (bool, error) {
+	resource := new(unstructured.Unstructured)
+	resource.SetAPIVersion(tr.APIVersion)
+	resource.SetKind(tr.Kind)
+	resource.SetNamespace(tr.Namespace)
+	resource.SetName(tr.Name)
+	switch tr.DeletionPolicy {
+	case v1beta1.DeletionPolicyOrphan, v1beta1.DeletionPolicyRetain:
+		if err := h.r.Get(ctx, ctypes.NamespacedName{Namespace: tr.Namespace, Name: tr.Name}, resource); err != nil {
+			if apierrors.IsNotFound(err) {
+				return false, nil
+			}
+			return false, err
 		}
-		inDiffNamespace, err := h.checkCrossNamespace(u)
-		if err != nil {
-			return err
+		if tr.Retained {
+			return true, nil
 		}
-		if inDiffNamespace {
-			needTracker = true
-			break
+		releaseResource(resource, tr.DeletionPolicy == v1beta1.DeletionPolicyOrphan)
+		if err := h.r.Update(ctx, resource); err != nil {
+			return false, err
 		}
-	}
-outLoop:
-	for _, acComponent := range ac.Spec.Components {
-		for _, t := range acComponent.Traits {
-			u, err := oamutil.RawExtension2Unstructured(&t.Trait)
-			if err != nil {
-				return err
-			}
-			inDiffNamespace, err := h.checkCrossNamespace(u)
-			if err != nil {
-				return err
-			}
-			if inDiffNamespace {
-				needTracker = true
-				break outLoop
-			}
+		h.logger.Info("release resource removed from application", "kind", tr.Kind, "name", tr.Name,
+			"deletionPolicy", tr.DeletionPolicy)
+		return tr.DeletionPolicy == v1beta1.DeletionPolicyRetain, nil
+	default:
+		if err := h.r.Delete(ctx, resource); err != nil && !apierrors.IsNotFound(err) {
+			return false, err
 		}
+		return false, nil
 	}
-	if needTracker {
-		// check weather related resourceTracker is existed, if not create it
-		err := h.r.Get(ctx, ctypes.NamespacedName{Name: h.generateResourceTrackerName()}, resourceTracker)
-		if err == nil {
-			h.resourceTracker = resourceTracker
-			return nil
-		}
-		if apierrors.IsNotFound(err) {
-			resourceTracker = &v1beta1.ResourceTracker{
-				ObjectMeta: metav1.ObjectMeta{
-					Name: h.generateResourceTrackerName(),
-				},
-			}
-			if err = h.r.Client.Create(ctx, resourceTracker); err != nil {
-				return err
-			}
-			h.resourceTracker = resourceTracker
-			return nil
+}
+
+// releaseResource removes the ownerReferences of OAM resources so that the resource is not garbage collected with its
+// owners, an orphaned resource is also detached from the application by removing the labels of application
+func releaseResource(resource *unstructured.Unstructured, orphan bool) {
+	var owners []metav1.OwnerReference
+	for _, owner := range resource.GetOwnerReferences() {
+		if gv, err := schema.ParseGroupVersion(owner.APIVersion); err == nil && gv.Group == v1beta1.Group {
+			continue
 		}
+		owners = append(owners, owner)
+	}
+	resource.SetOwnerReferences(owners)
+	if !orphan {
+		return
+	}
+	labels := resource.GetLabels()
+	for _, label := range []string{oam.LabelAppName, oam.LabelAppComponent, oam.LabelAppComponentRevision, oam.LabelAppRevision} {
+		delete(labels, label)
+	}
+	resource.SetLabels(labels)
+}
+
+// handleResourceTracker gets the resourceTracker of application and set in appHandler field, if not existed create it.
+// The resourceTracker records all workloads and traits of application, and owns the cluster-scoped or across-namespace ones
+func (h *appHandler) handleResourceTracker(ctx context.Context) error {
+	resourceTracker := new(v1beta1.ResourceTracker)
+	err := h.r.Get(ctx, ctypes.NamespacedName{Name: h.generateResourceTrackerName()}, resourceTracker)
+	if err == nil {
+		h.resourceTracker = resourceTracker
+		return nil
+	}
+	if !apierrors.IsNotFound(err) {
 		return err
 	}
+	resourceTracker = &v1beta1.ResourceTracker{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: h.generateResourceTrackerName(),
+		},
+	}
+	if err = h.r.Client.Create(ctx, resourceTracker); err != nil {
+		return err
+	}
+	h.resourceTracker = resourceTracker
 	return nil
 }
 
@@ -784,6 +872,8 @@ func (h *appHandler) handleRollout(ctx context.Context) (reconcile.Result, error
 			Name:      h.app.Name,
 			Namespace: h.app.Namespace,
 			UID:       h.app.UID,
+			// the rollout plan is held by the deployment windows of the application
+			OwnerReferences: []metav1.OwnerReference{*metav1.NewControllerRef(h.app, v1beta1.ApplicationKindVersionKind)},
 		},
 		Spec: v1beta1.AppRolloutSpec{
 			SourceAppRevisionName: srcRevision,
@@ -794,6 +884,13 @@ func (h *appHandler) handleRollout(ctx context.Context) (reconcile.Result, error
 		Status: h.app.Status.Rollout,
 	}
 
+	// the deployment window override of the application applies to its rollout plan
+	for _, key := range []string{oam.AnnotationDeploymentWindowOverride, oam.AnnotationDeploymentWindowOverrideBy} {
+		if value, ok := h.app.Annotations[key]; ok {
+			oamutil.AddAnnotations(&appRollout, map[string]string{key: value})
+		}
+	}
+
 	// construct a fake rollout object and call rollout.DoReconcile
 	r := applicationrollout.NewReconciler(h.r.Client, h.r.dm, h.r.Recorder, h.r.Scheme)
 	res, err := r.DoReconcile(ctx, &appRollout)
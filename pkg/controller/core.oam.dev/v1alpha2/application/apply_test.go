@@ -28,11 +28,16 @@ import (
 	terraformapi "github.com/oam-dev/terraform-controller/api/v1beta1"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/pointer"
+	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	"github.com/oam-dev/kubevela/apis/core.oam.dev/common"
@@ -41,8 +46,11 @@ import (
 	velatypes "github.com/oam-dev/kubevela/apis/types"
 	"github.com/oam-dev/kubevela/pkg/appfile"
 	"github.com/oam-dev/kubevela/pkg/controller/utils"
+	"github.com/oam-dev/kubevela/pkg/oam"
+	"github.com/oam-dev/kubevela/pkg/oam/discoverymapper"
 	"github.com/oam-dev/kubevela/pkg/oam/util"
 	"github.com/oam-dev/kubevela/pkg/utils/apply"
+	common2 "github.com/oam-dev/kubevela/pkg/utils/common"
 )
 
 const workloadDefinition = `
@@ -341,3 +349,115 @@ var _ = Describe("Test statusAggregate", func() {
 		Expect(err).Should(BeNil())
 	})
 })
+
+var _ = Describe("Test resources tracked with deletion policies", func() {
+	ctx := context.Background()
+	ns := "default"
+	app := &v1beta1.Application{
+		ObjectMeta: metav1.ObjectMeta{Name: "app", Namespace: ns, UID: "app-uid",
+			Finalizers: []string{resourceTrackerFinalizer}},
+		Spec: v1beta1.ApplicationSpec{Components: []v1beta1.ApplicationComponent{{Name: "web"}}},
+	}
+	owner := metav1.OwnerReference{APIVersion: v1alpha2.SchemeGroupVersion.String(), Kind: v1alpha2.ApplicationContextKind,
+		Name: "app", UID: "app-context-uid", Controller: pointer.BoolPtr(true)}
+	newDeploy := func(name string) *appsv1.Deployment {
+		return &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: ns,
+			Labels:          map[string]string{oam.LabelAppName: "app", oam.LabelAppComponent: name},
+			OwnerReferences: []metav1.OwnerReference{owner}}}
+	}
+	newTracked := func(kind, name string, policy v1beta1.DeletionPolicy) v1beta1.TrackedResource {
+		apiVersion := "apps/v1"
+		if kind == "ConfigMap" {
+			apiVersion = "v1"
+		}
+		return v1beta1.TrackedResource{
+			TypedReference: v1beta1.TypedReference{APIVersion: apiVersion, Kind: kind, Namespace: ns, Name: name},
+			Component:      name, DeletionPolicy: policy}
+	}
+	newHandler := func(objs ...runtime.Object) *appHandler {
+		return &appHandler{
+			r: &Reconciler{
+				Client: fake.NewFakeClientWithScheme(common2.Scheme, objs...),
+				dm:     discoverymapper.NewStaticDiscoveryMapper(common2.Scheme),
+			},
+			app:     app.DeepCopy(),
+			logger:  ctrl.Log.WithName("application"),
+			inplace: true,
+		}
+	}
+
+	It("records rendered resources with deletion policies", func() {
+		h := newHandler()
+		workload := &unstructured.Unstructured{}
+		workload.SetAPIVersion("apps/v1")
+		workload.SetKind("Deployment")
+		workload.SetAnnotations(map[string]string{oam.AnnotationDeletionPolicy: string(v1beta1.DeletionPolicyRetain)})
+		Expect(h.recodeTrackedResource("web", "web", util.Object2RawExtension(workload))).Should(BeNil())
+		role := &unstructured.Unstructured{}
+		role.SetAPIVersion("rbac.authorization.k8s.io/v1")
+		role.SetKind("ClusterRole")
+		Expect(h.recodeTrackedResource("web", "web-role", util.Object2RawExtension(role))).Should(BeNil())
+		Expect(h.trackedResources).Should(Equal([]v1beta1.TrackedResource{
+			newTracked("Deployment", "web", v1beta1.DeletionPolicyRetain),
+			{
+				TypedReference: v1beta1.TypedReference{APIVersion: "rbac.authorization.k8s.io/v1", Kind: "ClusterRole", Name: "web-role"},
+				Component:      "web", DeletionPolicy: v1beta1.DeletionPolicyDelete,
+			},
+		}))
+	})
+
+	It("collects resources removed from application by deletion policies", func() {
+		retained := newTracked("ConfigMap", "config", v1beta1.DeletionPolicyRetain)
+		retained.Retained = true
+		rt := &v1beta1.ResourceTracker{ObjectMeta: metav1.ObjectMeta{Name: "default-app"},
+			Status: v1beta1.ResourceTrackerStatus{TrackedResources: []v1beta1.TrackedResource{
+				newTracked("Deployment", "web", v1beta1.DeletionPolicyDelete),
+				newTracked("Deployment", "api", v1beta1.DeletionPolicyDelete),
+				newTracked("Deployment", "cache", v1beta1.DeletionPolicyOrphan),
+				newTracked("Deployment", "db", v1beta1.DeletionPolicyRetain),
+				retained,
+			}}}
+		h := newHandler(rt, newDeploy("web"), newDeploy("api"), newDeploy("cache"), newDeploy("db"))
+		h.trackedResources = []v1beta1.TrackedResource{newTracked("Deployment", "web", v1beta1.DeletionPolicyDelete)}
+		Expect(gcTrackedResources(ctx, h)).Should(BeNil())
+
+		deploy := &appsv1.Deployment{}
+		Expect(h.r.Get(ctx, types.NamespacedName{Namespace: ns, Name: "api"}, deploy)).Should(util.NotFoundMatcher{})
+		Expect(h.r.Get(ctx, types.NamespacedName{Namespace: ns, Name: "cache"}, deploy)).Should(BeNil())
+		Expect(deploy.OwnerReferences).Should(BeEmpty())
+		Expect(deploy.Labels).Should(BeEmpty())
+		Expect(h.r.Get(ctx, types.NamespacedName{Namespace: ns, Name: "db"}, deploy)).Should(BeNil())
+		Expect(deploy.OwnerReferences).Should(BeEmpty())
+		Expect(deploy.Labels).Should(HaveKeyWithValue(oam.LabelAppName, "app"))
+
+		checkRt := &v1beta1.ResourceTracker{}
+		Expect(h.r.Get(ctx, types.NamespacedName{Name: "default-app"}, checkRt)).Should(BeNil())
+		db := newTracked("Deployment", "db", v1beta1.DeletionPolicyRetain)
+		db.Retained = true
+		Expect(checkRt.Status.TrackedResources).Should(Equal([]v1beta1.TrackedResource{
+			newTracked("Deployment", "web", v1beta1.DeletionPolicyDelete), db}))
+		Expect(h.app.Status.ResourceTracker.Name).Should(Equal("default-app"))
+	})
+
+	It("keeps orphaned and retained resources on application deletion", func() {
+		rt := &v1beta1.ResourceTracker{ObjectMeta: metav1.ObjectMeta{Name: "default-app"},
+			Status: v1beta1.ResourceTrackerStatus{TrackedResources: []v1beta1.TrackedResource{
+				newTracked("Deployment", "web", v1beta1.DeletionPolicyDelete),
+				newTracked("Deployment", "cache", v1beta1.DeletionPolicyOrphan),
+			}}}
+		appContext := &v1alpha2.ApplicationContext{ObjectMeta: metav1.ObjectMeta{Name: "app", Namespace: ns,
+			OwnerReferences: []metav1.OwnerReference{*metav1.NewControllerRef(app, v1beta1.ApplicationKindVersionKind)}}}
+		h := newHandler(rt, appContext, newDeploy("web"), newDeploy("cache"))
+		needUpdate, err := h.removeResourceTracker(ctx)
+		Expect(err).Should(BeNil())
+		Expect(needUpdate).Should(BeTrue())
+		Expect(h.app.Finalizers).Should(BeEmpty())
+
+		Expect(h.r.Get(ctx, types.NamespacedName{Namespace: ns, Name: "app"}, &v1alpha2.ApplicationContext{})).Should(util.NotFoundMatcher{})
+		Expect(h.r.Get(ctx, types.NamespacedName{Name: "default-app"}, &v1beta1.ResourceTracker{})).Should(util.NotFoundMatcher{})
+		deploy := &appsv1.Deployment{}
+		Expect(h.r.Get(ctx, types.NamespacedName{Namespace: ns, Name: "web"}, deploy)).Should(util.NotFoundMatcher{})
+		Expect(h.r.Get(ctx, types.NamespacedName{Namespace: ns, Name: "cache"}, deploy)).Should(BeNil())
+		Expect(deploy.OwnerReferences).Should(BeEmpty())
+	})
+})
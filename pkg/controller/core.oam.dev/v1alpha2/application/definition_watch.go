@@ -0,0 +1,178 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package application
+
+import (
+	"context"
+
+	"github.com/go-logr/logr"
+	"golang.org/x/time/rate"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
+	"github.com/oam-dev/kubevela/pkg/oam"
+	oamutil "github.com/oam-dev/kubevela/pkg/oam/util"
+)
+
+const (
+	// componentDefinitionIndex indexes Applications by the ComponentDefinitions their components reference
+	componentDefinitionIndex = "spec.components.type"
+	// traitDefinitionIndex indexes Applications by the TraitDefinitions their traits reference
+	traitDefinitionIndex = "spec.components.traits.type"
+)
+
+// componentDefinitionsOf returns the ComponentDefinitions referenced by an Application.
+// Components pinned to a definition revision such as worker@v2 are not indexed as they never follow definition changes.
+func componentDefinitionsOf(obj runtime.Object) []string {
+	app, ok := obj.(*v1beta1.Application)
+	if !ok {
+		return nil
+	}
+	var refs []string
+	for _, comp := range app.Spec.Components {
+		refs = append(refs, comp.Type)
+	}
+	return unpinnedDefinitions(refs)
+}
+
+// traitDefinitionsOf returns the TraitDefinitions referenced by an Application, pinned ones are not indexed
+func traitDefinitionsOf(obj runtime.Object) []string {
+	app, ok := obj.(*v1beta1.Application)
+	if !ok {
+		return nil
+	}
+	var refs []string
+	for _, comp := range app.Spec.Components {
+		for _, trait := range comp.Traits {
+			refs = append(refs, trait.Type)
+		}
+	}
+	return unpinnedDefinitions(refs)
+}
+
+func unpinnedDefinitions(refs []string) []string {
+	var names []string
+	seen := make(map[string]bool)
+	for _, t := range refs {
+		if _, err := oamutil.ConvertDefinitionRevName(t); err == nil || seen[t] {
+			continue
+		}
+		seen[t] = true
+		names = append(names, t)
+	}
+	return names
+}
+
+// definitionChangeHandler enqueues Applications referencing a changed definition, they will be rendered again
+// with the new definition. Requests are throttled by the limiter so that a definition used by lots of Applications
+// won't re-render all of them at once.
+type definitionChangeHandler struct {
+	client  client.Reader
+	log     logr.Logger
+	index   string
+	extract client.IndexerFunc
+	limiter *rate.Limiter
+}
+
+var _ handler.EventHandler = &definitionChangeHandler{}
+
+func newDefinitionChangeHandler(c client.Reader, log logr.Logger, index string, extract client.IndexerFunc, qps float64, burst int) *definitionChangeHandler {
+	limit := rate.Limit(qps)
+	if qps <= 0 {
+		limit = rate.Inf
+	}
+	if burst < 1 {
+		burst = 1
+	}
+	return &definitionChangeHandler{
+		client:  c,
+		log:     log,
+		index:   index,
+		extract: extract,
+		limiter: rate.NewLimiter(limit, burst),
+	}
+}
+
+// Create enqueues Applications which are waiting for a missing definition
+func (h *definitionChangeHandler) Create(evt event.CreateEvent, q workqueue.RateLimitingInterface) {
+	h.enqueue(evt.Meta, q)
+}
+
+// Update enqueues Applications only if the spec of the definition is changed
+func (h *definitionChangeHandler) Update(evt event.UpdateEvent, q workqueue.RateLimitingInterface) {
+	if evt.MetaOld != nil && evt.MetaNew != nil && evt.MetaOld.GetGeneration() == evt.MetaNew.GetGeneration() {
+		return
+	}
+	h.enqueue(evt.MetaNew, q)
+}
+
+// Delete enqueues Applications so that they report the missing definition
+func (h *definitionChangeHandler) Delete(evt event.DeleteEvent, q workqueue.RateLimitingInterface) {
+	h.enqueue(evt.Meta, q)
+}
+
+// Generic does nothing
+func (h *definitionChangeHandler) Generic(event.GenericEvent, workqueue.RateLimitingInterface) {}
+
+func (h *definitionChangeHandler) enqueue(def metav1.Object, q workqueue.RateLimitingInterface) {
+	if def == nil {
+		return
+	}
+	for _, req := range h.findApps(def.GetNamespace(), def.GetName()) {
+		q.AddAfter(req, h.limiter.Reserve().Delay())
+	}
+}
+
+// findApps finds Applications referencing the definition, a definition in the system definition namespace
+// could be referenced by Applications in any namespace
+func (h *definitionChangeHandler) findApps(namespace, name string) []reconcile.Request {
+	opts := []client.ListOption{client.MatchingFields{h.index: name}}
+	if namespace != "" && namespace != oam.SystemDefinitonNamespace {
+		opts = append(opts, client.InNamespace(namespace))
+	}
+	apps := new(v1beta1.ApplicationList)
+	if err := h.client.List(context.Background(), apps, opts...); err != nil {
+		h.log.Error(err, "cannot list applications referencing definition", "definition", name)
+		return nil
+	}
+	var requests []reconcile.Request
+	for i := range apps.Items {
+		app := &apps.Items[i]
+		// double check the reference in case the reader doesn't support field selectors
+		if !containsString(h.extract(app), name) {
+			continue
+		}
+		requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{Namespace: app.Namespace, Name: app.Name}})
+	}
+	return requests
+}
+
+func containsString(items []string, s string) bool {
+	for _, item := range items {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
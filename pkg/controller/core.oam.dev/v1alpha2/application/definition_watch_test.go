@@ -0,0 +1,112 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package application
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/workqueue"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
+	"github.com/oam-dev/kubevela/pkg/oam"
+	"github.com/oam-dev/kubevela/pkg/utils/common"
+)
+
+var _ = Describe("Test re-rendering applications when definitions change", func() {
+	newApp := func(namespace, name string, comps ...v1beta1.ApplicationComponent) *v1beta1.Application {
+		return &v1beta1.Application{
+			ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name},
+			Spec:       v1beta1.ApplicationSpec{Components: comps},
+		}
+	}
+	apps := []*v1beta1.Application{
+		newApp("ns-a", "latest", v1beta1.ApplicationComponent{Name: "c1", Type: "worker",
+			Traits: []v1beta1.ApplicationTrait{{Type: "scaler"}, {Type: "ingress@v1"}}}),
+		newApp("ns-a", "pinned", v1beta1.ApplicationComponent{Name: "c1", Type: "worker@v2"}),
+		newApp("ns-b", "other-ns", v1beta1.ApplicationComponent{Name: "c1", Type: "worker"},
+			v1beta1.ApplicationComponent{Name: "c2", Type: "worker", Traits: []v1beta1.ApplicationTrait{{Type: "scaler"}}}),
+	}
+
+	drain := func(q workqueue.RateLimitingInterface, timeout time.Duration) []string {
+		var keys []string
+		deadline := time.After(timeout)
+		for {
+			select {
+			case <-deadline:
+				return keys
+			default:
+			}
+			if q.Len() == 0 {
+				time.Sleep(10 * time.Millisecond)
+				continue
+			}
+			item, _ := q.Get()
+			keys = append(keys, item.(ctrl.Request).String())
+			q.Done(item)
+		}
+	}
+
+	It("Test indexing definitions referenced by applications", func() {
+		Expect(componentDefinitionsOf(apps[0])).Should(Equal([]string{"worker"}))
+		Expect(traitDefinitionsOf(apps[0])).Should(Equal([]string{"scaler"}))
+		Expect(componentDefinitionsOf(apps[1])).Should(BeEmpty())
+		Expect(componentDefinitionsOf(apps[2])).Should(Equal([]string{"worker"}))
+		Expect(componentDefinitionsOf(&v1beta1.ComponentDefinition{})).Should(BeNil())
+	})
+
+	It("Test enqueuing applications referencing the changed definition", func() {
+		cli := fake.NewFakeClientWithScheme(common.Scheme, apps[0], apps[1], apps[2])
+		h := newDefinitionChangeHandler(cli, ctrl.Log, componentDefinitionIndex, componentDefinitionsOf, 0, 0)
+		q := workqueue.NewRateLimitingQueue(workqueue.DefaultControllerRateLimiter())
+		defer q.ShutDown()
+
+		systemDef := &v1beta1.ComponentDefinition{ObjectMeta: metav1.ObjectMeta{Namespace: oam.SystemDefinitonNamespace, Name: "worker", Generation: 2}}
+		oldDef := systemDef.DeepCopy()
+		oldDef.Generation = 1
+		h.Update(event.UpdateEvent{MetaOld: oldDef, ObjectOld: oldDef, MetaNew: systemDef, ObjectNew: systemDef}, q)
+		Expect(drain(q, 200*time.Millisecond)).Should(ConsistOf("ns-a/latest", "ns-b/other-ns"))
+
+		// status only changes are ignored
+		h.Update(event.UpdateEvent{MetaOld: systemDef, ObjectOld: systemDef, MetaNew: systemDef, ObjectNew: systemDef}, q)
+		Expect(drain(q, 100*time.Millisecond)).Should(BeEmpty())
+
+		// a definition in the app namespace only affects applications in the same namespace
+		nsDef := &v1beta1.ComponentDefinition{ObjectMeta: metav1.ObjectMeta{Namespace: "ns-b", Name: "worker"}}
+		h.Delete(event.DeleteEvent{Meta: nsDef, Object: nsDef}, q)
+		Expect(drain(q, 100*time.Millisecond)).Should(ConsistOf("ns-b/other-ns"))
+	})
+
+	It("Test throttling re-rendering", func() {
+		cli := fake.NewFakeClientWithScheme(common.Scheme, apps[0], apps[2])
+		h := newDefinitionChangeHandler(cli, ctrl.Log, traitDefinitionIndex, traitDefinitionsOf, 2, 1)
+		q := workqueue.NewRateLimitingQueue(workqueue.DefaultControllerRateLimiter())
+		defer q.ShutDown()
+		def := &v1beta1.TraitDefinition{ObjectMeta: metav1.ObjectMeta{Namespace: oam.SystemDefinitonNamespace, Name: "scaler"}}
+		h.Create(event.CreateEvent{Meta: def, Object: def}, q)
+		// only one application is re-rendered at once, the other one is delayed by 1/qps
+		Expect(drain(q, 100*time.Millisecond)).Should(HaveLen(1))
+		Expect(drain(q, time.Second)).Should(Equal([]string{types.NamespacedName{Namespace: "ns-b", Name: "other-ns"}.String()}))
+	})
+})
@@ -0,0 +1,151 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package application
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/common"
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
+	"github.com/oam-dev/kubevela/pkg/dsl/definition"
+	"github.com/oam-dev/kubevela/pkg/dsl/process"
+)
+
+const (
+	// contextComponents is the field in the context of app health policy holding the status of components
+	contextComponents = "components"
+
+	infoFmtWaitReadinessGates = "waiting for readiness gates: %s"
+)
+
+// healthAggregate decides the health of the application from the status of components by the app health policy,
+// all components and traits are required to be healthy if there is no policy. The application is not healthy until
+// all readiness gates are ready, status of readiness gates are recorded in the application status.
+func (h *appHandler) healthAggregate(ctx context.Context, appStatus []common.ApplicationComponentStatus, componentsHealthy bool) (bool, string, error) {
+	health := h.app.Spec.Health
+	if health == nil {
+		h.app.Status.ReadinessGates = nil
+		return componentsHealthy, "", nil
+	}
+
+	healthy, message := componentsHealthy, ""
+	if health.Policy != "" {
+		var err error
+		templateContext := map[string]interface{}{
+			process.ContextAppName: h.app.Name,
+			contextComponents:      componentsContext(appStatus),
+		}
+		if healthy, message, err = definition.EvalHealthPolicy(templateContext, health.Policy); err != nil {
+			return false, "", errors.WithMessagef(err, "app=%s, evaluate app health policy error", h.app.Name)
+		}
+	}
+
+	var gates []common.ReadinessGateStatus
+	var notReady []string
+	for _, gate := range health.ReadinessGates {
+		status, err := h.checkReadinessGate(ctx, gate)
+		if err != nil {
+			return false, "", errors.WithMessagef(err, "app=%s, gate=%s, check readiness gate error", h.app.Name, gate.Name)
+		}
+		if !status.Ready {
+			notReady = append(notReady, gate.Name)
+		}
+		gates = append(gates, status)
+	}
+	h.app.Status.ReadinessGates = gates
+	if len(notReady) > 0 {
+		healthy = false
+		messages := []string{fmt.Sprintf(infoFmtWaitReadinessGates, strings.Join(notReady, ", "))}
+		if message != "" {
+			messages = append([]string{message}, messages...)
+		}
+		message = strings.Join(messages, "; ")
+	}
+	return healthy, message, nil
+}
+
+// componentsContext converts the status of components to the context of app health policy, components are keyed by
+// names and traits are keyed by types
+func componentsContext(appStatus []common.ApplicationComponentStatus) map[string]interface{} {
+	components := make(map[string]interface{}, len(appStatus))
+	for _, status := range appStatus {
+		traits := make(map[string]interface{}, len(status.Traits))
+		for _, t := range status.Traits {
+			traits[t.Type] = map[string]interface{}{
+				"healthy": t.Healthy,
+				"message": t.Message,
+			}
+		}
+		components[status.Name] = map[string]interface{}{
+			"healthy": status.Healthy,
+			"message": status.Message,
+			"traits":  traits,
+		}
+	}
+	return components
+}
+
+// checkReadinessGate checks the resource of a readiness gate by its health policy, or by the status of a condition,
+// the gate is ready once the resource exists if neither is specified
+func (h *appHandler) checkReadinessGate(ctx context.Context, gate v1beta1.ReadinessGate) (common.ReadinessGateStatus, error) {
+	status := common.ReadinessGateStatus{Name: gate.Name}
+	obj := &unstructured.Unstructured{}
+	obj.SetAPIVersion(gate.APIVersion)
+	obj.SetKind(gate.Kind)
+	// the status of the resource is exposed in the application, so the gate is restricted to the namespace of the application
+	if err := h.r.Get(ctx, client.ObjectKey{Namespace: h.app.Namespace, Name: gate.ResourceName}, obj); err != nil {
+		if apierrors.IsNotFound(err) {
+			status.Message = fmt.Sprintf("%s %s is not found", gate.Kind, gate.ResourceName)
+			return status, nil
+		}
+		return status, err
+	}
+
+	switch {
+	case gate.HealthPolicy != "":
+		ready, message, err := definition.EvalHealthPolicy(map[string]interface{}{definition.OutputFieldName: obj.Object}, gate.HealthPolicy)
+		if err != nil {
+			return status, err
+		}
+		status.Ready, status.Message = ready, message
+	case gate.ConditionType != "":
+		status.Message = fmt.Sprintf("condition %s of %s %s is not true", gate.ConditionType, gate.Kind, gate.ResourceName)
+		conditions, _, _ := unstructured.NestedSlice(obj.Object, "status", "conditions")
+		for _, c := range conditions {
+			cond, ok := c.(map[string]interface{})
+			if !ok || cond["type"] != gate.ConditionType {
+				continue
+			}
+			if cond["status"] == "True" {
+				status.Ready, status.Message = true, ""
+			} else if message, ok := cond["message"].(string); ok && message != "" {
+				status.Message = message
+			}
+			break
+		}
+	default:
+		status.Ready = true
+	}
+	return status, nil
+}
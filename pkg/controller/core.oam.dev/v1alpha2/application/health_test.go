@@ -0,0 +1,148 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package application
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/common"
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
+	common2 "github.com/oam-dev/kubevela/pkg/utils/common"
+)
+
+var _ = Describe("Test application health policy and readiness gates", func() {
+	ctx := context.Background()
+	appStatus := []common.ApplicationComponentStatus{
+		{Name: "us", Healthy: true},
+		{Name: "eu", Healthy: false, Message: "0/1 ready"},
+		{Name: "cn", Healthy: true, Traits: []common.ApplicationTraitStatus{{Type: "ingress", Healthy: false}}},
+	}
+	newHandler := func(health *v1beta1.AppHealth, objs ...runtime.Object) *appHandler {
+		return &appHandler{
+			r: &Reconciler{Client: fake.NewFakeClientWithScheme(common2.Scheme, objs...)},
+			app: &v1beta1.Application{
+				ObjectMeta: metav1.ObjectMeta{Name: "app", Namespace: "default"},
+				Spec:       v1beta1.ApplicationSpec{Health: health},
+			},
+		}
+	}
+	newJob := func(status corev1.ConditionStatus) *batchv1.Job {
+		return &batchv1.Job{
+			ObjectMeta: metav1.ObjectMeta{Name: "migrate", Namespace: "default"},
+			Status: batchv1.JobStatus{Conditions: []batchv1.JobCondition{
+				{Type: batchv1.JobComplete, Status: status, Message: "migrating"},
+			}},
+		}
+	}
+	jobGate := v1beta1.ReadinessGate{Name: "db-migrated", APIVersion: "batch/v1", Kind: "Job", ResourceName: "migrate",
+		ConditionType: string(batchv1.JobComplete)}
+
+	It("requires all components healthy without health policy", func() {
+		h := newHandler(nil)
+		healthy, message, err := h.healthAggregate(ctx, appStatus, false)
+		Expect(err).Should(BeNil())
+		Expect(healthy).Should(BeFalse())
+		Expect(message).Should(BeEmpty())
+		Expect(h.app.Status.ReadinessGates).Should(BeNil())
+	})
+
+	It("evaluates health policy with status of components", func() {
+		h := newHandler(&v1beta1.AppHealth{Policy: `
+healthy: len([ for c in context.components if c.healthy {c}])
+isHealth: healthy >= 2
+message: "\(healthy) of 3 regions are healthy"`})
+		healthy, message, err := h.healthAggregate(ctx, appStatus, false)
+		Expect(err).Should(BeNil())
+		Expect(healthy).Should(BeTrue())
+		Expect(message).Should(Equal("2 of 3 regions are healthy"))
+
+		h = newHandler(&v1beta1.AppHealth{Policy: `isHealth: context.components.cn.traits.ingress.healthy`})
+		healthy, _, err = h.healthAggregate(ctx, appStatus, true)
+		Expect(err).Should(BeNil())
+		Expect(healthy).Should(BeFalse())
+
+		h = newHandler(&v1beta1.AppHealth{Policy: `isHealth: context.components.cache.healthy`})
+		_, _, err = h.healthAggregate(ctx, appStatus, true)
+		Expect(err).ShouldNot(BeNil())
+	})
+
+	It("waits for readiness gates", func() {
+		By("resource of the gate is not found")
+		h := newHandler(&v1beta1.AppHealth{ReadinessGates: []v1beta1.ReadinessGate{jobGate}})
+		healthy, message, err := h.healthAggregate(ctx, appStatus, true)
+		Expect(err).Should(BeNil())
+		Expect(healthy).Should(BeFalse())
+		Expect(message).Should(Equal("waiting for readiness gates: db-migrated"))
+		Expect(h.app.Status.ReadinessGates).Should(Equal([]common.ReadinessGateStatus{
+			{Name: "db-migrated", Message: "Job migrate is not found"},
+		}))
+
+		By("condition of the gate is not true")
+		h = newHandler(&v1beta1.AppHealth{ReadinessGates: []v1beta1.ReadinessGate{jobGate}}, newJob(corev1.ConditionFalse))
+		healthy, _, err = h.healthAggregate(ctx, appStatus, true)
+		Expect(err).Should(BeNil())
+		Expect(healthy).Should(BeFalse())
+		Expect(h.app.Status.ReadinessGates).Should(Equal([]common.ReadinessGateStatus{
+			{Name: "db-migrated", Message: "migrating"},
+		}))
+
+		By("resource of the gate is in another namespace")
+		otherJob := newJob(corev1.ConditionTrue)
+		otherJob.Namespace = "other"
+		h = newHandler(&v1beta1.AppHealth{ReadinessGates: []v1beta1.ReadinessGate{jobGate}}, otherJob)
+		healthy, _, err = h.healthAggregate(ctx, appStatus, true)
+		Expect(err).Should(BeNil())
+		Expect(healthy).Should(BeFalse())
+		Expect(h.app.Status.ReadinessGates).Should(Equal([]common.ReadinessGateStatus{
+			{Name: "db-migrated", Message: "Job migrate is not found"},
+		}))
+
+		By("condition of the gate is true")
+		h = newHandler(&v1beta1.AppHealth{ReadinessGates: []v1beta1.ReadinessGate{jobGate}}, newJob(corev1.ConditionTrue))
+		healthy, message, err = h.healthAggregate(ctx, appStatus, true)
+		Expect(err).Should(BeNil())
+		Expect(healthy).Should(BeTrue())
+		Expect(message).Should(BeEmpty())
+		Expect(h.app.Status.ReadinessGates).Should(Equal([]common.ReadinessGateStatus{{Name: "db-migrated", Ready: true}}))
+
+		By("health policy of the gate")
+		policyGate := jobGate
+		policyGate.ConditionType = ""
+		policyGate.HealthPolicy = `isHealth: len(context.output.status.conditions) > 1
+message: "\(len(context.output.status.conditions)) conditions"`
+		h = newHandler(&v1beta1.AppHealth{
+			Policy:         `isHealth: true`,
+			ReadinessGates: []v1beta1.ReadinessGate{policyGate},
+		}, newJob(corev1.ConditionTrue))
+		healthy, message, err = h.healthAggregate(ctx, appStatus, false)
+		Expect(err).Should(BeNil())
+		Expect(healthy).Should(BeFalse())
+		Expect(message).Should(Equal("waiting for readiness gates: db-migrated"))
+		Expect(h.app.Status.ReadinessGates).Should(Equal([]common.ReadinessGateStatus{
+			{Name: "db-migrated", Message: "1 conditions"},
+		}))
+	})
+})
@@ -162,6 +162,7 @@ func (h *appHandler) compareWithLastRevisionSpec(ctx context.Context, newAppRevi
 		// align the name and resourceVersion
 		newAppRevision.Name = lastAppRevision.Name
 		newAppRevision.ResourceVersion = lastAppRevision.ResourceVersion
+		newAppRevision.Spec.Change = lastAppRevision.Spec.Change
 		return false, nil
 	}
 	// if reach here, it's same hash but different spec
@@ -181,6 +182,9 @@ func (h *appHandler) GenerateAppRevision(ctx context.Context) (*v1beta1.Applicat
 	}
 	if isNewRev {
 		appRev.Name, _ = utils.GetAppNextRevision(h.app)
+		if err := h.recordRevisionChange(ctx, appRev); err != nil {
+			return appRev, err
+		}
 	}
 	h.isNewRevision = isNewRev
 	h.revisionHash = appRevisionHash
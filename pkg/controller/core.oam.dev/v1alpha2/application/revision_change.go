@@ -0,0 +1,194 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package application
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"time"
+
+	"github.com/pkg/errors"
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
+	"github.com/oam-dev/kubevela/pkg/oam"
+)
+
+// recordRevisionChange records the audit trail of a new revision. The user, time and message of the change are read
+// from annotations of the application, and the changes are summarized by comparing with the previous revision.
+func (h *appHandler) recordRevisionChange(ctx context.Context, appRev *v1beta1.ApplicationRevision) error {
+	annotations := h.app.GetAnnotations()
+	change := &v1beta1.RevisionChange{
+		User:    annotations[oam.AnnotationChangedBy],
+		Message: annotations[oam.AnnotationChangeMessage],
+		Time:    metav1.Now(),
+	}
+	if t, err := time.Parse(time.RFC3339, annotations[oam.AnnotationChangedAt]); err == nil {
+		change.Time = metav1.NewTime(t)
+	}
+	appRev.Spec.Change = change
+	if h.app.Status.LatestRevision == nil {
+		return nil
+	}
+	prevRev := &v1beta1.ApplicationRevision{}
+	if err := h.r.Get(ctx, client.ObjectKey{Name: h.app.Status.LatestRevision.Name, Namespace: h.app.Namespace},
+		prevRev); err != nil {
+		if apierrors.IsNotFound(err) {
+			// the previous revision is cleaned up, there is nothing to compare with
+			return nil
+		}
+		return errors.Wrapf(err, "fail to get applicationRevision %s", h.app.Status.LatestRevision.Name)
+	}
+	var err error
+	change.PreviousRevision = prevRev.Name
+	change.Components, err = diffComponents(prevRev.Spec.Application.Spec.Components, appRev.Spec.Application.Spec.Components)
+	if err != nil {
+		return errors.WithMessage(err, "cannot compare components with the previous revision")
+	}
+	change.Definitions = diffDefinitions(prevRev, appRev)
+	if apiequality.Semantic.DeepEqual(prevRev.Spec.Application.Spec, appRev.Spec.Application.Spec) {
+		// only definitions are changed, the user and message of the last change to the application don't apply
+		change.User, change.Message = "", ""
+		change.Time = metav1.Now()
+	}
+	return nil
+}
+
+// diffComponents summarizes the changes from the old components to the new ones
+func diffComponents(oldComps, newComps []v1beta1.ApplicationComponent) ([]v1beta1.ComponentChange, error) {
+	var changes []v1beta1.ComponentChange
+	oldByName := make(map[string]v1beta1.ApplicationComponent, len(oldComps))
+	for _, comp := range oldComps {
+		oldByName[comp.Name] = comp
+	}
+	newNames := make(map[string]bool, len(newComps))
+	for _, comp := range newComps {
+		newNames[comp.Name] = true
+		oldComp, ok := oldByName[comp.Name]
+		if !ok {
+			changes = append(changes, v1beta1.ComponentChange{Name: comp.Name, Action: v1beta1.ComponentAdded})
+			continue
+		}
+		oldFields, err := componentFields(oldComp)
+		if err != nil {
+			return nil, err
+		}
+		newFields, err := componentFields(comp)
+		if err != nil {
+			return nil, err
+		}
+		if fields := diffFields("", oldFields, newFields); len(fields) > 0 {
+			changes = append(changes, v1beta1.ComponentChange{Name: comp.Name, Action: v1beta1.ComponentModified, Fields: fields})
+		}
+	}
+	for _, comp := range oldComps {
+		if !newNames[comp.Name] {
+			changes = append(changes, v1beta1.ComponentChange{Name: comp.Name, Action: v1beta1.ComponentRemoved})
+		}
+	}
+	return changes, nil
+}
+
+// componentFields converts a component to a map, traits are keyed by their types so that a changed trait is
+// reported as traits.<type> rather than the whole list of traits
+func componentFields(comp v1beta1.ApplicationComponent) (map[string]interface{}, error) {
+	data, err := json.Marshal(comp)
+	if err != nil {
+		return nil, err
+	}
+	fields := map[string]interface{}{}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+	traits, ok := fields["traits"].([]interface{})
+	if !ok {
+		return fields, nil
+	}
+	traitsByType := make(map[string]interface{}, len(traits))
+	for i, trait := range traits {
+		key := fmt.Sprint(trait.(map[string]interface{})["type"])
+		if _, exist := traitsByType[key]; exist {
+			key = fmt.Sprintf("%s[%d]", key, i)
+		}
+		traitsByType[key] = trait
+	}
+	fields["traits"] = traitsByType
+	return fields, nil
+}
+
+// diffFields returns the sorted paths of changed fields, objects are compared recursively and other values are
+// compared as a whole
+func diffFields(path string, oldValue, newValue interface{}) []string {
+	oldMap, oldIsMap := oldValue.(map[string]interface{})
+	newMap, newIsMap := newValue.(map[string]interface{})
+	if !oldIsMap || !newIsMap {
+		if reflect.DeepEqual(oldValue, newValue) {
+			return nil
+		}
+		return []string{path}
+	}
+	keys := make(map[string]bool, len(oldMap)+len(newMap))
+	for k := range oldMap {
+		keys[k] = true
+	}
+	for k := range newMap {
+		keys[k] = true
+	}
+	var fields []string
+	for k := range keys {
+		sub := k
+		if path != "" {
+			sub = path + "." + k
+		}
+		fields = append(fields, diffFields(sub, oldMap[k], newMap[k])...)
+	}
+	sort.Strings(fields)
+	return fields
+}
+
+// diffDefinitions returns the sorted definitions used by both revisions whose spec is changed
+func diffDefinitions(oldRev, newRev *v1beta1.ApplicationRevision) []string {
+	var defs []string
+	for name, cd := range newRev.Spec.ComponentDefinitions {
+		if old, ok := oldRev.Spec.ComponentDefinitions[name]; ok && !apiequality.Semantic.DeepEqual(old.Spec, cd.Spec) {
+			defs = append(defs, "componentDefinition/"+name)
+		}
+	}
+	for name, wd := range newRev.Spec.WorkloadDefinitions {
+		if old, ok := oldRev.Spec.WorkloadDefinitions[name]; ok && !apiequality.Semantic.DeepEqual(old.Spec, wd.Spec) {
+			defs = append(defs, "workloadDefinition/"+name)
+		}
+	}
+	for name, td := range newRev.Spec.TraitDefinitions {
+		if old, ok := oldRev.Spec.TraitDefinitions[name]; ok && !apiequality.Semantic.DeepEqual(old.Spec, td.Spec) {
+			defs = append(defs, "traitDefinition/"+name)
+		}
+	}
+	for name, sd := range newRev.Spec.ScopeDefinitions {
+		if old, ok := oldRev.Spec.ScopeDefinitions[name]; ok && !apiequality.Semantic.DeepEqual(old.Spec, sd.Spec) {
+			defs = append(defs, "scopeDefinition/"+name)
+		}
+	}
+	sort.Strings(defs)
+	return defs
+}
@@ -0,0 +1,112 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package application
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
+)
+
+var _ = Describe("Test revision change summary", func() {
+	raw := func(s string) runtime.RawExtension {
+		return runtime.RawExtension{Raw: []byte(s)}
+	}
+
+	It("Test diffComponents", func() {
+		oldComps := []v1beta1.ApplicationComponent{{
+			Name:       "frontend",
+			Type:       "webservice",
+			Properties: raw(`{"image":"nginx:1.20","port":80,"env":[{"name":"A","value":"1"}]}`),
+			Traits: []v1beta1.ApplicationTrait{
+				{Type: "scaler", Properties: raw(`{"replicas":1}`)},
+				{Type: "ingress", Properties: raw(`{"domain":"a.com"}`)},
+			},
+		}, {
+			Name:       "cache",
+			Type:       "worker",
+			Properties: raw(`{"image":"redis"}`),
+		}, {
+			Name:       "backend",
+			Type:       "worker",
+			Properties: raw(`{"image":"busybox"}`),
+		}}
+		newComps := []v1beta1.ApplicationComponent{{
+			Name:       "frontend",
+			Type:       "webservice",
+			Properties: raw(`{"image":"nginx:1.21","port":80,"env":[{"name":"A","value":"2"}]}`),
+			Traits: []v1beta1.ApplicationTrait{
+				{Type: "scaler", Properties: raw(`{"replicas":3}`)},
+				{Type: "sidecar", Properties: raw(`{"image":"fluentd"}`)},
+			},
+			DeletionPolicy: v1beta1.DeletionPolicyOrphan,
+		}, {
+			Name:       "backend",
+			Type:       "worker",
+			Properties: raw(`{"image":"busybox"}`),
+		}, {
+			Name:       "db",
+			Type:       "worker",
+			Properties: raw(`{"image":"mysql"}`),
+		}}
+		changes, err := diffComponents(oldComps, newComps)
+		Expect(err).Should(BeNil())
+		Expect(changes).Should(Equal([]v1beta1.ComponentChange{{
+			Name:   "frontend",
+			Action: v1beta1.ComponentModified,
+			Fields: []string{"deletionPolicy", "properties.env", "properties.image", "traits.ingress",
+				"traits.scaler.properties.replicas", "traits.sidecar"},
+		}, {
+			Name:   "db",
+			Action: v1beta1.ComponentAdded,
+		}, {
+			Name:   "cache",
+			Action: v1beta1.ComponentRemoved,
+		}}))
+
+		changes, err = diffComponents(oldComps, oldComps)
+		Expect(err).Should(BeNil())
+		Expect(changes).Should(BeEmpty())
+	})
+
+	It("Test diffDefinitions", func() {
+		oldRev := &v1beta1.ApplicationRevision{Spec: v1beta1.ApplicationRevisionSpec{
+			ComponentDefinitions: map[string]v1beta1.ComponentDefinition{
+				"webservice": {Spec: v1beta1.ComponentDefinitionSpec{Extension: &runtime.RawExtension{Raw: []byte(`{"v":1}`)}}},
+				"worker":     {},
+			},
+			TraitDefinitions: map[string]v1beta1.TraitDefinition{
+				"scaler":  {Spec: v1beta1.TraitDefinitionSpec{RevisionEnabled: false}},
+				"ingress": {},
+			},
+		}}
+		newRev := &v1beta1.ApplicationRevision{Spec: v1beta1.ApplicationRevisionSpec{
+			ComponentDefinitions: map[string]v1beta1.ComponentDefinition{
+				"webservice": {Spec: v1beta1.ComponentDefinitionSpec{Extension: &runtime.RawExtension{Raw: []byte(`{"v":2}`)}}},
+				"worker":     {},
+				"task":       {},
+			},
+			TraitDefinitions: map[string]v1beta1.TraitDefinition{
+				"scaler": {Spec: v1beta1.TraitDefinitionSpec{RevisionEnabled: true}},
+			},
+		}}
+		Expect(diffDefinitions(oldRev, newRev)).Should(Equal([]string{"componentDefinition/webservice", "traitDefinition/scaler"}))
+		Expect(diffDefinitions(oldRev, oldRev)).Should(BeEmpty())
+	})
+})
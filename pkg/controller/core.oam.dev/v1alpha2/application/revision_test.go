@@ -226,7 +226,7 @@ var _ = Describe("test generate revision ", func() {
 		app.SetAnnotations(map[string]string{annoKey1: "true"})
 		generatedAppfile, err := appParser.GenerateAppFile(ctx, &app)
 		Expect(err).Should(Succeed())
-		ac, comps, err = generatedAppfile.GenerateApplicationConfiguration()
+		ac, comps, err = generatedAppfile.GenerateApplicationConfiguration(ctx)
 		Expect(err).Should(Succeed())
 		handler.appfile = generatedAppfile
 		Expect(ac.Namespace).Should(Equal(app.Namespace))
@@ -322,7 +322,7 @@ var _ = Describe("test generate revision ", func() {
 		Expect(k8sClient.Update(ctx, &app)).Should(SatisfyAny(BeNil(), &util.AlreadyExistMatcher{}))
 		generatedAppfile, err = appParser.GenerateAppFile(ctx, &app)
 		Expect(err).Should(Succeed())
-		ac, comps, err = generatedAppfile.GenerateApplicationConfiguration()
+		ac, comps, err = generatedAppfile.GenerateApplicationConfiguration(ctx)
 		Expect(err).Should(Succeed())
 		handler.appfile = generatedAppfile
 		handler.app = &app
@@ -374,7 +374,7 @@ var _ = Describe("test generate revision ", func() {
 		app.SetAnnotations(map[string]string{oam.AnnotationAppRollout: strconv.FormatBool(true)})
 		generatedAppfile, err := appParser.GenerateAppFile(ctx, &app)
 		Expect(err).Should(Succeed())
-		ac, comps, err = generatedAppfile.GenerateApplicationConfiguration()
+		ac, comps, err = generatedAppfile.GenerateApplicationConfiguration(ctx)
 		Expect(err).Should(Succeed())
 		handler.appfile = generatedAppfile
 		Expect(ac.Namespace).Should(Equal(app.Namespace))
@@ -469,7 +469,7 @@ var _ = Describe("test generate revision ", func() {
 		Expect(k8sClient.Update(ctx, &app)).Should(SatisfyAny(BeNil(), &util.AlreadyExistMatcher{}))
 		generatedAppfile, err = appParser.GenerateAppFile(ctx, &app)
 		Expect(err).Should(Succeed())
-		ac, comps, err = generatedAppfile.GenerateApplicationConfiguration()
+		ac, comps, err = generatedAppfile.GenerateApplicationConfiguration(ctx)
 		Expect(err).Should(Succeed())
 		handler.appfile = generatedAppfile
 		handler.app = &app
@@ -522,7 +522,7 @@ var _ = Describe("test generate revision ", func() {
 		app.SetAnnotations(map[string]string{annoKey1: "true"})
 		generatedAppfile, err := appParser.GenerateAppFile(ctx, &app)
 		Expect(err).Should(Succeed())
-		ac, comps, err = generatedAppfile.GenerateApplicationConfiguration()
+		ac, comps, err = generatedAppfile.GenerateApplicationConfiguration(ctx)
 		Expect(err).Should(Succeed())
 		handler.appfile = generatedAppfile
 		Expect(ac.Namespace).Should(Equal(app.Namespace))
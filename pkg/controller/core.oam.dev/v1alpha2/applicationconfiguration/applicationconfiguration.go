@@ -42,6 +42,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1alpha2"
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
 	oamtype "github.com/oam-dev/kubevela/apis/types"
 	core "github.com/oam-dev/kubevela/pkg/controller/core.oam.dev"
 	"github.com/oam-dev/kubevela/pkg/oam"
@@ -309,7 +310,14 @@ func (r *OAMApplicationReconciler) ACReconcile(ctx context.Context, ac *v1alpha2
 	r.record.Event(ac, event.Normal(reasonRenderComponents, "Successfully rendered components",
 		"workloads", strconv.Itoa(len(workloads))))
 
-	applyOpts := []apply.ApplyOption{apply.MustBeControllableBy(ac.GetUID()), applyOnceOnly(ac, r.applyOnceOnlyMode, log)}
+	controllable, err := r.controllableBy(ctx, ac)
+	if err != nil {
+		log.Debug("Cannot get the AppConfigs handed over", "error", err)
+		r.record.Event(ac, event.Warning(reasonCannotApplyComponents, err))
+		ac.SetConditions(v1alpha1.ReconcileError(errors.Wrap(err, errApplyComponents)))
+		return reconcile.Result{}
+	}
+	applyOpts := []apply.ApplyOption{controllable, applyOnceOnly(ac, r.applyOnceOnlyMode, log)}
 	if err := r.workloads.Apply(ctx, ac.Status.Workloads, workloads, applyOpts...); err != nil {
 		log.Debug("Cannot apply workload", "error", err)
 		r.record.Event(ac, event.Warning(reasonCannotApplyComponents, err))
@@ -344,6 +352,17 @@ func (r *OAMApplicationReconciler) ACReconcile(ctx context.Context, ac *v1alpha2
 			ac.SetConditions(v1alpha1.ReconcileError(errors.Wrap(err, errGCComponent)))
 			return reconcile.Result{}
 		}
+		kept, err := r.keptByDeletionPolicy(ctx, ac.GetNamespace(), &e)
+		if err != nil {
+			log.Debug("Cannot get the deletion policy of resource", "error", err)
+			record.Event(ac, event.Warning(reasonCannotGGComponents, err))
+			ac.SetConditions(v1alpha1.ReconcileError(errors.Wrap(err, errGCComponent)))
+			return reconcile.Result{}
+		}
+		if kept {
+			log.Debug("Skip garbage collecting resource kept by its deletion policy")
+			continue
+		}
 		if err := r.client.Delete(ctx, &e); resource.IgnoreNotFound(err) != nil {
 			log.Debug("Cannot garbage collect component", "error", err)
 			record.Event(ac, event.Warning(reasonCannotGGComponents, err))
@@ -403,6 +422,22 @@ func (r *OAMApplicationReconciler) confirmDeleteOnApplyOnceMode(ctx context.Cont
 	return nil
 }
 
+// keptByDeletionPolicy returns true if the live resource is annotated to be orphaned or retained on deletion, the
+// application controller takes over such resources once they are removed from the application
+func (r *OAMApplicationReconciler) keptByDeletionPolicy(ctx context.Context, namespace string, u *unstructured.Unstructured) (bool, error) {
+	getU := u.DeepCopy()
+	if err := r.client.Get(ctx, client.ObjectKey{Name: u.GetName(), Namespace: namespace}, getU); err != nil {
+		// the resource is gone, there is nothing to keep
+		return false, resource.IgnoreNotFound(err)
+	}
+	switch getU.GetAnnotations()[oam.AnnotationDeletionPolicy] {
+	case string(v1beta1.DeletionPolicyOrphan), string(v1beta1.DeletionPolicyRetain):
+		return true, nil
+	default:
+		return false, nil
+	}
+}
+
 // UpdateStatus updates v1alpha2.ApplicationConfiguration's Status with retry.RetryOnConflict
 func (r *OAMApplicationReconciler) UpdateStatus(ctx context.Context, ac *v1alpha2.ApplicationConfiguration, opts ...client.UpdateOption) error {
 	status := ac.DeepCopy().Status
@@ -687,6 +722,46 @@ func (e *GenerationUnchanged) Error() string {
 		"Please ignore this error in other logic.")
 }
 
+// controllableBy returns an ApplyOption that requires the existing workloads and traits are controllable by the appConfig.
+// If the appConfig adopts existing resources, it takes over the ones without a controller or controlled by the AppConfigs
+// handed over to its application. The adopt annotation could be set by anyone who can change the application, so it
+// never takes over the resources of others.
+func (r *OAMApplicationReconciler) controllableBy(ctx context.Context, ac *v1alpha2.ApplicationConfiguration) (apply.ApplyOption, error) {
+	if ac.GetAnnotations()[oam.AnnotationAdoptResources] != "true" {
+		return apply.MustBeControllableBy(ac.GetUID()), nil
+	}
+	handedOver, err := handedOverAppConfigs(ctx, r.client, ac)
+	if err != nil {
+		return nil, err
+	}
+	return apply.AdoptBy(ac.GetUID(), handedOver...), nil
+}
+
+// handedOverAppConfigs returns the UIDs of the AppConfigs handed over to the application of the appConfig. An AppConfig
+// is handed over by its own annotation, so only the ones who can change the AppConfig could hand over its resources.
+func handedOverAppConfigs(ctx context.Context, c client.Reader, ac *v1alpha2.ApplicationConfiguration) ([]types.UID, error) {
+	var appName string
+	for _, owner := range ac.GetOwnerReferences() {
+		if owner.Kind == v1beta1.ApplicationKind && owner.Controller != nil && *owner.Controller {
+			appName = owner.Name
+		}
+	}
+	if appName == "" {
+		return nil, nil
+	}
+	var acs v1alpha2.ApplicationConfigurationList
+	if err := c.List(ctx, &acs, client.InNamespace(ac.Namespace)); err != nil {
+		return nil, errors.Wrap(err, "cannot list AppConfigs")
+	}
+	var uids []types.UID
+	for _, item := range acs.Items {
+		if item.UID != ac.UID && item.GetAnnotations()[oam.AnnotationHandedOverTo] == appName {
+			uids = append(uids, item.UID)
+		}
+	}
+	return uids, nil
+}
+
 // applyOnceOnly is an ApplyOption that controls the applying mechanism for workload and trait.
 // More detail refers to the ApplyOnceOnlyMode type annotation
 func applyOnceOnly(ac *v1alpha2.ApplicationConfiguration, mode core.ApplyOnceOnlyMode, log logging.Logger) apply.ApplyOption {
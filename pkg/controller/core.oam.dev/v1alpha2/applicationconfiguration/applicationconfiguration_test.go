@@ -41,6 +41,8 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1alpha2"
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
+	"github.com/oam-dev/kubevela/pkg/oam"
 	"github.com/oam-dev/kubevela/pkg/oam/mock"
 	"github.com/oam-dev/kubevela/pkg/utils/apply"
 )
@@ -1890,3 +1892,28 @@ func TestUpdateStatus(t *testing.T) {
 	assert.Equal(t, ac.Status.ObservedGeneration, int64(1))
 
 }
+
+func TestHandedOverAppConfigs(t *testing.T) {
+	controller := true
+	ac := &v1alpha2.ApplicationConfiguration{ObjectMeta: metav1.ObjectMeta{Name: "myapp", Namespace: "default", UID: "ac",
+		OwnerReferences: []metav1.OwnerReference{{Kind: v1beta1.ApplicationKind, Name: "myapp", Controller: &controller}}}}
+	c := &test.MockClient{
+		MockList: func(_ context.Context, obj runtime.Object, _ ...client.ListOption) error {
+			obj.(*v1alpha2.ApplicationConfigurationList).Items = []v1alpha2.ApplicationConfiguration{
+				*ac,
+				{ObjectMeta: metav1.ObjectMeta{Name: "legacy", UID: "legacy", Annotations: map[string]string{oam.AnnotationHandedOverTo: "myapp"}}},
+				{ObjectMeta: metav1.ObjectMeta{Name: "others", UID: "others", Annotations: map[string]string{oam.AnnotationHandedOverTo: "otherapp"}}},
+				{ObjectMeta: metav1.ObjectMeta{Name: "live", UID: "live"}},
+			}
+			return nil
+		},
+	}
+	uids, err := handedOverAppConfigs(context.Background(), c, ac)
+	assert.NoError(t, err)
+	assert.Equal(t, []types.UID{"legacy"}, uids)
+
+	// an AppConfig not generated by an application has nothing handed over
+	uids, err = handedOverAppConfigs(context.Background(), c, &v1alpha2.ApplicationConfiguration{ObjectMeta: metav1.ObjectMeta{Name: "myapp"}})
+	assert.NoError(t, err)
+	assert.Empty(t, uids)
+}
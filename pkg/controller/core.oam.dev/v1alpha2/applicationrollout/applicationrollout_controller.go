@@ -18,6 +18,7 @@ package applicationrollout
 
 import (
 	"context"
+	"fmt"
 	"strconv"
 	"time"
 
@@ -26,6 +27,7 @@ import (
 	"github.com/crossplane/crossplane-runtime/pkg/meta"
 	"github.com/pkg/errors"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/util/retry"
 	"k8s.io/klog/v2"
@@ -39,6 +41,7 @@ import (
 	"github.com/oam-dev/kubevela/apis/types"
 	"github.com/oam-dev/kubevela/pkg/controller/common/rollout"
 	controller "github.com/oam-dev/kubevela/pkg/controller/core.oam.dev"
+	"github.com/oam-dev/kubevela/pkg/deploymentwindow"
 	"github.com/oam-dev/kubevela/pkg/oam"
 	"github.com/oam-dev/kubevela/pkg/oam/discoverymapper"
 	oamutil "github.com/oam-dev/kubevela/pkg/oam/util"
@@ -64,6 +67,7 @@ type Reconciler struct {
 // +kubebuilder:rbac:groups=core.oam.dev,resources=approllouts/status,verbs=get;update;patch
 // +kubebuilder:rbac:groups=core.oam.dev,resources=applications,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=core.oam.dev,resources=applications/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=core.oam.dev,resources=deploymentwindows,verbs=get;list;watch
 
 // Reconcile is the main logic of appRollout controller
 // nolint:gocyclo
@@ -146,6 +150,19 @@ func (r *Reconciler) DoReconcile(ctx context.Context, appRollout *v1beta1.AppRol
 		appRollout.Status.StateTransition(v1alpha1.RollingModifiedEvent)
 	}
 
+	if isRolloutAborted(*appRollout) {
+		klog.InfoS("rollout is aborted", "target", appRollout.Spec.TargetAppRevisionName)
+		r.record.Event(appRollout, event.Normal("Rollout Aborted", "rollout is aborted",
+			"target", appRollout.Spec.TargetAppRevisionName))
+		if appRollout.Status.RollingState == v1alpha1.LocatingTargetAppState ||
+			appRollout.Status.RollingState == v1alpha1.VerifyingSpecState {
+			// nothing is changed yet, we can fail it right away
+			appRollout.Status.RolloutFailed("rollout is aborted")
+		} else {
+			appRollout.Status.RolloutFailing("rollout is aborted")
+		}
+	}
+
 	// Get the source application first
 	var sourceApRev, targetAppRev *oamv1alpha2.ApplicationRevision
 	var sourceApp, targetApp *oamv1alpha2.ApplicationContext
@@ -225,6 +242,10 @@ func (r *Reconciler) DoReconcile(ctx context.Context, appRollout *v1beta1.AppRol
 	// reconcile the rollout part of the spec given the target and source workload
 	rolloutPlanController := rollout.NewRolloutPlanController(r, appRollout, r.record,
 		&appRollout.Spec.RolloutPlan, &appRollout.Status.RolloutStatus, targetWorkload, sourceWorkload)
+	if appRollout.Status.RollingState == v1alpha1.RollingInBatchesState &&
+		appRollout.Status.BatchRollingState == v1alpha1.BatchInitializingState {
+		r.checkDeploymentWindow(ctx, appRollout, rolloutPlanController)
+	}
 	result, rolloutStatus := rolloutPlanController.Reconcile(ctx)
 	// make sure that the new status is copied back
 	appRollout.Status.RolloutStatus = *rolloutStatus
@@ -257,6 +278,47 @@ func isRolloutModified(appRollout v1beta1.AppRollout) bool {
 				appRollout.Status.LastSourceAppRevision != appRollout.Spec.SourceAppRevisionName))
 }
 
+// check if the rollout to the current target is aborted and it's still in progress
+func isRolloutAborted(appRollout v1beta1.AppRollout) bool {
+	if appRollout.GetAnnotations()[oam.AnnotationAbortedTarget] != appRollout.Spec.TargetAppRevisionName {
+		return false
+	}
+	switch appRollout.Status.RollingState {
+	case v1alpha1.RolloutFailingState, v1alpha1.RolloutFailedState, v1alpha1.RolloutSucceedState,
+		v1alpha1.RolloutAbandoningState, v1alpha1.RolloutDeletingState:
+		return false
+	default:
+		return true
+	}
+}
+
+// checkDeploymentWindow holds the batch to start if deployment windows don't allow it. The rollout plan of an
+// application is constructed by the application controller, it's held by the deployment windows of the application.
+func (r *Reconciler) checkDeploymentWindow(ctx context.Context, appRollout *v1beta1.AppRollout,
+	rolloutPlanController *rollout.Controller) {
+	kind := v1beta1.AppRolloutKind
+	if owner := metav1.GetControllerOf(appRollout); owner != nil && owner.Kind == v1beta1.ApplicationKind &&
+		owner.UID == appRollout.UID {
+		kind = v1beta1.ApplicationKind
+	}
+	window, err := deploymentwindow.Check(ctx, r, kind, appRollout, time.Now())
+	if err != nil {
+		klog.ErrorS(err, "cannot check the deployment windows", "appRollout", klog.KObj(appRollout))
+		// hold the batch as it may be outside of the windows
+		rolloutPlanController.WaitForWindow(fmt.Sprintf("waiting for window: %v", err))
+		return
+	}
+	if !window.Open {
+		rolloutPlanController.WaitForWindow(window.Message)
+		return
+	}
+	if window.Overridden {
+		r.record.Event(appRollout, event.Normal(types.ReasonDeploymentWindowOverridden,
+			fmt.Sprintf("Batch %d starts outside of deployment windows, overridden by %s until %s",
+				appRollout.Status.CurrentBatch, window.OverriddenBy, window.OverrideUntil.Format(time.RFC3339))))
+	}
+}
+
 // handle adding and handle finalizer logic, it turns if we should continue to reconcile
 func (r *Reconciler) handleFinalizer(ctx context.Context, appRollout *v1beta1.AppRollout) (bool, reconcile.Result, error) {
 	if appRollout.DeletionTimestamp.IsZero() {
@@ -0,0 +1,370 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package applicationset
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	runtimev1alpha1 "github.com/crossplane/crossplane-runtime/apis/core/v1alpha1"
+	"github.com/crossplane/crossplane-runtime/pkg/logging"
+	"github.com/crossplane/crossplane-runtime/pkg/meta"
+	"github.com/go-logr/logr"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/common"
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
+	core "github.com/oam-dev/kubevela/pkg/controller/core.oam.dev"
+	"github.com/oam-dev/kubevela/pkg/oam"
+)
+
+const (
+	applicationSetFinalizer = "finalizers.applicationset.oam.dev"
+	reconcileTimeout        = time.Minute
+	errUpdateFinalizer      = "cannot update finalizer of ApplicationSet"
+	errUpdateStatus         = "cannot update status of ApplicationSet"
+)
+
+// Reconciler reconciles an ApplicationSet object
+type Reconciler struct {
+	client.Client
+	Log logr.Logger
+}
+
+// +kubebuilder:rbac:groups=core.oam.dev,resources=applicationsets,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=core.oam.dev,resources=applicationsets/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=core.oam.dev,resources=applications,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=core.oam.dev,resources=clusters,verbs=get;list;watch
+// +kubebuilder:rbac:groups="",resources=namespaces,verbs=get;list;watch
+
+// Reconcile renders an application for each entry generated, and applies them by the update strategy
+func (r *Reconciler) Reconcile(req ctrl.Request) (ctrl.Result, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), reconcileTimeout)
+	defer cancel()
+	log := r.Log.WithValues("applicationset", req.NamespacedName)
+
+	appSet := &v1beta1.ApplicationSet{}
+	if err := r.Get(ctx, req.NamespacedName, appSet); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+
+	if !appSet.DeletionTimestamp.IsZero() {
+		if !meta.FinalizerExists(&appSet.ObjectMeta, applicationSetFinalizer) {
+			return ctrl.Result{}, nil
+		}
+		// applications in other namespaces cannot be garbage collected by owner reference
+		if err := r.deleteApplications(ctx, appSet, nil); err != nil {
+			return ctrl.Result{}, err
+		}
+		meta.RemoveFinalizer(&appSet.ObjectMeta, applicationSetFinalizer)
+		return ctrl.Result{}, errors.Wrap(r.Update(ctx, appSet), errUpdateFinalizer)
+	}
+	if !meta.FinalizerExists(&appSet.ObjectMeta, applicationSetFinalizer) {
+		meta.AddFinalizer(&appSet.ObjectMeta, applicationSetFinalizer)
+		return ctrl.Result{}, errors.Wrap(r.Update(ctx, appSet), errUpdateFinalizer)
+	}
+
+	if err := r.reconcileApplications(ctx, appSet); err != nil {
+		log.Error(err, "cannot reconcile applications")
+		appSet.Status.Phase = v1beta1.ApplicationSetFailed
+		appSet.Status.SetConditions(runtimev1alpha1.ReconcileError(err))
+		if updateErr := r.Status().Update(ctx, appSet); updateErr != nil {
+			return ctrl.Result{}, errors.Wrap(updateErr, errUpdateStatus)
+		}
+		return ctrl.Result{}, err
+	}
+	appSet.Status.SetConditions(runtimev1alpha1.ReconcileSuccess())
+	return ctrl.Result{}, errors.Wrap(r.Status().Update(ctx, appSet), errUpdateStatus)
+}
+
+// reconcileApplications applies the applications of the entries, deletes the stale ones, and aggregates the status
+func (r *Reconciler) reconcileApplications(ctx context.Context, appSet *v1beta1.ApplicationSet) error {
+	entries, err := generateEntries(ctx, r, appSet)
+	if err != nil {
+		return errors.Wrap(err, "cannot generate entries")
+	}
+	desired := make([]*v1beta1.Application, 0, len(entries))
+	for _, e := range entries {
+		app, err := renderApplication(appSet, e)
+		if err != nil {
+			return err
+		}
+		desired = append(desired, app)
+	}
+
+	existing, err := r.listApplications(ctx, appSet)
+	if err != nil {
+		return err
+	}
+	current := make(map[types.NamespacedName]*v1beta1.Application, len(existing))
+	for i := range existing {
+		app := &existing[i]
+		current[types.NamespacedName{Namespace: app.Namespace, Name: app.Name}] = app
+	}
+	keep := make(map[types.NamespacedName]bool, len(desired))
+	for _, app := range desired {
+		keep[types.NamespacedName{Namespace: app.Namespace, Name: app.Name}] = true
+	}
+	if err := r.deleteApplications(ctx, appSet, keep); err != nil {
+		return err
+	}
+
+	// the applications updated but not running yet are updating, they're counted by the progressive strategy
+	budget := len(desired)
+	if appSet.Spec.Strategy.Type == v1beta1.ProgressiveStrategy {
+		budget = 1
+		if appSet.Spec.Strategy.MaxUpdating != nil && *appSet.Spec.Strategy.MaxUpdating > 0 {
+			budget = int(*appSet.Spec.Strategy.MaxUpdating)
+		}
+		for _, app := range desired {
+			cur, ok := current[types.NamespacedName{Namespace: app.Namespace, Name: app.Name}]
+			if ok && isUpdated(cur, app) && !isRunning(cur) {
+				budget--
+			}
+		}
+	}
+
+	status := make([]v1beta1.ApplicationSetApplicationStatus, 0, len(desired))
+	updated, running := 0, 0
+	for _, app := range desired {
+		appStatus := v1beta1.ApplicationSetApplicationStatus{
+			Entry:     app.Labels[oam.LabelApplicationSetEntry],
+			Name:      app.Name,
+			Namespace: app.Namespace,
+		}
+		cur, ok := current[types.NamespacedName{Namespace: app.Namespace, Name: app.Name}]
+		switch {
+		case ok && isUpdated(cur, app):
+			appStatus.Updated = true
+			appStatus.Phase = cur.Status.Phase
+			if isRunning(cur) {
+				running++
+			}
+		case budget > 0:
+			if err := r.applyApplication(ctx, appSet, app); err != nil {
+				return err
+			}
+			budget--
+			appStatus.Updated = true
+		case ok:
+			appStatus.Phase = cur.Status.Phase
+		}
+		if appStatus.Updated {
+			updated++
+		}
+		status = append(status, appStatus)
+	}
+
+	appSet.Status.ObservedGeneration = appSet.Generation
+	appSet.Status.Applications = status
+	appSet.Status.Total = len(desired)
+	appSet.Status.Updated = updated
+	appSet.Status.Running = running
+	appSet.Status.Phase = v1beta1.ApplicationSetProgressing
+	if running == len(desired) {
+		appSet.Status.Phase = v1beta1.ApplicationSetRunning
+	}
+	return nil
+}
+
+// isUpdated checks if the application has been updated to the desired one rendered from the template, and it's not
+// changed by others since then. The hash of template is one of the annotations compared.
+func isUpdated(cur, desired *v1beta1.Application) bool {
+	return containsAll(cur.Labels, desired.Labels) && containsAll(cur.Annotations, desired.Annotations) &&
+		specEqual(cur.Spec, desired.Spec)
+}
+
+// containsAll checks if all the key value pairs of want are in m
+func containsAll(m, want map[string]string) bool {
+	for k, v := range want {
+		if got, ok := m[k]; !ok || got != v {
+			return false
+		}
+	}
+	return true
+}
+
+// specEqual compares the specs of applications by JSON, so that the properties of components are compared by value
+// rather than by the raw bytes
+func specEqual(a, b v1beta1.ApplicationSpec) bool {
+	var values [2]interface{}
+	for i, spec := range []v1beta1.ApplicationSpec{a, b} {
+		data, err := json.Marshal(spec)
+		if err != nil {
+			return false
+		}
+		if err := json.Unmarshal(data, &values[i]); err != nil {
+			return false
+		}
+	}
+	return apiequality.Semantic.DeepEqual(values[0], values[1])
+}
+
+// isRunning checks if the application is running with its latest spec
+func isRunning(app *v1beta1.Application) bool {
+	return app.Status.ObservedGeneration == app.Generation && app.Status.Phase == common.ApplicationRunning
+}
+
+// listApplications lists the applications generated by the ApplicationSet in all namespaces
+func (r *Reconciler) listApplications(ctx context.Context, appSet *v1beta1.ApplicationSet) ([]v1beta1.Application, error) {
+	apps := &v1beta1.ApplicationList{}
+	if err := r.List(ctx, apps, client.MatchingLabels{
+		oam.LabelApplicationSet:          appSet.Name,
+		oam.LabelApplicationSetNamespace: appSet.Namespace,
+	}); err != nil {
+		return nil, errors.Wrap(err, "cannot list applications of ApplicationSet")
+	}
+	return apps.Items, nil
+}
+
+// deleteApplications deletes the applications generated by the ApplicationSet except the ones to keep
+func (r *Reconciler) deleteApplications(ctx context.Context, appSet *v1beta1.ApplicationSet, keep map[types.NamespacedName]bool) error {
+	apps, err := r.listApplications(ctx, appSet)
+	if err != nil {
+		return err
+	}
+	for i := range apps {
+		app := &apps[i]
+		if keep[types.NamespacedName{Namespace: app.Namespace, Name: app.Name}] {
+			continue
+		}
+		r.Log.Info("delete application", "applicationset", appSet.Namespace+"/"+appSet.Name, "application", app.Namespace+"/"+app.Name)
+		if err := r.Delete(ctx, app); err != nil && !apierrors.IsNotFound(err) {
+			return errors.Wrapf(err, "cannot delete application %s/%s", app.Namespace, app.Name)
+		}
+	}
+	return nil
+}
+
+// applyApplication creates the application or updates it to the desired one, an existing application not generated
+// by the ApplicationSet is never overwritten
+func (r *Reconciler) applyApplication(ctx context.Context, appSet *v1beta1.ApplicationSet, desired *v1beta1.Application) error {
+	cur := &v1beta1.Application{}
+	err := r.Get(ctx, client.ObjectKey{Namespace: desired.Namespace, Name: desired.Name}, cur)
+	if apierrors.IsNotFound(err) {
+		r.Log.Info("create application", "applicationset", appSet.Namespace+"/"+appSet.Name, "application", desired.Namespace+"/"+desired.Name)
+		return errors.Wrapf(r.Create(ctx, desired), "cannot create application %s/%s", desired.Namespace, desired.Name)
+	}
+	if err != nil {
+		return errors.Wrapf(err, "cannot get application %s/%s", desired.Namespace, desired.Name)
+	}
+	if cur.Labels[oam.LabelApplicationSet] != appSet.Name || cur.Labels[oam.LabelApplicationSetNamespace] != appSet.Namespace {
+		return errors.Errorf("application %s/%s exists and isn't generated by ApplicationSet %s", cur.Namespace, cur.Name, appSet.Name)
+	}
+	r.Log.Info("update application", "applicationset", appSet.Namespace+"/"+appSet.Name, "application", desired.Namespace+"/"+desired.Name)
+	removeTemplateMetadata(cur, desired)
+	meta.AddLabels(cur, desired.Labels)
+	meta.AddAnnotations(cur, desired.Annotations)
+	cur.OwnerReferences = desired.OwnerReferences
+	cur.Spec = desired.Spec
+	return errors.Wrapf(r.Update(ctx, cur), "cannot update application %s/%s", cur.Namespace, cur.Name)
+}
+
+// removeTemplateMetadata removes the labels and annotations that were set by the template but are removed from it,
+// the ones set by others are kept
+func removeTemplateMetadata(cur, desired *v1beta1.Application) {
+	var metadata templateMetadata
+	if err := json.Unmarshal([]byte(cur.Annotations[oam.AnnotationApplicationSetMetadata]), &metadata); err != nil {
+		return
+	}
+	for _, k := range metadata.Labels {
+		if _, ok := desired.Labels[k]; !ok {
+			delete(cur.Labels, k)
+		}
+	}
+	for _, k := range metadata.Annotations {
+		if _, ok := desired.Annotations[k]; !ok {
+			delete(cur.Annotations, k)
+		}
+	}
+}
+
+// SetupWithManager install to manager
+func (r *Reconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&v1beta1.ApplicationSet{}).
+		Watches(&source.Kind{Type: &v1beta1.Application{}}, &handler.EnqueueRequestsFromMapFunc{
+			ToRequests: handler.ToRequestsFunc(findApplicationSet),
+		}).
+		Watches(&source.Kind{Type: &v1beta1.Cluster{}}, &handler.EnqueueRequestsFromMapFunc{
+			ToRequests: handler.ToRequestsFunc(r.findClusterApplicationSets),
+		}).
+		Watches(&source.Kind{Type: &corev1.Namespace{}}, &handler.EnqueueRequestsFromMapFunc{
+			ToRequests: handler.ToRequestsFunc(r.findNamespaceApplicationSets),
+		}).
+		Complete(r)
+}
+
+// findApplicationSet finds the ApplicationSet that generates an application, it reconciles when the application
+// changes to aggregate the status and continue the progressive update
+func findApplicationSet(o handler.MapObject) []reconcile.Request {
+	labels := o.Meta.GetLabels()
+	name, namespace := labels[oam.LabelApplicationSet], labels[oam.LabelApplicationSetNamespace]
+	if name == "" || namespace == "" {
+		return nil
+	}
+	return []reconcile.Request{{NamespacedName: types.NamespacedName{Namespace: namespace, Name: name}}}
+}
+
+// findClusterApplicationSets finds the ApplicationSets generating entries from the Clusters in the same namespace
+func (r *Reconciler) findClusterApplicationSets(o handler.MapObject) []reconcile.Request {
+	return r.findApplicationSets(func(appSet *v1beta1.ApplicationSet) bool {
+		return appSet.Namespace == o.Meta.GetNamespace() && appSet.Spec.Generator.Clusters != nil
+	})
+}
+
+// findNamespaceApplicationSets finds the ApplicationSets generating entries from namespaces
+func (r *Reconciler) findNamespaceApplicationSets(o handler.MapObject) []reconcile.Request {
+	return r.findApplicationSets(func(appSet *v1beta1.ApplicationSet) bool {
+		return appSet.Spec.Generator.Namespaces != nil
+	})
+}
+
+func (r *Reconciler) findApplicationSets(match func(*v1beta1.ApplicationSet) bool) []reconcile.Request {
+	appSets := &v1beta1.ApplicationSetList{}
+	if err := r.List(context.Background(), appSets); err != nil {
+		r.Log.Error(err, "cannot list ApplicationSets")
+		return nil
+	}
+	var requests []reconcile.Request
+	for i := range appSets.Items {
+		appSet := &appSets.Items[i]
+		if match(appSet) {
+			requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{Namespace: appSet.Namespace, Name: appSet.Name}})
+		}
+	}
+	return requests
+}
+
+// Setup adds a controller that reconciles ApplicationSet.
+func Setup(mgr ctrl.Manager, _ core.Args, _ logging.Logger) error {
+	reconciler := Reconciler{
+		Client: mgr.GetClient(),
+		Log:    ctrl.Log.WithName("ApplicationSet"),
+	}
+	return reconciler.SetupWithManager(mgr)
+}
@@ -0,0 +1,370 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package applicationset
+
+import (
+	"context"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/common"
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
+	"github.com/oam-dev/kubevela/pkg/oam"
+	common2 "github.com/oam-dev/kubevela/pkg/utils/common"
+)
+
+func newApplicationSet(generator v1beta1.ApplicationSetGenerator) *v1beta1.ApplicationSet {
+	defaultReplicas := intstr.FromInt(1)
+	return &v1beta1.ApplicationSet{
+		ObjectMeta: metav1.ObjectMeta{Name: "tenant", Namespace: "default", UID: "appset-uid"},
+		Spec: v1beta1.ApplicationSetSpec{
+			Parameters: []v1beta1.ApplicationSetParameter{
+				{Name: "name", FieldPaths: []string{"metadata.labels.tenant"}},
+				{Name: "image", FieldPaths: []string{"spec.components[0].properties.image"}, Required: true},
+				{Name: "replicas", FieldPaths: []string{"spec.components[0].traits[0].properties.replicas"}, Default: &defaultReplicas},
+			},
+			Generator: generator,
+			Template: v1beta1.ApplicationSetTemplate{
+				Metadata: v1beta1.ApplicationSetTemplateMeta{Labels: map[string]string{"team": "sre"}},
+				Spec: v1beta1.ApplicationSpec{Components: []v1beta1.ApplicationComponent{{
+					Name:       "web",
+					Type:       "webservice",
+					Properties: runtime.RawExtension{Raw: []byte(`{"image":"nginx","port":80}`)},
+					Traits: []v1beta1.ApplicationTrait{{
+						Type:       "scaler",
+						Properties: runtime.RawExtension{Raw: []byte(`{"replicas":3}`)},
+					}},
+				}}},
+			},
+		},
+	}
+}
+
+func listGenerator(names ...string) v1beta1.ApplicationSetGenerator {
+	var elements []v1beta1.ApplicationSetElement
+	for _, name := range names {
+		elements = append(elements, v1beta1.ApplicationSetElement{Name: name, Values: []v1beta1.ApplicationSetParameterValue{
+			{Name: "image", Value: intstr.FromString("nginx:" + name)},
+		}})
+	}
+	return v1beta1.ApplicationSetGenerator{List: elements}
+}
+
+func TestRenderApplication(t *testing.T) {
+	appSet := newApplicationSet(v1beta1.ApplicationSetGenerator{})
+	app, err := renderApplication(appSet, entry{Name: "foo", Namespace: "default", Values: map[string]intstr.IntOrString{
+		"name":     intstr.FromString("foo"),
+		"image":    intstr.FromString("nginx:1.20"),
+		"replicas": intstr.FromInt(5),
+		"region":   intstr.FromString("us"),
+	}})
+	assert.NoError(t, err)
+	assert.Equal(t, "tenant-foo", app.Name)
+	assert.Equal(t, "default", app.Namespace)
+	assert.Equal(t, "sre", app.Labels["team"])
+	assert.Equal(t, "foo", app.Labels["tenant"])
+	assert.Equal(t, "tenant", app.Labels[oam.LabelApplicationSet])
+	assert.Equal(t, "default", app.Labels[oam.LabelApplicationSetNamespace])
+	assert.Equal(t, "foo", app.Labels[oam.LabelApplicationSetEntry])
+	assert.NotEmpty(t, app.Annotations[oam.AnnotationApplicationSetHash])
+	assert.JSONEq(t, `{"labels":["team","tenant"]}`, app.Annotations[oam.AnnotationApplicationSetMetadata])
+	assert.JSONEq(t, `{"image":"nginx:1.20","port":80}`, string(app.Spec.Components[0].Properties.Raw))
+	assert.JSONEq(t, `{"replicas":5}`, string(app.Spec.Components[0].Traits[0].Properties.Raw))
+	assert.Len(t, app.OwnerReferences, 1)
+	assert.Equal(t, types.UID("appset-uid"), app.OwnerReferences[0].UID)
+	// the template is not changed by rendering
+	assert.JSONEq(t, `{"image":"nginx","port":80}`, string(appSet.Spec.Template.Spec.Components[0].Properties.Raw))
+
+	app, err = renderApplication(appSet, entry{Name: "bar", Namespace: "bar", Values: map[string]intstr.IntOrString{
+		"image": intstr.FromString("nginx:1.20"),
+	}})
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"replicas":1}`, string(app.Spec.Components[0].Traits[0].Properties.Raw))
+	assert.Empty(t, app.OwnerReferences, "applications in other namespaces cannot be owned by the ApplicationSet")
+
+	_, err = renderApplication(appSet, entry{Name: "baz", Namespace: "default"})
+	assert.EqualError(t, err, "entry baz has no value of the required parameter image")
+
+	_, err = renderApplication(appSet, entry{Name: "Baz", Namespace: "default", Values: map[string]intstr.IntOrString{
+		"image": intstr.FromString("nginx:1.20"),
+	}})
+	assert.Error(t, err)
+}
+
+func TestGenerateEntries(t *testing.T) {
+	ctx := context.Background()
+	c := fake.NewFakeClientWithScheme(common2.Scheme,
+		&v1beta1.Cluster{ObjectMeta: metav1.ObjectMeta{Name: "us-west", Namespace: "default", Labels: map[string]string{"region": "us"}}},
+		&v1beta1.Cluster{ObjectMeta: metav1.ObjectMeta{Name: "us-east", Namespace: "default", Labels: map[string]string{"region": "us"}}},
+		&v1beta1.Cluster{ObjectMeta: metav1.ObjectMeta{Name: "eu-west", Namespace: "default", Labels: map[string]string{"region": "eu"}}},
+		&v1beta1.Cluster{ObjectMeta: metav1.ObjectMeta{Name: "us-other", Namespace: "other", Labels: map[string]string{"region": "us"}}},
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "team-a", Labels: map[string]string{"tenant": "true", oam.LabelApplicationSetAllowedNamespace: "default"}}},
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "team-b", Labels: map[string]string{"tenant": "true", oam.LabelApplicationSetAllowedNamespace: "default"}}},
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "team-c", Labels: map[string]string{"tenant": "true", oam.LabelApplicationSetAllowedNamespace: "other"}}},
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "default"}},
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "kube-system"}},
+	)
+	names := func(entries []entry) []string {
+		var names []string
+		for _, e := range entries {
+			names = append(names, e.Namespace+"/"+e.Name)
+		}
+		return names
+	}
+
+	entries, err := generateEntries(ctx, c, newApplicationSet(listGenerator("foo", "bar")))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"default/foo", "default/bar"}, names(entries))
+	assert.Equal(t, map[string]intstr.IntOrString{"name": intstr.FromString("bar"), "image": intstr.FromString("nginx:bar")}, entries[1].Values)
+
+	entries, err = generateEntries(ctx, c, newApplicationSet(v1beta1.ApplicationSetGenerator{
+		Clusters: &v1beta1.ApplicationSetClusterGenerator{Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"region": "us"}}},
+	}))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"default/us-east", "default/us-west"}, names(entries))
+	assert.Equal(t, intstr.FromString("us"), entries[0].Values["region"])
+	assert.Equal(t, intstr.FromString("us-east"), entries[0].Values["name"])
+
+	entries, err = generateEntries(ctx, c, newApplicationSet(v1beta1.ApplicationSetGenerator{
+		Namespaces: &v1beta1.ApplicationSetNamespaceGenerator{Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"tenant": "true"}}},
+	}))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"team-a/team-a", "team-b/team-b"}, names(entries))
+
+	entries, err = generateEntries(ctx, c, newApplicationSet(v1beta1.ApplicationSetGenerator{
+		Namespaces: &v1beta1.ApplicationSetNamespaceGenerator{Selector: &metav1.LabelSelector{}},
+	}))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"default/default", "team-a/team-a", "team-b/team-b"}, names(entries))
+
+	entries, err = generateEntries(ctx, c, newApplicationSet(v1beta1.ApplicationSetGenerator{
+		Namespaces: &v1beta1.ApplicationSetNamespaceGenerator{Names: []string{"team-b", "default"}},
+	}))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"team-b/team-b", "default/default"}, names(entries))
+
+	_, err = generateEntries(ctx, c, newApplicationSet(v1beta1.ApplicationSetGenerator{
+		Namespaces: &v1beta1.ApplicationSetNamespaceGenerator{Names: []string{"team-b", "kube-system"}},
+	}))
+	assert.EqualError(t, err, "namespace kube-system doesn't allow ApplicationSets in namespace default, "+
+		"it should be labeled with app.oam.dev/application-set-allowed-namespace=default")
+
+	_, err = generateEntries(ctx, c, newApplicationSet(v1beta1.ApplicationSetGenerator{
+		Namespaces: &v1beta1.ApplicationSetNamespaceGenerator{Names: []string{"team-d"}},
+	}))
+	assert.EqualError(t, err, "namespace team-d is not found")
+
+	_, err = generateEntries(ctx, c, newApplicationSet(v1beta1.ApplicationSetGenerator{
+		Namespaces: &v1beta1.ApplicationSetNamespaceGenerator{},
+	}))
+	assert.EqualError(t, err, "either names or selector of namespaces should be set")
+
+	_, err = generateEntries(ctx, c, newApplicationSet(listGenerator("foo", "foo")))
+	assert.EqualError(t, err, "entry foo is generated more than once")
+
+	gen := listGenerator("foo")
+	gen.Clusters = &v1beta1.ApplicationSetClusterGenerator{}
+	_, err = generateEntries(ctx, c, newApplicationSet(gen))
+	assert.EqualError(t, err, "one and only one of list, clusters and namespaces generators should be set")
+}
+
+func TestReconcileApplicationSet(t *testing.T) {
+	ctx := context.Background()
+	maxUpdating := int32(1)
+	appSet := newApplicationSet(listGenerator("a", "b", "c"))
+	appSet.Spec.Strategy = v1beta1.ApplicationSetStrategy{Type: v1beta1.ProgressiveStrategy, MaxUpdating: &maxUpdating}
+	c := fake.NewFakeClientWithScheme(common2.Scheme, appSet)
+	r := &Reconciler{Client: c, Log: ctrl.Log.WithName("ApplicationSet")}
+	key := types.NamespacedName{Namespace: "default", Name: "tenant"}
+
+	reconcile := func() *v1beta1.ApplicationSet {
+		_, err := r.Reconcile(ctrl.Request{NamespacedName: key})
+		assert.NoError(t, err)
+		got := &v1beta1.ApplicationSet{}
+		assert.NoError(t, c.Get(ctx, key, got))
+		return got
+	}
+	listApps := func() []v1beta1.Application {
+		apps := &v1beta1.ApplicationList{}
+		assert.NoError(t, c.List(ctx, apps, client.InNamespace("default")))
+		sort.Slice(apps.Items, func(i, j int) bool { return apps.Items[i].Name < apps.Items[j].Name })
+		return apps.Items
+	}
+	// the application controller runs the application with its latest spec
+	run := func(name string) {
+		app := &v1beta1.Application{}
+		assert.NoError(t, c.Get(ctx, types.NamespacedName{Namespace: "default", Name: name}, app))
+		app.Generation++
+		app.Status.ObservedGeneration = app.Generation
+		app.Status.Phase = common.ApplicationRunning
+		assert.NoError(t, c.Update(ctx, app))
+	}
+
+	got := reconcile()
+	assert.Contains(t, got.Finalizers, applicationSetFinalizer)
+
+	// only one application is created at a time
+	got = reconcile()
+	assert.Equal(t, v1beta1.ApplicationSetProgressing, got.Status.Phase)
+	assert.Equal(t, 3, got.Status.Total)
+	assert.Equal(t, 1, got.Status.Updated)
+	assert.Equal(t, 0, got.Status.Running)
+	assert.Len(t, listApps(), 1)
+	got = reconcile()
+	assert.Len(t, listApps(), 1, "the next application waits until the updating one is running")
+
+	for _, name := range []string{"tenant-a", "tenant-b", "tenant-c"} {
+		run(name)
+		reconcile()
+	}
+	got = reconcile()
+	assert.Equal(t, v1beta1.ApplicationSetRunning, got.Status.Phase)
+	assert.Equal(t, 3, got.Status.Running)
+	assert.Equal(t, []v1beta1.ApplicationSetApplicationStatus{
+		{Entry: "a", Name: "tenant-a", Namespace: "default", Updated: true, Phase: common.ApplicationRunning},
+		{Entry: "b", Name: "tenant-b", Namespace: "default", Updated: true, Phase: common.ApplicationRunning},
+		{Entry: "c", Name: "tenant-c", Namespace: "default", Updated: true, Phase: common.ApplicationRunning},
+	}, got.Status.Applications)
+
+	// update the template progressively and remove an entry
+	got.Spec.Template.Spec.Components[0].Properties = runtime.RawExtension{Raw: []byte(`{"image":"nginx","port":8080}`)}
+	got.Spec.Generator = listGenerator("a", "b")
+	assert.NoError(t, c.Update(ctx, got))
+	got = reconcile()
+	assert.Equal(t, 2, got.Status.Total)
+	assert.Equal(t, 1, got.Status.Updated)
+	assert.Equal(t, 0, got.Status.Running)
+	apps := listApps()
+	assert.Len(t, apps, 2)
+	assert.JSONEq(t, `{"image":"nginx:a","port":8080}`, string(apps[0].Spec.Components[0].Properties.Raw))
+	assert.JSONEq(t, `{"image":"nginx:b","port":80}`, string(apps[1].Spec.Components[0].Properties.Raw))
+	assert.Equal(t, common.ApplicationRunning, got.Status.Applications[1].Phase)
+	assert.False(t, got.Status.Applications[1].Updated)
+
+	run("tenant-a")
+	reconcile()
+	run("tenant-b")
+	got = reconcile()
+	assert.Equal(t, v1beta1.ApplicationSetRunning, got.Status.Phase)
+	assert.JSONEq(t, `{"image":"nginx:b","port":8080}`, string(listApps()[1].Spec.Components[0].Properties.Raw))
+
+	// an existing application not generated by the ApplicationSet is never overwritten
+	assert.NoError(t, c.Create(ctx, &v1beta1.Application{ObjectMeta: metav1.ObjectMeta{Name: "tenant-d", Namespace: "default"}}))
+	got.Spec.Generator = listGenerator("a", "b", "d")
+	assert.NoError(t, c.Update(ctx, got))
+	_, err := r.Reconcile(ctrl.Request{NamespacedName: key})
+	assert.EqualError(t, err, "application default/tenant-d exists and isn't generated by ApplicationSet tenant")
+	assert.NoError(t, c.Get(ctx, key, got))
+	assert.Equal(t, v1beta1.ApplicationSetFailed, got.Status.Phase)
+
+	// the applications are deleted with the ApplicationSet
+	now := metav1.Now()
+	got.DeletionTimestamp = &now
+	assert.NoError(t, c.Update(ctx, got))
+	_, err = r.Reconcile(ctrl.Request{NamespacedName: key})
+	assert.NoError(t, err)
+	assert.Len(t, listApps(), 1)
+}
+
+func TestReconcileDrift(t *testing.T) {
+	ctx := context.Background()
+	appSet := newApplicationSet(listGenerator("a"))
+	appSet.Finalizers = []string{applicationSetFinalizer}
+	c := fake.NewFakeClientWithScheme(common2.Scheme, appSet)
+	r := &Reconciler{Client: c, Log: ctrl.Log.WithName("ApplicationSet")}
+	key := types.NamespacedName{Namespace: "default", Name: "tenant"}
+	appKey := types.NamespacedName{Namespace: "default", Name: "tenant-a"}
+
+	_, err := r.Reconcile(ctrl.Request{NamespacedName: key})
+	assert.NoError(t, err)
+
+	// the changes made to the application by hand are reverted, the labels set by others are kept
+	app := &v1beta1.Application{}
+	assert.NoError(t, c.Get(ctx, appKey, app))
+	app.Labels["team"] = "dev"
+	app.Labels["owner"] = "alice"
+	app.Spec.Components[0].Properties = runtime.RawExtension{Raw: []byte(`{"image":"httpd","port":80}`)}
+	assert.NoError(t, c.Update(ctx, app))
+	_, err = r.Reconcile(ctrl.Request{NamespacedName: key})
+	assert.NoError(t, err)
+	app = &v1beta1.Application{}
+	assert.NoError(t, c.Get(ctx, appKey, app))
+	assert.Equal(t, "sre", app.Labels["team"])
+	assert.Equal(t, "alice", app.Labels["owner"])
+	assert.JSONEq(t, `{"image":"nginx:a","port":80}`, string(app.Spec.Components[0].Properties.Raw))
+
+	// the labels removed from the template are removed from the application
+	got := &v1beta1.ApplicationSet{}
+	assert.NoError(t, c.Get(ctx, key, got))
+	got.Spec.Template.Metadata.Labels = nil
+	assert.NoError(t, c.Update(ctx, got))
+	_, err = r.Reconcile(ctrl.Request{NamespacedName: key})
+	assert.NoError(t, err)
+	app = &v1beta1.Application{}
+	assert.NoError(t, c.Get(ctx, appKey, app))
+	assert.NotContains(t, app.Labels, "team")
+	assert.Equal(t, "a", app.Labels["tenant"])
+	assert.Equal(t, "alice", app.Labels["owner"])
+
+	// the application not changed since then is updated
+	assert.NoError(t, c.Get(ctx, key, got))
+	assert.Equal(t, 1, got.Status.Updated)
+	desired, err := renderApplication(got, entry{Name: "a", Namespace: "default", Values: map[string]intstr.IntOrString{
+		"name":  intstr.FromString("a"),
+		"image": intstr.FromString("nginx:a"),
+	}})
+	assert.NoError(t, err)
+	assert.True(t, isUpdated(app, desired))
+}
+
+func TestReconcileAllAtOnce(t *testing.T) {
+	ctx := context.Background()
+	appSet := newApplicationSet(v1beta1.ApplicationSetGenerator{
+		Namespaces: &v1beta1.ApplicationSetNamespaceGenerator{Names: []string{"team-a", "team-b"}},
+	})
+	appSet.Finalizers = []string{applicationSetFinalizer}
+	appSet.Spec.Parameters[1].Default = &intstr.IntOrString{Type: intstr.String, StrVal: "nginx:stable"}
+	c := fake.NewFakeClientWithScheme(common2.Scheme, appSet,
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "team-a", Labels: map[string]string{oam.LabelApplicationSetAllowedNamespace: "default"}}},
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "team-b", Labels: map[string]string{oam.LabelApplicationSetAllowedNamespace: "default"}}},
+	)
+	r := &Reconciler{Client: c, Log: ctrl.Log.WithName("ApplicationSet")}
+	key := types.NamespacedName{Namespace: "default", Name: "tenant"}
+
+	_, err := r.Reconcile(ctrl.Request{NamespacedName: key})
+	assert.NoError(t, err)
+	got := &v1beta1.ApplicationSet{}
+	assert.NoError(t, c.Get(ctx, key, got))
+	assert.Equal(t, 2, got.Status.Updated)
+	for _, ns := range []string{"team-a", "team-b"} {
+		app := &v1beta1.Application{}
+		assert.NoError(t, c.Get(ctx, types.NamespacedName{Namespace: ns, Name: "tenant-" + ns}, app))
+		assert.Equal(t, "default", app.Labels[oam.LabelApplicationSetNamespace])
+		assert.Equal(t, "tenant", findApplicationSet(handler.MapObject{Meta: app, Object: app})[0].Name)
+	}
+}
@@ -0,0 +1,293 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package applicationset
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/crossplane/crossplane-runtime/pkg/fieldpath"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
+	"github.com/oam-dev/kubevela/pkg/controller/utils"
+	"github.com/oam-dev/kubevela/pkg/oam"
+)
+
+// paramEntryName is the parameter whose value is the name of entry, every entry has it
+const paramEntryName = "name"
+
+// entry is generated by the generator of ApplicationSet, an application is rendered for each entry
+type entry struct {
+	Name string
+	// Namespace of the application
+	Namespace string
+	Values    map[string]intstr.IntOrString
+}
+
+// generateEntries generates the entries of an ApplicationSet in a stable order
+func generateEntries(ctx context.Context, c client.Reader, appSet *v1beta1.ApplicationSet) ([]entry, error) {
+	gen := appSet.Spec.Generator
+	set := 0
+	if len(gen.List) > 0 {
+		set++
+	}
+	if gen.Clusters != nil {
+		set++
+	}
+	if gen.Namespaces != nil {
+		set++
+	}
+	if set != 1 {
+		return nil, errors.New("one and only one of list, clusters and namespaces generators should be set")
+	}
+
+	var entries []entry
+	var err error
+	switch {
+	case len(gen.List) > 0:
+		entries = listEntries(appSet.Namespace, gen.List)
+	case gen.Clusters != nil:
+		entries, err = clusterEntries(ctx, c, appSet.Namespace, gen.Clusters)
+	default:
+		entries, err = namespaceEntries(ctx, c, appSet.Namespace, gen.Namespaces)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]bool, len(entries))
+	for _, e := range entries {
+		if seen[e.Name] {
+			return nil, errors.Errorf("entry %s is generated more than once", e.Name)
+		}
+		seen[e.Name] = true
+	}
+	return entries, nil
+}
+
+func listEntries(namespace string, elements []v1beta1.ApplicationSetElement) []entry {
+	entries := make([]entry, 0, len(elements))
+	for _, elem := range elements {
+		values := map[string]intstr.IntOrString{paramEntryName: intstr.FromString(elem.Name)}
+		for _, v := range elem.Values {
+			values[v.Name] = v.Value
+		}
+		entries = append(entries, entry{Name: elem.Name, Namespace: namespace, Values: values})
+	}
+	return entries
+}
+
+func clusterEntries(ctx context.Context, c client.Reader, namespace string, gen *v1beta1.ApplicationSetClusterGenerator) ([]entry, error) {
+	selector, err := toSelector(gen.Selector)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid selector of clusters")
+	}
+	clusters := &v1beta1.ClusterList{}
+	if err := c.List(ctx, clusters, client.InNamespace(namespace), client.MatchingLabelsSelector{Selector: selector}); err != nil {
+		return nil, errors.Wrap(err, "cannot list clusters")
+	}
+	entries := make([]entry, 0, len(clusters.Items))
+	for _, cluster := range clusters.Items {
+		entries = append(entries, labeledEntry(cluster.Name, namespace, cluster.Labels))
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name < entries[j].Name })
+	return entries, nil
+}
+
+// namespaceEntries generates the entries of namespaces allowing the ApplicationSets in appSetNamespace, the named
+// namespaces must allow them while the others selected are skipped
+func namespaceEntries(ctx context.Context, c client.Reader, appSetNamespace string, gen *v1beta1.ApplicationSetNamespaceGenerator) ([]entry, error) {
+	var entries []entry
+	if len(gen.Names) > 0 {
+		for _, name := range gen.Names {
+			ns := &corev1.Namespace{}
+			if err := c.Get(ctx, client.ObjectKey{Name: name}, ns); err != nil {
+				if apierrors.IsNotFound(err) {
+					return nil, errors.Errorf("namespace %s is not found", name)
+				}
+				return nil, errors.Wrapf(err, "cannot get namespace %s", name)
+			}
+			if !allowsApplicationSets(ns, appSetNamespace) {
+				return nil, errors.Errorf("namespace %s doesn't allow ApplicationSets in namespace %s, it should be labeled with %s=%s",
+					name, appSetNamespace, oam.LabelApplicationSetAllowedNamespace, appSetNamespace)
+			}
+			entries = append(entries, labeledEntry(name, name, ns.Labels))
+		}
+		return entries, nil
+	}
+	if gen.Selector == nil {
+		return nil, errors.New("either names or selector of namespaces should be set")
+	}
+	selector, err := metav1.LabelSelectorAsSelector(gen.Selector)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid selector of namespaces")
+	}
+	namespaces := &corev1.NamespaceList{}
+	if err := c.List(ctx, namespaces, client.MatchingLabelsSelector{Selector: selector}); err != nil {
+		return nil, errors.Wrap(err, "cannot list namespaces")
+	}
+	for i := range namespaces.Items {
+		ns := &namespaces.Items[i]
+		if !allowsApplicationSets(ns, appSetNamespace) {
+			continue
+		}
+		entries = append(entries, labeledEntry(ns.Name, ns.Name, ns.Labels))
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name < entries[j].Name })
+	return entries, nil
+}
+
+// allowsApplicationSets returns whether the ApplicationSets in appSetNamespace may generate applications in the
+// namespace, a namespace always allows the ApplicationSets in itself
+func allowsApplicationSets(ns *corev1.Namespace, appSetNamespace string) bool {
+	return ns.Name == appSetNamespace || ns.Labels[oam.LabelApplicationSetAllowedNamespace] == appSetNamespace
+}
+
+// labeledEntry generates an entry of a selected object, the labels of the object are values of the entry
+func labeledEntry(name, namespace string, objLabels map[string]string) entry {
+	values := make(map[string]intstr.IntOrString, len(objLabels)+1)
+	for k, v := range objLabels {
+		values[k] = intstr.FromString(v)
+	}
+	values[paramEntryName] = intstr.FromString(name)
+	return entry{Name: name, Namespace: namespace, Values: values}
+}
+
+func toSelector(selector *metav1.LabelSelector) (labels.Selector, error) {
+	if selector == nil {
+		return labels.Everything(), nil
+	}
+	return metav1.LabelSelectorAsSelector(selector)
+}
+
+// templateMetadata is the keys of labels and annotations set by the template of ApplicationSet to an application
+type templateMetadata struct {
+	Labels      []string `json:"labels,omitempty"`
+	Annotations []string `json:"annotations,omitempty"`
+}
+
+func newTemplateMetadata(tmpl *v1beta1.ApplicationSetTemplate) templateMetadata {
+	keys := func(m map[string]string) []string {
+		keys := make([]string, 0, len(m))
+		for k := range m {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		return keys
+	}
+	return templateMetadata{Labels: keys(tmpl.Metadata.Labels), Annotations: keys(tmpl.Metadata.Annotations)}
+}
+
+// applicationName is the name of the application rendered for an entry
+func applicationName(appSet *v1beta1.ApplicationSet, e entry) string {
+	return fmt.Sprintf("%s-%s", appSet.Name, e.Name)
+}
+
+// renderApplication renders the application of an entry from the template of ApplicationSet, the values of the
+// entry overwrite the fields of the template by parameters
+func renderApplication(appSet *v1beta1.ApplicationSet, e entry) (*v1beta1.Application, error) {
+	name := applicationName(appSet, e)
+	if errs := validation.IsDNS1123Subdomain(name); len(errs) > 0 {
+		return nil, errors.Errorf("invalid application name %s of entry %s: %s", name, e.Name, strings.Join(errs, ", "))
+	}
+
+	data, err := json.Marshal(appSet.Spec.Template)
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot marshal the application template")
+	}
+	paved := &fieldpath.Paved{}
+	if err := json.Unmarshal(data, paved); err != nil {
+		return nil, errors.Wrap(err, "cannot unmarshal the application template")
+	}
+	for _, p := range appSet.Spec.Parameters {
+		v, ok := e.Values[p.Name]
+		switch {
+		case ok:
+		case p.Default != nil:
+			v = *p.Default
+		case p.Required:
+			return nil, errors.Errorf("entry %s has no value of the required parameter %s", e.Name, p.Name)
+		default:
+			continue
+		}
+		var value interface{} = v.StrVal
+		if v.Type == intstr.Int {
+			value = int64(v.IntVal)
+		}
+		for _, path := range p.FieldPaths {
+			if err := paved.SetValue(path, value); err != nil {
+				return nil, errors.Wrapf(err, "cannot set parameter %s of entry %s to %s", p.Name, e.Name, path)
+			}
+		}
+	}
+	data, err = json.Marshal(paved)
+	if err != nil {
+		return nil, errors.Wrapf(err, "cannot marshal the application of entry %s", e.Name)
+	}
+	tmpl := &v1beta1.ApplicationSetTemplate{}
+	if err := json.Unmarshal(data, tmpl); err != nil {
+		return nil, errors.Wrapf(err, "cannot unmarshal the application of entry %s", e.Name)
+	}
+
+	app := &v1beta1.Application{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: v1beta1.SchemeGroupVersion.String(),
+			Kind:       v1beta1.ApplicationKind,
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        name,
+			Namespace:   e.Namespace,
+			Labels:      tmpl.Metadata.Labels,
+			Annotations: tmpl.Metadata.Annotations,
+		},
+		Spec: tmpl.Spec,
+	}
+	hash, err := utils.ComputeSpecHash(tmpl)
+	if err != nil {
+		return nil, errors.Wrapf(err, "cannot compute the hash of the application of entry %s", e.Name)
+	}
+	metadata, err := json.Marshal(newTemplateMetadata(tmpl))
+	if err != nil {
+		return nil, errors.Wrapf(err, "cannot marshal the metadata of the application of entry %s", e.Name)
+	}
+	if app.Labels == nil {
+		app.Labels = make(map[string]string)
+	}
+	app.Labels[oam.LabelApplicationSet] = appSet.Name
+	app.Labels[oam.LabelApplicationSetNamespace] = appSet.Namespace
+	app.Labels[oam.LabelApplicationSetEntry] = e.Name
+	if app.Annotations == nil {
+		app.Annotations = make(map[string]string)
+	}
+	app.Annotations[oam.AnnotationApplicationSetHash] = hash
+	app.Annotations[oam.AnnotationApplicationSetMetadata] = string(metadata)
+	if app.Namespace == appSet.Namespace {
+		app.OwnerReferences = []metav1.OwnerReference{*metav1.NewControllerRef(appSet, v1beta1.ApplicationSetKindVersionKind)}
+	}
+	return app, nil
+}
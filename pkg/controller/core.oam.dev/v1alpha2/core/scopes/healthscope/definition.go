@@ -0,0 +1,300 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package healthscope
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	runtimev1alpha1 "github.com/crossplane/crossplane-runtime/apis/core/v1alpha1"
+	"github.com/pkg/errors"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/common"
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1alpha2"
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
+	"github.com/oam-dev/kubevela/pkg/dsl/definition"
+	"github.com/oam-dev/kubevela/pkg/dsl/process"
+	"github.com/oam-dev/kubevela/pkg/oam"
+	"github.com/oam-dev/kubevela/pkg/oam/discoverymapper"
+	"github.com/oam-dev/kubevela/pkg/oam/util"
+)
+
+const (
+	infoFmtTraitUnhealthy = "trait %s is unhealthy"
+	errFmtEvalDefinition  = "evaluate status of definition %s"
+)
+
+// DefinitionHealthChecker checks health of workloads with the CUE healthPolicy and customStatus of their
+// ComponentDefinitions (or WorkloadDefinitions), the same as the Application controller does. Workloads whose
+// definitions have no health policy or custom status are left to other checkers. Traits are checked by CheckTraits
+// with their TraitDefinitions.
+type DefinitionHealthChecker struct {
+	dm discoverymapper.DiscoveryMapper
+}
+
+var _ WorloadHealthChecker = &DefinitionHealthChecker{}
+
+// NewDefinitionHealthChecker creates a DefinitionHealthChecker
+func NewDefinitionHealthChecker(dm discoverymapper.DiscoveryMapper) *DefinitionHealthChecker {
+	return &DefinitionHealthChecker{dm: dm}
+}
+
+// Check the health status of the workload and its traits, all workloads of a version-enabled component are checked
+func (h *DefinitionHealthChecker) Check(ctx context.Context, c client.Client, ref runtimev1alpha1.TypedReference, ns string) *WorkloadHealthCondition {
+	return WorkloadHealthCheckFn(h.check).Check(ctx, c, ref, ns)
+}
+
+// statusOutput is the live objects of a workload or trait with its status policies
+type statusOutput struct {
+	name    string
+	status  *common.Status
+	outputs map[string]interface{}
+}
+
+func (h *DefinitionHealthChecker) check(ctx context.Context, c client.Client, ref runtimev1alpha1.TypedReference, ns string) *WorkloadHealthCondition {
+	wl := &unstructured.Unstructured{}
+	wl.SetGroupVersionKind(ref.GroupVersionKind())
+	if err := c.Get(ctx, client.ObjectKey{Namespace: ns, Name: ref.Name}, wl); err != nil {
+		// leave it to other checkers to report the error
+		return nil
+	}
+	defCtx := util.SetNamespaceInCtx(ctx, ns)
+	workloadStatus, err := h.getWorkloadStatus(defCtx, c, wl)
+	if err != nil || !hasStatusPolicy(workloadStatus) {
+		return nil
+	}
+	compName, appName := getComponentNameFromLabel(wl), getAppConfigNameFromLabel(wl)
+	_, workloadOutputs, err := getTraitOutputs(defCtx, c, ns, appName, compName)
+	if err != nil {
+		return unhealthyCondition(ref, compName, err)
+	}
+
+	r := &WorkloadHealthCondition{
+		ComponentName:  compName,
+		TargetWorkload: ref,
+		HealthStatus:   StatusHealthy,
+	}
+	r.TargetWorkload.UID = wl.GetUID()
+	templateContext := newTemplateContext(appName, compName)
+	templateContext[definition.OutputFieldName] = wl.Object
+	if len(workloadOutputs) > 0 {
+		templateContext[definition.OutputsFieldName] = workloadOutputs
+	}
+	healthy, message, err := evalStatus(templateContext, workloadStatus)
+	if err != nil {
+		return unhealthyCondition(ref, compName, errors.WithMessagef(err, errFmtEvalDefinition, wl.GetLabels()[oam.WorkloadTypeLabel]))
+	}
+	if !healthy {
+		r.HealthStatus = StatusUnhealthy
+	}
+	r.Diagnosis = message
+	return r
+}
+
+// CheckTraits evaluates the health policy and custom status of traits applied to the workload, the health condition
+// of the workload checked by any checker becomes unhealthy if any trait is unhealthy, status messages of traits are
+// appended to its diagnosis.
+func (h *DefinitionHealthChecker) CheckTraits(ctx context.Context, c client.Client, r *WorkloadHealthCondition, ns string) {
+	wl := &unstructured.Unstructured{}
+	wl.SetGroupVersionKind(r.TargetWorkload.GroupVersionKind())
+	if err := c.Get(ctx, client.ObjectKey{Namespace: ns, Name: r.TargetWorkload.Name}, wl); err != nil {
+		return
+	}
+	compName, appName := getComponentNameFromLabel(wl), getAppConfigNameFromLabel(wl)
+	traits, _, err := getTraitOutputs(util.SetNamespaceInCtx(ctx, ns), c, ns, appName, compName)
+	if err != nil {
+		r.HealthStatus = StatusUnhealthy
+		r.Diagnosis = strings.TrimSpace(r.Diagnosis + " " + errors.Wrap(err, errHealthCheck).Error())
+		return
+	}
+	messages := []string{}
+	if r.Diagnosis != "" {
+		messages = append(messages, r.Diagnosis)
+	}
+	for _, trait := range traits {
+		templateContext := newTemplateContext(appName, compName)
+		templateContext[definition.OutputsFieldName] = trait.outputs
+		healthy, message, err := evalStatus(templateContext, trait.status)
+		if err != nil {
+			r.HealthStatus = StatusUnhealthy
+			messages = append(messages, errors.Wrapf(err, errFmtEvalDefinition, trait.name).Error())
+			continue
+		}
+		if !healthy {
+			r.HealthStatus = StatusUnhealthy
+			messages = append(messages, fmt.Sprintf(infoFmtTraitUnhealthy, trait.name))
+		}
+		if message != "" {
+			messages = append(messages, fmt.Sprintf("%s: %s", trait.name, message))
+		}
+	}
+	r.Diagnosis = strings.Join(messages, "; ")
+}
+
+// getWorkloadStatus gets the status policies from the definition of the workload, the definition is found by the
+// type label or the resource name of the workload
+func (h *DefinitionHealthChecker) getWorkloadStatus(ctx context.Context, c client.Reader, wl *unstructured.Unstructured) (*common.Status, error) {
+	defName, err := util.GetDefinitionName(h.dm, wl, oam.WorkloadTypeLabel)
+	if err != nil {
+		return nil, err
+	}
+	cd := &v1beta1.ComponentDefinition{}
+	err = util.GetDefinition(ctx, c, cd, defName)
+	if err == nil {
+		return cd.Spec.Status, nil
+	}
+	if !kerrors.IsNotFound(err) {
+		return nil, err
+	}
+	wd := &v1beta1.WorkloadDefinition{}
+	if err := util.GetDefinition(ctx, c, wd, defName); err != nil {
+		return nil, err
+	}
+	return wd.Spec.Status, nil
+}
+
+// getTraitOutputs gets the live objects of traits and auxiliary workloads rendered for the component in the AppConfig,
+// traits whose definitions have no health policy nor custom status are skipped
+func getTraitOutputs(ctx context.Context, c client.Reader, ns, appName, compName string) ([]statusOutput, map[string]interface{}, error) {
+	if appName == "" || compName == "" {
+		return nil, nil, nil
+	}
+	ac := &v1alpha2.ApplicationConfiguration{}
+	if err := c.Get(ctx, client.ObjectKey{Namespace: ns, Name: appName}, ac); err != nil {
+		return nil, nil, client.IgnoreNotFound(err)
+	}
+	workloadOutputs := map[string]interface{}{}
+	traitOutputs := map[string]*statusOutput{}
+	for _, acc := range ac.Spec.Components {
+		if acc.ComponentName != compName {
+			continue
+		}
+		for i := range acc.Traits {
+			t, err := util.RawExtension2Unstructured(&acc.Traits[i].Trait)
+			if err != nil {
+				return nil, nil, err
+			}
+			traitType, resource := t.GetLabels()[oam.TraitTypeLabel], t.GetLabels()[oam.TraitResource]
+			if traitType == "" {
+				continue
+			}
+			if traitType != definition.AuxiliaryWorkload {
+				if _, ok := traitOutputs[traitType]; !ok {
+					td := &v1beta1.TraitDefinition{}
+					if err := util.GetDefinition(ctx, c, td, traitType); err != nil {
+						if kerrors.IsNotFound(err) {
+							continue
+						}
+						return nil, nil, err
+					}
+					traitOutputs[traitType] = &statusOutput{name: traitType, status: td.Spec.Status, outputs: map[string]interface{}{}}
+				}
+				if !hasStatusPolicy(traitOutputs[traitType].status) {
+					continue
+				}
+			}
+			live, err := getLiveObject(ctx, c, t, ns, map[string]string{
+				oam.LabelAppName:      appName,
+				oam.LabelAppComponent: compName,
+				oam.TraitTypeLabel:    traitType,
+			}, resource)
+			if err != nil {
+				return nil, nil, err
+			}
+			if traitType == definition.AuxiliaryWorkload {
+				workloadOutputs[resource] = live
+			} else {
+				traitOutputs[traitType].outputs[resource] = live
+			}
+		}
+	}
+	var traits []statusOutput
+	for _, t := range traitOutputs {
+		if hasStatusPolicy(t.status) {
+			traits = append(traits, *t)
+		}
+	}
+	sort.Slice(traits, func(i, j int) bool { return traits[i].name < traits[j].name })
+	return traits, workloadOutputs, nil
+}
+
+// getLiveObject gets the object rendered from a template by its name, or by labels if the name is generated
+func getLiveObject(ctx context.Context, c client.Reader, t *unstructured.Unstructured, ns string, labels map[string]string, resource string) (map[string]interface{}, error) {
+	if t.GetName() != "" {
+		u, err := util.GetObjectGivenGVKAndName(ctx, c, t.GroupVersionKind(), ns, t.GetName())
+		if err != nil {
+			return nil, err
+		}
+		return u.Object, nil
+	}
+	if resource != "" {
+		labels[oam.TraitResource] = resource
+	}
+	list, err := util.GetObjectsGivenGVKAndLabels(ctx, c, t.GroupVersionKind(), ns, labels)
+	if err != nil {
+		return nil, err
+	}
+	if len(list.Items) == 0 {
+		return nil, errors.Errorf("cannot find %s with labels %v", t.GetKind(), labels)
+	}
+	return list.Items[0].Object, nil
+}
+
+func hasStatusPolicy(s *common.Status) bool {
+	return s != nil && (s.HealthPolicy != "" || s.CustomStatus != "")
+}
+
+func evalStatus(templateContext map[string]interface{}, s *common.Status) (bool, string, error) {
+	if s == nil {
+		return true, "", nil
+	}
+	healthy := true
+	var message string
+	var err error
+	if s.HealthPolicy != "" {
+		if healthy, err = definition.CheckHealth(templateContext, s.HealthPolicy); err != nil {
+			return false, "", err
+		}
+	}
+	if s.CustomStatus != "" {
+		if message, err = definition.GetStatusMessage(templateContext, s.CustomStatus); err != nil {
+			return false, "", err
+		}
+	}
+	return healthy, message, nil
+}
+
+func newTemplateContext(appName, compName string) map[string]interface{} {
+	return map[string]interface{}{
+		process.ContextName:    compName,
+		process.ContextAppName: appName,
+	}
+}
+
+func unhealthyCondition(ref runtimev1alpha1.TypedReference, compName string, err error) *WorkloadHealthCondition {
+	return &WorkloadHealthCondition{
+		ComponentName:  compName,
+		TargetWorkload: ref,
+		HealthStatus:   StatusUnhealthy,
+		Diagnosis:      errors.Wrap(err, errHealthCheck).Error(),
+	}
+}
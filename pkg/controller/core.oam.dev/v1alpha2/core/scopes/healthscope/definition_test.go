@@ -0,0 +1,142 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package healthscope
+
+import (
+	"testing"
+
+	runtimev1alpha1 "github.com/crossplane/crossplane-runtime/apis/core/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	apps "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/common"
+	corev1alpha2 "github.com/oam-dev/kubevela/apis/core.oam.dev/v1alpha2"
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
+	"github.com/oam-dev/kubevela/pkg/oam"
+	"github.com/oam-dev/kubevela/pkg/oam/discoverymapper"
+	common2 "github.com/oam-dev/kubevela/pkg/utils/common"
+)
+
+func TestDefinitionHealthChecker(t *testing.T) {
+	varInt2 := int32(2)
+	deploy := &apps.Deployment{
+		TypeMeta: v1.TypeMeta{APIVersion: "apps/v1", Kind: kindDeployment},
+		ObjectMeta: v1.ObjectMeta{
+			Name:      "web",
+			Namespace: namespace,
+			UID:       "web-uid",
+			Labels: map[string]string{
+				oam.WorkloadTypeLabel: "worker",
+				oam.LabelAppName:      "app",
+				oam.LabelAppComponent: "web",
+			},
+		},
+		Spec:   apps.DeploymentSpec{Replicas: &varInt1},
+		Status: apps.DeploymentStatus{ReadyReplicas: 1},
+	}
+	svc := &corev1.Service{
+		TypeMeta:   v1.TypeMeta{APIVersion: "v1", Kind: "Service"},
+		ObjectMeta: v1.ObjectMeta{Name: "web-svc", Namespace: namespace},
+		Spec:       corev1.ServiceSpec{ClusterIP: ""},
+	}
+	ac := &corev1alpha2.ApplicationConfiguration{
+		ObjectMeta: v1.ObjectMeta{Name: "app", Namespace: namespace},
+		Spec: corev1alpha2.ApplicationConfigurationSpec{
+			Components: []corev1alpha2.ApplicationConfigurationComponent{{
+				ComponentName: "web",
+				Traits: []corev1alpha2.ComponentTrait{{
+					Trait: runtime.RawExtension{Raw: []byte(`{"apiVersion":"v1","kind":"Service","metadata":{"name":"web-svc",` +
+						`"labels":{"trait.oam.dev/type":"expose","trait.oam.dev/resource":"service"}}}`)},
+				}},
+			}},
+		},
+	}
+	cd := &v1beta1.ComponentDefinition{
+		ObjectMeta: v1.ObjectMeta{Name: "worker", Namespace: oam.SystemDefinitonNamespace},
+		Spec: v1beta1.ComponentDefinitionSpec{
+			Status: &common.Status{
+				HealthPolicy: `isHealth: context.output.status.readyReplicas == context.output.spec.replicas`,
+				CustomStatus: `message: "ready replicas: \(context.output.status.readyReplicas)"`,
+			},
+		},
+	}
+	td := &v1beta1.TraitDefinition{
+		ObjectMeta: v1.ObjectMeta{Name: "expose", Namespace: oam.SystemDefinitonNamespace},
+		Spec: v1beta1.TraitDefinitionSpec{
+			Status: &common.Status{
+				HealthPolicy: `isHealth: context.outputs.service.spec.clusterIP != _|_`,
+			},
+		},
+	}
+	ref := runtimev1alpha1.TypedReference{APIVersion: "apps/v1", Kind: kindDeployment, Name: "web"}
+	dm := discoverymapper.NewStaticDiscoveryMapper(common2.Scheme)
+
+	t.Run("workload and trait are unhealthy", func(t *testing.T) {
+		d := deploy.DeepCopy()
+		d.Spec.Replicas = &varInt2
+		c := fake.NewFakeClientWithScheme(common2.Scheme, d, svc.DeepCopy(), ac.DeepCopy(), cd.DeepCopy(), td.DeepCopy())
+		checker := NewDefinitionHealthChecker(dm)
+		r := checker.check(ctx, c, ref, namespace)
+		assert.NotNil(t, r)
+		assert.EqualValues(t, StatusUnhealthy, r.HealthStatus)
+		assert.Equal(t, "web", r.ComponentName)
+		assert.Equal(t, "web-uid", string(r.TargetWorkload.UID))
+		assert.Equal(t, "ready replicas: 1", r.Diagnosis)
+
+		checker.CheckTraits(ctx, c, r, namespace)
+		assert.EqualValues(t, StatusUnhealthy, r.HealthStatus)
+		assert.Equal(t, "ready replicas: 1; trait expose is unhealthy", r.Diagnosis)
+	})
+
+	t.Run("workload and trait are healthy", func(t *testing.T) {
+		d := deploy.DeepCopy()
+		s := svc.DeepCopy()
+		s.Spec.ClusterIP = "10.0.0.1"
+		c := fake.NewFakeClientWithScheme(common2.Scheme, d, s, ac.DeepCopy(), cd.DeepCopy(), td.DeepCopy())
+		checker := NewDefinitionHealthChecker(dm)
+		r := checker.check(ctx, c, ref, namespace)
+		assert.NotNil(t, r)
+		checker.CheckTraits(ctx, c, r, namespace)
+		assert.Equal(t, StatusHealthy, r.HealthStatus)
+		assert.Equal(t, "ready replicas: 1", r.Diagnosis)
+	})
+
+	t.Run("trait policy applies to workloads checked by other checkers", func(t *testing.T) {
+		d := deploy.DeepCopy()
+		noPolicy := cd.DeepCopy()
+		noPolicy.Spec.Status = nil
+		c := fake.NewFakeClientWithScheme(common2.Scheme, d, svc.DeepCopy(), ac.DeepCopy(), noPolicy, td.DeepCopy())
+		checker := NewDefinitionHealthChecker(dm)
+		assert.Nil(t, checker.check(ctx, c, ref, namespace))
+
+		r := CheckDeploymentHealth(ctx, c, ref, namespace)
+		assert.Equal(t, StatusHealthy, r.HealthStatus)
+		checker.CheckTraits(ctx, c, r, namespace)
+		assert.EqualValues(t, StatusUnhealthy, r.HealthStatus)
+		assert.Equal(t, "Ready:1/1 ; trait expose is unhealthy", r.Diagnosis)
+	})
+
+	t.Run("no definition", func(t *testing.T) {
+		c := fake.NewFakeClientWithScheme(common2.Scheme, deploy.DeepCopy())
+		checker := NewDefinitionHealthChecker(dm)
+		assert.Nil(t, checker.check(ctx, c, ref, namespace))
+	})
+}
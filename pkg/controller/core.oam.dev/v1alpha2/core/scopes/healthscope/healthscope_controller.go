@@ -18,6 +18,7 @@ package healthscope
 
 import (
 	"context"
+	"fmt"
 	"strings"
 	"sync"
 	"time"
@@ -56,7 +57,7 @@ const (
 )
 
 // Setup adds a controller that reconciles HealthScope.
-func Setup(mgr ctrl.Manager, _ controller.Args, l logging.Logger) error {
+func Setup(mgr ctrl.Manager, args controller.Args, l logging.Logger) error {
 	name := "oam/" + strings.ToLower(v1alpha2.HealthScopeGroupKind)
 
 	return ctrl.NewControllerManagedBy(mgr).
@@ -65,6 +66,7 @@ func Setup(mgr ctrl.Manager, _ controller.Args, l logging.Logger) error {
 		Complete(NewReconciler(mgr,
 			WithLogger(l.WithValues("controller", name)),
 			WithRecorder(event.NewAPIRecorder(mgr.GetEventRecorderFor(name))),
+			WithDefinitionChecker(NewDefinitionHealthChecker(args.DiscoveryMapper)),
 		))
 }
 
@@ -76,6 +78,8 @@ type Reconciler struct {
 	record event.Recorder
 	// traitChecker represents checker fetching health condition from HealthCheckTrait
 	traitChecker WorloadHealthChecker
+	// definitionChecker represents checker evaluating health policies of workload and trait definitions
+	definitionChecker *DefinitionHealthChecker
 	// checkers represents a set of built-in checkers
 	checkers []WorloadHealthChecker
 	// unknownChecker represents checker handling workloads that
@@ -107,6 +111,13 @@ func WithTraitChecker(c WorloadHealthChecker) ReconcilerOption {
 	}
 }
 
+// WithDefinitionChecker adds health checker based on health policies of definitions
+func WithDefinitionChecker(c *DefinitionHealthChecker) ReconcilerOption {
+	return func(r *Reconciler) {
+		r.definitionChecker = c
+	}
+}
+
 // WithChecker adds workload health checker
 func WithChecker(c WorloadHealthChecker) ReconcilerOption {
 	return func(r *Reconciler) {
@@ -212,19 +223,41 @@ func (r *Reconciler) GetScopeHealthStatus(ctx context.Context, healthScope *v1al
 				workloadHealthConditionsC <- wlHealthCondition
 				return
 			}
+			defer func() {
+				if wlHealthCondition == nil {
+					// a checker may give no result, the workload is unknown then
+					wlHealthCondition = &WorkloadHealthCondition{
+						TargetWorkload: resRef,
+						HealthStatus:   StatusUnknown,
+						Diagnosis:      fmt.Sprintf(infoFmtUnknownWorkload, resRef.APIVersion, resRef.Kind),
+					}
+				}
+				if r.definitionChecker != nil {
+					// health policies of traits apply to the workload checked by any checker
+					r.definitionChecker.CheckTraits(ctxWithTimeout, r.client, wlHealthCondition, healthScope.GetNamespace())
+				}
+				workloadHealthConditionsC <- wlHealthCondition
+			}()
+
+			if r.definitionChecker != nil {
+				wlHealthCondition = r.definitionChecker.Check(ctxWithTimeout, r.client, resRef, healthScope.GetNamespace())
+				if wlHealthCondition != nil {
+					log.Debug("get health condition from definition", "workload", resRef, "healthCondition", wlHealthCondition)
+					return
+				}
+			}
 
 			for _, checker := range r.checkers {
 				wlHealthCondition = checker.Check(ctxWithTimeout, r.client, resRef, healthScope.GetNamespace())
 				if wlHealthCondition != nil {
 					log.Debug("get health condition from built-in checker", "workload", resRef, "healthCondition", wlHealthCondition)
 					// found matched checker and get health condition
-					workloadHealthConditionsC <- wlHealthCondition
 					return
 				}
 			}
 			// handle unknown workload
 			log.Debug("get unknown workload", "workload", resRef)
-			workloadHealthConditionsC <- r.unknownChecker.Check(ctx, r.client, resRef, healthScope.GetNamespace())
+			wlHealthCondition = r.unknownChecker.Check(ctx, r.client, resRef, healthScope.GetNamespace())
 		}(workloadRef)
 	}
 
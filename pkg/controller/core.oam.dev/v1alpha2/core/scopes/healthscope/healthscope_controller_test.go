@@ -154,6 +154,24 @@ var _ = Describe("HealthScope Controller Reconcile Test", func() {
 		_, err := reconciler.Reconcile(reconcile.Request{})
 		Expect(err).Should(BeNil())
 	})
+
+	It("Test unknown checker without result", func() {
+		reconciler.unknownChecker = WorkloadHealthCheckFn(
+			func(context.Context, client.Client, v1alpha1.TypedReference, string) *WorkloadHealthCondition {
+				return nil
+			})
+		defer func() { reconciler.unknownChecker = WorkloadHealthCheckFn(CheckUnknownWorkload) }()
+		reconciler.client = &test.MockClient{
+			MockGet: func(ctx context.Context, key client.ObjectKey, obj runtime.Object) error {
+				return errMockErr
+			},
+		}
+		scopeCondition, wlConditions := reconciler.GetScopeHealthStatus(context.Background(), &hs)
+		Expect(scopeCondition.HealthStatus).Should(BeEquivalentTo(StatusUnhealthy))
+		Expect(scopeCondition.UnknownWorkloads).Should(Equal(int64(1)))
+		Expect(wlConditions).Should(HaveLen(1))
+		Expect(wlConditions[0].HealthStatus).Should(BeEquivalentTo(StatusUnknown))
+	})
 })
 
 var _ = Describe("Test GetScopeHealthStatus", func() {
@@ -19,6 +19,7 @@ package utils
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"regexp"
 	"strings"
@@ -120,12 +121,19 @@ func (def *CapabilityComponentDefinition) GetKubeSchematicOpenAPISchema(params [
 			tmp = openapi3.NewFloat64Schema()
 		case commontypes.BooleanType:
 			tmp = openapi3.NewBoolSchema()
+		case commontypes.ObjectType:
+			tmp = openapi3.NewObjectSchema()
+		case commontypes.ArrayType:
+			tmp = openapi3.NewArraySchema()
 		default:
 			tmp = openapi3.NewStringSchema()
 		}
-		if p.Required != nil && *p.Required {
+		if p.Required != nil && *p.Required && p.Default == nil {
 			required = append(required, p.Name)
 		}
+		if err := setKubeParameterConstraints(tmp, p); err != nil {
+			return nil, err
+		}
 		// save FieldPaths into description
 		tmp.Description = fmt.Sprintf("The value will be applied to fields: [%s].", strings.Join(p.FieldPaths, ","))
 		if p.Description != nil {
@@ -144,6 +152,32 @@ func (def *CapabilityComponentDefinition) GetKubeSchematicOpenAPISchema(params [
 	return b, nil
 }
 
+// setKubeParameterConstraints sets the default value and constraints of kube parameter to its schema
+func setKubeParameterConstraints(s *openapi3.Schema, p commontypes.KubeParameter) error {
+	if p.Default != nil {
+		if err := json.Unmarshal(p.Default.Raw, &s.Default); err != nil {
+			return errors.Wrapf(err, "cannot decode default value of parameter %q", p.Name)
+		}
+	}
+	for _, e := range p.Enum {
+		var v interface{}
+		if err := json.Unmarshal(e.Raw, &v); err != nil {
+			return errors.Wrapf(err, "cannot decode enum of parameter %q", p.Name)
+		}
+		s.Enum = append(s.Enum, v)
+	}
+	if p.Pattern != nil {
+		s.Pattern = *p.Pattern
+	}
+	if p.Minimum != nil {
+		s.Min = pointer.Float64Ptr(float64(*p.Minimum))
+	}
+	if p.Maximum != nil {
+		s.Max = pointer.Float64Ptr(float64(*p.Maximum))
+	}
+	return nil
+}
+
 // StoreOpenAPISchema stores OpenAPI v3 schema in ConfigMap from WorkloadDefinition
 func (def *CapabilityComponentDefinition) StoreOpenAPISchema(ctx context.Context, k8sClient client.Client,
 	pd *definition.PackageDiscover, namespace, name, revName string) error {
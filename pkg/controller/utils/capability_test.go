@@ -28,6 +28,8 @@ import (
 	"github.com/getkin/kin-openapi/openapi3"
 	"github.com/google/go-cmp/cmp"
 	"gotest.tools/assert"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/utils/pointer"
 
 	"github.com/oam-dev/kubevela/apis/core.oam.dev/common"
 	"github.com/oam-dev/kubevela/apis/types"
@@ -148,3 +150,56 @@ func TestGenerateOpenAPISchemaFromCapabilityParameter(t *testing.T) {
 		})
 	}
 }
+
+func TestGetKubeSchematicOpenAPISchema(t *testing.T) {
+	params := []common.KubeParameter{
+		{
+			Name:       "replicas",
+			ValueType:  common.NumberType,
+			FieldPaths: []string{"spec.replicas"},
+			Required:   pointer.BoolPtr(true),
+			Default:    &apiextensionsv1.JSON{Raw: []byte(`1`)},
+			Minimum:    pointer.Int64Ptr(1),
+			Maximum:    pointer.Int64Ptr(10),
+		},
+		{
+			Name:       "pullPolicy",
+			ValueType:  common.StringType,
+			FieldPaths: []string{"spec.template.spec.containers[*].imagePullPolicy"},
+			Enum:       []apiextensionsv1.JSON{{Raw: []byte(`"Always"`)}, {Raw: []byte(`"IfNotPresent"`)}},
+		},
+		{
+			Name:       "image",
+			ValueType:  common.StringType,
+			FieldPaths: []string{"spec.template.spec.containers[0].image"},
+			Required:   pointer.BoolPtr(true),
+			Pattern:    pointer.StringPtr(`^.+:.+$`),
+		},
+		{
+			Name:       "env",
+			ValueType:  common.ArrayType,
+			FieldPaths: []string{"spec.template.spec.containers[0].env"},
+		},
+		{
+			Name:       "resources",
+			ValueType:  common.ObjectType,
+			FieldPaths: []string{"spec.template.spec.containers[0].resources"},
+		},
+	}
+	def := &CapabilityComponentDefinition{}
+	data, err := def.GetKubeSchematicOpenAPISchema(params)
+	assert.NilError(t, err)
+	schema := openapi3.NewSchema()
+	assert.NilError(t, schema.UnmarshalJSON(data))
+
+	assert.DeepEqual(t, schema.Required, []string{"image"})
+	replicas := schema.Properties["replicas"].Value
+	assert.Equal(t, replicas.Type, "number")
+	assert.Equal(t, replicas.Default, float64(1))
+	assert.Equal(t, *replicas.Min, float64(1))
+	assert.Equal(t, *replicas.Max, float64(10))
+	assert.DeepEqual(t, schema.Properties["pullPolicy"].Value.Enum, []interface{}{"Always", "IfNotPresent"})
+	assert.Equal(t, schema.Properties["image"].Value.Pattern, `^.+:.+$`)
+	assert.Equal(t, schema.Properties["env"].Value.Type, "array")
+	assert.Equal(t, schema.Properties["resources"].Value.Type, "object")
+}
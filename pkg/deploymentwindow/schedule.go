@@ -0,0 +1,177 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deploymentwindow
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// maxScheduleYears limits how far the next activation of a schedule is searched, a schedule like `0 0 30 2 *`
+// never activates
+const maxScheduleYears = 5
+
+var descriptors = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+var monthNames = map[string]int{
+	"jan": 1, "feb": 2, "mar": 3, "apr": 4, "may": 5, "jun": 6,
+	"jul": 7, "aug": 8, "sep": 9, "oct": 10, "nov": 11, "dec": 12,
+}
+
+var weekdayNames = map[string]int{
+	"sun": 0, "mon": 1, "tue": 2, "wed": 3, "thu": 4, "fri": 5, "sat": 6,
+}
+
+// Schedule is a standard cron schedule with five fields: minute, hour, day of month, month and day of week
+type Schedule struct {
+	minute, hour, dom, month, dow []bool
+	// a day matches if either day of month or day of week matches when both are restricted, as cron does
+	domRestricted, dowRestricted bool
+	location                     *time.Location
+}
+
+// ParseSchedule parses a cron expression in the time zone, e.g. `0 22 * * 1-5` or `@daily`
+func ParseSchedule(spec string, location *time.Location) (*Schedule, error) {
+	expr := strings.TrimSpace(spec)
+	if d, ok := descriptors[strings.ToLower(expr)]; ok {
+		expr = d
+	}
+	fields := strings.Fields(expr)
+	if len(fields) != 5 {
+		return nil, errors.Errorf("invalid schedule %q: expected 5 fields, got %d", spec, len(fields))
+	}
+	s := &Schedule{location: location}
+	var err error
+	if s.minute, err = parseField(fields[0], 0, 59, nil); err != nil {
+		return nil, errors.Wrapf(err, "invalid minute of schedule %q", spec)
+	}
+	if s.hour, err = parseField(fields[1], 0, 23, nil); err != nil {
+		return nil, errors.Wrapf(err, "invalid hour of schedule %q", spec)
+	}
+	if s.dom, err = parseField(fields[2], 1, 31, nil); err != nil {
+		return nil, errors.Wrapf(err, "invalid day of month of schedule %q", spec)
+	}
+	if s.month, err = parseField(fields[3], 1, 12, monthNames); err != nil {
+		return nil, errors.Wrapf(err, "invalid month of schedule %q", spec)
+	}
+	// 7 is Sunday too
+	if s.dow, err = parseField(fields[4], 0, 7, weekdayNames); err != nil {
+		return nil, errors.Wrapf(err, "invalid day of week of schedule %q", spec)
+	}
+	s.dow[0] = s.dow[0] || s.dow[7]
+	s.domRestricted = !strings.HasPrefix(fields[2], "*")
+	s.dowRestricted = !strings.HasPrefix(fields[4], "*")
+	return s, nil
+}
+
+// parseField parses a comma separated list of `*`, values and ranges with optional steps, e.g. `1-5,*/15`
+func parseField(field string, min, max int, names map[string]int) ([]bool, error) {
+	matches := make([]bool, max+1)
+	for _, part := range strings.Split(field, ",") {
+		rng, step := part, 1
+		if i := strings.Index(part, "/"); i >= 0 {
+			var err error
+			rng = part[:i]
+			if step, err = strconv.Atoi(part[i+1:]); err != nil || step <= 0 {
+				return nil, errors.Errorf("invalid step %q", part[i+1:])
+			}
+		}
+		low, high := min, max
+		if rng != "*" {
+			bounds := strings.SplitN(rng, "-", 2)
+			var err error
+			if low, err = parseValue(bounds[0], min, max, names); err != nil {
+				return nil, err
+			}
+			high = low
+			if len(bounds) == 2 {
+				if high, err = parseValue(bounds[1], min, max, names); err != nil {
+					return nil, err
+				}
+			} else if step > 1 {
+				// `a/n` means from a to the max
+				high = max
+			}
+			if low > high {
+				return nil, errors.Errorf("invalid range %q", rng)
+			}
+		}
+		for v := low; v <= high; v += step {
+			matches[v] = true
+		}
+	}
+	return matches, nil
+}
+
+func parseValue(value string, min, max int, names map[string]int) (int, error) {
+	if v, ok := names[strings.ToLower(value)]; ok {
+		return v, nil
+	}
+	v, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, errors.Errorf("invalid value %q", value)
+	}
+	if v < min || v > max {
+		return 0, errors.Errorf("value %d is out of range [%d, %d]", v, min, max)
+	}
+	return v, nil
+}
+
+// Next returns the first activation of the schedule after t, it's zero if the schedule never activates
+func (s *Schedule) Next(t time.Time) time.Time {
+	t = t.In(s.location).Truncate(time.Minute).Add(time.Minute)
+	end := t.AddDate(maxScheduleYears, 0, 0)
+	for day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, s.location); day.Before(end); day = day.AddDate(0, 0, 1) {
+		if !s.month[day.Month()] || !s.dayMatches(day) {
+			continue
+		}
+		for h := 0; h < 24; h++ {
+			if !s.hour[h] {
+				continue
+			}
+			for m := 0; m < 60; m++ {
+				if !s.minute[m] {
+					continue
+				}
+				next := time.Date(day.Year(), day.Month(), day.Day(), h, m, 0, 0, s.location)
+				if !next.Before(t) {
+					return next
+				}
+			}
+		}
+	}
+	return time.Time{}
+}
+
+func (s *Schedule) dayMatches(day time.Time) bool {
+	domMatch, dowMatch := s.dom[day.Day()], s.dow[day.Weekday()]
+	if s.domRestricted && s.dowRestricted {
+		return domMatch || dowMatch
+	}
+	return domMatch && dowMatch
+}
@@ -0,0 +1,90 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deploymentwindow
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseSchedule(t *testing.T) {
+	for _, spec := range []string{"* * * * *", "0 22 * * 1-5", "*/15 0-6/2 1,15 jan-jun SUN", "@daily", "0 0 * * 7"} {
+		_, err := ParseSchedule(spec, time.UTC)
+		assert.NoError(t, err, spec)
+	}
+	for _, spec := range []string{"", "* * * *", "60 * * * *", "* 24 * * *", "* * 0 * *", "* * * 13 *", "* * * * 8",
+		"*/0 * * * *", "5-1 * * * *", "a * * * *", "@every 1h"} {
+		_, err := ParseSchedule(spec, time.UTC)
+		assert.Error(t, err, spec)
+	}
+}
+
+func TestScheduleNext(t *testing.T) {
+	shanghai, err := time.LoadLocation("Asia/Shanghai")
+	assert.NoError(t, err)
+	// Friday
+	now := time.Date(2021, 6, 4, 23, 30, 15, 0, time.UTC)
+	testCases := map[string]struct {
+		spec     string
+		location *time.Location
+		want     time.Time
+	}{
+		"every minute": {
+			spec:     "* * * * *",
+			location: time.UTC,
+			want:     time.Date(2021, 6, 4, 23, 31, 0, 0, time.UTC),
+		},
+		"weekday nights skip the weekend": {
+			spec:     "0 22 * * mon-fri",
+			location: time.UTC,
+			want:     time.Date(2021, 6, 7, 22, 0, 0, 0, time.UTC),
+		},
+		"steps": {
+			spec:     "*/20 * * * *",
+			location: time.UTC,
+			want:     time.Date(2021, 6, 4, 23, 40, 0, 0, time.UTC),
+		},
+		"day of month or day of week": {
+			spec:     "0 0 10 * sat",
+			location: time.UTC,
+			want:     time.Date(2021, 6, 5, 0, 0, 0, 0, time.UTC),
+		},
+		"next year": {
+			spec:     "0 8 1 jan *",
+			location: time.UTC,
+			want:     time.Date(2022, 1, 1, 8, 0, 0, 0, time.UTC),
+		},
+		"time zone": {
+			spec:     "0 8 * * *",
+			location: shanghai,
+			want:     time.Date(2021, 6, 5, 8, 0, 0, 0, shanghai),
+		},
+		"never": {
+			spec:     "0 0 30 2 *",
+			location: time.UTC,
+		},
+	}
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			schedule, err := ParseSchedule(tc.spec, tc.location)
+			assert.NoError(t, err)
+			assert.True(t, tc.want.Equal(schedule.Next(now)), "got %s", schedule.Next(now))
+		})
+	}
+}
@@ -0,0 +1,197 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deploymentwindow
+
+import (
+	"context"
+	"fmt"
+	"time"
+	// the time zones of windows are loaded from the embedded database if the system has none, e.g. alpine
+	_ "time/tzdata"
+
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
+	"github.com/oam-dev/kubevela/pkg/oam"
+	oamutil "github.com/oam-dev/kubevela/pkg/oam/util"
+)
+
+// Result is the result of evaluating the deployment windows of an object
+type Result struct {
+	// Open means the object may change now
+	Open bool
+
+	// Message explains why the changes are held, it's empty if the object may change
+	Message string
+
+	// NextCheck is when the object may change, it's zero if it's unknown
+	NextCheck time.Time
+
+	// Overridden means the object may change only because the deployment windows are overridden
+	Overridden bool
+
+	// OverriddenBy is the user who set the override
+	OverriddenBy string
+
+	// OverrideUntil is when the override expires
+	OverrideUntil time.Time
+}
+
+// RequeueAfter is how long to wait before checking the deployment windows again, it's zero if they are not
+// going to change over time
+func (r *Result) RequeueAfter(now time.Time) time.Duration {
+	if r.NextCheck.IsZero() {
+		return 0
+	}
+	// a moment later in case the timer fires early
+	return r.NextCheck.Sub(now) + time.Second
+}
+
+// Check evaluates the deployment windows selecting the object of kind, Application or AppRollout, at the time now
+func Check(ctx context.Context, c client.Reader, kind string, obj metav1.Object, now time.Time) (*Result, error) {
+	windows := &v1beta1.DeploymentWindowList{}
+	if err := c.List(ctx, windows, client.InNamespace(obj.GetNamespace())); err != nil {
+		return nil, errors.Wrap(err, "cannot list deployment windows")
+	}
+	var selected []v1beta1.DeploymentWindow
+	for _, w := range windows.Items {
+		if selects(w, kind, obj.GetName()) {
+			selected = append(selected, w)
+		}
+	}
+	res, err := evaluate(selected, now)
+	if err != nil || res.Open {
+		return res, err
+	}
+
+	annotations := obj.GetAnnotations()
+	if until, ok := annotations[oam.AnnotationDeploymentWindowOverride]; ok {
+		t, err := time.Parse(time.RFC3339, until)
+		if err != nil {
+			res.Message += fmt.Sprintf(", the override %q is ignored as it's not in RFC3339 format", until)
+			return res, nil
+		}
+		if now.Before(t) {
+			return &Result{
+				Open:          true,
+				Overridden:    true,
+				OverriddenBy:  annotations[oam.AnnotationDeploymentWindowOverrideBy],
+				OverrideUntil: t,
+			}, nil
+		}
+	}
+	return res, nil
+}
+
+// selects returns whether the deployment window selects the object of kind by name
+func selects(w v1beta1.DeploymentWindow, kind, name string) bool {
+	if len(w.Spec.Applications) == 0 && len(w.Spec.AppRollouts) == 0 {
+		return true
+	}
+	names := w.Spec.AppRollouts
+	if kind == v1beta1.ApplicationKind {
+		names = w.Spec.Applications
+	}
+	for _, n := range names {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
+
+// evaluate decides whether changes are allowed at the time now, changes are held inside any block window, or
+// outside of all the allow windows if there is any
+func evaluate(windows []v1beta1.DeploymentWindow, now time.Time) (*Result, error) {
+	var blockedBy string
+	var blockedUntil, nextOpen time.Time
+	hasAllow, allowOpen := false, false
+	for _, dw := range windows {
+		for i, w := range dw.Spec.Windows {
+			name := fmt.Sprintf("%s/%d", dw.Name, i)
+			if w.Name != "" {
+				name = fmt.Sprintf("%s/%s", dw.Name, w.Name)
+			}
+			location, err := time.LoadLocation(w.TimeZone)
+			if err != nil {
+				return nil, errors.Wrapf(err, "invalid time zone of window %s", name)
+			}
+			schedule, err := ParseSchedule(w.Schedule, location)
+			if err != nil {
+				return nil, errors.Wrapf(err, "invalid window %s", name)
+			}
+			if w.Duration.Duration <= 0 {
+				return nil, errors.Errorf("invalid window %s: duration must be positive", name)
+			}
+			// the latest opening is the first one after now - duration if the window is open
+			start := schedule.Next(now.Add(-w.Duration.Duration))
+			open := !start.IsZero() && !start.After(now)
+			if w.Type == v1beta1.BlockWindow {
+				if open && start.Add(w.Duration.Duration).After(blockedUntil) {
+					blockedBy, blockedUntil = name, start.Add(w.Duration.Duration)
+				}
+				continue
+			}
+			hasAllow = true
+			if open {
+				allowOpen = true
+				continue
+			}
+			if next := schedule.Next(now); !next.IsZero() && (nextOpen.IsZero() || next.Before(nextOpen)) {
+				nextOpen = next
+			}
+		}
+	}
+
+	switch {
+	case blockedBy != "":
+		return &Result{
+			Message:   fmt.Sprintf("waiting for window: blocked by deployment window %s until %s", blockedBy, blockedUntil.Format(time.RFC3339)),
+			NextCheck: blockedUntil,
+		}, nil
+	case hasAllow && !allowOpen && nextOpen.IsZero():
+		return &Result{Message: "waiting for window: no allowed deployment window opens in the future"}, nil
+	case hasAllow && !allowOpen:
+		return &Result{
+			Message:   fmt.Sprintf("waiting for window: outside of the allowed deployment windows, the next one opens at %s", nextOpen.Format(time.RFC3339)),
+			NextCheck: nextOpen,
+		}, nil
+	}
+	return &Result{Open: true}, nil
+}
+
+// RecordOverride records the user who set the deployment window override in the annotation of object, the
+// recorded user is kept if the override isn't changed so that it cannot be forged. oldObj is empty on creation.
+func RecordOverride(obj, oldObj metav1.Object, user string) {
+	annotations := obj.GetAnnotations()
+	override, ok := annotations[oam.AnnotationDeploymentWindowOverride]
+	if !ok {
+		if _, recorded := annotations[oam.AnnotationDeploymentWindowOverrideBy]; recorded {
+			delete(annotations, oam.AnnotationDeploymentWindowOverrideBy)
+			obj.SetAnnotations(annotations)
+		}
+		return
+	}
+	by := user
+	oldAnnotations := oldObj.GetAnnotations()
+	if oldOverride, ok := oldAnnotations[oam.AnnotationDeploymentWindowOverride]; ok && oldOverride == override {
+		by = oldAnnotations[oam.AnnotationDeploymentWindowOverrideBy]
+	}
+	oamutil.AddAnnotations(obj, map[string]string{oam.AnnotationDeploymentWindowOverrideBy: by})
+}
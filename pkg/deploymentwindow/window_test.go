@@ -0,0 +1,246 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deploymentwindow
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
+	"github.com/oam-dev/kubevela/pkg/oam"
+	common2 "github.com/oam-dev/kubevela/pkg/utils/common"
+)
+
+func newDeploymentWindow(name string, apps []string, windows ...v1beta1.TimeWindow) *v1beta1.DeploymentWindow {
+	return &v1beta1.DeploymentWindow{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+		Spec:       v1beta1.DeploymentWindowSpec{Applications: apps, Windows: windows},
+	}
+}
+
+func TestCheck(t *testing.T) {
+	// Friday 23:30 UTC
+	now := time.Date(2021, 6, 4, 23, 30, 0, 0, time.UTC)
+	weekdayNights := v1beta1.TimeWindow{
+		Name:     "weekday-nights",
+		Type:     v1beta1.AllowWindow,
+		Schedule: "0 22 * * mon-fri",
+		Duration: metav1.Duration{Duration: 4 * time.Hour},
+	}
+	weekends := v1beta1.TimeWindow{
+		Type:     v1beta1.AllowWindow,
+		Schedule: "0 0 * * sat",
+		Duration: metav1.Duration{Duration: 48 * time.Hour},
+	}
+	freeze := v1beta1.TimeWindow{
+		Name:     "freeze",
+		Type:     v1beta1.BlockWindow,
+		Schedule: "0 20 4 6 *",
+		Duration: metav1.Duration{Duration: 6 * time.Hour},
+	}
+	morning := v1beta1.TimeWindow{
+		Type:     v1beta1.AllowWindow,
+		Schedule: "0 9 * * *",
+		Duration: metav1.Duration{Duration: time.Hour},
+		TimeZone: "Europe/Berlin",
+	}
+
+	testCases := map[string]struct {
+		windows     []runtime.Object
+		kind        string
+		annotations map[string]string
+		want        *Result
+		wantErr     bool
+	}{
+		"no window": {
+			kind: v1beta1.ApplicationKind,
+			want: &Result{Open: true},
+		},
+		"inside an allow window": {
+			windows: []runtime.Object{newDeploymentWindow("nights", []string{"app"}, weekdayNights)},
+			kind:    v1beta1.ApplicationKind,
+			want:    &Result{Open: true},
+		},
+		"outside of allow windows": {
+			windows: []runtime.Object{newDeploymentWindow("mornings", nil, morning)},
+			kind:    v1beta1.ApplicationKind,
+			want: &Result{
+				Message:   "waiting for window: outside of the allowed deployment windows, the next one opens at 2021-06-05T09:00:00+02:00",
+				NextCheck: time.Date(2021, 6, 5, 7, 0, 0, 0, time.UTC),
+			},
+		},
+		"the earliest allow window opens next": {
+			windows: []runtime.Object{newDeploymentWindow("mornings", nil, morning, weekends)},
+			kind:    v1beta1.ApplicationKind,
+			want: &Result{
+				Message:   "waiting for window: outside of the allowed deployment windows, the next one opens at 2021-06-05T00:00:00Z",
+				NextCheck: time.Date(2021, 6, 5, 0, 0, 0, 0, time.UTC),
+			},
+		},
+		"block window takes precedence": {
+			windows: []runtime.Object{
+				newDeploymentWindow("nights", nil, weekdayNights),
+				newDeploymentWindow("release", nil, freeze),
+			},
+			kind: v1beta1.AppRolloutKind,
+			want: &Result{
+				Message:   "waiting for window: blocked by deployment window release/freeze until 2021-06-05T02:00:00Z",
+				NextCheck: time.Date(2021, 6, 5, 2, 0, 0, 0, time.UTC),
+			},
+		},
+		"windows of other applications": {
+			windows: []runtime.Object{newDeploymentWindow("release", []string{"other"}, freeze)},
+			kind:    v1beta1.ApplicationKind,
+			want:    &Result{Open: true},
+		},
+		"windows of applications don't hold AppRollouts": {
+			windows: []runtime.Object{newDeploymentWindow("release", []string{"app"}, freeze)},
+			kind:    v1beta1.AppRolloutKind,
+			want:    &Result{Open: true},
+		},
+		"overridden": {
+			windows: []runtime.Object{newDeploymentWindow("release", nil, freeze)},
+			kind:    v1beta1.ApplicationKind,
+			annotations: map[string]string{
+				oam.AnnotationDeploymentWindowOverride:   "2021-06-05T00:00:00Z",
+				oam.AnnotationDeploymentWindowOverrideBy: "alice",
+			},
+			want: &Result{
+				Open:          true,
+				Overridden:    true,
+				OverriddenBy:  "alice",
+				OverrideUntil: time.Date(2021, 6, 5, 0, 0, 0, 0, time.UTC),
+			},
+		},
+		"override expired": {
+			windows:     []runtime.Object{newDeploymentWindow("release", nil, freeze)},
+			kind:        v1beta1.ApplicationKind,
+			annotations: map[string]string{oam.AnnotationDeploymentWindowOverride: "2021-06-04T23:00:00Z"},
+			want: &Result{
+				Message:   "waiting for window: blocked by deployment window release/freeze until 2021-06-05T02:00:00Z",
+				NextCheck: time.Date(2021, 6, 5, 2, 0, 0, 0, time.UTC),
+			},
+		},
+		"invalid override": {
+			windows:     []runtime.Object{newDeploymentWindow("release", nil, freeze)},
+			kind:        v1beta1.ApplicationKind,
+			annotations: map[string]string{oam.AnnotationDeploymentWindowOverride: "tomorrow"},
+			want: &Result{
+				Message: "waiting for window: blocked by deployment window release/freeze until 2021-06-05T02:00:00Z, " +
+					"the override \"tomorrow\" is ignored as it's not in RFC3339 format",
+				NextCheck: time.Date(2021, 6, 5, 2, 0, 0, 0, time.UTC),
+			},
+		},
+		"invalid schedule": {
+			windows: []runtime.Object{newDeploymentWindow("invalid", nil, v1beta1.TimeWindow{
+				Schedule: "0 25 * * *",
+				Duration: metav1.Duration{Duration: time.Hour},
+			})},
+			kind:    v1beta1.ApplicationKind,
+			wantErr: true,
+		},
+		"invalid time zone": {
+			windows: []runtime.Object{newDeploymentWindow("invalid", nil, v1beta1.TimeWindow{
+				Schedule: "0 1 * * *",
+				Duration: metav1.Duration{Duration: time.Hour},
+				TimeZone: "Mars/Olympus",
+			})},
+			kind:    v1beta1.ApplicationKind,
+			wantErr: true,
+		},
+	}
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			c := fake.NewFakeClientWithScheme(common2.Scheme, tc.windows...)
+			obj := &metav1.ObjectMeta{Name: "app", Namespace: "default", Annotations: tc.annotations}
+			res, err := Check(context.Background(), c, tc.kind, obj, now)
+			if tc.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.True(t, tc.want.NextCheck.Equal(res.NextCheck), "got next check %s", res.NextCheck)
+			assert.True(t, tc.want.OverrideUntil.Equal(res.OverrideUntil), "got override until %s", res.OverrideUntil)
+			tc.want.NextCheck, res.NextCheck = time.Time{}, time.Time{}
+			tc.want.OverrideUntil, res.OverrideUntil = time.Time{}, time.Time{}
+			assert.Equal(t, tc.want, res)
+		})
+	}
+}
+
+func TestRecordOverride(t *testing.T) {
+	override := map[string]string{oam.AnnotationDeploymentWindowOverride: "2021-06-05T00:00:00Z"}
+	testCases := map[string]struct {
+		annotations    map[string]string
+		oldAnnotations map[string]string
+		want           map[string]string
+	}{
+		"set on creation": {
+			annotations: map[string]string{oam.AnnotationDeploymentWindowOverride: "2021-06-05T00:00:00Z"},
+			want: map[string]string{
+				oam.AnnotationDeploymentWindowOverride:   "2021-06-05T00:00:00Z",
+				oam.AnnotationDeploymentWindowOverrideBy: "alice",
+			},
+		},
+		"changed": {
+			annotations: map[string]string{oam.AnnotationDeploymentWindowOverride: "2021-06-06T00:00:00Z"},
+			oldAnnotations: map[string]string{
+				oam.AnnotationDeploymentWindowOverride:   "2021-06-05T00:00:00Z",
+				oam.AnnotationDeploymentWindowOverrideBy: "bob",
+			},
+			want: map[string]string{
+				oam.AnnotationDeploymentWindowOverride:   "2021-06-06T00:00:00Z",
+				oam.AnnotationDeploymentWindowOverrideBy: "alice",
+			},
+		},
+		"forged user is reverted": {
+			annotations: map[string]string{
+				oam.AnnotationDeploymentWindowOverride:   "2021-06-05T00:00:00Z",
+				oam.AnnotationDeploymentWindowOverrideBy: "carol",
+			},
+			oldAnnotations: map[string]string{
+				oam.AnnotationDeploymentWindowOverride:   "2021-06-05T00:00:00Z",
+				oam.AnnotationDeploymentWindowOverrideBy: "bob",
+			},
+			want: map[string]string{
+				oam.AnnotationDeploymentWindowOverride:   "2021-06-05T00:00:00Z",
+				oam.AnnotationDeploymentWindowOverrideBy: "bob",
+			},
+		},
+		"removed": {
+			annotations:    map[string]string{oam.AnnotationDeploymentWindowOverrideBy: "bob"},
+			oldAnnotations: map[string]string{oam.AnnotationDeploymentWindowOverrideBy: "bob"},
+			want:           map[string]string{},
+		},
+		"no override": {
+			oldAnnotations: override,
+		},
+	}
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			obj := &metav1.ObjectMeta{Annotations: tc.annotations}
+			RecordOverride(obj, &metav1.ObjectMeta{Annotations: tc.oldAnnotations}, "alice")
+			assert.Equal(t, tc.want, obj.Annotations)
+		})
+	}
+}
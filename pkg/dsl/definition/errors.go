@@ -0,0 +1,110 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package definition
+
+import (
+	"fmt"
+	"strings"
+
+	"cuelang.org/go/cue"
+	cueerrors "cuelang.org/go/cue/errors"
+
+	mycue "github.com/oam-dev/kubevela/pkg/cue"
+)
+
+// ParameterError is a failure of a field in the parameter of a capability template
+type ParameterError struct {
+	// Path of the field in parameter, e.g. ["env", "0", "name"]
+	Path []string
+	// Message is the reason of the failure reported by CUE
+	Message string
+	// Required indicates the field is required but not given
+	Required bool
+}
+
+// ParameterErrors is returned if the parameter given to a capability template breaks the constraints of the template
+type ParameterErrors struct {
+	Errs  []ParameterError
+	cause error
+}
+
+// Error joins failures of the parameter
+func (e *ParameterErrors) Error() string {
+	messages := make([]string, 0, len(e.Errs))
+	for _, pe := range e.Errs {
+		path := strings.Join(append([]string{mycue.ParameterTag}, pe.Path...), ".")
+		if pe.Required {
+			messages = append(messages, fmt.Sprintf("%s: required value", path))
+			continue
+		}
+		messages = append(messages, fmt.Sprintf("%s: %s", path, pe.Message))
+	}
+	return strings.Join(messages, "; ")
+}
+
+// Unwrap returns the original CUE error
+func (e *ParameterErrors) Unwrap() error {
+	return e.cause
+}
+
+// parameterErrors picks failures on parameter out of a CUE error, err is returned as it is if the failures are not
+// caused by parameter
+func parameterErrors(err error) error {
+	var errs []ParameterError
+	index := map[string]int{}
+	for _, e := range cueerrors.Errors(err) {
+		path := e.Path()
+		if len(path) < 2 || path[0] != mycue.ParameterTag {
+			continue
+		}
+		format, args := e.Msg()
+		message := fmt.Sprintf(format, args...)
+		key := strings.Join(path, ".")
+		if i, ok := index[key]; ok {
+			// constraints of a field may fail in several ways, e.g. each disjunction of a field with default value
+			if !strings.Contains(errs[i].Message, message) {
+				errs[i].Message = strings.Join([]string{errs[i].Message, message}, "; ")
+			}
+			continue
+		}
+		index[key] = len(errs)
+		errs = append(errs, ParameterError{Path: path[1:], Message: message})
+	}
+	if len(errs) == 0 {
+		return err
+	}
+	return &ParameterErrors{Errs: errs, cause: err}
+}
+
+// incompleteParameters checks required fields of parameter are given, a required field without value fails
+// the output referencing it rather than the parameter itself
+func incompleteParameters(inst *cue.Instance) error {
+	err := inst.Lookup(mycue.ParameterTag).Validate(cue.Concrete(true))
+	if err == nil {
+		return nil
+	}
+	perr, ok := parameterErrors(err).(*ParameterErrors)
+	if !ok {
+		return nil
+	}
+	for i := range perr.Errs {
+		if strings.HasPrefix(perr.Errs[i].Message, "incomplete value") {
+			perr.Errs[i].Required = true
+		}
+	}
+	return perr
+}
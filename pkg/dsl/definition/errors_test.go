@@ -0,0 +1,110 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package definition
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/oam-dev/kubevela/pkg/dsl/process"
+)
+
+func TestParameterErrors(t *testing.T) {
+	workloadTemplate := `
+output: {
+	apiVersion: "apps/v1"
+	kind:       "Deployment"
+	spec: {
+		replicas: parameter.replicas
+		template: spec: containers: [{
+			image: parameter.image
+			if parameter["env"] != _|_ {
+				env: parameter.env
+			}
+		}]
+	}
+}
+parameter: {
+	image:     string
+	replicas: *1 | int & >0
+	env?: [...{name: string, value?: string}]
+}
+`
+	traitTemplate := `
+outputs: service: {
+	apiVersion: "v1"
+	kind:       "Service"
+	spec: ports: [{port: parameter.port}]
+}
+parameter: {
+	port: int
+}
+`
+	testCases := map[string]struct {
+		params   map[string]interface{}
+		expected []ParameterError
+	}{
+		"valid parameter": {
+			params: map[string]interface{}{"image": "nginx"},
+		},
+		"constraint and type failures": {
+			params: map[string]interface{}{"image": 1, "replicas": -1},
+			expected: []ParameterError{
+				{Path: []string{"image"}, Message: "conflicting values string and 1 (mismatched types string and int)"},
+				{Path: []string{"replicas"}, Message: "empty disjunction: conflicting values 1 and -1; " +
+					"empty disjunction: invalid value -1 (out of bound int & >0)"},
+			},
+		},
+		"failure in list": {
+			params: map[string]interface{}{"image": "nginx", "env": []interface{}{map[string]interface{}{"name": 1}}},
+			expected: []ParameterError{
+				{Path: []string{"env", "0", "name"}, Message: "conflicting values string and 1 (mismatched types string and int)"},
+			},
+		},
+		"required field": {
+			params: map[string]interface{}{"replicas": 2},
+			expected: []ParameterError{
+				{Path: []string{"image"}, Message: "incomplete value (string)", Required: true},
+			},
+		},
+	}
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			ctx := process.NewContext("default", "test", "myapp", "myapp-v1")
+			err := NewWorkloadAbstractEngine("test", &PackageDiscover{}).Complete(ctx, workloadTemplate, tc.params)
+			if tc.expected == nil {
+				assert.NoError(t, err)
+				return
+			}
+			var perr *ParameterErrors
+			assert.True(t, errors.As(err, &perr), err)
+			assert.Equal(t, tc.expected, perr.Errs)
+		})
+	}
+
+	t.Run("required field of trait", func(t *testing.T) {
+		ctx := process.NewContext("default", "test", "myapp", "myapp-v1")
+		assert.NoError(t, NewWorkloadAbstractEngine("test", &PackageDiscover{}).Complete(ctx, workloadTemplate,
+			map[string]interface{}{"image": "nginx"}))
+		err := NewTraitAbstractEngine("expose", &PackageDiscover{}).Complete(ctx, traitTemplate, nil)
+		var perr *ParameterErrors
+		assert.True(t, errors.As(err, &perr), err)
+		assert.Equal(t, "parameter.port: required value", perr.Error())
+	})
+}
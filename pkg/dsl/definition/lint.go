@@ -0,0 +1,517 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package definition
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"cuelang.org/go/cue"
+	"cuelang.org/go/cue/ast"
+	"cuelang.org/go/cue/build"
+	cueerrors "cuelang.org/go/cue/errors"
+	"cuelang.org/go/cue/format"
+	"cuelang.org/go/cue/parser"
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/version"
+
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
+	mycue "github.com/oam-dev/kubevela/pkg/cue"
+	"github.com/oam-dev/kubevela/pkg/dsl/process"
+)
+
+const (
+	// LintUnusedParameter reports the parameters which are never referenced by the template
+	LintUnusedParameter = "unused-parameter"
+	// LintMissingUsage reports the parameters without a `// +usage=` comment, which is shown by `vela show`
+	LintMissingUsage = "missing-usage"
+	// LintUnstableOutputName reports the outputs whose names are generated, they may conflict with the outputs of
+	// other components and traits, which is found only when an application is rendered
+	LintUnstableOutputName = "unstable-output-name"
+	// LintInvalidOutput reports the fields of outputs which don't exist on or conflict with the schema of their kinds
+	LintInvalidOutput = "invalid-output"
+	// LintInvalidPatch reports the fields of the patch which don't exist on or conflict with the schema of workloads
+	LintInvalidPatch = "invalid-patch"
+)
+
+// lintFieldPrefix is the prefix of the fields added to the template to check its values against the K8s schemas
+const lintFieldPrefix = "lint__"
+
+// LintIssue is a mistake found in the CUE template of a definition
+type LintIssue struct {
+	Rule string `json:"rule"`
+	// Path is the path of the field in the template, e.g. parameter.image
+	Path    string `json:"path"`
+	Message string `json:"message"`
+}
+
+// String formats the issue as `path: message (rule)`
+func (i LintIssue) String() string {
+	return fmt.Sprintf("%s: %s (%s)", i.Path, i.Message, i.Rule)
+}
+
+// LintTemplate checks the CUE template of a definition for the mistakes which are otherwise found only when an
+// application fails to render. The outputs are checked against the K8s schemas in the packages, and so is the patch
+// against the schemas of patchTargets, which are the kinds of workloads a trait applies to. The schemas are not
+// checked if pd is nil. An error is returned only if the template can't be parsed or built.
+func LintTemplate(template string, pd *PackageDiscover, patchTargets []metav1.GroupVersionKind) ([]LintIssue, error) {
+	f, err := parser.ParseFile("-", template, parser.ParseComments)
+	if err != nil {
+		return nil, errors.WithMessage(err, "invalid cue template")
+	}
+	issues := lintParameters(f)
+	issues = append(issues, lintOutputNames(f)...)
+	if pd == nil {
+		return issues, nil
+	}
+	schemaIssues, err := lintSchemas(f, template, pd, patchTargets)
+	if err != nil {
+		return nil, err
+	}
+	return append(issues, schemaIssues...), nil
+}
+
+// lintParameters reports the parameters which are never referenced or have no usage
+func lintParameters(f *ast.File) []LintIssue {
+	params := topLevelFields(f.Decls, mycue.ParameterTag)
+	var issues []LintIssue
+	used, usedAll := referencedParameters(f)
+	for _, param := range params {
+		st, ok := param.Value.(*ast.StructLit)
+		if !ok {
+			continue
+		}
+		for _, elt := range st.Elts {
+			field, ok := elt.(*ast.Field)
+			if !ok {
+				continue
+			}
+			name, _, err := ast.LabelName(field.Label)
+			if err != nil || strings.HasPrefix(name, "#") || strings.HasPrefix(name, "_") {
+				continue
+			}
+			path := mycue.ParameterTag + "." + name
+			if !usedAll && !used[name] {
+				issues = append(issues, LintIssue{Rule: LintUnusedParameter, Path: path,
+					Message: "parameter is declared but never used"})
+			}
+			if !hasUsage(field) {
+				issues = append(issues, LintIssue{Rule: LintMissingUsage, Path: path,
+					Message: fmt.Sprintf("parameter has no %q comment, its usage can't be shown by vela show", "// "+mycue.UsagePrefix)})
+			}
+		}
+	}
+	return issues
+}
+
+// referencedParameters finds the names of parameters referenced like `parameter.name` or `parameter["name"]`,
+// all parameters are referenced if `parameter` is used as a whole
+func referencedParameters(f *ast.File) (map[string]bool, bool) {
+	used := map[string]bool{}
+	usedAll := false
+	var before func(ast.Node) bool
+	before = func(n ast.Node) bool {
+		switch x := n.(type) {
+		case *ast.Field:
+			// labels are not references unless they are interpolated
+			if _, ok := x.Label.(*ast.Interpolation); ok {
+				ast.Walk(x.Label, before, nil)
+			}
+			ast.Walk(x.Value, before, nil)
+			return false
+		case *ast.SelectorExpr:
+			if isParameterIdent(x.X) {
+				if name, _, err := ast.LabelName(x.Sel); err == nil {
+					used[name] = true
+				}
+				return false
+			}
+		case *ast.IndexExpr:
+			if isParameterIdent(x.X) {
+				if lit, ok := x.Index.(*ast.BasicLit); ok {
+					if name, err := strconv.Unquote(lit.Value); err == nil {
+						used[name] = true
+						return false
+					}
+				}
+				usedAll = true
+				return false
+			}
+		case *ast.Ident:
+			if x.Name == mycue.ParameterTag {
+				usedAll = true
+			}
+		}
+		return true
+	}
+	ast.Walk(f, before, nil)
+	return used, usedAll
+}
+
+func isParameterIdent(x ast.Expr) bool {
+	ident, ok := x.(*ast.Ident)
+	return ok && ident.Name == mycue.ParameterTag
+}
+
+func hasUsage(field *ast.Field) bool {
+	for _, cg := range ast.Comments(field) {
+		for _, c := range cg.List {
+			if strings.Contains(c.Text, mycue.UsagePrefix) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// lintOutputNames reports the outputs whose names are interpolated or generated by comprehensions
+func lintOutputNames(f *ast.File) []LintIssue {
+	var issues []LintIssue
+	for _, outputs := range topLevelFields(f.Decls, OutputsFieldName) {
+		st, ok := outputs.Value.(*ast.StructLit)
+		if !ok {
+			continue
+		}
+		for _, elt := range st.Elts {
+			switch x := elt.(type) {
+			case *ast.Field:
+				if _, _, err := ast.LabelName(x.Label); err != nil {
+					issues = append(issues, LintIssue{Rule: LintUnstableOutputName, Path: OutputsFieldName + "." + nodeString(x.Label),
+						Message: "the name of output is generated, use a fixed name to make sure it's unique in the application"})
+				}
+			case *ast.Comprehension:
+				issues = append(issues, LintIssue{Rule: LintUnstableOutputName, Path: OutputsFieldName,
+					Message: "outputs are generated by a comprehension, use fixed names to make sure they are unique in the application"})
+			}
+		}
+	}
+	return issues
+}
+
+// topLevelFields finds the top-level fields with the name, including the ones in conditional blocks
+func topLevelFields(decls []ast.Decl, name string) []*ast.Field {
+	var fields []*ast.Field
+	for _, decl := range decls {
+		switch x := decl.(type) {
+		case *ast.Field:
+			if label, _, err := ast.LabelName(x.Label); err == nil && label == name {
+				fields = append(fields, x)
+			}
+		case *ast.Comprehension:
+			if st, ok := x.Value.(*ast.StructLit); ok {
+				fields = append(fields, topLevelFields(st.Elts, name)...)
+			}
+		case *ast.EmbedDecl:
+			if st, ok := x.Expr.(*ast.StructLit); ok {
+				fields = append(fields, topLevelFields(st.Elts, name)...)
+			}
+		}
+	}
+	return fields
+}
+
+// schemaCheck checks a value of the template against the schema of a K8s kind
+type schemaCheck struct {
+	rule string
+	// path is the path of the value in the template
+	path []string
+	gvk  metav1.GroupVersionKind
+}
+
+// lintSchemas checks the outputs and the patch against the K8s schemas, the template is built with fields referring
+// to the schemas and the values unified with the schemas
+func lintSchemas(f *ast.File, template string, pd *PackageDiscover, patchTargets []metav1.GroupVersionKind) ([]LintIssue, error) {
+	var checks []schemaCheck
+	for _, output := range topLevelFields(f.Decls, OutputFieldName) {
+		if gvk, ok := literalKind(output.Value); ok {
+			checks = append(checks, schemaCheck{rule: LintInvalidOutput, path: []string{OutputFieldName}, gvk: gvk})
+		}
+	}
+	for _, outputs := range topLevelFields(f.Decls, OutputsFieldName) {
+		st, ok := outputs.Value.(*ast.StructLit)
+		if !ok {
+			continue
+		}
+		for _, elt := range st.Elts {
+			field, ok := elt.(*ast.Field)
+			if !ok {
+				continue
+			}
+			name, _, err := ast.LabelName(field.Label)
+			if err != nil {
+				continue
+			}
+			if gvk, ok := literalKind(field.Value); ok {
+				checks = append(checks, schemaCheck{rule: LintInvalidOutput, path: []string{OutputsFieldName, name}, gvk: gvk})
+			}
+		}
+	}
+	if len(topLevelFields(f.Decls, PatchFieldName)) > 0 {
+		for _, gvk := range patchTargets {
+			checks = append(checks, schemaCheck{rule: LintInvalidPatch, path: []string{PatchFieldName}, gvk: gvk})
+		}
+	}
+
+	var imports, fields []string
+	var valid []schemaCheck
+	roots := map[string]bool{}
+	for _, check := range checks {
+		if !pd.Exist(check.gvk) {
+			continue
+		}
+		// declare the roots in case they are only declared in conditional blocks
+		if root := check.path[0]; !roots[root] {
+			roots[root] = true
+			fields = append(fields, root+": _")
+		}
+		i := len(valid)
+		valid = append(valid, check)
+		imports = append(imports, fmt.Sprintf("lintpkg%d %q", i, genOpenPkgName(convert2DGVK(check.gvk))))
+		schemaRef := fmt.Sprintf("lintpkg%d.#%s", i, check.gvk.Kind)
+		fields = append(fields, fmt.Sprintf("%s%d: %s", lintFieldPrefix, i, schemaRef),
+			fmt.Sprintf("%s%d_unified: %s & %s", lintFieldPrefix, i, schemaRef, cuePath(check.path)))
+	}
+	if len(valid) == 0 {
+		return nil, nil
+	}
+
+	bi := build.NewContext().NewInstance("", nil)
+	if err := bi.AddFile("-", template); err != nil {
+		return nil, errors.WithMessage(err, "invalid cue template")
+	}
+	if err := bi.AddFile("context", process.NewContext("default", "lint", "lint", "lint-v1").ExtendedContextFile()); err != nil {
+		return nil, err
+	}
+	lintFile := fmt.Sprintf("import (\n%s\n)\n%s\n", strings.Join(imports, "\n"), strings.Join(fields, "\n"))
+	if err := bi.AddFile("lint", lintFile); err != nil {
+		return nil, err
+	}
+	inst, err := pd.ImportPackagesAndBuildInstance(bi)
+	if err != nil {
+		return nil, errors.WithMessage(err, "invalid cue template")
+	}
+
+	var issues []LintIssue
+	for i, check := range valid {
+		kind := check.gvk.Kind
+		schemaValue := inst.Lookup(fmt.Sprintf("%s%d", lintFieldPrefix, i))
+		for _, path := range unknownFields(inst.Lookup(check.path...), schemaValue, check.path) {
+			issues = append(issues, LintIssue{Rule: check.rule, Path: path,
+				Message: fmt.Sprintf("field doesn't exist in %s %s", apiVersion(check.gvk), kind)})
+		}
+		unified := inst.Lookup(fmt.Sprintf("%s%d_unified", lintFieldPrefix, i))
+		for _, e := range cueerrors.Errors(unified.Validate()) {
+			path := append(append([]string{}, check.path...), e.Path()...)
+			if len(e.Path()) > 0 && strings.HasPrefix(e.Path()[0], lintFieldPrefix) {
+				path = append(append([]string{}, check.path...), e.Path()[1:]...)
+			}
+			format, args := e.Msg()
+			issues = append(issues, LintIssue{Rule: check.rule, Path: strings.Join(path, "."),
+				Message: fmt.Sprintf("conflicts with %s %s: %s", apiVersion(check.gvk), kind, fmt.Sprintf(format, args...))})
+		}
+	}
+	return issues, nil
+}
+
+// unknownFields finds the fields of the value which don't exist in the schema. Lists are checked by their elements,
+// and the structs without fields in the schema, e.g. the ones preserving unknown fields, are not checked.
+func unknownFields(v, schemaValue cue.Value, path []string) []string {
+	var unknown []string
+	// nolint:exhaustive
+	switch v.IncompleteKind() {
+	case cue.StructKind:
+		if schemaValue.IncompleteKind()&cue.StructKind == 0 {
+			return nil
+		}
+		known := map[string]cue.Value{}
+		it, err := schemaValue.Fields(cue.Optional(true))
+		if err != nil {
+			return nil
+		}
+		for it.Next() {
+			known[it.Label()] = it.Value()
+		}
+		tmpl := schemaValue.Template()
+		if len(known) == 0 && tmpl == nil {
+			return nil
+		}
+		fields, err := v.Fields(cue.Optional(true))
+		if err != nil {
+			return nil
+		}
+		for fields.Next() {
+			label := fields.Label()
+			sub, ok := known[label]
+			if !ok {
+				if tmpl == nil {
+					unknown = append(unknown, strings.Join(append(path, label), "."))
+					continue
+				}
+				sub = tmpl(label)
+			}
+			unknown = append(unknown, unknownFields(fields.Value(), sub, append(path[:len(path):len(path)], label))...)
+		}
+	case cue.ListKind:
+		elem, ok := schemaValue.Elem()
+		if !ok {
+			return nil
+		}
+		list, err := v.List()
+		if err != nil {
+			return nil
+		}
+		for i := 0; list.Next(); i++ {
+			unknown = append(unknown, unknownFields(list.Value(), elem, append(path[:len(path):len(path)], strconv.Itoa(i)))...)
+		}
+	}
+	return unknown
+}
+
+// literalKind gets the GVK of a resource in the template if its apiVersion and kind are string literals
+func literalKind(x ast.Expr) (metav1.GroupVersionKind, bool) {
+	st, ok := x.(*ast.StructLit)
+	if !ok {
+		return metav1.GroupVersionKind{}, false
+	}
+	values := map[string]string{}
+	for _, elt := range st.Elts {
+		field, ok := elt.(*ast.Field)
+		if !ok {
+			continue
+		}
+		name, _, err := ast.LabelName(field.Label)
+		if err != nil {
+			continue
+		}
+		if lit, ok := field.Value.(*ast.BasicLit); ok {
+			if s, err := strconv.Unquote(lit.Value); err == nil {
+				values[name] = s
+			}
+		}
+	}
+	if values["apiVersion"] == "" || values["kind"] == "" {
+		return metav1.GroupVersionKind{}, false
+	}
+	gvk := schema.FromAPIVersionAndKind(values["apiVersion"], values["kind"])
+	return metav1.GroupVersionKind{Group: gvk.Group, Version: gvk.Version, Kind: gvk.Kind}, true
+}
+
+// cuePath refers to a field of the template by its path
+func cuePath(path []string) string {
+	ref := path[0]
+	for _, label := range path[1:] {
+		if ast.IsValidIdent(label) {
+			ref += "." + label
+		} else {
+			ref += "[" + strconv.Quote(label) + "]"
+		}
+	}
+	return ref
+}
+
+func apiVersion(gvk metav1.GroupVersionKind) string {
+	return schema.GroupVersion{Group: gvk.Group, Version: gvk.Version}.String()
+}
+
+func nodeString(n ast.Node) string {
+	b, err := format.Node(n)
+	if err != nil {
+		return fmt.Sprint(n)
+	}
+	return string(b)
+}
+
+// WorkloadKinds resolves the kinds of the workloads which a trait applies to, its patch is checked against them.
+// A workload is either a resource with its group like `deployments.apps`, which is resolved by the kinds in the
+// packages with the preferred version, or a component type resolved by componentKinds. Wildcards are ignored.
+func (pd *PackageDiscover) WorkloadKinds(appliesToWorkloads []string, componentKinds map[string]metav1.GroupVersionKind) []metav1.GroupVersionKind {
+	var kinds []metav1.GroupVersionKind
+	seen := map[metav1.GroupVersionKind]bool{}
+	add := func(gvk metav1.GroupVersionKind) {
+		if !seen[gvk] {
+			seen[gvk] = true
+			kinds = append(kinds, gvk)
+		}
+	}
+	for _, workload := range appliesToWorkloads {
+		if strings.HasPrefix(workload, "*") {
+			continue
+		}
+		if gvk, ok := componentKinds[workload]; ok {
+			add(gvk)
+			continue
+		}
+		if gvk, ok := pd.resourceKind(schema.ParseGroupResource(workload)); ok {
+			add(gvk)
+		}
+	}
+	return kinds
+}
+
+// resourceKind finds the kind of a resource in the packages, the resource is assumed to be the plural of the kind
+func (pd *PackageDiscover) resourceKind(gr schema.GroupResource) (metav1.GroupVersionKind, bool) {
+	var candidates []metav1.GroupVersionKind
+	for importPath, vks := range pd.ListPackageKinds() {
+		if !strings.HasPrefix(importPath, BuiltinPackageDomain+"/") {
+			continue
+		}
+		for _, vk := range vks {
+			gv, err := schema.ParseGroupVersion(vk.APIVersion)
+			if err != nil || gv.Group != gr.Group || pluralize(vk.Kind) != gr.Resource {
+				continue
+			}
+			candidates = append(candidates, metav1.GroupVersionKind{Group: gv.Group, Version: gv.Version, Kind: vk.Kind})
+		}
+	}
+	if len(candidates) == 0 {
+		return metav1.GroupVersionKind{}, false
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		return version.CompareKubeAwareVersionStrings(candidates[i].Version, candidates[j].Version) > 0
+	})
+	return candidates[0], true
+}
+
+func pluralize(kind string) string {
+	kind = strings.ToLower(kind)
+	switch {
+	case strings.HasSuffix(kind, "s"), strings.HasSuffix(kind, "x"), strings.HasSuffix(kind, "ch"):
+		return kind + "es"
+	case strings.HasSuffix(kind, "y") && !strings.HasSuffix(kind, "ay") && !strings.HasSuffix(kind, "ey"):
+		return strings.TrimSuffix(kind, "y") + "ies"
+	default:
+		return kind + "s"
+	}
+}
+
+// ComponentWorkloadKinds maps the types of components to the kinds of their workloads
+func ComponentWorkloadKinds(cds []v1beta1.ComponentDefinition) map[string]metav1.GroupVersionKind {
+	kinds := map[string]metav1.GroupVersionKind{}
+	for _, cd := range cds {
+		def := cd.Spec.Workload.Definition
+		if def.APIVersion == "" || def.Kind == "" {
+			continue
+		}
+		gvk := schema.FromAPIVersionAndKind(def.APIVersion, def.Kind)
+		kinds[cd.Name] = metav1.GroupVersionKind{Group: gvk.Group, Version: gvk.Version, Kind: gvk.Kind}
+	}
+	return kinds
+}
@@ -0,0 +1,201 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package definition
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"gotest.tools/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/common"
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
+)
+
+func TestLintTemplate(t *testing.T) {
+	schema, err := BundledOpenAPISchema()
+	assert.NilError(t, err)
+	pd, err := NewPackageDiscoverFromOpenAPI(schema)
+	assert.NilError(t, err)
+	deployment := metav1.GroupVersionKind{Group: "apps", Version: "v1", Kind: "Deployment"}
+
+	testCases := map[string]struct {
+		template string
+		pd       *PackageDiscover
+		targets  []metav1.GroupVersionKind
+		issues   []LintIssue
+	}{
+		"valid template": {
+			template: `
+output: {
+	apiVersion: "apps/v1"
+	kind:       "Deployment"
+	spec: template: spec: containers: [{name: context.name, image: parameter.image}]
+}
+outputs: service: {
+	apiVersion: "v1"
+	kind:       "Service"
+	spec: ports: [{port: parameter.port}]
+}
+parameter: {
+	// +usage=Which image would you like to use for your service
+	image: string
+	// +usage=Which port do you want customer traffic sent to
+	port: *80 | int
+}
+`,
+			pd: pd,
+		},
+		"unused parameters and missing usages": {
+			template: `
+output: {
+	metadata: labels: parameter["labels"]
+	spec: image: parameter.image.name
+}
+parameter: {
+	// +usage=The image
+	image: name: string
+	labels: [string]: string
+	// +usage=Never used
+	debug?: bool
+	_hidden: string
+}
+`,
+			issues: []LintIssue{
+				{Rule: LintMissingUsage, Path: "parameter.labels", Message: `parameter has no "// +usage=" comment, its usage can't be shown by vela show`},
+				{Rule: LintUnusedParameter, Path: "parameter.debug", Message: "parameter is declared but never used"},
+			},
+		},
+		"parameter used as a whole": {
+			template: `
+output: spec: parameter
+parameter: {
+	// +usage=The replicas
+	replicas: int
+}
+`,
+		},
+		"generated output names": {
+			template: `
+outputs: {
+	"\(parameter.name)-svc": {}
+	for k, v in parameter.ports {
+		"\(k)": {}
+	}
+}
+parameter: {
+	// +usage=The name
+	name: string
+	// +usage=The ports
+	ports: [string]: int
+}
+`,
+			issues: []LintIssue{
+				{Rule: LintUnstableOutputName, Path: `outputs."\(parameter.name)-svc"`, Message: "the name of output is generated, use a fixed name to make sure it's unique in the application"},
+				{Rule: LintUnstableOutputName, Path: "outputs", Message: "outputs are generated by a comprehension, use fixed names to make sure they are unique in the application"},
+			},
+		},
+		"invalid outputs": {
+			template: `
+if parameter.expose {
+	outputs: "my-service": {
+		apiVersion: "v1"
+		kind:       "Service"
+		spec: {
+			ports: [{port: 80, protocl: "TCP"}]
+			type: 80
+		}
+	}
+}
+parameter: {
+	// +usage=Expose the service
+	expose: *true | bool
+}
+`,
+			pd: pd,
+			issues: []LintIssue{
+				{Rule: LintInvalidOutput, Path: "outputs.my-service.spec.ports.0.protocl", Message: "field doesn't exist in v1 Service"},
+				{Rule: LintInvalidOutput, Path: "outputs.my-service.spec.type", Message: `conflicts with v1 Service: conflicting values string and 80 (mismatched types string and int)`},
+			},
+		},
+		"invalid patch": {
+			template: `
+patch: {
+	spec: replica: parameter.replicas
+	// +patchKey=name
+	spec: template: spec: containers: [{name: context.name, image: "nginx"}]
+	spec: template: metadata: labels: app: context.name
+}
+parameter: {
+	// +usage=The replicas
+	replicas: int
+}
+`,
+			pd:      pd,
+			targets: []metav1.GroupVersionKind{deployment, {Group: "example.com", Version: "v1", Kind: "Unknown"}},
+			issues: []LintIssue{
+				{Rule: LintInvalidPatch, Path: "patch.spec.replica", Message: "field doesn't exist in apps/v1 Deployment"},
+			},
+		},
+		"schemas are not checked without packages": {
+			template: `
+patch: spec: replica: 1
+`,
+			targets: []metav1.GroupVersionKind{deployment},
+		},
+	}
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			issues, err := LintTemplate(tc.template, tc.pd, tc.targets)
+			assert.NilError(t, err)
+			if diff := cmp.Diff(tc.issues, issues); diff != "" {
+				t.Errorf("-want +got:\n%s", diff)
+			}
+		})
+	}
+
+	_, err = LintTemplate("output: {", pd, nil)
+	assert.ErrorContains(t, err, "invalid cue template")
+}
+
+func TestWorkloadKinds(t *testing.T) {
+	schema, err := BundledOpenAPISchema()
+	assert.NilError(t, err)
+	pd, err := NewPackageDiscoverFromOpenAPI(schema)
+	assert.NilError(t, err)
+
+	componentKinds := ComponentWorkloadKinds([]v1beta1.ComponentDefinition{
+		{ObjectMeta: metav1.ObjectMeta{Name: "worker"}, Spec: v1beta1.ComponentDefinitionSpec{
+			Workload: common.WorkloadTypeDescriptor{Definition: common.WorkloadGVK{APIVersion: "apps/v1", Kind: "Deployment"}}}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "raw"}, Spec: v1beta1.ComponentDefinitionSpec{
+			Workload: common.WorkloadTypeDescriptor{Type: "autodetects.core.oam.dev"}}},
+	})
+	assert.DeepEqual(t, map[string]metav1.GroupVersionKind{
+		"worker": {Group: "apps", Version: "v1", Kind: "Deployment"},
+	}, componentKinds)
+
+	kinds := pd.WorkloadKinds([]string{"*", "*.apps", "worker", "deployments.apps", "statefulsets.apps",
+		"services", "ingresses.networking.k8s.io", "cronjobs.batch", "unknowns.example.com"}, componentKinds)
+	assert.DeepEqual(t, []metav1.GroupVersionKind{
+		{Group: "apps", Version: "v1", Kind: "Deployment"},
+		{Group: "apps", Version: "v1", Kind: "StatefulSet"},
+		{Version: "v1", Kind: "Service"},
+		{Group: "networking.k8s.io", Version: "v1beta1", Kind: "Ingress"},
+		{Group: "batch", Version: "v1beta1", Kind: "CronJob"},
+	}, kinds)
+}
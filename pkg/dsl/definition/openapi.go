@@ -0,0 +1,216 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package definition
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"sync"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+)
+
+// NewPackageDiscoverFromOpenAPI creates a PackageDiscover from an OpenAPI v2 schema rather than a cluster,
+// the schema could be a snapshot got by `kubectl get --raw /openapi/v2`. The PackageDiscover can't be refreshed.
+func NewPackageDiscoverFromOpenAPI(apiSchema string) (*PackageDiscover, error) {
+	pd := &PackageDiscover{
+		pkgKinds: make(map[string][]VersionKind),
+	}
+	if err := pd.addKubeCUEPackagesFromCluster(apiSchema); err != nil {
+		return nil, err
+	}
+	return pd, nil
+}
+
+var (
+	bundledOpenAPISchema     string
+	bundledOpenAPISchemaErr  error
+	bundledOpenAPISchemaOnce sync.Once
+)
+
+// BundledOpenAPISchema returns the OpenAPI v2 schema of the built-in K8s resources known by the client-go
+// this binary is built with, it's used to render templates importing kube packages without a cluster.
+// Descriptions and validations are not included, CRDs are never included.
+func BundledOpenAPISchema() (string, error) {
+	bundledOpenAPISchemaOnce.Do(func() {
+		bundledOpenAPISchema, bundledOpenAPISchemaErr = generateOpenAPISchema(clientgoscheme.Scheme)
+	})
+	return bundledOpenAPISchema, bundledOpenAPISchemaErr
+}
+
+var (
+	objectMetaType = reflect.TypeOf(metav1.ObjectMeta{})
+	rawMessageType = reflect.TypeOf(json.RawMessage{})
+	openAPIFormats = map[reflect.Type]map[string]interface{}{
+		reflect.TypeOf(resource.Quantity{}):    {"type": "string"},
+		reflect.TypeOf(intstr.IntOrString{}):   {"type": "string", "format": "int-or-string"},
+		reflect.TypeOf(metav1.Time{}):          {"type": "string", "format": "date-time"},
+		reflect.TypeOf(metav1.MicroTime{}):     {"type": "string", "format": "date-time"},
+		reflect.TypeOf(metav1.Duration{}):      {"type": "string"},
+		reflect.TypeOf(metav1.FieldsV1{}):      {"type": "object"},
+		reflect.TypeOf(runtime.RawExtension{}): {"type": "object"},
+		reflect.TypeOf(runtime.Unknown{}):      {"type": "object"},
+	}
+)
+
+// generateOpenAPISchema generates an OpenAPI v2 schema of the kinds with ObjectMeta registered in the scheme,
+// definitions are named after the Go packages like the K8s API server does, e.g. io.k8s.api.apps.v1.Deployment
+func generateOpenAPISchema(scheme *runtime.Scheme) (string, error) {
+	g := &openAPIGenerator{definitions: map[string]interface{}{}}
+	paths := map[string]interface{}{}
+	for gvk, t := range scheme.AllKnownTypes() {
+		if gvk.Version == runtime.APIVersionInternal || strings.HasSuffix(gvk.Kind, "List") || !hasObjectMeta(t) {
+			continue
+		}
+		name := g.ref(t)
+		// generated definitions of the same type are shared by the kinds registered in several versions,
+		// only the version the type is declared in is kept
+		if !strings.HasSuffix(name, "."+gvk.Version+"."+gvk.Kind) {
+			continue
+		}
+		paths["/apis/"+gvk.GroupVersion().String()+"/"+strings.ToLower(gvk.Kind)] = map[string]interface{}{
+			"post": map[string]interface{}{
+				"x-kubernetes-group-version-kind": map[string]string{
+					"group":   gvk.Group,
+					"version": gvk.Version,
+					"kind":    gvk.Kind,
+				},
+			},
+		}
+	}
+	data, err := json.Marshal(map[string]interface{}{
+		"swagger":     "2.0",
+		"paths":       paths,
+		"definitions": g.definitions,
+	})
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+func hasObjectMeta(t reflect.Type) bool {
+	if t.Kind() != reflect.Struct {
+		return false
+	}
+	for i := 0; i < t.NumField(); i++ {
+		if t.Field(i).Type == objectMetaType {
+			return true
+		}
+	}
+	return false
+}
+
+type openAPIGenerator struct {
+	definitions map[string]interface{}
+}
+
+// ref generates the definition of a struct type and returns its name
+func (g *openAPIGenerator) ref(t reflect.Type) string {
+	name := openAPIDefinitionName(t)
+	if _, ok := g.definitions[name]; ok {
+		return name
+	}
+	if format, ok := openAPIFormats[t]; ok {
+		g.definitions[name] = format
+		return name
+	}
+	// placeholder for recursive types
+	g.definitions[name] = map[string]interface{}{}
+	properties := map[string]interface{}{}
+	g.addProperties(t, properties)
+	g.definitions[name] = map[string]interface{}{
+		"type":       "object",
+		"properties": properties,
+	}
+	return name
+}
+
+func (g *openAPIGenerator) addProperties(t reflect.Type, properties map[string]interface{}) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.PkgPath != "" {
+			continue
+		}
+		tag := strings.Split(f.Tag.Get("json"), ",")
+		name := tag[0]
+		if name == "-" {
+			continue
+		}
+		if f.Anonymous && name == "" {
+			ft := f.Type
+			if ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				g.addProperties(ft, properties)
+				continue
+			}
+		}
+		if name == "" {
+			name = f.Name
+		}
+		properties[name] = g.schema(f.Type)
+	}
+}
+
+func (g *openAPIGenerator) schema(t reflect.Type) map[string]interface{} {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if _, ok := openAPIFormats[t]; ok {
+		return map[string]interface{}{"$ref": "#/definitions/" + g.ref(t)}
+	}
+	switch t.Kind() {
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return map[string]interface{}{"type": "integer", "format": "int32"}
+	case reflect.Int64, reflect.Uint64:
+		return map[string]interface{}{"type": "integer", "format": "int64"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number", "format": "double"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 || t == rawMessageType {
+			return map[string]interface{}{"type": "string", "format": "byte"}
+		}
+		return map[string]interface{}{"type": "array", "items": g.schema(t.Elem())}
+	case reflect.Map:
+		return map[string]interface{}{"type": "object", "additionalProperties": g.schema(t.Elem())}
+	case reflect.Struct:
+		return map[string]interface{}{"$ref": "#/definitions/" + g.ref(t)}
+	default:
+		return map[string]interface{}{"type": "object"}
+	}
+}
+
+// openAPIDefinitionName names a type after its package, k8s.io/api/apps/v1.Deployment is io.k8s.api.apps.v1.Deployment
+func openAPIDefinitionName(t reflect.Type) string {
+	path := strings.Split(t.PkgPath(), "/")
+	domain := strings.Split(path[0], ".")
+	for i, j := 0, len(domain)-1; i < j; i, j = i+1, j-1 {
+		domain[i], domain[j] = domain[j], domain[i]
+	}
+	return strings.Join(append(append(domain, path[1:]...), t.Name()), ".")
+}
@@ -628,3 +628,46 @@ func TestReverseString(t *testing.T) {
 		assert.Equal(t, convert2DGVK(tCase.gvr).reverseString(), tCase.reverseString)
 	}
 }
+
+func TestBundledOpenAPISchema(t *testing.T) {
+	schema, err := BundledOpenAPISchema()
+	assert.NilError(t, err)
+	pd, err := NewPackageDiscoverFromOpenAPI(schema)
+	assert.NilError(t, err)
+	assert.Equal(t, pd.Exist(metav1.GroupVersionKind{Group: "apps", Version: "v1", Kind: "Deployment"}), true)
+	assert.Equal(t, pd.Exist(metav1.GroupVersionKind{Version: "v1", Kind: "Service"}), true)
+	assert.Equal(t, pd.Exist(metav1.GroupVersionKind{Group: "apps", Version: "v1", Kind: "DeploymentList"}), false)
+
+	bi := build.NewContext().NewInstance("", nil)
+	assert.NilError(t, bi.AddFile("-", `
+import (
+	apps "kube/apps/v1"
+	corev1 "k8s.io/core/v1"
+)
+deploy: apps.#Deployment & {
+	spec: template: spec: containers: [{name: "main", image: "nginx", resources: limits: cpu: "500m"}]
+}
+svc: corev1.#Service & {
+	spec: ports: [{port: 80, targetPort: 8080}]
+}
+`))
+	inst, err := pd.ImportPackagesAndBuildInstance(bi)
+	assert.NilError(t, err)
+	kind, err := inst.Lookup("deploy", "kind").String()
+	assert.NilError(t, err)
+	assert.Equal(t, kind, "Deployment")
+	apiVersion, err := inst.Lookup("svc", "apiVersion").String()
+	assert.NilError(t, err)
+	assert.Equal(t, apiVersion, "v1")
+
+	bi = build.NewContext().NewInstance("", nil)
+	assert.NilError(t, bi.AddFile("-", `
+import apps "kube/apps/v1"
+deploy: apps.#Deployment & {spec: replicas: "two"}
+`))
+	inst, err = pd.ImportPackagesAndBuildInstance(bi)
+	if err == nil {
+		err = inst.Value().Validate(cue.Concrete(false))
+	}
+	assert.Assert(t, err != nil)
+}
@@ -110,9 +110,16 @@ func (wd *workloadDef) Complete(ctx process.Context, abstractTemplate string, pa
 	}
 
 	if err := inst.Value().Validate(); err != nil {
-		return errors.WithMessagef(err, "invalid cue template of workload %s after merge parameter and context", wd.name)
+		return errors.WithMessagef(parameterErrors(err), "invalid cue template of workload %s after merge parameter and context", wd.name)
 	}
 	output := inst.Lookup(OutputFieldName)
+	if output.Exists() {
+		if _, err := output.MarshalJSON(); err != nil {
+			if perr := incompleteParameters(inst); perr != nil {
+				return errors.WithMessagef(perr, "invalid output of workload %s", wd.name)
+			}
+		}
+	}
 	base, err := model.NewBase(output)
 	if err != nil {
 		return errors.WithMessagef(err, "invalid output of workload %s", wd.name)
@@ -200,10 +207,11 @@ func (wd *workloadDef) HealthCheck(ctx process.Context, cli client.Client, ns st
 	if err != nil {
 		return false, errors.WithMessage(err, "get template context")
 	}
-	return checkHealth(templateContext, healthPolicyTemplate)
+	return CheckHealth(templateContext, healthPolicyTemplate)
 }
 
-func checkHealth(templateContext map[string]interface{}, healthPolicyTemplate string) (bool, error) {
+// CheckHealth evaluates the health policy with the template context, the context contains the live objects of outputs
+func CheckHealth(templateContext map[string]interface{}, healthPolicyTemplate string) (bool, error) {
 	bt, err := json.Marshal(templateContext)
 	if err != nil {
 		return false, errors.WithMessage(err, "json marshal template context")
@@ -231,10 +239,11 @@ func (wd *workloadDef) Status(ctx process.Context, cli client.Client, ns string,
 	if err != nil {
 		return "", errors.WithMessage(err, "get template context")
 	}
-	return getStatusMessage(templateContext, customStatusTemplate)
+	return GetStatusMessage(templateContext, customStatusTemplate)
 }
 
-func getStatusMessage(templateContext map[string]interface{}, customStatusTemplate string) (string, error) {
+// GetStatusMessage evaluates the message of custom status with the template context
+func GetStatusMessage(templateContext map[string]interface{}, customStatusTemplate string) (string, error) {
 	bt, err := json.Marshal(templateContext)
 	if err != nil {
 		return "", errors.WithMessage(err, "json marshal template context")
@@ -252,6 +261,31 @@ func getStatusMessage(templateContext map[string]interface{}, customStatusTempla
 	return message, nil
 }
 
+// EvalHealthPolicy evaluates a policy which decides the health by `isHealth` and explains it by an optional `message`
+func EvalHealthPolicy(templateContext map[string]interface{}, policyTemplate string) (bool, string, error) {
+	bt, err := json.Marshal(templateContext)
+	if err != nil {
+		return false, "", errors.WithMessage(err, "json marshal template context")
+	}
+	var buff = "context: " + string(bt) + "\n" + policyTemplate
+	var r cue.Runtime
+	inst, err := r.Compile("-", buff)
+	if err != nil {
+		return false, "", errors.WithMessage(err, "compile health policy")
+	}
+	healthy, err := inst.Lookup(HealthCheckPolicy).Bool()
+	if err != nil {
+		return false, "", errors.WithMessage(err, "evaluate health status")
+	}
+	var message string
+	if v := inst.Lookup(CustomMessage); v.Exists() {
+		if message, err = v.String(); err != nil {
+			return false, "", errors.WithMessage(err, "evaluate message")
+		}
+	}
+	return healthy, message, nil
+}
+
 type traitDef struct {
 	def
 }
@@ -295,7 +329,7 @@ func (td *traitDef) Complete(ctx process.Context, abstractTemplate string, param
 	}
 
 	if err := inst.Value().Validate(); err != nil {
-		return errors.WithMessagef(err, "invalid template of trait %s after merge with parameter and context", td.name)
+		return errors.WithMessagef(parameterErrors(err), "invalid template of trait %s after merge with parameter and context", td.name)
 	}
 	processing := inst.Lookup("processing")
 	if processing.Exists() {
@@ -305,6 +339,11 @@ func (td *traitDef) Complete(ctx process.Context, abstractTemplate string, param
 	}
 	outputs := inst.Lookup(OutputsFieldName)
 	if outputs.Exists() {
+		if _, err := outputs.MarshalJSON(); err != nil {
+			if perr := incompleteParameters(inst); perr != nil {
+				return errors.WithMessagef(perr, "invalid outputs of trait %s", td.name)
+			}
+		}
 		st, err := outputs.Struct()
 		if err != nil {
 			return errors.WithMessagef(err, "invalid outputs of trait %s", td.name)
@@ -400,7 +439,7 @@ func (td *traitDef) Status(ctx process.Context, cli client.Client, ns string, cu
 	if err != nil {
 		return "", errors.WithMessage(err, "get template context")
 	}
-	return getStatusMessage(templateContext, customStatusTemplate)
+	return GetStatusMessage(templateContext, customStatusTemplate)
 }
 
 // HealthCheck address health check for trait
@@ -412,7 +451,7 @@ func (td *traitDef) HealthCheck(ctx process.Context, cli client.Client, ns strin
 	if err != nil {
 		return false, errors.WithMessage(err, "get template context")
 	}
-	return checkHealth(templateContext, healthPolicyTemplate)
+	return CheckHealth(templateContext, healthPolicyTemplate)
 }
 
 func getResourceFromObj(obj *unstructured.Unstructured, client client.Reader, namespace string, labels map[string]string, outputsResource string) (map[string]interface{}, error) {
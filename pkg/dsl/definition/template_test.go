@@ -890,7 +890,7 @@ func TestCheckHealth(t *testing.T) {
 		},
 	}
 	for message, ca := range cases {
-		healthy, err := checkHealth(ca.tpContext, ca.healthTemp)
+		healthy, err := CheckHealth(ca.tpContext, ca.healthTemp)
 		assert.NoError(t, err, message)
 		assert.Equal(t, ca.exp, healthy, message)
 	}
@@ -961,8 +961,54 @@ if len(context.outputs.ingress.status.loadBalancer.ingress) == 0 {
 		},
 	}
 	for message, ca := range cases {
-		gotMessage, err := getStatusMessage(ca.tpContext, ca.statusTemp)
+		gotMessage, err := GetStatusMessage(ca.tpContext, ca.statusTemp)
 		assert.NoError(t, err, message)
 		assert.Equal(t, ca.expMessage, gotMessage, message)
 	}
 }
+
+func TestEvalHealthPolicy(t *testing.T) {
+	tpContext := map[string]interface{}{
+		"components": map[string]interface{}{
+			"us": map[string]interface{}{"healthy": true},
+			"eu": map[string]interface{}{"healthy": false},
+			"cn": map[string]interface{}{"healthy": true},
+		},
+	}
+	cases := map[string]struct {
+		policy     string
+		expHealthy bool
+		expMessage string
+		expErr     bool
+	}{
+		"without message": {
+			policy:     `isHealth: context.components.us.healthy`,
+			expHealthy: true,
+		},
+		"with message": {
+			policy: `healthyRegions: len([ for c in context.components if c.healthy {c}])
+isHealth: healthyRegions >= 2
+message: "\(healthyRegions) of 3 regions are healthy"`,
+			expHealthy: true,
+			expMessage: "2 of 3 regions are healthy",
+		},
+		"unhealthy": {
+			policy:     `isHealth: context.components.eu.healthy`,
+			expHealthy: false,
+		},
+		"missing isHealth": {
+			policy: `message: "ok"`,
+			expErr: true,
+		},
+	}
+	for name, ca := range cases {
+		healthy, message, err := EvalHealthPolicy(tpContext, ca.policy)
+		if ca.expErr {
+			assert.Error(t, err, name)
+			continue
+		}
+		assert.NoError(t, err, name)
+		assert.Equal(t, ca.expHealthy, healthy, name)
+		assert.Equal(t, ca.expMessage, message, name)
+	}
+}
@@ -0,0 +1,310 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package notification
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/pkg/errors"
+	"golang.org/x/time/rate"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/common"
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
+)
+
+// EventType is the type of application event notified
+type EventType string
+
+const (
+	// EventPhaseChanged means the phase of application is changed
+	EventPhaseChanged EventType = "PhaseChanged"
+	// EventFailure means the application failed in reconciliation, e.g. cannot apply resources
+	EventFailure EventType = "Failure"
+)
+
+// DefaultTemplate is the template of message if the template of notification is empty
+const DefaultTemplate = `Application {{ .Namespace }}/{{ .Application }} ` +
+	`{{ if eq .Type "Failure" }}{{ .Reason }}: {{ .Message }}` +
+	`{{ else }}phase changed from {{ .PreviousPhase }} to {{ .Phase }}{{ end }}`
+
+// Event is an event of application to notify, it's the data of message template
+type Event struct {
+	Type          EventType               `json:"type"`
+	Namespace     string                  `json:"namespace"`
+	Application   string                  `json:"application"`
+	Phase         common.ApplicationPhase `json:"phase,omitempty"`
+	PreviousPhase common.ApplicationPhase `json:"previousPhase,omitempty"`
+	Reason        string                  `json:"reason,omitempty"`
+	Message       string                  `json:"message,omitempty"`
+	Time          time.Time               `json:"time"`
+}
+
+// Options configures deliveries of notifications
+type Options struct {
+	// DedupWindow is the period that the same message to a receiver is sent only once
+	DedupWindow time.Duration
+	// QPS is the maximum rate of messages delivered to a receiver, messages over the limit are dropped.
+	// A non-positive value means no limit.
+	QPS float64
+	// Burst is the maximum number of messages delivered to a receiver at once
+	Burst int
+	// Retries is the number of retries if a message fails to be delivered
+	Retries int
+	// RetryInterval is the initial interval between retries, it's doubled after each retry
+	RetryInterval time.Duration
+	// QueueSize is the maximum number of messages waiting for delivery
+	QueueSize int
+	// HTTPClient sends messages to receivers
+	HTTPClient *http.Client
+}
+
+// DefaultOptions returns the default options of Notifier
+func DefaultOptions() Options {
+	return Options{
+		DedupWindow:   10 * time.Minute,
+		QPS:           1,
+		Burst:         5,
+		Retries:       3,
+		RetryInterval: time.Second,
+		QueueSize:     1000,
+		HTTPClient:    &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// delivery is a message to a receiver
+type delivery struct {
+	key      string
+	receiver v1beta1.NotificationReceiver
+	url      string
+	event    Event
+	text     string
+}
+
+// Notifier delivers the events of applications to the receivers of notifications subscribing them. Messages are
+// delivered asynchronously once it's started, so that reconciliation of applications is not blocked.
+type Notifier struct {
+	client client.Client
+	log    logr.Logger
+	opts   Options
+	queue  chan delivery
+
+	mu       sync.Mutex
+	sent     map[string]time.Time
+	limiters map[string]*rate.Limiter
+}
+
+// NewNotifier creates a Notifier
+func NewNotifier(c client.Client, log logr.Logger, opts Options) *Notifier {
+	defaults := DefaultOptions()
+	if opts.QueueSize <= 0 {
+		opts.QueueSize = defaults.QueueSize
+	}
+	if opts.RetryInterval <= 0 {
+		opts.RetryInterval = defaults.RetryInterval
+	}
+	if opts.HTTPClient == nil {
+		opts.HTTPClient = defaults.HTTPClient
+	}
+	return &Notifier{
+		client:   c,
+		log:      log,
+		opts:     opts,
+		queue:    make(chan delivery, opts.QueueSize),
+		sent:     map[string]time.Time{},
+		limiters: map[string]*rate.Limiter{},
+	}
+}
+
+// Start delivers messages until stop is closed, it implements manager.Runnable
+func (n *Notifier) Start(stop <-chan struct{}) error {
+	for {
+		select {
+		case <-stop:
+			return nil
+		case d := <-n.queue:
+			if err := n.deliver(d); err != nil {
+				n.log.Error(err, "cannot deliver notification", "receiver", d.receiver.Name,
+					"application", d.event.Namespace+"/"+d.event.Application)
+			}
+		}
+	}
+}
+
+// PhaseChanged notifies the transition of application phase
+func (n *Notifier) PhaseChanged(ctx context.Context, app *v1beta1.Application, previous common.ApplicationPhase) {
+	n.Notify(ctx, Event{
+		Type:          EventPhaseChanged,
+		Namespace:     app.Namespace,
+		Application:   app.Name,
+		Phase:         app.Status.Phase,
+		PreviousPhase: previous,
+		Time:          time.Now(),
+	})
+}
+
+// Failed notifies a failure of application
+func (n *Notifier) Failed(ctx context.Context, app *v1beta1.Application, reason, message string) {
+	n.Notify(ctx, Event{
+		Type:        EventFailure,
+		Namespace:   app.Namespace,
+		Application: app.Name,
+		Phase:       app.Status.Phase,
+		Reason:      reason,
+		Message:     message,
+		Time:        time.Now(),
+	})
+}
+
+// Notify queues the messages of event to the receivers of notifications subscribing it, messages sent to a receiver
+// in the dedup window or over the rate limit are dropped
+func (n *Notifier) Notify(ctx context.Context, e Event) {
+	notifications := new(v1beta1.NotificationList)
+	if err := n.client.List(ctx, notifications, client.InNamespace(e.Namespace)); err != nil {
+		n.log.Error(err, "cannot list notifications", "namespace", e.Namespace)
+		return
+	}
+	for i := range notifications.Items {
+		nt := &notifications.Items[i]
+		if !subscribed(nt.Spec, e) {
+			continue
+		}
+		text, err := renderMessage(nt.Spec.Template, e)
+		if err != nil {
+			n.log.Error(err, "cannot render notification message", "notification", nt.Name)
+			continue
+		}
+		for _, r := range nt.Spec.Receivers {
+			key := strings.Join([]string{nt.Name, r.Name, e.Application, string(e.Type), text}, "/")
+			if !n.allow(nt.Namespace+"/"+nt.Name+"/"+r.Name, key) {
+				continue
+			}
+			url, err := n.receiverURL(ctx, nt.Namespace, r)
+			if err != nil {
+				n.log.Error(err, "cannot get URL of receiver", "notification", nt.Name, "receiver", r.Name)
+				continue
+			}
+			select {
+			case n.queue <- delivery{key: key, receiver: r, url: url, event: e, text: text}:
+			default:
+				n.log.Info("drop notification as the queue is full", "notification", nt.Name, "receiver", r.Name)
+			}
+		}
+	}
+}
+
+// subscribed checks whether the event is subscribed by the notification
+func subscribed(spec v1beta1.NotificationSpec, e Event) bool {
+	if len(spec.Applications) > 0 && !contains(spec.Applications, e.Application) {
+		return false
+	}
+	if len(spec.Phases) == 0 && len(spec.Failures) == 0 {
+		return true
+	}
+	switch e.Type {
+	case EventPhaseChanged:
+		for _, p := range spec.Phases {
+			if p == e.Phase {
+				return true
+			}
+		}
+	case EventFailure:
+		return contains(spec.Failures, e.Reason)
+	}
+	return false
+}
+
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
+func renderMessage(tmpl string, e Event) (string, error) {
+	if tmpl == "" {
+		tmpl = DefaultTemplate
+	}
+	t, err := template.New("message").Parse(tmpl)
+	if err != nil {
+		return "", errors.Wrap(err, "invalid template")
+	}
+	var buf bytes.Buffer
+	if err := t.Execute(&buf, e); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// allow checks the message is not a duplicate in the dedup window and the receiver is not over the rate limit
+func (n *Notifier) allow(receiver, key string) bool {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	now := time.Now()
+	if last, ok := n.sent[key]; ok && now.Sub(last) < n.opts.DedupWindow {
+		return false
+	}
+	if n.opts.QPS > 0 {
+		limiter, ok := n.limiters[receiver]
+		if !ok {
+			limiter = rate.NewLimiter(rate.Limit(n.opts.QPS), n.opts.Burst)
+			n.limiters[receiver] = limiter
+		}
+		if !limiter.AllowN(now, 1) {
+			n.log.Info("drop notification over the rate limit", "receiver", receiver)
+			return false
+		}
+	}
+	n.sent[key] = now
+	// clean up expired records to bound the memory
+	for k, t := range n.sent {
+		if now.Sub(t) >= n.opts.DedupWindow {
+			delete(n.sent, k)
+		}
+	}
+	return true
+}
+
+func (n *Notifier) receiverURL(ctx context.Context, namespace string, r v1beta1.NotificationReceiver) (string, error) {
+	if r.URL != "" || r.URLSecretRef == nil {
+		if r.URL == "" {
+			return "", fmt.Errorf("URL of receiver %s is not set", r.Name)
+		}
+		return r.URL, nil
+	}
+	secret := new(corev1.Secret)
+	if err := n.client.Get(ctx, types.NamespacedName{Namespace: namespace, Name: r.URLSecretRef.Name}, secret); err != nil {
+		return "", err
+	}
+	url, ok := secret.Data[r.URLSecretRef.Key]
+	if !ok {
+		return "", fmt.Errorf("key %s is not found in secret %s", r.URLSecretRef.Key, r.URLSecretRef.Name)
+	}
+	return strings.TrimSpace(string(url)), nil
+}
@@ -0,0 +1,184 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package notification
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/common"
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
+	common2 "github.com/oam-dev/kubevela/pkg/utils/common"
+)
+
+// receiverServer records the payloads posted to it, it fails the first failures requests
+type receiverServer struct {
+	*httptest.Server
+	mu       sync.Mutex
+	failures int
+	payloads map[string][]map[string]interface{}
+}
+
+func newReceiverServer(failures int) *receiverServer {
+	s := &receiverServer{failures: failures, payloads: map[string][]map[string]interface{}{}}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		if s.failures > 0 {
+			s.failures--
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		body, _ := ioutil.ReadAll(r.Body)
+		payload := map[string]interface{}{}
+		_ = json.Unmarshal(body, &payload)
+		s.payloads[r.URL.Path] = append(s.payloads[r.URL.Path], payload)
+	}))
+	return s
+}
+
+func (s *receiverServer) received(path string) []map[string]interface{} {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.payloads[path]
+}
+
+func TestNotifier(t *testing.T) {
+	server := newReceiverServer(1)
+	defer server.Close()
+
+	notification := &v1beta1.Notification{
+		ObjectMeta: metav1.ObjectMeta{Name: "ops", Namespace: "default"},
+		Spec: v1beta1.NotificationSpec{
+			Applications: []string{"myapp"},
+			Phases:       []common.ApplicationPhase{common.ApplicationRunning},
+			Failures:     []string{"FailedApply"},
+			Receivers: []v1beta1.NotificationReceiver{
+				{Name: "hook", Type: v1beta1.WebhookReceiver, URL: server.URL + "/webhook"},
+				{Name: "slack", Type: v1beta1.SlackReceiver, URL: server.URL + "/slack"},
+				{Name: "dingtalk", Type: v1beta1.DingTalkReceiver, URLSecretRef: &corev1.SecretKeySelector{
+					LocalObjectReference: corev1.LocalObjectReference{Name: "dingtalk"}, Key: "url"}},
+			},
+		},
+	}
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "dingtalk", Namespace: "default"},
+		Data:       map[string][]byte{"url": []byte(server.URL + "/dingtalk")},
+	}
+	c := fake.NewFakeClientWithScheme(common2.Scheme, notification, secret)
+	opts := DefaultOptions()
+	opts.RetryInterval = 10 * time.Millisecond
+	n := NewNotifier(c, ctrl.Log.WithName("test"), opts)
+	stop := make(chan struct{})
+	defer close(stop)
+	go func() {
+		_ = n.Start(stop)
+	}()
+
+	ctx := context.Background()
+	app := &v1beta1.Application{ObjectMeta: metav1.ObjectMeta{Name: "myapp", Namespace: "default"}}
+	app.Status.Phase = common.ApplicationRunning
+	n.PhaseChanged(ctx, app, common.ApplicationRendering)
+	// duplicate and unsubscribed events are not delivered
+	n.PhaseChanged(ctx, app, common.ApplicationRendering)
+	n.Failed(ctx, app, "FailedGC", "cannot delete resources")
+	other := &v1beta1.Application{ObjectMeta: metav1.ObjectMeta{Name: "other", Namespace: "default"}}
+	n.Failed(ctx, other, "FailedApply", "cannot apply resources")
+
+	assert.Eventually(t, func() bool {
+		return len(server.received("/webhook")) == 1 && len(server.received("/slack")) == 1 &&
+			len(server.received("/dingtalk")) == 1
+	}, 5*time.Second, 10*time.Millisecond)
+	webhook := server.received("/webhook")[0]
+	assert.Equal(t, "PhaseChanged", webhook["type"])
+	assert.Equal(t, "myapp", webhook["application"])
+	assert.Equal(t, "running", webhook["phase"])
+	assert.Equal(t, "rendering", webhook["previousPhase"])
+	text := "Application default/myapp phase changed from rendering to running"
+	assert.Equal(t, text, webhook["text"])
+	assert.Equal(t, map[string]interface{}{"text": text}, server.received("/slack")[0])
+	assert.Equal(t, map[string]interface{}{"msgtype": "text", "text": map[string]interface{}{"content": text}},
+		server.received("/dingtalk")[0])
+
+	notification.Spec.Template = "{{ .Application }} failed: {{ .Message }}"
+	assert.NoError(t, c.Update(ctx, notification))
+	n.Failed(ctx, app, "FailedApply", "cannot apply resources")
+	assert.Eventually(t, func() bool {
+		return len(server.received("/slack")) == 2
+	}, 5*time.Second, 10*time.Millisecond)
+	assert.Equal(t, "myapp failed: cannot apply resources", server.received("/slack")[1]["text"])
+}
+
+func TestSubscribed(t *testing.T) {
+	phaseChanged := Event{Type: EventPhaseChanged, Application: "myapp", Phase: common.ApplicationRunning}
+	failure := Event{Type: EventFailure, Application: "myapp", Reason: "FailedApply"}
+	testCases := map[string]struct {
+		spec     v1beta1.NotificationSpec
+		event    Event
+		expected bool
+	}{
+		"all events of namespace": {
+			event:    failure,
+			expected: true,
+		},
+		"application not selected": {
+			spec:  v1beta1.NotificationSpec{Applications: []string{"other"}},
+			event: phaseChanged,
+		},
+		"phase subscribed": {
+			spec:     v1beta1.NotificationSpec{Phases: []common.ApplicationPhase{common.ApplicationRunning}},
+			event:    phaseChanged,
+			expected: true,
+		},
+		"only phases subscribed": {
+			spec:  v1beta1.NotificationSpec{Phases: []common.ApplicationPhase{common.ApplicationRunning}},
+			event: failure,
+		},
+		"failure not subscribed": {
+			spec:  v1beta1.NotificationSpec{Failures: []string{"FailedHealthCheck"}},
+			event: failure,
+		},
+	}
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, subscribed(tc.spec, tc.event))
+		})
+	}
+}
+
+func TestRateLimit(t *testing.T) {
+	opts := DefaultOptions()
+	opts.QPS, opts.Burst = 1, 2
+	n := NewNotifier(nil, ctrl.Log.WithName("test"), opts)
+	assert.True(t, n.allow("r", "a"))
+	assert.False(t, n.allow("r", "a"))
+	assert.True(t, n.allow("r", "b"))
+	assert.False(t, n.allow("r", "c"))
+	assert.True(t, n.allow("another", "c"))
+}
@@ -0,0 +1,52 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package notification
+
+import (
+	"context"
+
+	"github.com/crossplane/crossplane-runtime/pkg/event"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
+)
+
+// recorder forwards the warning events of applications to Notifier besides recording them
+type recorder struct {
+	event.Recorder
+	notifier *Notifier
+}
+
+// NewRecorder wraps an event recorder to notify the failures of applications
+func NewRecorder(r event.Recorder, n *Notifier) event.Recorder {
+	return &recorder{Recorder: r, notifier: n}
+}
+
+// Event records the event and notifies it if it's a warning of application
+func (r *recorder) Event(obj runtime.Object, e event.Event) {
+	r.Recorder.Event(obj, e)
+	app, ok := obj.(*v1beta1.Application)
+	if !ok || e.Type != event.TypeWarning {
+		return
+	}
+	r.notifier.Failed(context.Background(), app, string(e.Reason), e.Message)
+}
+
+// WithAnnotations returns a recorder with annotations which still notifies events
+func (r *recorder) WithAnnotations(keysAndValues ...string) event.Recorder {
+	return &recorder{Recorder: r.Recorder.WithAnnotations(keysAndValues...), notifier: r.notifier}
+}
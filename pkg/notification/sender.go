@@ -0,0 +1,100 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package notification
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+
+	"k8s.io/apimachinery/pkg/util/wait"
+
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
+)
+
+// webhookPayload is the payload sent to a generic webhook
+type webhookPayload struct {
+	Event `json:",inline"`
+	Text  string `json:"text"`
+}
+
+// payload formats the message for the type of receiver
+func payload(t v1beta1.NotificationReceiverType, e Event, text string) ([]byte, error) {
+	switch t {
+	case v1beta1.SlackReceiver:
+		return json.Marshal(map[string]string{"text": text})
+	case v1beta1.DingTalkReceiver:
+		return json.Marshal(map[string]interface{}{
+			"msgtype": "text",
+			"text":    map[string]string{"content": text},
+		})
+	case v1beta1.WebhookReceiver, "":
+		return json.Marshal(webhookPayload{Event: e, Text: text})
+	default:
+		return nil, fmt.Errorf("unsupported receiver type %s", t)
+	}
+}
+
+// deliver sends a message to the receiver, it retries with exponential backoff if the receiver is unavailable
+func (n *Notifier) deliver(d delivery) error {
+	body, err := payload(d.receiver.Type, d.event, d.text)
+	if err != nil {
+		return err
+	}
+	backoff := wait.Backoff{Duration: n.opts.RetryInterval, Factor: 2, Steps: n.opts.Retries + 1}
+	var sendErr error
+	err = wait.ExponentialBackoff(backoff, func() (bool, error) {
+		retry, err := n.send(d.url, body)
+		if err == nil {
+			return true, nil
+		}
+		sendErr = err
+		if !retry {
+			return false, err
+		}
+		return false, nil
+	})
+	if err != nil {
+		// the message can be sent again once the receiver recovers
+		n.mu.Lock()
+		delete(n.sent, d.key)
+		n.mu.Unlock()
+		if sendErr != nil {
+			return sendErr
+		}
+		return err
+	}
+	return nil
+}
+
+// send posts the body to url, it returns true if the failure is transient and the message should be sent again
+func (n *Notifier) send(url string, body []byte) (bool, error) {
+	resp, err := n.opts.HTTPClient.Post(url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return true, err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(ioutil.Discard, resp.Body)
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return false, nil
+	}
+	err = fmt.Errorf("receiver responds %s", resp.Status)
+	return resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500, err
+}
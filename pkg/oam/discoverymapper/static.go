@@ -0,0 +1,120 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package discoverymapper
+
+import (
+	crdv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// clusterScopedKinds are the built-in kinds which are not namespaced, other kinds in a scheme are assumed to be namespaced
+var clusterScopedKinds = map[string]bool{
+	"Namespace":                      true,
+	"Node":                           true,
+	"PersistentVolume":               true,
+	"ComponentStatus":                true,
+	"ClusterRole":                    true,
+	"ClusterRoleBinding":             true,
+	"StorageClass":                   true,
+	"VolumeAttachment":               true,
+	"CSIDriver":                      true,
+	"CSINode":                        true,
+	"PriorityClass":                  true,
+	"RuntimeClass":                   true,
+	"PodSecurityPolicy":              true,
+	"IngressClass":                   true,
+	"CertificateSigningRequest":      true,
+	"CustomResourceDefinition":       true,
+	"APIService":                     true,
+	"MutatingWebhookConfiguration":   true,
+	"ValidatingWebhookConfiguration": true,
+}
+
+var _ DiscoveryMapper = &StaticDiscoveryMapper{}
+
+// StaticDiscoveryMapper maps resources without a K8s cluster, it only knows the kinds registered in a scheme and
+// the given CRDs. It's used to render applications offline.
+type StaticDiscoveryMapper struct {
+	mapper meta.RESTMapper
+}
+
+// NewStaticDiscoveryMapper creates a StaticDiscoveryMapper, resource names of the kinds in scheme are guessed from kinds
+func NewStaticDiscoveryMapper(scheme *runtime.Scheme, crds ...*crdv1.CustomResourceDefinition) *StaticDiscoveryMapper {
+	// default group versions are searched when a kind is mapped without version
+	defaultGroupVersions := scheme.PrioritizedVersionsAllGroups()
+	for _, crd := range crds {
+		for _, v := range crd.Spec.Versions {
+			defaultGroupVersions = append(defaultGroupVersions, schema.GroupVersion{Group: crd.Spec.Group, Version: v.Name})
+		}
+	}
+	mapper := meta.NewDefaultRESTMapper(defaultGroupVersions)
+	for gvk := range scheme.AllKnownTypes() {
+		if gvk.Version == runtime.APIVersionInternal {
+			continue
+		}
+		scope := meta.RESTScopeNamespace
+		if clusterScopedKinds[gvk.Kind] {
+			scope = meta.RESTScopeRoot
+		}
+		mapper.Add(gvk, scope)
+	}
+	for _, crd := range crds {
+		scope := meta.RESTScopeNamespace
+		if crd.Spec.Scope == crdv1.ClusterScoped {
+			scope = meta.RESTScopeRoot
+		}
+		for _, v := range crd.Spec.Versions {
+			gvk := schema.GroupVersionKind{Group: crd.Spec.Group, Version: v.Name, Kind: crd.Spec.Names.Kind}
+			plural := schema.GroupVersionResource{Group: crd.Spec.Group, Version: v.Name, Resource: crd.Spec.Names.Plural}
+			singular := plural
+			singular.Resource = crd.Spec.Names.Singular
+			mapper.AddSpecific(gvk, plural, singular, scope)
+		}
+	}
+	return &StaticDiscoveryMapper{mapper: mapper}
+}
+
+// GetMapper returns the static mapper
+func (d *StaticDiscoveryMapper) GetMapper() (meta.RESTMapper, error) {
+	return d.mapper, nil
+}
+
+// Refresh does nothing as there is nothing to discover
+func (d *StaticDiscoveryMapper) Refresh() (meta.RESTMapper, error) {
+	return d.mapper, nil
+}
+
+// RESTMapping maps resources from GVK
+func (d *StaticDiscoveryMapper) RESTMapping(gk schema.GroupKind, version ...string) (*meta.RESTMapping, error) {
+	return d.mapper.RESTMapping(gk, version...)
+}
+
+// KindsFor gets kinds from GroupVersionResource, if version not set, all resources matched will be returned.
+func (d *StaticDiscoveryMapper) KindsFor(input schema.GroupVersionResource) ([]schema.GroupVersionKind, error) {
+	return d.mapper.KindsFor(input)
+}
+
+// ResourcesFor gets a resource from GroupVersionKind
+func (d *StaticDiscoveryMapper) ResourcesFor(input schema.GroupVersionKind) (schema.GroupVersionResource, error) {
+	mapping, err := d.mapper.RESTMapping(input.GroupKind(), input.Version)
+	if err != nil {
+		return schema.GroupVersionResource{}, err
+	}
+	return mapping.Resource, nil
+}
@@ -33,6 +33,17 @@ const (
 	LabelOAMResourceType = "app.oam.dev/resourceType"
 	// LabelAppRevisionHash records the Hash value of the application revision
 	LabelAppRevisionHash = "app.oam.dev/app-revision-hash"
+	// LabelAppEnv records the name of the Environment that an Application is deployed to
+	LabelAppEnv = "app.oam.dev/env"
+	// LabelApplicationSet records the name of the ApplicationSet that an Application is generated by
+	LabelApplicationSet = "app.oam.dev/application-set"
+	// LabelApplicationSetNamespace records the namespace of the ApplicationSet that an Application is generated by
+	LabelApplicationSetNamespace = "app.oam.dev/application-set-namespace"
+	// LabelApplicationSetEntry records the name of the ApplicationSet entry that an Application is rendered for
+	LabelApplicationSetEntry = "app.oam.dev/application-set-entry"
+	// LabelApplicationSetAllowedNamespace is set on a namespace to allow the ApplicationSets in the namespace of its value
+	// to generate applications in it
+	LabelApplicationSetAllowedNamespace = "app.oam.dev/application-set-allowed-namespace"
 
 	// WorkloadTypeLabel indicates the type of the workloadDefinition
 	WorkloadTypeLabel = "workload.oam.dev/type"
@@ -82,4 +93,49 @@ const (
 	// AnnotationAppRevisionOnly the Application update should only generate revision,
 	// not any appContexts or components.
 	AnnotationAppRevisionOnly = "app.oam.dev/revision-only"
+
+	// AnnotationDeletionPolicy indicates the deletion policy of the component or trait the resource is rendered from,
+	// resources with Orphan or Retain policy are not garbage collected when they're removed from the application
+	AnnotationDeletionPolicy = "app.oam.dev/deletion-policy"
+
+	// AnnotationChangedBy records the user who changed the spec of application last time,
+	// it's set by the admission webhook and recorded in the application revision
+	AnnotationChangedBy = "app.oam.dev/changed-by"
+
+	// AnnotationChangedAt records when the spec of application was changed last time in RFC3339 format
+	AnnotationChangedAt = "app.oam.dev/changed-at"
+
+	// AnnotationChangeMessage describes why the application is changed, it's recorded in the application revision
+	AnnotationChangeMessage = "app.oam.dev/change-message"
+
+	// AnnotationBatchApproval records the latest approval of rollout batches in JSON, it's set by the admission
+	// webhook when the batch partition of an AppRollout is advanced
+	AnnotationBatchApproval = "app.oam.dev/batch-approval"
+
+	// AnnotationAbortedTarget aborts the rollout to the target app revision of the annotation value
+	AnnotationAbortedTarget = "app.oam.dev/aborted-target"
+
+	// AnnotationAdoptResources indicates that the application takes over the existing resources with the same
+	// names as its workloads and traits, if they have no controller or are controlled by AppConfigs handed over to it
+	AnnotationAdoptResources = "app.oam.dev/adopt-resources"
+
+	// AnnotationHandedOverTo is set on an AppConfig to hand over its workloads and traits to the application of
+	// the annotation value, the application adopts them if it adopts resources
+	AnnotationHandedOverTo = "app.oam.dev/handed-over-to"
+
+	// AnnotationApplicationSetHash records the hash of the application rendered from the template of ApplicationSet,
+	// the application is updated if the hash is changed
+	AnnotationApplicationSetHash = "app.oam.dev/application-set-hash"
+
+	// AnnotationApplicationSetMetadata records the keys of labels and annotations set by the template of ApplicationSet
+	// in JSON, they're removed from the application once they're removed from the template
+	AnnotationApplicationSetMetadata = "app.oam.dev/application-set-metadata"
+
+	// AnnotationDeploymentWindowOverride overrides the deployment windows of the application or AppRollout until
+	// the time of annotation value in RFC3339 format
+	AnnotationDeploymentWindowOverride = "app.oam.dev/deployment-window-override"
+
+	// AnnotationDeploymentWindowOverrideBy records the user who set the deployment window override, it's set by
+	// the admission webhook
+	AnnotationDeploymentWindowOverrideBy = "app.oam.dev/deployment-window-override-by"
 )
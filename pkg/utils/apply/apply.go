@@ -29,6 +29,7 @@ import (
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/klog/v2"
+	"k8s.io/utils/pointer"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
@@ -186,3 +187,85 @@ func MustBeControllableBy(u types.UID) ApplyOption {
 		return nil
 	}
 }
+
+// AdoptBy is like MustBeControllableBy, but takes over an existing object
+// without a controller, or controlled by one of the handed over UIDs. An
+// object controlled by others is never taken over. The previous owners are
+// kept as owners that are not the controller. The label selector of the
+// existing object is kept as well because it's immutable for most workloads,
+// so the object is adopted without being recreated.
+func AdoptBy(u types.UID, handedOver ...types.UID) ApplyOption {
+	return func(_ context.Context, existing, desired runtime.Object) error {
+		if existing == nil {
+			return nil
+		}
+		e, ok := existing.(metav1.Object)
+		if !ok {
+			return errors.New("cannot access metadata of the existing object")
+		}
+		d, ok := desired.(metav1.Object)
+		if !ok {
+			return errors.New("cannot access metadata of the desired object")
+		}
+		if c := metav1.GetControllerOf(e); c != nil && c.UID != u {
+			// if workload is a cross namespace resource, skip check UID
+			if c.Kind == v1beta1.ResourceTrackerKind {
+				return nil
+			}
+			if !hasUID(handedOver, c.UID) {
+				return errors.Errorf("existing object is controlled by %s %s and not handed over to UID %q", c.Kind, c.Name, u)
+			}
+		}
+		owners := d.GetOwnerReferences()
+		for _, ref := range e.GetOwnerReferences() {
+			if ref.UID == u || hasOwner(owners, ref.UID) {
+				continue
+			}
+			ref.Controller = pointer.BoolPtr(false)
+			owners = append(owners, ref)
+		}
+		d.SetOwnerReferences(owners)
+		return keepLabelSelector(existing, desired)
+	}
+}
+
+func hasUID(uids []types.UID, u types.UID) bool {
+	for _, uid := range uids {
+		if uid == u {
+			return true
+		}
+	}
+	return false
+}
+
+func hasOwner(owners []metav1.OwnerReference, u types.UID) bool {
+	for _, o := range owners {
+		if o.UID == u {
+			return true
+		}
+	}
+	return false
+}
+
+// keepLabelSelector sets the label selector of the existing object to the desired one.
+// Selectors of services are maps without matchLabels and matchExpressions, they are not kept.
+func keepLabelSelector(existing, desired runtime.Object) error {
+	e, err := runtime.DefaultUnstructuredConverter.ToUnstructured(existing)
+	if err != nil {
+		return errors.Wrap(err, "cannot convert the existing object")
+	}
+	selector, found, err := unstructured.NestedMap(e, "spec", "selector")
+	if err != nil || !found {
+		return nil
+	}
+	_, hasLabels := selector["matchLabels"]
+	_, hasExpressions := selector["matchExpressions"]
+	if !hasLabels && !hasExpressions {
+		return nil
+	}
+	d, ok := desired.(*unstructured.Unstructured)
+	if !ok {
+		return errors.New("cannot keep the label selector of a typed object")
+	}
+	return errors.Wrap(unstructured.SetNestedMap(d.Object, selector, "spec", "selector"), "cannot keep the label selector")
+}
@@ -30,6 +30,7 @@ import (
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/pointer"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
@@ -371,3 +372,95 @@ func TestMustBeControllableBy(t *testing.T) {
 		})
 	}
 }
+
+func TestAdoptBy(t *testing.T) {
+	uid := types.UID("very-unique-string")
+	controller := true
+	ctx := context.TODO()
+	owner := metav1.OwnerReference{UID: uid, Controller: &controller, Kind: "ApplicationConfiguration", Name: "app"}
+	newDesired := func() *unstructured.Unstructured {
+		u := &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "apps/v1",
+			"kind":       "Deployment",
+			"spec": map[string]interface{}{
+				"selector": map[string]interface{}{"matchLabels": map[string]interface{}{"app.oam.dev/component": "web"}},
+			},
+		}}
+		u.SetOwnerReferences([]metav1.OwnerReference{owner})
+		return u
+	}
+
+	cases := map[string]struct {
+		reason       string
+		current      runtime.Object
+		wantOwners   []metav1.OwnerReference
+		wantSelector map[string]interface{}
+	}{
+		"NoExistingObject": {
+			reason:       "The desired object should not be changed if no existing object",
+			wantOwners:   []metav1.OwnerReference{owner},
+			wantSelector: map[string]interface{}{"matchLabels": map[string]interface{}{"app.oam.dev/component": "web"}},
+		},
+		"HandedOver": {
+			reason: "The previous controller should be kept as an owner and the label selector should be kept",
+			current: &appsv1.Deployment{
+				ObjectMeta: metav1.ObjectMeta{OwnerReferences: []metav1.OwnerReference{
+					{UID: "handed-over-uid", Controller: &controller, Kind: "ApplicationConfiguration", Name: "web"},
+				}},
+				Spec: appsv1.DeploymentSpec{Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}}},
+			},
+			wantOwners: []metav1.OwnerReference{
+				owner,
+				{UID: "handed-over-uid", Controller: pointer.BoolPtr(false), Kind: "ApplicationConfiguration", Name: "web"},
+			},
+			wantSelector: map[string]interface{}{"matchLabels": map[string]interface{}{"app": "web"}},
+		},
+		"NoController": {
+			reason: "An object without a controller should be adopted",
+			current: &appsv1.Deployment{
+				ObjectMeta: metav1.ObjectMeta{OwnerReferences: []metav1.OwnerReference{
+					{UID: "some-owner-uid", Kind: "ConfigMap", Name: "web"},
+				}},
+				Spec: appsv1.DeploymentSpec{Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}}},
+			},
+			wantOwners: []metav1.OwnerReference{
+				owner,
+				{UID: "some-owner-uid", Controller: pointer.BoolPtr(false), Kind: "ConfigMap", Name: "web"},
+			},
+			wantSelector: map[string]interface{}{"matchLabels": map[string]interface{}{"app": "web"}},
+		},
+		"Adopted": {
+			reason: "An adopted object should keep its label selector",
+			current: &appsv1.Deployment{
+				ObjectMeta: metav1.ObjectMeta{OwnerReferences: []metav1.OwnerReference{owner}},
+				Spec:       appsv1.DeploymentSpec{Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}}},
+			},
+			wantOwners:   []metav1.OwnerReference{owner},
+			wantSelector: map[string]interface{}{"matchLabels": map[string]interface{}{"app": "web"}},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			desired := newDesired()
+			if err := AdoptBy(uid, "handed-over-uid")(ctx, tc.current, desired); err != nil {
+				t.Fatalf("\n%s\nAdoptBy(...)(...): unexpected error: %v\n", tc.reason, err)
+			}
+			if diff := cmp.Diff(tc.wantOwners, desired.GetOwnerReferences()); diff != "" {
+				t.Errorf("\n%s\nAdoptBy(...)(...): -want owners, +got owners\n%s\n", tc.reason, diff)
+			}
+			selector, _, _ := unstructured.NestedMap(desired.Object, "spec", "selector")
+			if diff := cmp.Diff(tc.wantSelector, selector); diff != "" {
+				t.Errorf("\n%s\nAdoptBy(...)(...): -want selector, +got selector\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+
+	// an object controlled by others is never taken over
+	current := &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{OwnerReferences: []metav1.OwnerReference{
+		{UID: "some-other-uid", Controller: &controller, Kind: "ApplicationConfiguration", Name: "others"},
+	}}}
+	if err := AdoptBy(uid, "handed-over-uid")(ctx, current, newDesired()); err == nil {
+		t.Errorf("AdoptBy(...)(...): expected error when the object is controlled by others")
+	}
+}
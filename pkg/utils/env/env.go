@@ -24,24 +24,31 @@ import (
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"sort"
 
 	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	k8stypes "k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
 	"github.com/oam-dev/kubevela/apis/types"
 	"github.com/oam-dev/kubevela/pkg/utils/system"
 )
 
+// Environments are stored serverside as v1beta1.Environment objects, so the CLI, the apiserver and
+// all teammates share the same set of envs. The env dir under the vela home only caches the
+// envs used locally and records the current env of the user.
+
 // GetEnvDirByName will get env dir from name
 func GetEnvDirByName(name string) string {
 	envdir, _ := system.GetEnvDir()
 	return filepath.Join(envdir, name)
 }
 
-// GetEnvByName will get env info by name
+// GetEnvByName will get env info by name from the local cache, the cache is refreshed by `vela env init/set`
 func GetEnvByName(name string) (*types.EnvMeta, error) {
 	data, err := ioutil.ReadFile(filepath.Join(GetEnvDirByName(name), system.EnvConfigName))
 	if err != nil {
@@ -57,13 +64,47 @@ func GetEnvByName(name string) (*types.EnvMeta, error) {
 	return &meta, nil
 }
 
+// GetEnvFromCluster will get env info by name from the Environment in cluster.
+// The default env is always available even if it has never been created in cluster.
+func GetEnvFromCluster(ctx context.Context, c client.Reader, name string) (*types.EnvMeta, error) {
+	var e v1beta1.Environment
+	if err := c.Get(ctx, k8stypes.NamespacedName{Name: name}, &e); err != nil {
+		if !apierrors.IsNotFound(err) && !meta.IsNoMatchError(err) {
+			return nil, err
+		}
+		if name == types.DefaultEnvName {
+			return defaultEnvMeta(), nil
+		}
+		return nil, fmt.Errorf("env %s not exist", name)
+	}
+	return ToEnvMeta(&e), nil
+}
+
+// ToEnvMeta converts an Environment to EnvMeta
+func ToEnvMeta(e *v1beta1.Environment) *types.EnvMeta {
+	return &types.EnvMeta{
+		Name:      e.Name,
+		Namespace: e.Spec.Namespace,
+		Email:     e.Spec.Email,
+		Domain:    e.Spec.Domain,
+		Clusters:  e.Spec.Clusters,
+	}
+}
+
+func defaultEnvMeta() *types.EnvMeta {
+	return &types.EnvMeta{
+		Name:      types.DefaultEnvName,
+		Namespace: types.DefaultAppNamespace,
+	}
+}
+
 // CreateOrUpdateEnv will create or update env.
 // If it does not exist, create it and set to the new env.
 // If it exists, update it and set to the new env.
 func CreateOrUpdateEnv(ctx context.Context, c client.Client, envName string, envArgs *types.EnvMeta) (string, error) {
 
 	createOrUpdated := "created"
-	old, err := GetEnvByName(envName)
+	old, err := GetEnvFromCluster(ctx, c, envName)
 	if err == nil {
 		createOrUpdated = "updated"
 		if envArgs.Domain == "" {
@@ -75,6 +116,9 @@ func CreateOrUpdateEnv(ctx context.Context, c client.Client, envName string, env
 		if envArgs.Namespace == "" {
 			envArgs.Namespace = old.Namespace
 		}
+		if len(envArgs.Clusters) == 0 {
+			envArgs.Clusters = old.Clusters
+		}
 	}
 
 	if envArgs.Namespace == "" {
@@ -82,31 +126,14 @@ func CreateOrUpdateEnv(ctx context.Context, c client.Client, envName string, env
 	}
 
 	var message = ""
-	// Check If Namespace Exists
-	if err := c.Get(ctx, k8stypes.NamespacedName{Name: envArgs.Namespace}, &corev1.Namespace{}); err != nil {
-		if !apierrors.IsNotFound(err) {
-			return message, err
-		}
-		// Create Namespace if not found
-		if err := c.Create(ctx, &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: envArgs.Namespace}}); err != nil {
-			return message, err
-		}
-	}
-
-	data, err := json.Marshal(envArgs)
-	if err != nil {
+	if err := createNamespaceIfNotExist(ctx, c, envArgs.Namespace); err != nil {
 		return message, err
 	}
-	envdir, err := system.GetEnvDir()
-	if err != nil {
+	envArgs.Name = envName
+	if err := applyEnvironment(ctx, c, envArgs); err != nil {
 		return message, err
 	}
-	subEnvDir := filepath.Join(envdir, envName)
-	if _, err = system.CreateIfNotExist(subEnvDir); err != nil {
-		return message, err
-	}
-	// nolint:gosec
-	if err = ioutil.WriteFile(filepath.Join(subEnvDir, system.EnvConfigName), data, 0644); err != nil {
+	if err := saveEnvCache(envArgs); err != nil {
 		return message, err
 	}
 	curEnvPath, err := system.GetCurrentEnvPath()
@@ -127,84 +154,94 @@ func CreateOrUpdateEnv(ctx context.Context, c client.Client, envName string, env
 
 // CreateEnv will only create. If env already exists, return error
 func CreateEnv(ctx context.Context, c client.Client, envName string, envArgs *types.EnvMeta) (string, error) {
-	_, err := GetEnvByName(envName)
+	err := c.Get(ctx, k8stypes.NamespacedName{Name: envName}, &v1beta1.Environment{})
 	if err == nil {
 		message := fmt.Sprintf("Env %s already exist", envName)
 		return message, errors.New(message)
 	}
+	if !apierrors.IsNotFound(err) {
+		return err.Error(), err
+	}
 	return CreateOrUpdateEnv(ctx, c, envName, envArgs)
 }
 
 // UpdateEnv will update Env, if env does not exist, return error
 func UpdateEnv(ctx context.Context, c client.Client, envName string, namespace string) (string, error) {
 	var message = ""
-	envMeta, err := GetEnvByName(envName)
+	envMeta, err := GetEnvFromCluster(ctx, c, envName)
 	if err != nil {
 		return err.Error(), err
 	}
-	if err := c.Create(ctx, &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: envMeta.Namespace}}); err != nil && !apierrors.IsAlreadyExists(err) {
+	if err := createNamespaceIfNotExist(ctx, c, namespace); err != nil {
 		return message, err
 	}
 	envMeta.Namespace = namespace
-	data, err := json.Marshal(envMeta)
-	if err != nil {
+	if err := applyEnvironment(ctx, c, envMeta); err != nil {
 		return message, err
 	}
-	envdir, err := system.GetEnvDir()
-	if err != nil {
-		return message, err
-	}
-	subEnvDir := filepath.Join(envdir, envName)
-	// nolint:gosec
-	if err = ioutil.WriteFile(filepath.Join(subEnvDir, system.EnvConfigName), data, 0644); err != nil {
-		return message, err
+	if _, err := GetEnvByName(envName); err == nil {
+		if err := saveEnvCache(envMeta); err != nil {
+			return message, err
+		}
 	}
 	message = "Update env succeed"
 	return message, err
 }
 
-// ListEnvs will list all envs
-func ListEnvs(envName string) ([]*types.EnvMeta, error) {
+// ListEnvs will list all envs from cluster
+func ListEnvs(ctx context.Context, c client.Reader, envName string) ([]*types.EnvMeta, error) {
 	var envList []*types.EnvMeta
 	if envName != "" {
-		env, err := GetEnvByName(envName)
+		env, err := GetEnvFromCluster(ctx, c, envName)
 		if err != nil {
-			if os.IsNotExist(err) {
-				err = fmt.Errorf("env %s not exist", envName)
+			cached, cacheErr := GetEnvByName(envName)
+			if cacheErr != nil {
+				return envList, err
 			}
-			return envList, err
+			env = cached
 		}
 		envList = append(envList, env)
-		return envList, err
+		return envList, nil
 	}
-	envDir, err := system.GetEnvDir()
-	if err != nil {
-		return envList, err
-	}
-	files, err := ioutil.ReadDir(envDir)
-	if err != nil {
+	var environments v1beta1.EnvironmentList
+	if err := c.List(ctx, &environments); err != nil && !meta.IsNoMatchError(err) {
 		return envList, err
 	}
 	curEnv, err := GetCurrentEnvName()
 	if err != nil {
 		curEnv = types.DefaultEnvName
 	}
-	for _, f := range files {
-		if !f.IsDir() {
-			continue
-		}
-		data, err := ioutil.ReadFile(filepath.Clean(filepath.Join(envDir, f.Name(), system.EnvConfigName)))
-		if err != nil {
-			continue
+	listed := make(map[string]bool)
+	for i := range environments.Items {
+		envMeta := ToEnvMeta(&environments.Items[i])
+		listed[envMeta.Name] = true
+		envList = append(envList, envMeta)
+	}
+	// the envs only cached locally, e.g. created before envs were stored in cluster, are still usable
+	cached, err := listEnvCache()
+	if err != nil {
+		return envList, err
+	}
+	for _, envMeta := range cached {
+		if !listed[envMeta.Name] {
+			listed[envMeta.Name] = true
+			envList = append(envList, envMeta)
 		}
-		var envMeta types.EnvMeta
-		if err = json.Unmarshal(data, &envMeta); err != nil {
-			continue
+	}
+	if !listed[types.DefaultEnvName] {
+		envList = append(envList, defaultEnvMeta())
+	}
+	// the default env goes first, the others are sorted by name
+	sort.SliceStable(envList, func(i, j int) bool {
+		if envList[i].Name == types.DefaultEnvName || envList[j].Name == types.DefaultEnvName {
+			return envList[i].Name == types.DefaultEnvName && envList[j].Name != types.DefaultEnvName
 		}
-		if curEnv == f.Name() {
+		return envList[i].Name < envList[j].Name
+	})
+	for _, envMeta := range envList {
+		if curEnv == envMeta.Name {
 			envMeta.Current = "*"
 		}
-		envList = append(envList, &envMeta)
 	}
 	return envList, nil
 }
@@ -222,8 +259,8 @@ func GetCurrentEnvName() (string, error) {
 	return string(data), nil
 }
 
-// DeleteEnv will delete env locally
-func DeleteEnv(envName string) (string, error) {
+// DeleteEnv will delete env from cluster and the local cache
+func DeleteEnv(ctx context.Context, c client.Client, envName string) (string, error) {
 	var message string
 	var err error
 	curEnv, err := GetCurrentEnvName()
@@ -234,35 +271,40 @@ func DeleteEnv(envName string) (string, error) {
 		err = fmt.Errorf("you can't delete current using environment %s", curEnv)
 		return message, err
 	}
+	e := &v1beta1.Environment{ObjectMeta: metav1.ObjectMeta{Name: envName}}
+	if err = c.Delete(ctx, e); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return message, err
+		}
+		if _, cacheErr := GetEnvByName(envName); cacheErr != nil {
+			return message, fmt.Errorf("%s does not exist", envName)
+		}
+	}
 	envdir, err := system.GetEnvDir()
 	if err != nil {
 		return message, err
 	}
-	envPath := filepath.Join(envdir, envName)
-	if _, err := os.Stat(envPath); err != nil {
-		if os.IsNotExist(err) {
-			err = fmt.Errorf("%s does not exist", envName)
-			return message, err
-		}
-	}
-	if err = os.RemoveAll(envPath); err != nil {
+	if err = os.RemoveAll(filepath.Join(envdir, envName)); err != nil {
 		return message, err
 	}
 	message = envName + " deleted"
 	return message, err
 }
 
-// SetEnv will set the current env to the specified one
-func SetEnv(envName string) (string, error) {
+// SetEnv will set the current env to the specified one and refresh its local cache from cluster
+func SetEnv(ctx context.Context, c client.Reader, envName string) (string, error) {
 	var msg string
 	currentEnvPath, err := system.GetCurrentEnvPath()
 	if err != nil {
 		return msg, err
 	}
-	envMeta, err := GetEnvByName(envName)
+	envMeta, err := GetEnvFromCluster(ctx, c, envName)
 	if err != nil {
 		return msg, err
 	}
+	if err = saveEnvCache(envMeta); err != nil {
+		return msg, err
+	}
 	//nolint:gosec
 	if err = ioutil.WriteFile(currentEnvPath, []byte(envName), 0644); err != nil {
 		return msg, err
@@ -270,3 +312,82 @@ func SetEnv(envName string) (string, error) {
 	msg = fmt.Sprintf("Set environment succeed, current environment is " + envName + ", namespace is " + envMeta.Namespace)
 	return msg, nil
 }
+
+func createNamespaceIfNotExist(ctx context.Context, c client.Client, namespace string) error {
+	if err := c.Get(ctx, k8stypes.NamespacedName{Name: namespace}, &corev1.Namespace{}); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return err
+		}
+		if err := c.Create(ctx, &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: namespace}}); err != nil && !apierrors.IsAlreadyExists(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+// applyEnvironment creates or updates the Environment in cluster, the patches of an existing Environment are kept
+func applyEnvironment(ctx context.Context, c client.Client, envMeta *types.EnvMeta) error {
+	e := new(v1beta1.Environment)
+	err := c.Get(ctx, k8stypes.NamespacedName{Name: envMeta.Name}, e)
+	if err != nil && !apierrors.IsNotFound(err) {
+		return err
+	}
+	exist := err == nil
+	e.SetGroupVersionKind(v1beta1.EnvironmentKindVersionKind)
+	e.SetName(envMeta.Name)
+	e.Spec.Namespace = envMeta.Namespace
+	e.Spec.Email = envMeta.Email
+	e.Spec.Domain = envMeta.Domain
+	e.Spec.Clusters = envMeta.Clusters
+	if exist {
+		return c.Update(ctx, e)
+	}
+	return c.Create(ctx, e)
+}
+
+// listEnvCache lists the envs cached locally
+func listEnvCache() ([]*types.EnvMeta, error) {
+	envDir, err := system.GetEnvDir()
+	if err != nil {
+		return nil, err
+	}
+	files, err := ioutil.ReadDir(envDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var envList []*types.EnvMeta
+	for _, f := range files {
+		if !f.IsDir() {
+			continue
+		}
+		envMeta, err := GetEnvByName(f.Name())
+		if err != nil {
+			continue
+		}
+		envMeta.Current = ""
+		envList = append(envList, envMeta)
+	}
+	return envList, nil
+}
+
+func saveEnvCache(envMeta *types.EnvMeta) error {
+	cache := *envMeta
+	cache.Current = ""
+	data, err := json.Marshal(cache)
+	if err != nil {
+		return err
+	}
+	envdir, err := system.GetEnvDir()
+	if err != nil {
+		return err
+	}
+	subEnvDir := filepath.Join(envdir, envMeta.Name)
+	if _, err = system.CreateIfNotExist(subEnvDir); err != nil {
+		return err
+	}
+	// nolint:gosec
+	return ioutil.WriteFile(filepath.Join(subEnvDir, system.EnvConfigName), data, 0644)
+}
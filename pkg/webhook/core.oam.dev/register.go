@@ -32,6 +32,7 @@ import (
 // Register will be called in main and register all validation handlers
 func Register(mgr manager.Manager, args controller.Args) {
 	application.RegisterValidatingHandler(mgr, args)
+	application.RegisterMutatingHandler(mgr)
 	applicationconfiguration.RegisterValidatingHandler(mgr, args)
 	componentdefinition.RegisterMutatingHandler(mgr, args)
 	componentdefinition.RegisterValidatingHandler(mgr, args)
@@ -0,0 +1,136 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package application
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	admissionv1beta1 "k8s.io/api/admission/v1beta1"
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
+	"github.com/oam-dev/kubevela/pkg/deploymentwindow"
+	"github.com/oam-dev/kubevela/pkg/oam"
+	"github.com/oam-dev/kubevela/pkg/oam/util"
+)
+
+var _ admission.Handler = &MutatingHandler{}
+
+// MutatingHandler records who changed the spec of application and when, they're recorded in the application revision.
+// It also records who overrides the deployment windows of application.
+type MutatingHandler struct {
+	// Decoder decodes objects
+	Decoder *admission.Decoder
+}
+
+var _ admission.DecoderInjector = &MutatingHandler{}
+
+// InjectDecoder injects the decoder into the MutatingHandler
+func (h *MutatingHandler) InjectDecoder(d *admission.Decoder) error {
+	if h.Decoder != nil {
+		return nil
+	}
+	h.Decoder = d
+	return nil
+}
+
+// Handle records the requesting user of the application whose spec is created or changed, and the user who
+// overrides its deployment windows
+func (h *MutatingHandler) Handle(ctx context.Context, req admission.Request) admission.Response {
+	app := &v1beta1.Application{}
+	if err := h.Decoder.Decode(req, app); err != nil {
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+	// the old application is empty on creation
+	oldApp := &v1beta1.Application{}
+	if req.Operation == admissionv1beta1.Update {
+		if err := h.Decoder.DecodeRaw(req.AdmissionRequest.OldObject, oldApp); err != nil {
+			return admission.Errored(http.StatusBadRequest, err)
+		}
+	}
+	// patch the raw object rather than the decoded one, so that only annotations are changed
+	obj := &unstructured.Unstructured{}
+	if err := obj.UnmarshalJSON(req.AdmissionRequest.Object.Raw); err != nil {
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+	if req.Operation != admissionv1beta1.Update || !apiequality.Semantic.DeepEqual(app.Spec, oldApp.Spec) {
+		recordChange(obj, oldApp, req.UserInfo.Username, time.Now())
+	} else {
+		restoreChange(obj, oldApp)
+	}
+	deploymentwindow.RecordOverride(obj, oldApp, req.UserInfo.Username)
+	marshalled, err := obj.MarshalJSON()
+	if err != nil {
+		return admission.Errored(http.StatusInternalServerError, err)
+	}
+	return admission.PatchResponseFromRaw(req.AdmissionRequest.Object.Raw, marshalled)
+}
+
+// recordChange sets the user and time of the change to annotations of application. The change message is only kept
+// if it's set along with the change, a message left from an earlier change doesn't describe this one.
+func recordChange(obj *unstructured.Unstructured, oldObj metav1.Object, user string, t time.Time) {
+	annotations := obj.GetAnnotations()
+	if message, ok := annotations[oam.AnnotationChangeMessage]; ok {
+		if oldMessage, ok := oldObj.GetAnnotations()[oam.AnnotationChangeMessage]; ok && oldMessage == message {
+			delete(annotations, oam.AnnotationChangeMessage)
+			obj.SetAnnotations(annotations)
+		}
+	}
+	util.AddAnnotations(obj, map[string]string{
+		oam.AnnotationChangedBy: user,
+		oam.AnnotationChangedAt: t.UTC().Format(time.RFC3339),
+	})
+}
+
+// restoreChange keeps the user and time of the last change when the spec is not changed, they can only be set
+// by the webhook so that the user recorded in the application revision can't be forged
+func restoreChange(obj *unstructured.Unstructured, oldObj metav1.Object) {
+	annotations := obj.GetAnnotations()
+	oldAnnotations := oldObj.GetAnnotations()
+	var changed bool
+	for _, key := range []string{oam.AnnotationChangedBy, oam.AnnotationChangedAt} {
+		value, ok := annotations[key]
+		oldValue, oldOK := oldAnnotations[key]
+		switch {
+		case oldOK && (!ok || value != oldValue):
+			if annotations == nil {
+				annotations = map[string]string{}
+			}
+			annotations[key] = oldValue
+			changed = true
+		case !oldOK && ok:
+			delete(annotations, key)
+			changed = true
+		}
+	}
+	if changed {
+		obj.SetAnnotations(annotations)
+	}
+}
+
+// RegisterMutatingHandler will register application mutation handler to the webhook
+func RegisterMutatingHandler(mgr manager.Manager) {
+	server := mgr.GetWebhookServer()
+	server.Register("/mutating-core-oam-dev-v1beta1-applications", &webhook.Admission{Handler: &MutatingHandler{}})
+}
@@ -0,0 +1,180 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package application
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	admissionv1beta1 "k8s.io/api/admission/v1beta1"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+var _ = Describe("Test Application Mutator", func() {
+	var mutatingHandler *MutatingHandler
+	app := func(image string) runtime.RawExtension {
+		return runtime.RawExtension{Raw: []byte(`{"apiVersion":"core.oam.dev/v1beta1","kind":"Application",
+"metadata":{"name":"application-sample"},
+"spec":{"components":[{"name":"web","type":"webservice","properties":{"image":"` + image + `"}}]}}`)}
+	}
+
+	BeforeEach(func() {
+		mutatingHandler = &MutatingHandler{}
+		Expect(mutatingHandler.InjectDecoder(decoder)).Should(BeNil())
+	})
+
+	It("Test recording the user who creates application", func() {
+		resp := mutatingHandler.Handle(ctx, admission.Request{AdmissionRequest: admissionv1beta1.AdmissionRequest{
+			Operation: admissionv1beta1.Create,
+			UserInfo:  authenticationv1.UserInfo{Username: "alice"},
+			Object:    app("nginx"),
+		}})
+		Expect(resp.Allowed).Should(BeTrue())
+		Expect(resp.Patches).Should(HaveLen(1))
+		Expect(resp.Patches[0].Path).Should(Equal("/metadata/annotations"))
+		annotations := resp.Patches[0].Value.(map[string]interface{})
+		Expect(annotations["app.oam.dev/changed-by"]).Should(Equal("alice"))
+		Expect(annotations["app.oam.dev/changed-at"]).ShouldNot(BeEmpty())
+	})
+
+	It("Test recording the user who changes the spec of application", func() {
+		resp := mutatingHandler.Handle(ctx, admission.Request{AdmissionRequest: admissionv1beta1.AdmissionRequest{
+			Operation: admissionv1beta1.Update,
+			UserInfo:  authenticationv1.UserInfo{Username: "bob"},
+			Object:    app("nginx:1.21"),
+			OldObject: app("nginx:1.20"),
+		}})
+		Expect(resp.Allowed).Should(BeTrue())
+		Expect(resp.Patches).Should(HaveLen(1))
+		Expect(resp.Patches[0].Value.(map[string]interface{})["app.oam.dev/changed-by"]).Should(Equal("bob"))
+
+		By("Spec is not changed")
+		resp = mutatingHandler.Handle(ctx, admission.Request{AdmissionRequest: admissionv1beta1.AdmissionRequest{
+			Operation: admissionv1beta1.Update,
+			UserInfo:  authenticationv1.UserInfo{Username: "system:serviceaccount:vela-system:kubevela"},
+			Object:    app("nginx:1.21"),
+			OldObject: app("nginx:1.21"),
+		}})
+		Expect(resp.Allowed).Should(BeTrue())
+		Expect(resp.Patches).Should(BeEmpty())
+	})
+
+	It("Test restoring the forged user of the last change", func() {
+		changedBy := func(image, user string) runtime.RawExtension {
+			return runtime.RawExtension{Raw: []byte(`{"apiVersion":"core.oam.dev/v1beta1","kind":"Application",
+"metadata":{"name":"application-sample","annotations":{"app.oam.dev/changed-by":"` + user + `"}},
+"spec":{"components":[{"name":"web","type":"webservice","properties":{"image":"` + image + `"}}]}}`)}
+		}
+		resp := mutatingHandler.Handle(ctx, admission.Request{AdmissionRequest: admissionv1beta1.AdmissionRequest{
+			Operation: admissionv1beta1.Update,
+			UserInfo:  authenticationv1.UserInfo{Username: "mallory"},
+			Object:    changedBy("nginx:1.21", "alice"),
+			OldObject: changedBy("nginx:1.21", "bob"),
+		}})
+		Expect(resp.Allowed).Should(BeTrue())
+		Expect(resp.Patches).Should(HaveLen(1))
+		Expect(resp.Patches[0].Path).Should(Equal("/metadata/annotations/app.oam.dev~1changed-by"))
+		Expect(resp.Patches[0].Value).Should(Equal("bob"))
+
+		By("The user is set without a change")
+		resp = mutatingHandler.Handle(ctx, admission.Request{AdmissionRequest: admissionv1beta1.AdmissionRequest{
+			Operation: admissionv1beta1.Update,
+			UserInfo:  authenticationv1.UserInfo{Username: "mallory"},
+			Object:    changedBy("nginx:1.21", "alice"),
+			OldObject: app("nginx:1.21"),
+		}})
+		Expect(resp.Allowed).Should(BeTrue())
+		Expect(resp.Patches).Should(HaveLen(1))
+		Expect(resp.Patches[0].Operation).Should(Equal("remove"))
+	})
+
+	It("Test clearing the change message left from an earlier change", func() {
+		withMessage := func(image, message string) runtime.RawExtension {
+			return runtime.RawExtension{Raw: []byte(`{"apiVersion":"core.oam.dev/v1beta1","kind":"Application",
+"metadata":{"name":"application-sample","annotations":{"app.oam.dev/change-message":"` + message + `"}},
+"spec":{"components":[{"name":"web","type":"webservice","properties":{"image":"` + image + `"}}]}}`)}
+		}
+		resp := mutatingHandler.Handle(ctx, admission.Request{AdmissionRequest: admissionv1beta1.AdmissionRequest{
+			Operation: admissionv1beta1.Update,
+			UserInfo:  authenticationv1.UserInfo{Username: "alice"},
+			Object:    withMessage("nginx:1.20", "rollback to revision application-sample-v1"),
+			OldObject: app("nginx:1.21"),
+		}})
+		Expect(resp.Allowed).Should(BeTrue())
+		for _, patch := range resp.Patches {
+			Expect(patch.Path).ShouldNot(Equal("/metadata/annotations/app.oam.dev~1change-message"))
+		}
+
+		By("The spec is changed again without a new message")
+		resp = mutatingHandler.Handle(ctx, admission.Request{AdmissionRequest: admissionv1beta1.AdmissionRequest{
+			Operation: admissionv1beta1.Update,
+			UserInfo:  authenticationv1.UserInfo{Username: "bob"},
+			Object:    withMessage("nginx:1.22", "rollback to revision application-sample-v1"),
+			OldObject: withMessage("nginx:1.20", "rollback to revision application-sample-v1"),
+		}})
+		Expect(resp.Allowed).Should(BeTrue())
+		var removed bool
+		for _, patch := range resp.Patches {
+			if patch.Operation == "remove" && patch.Path == "/metadata/annotations/app.oam.dev~1change-message" {
+				removed = true
+			}
+		}
+		Expect(removed).Should(BeTrue())
+	})
+
+	It("Test recording the user who overrides the deployment windows of application", func() {
+		overridden := func(until, by string) runtime.RawExtension {
+			annotations := `{"app.oam.dev/deployment-window-override":"` + until + `"`
+			if by != "" {
+				annotations += `,"app.oam.dev/deployment-window-override-by":"` + by + `"`
+			}
+			return runtime.RawExtension{Raw: []byte(`{"apiVersion":"core.oam.dev/v1beta1","kind":"Application",
+"metadata":{"name":"application-sample","annotations":` + annotations + `}},
+"spec":{"components":[{"name":"web","type":"webservice","properties":{"image":"nginx"}}]}}`)}
+		}
+		resp := mutatingHandler.Handle(ctx, admission.Request{AdmissionRequest: admissionv1beta1.AdmissionRequest{
+			Operation: admissionv1beta1.Update,
+			UserInfo:  authenticationv1.UserInfo{Username: "alice"},
+			Object:    overridden("2021-06-05T00:00:00Z", ""),
+			OldObject: app("nginx"),
+		}})
+		Expect(resp.Allowed).Should(BeTrue())
+		Expect(resp.Patches).Should(HaveLen(1))
+		Expect(resp.Patches[0].Path).Should(Equal("/metadata/annotations/app.oam.dev~1deployment-window-override-by"))
+		Expect(resp.Patches[0].Value).Should(Equal("alice"))
+
+		By("The user who overrides cannot be forged")
+		resp = mutatingHandler.Handle(ctx, admission.Request{AdmissionRequest: admissionv1beta1.AdmissionRequest{
+			Operation: admissionv1beta1.Update,
+			UserInfo:  authenticationv1.UserInfo{Username: "bob"},
+			Object:    overridden("2021-06-05T00:00:00Z", "carol"),
+			OldObject: overridden("2021-06-05T00:00:00Z", "alice"),
+		}})
+		Expect(resp.Allowed).Should(BeTrue())
+		Expect(resp.Patches).Should(HaveLen(1))
+		Expect(resp.Patches[0].Value).Should(Equal("alice"))
+	})
+
+	It("Test bad request", func() {
+		resp := mutatingHandler.Handle(ctx, admission.Request{AdmissionRequest: admissionv1beta1.AdmissionRequest{
+			Operation: admissionv1beta1.Create,
+			Object:    runtime.RawExtension{Raw: []byte("bad request")},
+		}})
+		Expect(resp.Allowed).Should(BeFalse())
+	})
+})
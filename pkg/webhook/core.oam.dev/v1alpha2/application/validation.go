@@ -40,7 +40,11 @@ func (h *ValidatingHandler) ValidateCreate(ctx context.Context, app *v1beta1.App
 		return componentErrs
 	}
 	if err := appParser.ValidateCUESchematicAppfile(af); err != nil {
-		componentErrs = append(componentErrs, field.Invalid(field.NewPath("schematic"), app, err.Error()))
+		if propertyErrs := appfile.PropertyErrors(err); len(propertyErrs) > 0 {
+			componentErrs = append(componentErrs, propertyErrs...)
+		} else {
+			componentErrs = append(componentErrs, field.Invalid(field.NewPath("schematic"), app, err.Error()))
+		}
 	}
 	if v := app.GetAnnotations()[oam.AnnotationAppRollout]; len(v) != 0 && v != "true" {
 		componentErrs = append(componentErrs, field.Invalid(field.NewPath("annotation:app.oam.dev/rollout-template"), app, "the annotation value of rollout-template must be true"))
@@ -48,9 +52,28 @@ func (h *ValidatingHandler) ValidateCreate(ctx context.Context, app *v1beta1.App
 	if app.Spec.RolloutPlan != nil {
 		componentErrs = append(componentErrs, rollout.ValidateCreate(h.Client, app.Spec.RolloutPlan, field.NewPath("rolloutPlan"))...)
 	}
+	if app.Spec.Health != nil {
+		componentErrs = append(componentErrs, validateReadinessGates(app.Spec.Health.ReadinessGates, field.NewPath("spec", "health", "readinessGates"))...)
+	}
 	return componentErrs
 }
 
+// validateReadinessGates validates the names of readiness gates are unique and each of them is checked in one way
+func validateReadinessGates(gates []v1beta1.ReadinessGate, path *field.Path) field.ErrorList {
+	var errs field.ErrorList
+	names := map[string]bool{}
+	for i, gate := range gates {
+		if names[gate.Name] {
+			errs = append(errs, field.Duplicate(path.Index(i).Child("name"), gate.Name))
+		}
+		names[gate.Name] = true
+		if gate.ConditionType != "" && gate.HealthPolicy != "" {
+			errs = append(errs, field.Invalid(path.Index(i), gate.Name, "only one of conditionType and healthPolicy can be specified"))
+		}
+	}
+	return errs
+}
+
 // ValidateUpdate validates the Application on update
 func (h *ValidatingHandler) ValidateUpdate(ctx context.Context, newApp, oldApp *v1beta1.Application) field.ErrorList {
 	// check if the newApp is valid
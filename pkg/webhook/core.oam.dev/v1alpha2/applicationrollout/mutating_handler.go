@@ -20,7 +20,10 @@ import (
 	"context"
 	"encoding/json"
 	"net/http"
+	"time"
 
+	admissionv1beta1 "k8s.io/api/admission/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/klog/v2"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
@@ -29,7 +32,11 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
 
 	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
+	"github.com/oam-dev/kubevela/apis/standard.oam.dev/v1alpha1"
 	"github.com/oam-dev/kubevela/pkg/controller/common"
+	"github.com/oam-dev/kubevela/pkg/deploymentwindow"
+	"github.com/oam-dev/kubevela/pkg/oam"
+	oamutil "github.com/oam-dev/kubevela/pkg/oam/util"
 	util "github.com/oam-dev/kubevela/pkg/utils"
 	"github.com/oam-dev/kubevela/pkg/webhook/common/rollout"
 )
@@ -53,6 +60,15 @@ func (h *MutatingHandler) Handle(ctx context.Context, req admission.Request) adm
 		return admission.Errored(http.StatusBadRequest, err)
 	}
 	DefaultAppRollout(obj)
+	// the old object is empty on creation
+	oldObj := &v1beta1.AppRollout{}
+	if req.Operation == admissionv1beta1.Update {
+		if err := h.Decoder.DecodeRaw(req.AdmissionRequest.OldObject, oldObj); err != nil {
+			return admission.Errored(http.StatusBadRequest, err)
+		}
+	}
+	RecordBatchApproval(obj, oldObj, req.UserInfo.Username, time.Now())
+	deploymentwindow.RecordOverride(obj, oldObj, req.UserInfo.Username)
 
 	marshalled, err := json.Marshal(obj)
 	if err != nil {
@@ -77,6 +93,65 @@ func DefaultAppRollout(obj *v1beta1.AppRollout) {
 	rollout.DefaultRolloutPlan(&obj.Spec.RolloutPlan)
 }
 
+// RecordBatchApproval records the user who advanced the batch partition in the annotation of AppRollout,
+// the rollout controller records it as the approver of batches requiring approval. The recorded approval is kept
+// if the batch partition isn't advanced so that it cannot be forged. oldObj is empty on creation.
+func RecordBatchApproval(obj, oldObj *v1beta1.AppRollout, user string, now time.Time) {
+	approved, advanced := advancedPartition(obj.Spec.RolloutPlan, oldObj.Spec.RolloutPlan)
+	if !advanced {
+		restoreBatchApproval(obj, oldObj)
+		return
+	}
+	approval, err := json.Marshal(v1alpha1.BatchApproval{Batch: approved, Approver: user, Time: metav1.NewTime(now)})
+	if err != nil {
+		klog.ErrorS(err, "failed to record the batch approval", "name", obj.Name)
+		return
+	}
+	oamutil.AddAnnotations(obj, map[string]string{oam.AnnotationBatchApproval: string(approval)})
+}
+
+// advancedPartition returns the batch partition of plan and whether it's advanced from the old plan
+func advancedPartition(plan, oldPlan v1alpha1.RolloutPlan) (int32, bool) {
+	if plan.BatchPartition == nil && oldPlan.BatchPartition == nil {
+		return 0, false
+	}
+	lastBatch := int32(len(plan.RolloutBatches) - 1)
+	approved := lastBatch
+	if plan.BatchPartition != nil {
+		approved = *plan.BatchPartition
+	}
+	oldApproved := lastBatch
+	if oldPlan.BatchPartition != nil {
+		oldApproved = *oldPlan.BatchPartition
+	} else if requireApproval(oldPlan) {
+		// no batch requiring approval is approved without the batch partition
+		oldApproved = -1
+	}
+	return approved, approved > oldApproved
+}
+
+// restoreBatchApproval reverts the batch approval of AppRollout to the one recorded in the old object
+func restoreBatchApproval(obj, oldObj *v1beta1.AppRollout) {
+	if oldApproval, ok := oldObj.GetAnnotations()[oam.AnnotationBatchApproval]; ok {
+		oamutil.AddAnnotations(obj, map[string]string{oam.AnnotationBatchApproval: oldApproval})
+		return
+	}
+	annotations := obj.GetAnnotations()
+	if _, ok := annotations[oam.AnnotationBatchApproval]; ok {
+		delete(annotations, oam.AnnotationBatchApproval)
+		obj.SetAnnotations(annotations)
+	}
+}
+
+func requireApproval(plan v1alpha1.RolloutPlan) bool {
+	for _, batch := range plan.RolloutBatches {
+		if batch.RequireApproval {
+			return true
+		}
+	}
+	return false
+}
+
 var _ inject.Client = &MutatingHandler{}
 
 // InjectClient injects the client into the MutatingHandler
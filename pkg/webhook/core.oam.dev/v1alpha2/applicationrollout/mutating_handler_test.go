@@ -0,0 +1,119 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package applicationrollout
+
+import (
+	"encoding/json"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/utils/pointer"
+
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
+	"github.com/oam-dev/kubevela/apis/standard.oam.dev/v1alpha1"
+	"github.com/oam-dev/kubevela/pkg/oam"
+)
+
+var _ = Describe("Test record batch approval", func() {
+	now := time.Date(2021, 5, 1, 0, 0, 0, 0, time.UTC)
+
+	newRollout := func(partition *int32, requireApproval ...bool) *v1beta1.AppRollout {
+		rollout := &v1beta1.AppRollout{}
+		rollout.Spec.RolloutPlan.BatchPartition = partition
+		for _, require := range requireApproval {
+			rollout.Spec.RolloutPlan.RolloutBatches = append(rollout.Spec.RolloutPlan.RolloutBatches,
+				v1alpha1.RolloutBatch{Replicas: intstr.FromInt(1), RequireApproval: require})
+		}
+		return rollout
+	}
+
+	approvalOf := func(rollout *v1beta1.AppRollout) *v1alpha1.BatchApproval {
+		record, ok := rollout.GetAnnotations()[oam.AnnotationBatchApproval]
+		if !ok {
+			return nil
+		}
+		approval := &v1alpha1.BatchApproval{}
+		Expect(json.Unmarshal([]byte(record), approval)).Should(Succeed())
+		return approval
+	}
+
+	It("Test advancing the batch partition", func() {
+		obj := newRollout(pointer.Int32Ptr(1), false, false, false)
+		RecordBatchApproval(obj, newRollout(pointer.Int32Ptr(0), false, false, false), "alice", now)
+		approval := approvalOf(obj)
+		Expect(approval).ShouldNot(BeNil())
+		Expect(approval.Batch).Should(BeEquivalentTo(1))
+		Expect(approval.Approver).Should(Equal("alice"))
+		Expect(approval.Time.Time.Equal(now)).Should(BeTrue())
+	})
+
+	It("Test removing the batch partition of a plan requiring approval", func() {
+		obj := newRollout(nil, false, true, true)
+		RecordBatchApproval(obj, newRollout(pointer.Int32Ptr(0), false, true, true), "bob", now)
+		Expect(approvalOf(obj)).ShouldNot(BeNil())
+		Expect(approvalOf(obj).Batch).Should(BeEquivalentTo(2))
+	})
+
+	It("Test no approval", func() {
+		obj := newRollout(pointer.Int32Ptr(1), false, false, false)
+		RecordBatchApproval(obj, newRollout(pointer.Int32Ptr(1), false, false, false), "alice", now)
+		Expect(approvalOf(obj)).Should(BeNil())
+
+		obj = newRollout(nil, false, true)
+		RecordBatchApproval(obj, newRollout(nil, false, true), "alice", now)
+		Expect(approvalOf(obj)).Should(BeNil())
+
+		obj = newRollout(pointer.Int32Ptr(0), false, false)
+		RecordBatchApproval(obj, newRollout(nil, false, false), "alice", now)
+		Expect(approvalOf(obj)).Should(BeNil())
+	})
+
+	It("Test forged approvals are dropped", func() {
+		forged := `{"batch":2,"approver":"carol","time":"2021-05-01T00:00:00Z"}`
+		By("Creating an AppRollout with an approval")
+		obj := newRollout(pointer.Int32Ptr(2), true, true, true)
+		obj.SetAnnotations(map[string]string{oam.AnnotationBatchApproval: forged})
+		RecordBatchApproval(obj, &v1beta1.AppRollout{}, "bob", now)
+		Expect(approvalOf(obj)).Should(BeNil())
+
+		By("Changing the approval without advancing the batch partition")
+		oldObj := newRollout(pointer.Int32Ptr(1), true, true, true)
+		RecordBatchApproval(oldObj, newRollout(pointer.Int32Ptr(0), true, true, true), "alice", now)
+		obj = newRollout(pointer.Int32Ptr(1), true, true, true)
+		obj.SetAnnotations(map[string]string{oam.AnnotationBatchApproval: forged})
+		RecordBatchApproval(obj, oldObj, "bob", now)
+		Expect(approvalOf(obj)).Should(Equal(approvalOf(oldObj)))
+		Expect(approvalOf(obj).Approver).Should(Equal("alice"))
+	})
+})
+
+var _ = Describe("Test waiting for approval", func() {
+	It("Test batches requiring approval", func() {
+		plan := v1alpha1.RolloutPlan{RolloutBatches: []v1alpha1.RolloutBatch{
+			{Replicas: intstr.FromInt(1)}, {Replicas: intstr.FromInt(1), RequireApproval: true},
+		}}
+		Expect(plan.WaitingForApproval(0)).Should(BeFalse())
+		Expect(plan.WaitingForApproval(1)).Should(BeTrue())
+		Expect(plan.WaitingForApproval(2)).Should(BeFalse())
+		plan.BatchPartition = pointer.Int32Ptr(0)
+		Expect(plan.WaitingForApproval(1)).Should(BeTrue())
+		plan.BatchPartition = pointer.Int32Ptr(1)
+		Expect(plan.WaitingForApproval(1)).Should(BeFalse())
+	})
+})
@@ -20,19 +20,26 @@ import (
 	"context"
 	"fmt"
 	"net/http"
+	"strings"
 
 	admissionv1beta1 "k8s.io/api/admission/v1beta1"
+	"k8s.io/klog"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
 	"sigs.k8s.io/controller-runtime/pkg/webhook"
 	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
 
 	"github.com/oam-dev/kubevela/apis/core.oam.dev/common"
 	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
+	"github.com/oam-dev/kubevela/pkg/appfile"
 	controller "github.com/oam-dev/kubevela/pkg/controller/core.oam.dev"
+	"github.com/oam-dev/kubevela/pkg/dsl/definition"
 	"github.com/oam-dev/kubevela/pkg/oam/discoverymapper"
 	"github.com/oam-dev/kubevela/pkg/oam/util"
 )
 
+// lintWarningsAuditKey is the key of the audit annotation recording the lint warnings of a definition
+const lintWarningsAuditKey = "lint-warnings"
+
 var componentDefGVR = v1beta1.SchemeGroupVersion.WithResource("componentdefinitions")
 
 // ValidatingHandler handles validation of component definition
@@ -41,6 +48,8 @@ type ValidatingHandler struct {
 
 	// Decoder decodes object
 	Decoder *admission.Decoder
+	// PackageDiscover lints the CUE template of component definitions if it's not nil
+	PackageDiscover *definition.PackageDiscover
 }
 
 var _ admission.Handler = &ValidatingHandler{}
@@ -61,6 +70,14 @@ func (h *ValidatingHandler) Handle(ctx context.Context, req admission.Request) a
 		if err != nil {
 			return admission.Denied(err.Error())
 		}
+		if h.PackageDiscover != nil {
+			if warnings := LintDefinition(h.PackageDiscover, obj); len(warnings) > 0 {
+				klog.Warning("lint warnings ", " name: ", obj.Name, " warnings: ", strings.Join(warnings, "; "))
+				resp := admission.ValidationResponse(true, "")
+				resp.AuditAnnotations = map[string]string{lintWarningsAuditKey: strings.Join(warnings, "; ")}
+				return resp
+			}
+		}
 	}
 	return admission.ValidationResponse(true, "")
 }
@@ -76,9 +93,30 @@ func (h *ValidatingHandler) InjectDecoder(d *admission.Decoder) error {
 // RegisterValidatingHandler will register TraitDefinition validation to webhook
 func RegisterValidatingHandler(mgr manager.Manager, args controller.Args) {
 	server := mgr.GetWebhookServer()
-	server.Register("/validating-core-oam-dev-v1beta1-componentdefinitions", &webhook.Admission{Handler: &ValidatingHandler{
+	handler := &ValidatingHandler{
 		Mapper: args.DiscoveryMapper,
-	}})
+	}
+	if args.LintDefinitions {
+		handler.PackageDiscover = args.PackageDiscover
+	}
+	server.Register("/validating-core-oam-dev-v1beta1-componentdefinitions", &webhook.Admission{Handler: handler})
+}
+
+// LintDefinition lints the CUE template of the component definition
+func LintDefinition(pd *definition.PackageDiscover, cd *v1beta1.ComponentDefinition) []string {
+	capability, err := appfile.ConvertTemplateJSON2Object(cd.Name, cd.Spec.Extension, cd.Spec.Schematic)
+	if err != nil || capability.CueTemplate == "" {
+		return nil
+	}
+	issues, err := definition.LintTemplate(capability.CueTemplate, pd, nil)
+	if err != nil {
+		return []string{err.Error()}
+	}
+	var warnings []string
+	for _, issue := range issues {
+		warnings = append(warnings, issue.String())
+	}
+	return warnings
 }
 
 // ValidateWorkload validates whether the Workload field is valid
@@ -20,6 +20,7 @@ import (
 	"context"
 	"fmt"
 	"net/http"
+	"strings"
 
 	"github.com/pkg/errors"
 	admissionv1beta1 "k8s.io/api/admission/v1beta1"
@@ -33,6 +34,8 @@ import (
 	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
 	"github.com/oam-dev/kubevela/pkg/appfile"
 	controller "github.com/oam-dev/kubevela/pkg/controller/core.oam.dev"
+	"github.com/oam-dev/kubevela/pkg/dsl/definition"
+	"github.com/oam-dev/kubevela/pkg/oam"
 	"github.com/oam-dev/kubevela/pkg/oam/discoverymapper"
 )
 
@@ -40,6 +43,9 @@ const (
 	errValidateDefRef = "error occurs when validating definition reference"
 
 	failInfoDefRefOmitted = "if definition reference is omitted, patch or output with GVK is required"
+
+	// lintWarningsAuditKey is the key of the audit annotation recording the lint warnings of a definition
+	lintWarningsAuditKey = "lint-warnings"
 )
 
 var traitDefGVR = v1beta1.SchemeGroupVersion.WithResource("traitdefinitions")
@@ -53,6 +59,8 @@ type ValidatingHandler struct {
 	Decoder *admission.Decoder
 	// Validators validate objects
 	Validators []TraitDefValidator
+	// PackageDiscover lints the CUE template of trait definitions if it's not nil
+	PackageDiscover *definition.PackageDiscover
 }
 
 // TraitDefValidator validate trait definition
@@ -90,10 +98,46 @@ func (h *ValidatingHandler) Handle(ctx context.Context, req admission.Request) a
 			}
 		}
 		klog.Info("validation passed ", " name: ", obj.Name, " operation: ", string(req.Operation))
+		if h.PackageDiscover != nil {
+			if warnings := LintDefinition(ctx, h.Client, h.PackageDiscover, obj); len(warnings) > 0 {
+				klog.Warning("lint warnings ", " name: ", obj.Name, " warnings: ", strings.Join(warnings, "; "))
+				resp := admission.ValidationResponse(true, "")
+				resp.AuditAnnotations = map[string]string{lintWarningsAuditKey: strings.Join(warnings, "; ")}
+				return resp
+			}
+		}
 	}
 	return admission.ValidationResponse(true, "")
 }
 
+// LintDefinition lints the CUE template of the trait definition, its patch is checked against the workloads of the
+// component definitions in its namespace and the system namespace
+func LintDefinition(ctx context.Context, c client.Reader, pd *definition.PackageDiscover, td *v1beta1.TraitDefinition) []string {
+	capability, err := appfile.ConvertTemplateJSON2Object(td.Name, td.Spec.Extension, td.Spec.Schematic)
+	if err != nil || capability.CueTemplate == "" {
+		return nil
+	}
+	var cds []v1beta1.ComponentDefinition
+	for _, ns := range []string{oam.SystemDefinitonNamespace, td.Namespace} {
+		list := &v1beta1.ComponentDefinitionList{}
+		if err := c.List(ctx, list, client.InNamespace(ns)); err != nil {
+			klog.Info("failed to list component definitions for linting ", " namespace: ", ns, " errMsg: ", err.Error())
+			continue
+		}
+		cds = append(cds, list.Items...)
+	}
+	targets := pd.WorkloadKinds(td.Spec.AppliesToWorkloads, definition.ComponentWorkloadKinds(cds))
+	issues, err := definition.LintTemplate(capability.CueTemplate, pd, targets)
+	if err != nil {
+		return []string{err.Error()}
+	}
+	var warnings []string
+	for _, issue := range issues {
+		warnings = append(warnings, issue.String())
+	}
+	return warnings
+}
+
 var _ inject.Client = &ValidatingHandler{}
 
 // InjectClient injects the client into the ValidatingHandler
@@ -113,13 +157,17 @@ func (h *ValidatingHandler) InjectDecoder(d *admission.Decoder) error {
 // RegisterValidatingHandler will register TraitDefinition validation to webhook
 func RegisterValidatingHandler(mgr manager.Manager, args controller.Args) {
 	server := mgr.GetWebhookServer()
-	server.Register("/validating-core-oam-dev-v1alpha2-traitdefinitions", &webhook.Admission{Handler: &ValidatingHandler{
+	handler := &ValidatingHandler{
 		Mapper: args.DiscoveryMapper,
 		Validators: []TraitDefValidator{
 			TraitDefValidatorFn(ValidateDefinitionReference),
 			// add more validators here
 		},
-	}})
+	}
+	if args.LintDefinitions {
+		handler.PackageDiscover = args.PackageDiscover
+	}
+	server.Register("/validating-core-oam-dev-v1alpha2-traitdefinitions", &webhook.Admission{Handler: handler})
 }
 
 // ValidateDefinitionReference validates whether the trait definition is valid if
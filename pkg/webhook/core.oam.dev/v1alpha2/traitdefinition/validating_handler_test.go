@@ -30,9 +30,13 @@ import (
 	admissionv1beta1 "k8s.io/api/admission/v1beta1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
 
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/common"
 	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1alpha2"
+	"github.com/oam-dev/kubevela/pkg/dsl/definition"
+	"github.com/oam-dev/kubevela/pkg/oam"
 )
 
 var handler ValidatingHandler
@@ -137,5 +141,47 @@ var _ = Describe("Test TraitDefinition validating handler", func() {
 			Expect(resp.Allowed).Should(BeFalse())
 			Expect(resp.Result.Reason).Should(Equal(metav1.StatusReason("mock validator error")))
 		})
+		It("Test lint warnings", func() {
+			handler.Validators = nil
+			schema, err := definition.BundledOpenAPISchema()
+			Expect(err).Should(BeNil())
+			handler.PackageDiscover, err = definition.NewPackageDiscoverFromOpenAPI(schema)
+			Expect(err).Should(BeNil())
+			clientScheme := runtime.NewScheme()
+			Expect(v1beta1.SchemeBuilder.AddToScheme(clientScheme)).Should(Succeed())
+			handler.Client = fake.NewFakeClientWithScheme(clientScheme, &v1beta1.ComponentDefinition{
+				ObjectMeta: metav1.ObjectMeta{Name: "worker", Namespace: oam.SystemDefinitonNamespace},
+				Spec: v1beta1.ComponentDefinitionSpec{Workload: common.WorkloadTypeDescriptor{
+					Definition: common.WorkloadGVK{APIVersion: "apps/v1", Kind: "Deployment"}}},
+			})
+
+			scaler := v1beta1.TraitDefinition{
+				TypeMeta:   metav1.TypeMeta{APIVersion: v1beta1.SchemeGroupVersion.String(), Kind: v1beta1.TraitDefinitionKind},
+				ObjectMeta: metav1.ObjectMeta{Name: "scaler", Namespace: "default"},
+				Spec: v1beta1.TraitDefinitionSpec{
+					AppliesToWorkloads: []string{"worker"},
+					Schematic: &common.Schematic{CUE: &common.CUE{Template: `
+patch: spec: replica: parameter.replicas
+parameter: {
+	// +usage=The replicas
+	replicas: int
+}
+`}},
+				},
+			}
+			raw, err := json.Marshal(scaler)
+			Expect(err).Should(BeNil())
+			req = admission.Request{
+				AdmissionRequest: admissionv1beta1.AdmissionRequest{
+					Operation: admissionv1beta1.Create,
+					Resource:  reqResource,
+					Object:    runtime.RawExtension{Raw: raw},
+				},
+			}
+			resp := handler.Handle(context.TODO(), req)
+			Expect(resp.Allowed).Should(BeTrue())
+			Expect(resp.AuditAnnotations[lintWarningsAuditKey]).Should(Equal(
+				"patch.spec.replica: field doesn't exist in apps/v1 Deployment (invalid-patch)"))
+		})
 	})
 })
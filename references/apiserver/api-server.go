@@ -21,13 +21,25 @@ import (
 	"net/http"
 	"time"
 
-	"github.com/oam-dev/kubevela/pkg/utils/common"
-
+	"github.com/gin-gonic/gin"
 	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/client-go/rest"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
 
 	"github.com/oam-dev/kubevela/pkg/oam/discoverymapper"
+	"github.com/oam-dev/kubevela/pkg/utils/common"
+	"github.com/oam-dev/kubevela/pkg/utils/env"
+	"github.com/oam-dev/kubevela/references/apiserver/auth"
+	"github.com/oam-dev/kubevela/references/apiserver/util"
+)
+
+// keys to set/get the clients acting as the authenticated user in gin context
+const (
+	kubeClientKey = "kubeClient"
+	kubeArgsKey   = "kubeArgs"
 )
 
 // APIServer run a restful API server for dashboard
@@ -36,10 +48,14 @@ type APIServer struct {
 	KubeClient client.Client
 	dm         discoverymapper.DiscoveryMapper
 	c          common.Args
+
+	authn  auth.Authenticator
+	authz  *auth.Authorizer
+	mapper meta.RESTMapper
 }
 
-// New will create APIServer
-func New(c common.Args, port, staticPath string) (*APIServer, error) {
+// New will create APIServer, authentication is disabled if no authenticator is configured in authOptions
+func New(c common.Args, port, staticPath string, authOptions auth.Options) (*APIServer, error) {
 	newClient, err := c.GetClient()
 	if err != nil {
 		return nil, err
@@ -53,6 +69,9 @@ func New(c common.Args, port, staticPath string) (*APIServer, error) {
 		dm:         dm,
 		c:          c,
 	}
+	if err := s.setupAuth(authOptions); err != nil {
+		return nil, err
+	}
 	server := &http.Server{
 		Addr:         port,
 		Handler:      s.setupRoute(staticPath),
@@ -80,3 +99,78 @@ func (s *APIServer) Shutdown(ctx context.Context) error {
 	ctrl.Log.Info("sever shutting down")
 	return s.server.Shutdown(ctx)
 }
+
+func (s *APIServer) setupAuth(o auth.Options) error {
+	authn, err := auth.NewAuthenticator(o, s.KubeClient)
+	if err != nil {
+		return err
+	}
+	if authn == nil {
+		if o.PolicyFile != "" {
+			return errors.New("authorization policy requires at least one authenticator")
+		}
+		ctrl.Log.Info("authentication is disabled, all requests are served with the identity of the apiserver")
+		return nil
+	}
+	s.authn = authn
+	if o.PolicyFile != "" {
+		policy, err := auth.LoadPolicy(o.PolicyFile)
+		if err != nil {
+			return err
+		}
+		if s.authz, err = auth.NewAuthorizer(policy); err != nil {
+			return err
+		}
+	}
+	// the mapper is shared by the clients impersonating users to avoid discovery per request
+	s.mapper, err = apiutil.NewDynamicRESTMapper(s.c.Config)
+	return err
+}
+
+// resolveNamespace returns the namespace of an env for authorization
+func (s *APIServer) resolveNamespace(ctx context.Context, envName string) (string, error) {
+	envMeta, err := env.GetEnvFromCluster(ctx, s.KubeClient, envName)
+	if err != nil {
+		return "", err
+	}
+	return envMeta.Namespace, nil
+}
+
+// impersonate sets the clients impersonating the authenticated user into the context,
+// so the RBAC of the cluster applies and the audit logs of the cluster show the real user.
+func (s *APIServer) impersonate() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		user := auth.GetUser(c)
+		if user == nil {
+			return
+		}
+		cfg := rest.CopyConfig(s.c.Config)
+		cfg.Impersonate = rest.ImpersonationConfig{UserName: user.Name, Groups: user.Groups}
+		cli, err := client.New(cfg, client.Options{Scheme: s.c.Schema, Mapper: s.mapper})
+		if err != nil {
+			util.SetErrorAndAbort(c, util.StatusInternalServerError, err.Error())
+			return
+		}
+		args := s.c
+		args.Config = cfg
+		args.Client = cli
+		c.Set(kubeClientKey, cli)
+		c.Set(kubeArgsKey, args)
+	}
+}
+
+// kubeClient returns the client to serve the request
+func (s *APIServer) kubeClient(c *gin.Context) client.Client {
+	if cli, ok := c.Get(kubeClientKey); ok {
+		return cli.(client.Client)
+	}
+	return s.KubeClient
+}
+
+// kubeArgs returns the args to serve the request
+func (s *APIServer) kubeArgs(c *gin.Context) common.Args {
+	if args, ok := c.Get(kubeArgsKey); ok {
+		return args.(common.Args)
+	}
+	return s.c
+}
@@ -20,16 +20,18 @@ import (
 	"k8s.io/apimachinery/pkg/runtime"
 
 	corev1alpha2 "github.com/oam-dev/kubevela/apis/core.oam.dev/v1alpha2"
+	corev1beta1 "github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
 	"github.com/oam-dev/kubevela/apis/types"
 )
 
 // Environment contains all info needed in `vela env` command
 type Environment struct {
-	EnvName   string `json:"envName" binding:"required,min=1,max=32"`
-	Namespace string `json:"namespace" binding:"required,min=1,max=32"`
-	Email     string `json:"email"`
-	Domain    string `json:"domain"`
-	Current   string `json:"current,omitempty"`
+	EnvName   string   `json:"envName" binding:"required,min=1,max=32"`
+	Namespace string   `json:"namespace" binding:"required,min=1,max=32"`
+	Email     string   `json:"email"`
+	Domain    string   `json:"domain"`
+	Clusters  []string `json:"clusters,omitempty"`
+	Current   string   `json:"current,omitempty"`
 }
 
 // EnvironmentBody used for restful API in dashboard server
@@ -90,6 +92,60 @@ type ApplicationMeta struct {
 	CreatedTime string          `json:"createdTime,omitempty"`
 }
 
+// RevisionMeta used for dashboard restful API server
+type RevisionMeta struct {
+	Name     string `json:"name"`
+	Revision int    `json:"revision"`
+	Hash     string `json:"hash,omitempty"`
+	// Latest means the revision is the latest revision of the application
+	Latest      bool     `json:"latest,omitempty"`
+	Components  []string `json:"components,omitempty"`
+	CreatedTime string   `json:"createdTime,omitempty"`
+	// Change records who made the change of the revision, when and why, and what is changed
+	Change *corev1beta1.RevisionChange `json:"change,omitempty"`
+}
+
+// RevisionDiff used for dashboard restful API server
+type RevisionDiff struct {
+	Base    string `json:"base"`
+	Target  string `json:"target"`
+	Changed bool   `json:"changed"`
+	// Report is the diff report in text
+	Report string `json:"report"`
+}
+
+// RolloutStatus used for dashboard restful API server
+type RolloutStatus struct {
+	Name           string `json:"name"`
+	SourceRevision string `json:"sourceRevision,omitempty"`
+	TargetRevision string `json:"targetRevision"`
+	RollingState   string `json:"rollingState"`
+	// BatchRollingState is the state of the current batch
+	BatchRollingState string `json:"batchRollingState,omitempty"`
+	CurrentBatch      int32  `json:"currentBatch"`
+	// BatchPartition is the last batch allowed to rollout, all batches are allowed if it's empty
+	BatchPartition *int32 `json:"batchPartition,omitempty"`
+	Paused         bool   `json:"paused,omitempty"`
+	TargetSize     int32  `json:"targetSize"`
+	Upgraded       int32  `json:"upgraded"`
+	Ready          int32  `json:"ready"`
+	// BatchReady means the current batch is rolled out and ready, or it's waiting for approval
+	BatchReady bool `json:"batchReady"`
+	// WaitingForWindow explains why the current batch is held by deployment windows
+	WaitingForWindow string               `json:"waitingForWindow,omitempty"`
+	Batches          []RolloutBatchStatus `json:"batches,omitempty"`
+}
+
+// RolloutBatchStatus used for dashboard restful API server
+type RolloutBatchStatus struct {
+	Index           int32  `json:"index"`
+	Replicas        string `json:"replicas,omitempty"`
+	RequireApproval bool   `json:"requireApproval,omitempty"`
+	// State is ready, pending, waitingForWindow, waitingForApproval or the batch rolling state of the current batch
+	State    string `json:"state"`
+	Approver string `json:"approver,omitempty"`
+}
+
 // CapabilityMeta used for dashboard restful API server
 type CapabilityMeta struct {
 	CapabilityName       string `json:"capabilityName"`
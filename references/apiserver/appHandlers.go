@@ -36,7 +36,7 @@ func (s *APIServer) UpdateApps(c *gin.Context) {
 // GetApp requests an application by the namespaced name in the gin.Context
 func (s *APIServer) GetApp(c *gin.Context) {
 	envName := c.Param("envName")
-	envMeta, err := env.GetEnvByName(envName)
+	envMeta, err := env.GetEnvFromCluster(util.GetContext(c), s.kubeClient(c), envName)
 	if err != nil {
 		util.HandleError(c, util.StatusInternalServerError, err)
 		return
@@ -44,7 +44,7 @@ func (s *APIServer) GetApp(c *gin.Context) {
 	namespace := envMeta.Namespace
 	appName := c.Param("appName")
 	ctx := util.GetContext(c)
-	applicationMeta, err := common.RetrieveApplicationStatusByName(ctx, s.KubeClient, appName, namespace)
+	applicationMeta, err := common.RetrieveApplicationStatusByName(ctx, s.kubeClient(c), appName, namespace)
 	if err != nil {
 		util.HandleError(c, util.StatusInternalServerError, err)
 		return
@@ -62,7 +62,7 @@ func (s *APIServer) GetApp(c *gin.Context) {
 // @Router /envs/{envName}/apps [get]
 func (s *APIServer) ListApps(c *gin.Context) {
 	envName := c.Param("envName")
-	envMeta, err := env.GetEnvByName(envName)
+	envMeta, err := env.GetEnvFromCluster(util.GetContext(c), s.kubeClient(c), envName)
 	if err != nil {
 		util.HandleError(c, util.StatusInternalServerError, err)
 		return
@@ -70,7 +70,7 @@ func (s *APIServer) ListApps(c *gin.Context) {
 	namespace := envMeta.Namespace
 
 	ctx := util.GetContext(c)
-	applicationMetaList, err := common.ListApplications(ctx, s.KubeClient, common.Option{Namespace: namespace})
+	applicationMetaList, err := common.ListApplications(ctx, s.kubeClient(c), common.Option{Namespace: namespace})
 	if err != nil {
 		util.HandleError(c, util.StatusInternalServerError, err.Error())
 		return
@@ -81,7 +81,7 @@ func (s *APIServer) ListApps(c *gin.Context) {
 // DeleteApps deletes an application by the namespaced name in the gin.Context
 func (s *APIServer) DeleteApps(c *gin.Context) {
 	envName := c.Param("envName")
-	envMeta, err := env.GetEnvByName(envName)
+	envMeta, err := env.GetEnvFromCluster(util.GetContext(c), s.kubeClient(c), envName)
 	if err != nil {
 		util.HandleError(c, util.StatusInternalServerError, err)
 		return
@@ -89,7 +89,7 @@ func (s *APIServer) DeleteApps(c *gin.Context) {
 	appName := c.Param("appName")
 
 	o := common.DeleteOptions{
-		Client:  s.KubeClient,
+		Client:  s.kubeClient(c),
 		Env:     envMeta,
 		AppName: appName,
 	}
@@ -112,23 +112,23 @@ func (s *APIServer) CreateApplication(c *gin.Context) {
 		util.HandleError(c, util.InvalidArgument, "the application creation request body is invalid")
 		return
 	}
-	env, err := env.GetEnvByName(c.Param("envName"))
+	env, err := env.GetEnvFromCluster(util.GetContext(c), s.kubeClient(c), c.Param("envName"))
 	if err != nil {
 		util.HandleError(c, util.StatusInternalServerError, err.Error())
 		return
 	}
 	ioStream := cmdutil.IOStreams{In: os.Stdin, Out: os.Stdout, ErrOut: os.Stderr}
 	o := &common.AppfileOptions{
-		Kubecli: s.KubeClient,
+		Kubecli: s.kubeClient(c),
 		IO:      ioStream,
 		Env:     env,
 	}
-	buildResult, data, err := o.ExportFromAppFile(&body, env.Namespace, false, s.c)
+	buildResult, data, err := o.ExportFromAppFile(&body, env.Namespace, false, s.kubeArgs(c))
 	if err != nil {
 		util.HandleError(c, util.StatusInternalServerError, err.Error())
 		return
 	}
-	err = o.BaseAppFileRun(buildResult, data, s.c)
+	err = o.BaseAppFileRun(buildResult, data, s.kubeArgs(c))
 	if err != nil {
 		util.HandleError(c, util.StatusInternalServerError, err.Error())
 		return
@@ -0,0 +1,309 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/oam-dev/kubevela/references/apiserver/util"
+)
+
+func TestStaticTokenAuthenticator(t *testing.T) {
+	a, err := parseTokenFile(strings.NewReader(`# comment
+token-a,alice,1,"dev,ops"
+token-b,bob,2
+`))
+	assert.NoError(t, err)
+
+	user, ok, err := a.AuthenticateToken(context.Background(), "token-a")
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, &User{Name: "alice", Groups: []string{"dev", "ops"}}, user)
+
+	user, ok, err = a.AuthenticateToken(context.Background(), "token-b")
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, &User{Name: "bob"}, user)
+
+	_, ok, err = a.AuthenticateToken(context.Background(), "token-c")
+	assert.NoError(t, err)
+	assert.False(t, ok)
+
+	_, err = parseTokenFile(strings.NewReader("token,alice\n"))
+	assert.Error(t, err)
+	_, err = parseTokenFile(strings.NewReader("token,alice,1\ntoken,bob,2\n"))
+	assert.Error(t, err)
+}
+
+func signJWT(t *testing.T, key *rsa.PrivateKey, kid string, claims map[string]interface{}) string {
+	header, err := json.Marshal(map[string]string{"alg": "RS256", "kid": kid})
+	assert.NoError(t, err)
+	payload, err := json.Marshal(claims)
+	assert.NoError(t, err)
+	signed := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+	digest := sha256.Sum256([]byte(signed))
+	sig, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+	assert.NoError(t, err)
+	return signed + "." + base64.RawURLEncoding.EncodeToString(sig)
+}
+
+func TestOIDCAuthenticator(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+	jwks, err := json.Marshal(map[string]interface{}{"keys": []map[string]string{{
+		"kty": "RSA",
+		"kid": "k1",
+		"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+		"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+	}}})
+	assert.NoError(t, err)
+	keys, err := parseJWKS(jwks)
+	assert.NoError(t, err)
+	now := time.Unix(1600000000, 0)
+	a := &OIDCAuthenticator{
+		Issuer:        "https://issuer.example.com",
+		ClientID:      "vela",
+		UsernameClaim: "email",
+		GroupsClaim:   "groups",
+		keys:          keys,
+		now:           func() time.Time { return now },
+	}
+	claims := func(modify func(map[string]interface{})) map[string]interface{} {
+		c := map[string]interface{}{
+			"iss":    "https://issuer.example.com",
+			"aud":    []string{"vela"},
+			"exp":    now.Add(time.Hour).Unix(),
+			"email":  "alice@example.com",
+			"groups": []string{"dev"},
+		}
+		if modify != nil {
+			modify(c)
+		}
+		return c
+	}
+
+	user, ok, err := a.AuthenticateToken(context.Background(), signJWT(t, key, "k1", claims(nil)))
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, &User{Name: "alice@example.com", Groups: []string{"dev"}}, user)
+
+	_, _, err = a.AuthenticateToken(context.Background(), signJWT(t, key, "k1", claims(func(c map[string]interface{}) {
+		c["exp"] = now.Add(-time.Hour).Unix()
+	})))
+	assert.Error(t, err)
+
+	_, _, err = a.AuthenticateToken(context.Background(), signJWT(t, key, "k1", claims(func(c map[string]interface{}) {
+		c["aud"] = "others"
+	})))
+	assert.Error(t, err)
+
+	_, ok, err = a.AuthenticateToken(context.Background(), signJWT(t, key, "k1", claims(func(c map[string]interface{}) {
+		c["iss"] = "https://others.example.com"
+	})))
+	assert.NoError(t, err)
+	assert.False(t, ok)
+
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+	_, _, err = a.AuthenticateToken(context.Background(), signJWT(t, otherKey, "k1", claims(nil)))
+	assert.Error(t, err)
+
+	_, ok, err = a.AuthenticateToken(context.Background(), "not-a-jwt")
+	assert.NoError(t, err)
+	assert.False(t, ok)
+
+	a.UsernamePrefix, a.GroupsPrefix = DefaultOIDCPrefix, "idp:"
+	user, ok, err = a.AuthenticateToken(context.Background(), signJWT(t, key, "k1", claims(nil)))
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, &User{Name: "oidc:alice@example.com", Groups: []string{"idp:dev"}}, user)
+
+	a.UsernamePrefix, a.GroupsPrefix = "", ""
+	_, _, err = a.AuthenticateToken(context.Background(), signJWT(t, key, "k1", claims(func(c map[string]interface{}) {
+		c["email"] = "system:admin"
+	})))
+	assert.Error(t, err)
+
+	_, _, err = a.AuthenticateToken(context.Background(), signJWT(t, key, "k1", claims(func(c map[string]interface{}) {
+		c["groups"] = []string{"dev", "system:masters"}
+	})))
+	assert.Error(t, err)
+}
+
+func TestOIDCPrefix(t *testing.T) {
+	assert.Equal(t, DefaultOIDCPrefix, oidcPrefix(""))
+	assert.Equal(t, "", oidcPrefix(NoOIDCPrefix))
+	assert.Equal(t, "corp:", oidcPrefix("corp:"))
+}
+
+func TestAuthorizer(t *testing.T) {
+	z, err := NewAuthorizer(&Policy{
+		Roles: []Role{{Name: "env-manager", Rules: []Rule{{Verbs: []string{All}, Resources: []string{"envs"}}}}},
+		Bindings: []Binding{
+			{Role: RoleAdmin, Users: []string{"root"}},
+			{Role: RoleViewer, Groups: []string{"everyone"}},
+			{Role: RoleDeveloper, Users: []string{"alice"}, Envs: []string{"dev"}},
+			{Role: RoleDeveloper, Groups: []string{"team-a"}, Namespaces: []string{"team-a"}},
+			{Role: "env-manager", Users: []string{"bob"}},
+		},
+	})
+	assert.NoError(t, err)
+
+	alice := &User{Name: "alice", Groups: []string{"everyone"}}
+	testCases := map[string]struct {
+		attr    Attributes
+		allowed bool
+	}{
+		"admin could do anything": {
+			attr:    Attributes{User: &User{Name: "root"}, Verb: VerbDelete, Resource: "capabilities"},
+			allowed: true,
+		},
+		"viewer could list apps": {
+			attr:    Attributes{User: alice, Verb: VerbList, Resource: "apps", Env: "prod", Namespace: "prod"},
+			allowed: true,
+		},
+		"developer could create apps in bound env": {
+			attr:    Attributes{User: alice, Verb: VerbCreate, Resource: "apps", Env: "dev", Namespace: "dev"},
+			allowed: true,
+		},
+		"developer couldn't create apps in other env": {
+			attr: Attributes{User: alice, Verb: VerbCreate, Resource: "apps", Env: "prod", Namespace: "prod"},
+		},
+		"developer couldn't delete env": {
+			attr: Attributes{User: alice, Verb: VerbDelete, Resource: "envs", Env: "dev", Namespace: "dev"},
+		},
+		"group bound to namespace": {
+			attr:    Attributes{User: &User{Name: "carol", Groups: []string{"team-a"}}, Verb: VerbUpdate, Resource: "apps", Env: "a", Namespace: "team-a"},
+			allowed: true,
+		},
+		"scoped binding doesn't apply outside envs": {
+			attr: Attributes{User: &User{Name: "carol", Groups: []string{"team-a"}}, Verb: VerbList, Resource: "capabilities"},
+		},
+		"custom role": {
+			attr:    Attributes{User: &User{Name: "bob"}, Verb: VerbCreate, Resource: "envs"},
+			allowed: true,
+		},
+		"unknown user": {
+			attr: Attributes{User: &User{Name: "eve"}, Verb: VerbList, Resource: "apps"},
+		},
+		"anonymous": {
+			attr: Attributes{Verb: VerbList, Resource: "apps"},
+		},
+	}
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			allowed, reason := z.Authorize(tc.attr)
+			assert.Equal(t, tc.allowed, allowed, reason)
+		})
+	}
+
+	_, err = NewAuthorizer(&Policy{Bindings: []Binding{{Role: "unknown", Users: []string{"alice"}}}})
+	assert.Error(t, err)
+}
+
+type fakeAuthenticator map[string]*User
+
+func (f fakeAuthenticator) AuthenticateToken(_ context.Context, token string) (*User, bool, error) {
+	u, ok := f[token]
+	return u, ok, nil
+}
+
+func TestMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	z, err := NewAuthorizer(&Policy{Bindings: []Binding{
+		{Role: RoleDeveloper, Users: []string{"alice"}, Namespaces: []string{"dev-ns"}},
+	}})
+	assert.NoError(t, err)
+	resolve := func(_ context.Context, envName string) (string, error) {
+		return envName + "-ns", nil
+	}
+
+	var got Attributes
+	router := gin.New()
+	router.Use(util.SetRequestID(), util.SetContext())
+	api := router.Group(util.RootPath)
+	api.Use(Authenticate(fakeAuthenticator{"alice-token": {Name: "alice"}}), Authorize(z, resolve))
+	handler := func(c *gin.Context) {
+		got = RequestAttributes(c)
+		c.Status(http.StatusOK)
+	}
+	api.GET("/envs/:envName/apps/:appName/components/:compName", handler)
+	api.POST("/envs/:envName/apps/", handler)
+	api.PUT("/envs/:envName/rollouts/:rolloutName/approve-next", handler)
+	api.GET("/capabilities", handler)
+
+	testCases := map[string]struct {
+		method   string
+		path     string
+		token    string
+		wantCode int
+		wantAttr Attributes
+	}{
+		"no token": {
+			method: http.MethodGet, path: "/api/envs/dev/apps/a/components/c", wantCode: http.StatusUnauthorized,
+		},
+		"invalid token": {
+			method: http.MethodGet, path: "/api/envs/dev/apps/a/components/c", token: "bad", wantCode: http.StatusUnauthorized,
+		},
+		"get component": {
+			method: http.MethodGet, path: "/api/envs/dev/apps/a/components/c", token: "alice-token", wantCode: http.StatusOK,
+			wantAttr: Attributes{User: &User{Name: "alice"}, Verb: VerbGet, Resource: "apps", Env: "dev"},
+		},
+		"create app": {
+			method: http.MethodPost, path: "/api/envs/dev/apps/", token: "alice-token", wantCode: http.StatusOK,
+			wantAttr: Attributes{User: &User{Name: "alice"}, Verb: VerbCreate, Resource: "apps", Env: "dev"},
+		},
+		"create app in other env": {
+			method: http.MethodPost, path: "/api/envs/prod/apps/", token: "alice-token", wantCode: http.StatusForbidden,
+		},
+		"approve next batch": {
+			method: http.MethodPut, path: "/api/envs/dev/rollouts/r/approve-next", token: "alice-token", wantCode: http.StatusOK,
+			wantAttr: Attributes{User: &User{Name: "alice"}, Verb: VerbUpdate, Resource: "rollouts", Env: "dev"},
+		},
+		"list capabilities": {
+			method: http.MethodGet, path: "/api/capabilities", token: "alice-token", wantCode: http.StatusForbidden,
+		},
+	}
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			got = Attributes{}
+			req := httptest.NewRequest(tc.method, tc.path, nil)
+			if tc.token != "" {
+				req.Header.Set("Authorization", "Bearer "+tc.token)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+			assert.Equal(t, tc.wantCode, w.Code, w.Body.String())
+			assert.Equal(t, tc.wantAttr, got)
+		})
+	}
+}
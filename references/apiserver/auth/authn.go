@@ -0,0 +1,139 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package auth
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/pkg/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// User is the authenticated user of a request
+type User struct {
+	Name   string
+	Groups []string
+}
+
+// Authenticator authenticates a bearer token.
+// It returns false if the token is not recognized by the authenticator, and error if the token is recognized but invalid.
+type Authenticator interface {
+	AuthenticateToken(ctx context.Context, token string) (*User, bool, error)
+}
+
+// Union tries the authenticators in order, the first one recognizing the token wins
+type Union []Authenticator
+
+// AuthenticateToken implements Authenticator
+func (u Union) AuthenticateToken(ctx context.Context, token string) (*User, bool, error) {
+	var errs []string
+	for _, a := range u {
+		user, ok, err := a.AuthenticateToken(ctx, token)
+		if err != nil {
+			errs = append(errs, err.Error())
+			continue
+		}
+		if ok {
+			return user, true, nil
+		}
+	}
+	if len(errs) != 0 {
+		return nil, false, errors.New(strings.Join(errs, "; "))
+	}
+	return nil, false, nil
+}
+
+// Options configures the authentication and authorization of the apiserver.
+// Authentication is disabled if no authenticator is configured.
+type Options struct {
+	// TokenAuthFile is a CSV file of static tokens in the format of `token,user,uid,"group1,group2"`
+	TokenAuthFile string
+	// OIDCIssuer is the issuer of the OIDC ID tokens
+	OIDCIssuer string
+	// OIDCClientID is the audience of the OIDC ID tokens
+	OIDCClientID string
+	// OIDCJWKSFile is the file of the JSON Web Key Set to verify the signature of OIDC ID tokens
+	OIDCJWKSFile string
+	// OIDCUsernameClaim is the claim used as user name, default to `sub`
+	OIDCUsernameClaim string
+	// OIDCGroupsClaim is the claim used as user groups
+	OIDCGroupsClaim string
+	// OIDCUsernamePrefix is prepended to the user name of OIDC ID tokens, default to `oidc:`, `-` disables the prefix
+	OIDCUsernamePrefix string
+	// OIDCGroupsPrefix is prepended to the groups of OIDC ID tokens, default to `oidc:`, `-` disables the prefix
+	OIDCGroupsPrefix string
+	// TokenReview authenticates tokens by the TokenReview API of Kubernetes
+	TokenReview bool
+	// PolicyFile is the file of authorization policy, all authenticated users are allowed if it's empty
+	PolicyFile string
+}
+
+// Enabled returns whether any authenticator is configured
+func (o Options) Enabled() bool {
+	return o.TokenAuthFile != "" || o.OIDCJWKSFile != "" || o.TokenReview
+}
+
+// NewAuthenticator creates the authenticator from options, it returns nil if authentication is disabled.
+// The client is used for the TokenReview API.
+func NewAuthenticator(o Options, c client.Client) (Authenticator, error) {
+	var union Union
+	if o.TokenAuthFile != "" {
+		a, err := NewStaticTokenAuthenticator(o.TokenAuthFile)
+		if err != nil {
+			return nil, err
+		}
+		union = append(union, a)
+	}
+	if o.OIDCJWKSFile != "" {
+		a, err := NewOIDCAuthenticator(o.OIDCIssuer, o.OIDCClientID, o.OIDCJWKSFile, o.OIDCUsernameClaim, o.OIDCGroupsClaim)
+		if err != nil {
+			return nil, err
+		}
+		a.UsernamePrefix = oidcPrefix(o.OIDCUsernamePrefix)
+		a.GroupsPrefix = oidcPrefix(o.OIDCGroupsPrefix)
+		union = append(union, a)
+	}
+	if o.TokenReview {
+		union = append(union, &TokenReviewAuthenticator{Client: c})
+	}
+	if len(union) == 0 {
+		return nil, nil
+	}
+	return union, nil
+}
+
+func oidcPrefix(prefix string) string {
+	switch prefix {
+	case "":
+		return DefaultOIDCPrefix
+	case NoOIDCPrefix:
+		return ""
+	default:
+		return prefix
+	}
+}
+
+// bearerToken extracts the bearer token from the Authorization header
+func bearerToken(r *http.Request) string {
+	parts := strings.SplitN(strings.TrimSpace(r.Header.Get("Authorization")), " ", 2)
+	if len(parts) != 2 || !strings.EqualFold(parts[0], "bearer") {
+		return ""
+	}
+	return strings.TrimSpace(parts[1])
+}
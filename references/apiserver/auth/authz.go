@@ -0,0 +1,179 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package auth
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+
+	"github.com/ghodss/yaml"
+	"github.com/pkg/errors"
+)
+
+// Verbs of the apiserver requests
+const (
+	VerbGet    = "get"
+	VerbList   = "list"
+	VerbCreate = "create"
+	VerbUpdate = "update"
+	VerbDelete = "delete"
+	// All matches any verb or resource
+	All = "*"
+)
+
+// Built-in roles
+const (
+	// RoleViewer could read all resources
+	RoleViewer = "viewer"
+	// RoleDeveloper could read all resources and manage applications and their rollouts
+	RoleDeveloper = "developer"
+	// RoleAdmin could do anything
+	RoleAdmin = "admin"
+)
+
+// Rule allows the verbs on the resources
+type Rule struct {
+	Verbs     []string `json:"verbs"`
+	Resources []string `json:"resources"`
+}
+
+// Role is a set of rules
+type Role struct {
+	Name  string `json:"name"`
+	Rules []Rule `json:"rules"`
+}
+
+// Binding grants a role to users and groups.
+// The role is granted in the given envs and namespaces, or everywhere if neither is specified.
+type Binding struct {
+	Role       string   `json:"role"`
+	Users      []string `json:"users,omitempty"`
+	Groups     []string `json:"groups,omitempty"`
+	Envs       []string `json:"envs,omitempty"`
+	Namespaces []string `json:"namespaces,omitempty"`
+}
+
+// Policy is the authorization policy of the apiserver
+type Policy struct {
+	Roles    []Role    `json:"roles,omitempty"`
+	Bindings []Binding `json:"bindings"`
+}
+
+// Attributes describes a request to be authorized
+type Attributes struct {
+	User     *User
+	Verb     string
+	Resource string
+	// Env and Namespace are empty for requests not belonging to any env
+	Env       string
+	Namespace string
+}
+
+// Authorizer authorizes requests by the policy
+type Authorizer struct {
+	roles    map[string]Role
+	bindings []Binding
+}
+
+var builtinRoles = []Role{
+	{Name: RoleViewer, Rules: []Rule{{Verbs: []string{VerbGet, VerbList}, Resources: []string{All}}}},
+	{Name: RoleDeveloper, Rules: []Rule{
+		{Verbs: []string{VerbGet, VerbList}, Resources: []string{All}},
+		{Verbs: []string{All}, Resources: []string{"apps", "rollouts"}},
+	}},
+	{Name: RoleAdmin, Rules: []Rule{{Verbs: []string{All}, Resources: []string{All}}}},
+}
+
+// LoadPolicy loads the policy from a YAML or JSON file
+func LoadPolicy(path string) (*Policy, error) {
+	data, err := ioutil.ReadFile(filepath.Clean(path))
+	if err != nil {
+		return nil, errors.Wrap(err, "read policy file")
+	}
+	policy := new(Policy)
+	if err := yaml.Unmarshal(data, policy); err != nil {
+		return nil, errors.Wrap(err, "parse policy file")
+	}
+	return policy, nil
+}
+
+// NewAuthorizer creates an Authorizer with the policy, the built-in roles could be overridden by the policy
+func NewAuthorizer(p *Policy) (*Authorizer, error) {
+	roles := make(map[string]Role)
+	for _, r := range builtinRoles {
+		roles[r.Name] = r
+	}
+	for _, r := range p.Roles {
+		roles[r.Name] = r
+	}
+	for i, b := range p.Bindings {
+		if _, ok := roles[b.Role]; !ok {
+			return nil, fmt.Errorf("binding %d refers to unknown role %s", i, b.Role)
+		}
+	}
+	return &Authorizer{roles: roles, bindings: p.Bindings}, nil
+}
+
+// Authorize returns whether the request is allowed and the reason if it's denied
+func (a *Authorizer) Authorize(attr Attributes) (bool, string) {
+	if attr.User == nil {
+		return false, "anonymous user is not allowed"
+	}
+	for _, b := range a.bindings {
+		if !b.appliesTo(attr) {
+			continue
+		}
+		for _, rule := range a.roles[b.Role].Rules {
+			if contains(rule.Verbs, attr.Verb) && contains(rule.Resources, attr.Resource) {
+				return true, ""
+			}
+		}
+	}
+	reason := fmt.Sprintf("user %q cannot %s %s", attr.User.Name, attr.Verb, attr.Resource)
+	if attr.Env != "" {
+		reason += fmt.Sprintf(" in env %q", attr.Env)
+	}
+	return false, reason
+}
+
+func (b Binding) appliesTo(attr Attributes) bool {
+	subject := contains(b.Users, attr.User.Name)
+	for _, g := range attr.User.Groups {
+		subject = subject || contains(b.Groups, g)
+	}
+	if !subject {
+		return false
+	}
+	if len(b.Envs) == 0 && len(b.Namespaces) == 0 {
+		return true
+	}
+	if attr.Env == "" {
+		// scoped bindings don't grant anything outside envs
+		return false
+	}
+	return contains(b.Envs, attr.Env) || (attr.Namespace != "" && contains(b.Namespaces, attr.Namespace))
+}
+
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == All || item == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,121 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package auth
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	"github.com/oam-dev/kubevela/references/apiserver/util"
+)
+
+// UserKey is used as key to set/get the authenticated user in gin context
+const UserKey = "user"
+
+// NamespaceResolver resolves the namespace of an env
+type NamespaceResolver func(ctx context.Context, envName string) (string, error)
+
+// Authenticate authenticates the bearer token of requests, the request is rejected if it's not authenticated
+func Authenticate(a Authenticator) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		token := bearerToken(c.Request)
+		if token == "" {
+			util.SetErrorAndAbort(c, util.Unauthorized, "bearer token is required")
+			return
+		}
+		user, ok, err := a.AuthenticateToken(util.GetContext(c), token)
+		if err != nil {
+			ctrl.Log.Info("failed to authenticate request", "path", c.Request.URL.Path, "error", err.Error())
+			util.SetErrorAndAbort(c, util.Unauthorized, "invalid bearer token")
+			return
+		}
+		if !ok {
+			util.SetErrorAndAbort(c, util.Unauthorized, "invalid bearer token")
+			return
+		}
+		c.Set(UserKey, user)
+	}
+}
+
+// Authorize authorizes the requests of the authenticated user
+func Authorize(z *Authorizer, resolve NamespaceResolver) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		attr := RequestAttributes(c)
+		if attr.Env != "" && resolve != nil {
+			ns, err := resolve(util.GetContext(c), attr.Env)
+			if err == nil {
+				attr.Namespace = ns
+			}
+		}
+		if ok, reason := z.Authorize(attr); !ok {
+			util.SetErrorAndAbort(c, util.Forbidden, reason)
+			return
+		}
+	}
+}
+
+// GetUser returns the authenticated user of the request, it's nil if authentication is disabled
+func GetUser(c *gin.Context) *User {
+	if u, ok := c.Get(UserKey); ok {
+		return u.(*User)
+	}
+	return nil
+}
+
+// RequestAttributes gets the attributes of the request from its route.
+// Components and traits of an application are regarded as part of the application, and operations of a rollout
+// are regarded as updating the rollout.
+func RequestAttributes(c *gin.Context) Attributes {
+	attr := Attributes{User: GetUser(c), Env: c.Param("envName")}
+	route := strings.TrimPrefix(c.FullPath(), util.RootPath)
+	var segments []string
+	for _, s := range strings.Split(route, "/") {
+		if s != "" {
+			segments = append(segments, s)
+		}
+	}
+	for _, s := range segments {
+		if strings.HasPrefix(s, ":") {
+			continue
+		}
+		attr.Resource = s
+		if attr.Env == "" || s == strings.TrimPrefix(util.ApplicationPath, "/") ||
+			s == strings.TrimPrefix(util.RolloutPath, "/") {
+			break
+		}
+	}
+	switch c.Request.Method {
+	case http.MethodGet, http.MethodHead:
+		attr.Verb = VerbList
+		if len(segments) != 0 && strings.HasPrefix(segments[len(segments)-1], ":") {
+			attr.Verb = VerbGet
+		}
+	case http.MethodPost:
+		attr.Verb = VerbCreate
+	case http.MethodPut, http.MethodPatch:
+		attr.Verb = VerbUpdate
+	case http.MethodDelete:
+		attr.Verb = VerbDelete
+	default:
+		attr.Verb = strings.ToLower(c.Request.Method)
+	}
+	return attr
+}
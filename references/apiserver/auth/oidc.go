@@ -0,0 +1,321 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// DefaultOIDCPrefix is the default prefix of the user name and groups of OIDC tokens
+	DefaultOIDCPrefix = "oidc:"
+	// NoOIDCPrefix disables the prefix of the user name or groups of OIDC tokens
+	NoOIDCPrefix = "-"
+
+	reservedIdentityPrefix = "system:"
+)
+
+// OIDCAuthenticator authenticates OIDC ID tokens, the signature is verified by the keys of a JWKS file
+type OIDCAuthenticator struct {
+	Issuer        string
+	ClientID      string
+	UsernameClaim string
+	GroupsClaim   string
+	// UsernamePrefix and GroupsPrefix are prepended to the user name and groups of tokens, so users of the OIDC
+	// provider can't collide with the users and groups of Kubernetes when they are impersonated
+	UsernamePrefix string
+	GroupsPrefix   string
+
+	keys map[string]crypto.PublicKey
+	// now is used to check the expiration of tokens, it's replaced in tests
+	now func() time.Time
+}
+
+type jsonWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+type jwtHeader struct {
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+}
+
+// NewOIDCAuthenticator creates an OIDCAuthenticator with the JWKS file
+func NewOIDCAuthenticator(issuer, clientID, jwksFile, usernameClaim, groupsClaim string) (*OIDCAuthenticator, error) {
+	if issuer == "" || clientID == "" {
+		return nil, errors.New("OIDC issuer and client id must be specified")
+	}
+	data, err := ioutil.ReadFile(filepath.Clean(jwksFile))
+	if err != nil {
+		return nil, errors.Wrap(err, "read JWKS file")
+	}
+	keys, err := parseJWKS(data)
+	if err != nil {
+		return nil, err
+	}
+	if usernameClaim == "" {
+		usernameClaim = "sub"
+	}
+	return &OIDCAuthenticator{
+		Issuer:        issuer,
+		ClientID:      clientID,
+		UsernameClaim: usernameClaim,
+		GroupsClaim:   groupsClaim,
+		keys:          keys,
+		now:           time.Now,
+	}, nil
+}
+
+func parseJWKS(data []byte) (map[string]crypto.PublicKey, error) {
+	var jwks struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+	if err := json.Unmarshal(data, &jwks); err != nil {
+		return nil, errors.Wrap(err, "parse JWKS")
+	}
+	keys := make(map[string]crypto.PublicKey)
+	for _, k := range jwks.Keys {
+		key, err := k.publicKey()
+		if err != nil {
+			return nil, errors.WithMessagef(err, "parse key %q", k.Kid)
+		}
+		keys[k.Kid] = key
+	}
+	if len(keys) == 0 {
+		return nil, errors.New("no key found in JWKS")
+	}
+	return keys, nil
+}
+
+func (k jsonWebKey) publicKey() (crypto.PublicKey, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := decodeBigInt(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decodeBigInt(k.E)
+		if err != nil {
+			return nil, err
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve %s", k.Crv)
+		}
+		x, err := decodeBigInt(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decodeBigInt(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	default:
+		return nil, fmt.Errorf("unsupported key type %s", k.Kty)
+	}
+}
+
+func decodeBigInt(s string) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).SetBytes(b), nil
+}
+
+// AuthenticateToken implements Authenticator
+func (a *OIDCAuthenticator) AuthenticateToken(_ context.Context, token string) (*User, bool, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		// not a JWT
+		return nil, false, nil
+	}
+	claims, err := a.verify(parts)
+	if err != nil {
+		return nil, false, err
+	}
+	if iss, _ := claims["iss"].(string); iss != a.Issuer {
+		// issued by others
+		return nil, false, nil
+	}
+	if !hasAudience(claims["aud"], a.ClientID) {
+		return nil, false, errors.New("oidc: token is not issued for this client")
+	}
+	now := float64(a.now().Unix())
+	if exp, ok := claims["exp"].(float64); !ok || now >= exp {
+		return nil, false, errors.New("oidc: token is expired")
+	}
+	if nbf, ok := claims["nbf"].(float64); ok && now < nbf {
+		return nil, false, errors.New("oidc: token is not valid yet")
+	}
+	name, _ := claims[a.UsernameClaim].(string)
+	if name == "" {
+		return nil, false, fmt.Errorf("oidc: claim %s not found", a.UsernameClaim)
+	}
+	user := &User{Name: a.UsernamePrefix + name}
+	if a.GroupsClaim != "" {
+		switch groups := claims[a.GroupsClaim].(type) {
+		case string:
+			user.Groups = []string{a.GroupsPrefix + groups}
+		case []interface{}:
+			for _, g := range groups {
+				if s, ok := g.(string); ok {
+					user.Groups = append(user.Groups, a.GroupsPrefix+s)
+				}
+			}
+		}
+	}
+	if err := checkReservedIdentity(user); err != nil {
+		return nil, false, err
+	}
+	return user, true, nil
+}
+
+// checkReservedIdentity rejects the users and groups reserved by Kubernetes, the apiserver impersonates them
+// to the cluster so an OIDC provider must not be able to claim them
+func checkReservedIdentity(user *User) error {
+	if strings.HasPrefix(user.Name, reservedIdentityPrefix) {
+		return fmt.Errorf("oidc: user %s is reserved by Kubernetes", user.Name)
+	}
+	for _, g := range user.Groups {
+		if strings.HasPrefix(g, reservedIdentityPrefix) {
+			return fmt.Errorf("oidc: group %s is reserved by Kubernetes", g)
+		}
+	}
+	return nil
+}
+
+// verify checks the signature of the JWT and returns its claims
+func (a *OIDCAuthenticator) verify(parts []string) (map[string]interface{}, error) {
+	var header jwtHeader
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return nil, errors.Wrap(err, "oidc: malformed token header")
+	}
+	key, ok := a.keys[header.Kid]
+	if !ok {
+		if len(a.keys) != 1 || header.Kid != "" {
+			return nil, fmt.Errorf("oidc: unknown key %q", header.Kid)
+		}
+		for _, k := range a.keys {
+			key = k
+		}
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, errors.Wrap(err, "oidc: malformed token signature")
+	}
+	if err := verifySignature(header.Alg, key, []byte(parts[0]+"."+parts[1]), signature); err != nil {
+		return nil, err
+	}
+	claims := make(map[string]interface{})
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return nil, errors.Wrap(err, "oidc: malformed token payload")
+	}
+	return claims, nil
+}
+
+func verifySignature(alg string, key crypto.PublicKey, signed, signature []byte) error {
+	var hash crypto.Hash
+	switch alg {
+	case "RS256", "ES256":
+		hash = crypto.SHA256
+	case "RS384", "ES384":
+		hash = crypto.SHA384
+	case "RS512", "ES512":
+		hash = crypto.SHA512
+	default:
+		return fmt.Errorf("oidc: unsupported signing algorithm %q", alg)
+	}
+	h := hash.New()
+	h.Write(signed) //nolint:errcheck
+	digest := h.Sum(nil)
+	switch k := key.(type) {
+	case *rsa.PublicKey:
+		if !strings.HasPrefix(alg, "RS") {
+			return fmt.Errorf("oidc: algorithm %s doesn't match RSA key", alg)
+		}
+		if err := rsa.VerifyPKCS1v15(k, hash, digest, signature); err != nil {
+			return errors.New("oidc: invalid token signature")
+		}
+	case *ecdsa.PublicKey:
+		if !strings.HasPrefix(alg, "ES") {
+			return fmt.Errorf("oidc: algorithm %s doesn't match EC key", alg)
+		}
+		size := (k.Curve.Params().BitSize + 7) / 8
+		if len(signature) != 2*size {
+			return errors.New("oidc: invalid token signature")
+		}
+		r := new(big.Int).SetBytes(signature[:size])
+		s := new(big.Int).SetBytes(signature[size:])
+		if !ecdsa.Verify(k, digest, r, s) {
+			return errors.New("oidc: invalid token signature")
+		}
+	}
+	return nil
+}
+
+func decodeSegment(seg string, v interface{}) error {
+	data, err := base64.RawURLEncoding.DecodeString(seg)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+func hasAudience(aud interface{}, clientID string) bool {
+	switch a := aud.(type) {
+	case string:
+		return a == clientID
+	case []interface{}:
+		for _, v := range a {
+			if s, ok := v.(string); ok && s == clientID {
+				return true
+			}
+		}
+	}
+	return false
+}
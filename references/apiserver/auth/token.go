@@ -0,0 +1,90 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package auth
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// StaticTokenAuthenticator authenticates tokens listed in a token file
+type StaticTokenAuthenticator struct {
+	tokens map[string]*User
+}
+
+// NewStaticTokenAuthenticator loads the token file, which uses the same format as the `--token-auth-file` of kube-apiserver:
+// a CSV file with at least 3 columns `token,user,uid`, followed by an optional quoted group list `"group1,group2"`.
+func NewStaticTokenAuthenticator(path string) (*StaticTokenAuthenticator, error) {
+	f, err := os.Open(filepath.Clean(path))
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close() //nolint:errcheck
+	return parseTokenFile(f)
+}
+
+func parseTokenFile(r io.Reader) (*StaticTokenAuthenticator, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.Comment = '#'
+	tokens := make(map[string]*User)
+	for line := 1; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if len(record) < 3 {
+			return nil, fmt.Errorf("token file line %d: expect at least 3 columns, got %d", line, len(record))
+		}
+		token := strings.TrimSpace(record[0])
+		if token == "" {
+			return nil, fmt.Errorf("token file line %d: empty token", line)
+		}
+		if _, ok := tokens[token]; ok {
+			return nil, fmt.Errorf("token file line %d: duplicated token", line)
+		}
+		user := &User{Name: strings.TrimSpace(record[1])}
+		if len(record) > 3 {
+			for _, g := range strings.Split(record[3], ",") {
+				if g = strings.TrimSpace(g); g != "" {
+					user.Groups = append(user.Groups, g)
+				}
+			}
+		}
+		tokens[token] = user
+	}
+	return &StaticTokenAuthenticator{tokens: tokens}, nil
+}
+
+// AuthenticateToken implements Authenticator
+func (a *StaticTokenAuthenticator) AuthenticateToken(_ context.Context, token string) (*User, bool, error) {
+	for t, user := range a.tokens {
+		if subtle.ConstantTimeCompare([]byte(t), []byte(token)) == 1 {
+			return user, true, nil
+		}
+	}
+	return nil, false, nil
+}
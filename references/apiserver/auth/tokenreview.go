@@ -0,0 +1,46 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package auth
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// TokenReviewAuthenticator authenticates tokens by the TokenReview API of Kubernetes,
+// so ServiceAccount tokens and any token accepted by the kube-apiserver could be used.
+type TokenReviewAuthenticator struct {
+	Client client.Client
+}
+
+// AuthenticateToken implements Authenticator
+func (a *TokenReviewAuthenticator) AuthenticateToken(ctx context.Context, token string) (*User, bool, error) {
+	review := &authenticationv1.TokenReview{Spec: authenticationv1.TokenReviewSpec{Token: token}}
+	if err := a.Client.Create(ctx, review); err != nil {
+		return nil, false, errors.Wrap(err, "token review")
+	}
+	if !review.Status.Authenticated {
+		if review.Status.Error != "" {
+			return nil, false, errors.New(review.Status.Error)
+		}
+		return nil, false, nil
+	}
+	return &User{Name: review.Status.User.Username, Groups: review.Status.User.Groups}, true, nil
+}
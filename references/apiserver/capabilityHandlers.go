@@ -61,7 +61,7 @@ func (s *APIServer) SyncCapabilityCenter(c *gin.Context) {
 // AddCapabilityIntoCluster adds specific capability into cluster
 func (s *APIServer) AddCapabilityIntoCluster(c *gin.Context) {
 	cap := c.Param("capabilityCenterName") + "/" + c.Param("capabilityName")
-	msg, err := common.AddCapabilityIntoCluster(s.KubeClient, s.dm, cap)
+	msg, err := common.AddCapabilityIntoCluster(s.kubeClient(c), s.dm, cap, c.Query("force") == "true")
 	if err != nil {
 		util.HandleError(c, util.StatusInternalServerError)
 		return
@@ -80,7 +80,7 @@ func (s *APIServer) DeleteCapabilityCenter(c *gin.Context) {
 func (s *APIServer) RemoveCapabilityFromCluster(c *gin.Context) {
 	capabilityCenterName := c.Param("capabilityName")
 	// TODO get namespace from env
-	msg, err := common.RemoveCapabilityFromCluster("default", s.c, s.KubeClient, capabilityCenterName)
+	msg, err := common.RemoveCapabilityFromCluster("default", s.kubeArgs(c), s.kubeClient(c), capabilityCenterName)
 	if err != nil {
 		util.HandleError(c, util.StatusInternalServerError, err.Error())
 		return
@@ -91,7 +91,7 @@ func (s *APIServer) RemoveCapabilityFromCluster(c *gin.Context) {
 // ListCapabilities lists capabilities of a capability center
 func (s *APIServer) ListCapabilities(c *gin.Context) {
 	capabilityCenterName := c.Param("capabilityName")
-	capabilityList, err := common.ListCapabilities("default", s.c, capabilityCenterName)
+	capabilityList, err := common.ListCapabilities("default", s.kubeArgs(c), capabilityCenterName)
 	if err != nil {
 		util.HandleError(c, util.StatusInternalServerError, err.Error())
 		return
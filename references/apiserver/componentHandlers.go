@@ -30,7 +30,7 @@ import (
 // GetComponent gets a comoponent from cluster
 func (s *APIServer) GetComponent(c *gin.Context) {
 	envName := c.Param("envName")
-	envMeta, err := env.GetEnvByName(envName)
+	envMeta, err := env.GetEnvFromCluster(util.GetContext(c), s.kubeClient(c), envName)
 	if err != nil {
 		util.HandleError(c, util.StatusInternalServerError, err)
 		return
@@ -39,7 +39,7 @@ func (s *APIServer) GetComponent(c *gin.Context) {
 	applicationName := c.Param("appName")
 	componentName := c.Param("compName")
 	ctx := util.GetContext(c)
-	componentMeta, err := common.RetrieveComponent(ctx, s.KubeClient, applicationName, componentName, namespace)
+	componentMeta, err := common.RetrieveComponent(ctx, s.kubeClient(c), applicationName, componentName, namespace)
 	if err != nil {
 		util.HandleError(c, util.StatusInternalServerError, err)
 		return
@@ -50,7 +50,7 @@ func (s *APIServer) GetComponent(c *gin.Context) {
 // DeleteComponent deletes a component from cluster
 func (s *APIServer) DeleteComponent(c *gin.Context) {
 	envName := c.Param("envName")
-	envMeta, err := env.GetEnvByName(envName)
+	envMeta, err := env.GetEnvFromCluster(util.GetContext(c), s.kubeClient(c), envName)
 	if err != nil {
 		util.HandleError(c, util.StatusInternalServerError, err)
 		return
@@ -59,7 +59,7 @@ func (s *APIServer) DeleteComponent(c *gin.Context) {
 	componentName := c.Param("compName")
 
 	o := common.DeleteOptions{
-		Client:   s.KubeClient,
+		Client:   s.kubeClient(c),
 		Env:      envMeta,
 		AppName:  appName,
 		CompName: componentName}
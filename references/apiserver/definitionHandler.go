@@ -36,7 +36,7 @@ import (
 // @Router /definitions/{definitionName} [get]
 func (s *APIServer) GetDefinition(c *gin.Context) {
 	definitionName := c.Param("name")
-	cm, err := common.GetCapabilityConfigMap(s.KubeClient, definitionName)
+	cm, err := common.GetCapabilityConfigMap(s.kubeClient(c), definitionName)
 	if err != nil {
 		util.HandleError(c, util.StatusInternalServerError, errors.New("OpenAPI v3 JSON Schema is not ready"))
 		return
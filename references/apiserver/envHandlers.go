@@ -46,12 +46,13 @@ func (s *APIServer) CreateEnv(c *gin.Context) {
 	}
 
 	ctx := util.GetContext(c)
-	message, err := env.CreateEnv(ctx, s.KubeClient, name, &types.EnvMeta{
+	message, err := env.CreateEnv(ctx, s.kubeClient(c), name, &types.EnvMeta{
 		Name:      name,
 		Current:   environment.Current,
 		Namespace: namespace,
 		Email:     environment.Email,
 		Domain:    environment.Domain,
+		Clusters:  environment.Clusters,
 	})
 	util.AssembleResponse(c, message, err)
 }
@@ -73,7 +74,7 @@ func (s *APIServer) UpdateEnv(c *gin.Context) {
 		return
 	}
 	ctx := util.GetContext(c)
-	message, err := env.UpdateEnv(ctx, s.KubeClient, envName, environmentBody.Namespace)
+	message, err := env.UpdateEnv(ctx, s.kubeClient(c), envName, environmentBody.Namespace)
 	util.AssembleResponse(c, message, err)
 }
 
@@ -87,13 +88,16 @@ func (s *APIServer) UpdateEnv(c *gin.Context) {
 func (s *APIServer) GetEnv(c *gin.Context) {
 	envName := c.Param("envName")
 	ctrl.Log.Info("Get a get environment request", "envName", envName)
-	envList, err := env.ListEnvs(envName)
+	envList, err := env.ListEnvs(util.GetContext(c), s.kubeClient(c), envName)
 
 	environmentList := make([]apis.Environment, 0)
 	for _, envMeta := range envList {
 		environmentList = append(environmentList, apis.Environment{
 			EnvName:   envMeta.Name,
 			Namespace: envMeta.Namespace,
+			Email:     envMeta.Email,
+			Domain:    envMeta.Domain,
+			Clusters:  envMeta.Clusters,
 			Current:   envMeta.Current,
 		})
 	}
@@ -122,7 +126,7 @@ func (s *APIServer) ListEnv(c *gin.Context) {
 func (s *APIServer) DeleteEnv(c *gin.Context) {
 	envName := c.Param("envName")
 	ctrl.Log.Info("Delete a delete environment request", "envName", envName)
-	msg, err := env.DeleteEnv(envName)
+	msg, err := env.DeleteEnv(util.GetContext(c), s.kubeClient(c), envName)
 	util.AssembleResponse(c, msg, err)
 }
 
@@ -136,6 +140,6 @@ func (s *APIServer) DeleteEnv(c *gin.Context) {
 func (s *APIServer) SetEnv(c *gin.Context) {
 	envName := c.Param("envName")
 	ctrl.Log.Info("Patch a set environment request", "envName", envName)
-	msg, err := env.SetEnv(envName)
+	msg, err := env.SetEnv(util.GetContext(c), s.kubeClient(c), envName)
 	util.AssembleResponse(c, msg, err)
 }
@@ -0,0 +1,114 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package apiserver
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/oam-dev/kubevela/pkg/utils/env"
+	"github.com/oam-dev/kubevela/references/apiserver/util"
+	"github.com/oam-dev/kubevela/references/common"
+)
+
+// ListRevisions requests all revisions of an application
+// @tags applications
+// @ID ListRevisions
+// @Summary list all revisions of an application
+// @Param envName path string true "environment name"
+// @Param appName path string true "application name"
+// @Success 200 {object} apis.Response{code=int,data=[]apis.RevisionMeta}
+// @Failure 500 {object} apis.Response{code=int,data=string}
+// @Router /envs/{envName}/apps/{appName}/revisions [get]
+func (s *APIServer) ListRevisions(c *gin.Context) {
+	ctx := util.GetContext(c)
+	envMeta, err := env.GetEnvFromCluster(ctx, s.kubeClient(c), c.Param("envName"))
+	if err != nil {
+		util.HandleError(c, util.StatusInternalServerError, err.Error())
+		return
+	}
+	revisions, err := common.ListRevisions(ctx, s.kubeClient(c), c.Param("appName"), envMeta.Namespace)
+	if err != nil {
+		util.HandleError(c, util.StatusInternalServerError, err.Error())
+		return
+	}
+	util.AssembleResponse(c, revisions, nil)
+}
+
+// DiffRevisions compares a revision with another one of an application
+// @tags applications
+// @ID DiffRevisions
+// @Summary compare two revisions of an application
+// @Param envName path string true "environment name"
+// @Param appName path string true "application name"
+// @Param revision path string true "the base revision"
+// @Param target query string false "the target revision, default to the latest revision"
+// @Param context query int false "number of unchanged lines around changes, default to show all lines"
+// @Success 200 {object} apis.Response{code=int,data=apis.RevisionDiff}
+// @Failure 500 {object} apis.Response{code=int,data=string}
+// @Router /envs/{envName}/apps/{appName}/revisions/{revision}/diff [get]
+func (s *APIServer) DiffRevisions(c *gin.Context) {
+	diffContext := -1
+	if v := c.Query("context"); v != "" {
+		var err error
+		if diffContext, err = strconv.Atoi(v); err != nil {
+			util.HandleError(c, util.InvalidArgument, "context must be an integer")
+			return
+		}
+	}
+	ctx := util.GetContext(c)
+	envMeta, err := env.GetEnvFromCluster(ctx, s.kubeClient(c), c.Param("envName"))
+	if err != nil {
+		util.HandleError(c, util.StatusInternalServerError, err.Error())
+		return
+	}
+	diff, err := common.DiffRevisions(ctx, s.kubeClient(c), c.Param("appName"), envMeta.Namespace,
+		c.Param("revision"), c.Query("target"), diffContext)
+	if err != nil {
+		util.HandleError(c, util.StatusInternalServerError, err.Error())
+		return
+	}
+	util.AssembleResponse(c, diff, nil)
+}
+
+// RollbackApp restores the spec of an application from one of its revisions
+// @tags applications
+// @ID RollbackApplication
+// @Summary rollback an application to a revision
+// @Param envName path string true "environment name"
+// @Param appName path string true "application name"
+// @Param revision path string true "the revision to rollback to"
+// @Success 200 {object} apis.Response{code=int,data=string}
+// @Failure 500 {object} apis.Response{code=int,data=string}
+// @Router /envs/{envName}/apps/{appName}/rollback/{revision} [post]
+func (s *APIServer) RollbackApp(c *gin.Context) {
+	ctx := util.GetContext(c)
+	envMeta, err := env.GetEnvFromCluster(ctx, s.kubeClient(c), c.Param("envName"))
+	if err != nil {
+		util.HandleError(c, util.StatusInternalServerError, err.Error())
+		return
+	}
+	appName := c.Param("appName")
+	rev, err := common.RollbackApplication(ctx, s.kubeClient(c), appName, envMeta.Namespace, c.Param("revision"))
+	if err != nil {
+		util.HandleError(c, util.StatusInternalServerError, err.Error())
+		return
+	}
+	util.AssembleResponse(c, fmt.Sprintf("application %s is rolled back to revision %s", appName, rev.Name), nil)
+}
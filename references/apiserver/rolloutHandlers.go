@@ -0,0 +1,181 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package apiserver
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/oam-dev/kubevela/pkg/utils/env"
+	"github.com/oam-dev/kubevela/references/apiserver/util"
+	"github.com/oam-dev/kubevela/references/common"
+)
+
+// GetRollout requests the status of a rollout
+// @tags rollouts
+// @ID GetRollout
+// @Summary get the status of a rollout and its batches
+// @Param envName path string true "environment name"
+// @Param rolloutName path string true "rollout name"
+// @Success 200 {object} apis.Response{code=int,data=apis.RolloutStatus}
+// @Failure 500 {object} apis.Response{code=int,data=string}
+// @Router /envs/{envName}/rollouts/{rolloutName} [get]
+func (s *APIServer) GetRollout(c *gin.Context) {
+	ctx := util.GetContext(c)
+	envMeta, err := env.GetEnvFromCluster(ctx, s.kubeClient(c), c.Param("envName"))
+	if err != nil {
+		util.HandleError(c, util.StatusInternalServerError, err.Error())
+		return
+	}
+	status, err := common.GetRolloutStatus(ctx, s.kubeClient(c), c.Param("rolloutName"), envMeta.Namespace)
+	if err != nil {
+		util.HandleError(c, util.StatusInternalServerError, err.Error())
+		return
+	}
+	util.AssembleResponse(c, status, nil)
+}
+
+// PauseRollout pauses a rollout
+// @tags rollouts
+// @ID PauseRollout
+// @Summary pause a rollout
+// @Param envName path string true "environment name"
+// @Param rolloutName path string true "rollout name"
+// @Success 200 {object} apis.Response{code=int,data=string}
+// @Failure 500 {object} apis.Response{code=int,data=string}
+// @Router /envs/{envName}/rollouts/{rolloutName}/pause [put]
+func (s *APIServer) PauseRollout(c *gin.Context) {
+	s.pauseRollout(c, true)
+}
+
+// ResumeRollout resumes a paused rollout
+// @tags rollouts
+// @ID ResumeRollout
+// @Summary resume a rollout
+// @Param envName path string true "environment name"
+// @Param rolloutName path string true "rollout name"
+// @Success 200 {object} apis.Response{code=int,data=string}
+// @Failure 500 {object} apis.Response{code=int,data=string}
+// @Router /envs/{envName}/rollouts/{rolloutName}/resume [put]
+func (s *APIServer) ResumeRollout(c *gin.Context) {
+	s.pauseRollout(c, false)
+}
+
+func (s *APIServer) pauseRollout(c *gin.Context, paused bool) {
+	ctx := util.GetContext(c)
+	envMeta, err := env.GetEnvFromCluster(ctx, s.kubeClient(c), c.Param("envName"))
+	if err != nil {
+		util.HandleError(c, util.StatusInternalServerError, err.Error())
+		return
+	}
+	name := c.Param("rolloutName")
+	if err := common.PauseRollout(ctx, s.kubeClient(c), name, envMeta.Namespace, paused); err != nil {
+		util.HandleError(c, util.StatusInternalServerError, err.Error())
+		return
+	}
+	action := "resumed"
+	if paused {
+		action = "paused"
+	}
+	util.AssembleResponse(c, fmt.Sprintf("rollout %s is %s", name, action), nil)
+}
+
+// ApproveNextBatch approves the next batch of a rollout
+// @tags rollouts
+// @ID ApproveNextBatch
+// @Summary approve the next batch of a rollout by advancing its batch partition by one
+// @Param envName path string true "environment name"
+// @Param rolloutName path string true "rollout name"
+// @Param force query bool false "approve even if the current batch is not ready"
+// @Success 200 {object} apis.Response{code=int,data=string}
+// @Failure 500 {object} apis.Response{code=int,data=string}
+// @Router /envs/{envName}/rollouts/{rolloutName}/approve-next [put]
+func (s *APIServer) ApproveNextBatch(c *gin.Context) {
+	var force bool
+	if v := c.Query("force"); v != "" {
+		var err error
+		if force, err = strconv.ParseBool(v); err != nil {
+			util.HandleError(c, util.InvalidArgument, "force must be a boolean")
+			return
+		}
+	}
+	ctx := util.GetContext(c)
+	envMeta, err := env.GetEnvFromCluster(ctx, s.kubeClient(c), c.Param("envName"))
+	if err != nil {
+		util.HandleError(c, util.StatusInternalServerError, err.Error())
+		return
+	}
+	name := c.Param("rolloutName")
+	batch, err := common.ApproveNextBatch(ctx, s.kubeClient(c), name, envMeta.Namespace, force)
+	if err != nil {
+		util.HandleError(c, util.StatusInternalServerError, err.Error())
+		return
+	}
+	util.AssembleResponse(c, fmt.Sprintf("batch %d of rollout %s is approved", batch, name), nil)
+}
+
+// AbortRollout aborts a rollout in progress
+// @tags rollouts
+// @ID AbortRollout
+// @Summary abort a rollout, it fails at the current batch and the upgraded batches are kept
+// @Param envName path string true "environment name"
+// @Param rolloutName path string true "rollout name"
+// @Success 200 {object} apis.Response{code=int,data=string}
+// @Failure 500 {object} apis.Response{code=int,data=string}
+// @Router /envs/{envName}/rollouts/{rolloutName}/abort [put]
+func (s *APIServer) AbortRollout(c *gin.Context) {
+	ctx := util.GetContext(c)
+	envMeta, err := env.GetEnvFromCluster(ctx, s.kubeClient(c), c.Param("envName"))
+	if err != nil {
+		util.HandleError(c, util.StatusInternalServerError, err.Error())
+		return
+	}
+	name := c.Param("rolloutName")
+	if err := common.AbortRollout(ctx, s.kubeClient(c), name, envMeta.Namespace); err != nil {
+		util.HandleError(c, util.StatusInternalServerError, err.Error())
+		return
+	}
+	util.AssembleResponse(c, fmt.Sprintf("rollout %s is aborted", name), nil)
+}
+
+// RollbackRollout rolls back a rollout to its source revision
+// @tags rollouts
+// @ID RollbackRollout
+// @Summary rollback a rollout to its source revision
+// @Param envName path string true "environment name"
+// @Param rolloutName path string true "rollout name"
+// @Success 200 {object} apis.Response{code=int,data=string}
+// @Failure 500 {object} apis.Response{code=int,data=string}
+// @Router /envs/{envName}/rollouts/{rolloutName}/rollback [put]
+func (s *APIServer) RollbackRollout(c *gin.Context) {
+	ctx := util.GetContext(c)
+	envMeta, err := env.GetEnvFromCluster(ctx, s.kubeClient(c), c.Param("envName"))
+	if err != nil {
+		util.HandleError(c, util.StatusInternalServerError, err.Error())
+		return
+	}
+	name := c.Param("rolloutName")
+	rollout, err := common.RollbackRollout(ctx, s.kubeClient(c), name, envMeta.Namespace)
+	if err != nil {
+		util.HandleError(c, util.StatusInternalServerError, err.Error())
+		return
+	}
+	util.AssembleResponse(c, fmt.Sprintf("rollout %s is rolling back to revision %s", name,
+		rollout.Spec.TargetAppRevisionName), nil)
+}
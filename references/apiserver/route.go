@@ -27,6 +27,7 @@ import (
 	"github.com/gin-contrib/static"
 	"github.com/gin-gonic/gin"
 
+	"github.com/oam-dev/kubevela/references/apiserver/auth"
 	// swagger json data
 	_ "github.com/oam-dev/kubevela/references/apiserver/docs"
 	"github.com/oam-dev/kubevela/references/apiserver/util"
@@ -77,6 +78,13 @@ func (s *APIServer) setupRoute(staticPath string) http.Handler {
 	router.Use(util.ValidateHeaders())
 	// all requests start with /api
 	api := router.Group(util.RootPath)
+	if s.authn != nil {
+		api.Use(auth.Authenticate(s.authn))
+		if s.authz != nil {
+			api.Use(auth.Authorize(s.authz, s.resolveNamespace))
+		}
+		api.Use(s.impersonate())
+	}
 	// env related operation
 	envs := api.Group(util.EnvironmentPath)
 	{
@@ -88,6 +96,16 @@ func (s *APIServer) setupRoute(staticPath string) http.Handler {
 		envs.GET("", s.ListEnv)
 		envs.DELETE("/:envName", s.DeleteEnv)
 		envs.PATCH("/:envName", s.SetEnv)
+		// rollout related operation
+		rollouts := envs.Group("/:envName/rollouts")
+		{
+			rollouts.GET("/:rolloutName", s.GetRollout)
+			rollouts.PUT("/:rolloutName/pause", s.PauseRollout)
+			rollouts.PUT("/:rolloutName/resume", s.ResumeRollout)
+			rollouts.PUT("/:rolloutName/approve-next", s.ApproveNextBatch)
+			rollouts.PUT("/:rolloutName/abort", s.AbortRollout)
+			rollouts.PUT("/:rolloutName/rollback", s.RollbackRollout)
+		}
 		// app related operation
 		apps := envs.Group("/:envName/apps")
 		{
@@ -97,6 +115,10 @@ func (s *APIServer) setupRoute(staticPath string) http.Handler {
 			apps.GET("", s.ListApps)
 			apps.DELETE("/:appName", s.DeleteApps)
 			apps.POST("/", s.CreateApplication)
+			// revision related operation
+			apps.GET("/:appName/revisions", s.ListRevisions)
+			apps.GET("/:appName/revisions/:revision/diff", s.DiffRevisions)
+			apps.POST("/:appName/rollback/:revision", s.RollbackApp)
 
 			// component related operation
 			components := apps.Group("/:appName/components")
@@ -47,7 +47,7 @@ func (s *APIServer) GetTrait(c *gin.Context) {
 	var capability types.Capability
 	var err error
 
-	if capability, err = common.GetTraitDefinition("default", s.c, &workloadType, traitType); err != nil {
+	if capability, err = common.GetTraitDefinition("default", s.kubeArgs(c), &workloadType, traitType); err != nil {
 		util.HandleError(c, util.StatusInternalServerError, err)
 		return
 	}
@@ -59,7 +59,7 @@ func (s *APIServer) ListTrait(c *gin.Context) {
 	var traitList []types.Capability
 	var workloadName string
 	var err error
-	if traitList, err = common.ListTraitDefinitions("default", s.c, &workloadName); err != nil {
+	if traitList, err = common.ListTraitDefinitions("default", s.kubeArgs(c), &workloadName); err != nil {
 		util.HandleError(c, util.StatusInternalServerError, err)
 		return
 	}
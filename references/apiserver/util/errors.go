@@ -36,6 +36,8 @@ const (
 	InvalidArgument
 	UnsupportedMediaType
 	StatusInternalServerError
+	Unauthorized
+	Forbidden
 )
 
 type errorDetail struct {
@@ -48,7 +50,9 @@ var errorDetails = map[Code]errorDetail{
 	PathNotSupported:          {"PathNotSupported", http.StatusNotFound, "'%s' against '%s' is not supported"},
 	InvalidArgument:           {"InvalidArgument", http.StatusBadRequest, "%s"},
 	UnsupportedMediaType:      {"UnsupportedMediaType", http.StatusUnsupportedMediaType, "content type should be 'application/json' or 'application/octet-stream'"},
-	StatusInternalServerError: {"StatusInternalServerError", http.StatusInternalServerError, "%s"}}
+	StatusInternalServerError: {"StatusInternalServerError", http.StatusInternalServerError, "%s"},
+	Unauthorized:              {"Unauthorized", http.StatusUnauthorized, "%s"},
+	Forbidden:                 {"Forbidden", http.StatusForbidden, "%s"}}
 
 // ID returns the error ID.
 func (c Code) ID() string {
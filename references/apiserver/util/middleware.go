@@ -63,6 +63,7 @@ const (
 	RootPath                = "/api"
 	EnvironmentPath         = "/envs"
 	ApplicationPath         = "/apps"
+	RolloutPath             = "/rollouts"
 	WorkloadDefinitionPath  = "/workloads"
 	ComponentDefinitionPath = "/components"
 	ScopeDefinitionPath     = "/scopes"
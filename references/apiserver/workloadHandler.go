@@ -45,7 +45,7 @@ func (s *APIServer) GetWorkload(c *gin.Context) {
 // ListWorkload lists all workloads in the cluster
 func (s *APIServer) ListWorkload(c *gin.Context) {
 	var componentDefinitionList []apis.WorkloadMeta
-	workloads, err := plugins.LoadInstalledCapabilityWithType("default", s.c, types.TypeComponentDefinition)
+	workloads, err := plugins.LoadInstalledCapabilityWithType("default", s.kubeArgs(c), types.TypeComponentDefinition)
 	if err != nil {
 		util.HandleError(c, util.StatusInternalServerError, err)
 		return
@@ -63,6 +63,8 @@ type AppFile struct {
 	UpdateTime time.Time          `json:"updateTime,omitempty"`
 	Services   map[string]Service `json:"services"`
 	Secrets    map[string]string  `json:"secrets,omitempty"`
+	// Profiles patch services for environments, a profile is selected by `vela up --profile` or the current env
+	Profiles map[string]Profile `json:"profiles,omitempty"`
 
 	configGetter config.Store
 	initialized  bool
@@ -77,6 +79,11 @@ func NewAppFile() *AppFile {
 	}
 }
 
+// SetConfigStore sets the store that the user configs of services are read from
+func (app *AppFile) SetConfigStore(s config.Store) {
+	app.configGetter = s
+}
+
 // Load will load appfile from default path
 func Load() (*AppFile, error) {
 	if _, err := os.Stat(DefaultAppfilePath); err == nil {
@@ -157,6 +164,9 @@ func (app *AppFile) BuildOAMApplication(env *types.EnvMeta, io cmdutil.IOStreams
 	servApp := new(v1beta1.Application)
 	servApp.SetNamespace(env.Namespace)
 	servApp.SetName(app.Name)
+	if env.Name != "" {
+		servApp.SetLabels(map[string]string{oam.LabelAppEnv: env.Name})
+	}
 	servApp.Spec.Components = []v1beta1.ApplicationComponent{}
 	for serviceName, svc := range app.GetServices() {
 		if !silence {
@@ -172,11 +182,17 @@ func (app *AppFile) BuildOAMApplication(env *types.EnvMeta, io cmdutil.IOStreams
 			if err != nil {
 				return nil, nil, err
 			}
-			cm, err := config.ToConfigMap(app.configGetter, config.GenConfigMapName(app.Name, serviceName, configname), env.Name, decodedData)
+			// configs read from the secret store are kept in secret rather than configmap
+			var configObj oam.Object
+			if app.configGetter.Type() == config.TypeSecret {
+				configObj, err = config.ToSecret(app.configGetter, config.GenConfigMapName(app.Name, serviceName, configname), env.Name, decodedData)
+			} else {
+				configObj, err = config.ToConfigMap(app.configGetter, config.GenConfigMapName(app.Name, serviceName, configname), env.Name, decodedData)
+			}
 			if err != nil {
 				return nil, nil, err
 			}
-			auxiliaryObjects = append(auxiliaryObjects, cm)
+			auxiliaryObjects = append(auxiliaryObjects, configObj)
 		}
 		comp, err := svc.RenderServiceToApplicationComponent(tm, serviceName)
 		if err != nil {
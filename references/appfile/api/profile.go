@@ -0,0 +1,222 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	// PatchKey is the key of a list item in profile to indicate how the item is merged, only "delete" is supported
+	PatchKey = "$patch"
+	// PatchDelete removes the item with the same name from the list of base Appfile
+	PatchDelete = "delete"
+	// MergeKey is the key to identify items of lists which are merged rather than replaced
+	MergeKey = "name"
+)
+
+// Profile patches the services of Appfile for an environment, fields of a service are merged into the base Appfile
+// recursively and a field set to null is removed, e.g. a trait of the service.
+type Profile struct {
+	Services map[string]Service `json:"services,omitempty"`
+}
+
+// OverlayFile returns the overlay file of a profile for an Appfile, e.g. vela.prod.yaml for vela.yaml
+func OverlayFile(filename, profile string) string {
+	ext := filepath.Ext(filename)
+	return strings.TrimSuffix(filename, ext) + "." + profile + ext
+}
+
+// LoadWithProfile loads appfile from default path and patches it with the profile
+func LoadWithProfile(profile string, optional bool) (*AppFile, error) {
+	for _, filename := range []string{DefaultAppfilePath, DefaultJSONAppfilePath} {
+		if _, err := os.Stat(filename); err == nil {
+			return LoadFromFileWithProfile(filename, profile, optional)
+		}
+	}
+	return LoadFromFileWithProfile(DefaultUnknowFormatAppfilePath, profile, optional)
+}
+
+// LoadFromFileWithProfile reads the Appfile and patches it with the profile, the profile is looked up in profiles of
+// the Appfile first and then the overlay file next to the Appfile. A missing profile is ignored if optional is true.
+func LoadFromFileWithProfile(filename, profile string, optional bool) (*AppFile, error) {
+	af, err := LoadFromFile(filename)
+	if err != nil || profile == "" {
+		return af, err
+	}
+	if _, ok := af.Profiles[profile]; ok {
+		return af, af.ApplyProfile(profile, false)
+	}
+	overlayFile := OverlayFile(filename, profile)
+	if _, err := os.Stat(overlayFile); err != nil {
+		if os.IsNotExist(err) && optional {
+			return af, nil
+		}
+		return nil, fmt.Errorf("profile %s is not found in %s or %s", profile, filename, overlayFile)
+	}
+	overlay, err := LoadFromFile(overlayFile)
+	if err != nil {
+		return nil, err
+	}
+	af.Patch(Profile{Services: overlay.Services})
+	return af, nil
+}
+
+// ApplyProfile patches Appfile with a profile defined in its profiles, a missing profile is ignored if optional is true
+func (app *AppFile) ApplyProfile(profile string, optional bool) error {
+	if profile == "" {
+		return nil
+	}
+	p, ok := app.Profiles[profile]
+	if !ok {
+		if optional {
+			return nil
+		}
+		return fmt.Errorf("profile %s is not found in appfile", profile)
+	}
+	app.Patch(p)
+	return nil
+}
+
+// Patch merges the services of profile into Appfile, a service set to null is removed from Appfile
+func (app *AppFile) Patch(p Profile) {
+	if app.Services == nil {
+		app.Services = make(map[string]Service)
+	}
+	for name, svc := range p.Services {
+		if svc == nil {
+			delete(app.Services, name)
+			continue
+		}
+		base, ok := app.Services[name]
+		if !ok {
+			base = Service{}
+		}
+		app.Services[name] = mergeMap(base, svc)
+	}
+}
+
+// mergeMap merges patch into base recursively, fields set to null in patch are removed
+func mergeMap(base, patch map[string]interface{}) map[string]interface{} {
+	merged := make(map[string]interface{}, len(base))
+	for k, v := range base {
+		merged[k] = v
+	}
+	for k, v := range patch {
+		if v == nil {
+			delete(merged, k)
+			continue
+		}
+		merged[k] = mergeValue(merged[k], v)
+	}
+	return merged
+}
+
+func mergeValue(base, patch interface{}) interface{} {
+	switch p := patch.(type) {
+	case map[string]interface{}:
+		if b, ok := base.(map[string]interface{}); ok {
+			return mergeMap(b, p)
+		}
+		return mergeMap(nil, p)
+	case []interface{}:
+		if b, ok := base.([]interface{}); ok && mergeable(b) && mergeable(p) {
+			return mergeList(b, p)
+		}
+		return removePatchDirectives(p)
+	default:
+		return patch
+	}
+}
+
+// mergeable returns true if every item of the list is an object with a name
+func mergeable(list []interface{}) bool {
+	if len(list) == 0 {
+		return false
+	}
+	for _, item := range list {
+		m, ok := item.(map[string]interface{})
+		if !ok {
+			return false
+		}
+		if _, ok := m[MergeKey].(string); !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// mergeList merges items of lists by their names, items of patch are appended if they are not in base and items with
+// "$patch: delete" are removed
+func mergeList(base, patch []interface{}) []interface{} {
+	merged := make([]interface{}, len(base))
+	copy(merged, base)
+	for _, item := range patch {
+		p := item.(map[string]interface{})
+		name := p[MergeKey].(string)
+		index := -1
+		for i, b := range merged {
+			if b.(map[string]interface{})[MergeKey] == name {
+				index = i
+				break
+			}
+		}
+		if p[PatchKey] == PatchDelete {
+			if index >= 0 {
+				merged = append(merged[:index], merged[index+1:]...)
+			}
+			continue
+		}
+		if index < 0 {
+			merged = append(merged, mergeMap(nil, withoutPatchKey(p)))
+			continue
+		}
+		merged[index] = mergeMap(merged[index].(map[string]interface{}), withoutPatchKey(p))
+	}
+	return merged
+}
+
+// removePatchDirectives drops items to be deleted from a list which replaces the one in base
+func removePatchDirectives(list []interface{}) []interface{} {
+	result := make([]interface{}, 0, len(list))
+	for _, item := range list {
+		if m, ok := item.(map[string]interface{}); ok {
+			if m[PatchKey] == PatchDelete {
+				continue
+			}
+			item = withoutPatchKey(m)
+		}
+		result = append(result, item)
+	}
+	return result
+}
+
+func withoutPatchKey(m map[string]interface{}) map[string]interface{} {
+	if _, ok := m[PatchKey]; !ok {
+		return m
+	}
+	result := make(map[string]interface{}, len(m))
+	for k, v := range m {
+		if k != PatchKey {
+			result[k] = v
+		}
+	}
+	return result
+}
@@ -0,0 +1,142 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const baseAppfile = `
+name: testapp
+services:
+  frontend:
+    image: oamdev/testapp:v1
+    env:
+      - name: LOG_LEVEL
+        value: info
+      - name: DEBUG
+        value: "true"
+    route:
+      domain: example.com
+  backend:
+    image: oamdev/backend:v1
+profiles:
+  prod:
+    services:
+      frontend:
+        image: oamdev/testapp:v2
+        env:
+          - name: LOG_LEVEL
+            value: warn
+          - name: DEBUG
+            $patch: delete
+          - name: REGION
+            value: us
+        scaler:
+          replicas: 3
+        route: null
+      backend: null
+`
+
+func TestLoadFromFileWithProfile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "vela-profile")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	filename := filepath.Join(dir, "vela.yaml")
+	assert.NoError(t, ioutil.WriteFile(filename, []byte(baseAppfile), 0600))
+	assert.NoError(t, ioutil.WriteFile(OverlayFile(filename, "staging"), []byte(`
+services:
+  frontend:
+    image: oamdev/testapp:staging
+    cmd: ["node", "server.js"]
+`), 0600))
+
+	af, err := LoadFromFileWithProfile(filename, "prod", false)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]Service{
+		"frontend": {
+			"image": "oamdev/testapp:v2",
+			"env": []interface{}{
+				map[string]interface{}{"name": "LOG_LEVEL", "value": "warn"},
+				map[string]interface{}{"name": "REGION", "value": "us"},
+			},
+			"scaler": map[string]interface{}{"replicas": float64(3)},
+		},
+	}, af.Services)
+
+	af, err = LoadFromFileWithProfile(filename, "staging", false)
+	assert.NoError(t, err)
+	assert.Equal(t, "oamdev/testapp:staging", af.Services["frontend"]["image"])
+	assert.Equal(t, []interface{}{"node", "server.js"}, af.Services["frontend"]["cmd"])
+	assert.Equal(t, "oamdev/backend:v1", af.Services["backend"]["image"])
+
+	af, err = LoadFromFileWithProfile(filename, "dev", true)
+	assert.NoError(t, err)
+	assert.Equal(t, "oamdev/testapp:v1", af.Services["frontend"]["image"])
+
+	_, err = LoadFromFileWithProfile(filename, "dev", false)
+	assert.EqualError(t, err, "profile dev is not found in "+filename+" or "+filepath.Join(dir, "vela.dev.yaml"))
+}
+
+func TestMergeValue(t *testing.T) {
+	testCases := map[string]struct {
+		base     interface{}
+		patch    interface{}
+		expected interface{}
+	}{
+		"scalar": {
+			base:     "v1",
+			patch:    "v2",
+			expected: "v2",
+		},
+		"list without names is replaced": {
+			base:     []interface{}{"a", "b"},
+			patch:    []interface{}{"c"},
+			expected: []interface{}{"c"},
+		},
+		"list with names is merged": {
+			base: []interface{}{
+				map[string]interface{}{"name": "a", "port": 80},
+				map[string]interface{}{"name": "b", "port": 81},
+			},
+			patch: []interface{}{
+				map[string]interface{}{"name": "b", "port": 8081, "protocol": "UDP"},
+				map[string]interface{}{"name": "c", "port": 82},
+			},
+			expected: []interface{}{
+				map[string]interface{}{"name": "a", "port": 80},
+				map[string]interface{}{"name": "b", "port": 8081, "protocol": "UDP"},
+				map[string]interface{}{"name": "c", "port": 82},
+			},
+		},
+		"field removed from map": {
+			base:     map[string]interface{}{"domain": "example.com", "path": "/"},
+			patch:    map[string]interface{}{"path": nil},
+			expected: map[string]interface{}{"domain": "example.com"},
+		},
+	}
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, mergeValue(tc.base, tc.patch))
+		})
+	}
+}
@@ -108,12 +108,44 @@ func (l *LiveDiffOption) Diff(ctx context.Context, app *v1beta1.Application, app
 	if err != nil {
 		return nil, errors.WithMessagef(err, "cannot generate diff manifest for AppRevision %q", appRevision.Name)
 	}
-	diffResult := l.calculateDiff(oldManifest, newManifest)
+	diffResult := calculateDiff(oldManifest, newManifest)
 	return diffResult, nil
 }
 
+// Estimate estimates the resources of the application by dry-run and the resources of the living AppRevision,
+// the cost is calculated if the price table is not nil
+func (l *LiveDiffOption) Estimate(ctx context.Context, app *v1beta1.Application, appRevision *v1beta1.ApplicationRevision,
+	prices *PriceTable) (*Estimate, *Estimate, error) {
+	ac, comps, err := l.ExecuteDryRun(ctx, app)
+	if err != nil {
+		return nil, nil, errors.WithMessagef(err, "cannot dry-run for app %q", app.Name)
+	}
+	target, err := EstimateApplication(ac, comps, prices)
+	if err != nil {
+		return nil, nil, errors.WithMessagef(err, "cannot estimate resources of app %q", app.Name)
+	}
+	base, err := EstimateAppRevision(appRevision, prices)
+	if err != nil {
+		return nil, nil, errors.WithMessagef(err, "cannot estimate resources of AppRevision %q", appRevision.Name)
+	}
+	return target, base, nil
+}
+
+// DiffRevisions calculates diff between the rendered results of two AppRevisions, no dry-run is needed
+func DiffRevisions(base, target *v1beta1.ApplicationRevision) (*DiffEntry, error) {
+	baseManifest, err := generateManifestFromAppRevision(base)
+	if err != nil {
+		return nil, errors.WithMessagef(err, "cannot generate diff manifest for AppRevision %q", base.Name)
+	}
+	targetManifest, err := generateManifestFromAppRevision(target)
+	if err != nil {
+		return nil, errors.WithMessagef(err, "cannot generate diff manifest for AppRevision %q", target.Name)
+	}
+	return calculateDiff(baseManifest, targetManifest), nil
+}
+
 // calculateDiff calculate diff between two application and their sub-resources
-func (l *LiveDiffOption) calculateDiff(oldApp, newApp *manifest) *DiffEntry {
+func calculateDiff(oldApp, newApp *manifest) *DiffEntry {
 	emptyManifest := &manifest{}
 	r := &DiffEntry{
 		Name: oldApp.Name,
@@ -332,21 +364,38 @@ func generateManifest(app *v1beta1.Application, ac *v1alpha2.ApplicationConfigur
 	return r, nil
 }
 
-// generateManifestFromAppRevision generates manifest from an AppRevision
-func generateManifestFromAppRevision(appRevision *v1beta1.ApplicationRevision) (*manifest, error) {
+// loadAppRevision loads the AppConfig and components of an AppRevision
+func loadAppRevision(appRevision *v1beta1.ApplicationRevision) (*v1alpha2.ApplicationConfiguration, []*v1alpha2.Component, error) {
 	ac := &v1alpha2.ApplicationConfiguration{}
 	if err := json.Unmarshal(appRevision.Spec.ApplicationConfiguration.Raw, ac); err != nil {
-		return nil, errors.Wrap(err, "cannot unmarshal appconfig")
+		return nil, nil, errors.Wrap(err, "cannot unmarshal appconfig")
+	}
+	// components of the AppConfig in an AppRevision refer to component revisions
+	for i := range ac.Spec.Components {
+		acc := &ac.Spec.Components[i]
+		if acc.ComponentName == "" && acc.RevisionName != "" {
+			acc.ComponentName = extractNameFromRevisionName(acc.RevisionName)
+			acc.RevisionName = ""
+		}
 	}
 
 	comps := []*v1alpha2.Component{}
 	for _, rawComp := range appRevision.Spec.Components {
 		c := &v1alpha2.Component{}
 		if err := json.Unmarshal(rawComp.Raw.Raw, c); err != nil {
-			return nil, errors.Wrap(err, "cannot unmarshal component")
+			return nil, nil, errors.Wrap(err, "cannot unmarshal component")
 		}
 		comps = append(comps, c)
 	}
+	return ac, comps, nil
+}
+
+// generateManifestFromAppRevision generates manifest from an AppRevision
+func generateManifestFromAppRevision(appRevision *v1beta1.ApplicationRevision) (*manifest, error) {
+	ac, comps, err := loadAppRevision(appRevision)
+	if err != nil {
+		return nil, err
+	}
 
 	app := appRevision.Spec.Application
 	// app in appRevision has no name & namespace
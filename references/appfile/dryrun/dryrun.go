@@ -63,7 +63,7 @@ func (d *Option) ExecuteDryRun(ctx context.Context, app *v1beta1.Application) (*
 	if err != nil {
 		return nil, nil, errors.WithMessage(err, "cannot generate appFile from application")
 	}
-	ac, comps, err := appFile.GenerateApplicationConfiguration()
+	ac, comps, err := appFile.GenerateApplicationConfiguration(ctx)
 	if err != nil {
 		return nil, nil, errors.WithMessage(err, "cannot generate AppConfig and Components")
 	}
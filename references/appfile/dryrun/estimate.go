@@ -0,0 +1,428 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dryrun
+
+import (
+	"io/ioutil"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/yaml"
+
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1alpha2"
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
+	"github.com/oam-dev/kubevela/pkg/oam"
+)
+
+const gibibyte = 1 << 30
+
+// Resources are the amount of compute and storage resources
+type Resources struct {
+	CPU     resource.Quantity `json:"cpu"`
+	Memory  resource.Quantity `json:"memory"`
+	Storage resource.Quantity `json:"storage"`
+}
+
+// Add adds the resources of r2 to r
+func (r *Resources) Add(r2 Resources) {
+	r.CPU.Add(r2.CPU)
+	r.Memory.Add(r2.Memory)
+	r.Storage.Add(r2.Storage)
+}
+
+// times returns the resources multiplied by n
+func (r Resources) times(n int32) Resources {
+	return Resources{
+		CPU:     *resource.NewMilliQuantity(r.CPU.MilliValue()*int64(n), resource.DecimalSI),
+		Memory:  *resource.NewQuantity(r.Memory.Value()*int64(n), resource.BinarySI),
+		Storage: *resource.NewQuantity(r.Storage.Value()*int64(n), resource.BinarySI),
+	}
+}
+
+// ComponentEstimate is the estimated resources of a component, or the total of an application
+type ComponentEstimate struct {
+	Name string `json:"name"`
+	// Replicas is the number of pods, it's the min replicas of the autoscaler if the component is autoscaled
+	Replicas int32 `json:"replicas"`
+	// MaxReplicas is the max replicas of the autoscaler, it equals Replicas if the component is not autoscaled
+	MaxReplicas int32 `json:"maxReplicas"`
+	// MaxUnbounded is true if an autoscaler has no max replicas, MaxReplicas and the max resources only count its
+	// min replicas
+	MaxUnbounded bool `json:"maxUnbounded,omitempty"`
+	// PerNode is true if the pods run on every node like a DaemonSet, resources are counted for one node
+	PerNode bool `json:"perNode,omitempty"`
+	// Requests are the resources requested by the pods with Replicas and the persistent volume claims
+	Requests Resources `json:"requests"`
+	// Limits are the resource limits of the pods with Replicas and the persistent volume claims
+	Limits Resources `json:"limits"`
+	// MaxRequests are the resources requested by the pods with MaxReplicas and the persistent volume claims
+	MaxRequests Resources `json:"maxRequests"`
+	// MaxLimits are the resource limits of the pods with MaxReplicas and the persistent volume claims
+	MaxLimits Resources `json:"maxLimits"`
+	// Cost is the price of Requests, MaxCost is the price of MaxRequests, they are zero without a price table
+	Cost    float64 `json:"cost,omitempty"`
+	MaxCost float64 `json:"maxCost,omitempty"`
+}
+
+// Estimate is the estimated resources of an application
+type Estimate struct {
+	Components []ComponentEstimate `json:"components"`
+	Total      ComponentEstimate   `json:"total"`
+}
+
+// Component returns the estimate of a component, nil if it's not found
+func (e *Estimate) Component(name string) *ComponentEstimate {
+	for i := range e.Components {
+		if e.Components[i].Name == name {
+			return &e.Components[i]
+		}
+	}
+	return nil
+}
+
+// PriceTable is the price of resources per period, such as a month
+type PriceTable struct {
+	Currency string `json:"currency,omitempty"`
+	Period   string `json:"period,omitempty"`
+	// CPU is the price of one core
+	CPU float64 `json:"cpu"`
+	// Memory is the price of one GiB of memory
+	Memory float64 `json:"memory"`
+	// Storage is the price of one GiB of persistent storage
+	Storage float64 `json:"storage"`
+}
+
+// LoadPriceTable loads a price table from a YAML or JSON file
+func LoadPriceTable(path string) (*PriceTable, error) {
+	data, err := ioutil.ReadFile(filepath.Clean(path))
+	if err != nil {
+		return nil, errors.Wrapf(err, "cannot read price table %s", path)
+	}
+	prices := &PriceTable{}
+	if err := yaml.Unmarshal(data, prices); err != nil {
+		return nil, errors.Wrapf(err, "invalid price table %s", path)
+	}
+	return prices, nil
+}
+
+// Price returns the price of the resources
+func (p *PriceTable) Price(r Resources) float64 {
+	return float64(r.CPU.MilliValue())/1000*p.CPU +
+		float64(r.Memory.Value())/gibibyte*p.Memory +
+		float64(r.Storage.Value())/gibibyte*p.Storage
+}
+
+// EstimateApplication estimates the resources of an application from the dry-run result, the cost is calculated
+// if the price table is not nil
+func EstimateApplication(ac *v1alpha2.ApplicationConfiguration, comps []*v1alpha2.Component, prices *PriceTable) (*Estimate, error) {
+	manifests, err := RenderManifests(ac, comps)
+	if err != nil {
+		return nil, err
+	}
+	return EstimateManifests(manifests, prices)
+}
+
+// EstimateAppRevision estimates the resources of an application revision, the cost is calculated if the price
+// table is not nil
+func EstimateAppRevision(appRevision *v1beta1.ApplicationRevision, prices *PriceTable) (*Estimate, error) {
+	ac, comps, err := loadAppRevision(appRevision)
+	if err != nil {
+		return nil, err
+	}
+	return EstimateApplication(ac, comps, prices)
+}
+
+// componentPods collects the pods and storage of a component from its workloads and traits
+type componentPods struct {
+	workloads []*workloadPods
+	scalers   []podScaler
+	// storage claimed by PersistentVolumeClaims, it doesn't grow with replicas
+	storage resource.Quantity
+}
+
+// workloadPods are the pods of a workload
+type workloadPods struct {
+	name string
+	// requests and limits of one pod, including the storage claimed by the volume claim templates for each pod
+	requests, limits Resources
+	replicas         int32
+	perNode          bool
+}
+
+// podScaler is a scaler trait overriding the replicas of workloads
+type podScaler struct {
+	// target is the name of the scaled workload, the scaler applies to all workloads of the component if it's empty
+	target      string
+	replicas    int32
+	maxReplicas int32
+	// unbounded is true if the autoscaler has no max replicas
+	unbounded bool
+}
+
+// EstimateManifests estimates the resources of the rendered workloads and traits grouped by components.
+// Pods are counted from the pod templates of workloads with their replicas, which are overridden by the
+// ManualScalerTraits and the min and max replicas of HorizontalPodAutoscalers. Persistent storage is counted from
+// PersistentVolumeClaims and the volume claim templates of StatefulSets.
+func EstimateManifests(manifests []*unstructured.Unstructured, prices *PriceTable) (*Estimate, error) {
+	var names []string
+	components := map[string]*componentPods{}
+	for _, m := range manifests {
+		name := m.GetLabels()[oam.LabelAppComponent]
+		cp, ok := components[name]
+		if !ok {
+			cp = &componentPods{}
+			components[name] = cp
+			names = append(names, name)
+		}
+		if err := cp.add(m); err != nil {
+			return nil, err
+		}
+	}
+
+	result := &Estimate{Total: ComponentEstimate{Name: "total"}}
+	for _, name := range names {
+		ce := components[name].estimate(name, prices)
+		result.Components = append(result.Components, ce)
+		result.Total.add(ce)
+	}
+	return result, nil
+}
+
+// add adds a workload or trait of the component
+func (cp *componentPods) add(m *unstructured.Unstructured) error {
+	obj := m.Object
+	switch m.GetKind() {
+	case "ManualScalerTrait":
+		if replicas, found, _ := nestedInt(obj, "spec", "replicaCount"); found {
+			target, _, _ := unstructured.NestedString(obj, "spec", "workloadRef", "name")
+			cp.scalers = append(cp.scalers, podScaler{target: target, replicas: int32(replicas), maxReplicas: int32(replicas)})
+		}
+		return nil
+	case "HorizontalPodAutoscaler":
+		minReplicas, found, _ := nestedInt(obj, "spec", "minReplicas")
+		if !found {
+			minReplicas = 1
+		}
+		maxReplicas, found, _ := nestedInt(obj, "spec", "maxReplicas")
+		target, _, _ := unstructured.NestedString(obj, "spec", "scaleTargetRef", "name")
+		cp.scalers = append(cp.scalers, podScaler{
+			target:      target,
+			replicas:    int32(minReplicas),
+			maxReplicas: int32(maxReplicas),
+			unbounded:   !found || maxReplicas <= 0,
+		})
+		return nil
+	case "PersistentVolumeClaim":
+		storage, err := claimedStorage(obj, "spec")
+		if err != nil {
+			return errors.Wrapf(err, "invalid storage of PersistentVolumeClaim %s", m.GetName())
+		}
+		cp.storage.Add(storage)
+		return nil
+	}
+
+	spec, path := podSpecOf(m)
+	if spec == nil {
+		return nil
+	}
+	podSpec := &corev1.PodSpec{}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(spec, podSpec); err != nil {
+		return errors.Wrapf(err, "invalid pod template of %s %s", m.GetKind(), m.GetName())
+	}
+	wp := &workloadPods{name: m.GetName(), replicas: 1}
+	wp.requests, wp.limits = podResources(podSpec)
+	claims, _, _ := unstructured.NestedSlice(obj, "spec", "volumeClaimTemplates")
+	for _, claim := range claims {
+		claim, ok := claim.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		storage, err := claimedStorage(claim, "spec")
+		if err != nil {
+			return errors.Wrapf(err, "invalid volume claim template of %s %s", m.GetKind(), m.GetName())
+		}
+		wp.requests.Storage.Add(storage)
+		wp.limits.Storage.Add(storage)
+	}
+
+	switch {
+	case m.GetKind() == "DaemonSet":
+		wp.perNode = true
+	case m.GetKind() == "Job" || m.GetKind() == "CronJob":
+		// the job spec is the parent of the pod template
+		jobSpec := append(append([]string{}, path[:len(path)-2]...), "parallelism")
+		if parallelism, found, _ := nestedInt(obj, jobSpec...); found {
+			wp.replicas = int32(parallelism)
+		}
+	default:
+		if replicas, found, _ := nestedInt(obj, "spec", "replicas"); found {
+			wp.replicas = int32(replicas)
+		}
+	}
+	cp.workloads = append(cp.workloads, wp)
+	return nil
+}
+
+// scalerOf returns the last scaler of the workload, nil if the workload is not scaled by traits
+func (cp *componentPods) scalerOf(wp *workloadPods) *podScaler {
+	var scaler *podScaler
+	for i, s := range cp.scalers {
+		if s.target == "" || s.target == wp.name {
+			scaler = &cp.scalers[i]
+		}
+	}
+	return scaler
+}
+
+// estimate calculates the resources of all pods of the component, the replicas of scaler traits take precedence
+// over the workloads
+func (cp *componentPods) estimate(name string, prices *PriceTable) ComponentEstimate {
+	ce := ComponentEstimate{Name: name}
+	for _, wp := range cp.workloads {
+		replicas, maxReplicas := wp.replicas, wp.replicas
+		if scaler := cp.scalerOf(wp); scaler != nil {
+			replicas, maxReplicas = scaler.replicas, scaler.maxReplicas
+			if scaler.unbounded {
+				// the max of an unbounded autoscaler is unknown, only its min replicas are counted
+				maxReplicas = scaler.replicas
+				ce.MaxUnbounded = true
+			}
+		}
+		ce.Replicas += replicas
+		ce.MaxReplicas += maxReplicas
+		ce.PerNode = ce.PerNode || wp.perNode
+		ce.Requests.Add(wp.requests.times(replicas))
+		ce.Limits.Add(wp.limits.times(replicas))
+		ce.MaxRequests.Add(wp.requests.times(maxReplicas))
+		ce.MaxLimits.Add(wp.limits.times(maxReplicas))
+	}
+	for _, r := range []*Resources{&ce.Requests, &ce.Limits, &ce.MaxRequests, &ce.MaxLimits} {
+		r.Storage.Add(cp.storage)
+	}
+	if prices != nil {
+		ce.Cost, ce.MaxCost = prices.Price(ce.Requests), prices.Price(ce.MaxRequests)
+	}
+	return ce
+}
+
+// add adds the estimate of a component to the total
+func (e *ComponentEstimate) add(ce ComponentEstimate) {
+	e.Replicas += ce.Replicas
+	e.MaxReplicas += ce.MaxReplicas
+	e.MaxUnbounded = e.MaxUnbounded || ce.MaxUnbounded
+	e.PerNode = e.PerNode || ce.PerNode
+	e.Requests.Add(ce.Requests)
+	e.Limits.Add(ce.Limits)
+	e.MaxRequests.Add(ce.MaxRequests)
+	e.MaxLimits.Add(ce.MaxLimits)
+	e.Cost += ce.Cost
+	e.MaxCost += ce.MaxCost
+}
+
+// podSpecOf finds the pod spec of a workload and returns its path
+func podSpecOf(u *unstructured.Unstructured) (map[string]interface{}, []string) {
+	paths := [][]string{
+		{"spec", "template", "spec"},
+		{"spec", "jobTemplate", "spec", "template", "spec"},
+	}
+	if u.GetKind() == "Pod" {
+		paths = append(paths, []string{"spec"})
+	}
+	for _, path := range paths {
+		spec, found, err := unstructured.NestedMap(u.Object, path...)
+		if err != nil || !found {
+			continue
+		}
+		if _, ok := spec["containers"]; ok {
+			return spec, path
+		}
+	}
+	return nil, nil
+}
+
+// podResources calculates the effective requests and limits of a pod as the scheduler does, it's the larger one of
+// the sum of containers and the max of init containers. Requests default to limits if they are not set.
+func podResources(spec *corev1.PodSpec) (Resources, Resources) {
+	var requests, limits Resources
+	for _, c := range spec.Containers {
+		r, l := containerResources(c)
+		requests.Add(r)
+		limits.Add(l)
+	}
+	for _, c := range spec.InitContainers {
+		r, l := containerResources(c)
+		maxQuantity(&requests.CPU, r.CPU)
+		maxQuantity(&requests.Memory, r.Memory)
+		maxQuantity(&limits.CPU, l.CPU)
+		maxQuantity(&limits.Memory, l.Memory)
+	}
+	return requests, limits
+}
+
+func containerResources(c corev1.Container) (Resources, Resources) {
+	var requests, limits Resources
+	for name, q := range map[corev1.ResourceName]*resource.Quantity{
+		corev1.ResourceCPU: &requests.CPU, corev1.ResourceMemory: &requests.Memory} {
+		if v, ok := c.Resources.Requests[name]; ok {
+			*q = v.DeepCopy()
+		} else if v, ok := c.Resources.Limits[name]; ok {
+			*q = v.DeepCopy()
+		}
+	}
+	if v, ok := c.Resources.Limits[corev1.ResourceCPU]; ok {
+		limits.CPU = v.DeepCopy()
+	}
+	if v, ok := c.Resources.Limits[corev1.ResourceMemory]; ok {
+		limits.Memory = v.DeepCopy()
+	}
+	return requests, limits
+}
+
+func maxQuantity(q *resource.Quantity, v resource.Quantity) {
+	if v.Cmp(*q) > 0 {
+		*q = v.DeepCopy()
+	}
+}
+
+// nestedInt gets an integer field, numbers decoded from JSON are float64 while the ones from the API server are int64
+func nestedInt(obj map[string]interface{}, fields ...string) (int64, bool, error) {
+	v, found, err := unstructured.NestedFieldNoCopy(obj, fields...)
+	if err != nil || !found {
+		return 0, found, err
+	}
+	switch n := v.(type) {
+	case int64:
+		return n, true, nil
+	case float64:
+		return int64(n), true, nil
+	default:
+		return 0, false, errors.Errorf("%v accessor error: %v is of the type %T, expected int64", fields, v, v)
+	}
+}
+
+// claimedStorage gets the storage requested by a persistent volume claim spec
+func claimedStorage(obj map[string]interface{}, spec string) (resource.Quantity, error) {
+	storage, found, _ := unstructured.NestedString(obj, spec, "resources", "requests", "storage")
+	if !found {
+		return resource.Quantity{}, nil
+	}
+	return resource.ParseQuantity(storage)
+}
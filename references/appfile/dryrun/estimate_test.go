@@ -0,0 +1,283 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dryrun
+
+import (
+	"encoding/json"
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/yaml"
+
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
+)
+
+const estimateManifests = `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+  labels:
+    app.oam.dev/component: web
+spec:
+  replicas: 2
+  template:
+    spec:
+      initContainers:
+      - name: init
+        image: busybox
+        resources:
+          requests:
+            cpu: "1"
+      containers:
+      - name: web
+        image: nginx
+        resources:
+          requests:
+            cpu: 250m
+            memory: 256Mi
+          limits:
+            cpu: 500m
+            memory: 512Mi
+      - name: sidecar
+        image: envoy
+        resources:
+          limits:
+            cpu: 100m
+            memory: 128Mi
+---
+apiVersion: autoscaling/v1
+kind: HorizontalPodAutoscaler
+metadata:
+  name: web
+  labels:
+    app.oam.dev/component: web
+spec:
+  minReplicas: 3
+  maxReplicas: 5
+---
+apiVersion: apps/v1
+kind: StatefulSet
+metadata:
+  name: db
+  labels:
+    app.oam.dev/component: db
+spec:
+  replicas: 1
+  template:
+    spec:
+      containers:
+      - name: db
+        image: mysql
+        resources:
+          requests:
+            cpu: "2"
+            memory: 4Gi
+  volumeClaimTemplates:
+  - metadata:
+      name: data
+    spec:
+      resources:
+        requests:
+          storage: 10Gi
+---
+apiVersion: core.oam.dev/v1alpha2
+kind: ManualScalerTrait
+metadata:
+  name: db
+  labels:
+    app.oam.dev/component: db
+spec:
+  replicaCount: 3
+---
+apiVersion: v1
+kind: PersistentVolumeClaim
+metadata:
+  name: shared
+  labels:
+    app.oam.dev/component: db
+spec:
+  resources:
+    requests:
+      storage: 20Gi
+---
+apiVersion: apps/v1
+kind: DaemonSet
+metadata:
+  name: agent
+  labels:
+    app.oam.dev/component: agent
+spec:
+  template:
+    spec:
+      containers:
+      - name: agent
+        image: fluentd
+        resources:
+          requests:
+            cpu: 100m
+            memory: 64Mi
+`
+
+const multiWorkloadManifests = `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: frontend
+  labels:
+    app.oam.dev/component: shop
+spec:
+  replicas: 2
+  template:
+    spec:
+      containers:
+      - name: frontend
+        image: nginx
+        resources:
+          requests:
+            cpu: 100m
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: backend
+  labels:
+    app.oam.dev/component: shop
+spec:
+  replicas: 1
+  template:
+    spec:
+      containers:
+      - name: backend
+        image: java
+        resources:
+          requests:
+            cpu: "1"
+---
+apiVersion: autoscaling/v1
+kind: HorizontalPodAutoscaler
+metadata:
+  name: backend
+  labels:
+    app.oam.dev/component: shop
+spec:
+  scaleTargetRef:
+    apiVersion: apps/v1
+    kind: Deployment
+    name: backend
+  minReplicas: 3
+`
+
+func loadEstimateManifests(docs string) []*unstructured.Unstructured {
+	var manifests []*unstructured.Unstructured
+	for _, doc := range strings.Split(docs, "---") {
+		b, err := yaml.YAMLToJSON([]byte(doc))
+		Expect(err).Should(BeNil())
+		u := &unstructured.Unstructured{}
+		Expect(u.UnmarshalJSON(b)).Should(Succeed())
+		manifests = append(manifests, u)
+	}
+	return manifests
+}
+
+func expectQuantity(q resource.Quantity, expected string) {
+	ExpectWithOffset(1, q.Cmp(resource.MustParse(expected))).Should(BeZero(), "expected %s, got %s", expected, q.String())
+}
+
+var _ = Describe("Test estimate resources", func() {
+	It("Test estimate manifests", func() {
+		estimate, err := EstimateManifests(loadEstimateManifests(estimateManifests), nil)
+		Expect(err).Should(BeNil())
+		Expect(estimate.Components).Should(HaveLen(3))
+
+		By("autoscaled workload counts min and max replicas, init containers and default requests")
+		web := estimate.Component("web")
+		Expect(web).ShouldNot(BeNil())
+		Expect(web.Replicas).Should(BeEquivalentTo(3))
+		Expect(web.MaxReplicas).Should(BeEquivalentTo(5))
+		expectQuantity(web.Requests.CPU, "3")
+		expectQuantity(web.Requests.Memory, "1152Mi")
+		expectQuantity(web.Limits.CPU, "1800m")
+		expectQuantity(web.MaxRequests.CPU, "5")
+		expectQuantity(web.MaxLimits.Memory, "3200Mi")
+		Expect(web.Cost).Should(BeZero())
+
+		By("scaler trait overrides replicas and storage is counted per pod and per claim")
+		db := estimate.Component("db")
+		Expect(db).ShouldNot(BeNil())
+		Expect(db.Replicas).Should(BeEquivalentTo(3))
+		Expect(db.MaxReplicas).Should(BeEquivalentTo(3))
+		expectQuantity(db.Requests.CPU, "6")
+		expectQuantity(db.Requests.Memory, "12Gi")
+		expectQuantity(db.Requests.Storage, "50Gi")
+
+		By("daemon set is counted for one node")
+		agent := estimate.Component("agent")
+		Expect(agent).ShouldNot(BeNil())
+		Expect(agent.PerNode).Should(BeTrue())
+		Expect(agent.Replicas).Should(BeEquivalentTo(1))
+
+		Expect(estimate.Total.Replicas).Should(BeEquivalentTo(7))
+		Expect(estimate.Total.MaxReplicas).Should(BeEquivalentTo(9))
+		expectQuantity(estimate.Total.Requests.CPU, "9100m")
+		expectQuantity(estimate.Total.Requests.Storage, "50Gi")
+	})
+
+	It("Test estimate a component with multiple workloads", func() {
+		estimate, err := EstimateManifests(loadEstimateManifests(multiWorkloadManifests), nil)
+		Expect(err).Should(BeNil())
+		shop := estimate.Component("shop")
+		Expect(shop).ShouldNot(BeNil())
+
+		By("replicas are counted per workload and the autoscaler only scales its target")
+		Expect(shop.Replicas).Should(BeEquivalentTo(5))
+		expectQuantity(shop.Requests.CPU, "3200m")
+
+		By("autoscaler without max replicas is unbounded")
+		Expect(shop.MaxUnbounded).Should(BeTrue())
+		Expect(shop.MaxReplicas).Should(BeEquivalentTo(5))
+		expectQuantity(shop.MaxRequests.CPU, "3200m")
+		Expect(estimate.Total.MaxUnbounded).Should(BeTrue())
+	})
+
+	It("Test estimate cost with price table", func() {
+		prices := &PriceTable{Currency: "USD", Period: "month", CPU: 20, Memory: 4, Storage: 0.1}
+		estimate, err := EstimateManifests(loadEstimateManifests(estimateManifests), prices)
+		Expect(err).Should(BeNil())
+		db := estimate.Component("db")
+		Expect(db.Cost).Should(BeNumerically("~", 6*20+12*4+50*0.1, 0.001))
+		web := estimate.Component("web")
+		Expect(web.MaxCost).Should(BeNumerically("~", 5*20+1.875*4, 0.001))
+		Expect(estimate.Total.Cost).Should(BeNumerically("~", db.Cost+web.Cost+estimate.Component("agent").Cost, 0.001))
+	})
+
+	It("Test estimate AppRevision", func() {
+		appRevision := &v1beta1.ApplicationRevision{}
+		b, err := yaml.YAMLToJSON([]byte(readDataFromFile("./testdata/diff-apprevision.yaml")))
+		Expect(err).Should(BeNil())
+		Expect(json.Unmarshal(b, appRevision)).Should(Succeed())
+
+		estimate, err := EstimateAppRevision(appRevision, nil)
+		Expect(err).Should(BeNil())
+		Expect(estimate.Components).Should(HaveLen(2))
+		Expect(estimate.Component("myweb-1").Replicas).Should(BeEquivalentTo(2))
+		Expect(estimate.Component("myweb-2").Replicas).Should(BeEquivalentTo(1))
+	})
+})
@@ -0,0 +1,156 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dryrun
+
+import (
+	"github.com/pkg/errors"
+	crdv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/common"
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1alpha2"
+	"github.com/oam-dev/kubevela/pkg/dsl/definition"
+	"github.com/oam-dev/kubevela/pkg/oam"
+	"github.com/oam-dev/kubevela/pkg/oam/discoverymapper"
+	oamutil "github.com/oam-dev/kubevela/pkg/oam/util"
+	common2 "github.com/oam-dev/kubevela/pkg/utils/common"
+)
+
+// HealthScopeDefinitionName is the ScopeDefinition of HealthScope, it's installed with KubeVela so it's always
+// available in offline dry-run
+const HealthScopeDefinitionName = "healthscopes.core.oam.dev"
+
+// NewOfflineDryRunOption creates a dry-run option which never talks to a K8s cluster. Definitions and CRDs are
+// only read from the auxiliaries, kube packages imported by CUE templates are built from the OpenAPI schema,
+// the schema of built-in K8s resources bundled in the binary is used if it's empty.
+func NewOfflineDryRunOption(as []oam.Object, openAPISchema string) (*Option, error) {
+	var err error
+	if openAPISchema == "" {
+		if openAPISchema, err = definition.BundledOpenAPISchema(); err != nil {
+			return nil, errors.WithMessage(err, "generate bundled OpenAPI schema")
+		}
+	}
+	pd, err := definition.NewPackageDiscoverFromOpenAPI(openAPISchema)
+	if err != nil {
+		return nil, errors.WithMessage(err, "load OpenAPI schema")
+	}
+
+	var objs []runtime.Object
+	var crds []*crdv1.CustomResourceDefinition
+	hasHealthScope := false
+	for _, obj := range as {
+		u, err := toUnstructured(obj)
+		if err != nil {
+			return nil, err
+		}
+		switch u.GetKind() {
+		case "CustomResourceDefinition":
+			crd := &crdv1.CustomResourceDefinition{}
+			if err := runtime.DefaultUnstructuredConverter.FromUnstructured(u.Object, crd); err != nil {
+				return nil, errors.Wrapf(err, "invalid CRD %s", u.GetName())
+			}
+			crds = append(crds, crd)
+			continue
+		case v1alpha2.ScopeDefinitionKind:
+			// ScopeDefinitions are always read in v1alpha2, which has the same schema as v1beta1
+			u.SetAPIVersion(v1alpha2.SchemeGroupVersion.String())
+			hasHealthScope = hasHealthScope || u.GetName() == HealthScopeDefinitionName
+		}
+		// definitions without namespace are treated as system definitions
+		if u.GetNamespace() == "" {
+			u.SetNamespace(oam.SystemDefinitonNamespace)
+		}
+		objs = append(objs, u)
+	}
+	if !hasHealthScope {
+		sd := &v1alpha2.ScopeDefinition{}
+		sd.SetGroupVersionKind(v1alpha2.ScopeDefinitionGroupVersionKind)
+		sd.Name = HealthScopeDefinitionName
+		sd.Namespace = oam.SystemDefinitonNamespace
+		sd.Spec.Reference = common.DefinitionReference{Name: HealthScopeDefinitionName}
+		sd.Spec.WorkloadRefsPath = "spec.workloadRefs"
+		sd.Spec.AllowComponentOverlap = true
+		objs = append(objs, sd)
+	}
+	c := fake.NewFakeClientWithScheme(common2.Scheme, objs...)
+	dm := discoverymapper.NewStaticDiscoveryMapper(common2.Scheme, crds...)
+	return NewDryRunOption(c, dm, pd, as), nil
+}
+
+func toUnstructured(obj oam.Object) (*unstructured.Unstructured, error) {
+	if u, ok := obj.(*unstructured.Unstructured); ok {
+		return u.DeepCopy(), nil
+	}
+	data, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+	if err != nil {
+		return nil, err
+	}
+	return &unstructured.Unstructured{Object: data}, nil
+}
+
+// RenderManifests renders the final K8s resources of an AppConfig as the application controller does, workloads
+// and traits are named and labeled, namespaces are set if they are empty.
+func RenderManifests(ac *v1alpha2.ApplicationConfiguration, comps []*v1alpha2.Component) ([]*unstructured.Unstructured, error) {
+	workloads := make(map[string]*runtime.RawExtension, len(comps))
+	for _, comp := range comps {
+		workloads[comp.Name] = &comp.Spec.Workload
+	}
+	var manifests []*unstructured.Unstructured
+	for _, acc := range ac.Spec.Components {
+		raw, ok := workloads[acc.ComponentName]
+		if !ok {
+			return nil, errors.Errorf("component %s is not found", acc.ComponentName)
+		}
+		w, err := oamutil.RawExtension2Unstructured(raw)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid workload of component %s", acc.ComponentName)
+		}
+		// workloads are upgraded in place unless the application is rolled out
+		if w.GetName() == "" {
+			w.SetName(acc.ComponentName)
+		}
+		setManifestMeta(w, ac, acc.ComponentName, oam.ResourceTypeWorkload)
+		manifests = append(manifests, w)
+
+		for i := range acc.Traits {
+			ct := &acc.Traits[i]
+			t, err := oamutil.RawExtension2Unstructured(&ct.Trait)
+			if err != nil {
+				return nil, errors.Wrapf(err, "invalid trait of component %s", acc.ComponentName)
+			}
+			if t.GetName() == "" {
+				t.SetName(oamutil.GenTraitName(acc.ComponentName, ct.DeepCopy(), t.GetLabels()[oam.TraitTypeLabel]))
+			}
+			setManifestMeta(t, ac, acc.ComponentName, oam.ResourceTypeTrait)
+			manifests = append(manifests, t)
+		}
+	}
+	return manifests, nil
+}
+
+func setManifestMeta(u *unstructured.Unstructured, ac *v1alpha2.ApplicationConfiguration, compName, resourceType string) {
+	if u.GetNamespace() == "" {
+		u.SetNamespace(ac.Namespace)
+	}
+	oamutil.AddLabels(u, map[string]string{
+		oam.LabelAppName:         ac.Name,
+		oam.LabelAppComponent:    compName,
+		oam.LabelOAMResourceType: resourceType,
+	})
+}
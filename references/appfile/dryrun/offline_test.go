@@ -0,0 +1,97 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dryrun
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/google/go-cmp/cmp"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/yaml"
+
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
+	"github.com/oam-dev/kubevela/pkg/oam"
+	oamutil "github.com/oam-dev/kubevela/pkg/oam/util"
+)
+
+var _ = Describe("Test offline DryRun", func() {
+	var offlineOpt *Option
+
+	BeforeEach(func() {
+		var defs []oam.Object
+		for _, f := range []string{"./testdata/cd-myworker.yaml", "./testdata/td-myingress.yaml", "./testdata/td-myscaler.yaml"} {
+			def := &unstructured.Unstructured{}
+			Expect(yaml.Unmarshal([]byte(readDataFromFile(f)), &def.Object)).Should(Succeed())
+			defs = append(defs, def)
+		}
+		var err error
+		offlineOpt, err = NewOfflineDryRunOption(defs, "")
+		Expect(err).Should(BeNil())
+	})
+
+	It("Test offline DryRun renders the same as online", func() {
+		app := &v1beta1.Application{}
+		b, err := yaml.YAMLToJSON([]byte(readDataFromFile("./testdata/dryrun-app.yaml")))
+		Expect(err).Should(BeNil())
+		Expect(json.Unmarshal(b, app)).Should(Succeed())
+
+		ac, comps, err := offlineOpt.ExecuteDryRun(context.Background(), app)
+		Expect(err).Should(BeNil())
+		resultACstr, err := yaml.Marshal(ac)
+		Expect(err).Should(BeNil())
+		Expect(cmp.Diff(readDataFromFile("./testdata/dryrun-exp-ac.yaml"), string(resultACstr))).Should(BeEmpty())
+		Expect(comps).ShouldNot(BeEmpty())
+		resultCompStr, err := yaml.Marshal(comps[0])
+		Expect(err).Should(BeNil())
+		Expect(cmp.Diff(readDataFromFile("./testdata/dryrun-exp-comp.yaml"), string(resultCompStr))).Should(BeEmpty())
+
+		By("Render final manifests")
+		ac.Namespace = "default"
+		// traits without a name are named after the component and trait type
+		unnamed, err := oamutil.RawExtension2Unstructured(&ac.Spec.Components[0].Traits[0].Trait)
+		Expect(err).Should(BeNil())
+		unnamed.SetName("")
+		ac.Spec.Components[0].Traits[0].Trait = oamutil.Object2RawExtension(unnamed)
+		manifests, err := RenderManifests(ac, comps)
+		Expect(err).Should(BeNil())
+		Expect(manifests).Should(HaveLen(3))
+		workload := manifests[0]
+		Expect(workload.GetKind()).Should(Equal("Deployment"))
+		Expect(workload.GetName()).Should(Equal("myweb"))
+		Expect(workload.GetNamespace()).Should(Equal("default"))
+		Expect(workload.GetLabels()).Should(HaveKeyWithValue(oam.LabelAppName, "app-dryrun"))
+		Expect(workload.GetLabels()).Should(HaveKeyWithValue(oam.LabelOAMResourceType, oam.ResourceTypeWorkload))
+		Expect(manifests[1].GetName()).Should(HavePrefix("myweb-myingress-"))
+		Expect(manifests[2].GetName()).Should(Equal("myweb"))
+		for _, trait := range manifests[1:] {
+			Expect(trait.GetNamespace()).Should(Equal("default"))
+			Expect(trait.GetLabels()).Should(HaveKeyWithValue(oam.LabelAppComponent, "myweb"))
+			Expect(trait.GetLabels()).Should(HaveKeyWithValue(oam.LabelOAMResourceType, oam.ResourceTypeTrait))
+		}
+	})
+
+	It("Test offline DryRun reports definitions not provided", func() {
+		app := &v1beta1.Application{}
+		app.Name = "app"
+		app.Spec.Components = []v1beta1.ApplicationComponent{{Name: "web", Type: "webservice"}}
+		_, _, err := offlineOpt.ExecuteDryRun(context.Background(), app)
+		Expect(err).ShouldNot(BeNil())
+	})
+})
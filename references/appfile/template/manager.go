@@ -17,7 +17,11 @@ limitations under the License.
 package template
 
 import (
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
 	"github.com/oam-dev/kubevela/apis/types"
+	"github.com/oam-dev/kubevela/pkg/oam"
 	"github.com/oam-dev/kubevela/pkg/utils/common"
 	"github.com/oam-dev/kubevela/references/plugins"
 )
@@ -44,6 +48,33 @@ func Load(namespace string, c common.Args) (Manager, error) {
 	return m, nil
 }
 
+// LoadFromDefinitions creates a manager of the ComponentDefinitions and TraitDefinitions in objs rather than
+// capabilities installed in cluster, other objects are ignored
+func LoadFromDefinitions(objs []oam.Object) (Manager, error) {
+	m := newManager()
+	for _, obj := range objs {
+		u, ok := obj.(*unstructured.Unstructured)
+		if !ok {
+			continue
+		}
+		var capType types.CapType
+		switch u.GetKind() {
+		case v1beta1.ComponentDefinitionKind:
+			capType = types.TypeComponentDefinition
+		case v1beta1.TraitDefinitionKind:
+			capType = types.TypeTrait
+		default:
+			continue
+		}
+		tmpl, _, err := unstructured.NestedString(u.Object, "spec", "schematic", "cue", "template")
+		if err != nil {
+			return nil, err
+		}
+		m.Templates[u.GetName()] = &Template{Captype: capType, Raw: tmpl}
+	}
+	return m, nil
+}
+
 // Template defines a raw template struct
 type Template struct {
 	Captype types.CapType
@@ -0,0 +1,110 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cli
+
+import (
+	"context"
+	"io/ioutil"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/yaml"
+
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
+	"github.com/oam-dev/kubevela/apis/types"
+	common2 "github.com/oam-dev/kubevela/pkg/utils/common"
+	cmdutil "github.com/oam-dev/kubevela/pkg/utils/util"
+	"github.com/oam-dev/kubevela/references/common"
+)
+
+// NewAdoptCommand creates `adopt` command
+func NewAdoptCommand(c common2.Args, ioStreams cmdutil.IOStreams) *cobra.Command {
+	var selector, output string
+	opts := common.AdoptOptions{}
+	cmd := &cobra.Command{
+		Use:                   "adopt",
+		DisableFlagsInUseLine: true,
+		Short:                 "Adopt existing resources into an application",
+		Long: "Generate an application from the existing Deployments, StatefulSets, DaemonSets, Services, Ingresses and " +
+			"HorizontalPodAutoscalers selected by labels or installed by a Helm release. The components and traits are " +
+			"the definitions best matching the resources. The application takes over the existing resources without " +
+			"recreating them once it's applied.",
+		Example: "vela adopt -l app=myapp\nvela adopt --helm-release myrelease -o app.yaml",
+		Annotations: map[string]string{
+			types.TagCommandType: types.TypeApp,
+		},
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			return c.SetConfig()
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			velaEnv, err := GetEnv(cmd)
+			if err != nil {
+				return err
+			}
+			opts.Namespace = velaEnv.Namespace
+			if selector != "" {
+				if opts.Selector, err = labels.Parse(selector); err != nil {
+					return errors.Wrap(err, "invalid label selector")
+				}
+			}
+			newClient, err := c.GetClient()
+			if err != nil {
+				return err
+			}
+			app, warnings, err := common.AdoptResources(context.Background(), newClient, opts)
+			if err != nil {
+				return err
+			}
+			for _, w := range warnings {
+				ioStreams.Errorf("Warning: %s\n", w)
+			}
+			data, err := marshalApplication(app)
+			if err != nil {
+				return err
+			}
+			if output != "" {
+				if err := ioutil.WriteFile(output, data, 0600); err != nil {
+					return errors.Wrapf(err, "cannot write application to %s", output)
+				}
+				ioStreams.Infof("Application %s is written to %s, apply it to adopt the resources\n", app.Name, output)
+				return nil
+			}
+			_, err = ioStreams.Out.Write(data)
+			return err
+		},
+	}
+	cmd.SetOut(ioStreams.Out)
+	cmd.Flags().StringVarP(&selector, "selector", "l", "", "label selector of the resources to adopt")
+	cmd.Flags().StringVar(&opts.HelmRelease, "helm-release", "", "adopt the resources installed by the Helm release")
+	cmd.Flags().StringVar(&opts.AppName, "name", "", "name of the application, it's the Helm release or the first workload by default")
+	cmd.Flags().StringVarP(&output, "output", "o", "", "write the application to the file instead of stdout")
+	return cmd
+}
+
+// marshalApplication marshals an application into YAML without its status and empty metadata
+func marshalApplication(app *v1beta1.Application) ([]byte, error) {
+	obj, err := runtime.DefaultUnstructuredConverter.ToUnstructured(app)
+	if err != nil {
+		return nil, err
+	}
+	unstructured.RemoveNestedField(obj, "status")
+	unstructured.RemoveNestedField(obj, "metadata", "creationTimestamp")
+	return yaml.Marshal(obj)
+}
@@ -17,6 +17,7 @@ limitations under the License.
 package cli
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"strings"
@@ -47,6 +48,7 @@ func CapabilityCommandGroup(c common2.Args, ioStream cmdutil.IOStreams) *cobra.C
 		NewCenterCommand(ioStream),
 		NewCapListCommand(c, ioStream),
 		NewCapInstallCommand(c, ioStream),
+		NewCapUpgradeCommand(c, ioStream),
 		NewCapUninstallCommand(c, ioStream),
 	)
 	return cmd
@@ -71,10 +73,15 @@ func NewCenterCommand(ioStream cmdutil.IOStreams) *cobra.Command {
 // NewCapCenterConfigCommand Configure (add if not exist) a capability center, default is local (built-in capabilities)
 func NewCapCenterConfigCommand(ioStreams cmdutil.IOStreams) *cobra.Command {
 	cmd := &cobra.Command{
-		Use:     "config <centerName> <centerURL>",
-		Short:   "Configure (add if not exist) a capability center, default is local (built-in capabilities)",
-		Long:    "Configure (add if not exist) a capability center, default is local (built-in capabilities)",
-		Example: `vela cap center config mycenter https://github.com/oam-dev/catalog/tree/master/registry`,
+		Use:   "config <centerName> <centerURL>",
+		Short: "Configure (add if not exist) a capability center, default is local (built-in capabilities)",
+		Long: `Configure (add if not exist) a capability center, default is local (built-in capabilities).
+The center URL could be a directory of a Github repo, an HTTP URL of an index file, an OCI reference like oci://<registry>/<repository>[:<tag>],
+or a local directory which could be a plain directory of definitions, a directory with an index file, or an OCI image layout.`,
+		Example: `vela cap center config mycenter https://github.com/oam-dev/catalog/tree/master/registry
+vela cap center config mycenter https://gitea.example.com/platform/caps/raw/branch/master/index.yaml
+vela cap center config mycenter oci://registry.example.com/platform/caps:v1.0.0
+vela cap center config mycenter /mnt/mirror/caps`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			argsLength := len(args)
 			if argsLength < 2 {
@@ -97,10 +104,15 @@ func NewCapCenterConfigCommand(ioStreams cmdutil.IOStreams) *cobra.Command {
 // NewCapInstallCommand Install capability into cluster
 func NewCapInstallCommand(c common2.Args, ioStreams cmdutil.IOStreams) *cobra.Command {
 	cmd := &cobra.Command{
-		Use:     "install <center>/<name>",
-		Short:   "Install capability into cluster",
-		Long:    "Install capability into cluster",
-		Example: `vela cap install mycenter/route`,
+		Use:   "install <center>/<name>[@<version>]",
+		Short: "Install capability into cluster",
+		Long: `Install capability into cluster.
+The version could be an exact version or a constraint, the highest synced version is installed if it's omitted.
+Dependencies declared by the capability are installed first.
+An existing definition is only overwritten if it's installed from the same cap center, unless --force is set.`,
+		Example: `vela cap install mycenter/route
+vela cap install mycenter/route@1.2.0
+vela cap install "mycenter/route@>=1.0.0 <2.0.0"`,
 		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
 			return c.SetConfig()
 		},
@@ -108,7 +120,7 @@ func NewCapInstallCommand(c common2.Args, ioStreams cmdutil.IOStreams) *cobra.Co
 			var err error
 			argsLength := len(args)
 			if argsLength < 1 {
-				return errors.New("you must specify <center>/<name> for capability you want to install")
+				return errors.New("you must specify <center>/<name>[@<version>] for capability you want to install")
 			}
 			newClient, err := c.GetClient()
 			if err != nil {
@@ -118,13 +130,48 @@ func NewCapInstallCommand(c common2.Args, ioStreams cmdutil.IOStreams) *cobra.Co
 			if err != nil {
 				return err
 			}
-			if _, err = common.AddCapabilityIntoCluster(newClient, mapper, args[0]); err != nil {
+			force, err := cmd.Flags().GetBool("force")
+			if err != nil {
+				return err
+			}
+			if _, err = common.AddCapabilityIntoCluster(newClient, mapper, args[0], force); err != nil {
 				return err
 			}
 			return nil
 		},
 	}
 	AddTokenVarFlags(cmd)
+	cmd.Flags().Bool("force", false, "overwrite the definition even if it's not installed from the cap center")
+	return cmd
+}
+
+// NewCapUpgradeCommand Upgrade capabilities to the latest version synced from cap center
+func NewCapUpgradeCommand(c common2.Args, ioStreams cmdutil.IOStreams) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "upgrade [name]",
+		Short: "Upgrade capabilities to the latest version synced from cap center",
+		Long: `Upgrade capabilities to the latest version synced from cap center, default to upgrade all outdated capabilities.
+Run 'vela cap center sync' first to get the latest versions from remote.`,
+		Example: `vela cap upgrade route`,
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			return c.SetConfig()
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var name string
+			if len(args) > 0 {
+				name = args[0]
+			}
+			newClient, err := c.GetClient()
+			if err != nil {
+				return err
+			}
+			mapper, err := discoverymapper.New(c.Config)
+			if err != nil {
+				return err
+			}
+			return common.UpgradeCapability(newClient, mapper, name, ioStreams)
+		},
+	}
 	return cmd
 }
 
@@ -190,15 +237,23 @@ func NewCapCenterSyncCommand(ioStreams cmdutil.IOStreams) *cobra.Command {
 // NewCapListCommand List capabilities from cap-center
 func NewCapListCommand(c common2.Args, ioStreams cmdutil.IOStreams) *cobra.Command {
 	cmd := &cobra.Command{
-		Use:     "ls [cap-center]",
-		Short:   "List capabilities from cap-center",
-		Long:    "List capabilities from cap-center",
-		Example: `vela cap ls`,
+		Use:   "ls [cap-center]",
+		Short: "List capabilities from cap-center",
+		Long:  "List capabilities from cap-center",
+		Example: `vela cap ls
+vela cap ls --outdated`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			var repoName string
 			if len(args) > 0 {
 				repoName = args[0]
 			}
+			outdated, err := cmd.Flags().GetBool("outdated")
+			if err != nil {
+				return err
+			}
+			if outdated {
+				return listOutdatedCapabilities(c, repoName, ioStreams)
+			}
 			env, err := GetEnv(cmd)
 			if err != nil {
 				return err
@@ -208,18 +263,40 @@ func NewCapListCommand(c common2.Args, ioStreams cmdutil.IOStreams) *cobra.Comma
 				return err
 			}
 			table := newUITable()
-			table.AddRow("NAME", "CENTER", "TYPE", "DEFINITION", "STATUS", "APPLIES-TO")
+			table.AddRow("NAME", "CENTER", "VERSION", "TYPE", "DEFINITION", "STATUS", "APPLIES-TO")
 
 			for _, c := range capabilityList {
-				table.AddRow(c.Name, c.Center, c.Type, c.CrdName, c.Status, c.AppliesTo)
+				table.AddRow(c.Name, c.Center, c.Version, c.Type, c.CrdName, c.Status, c.AppliesTo)
 			}
 			ioStreams.Info(table.String())
 			return nil
 		},
 	}
+	cmd.Flags().Bool("outdated", false, "only list installed capabilities which have newer versions in cap center")
 	return cmd
 }
 
+func listOutdatedCapabilities(c common2.Args, centerName string, ioStreams cmdutil.IOStreams) error {
+	newClient, err := c.GetClient()
+	if err != nil {
+		return err
+	}
+	outdated, err := common.ListOutdatedCapabilities(context.Background(), newClient)
+	if err != nil {
+		return err
+	}
+	table := newUITable()
+	table.AddRow("NAME", "CENTER", "TYPE", "INSTALLED", "LATEST")
+	for _, p := range outdated {
+		if centerName != "" && p.Center != centerName {
+			continue
+		}
+		table.AddRow(p.Name, p.Center, p.Type, p.Version, p.Latest)
+	}
+	ioStreams.Info(table.String())
+	return nil
+}
+
 // NewCapCenterListCommand List all capability centers
 func NewCapCenterListCommand(ioStreams cmdutil.IOStreams) *cobra.Command {
 	cmd := &cobra.Command{
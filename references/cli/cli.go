@@ -84,10 +84,15 @@ func NewCommand() *cobra.Command {
 		NewPortForwardCommand(commandArgs, ioStream),
 		NewLogsCommand(commandArgs, ioStream),
 		NewEnvCommand(commandArgs, ioStream),
-		NewConfigCommand(ioStream),
+		NewConfigCommand(commandArgs, ioStream),
+		NewRevisionCommand(commandArgs, ioStream),
+		NewRolloutCommand(commandArgs, ioStream),
+		NewAdoptCommand(commandArgs, ioStream),
+		NewMigrateCommand(commandArgs, ioStream),
 
 		// Capabilities
 		CapabilityCommandGroup(commandArgs, ioStream),
+		NewDefinitionCommandGroup(commandArgs, ioStream),
 		NewTemplateCommand(ioStream),
 		NewTraitsCommand(commandArgs, ioStream),
 		NewComponentsCommand(commandArgs, ioStream),
@@ -17,26 +17,32 @@ limitations under the License.
 package cli
 
 import (
-	"bufio"
-	"bytes"
-	b64 "encoding/base64"
 	"fmt"
-	"io/ioutil"
 	"strings"
 
 	"github.com/spf13/cobra"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
 
 	"github.com/oam-dev/kubevela/apis/types"
-	"github.com/oam-dev/kubevela/pkg/utils/config"
+	"github.com/oam-dev/kubevela/pkg/appfile/config"
+	"github.com/oam-dev/kubevela/pkg/utils/common"
 	cmdutil "github.com/oam-dev/kubevela/pkg/utils/util"
 )
 
 // Notes about config dir layout:
 // Under each env dir, there are individual files for each config.
 // The format is the same as k8s Secret.Data field with value base64 encoded.
+// Configs could also be stored in ConfigMaps or Secrets of the env namespace by `--store`.
+
+const (
+	configStoreFlag       = "store"
+	encryptionKeyFileFlag = "encryption-key-file"
+	// allowPlaintextConfigFlag allows the encrypted configs to be exported into plaintext Secrets for applications
+	allowPlaintextConfigFlag = "allow-plaintext-config"
+)
 
 // NewConfigCommand will create command for config management for AppFile
-func NewConfigCommand(io cmdutil.IOStreams) *cobra.Command {
+func NewConfigCommand(c common.Args, io cmdutil.IOStreams) *cobra.Command {
 	cmd := &cobra.Command{
 		Use:                   "config",
 		DisableFlagsInUseLine: true,
@@ -47,17 +53,64 @@ func NewConfigCommand(io cmdutil.IOStreams) *cobra.Command {
 		},
 	}
 	cmd.SetOut(io.Out)
+	cmd.PersistentFlags().String(configStoreFlag, config.TypeLocal, "the store of configs, one of local, configmap and secret")
+	cmd.PersistentFlags().String(encryptionKeyFileFlag, "", "the file of AES key to encrypt or decrypt the configs in secret store")
 	cmd.AddCommand(
-		NewConfigListCommand(io),
-		NewConfigGetCommand(io),
-		NewConfigSetCommand(io),
-		NewConfigDeleteCommand(io),
+		NewConfigListCommand(c, io),
+		NewConfigGetCommand(c, io),
+		NewConfigSetCommand(c, io),
+		NewConfigDeleteCommand(c, io),
+		NewConfigMigrateCommand(c, io),
 	)
 	return cmd
 }
 
+// newConfigStore creates the config store of the given type
+func newConfigStore(c common.Args, storeType, keyFile string) (config.Manager, error) {
+	switch storeType {
+	case "", config.TypeLocal:
+		return &config.Local{}, nil
+	case config.TypeConfigMap:
+		kubecli, err := c.GetClient()
+		if err != nil {
+			return nil, err
+		}
+		return &config.Configmap{Client: kubecli}, nil
+	case config.TypeSecret:
+		kubecli, err := c.GetClient()
+		if err != nil {
+			return nil, err
+		}
+		store := &config.Secret{Client: kubecli}
+		if keyFile != "" {
+			if store.Encryptor, err = config.LoadEncryptor(keyFile); err != nil {
+				return nil, err
+			}
+		}
+		return store, nil
+	default:
+		return nil, fmt.Errorf("unknown config store %s, must be one of local, configmap and secret", storeType)
+	}
+}
+
+// getConfigStore gets the config store from the flags of command, the local store is used if the command is nil
+func getConfigStore(cmd *cobra.Command, c common.Args) (config.Manager, error) {
+	if cmd == nil {
+		return &config.Local{}, nil
+	}
+	storeType, err := cmd.Flags().GetString(configStoreFlag)
+	if err != nil {
+		return nil, err
+	}
+	keyFile, err := cmd.Flags().GetString(encryptionKeyFileFlag)
+	if err != nil {
+		return nil, err
+	}
+	return newConfigStore(c, storeType, keyFile)
+}
+
 // NewConfigListCommand list all created configs
-func NewConfigListCommand(io cmdutil.IOStreams) *cobra.Command {
+func NewConfigListCommand(c common.Args, io cmdutil.IOStreams) *cobra.Command {
 	cmd := &cobra.Command{
 		Use:                   "ls",
 		Aliases:               []string{"list"},
@@ -66,7 +119,7 @@ func NewConfigListCommand(io cmdutil.IOStreams) *cobra.Command {
 		Long:                  "List all configs",
 		Example:               `vela config ls`,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return ListConfigs(io, cmd)
+			return ListConfigs(c, io, cmd)
 		},
 		Annotations: map[string]string{
 			types.TagCommandType: types.TypeStart,
@@ -76,27 +129,22 @@ func NewConfigListCommand(io cmdutil.IOStreams) *cobra.Command {
 	return cmd
 }
 
-func getConfigDir(cmd *cobra.Command) (string, error) {
+// ListConfigs will list all configs
+func ListConfigs(c common.Args, ioStreams cmdutil.IOStreams, cmd *cobra.Command) error {
 	e, err := GetEnv(cmd)
 	if err != nil {
-		return "", err
+		return err
 	}
-	return config.GetConfigsDir(e.Name)
-}
-
-// ListConfigs will list all configs
-func ListConfigs(ioStreams cmdutil.IOStreams, cmd *cobra.Command) error {
-	d, err := getConfigDir(cmd)
+	store, err := getConfigStore(cmd, c)
 	if err != nil {
 		return err
 	}
-	table := newUITable()
-	table.AddRow("NAME")
-	cfgList, err := listConfigs(d)
+	cfgList, err := store.ListConfigs(e.Name)
 	if err != nil {
 		return err
 	}
-
+	table := newUITable()
+	table.AddRow("NAME")
 	for _, name := range cfgList {
 		table.AddRow(name)
 	}
@@ -104,21 +152,8 @@ func ListConfigs(ioStreams cmdutil.IOStreams, cmd *cobra.Command) error {
 	return nil
 }
 
-func listConfigs(dir string) ([]string, error) {
-	files, err := ioutil.ReadDir(dir)
-	if err != nil {
-		return nil, err
-	}
-
-	l := []string{}
-	for _, f := range files {
-		l = append(l, f.Name())
-	}
-	return l, nil
-}
-
 // NewConfigGetCommand get config from local
-func NewConfigGetCommand(io cmdutil.IOStreams) *cobra.Command {
+func NewConfigGetCommand(c common.Args, io cmdutil.IOStreams) *cobra.Command {
 	cmd := &cobra.Command{
 		Use:                   "get",
 		Aliases:               []string{"get"},
@@ -127,7 +162,7 @@ func NewConfigGetCommand(io cmdutil.IOStreams) *cobra.Command {
 		Long:                  "Get data for a config",
 		Example:               `vela config get <config-name>`,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return getConfig(args, io, cmd)
+			return getConfig(c, args, io, cmd)
 		},
 		Annotations: map[string]string{
 			types.TagCommandType: types.TypeStart,
@@ -137,7 +172,7 @@ func NewConfigGetCommand(io cmdutil.IOStreams) *cobra.Command {
 	return cmd
 }
 
-func getConfig(args []string, io cmdutil.IOStreams, cmd *cobra.Command) error {
+func getConfig(c common.Args, args []string, io cmdutil.IOStreams, cmd *cobra.Command) error {
 	e, err := GetEnv(cmd)
 	if err != nil {
 		return err
@@ -146,24 +181,23 @@ func getConfig(args []string, io cmdutil.IOStreams, cmd *cobra.Command) error {
 		return fmt.Errorf("must specify config name, vela config get <name>")
 	}
 	configName := args[0]
-	cfgData, err := config.ReadConfig(e.Name, configName)
+	store, err := getConfigStore(cmd, c)
+	if err != nil {
+		return err
+	}
+	cfgData, err := store.GetConfigData(configName, e.Name)
 	if err != nil {
 		return err
 	}
 	io.Infof("Data:\n")
-	scanner := bufio.NewScanner(bytes.NewReader(cfgData))
-	for scanner.Scan() {
-		k, v, err := config.ReadConfigLine(scanner.Text())
-		if err != nil {
-			return err
-		}
-		io.Infof("  %s: %s\n", k, v)
+	for _, d := range cfgData {
+		io.Infof("  %s: %s\n", d["name"], d["value"])
 	}
 	return nil
 }
 
 // NewConfigSetCommand set a config data in local
-func NewConfigSetCommand(io cmdutil.IOStreams) *cobra.Command {
+func NewConfigSetCommand(c common.Args, io cmdutil.IOStreams) *cobra.Command {
 	cmd := &cobra.Command{
 		Use:                   "set",
 		Aliases:               []string{"set"},
@@ -172,7 +206,7 @@ func NewConfigSetCommand(io cmdutil.IOStreams) *cobra.Command {
 		Long:                  "Set data for a config",
 		Example:               `vela config set <config-name> KEY=VALUE K2=V2`,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return setConfig(args, io, cmd)
+			return setConfig(c, args, io, cmd)
 		},
 		Annotations: map[string]string{
 			types.TagCommandType: types.TypeStart,
@@ -182,7 +216,7 @@ func NewConfigSetCommand(io cmdutil.IOStreams) *cobra.Command {
 	return cmd
 }
 
-func setConfig(args []string, io cmdutil.IOStreams, cmd *cobra.Command) error {
+func setConfig(c common.Args, args []string, io cmdutil.IOStreams, cmd *cobra.Command) error {
 	e, err := GetEnv(cmd)
 	if err != nil {
 		return err
@@ -208,28 +242,27 @@ func setConfig(args []string, io cmdutil.IOStreams, cmd *cobra.Command) error {
 		input[k] = v
 	}
 
-	cfgData, err := config.ReadConfig(envName, configName)
+	store, err := getConfigStore(cmd, c)
+	if err != nil {
+		return err
+	}
+	cfgData, err := store.GetConfigData(configName, envName)
+	if err != nil && !kerrors.IsNotFound(err) {
+		return err
+	}
+	existing, err := config.DecodeConfigFormat(cfgData)
 	if err != nil {
 		return err
 	}
 
 	io.Infof("reading existing config data and merging with user input\n")
-	scanner := bufio.NewScanner(bytes.NewReader(cfgData))
-	for scanner.Scan() {
-		k, v, err := config.ReadConfigLine(scanner.Text())
-		if err != nil {
-			return err
+	for k, v := range existing {
+		if _, ok := input[k]; !ok {
+			input[k] = v
 		}
-		input[k] = v
 	}
 
-	var out bytes.Buffer
-	for k, v := range input {
-		vEnc := b64.StdEncoding.EncodeToString([]byte(v))
-		out.WriteString(fmt.Sprintf("%s: %s\n", k, vEnc))
-	}
-	err = config.WriteConfig(envName, configName, out.Bytes())
-	if err != nil {
+	if err = store.SetConfigData(configName, envName, input); err != nil {
 		return err
 	}
 	io.Infof("config data saved successfully %s\n", emojiSucceed)
@@ -237,7 +270,7 @@ func setConfig(args []string, io cmdutil.IOStreams, cmd *cobra.Command) error {
 }
 
 // NewConfigDeleteCommand delete a config from local
-func NewConfigDeleteCommand(io cmdutil.IOStreams) *cobra.Command {
+func NewConfigDeleteCommand(c common.Args, io cmdutil.IOStreams) *cobra.Command {
 	cmd := &cobra.Command{
 		Use:                   "del",
 		Aliases:               []string{"del"},
@@ -246,7 +279,7 @@ func NewConfigDeleteCommand(io cmdutil.IOStreams) *cobra.Command {
 		Long:                  "Delete config",
 		Example:               `vela config del <config-name>`,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return deleteConfig(args, io, cmd)
+			return deleteConfig(c, args, io, cmd)
 		},
 		Annotations: map[string]string{
 			types.TagCommandType: types.TypeStart,
@@ -256,7 +289,7 @@ func NewConfigDeleteCommand(io cmdutil.IOStreams) *cobra.Command {
 	return cmd
 }
 
-func deleteConfig(args []string, io cmdutil.IOStreams, cmd *cobra.Command) error {
+func deleteConfig(c common.Args, args []string, io cmdutil.IOStreams, cmd *cobra.Command) error {
 	e, err := GetEnv(cmd)
 	if err != nil {
 		return err
@@ -265,10 +298,78 @@ func deleteConfig(args []string, io cmdutil.IOStreams, cmd *cobra.Command) error
 		return fmt.Errorf("must specify config name, vela config get <name>")
 	}
 	configName := args[0]
-	err = config.DeleteConfig(e.Name, configName)
+	store, err := getConfigStore(cmd, c)
 	if err != nil {
 		return err
 	}
+	if err = store.DeleteConfig(configName, e.Name); err != nil {
+		return err
+	}
 	io.Infof("config (%s) deleted successfully\n", configName)
 	return nil
 }
+
+// NewConfigMigrateCommand migrates configs from one store to another
+func NewConfigMigrateCommand(c common.Args, io cmdutil.IOStreams) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:                   "migrate",
+		DisableFlagsInUseLine: true,
+		Short:                 "Migrate configs between stores",
+		Long:                  "Migrate all configs of the env from one store to another",
+		Example:               `vela config migrate --from configmap --to secret --encryption-key-file key.txt`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			e, err := GetEnv(cmd)
+			if err != nil {
+				return err
+			}
+			from, err := cmd.Flags().GetString("from")
+			if err != nil {
+				return err
+			}
+			to, err := cmd.Flags().GetString("to")
+			if err != nil {
+				return err
+			}
+			deleteSource, err := cmd.Flags().GetBool("delete-source")
+			if err != nil {
+				return err
+			}
+			keyFile, err := cmd.Flags().GetString(encryptionKeyFileFlag)
+			if err != nil {
+				return err
+			}
+			return migrateConfigs(c, io, e.Name, from, to, keyFile, deleteSource)
+		},
+		Annotations: map[string]string{
+			types.TagCommandType: types.TypeStart,
+		},
+	}
+	cmd.SetOut(io.Out)
+	cmd.Flags().String("from", config.TypeConfigMap, "the store that configs are migrated from")
+	cmd.Flags().String("to", config.TypeSecret, "the store that configs are migrated to")
+	cmd.Flags().Bool("delete-source", false, "delete the configs from the source store after migration")
+	return cmd
+}
+
+func migrateConfigs(c common.Args, io cmdutil.IOStreams, envName, from, to, keyFile string, deleteSource bool) error {
+	if from == to {
+		return fmt.Errorf("the source and target store are both %s", from)
+	}
+	src, err := newConfigStore(c, from, keyFile)
+	if err != nil {
+		return err
+	}
+	dst, err := newConfigStore(c, to, keyFile)
+	if err != nil {
+		return err
+	}
+	names, err := config.Migrate(src, dst, envName, deleteSource)
+	if err != nil {
+		return err
+	}
+	for _, name := range names {
+		io.Infof("config (%s) migrated from %s to %s\n", name, from, to)
+	}
+	io.Infof("%d configs migrated successfully %s\n", len(names), emojiSucceed)
+	return nil
+}
@@ -24,6 +24,7 @@ import (
 
 	"github.com/stretchr/testify/assert"
 
+	"github.com/oam-dev/kubevela/pkg/utils/common"
 	"github.com/oam-dev/kubevela/pkg/utils/system"
 	cmdutil "github.com/oam-dev/kubevela/pkg/utils/util"
 )
@@ -42,7 +43,7 @@ func TestConfigCommand(t *testing.T) {
 
 	// vela config set test a=b
 	io := cmdutil.IOStreams{In: os.Stdin, Out: os.Stdout, ErrOut: os.Stderr}
-	err = setConfig([]string{"test", "a=b"}, io, nil)
+	err = setConfig(common.Args{}, []string{"test", "a=b"}, io, nil)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -50,7 +51,7 @@ func TestConfigCommand(t *testing.T) {
 	// vela config get test
 	var b bytes.Buffer
 	io.Out = &b
-	err = getConfig([]string{"test"}, io, nil)
+	err = getConfig(common.Args{}, []string{"test"}, io, nil)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -58,7 +59,7 @@ func TestConfigCommand(t *testing.T) {
 
 	// vela config set test2 c=d
 	io.Out = os.Stdout
-	err = setConfig([]string{"test2", "c=d"}, io, nil)
+	err = setConfig(common.Args{}, []string{"test2", "c=d"}, io, nil)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -66,7 +67,7 @@ func TestConfigCommand(t *testing.T) {
 	// vela config ls
 	b = bytes.Buffer{}
 	io.Out = &b
-	err = ListConfigs(io, nil)
+	err = ListConfigs(common.Args{}, io, nil)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -74,7 +75,7 @@ func TestConfigCommand(t *testing.T) {
 
 	// vela config del test
 	io.Out = os.Stdout
-	err = deleteConfig([]string{"test"}, io, nil)
+	err = deleteConfig(common.Args{}, []string{"test"}, io, nil)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -82,7 +83,7 @@ func TestConfigCommand(t *testing.T) {
 	// vela config ls
 	b = bytes.Buffer{}
 	io.Out = &b
-	err = ListConfigs(io, nil)
+	err = ListConfigs(common.Args{}, io, nil)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -44,6 +44,7 @@ import (
 	"github.com/oam-dev/kubevela/pkg/utils/system"
 	cmdutil "github.com/oam-dev/kubevela/pkg/utils/util"
 	"github.com/oam-dev/kubevela/references/apiserver"
+	"github.com/oam-dev/kubevela/references/apiserver/auth"
 	"github.com/oam-dev/kubevela/references/apiserver/util"
 )
 
@@ -187,7 +188,7 @@ func SetupAPIServer(c common.Args, cmd *cobra.Command, o Options) error {
 	}
 
 	// Setup RESTful server
-	server, err := apiserver.New(c, o.port, o.staticPath)
+	server, err := apiserver.New(c, o.port, o.staticPath, auth.Options{})
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,217 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
+	"github.com/oam-dev/kubevela/apis/types"
+	"github.com/oam-dev/kubevela/pkg/appfile"
+	"github.com/oam-dev/kubevela/pkg/dsl/definition"
+	"github.com/oam-dev/kubevela/pkg/oam"
+	common2 "github.com/oam-dev/kubevela/pkg/utils/common"
+	cmdutil "github.com/oam-dev/kubevela/pkg/utils/util"
+)
+
+// NewDefinitionCommandGroup creates `def` command and its nested children
+func NewDefinitionCommandGroup(c common2.Args, ioStreams cmdutil.IOStreams) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:                   "def",
+		DisableFlagsInUseLine: true,
+		Short:                 "Manage definitions",
+		Long:                  "Check ComponentDefinitions and TraitDefinitions before applying them",
+		Annotations: map[string]string{
+			types.TagCommandType: types.TypeCap,
+		},
+	}
+	cmd.SetOut(ioStreams.Out)
+	cmd.AddCommand(NewDefinitionLintCommand(c, ioStreams))
+	return cmd
+}
+
+// NewDefinitionLintCommand creates `def lint` command
+func NewDefinitionLintCommand(c common2.Args, ioStreams cmdutil.IOStreams) *cobra.Command {
+	var offline bool
+	var openAPISchema string
+	cmd := &cobra.Command{
+		Use:                   "lint <file or directory>...",
+		DisableFlagsInUseLine: true,
+		Short:                 "Lint the CUE templates of definitions",
+		Long: "Lint the CUE templates of ComponentDefinitions and TraitDefinitions in files or directories. " +
+			"It reports parameters never used or without a usage comment, outputs with generated names, " +
+			"and fields of outputs and patches which don't match the K8s schemas. " +
+			"The schemas and the component definitions which traits apply to are loaded from the cluster unless in offline mode.",
+		Example: "vela def lint ./definitions\nvela def lint --offline my-trait.yaml",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) == 0 {
+				return fmt.Errorf("must specify files or directories of definitions")
+			}
+			var objs []oam.Object
+			for _, path := range args {
+				o, err := ReadObjectsFromFile(path)
+				if err != nil {
+					return errors.WithMessagef(err, "read definitions from %s", path)
+				}
+				objs = append(objs, o...)
+			}
+			cds, tds, err := decodeDefinitions(objs)
+			if err != nil {
+				return err
+			}
+			if len(cds)+len(tds) == 0 {
+				return fmt.Errorf("no ComponentDefinition or TraitDefinition is found")
+			}
+
+			var pd *definition.PackageDiscover
+			clusterCDs := cds
+			if offline {
+				if pd, err = offlinePackageDiscover(openAPISchema); err != nil {
+					return err
+				}
+			} else {
+				if pd, err = c.GetPackageDiscover(); err != nil {
+					return err
+				}
+				newClient, err := c.GetClient()
+				if err != nil {
+					return err
+				}
+				installed := &v1beta1.ComponentDefinitionList{}
+				if err := newClient.List(context.Background(), installed, client.InNamespace(oam.SystemDefinitonNamespace)); err != nil {
+					return errors.WithMessage(err, "list component definitions")
+				}
+				// the local definitions take precedence over the installed ones
+				clusterCDs = append(installed.Items, cds...)
+			}
+
+			results := lintDefinitions(cds, tds, pd, definition.ComponentWorkloadKinds(clusterCDs))
+			problems, failed := 0, 0
+			for _, r := range results {
+				if len(r.issues) == 0 {
+					continue
+				}
+				ioStreams.Infof("%s/%s\n", r.kind, r.name)
+				for _, issue := range r.issues {
+					ioStreams.Infof("  %s\n", issue)
+				}
+				problems += len(r.issues)
+				failed++
+			}
+			if problems > 0 {
+				return fmt.Errorf("found %d problems in %d of %d definitions", problems, failed, len(results))
+			}
+			ioStreams.Infof("no problems found in %d definitions\n", len(results))
+			return nil
+		},
+	}
+	cmd.Flags().BoolVar(&offline, "offline", false, "lint without a K8s cluster, traits can only apply to the component definitions specified together")
+	cmd.Flags().StringVar(&openAPISchema, "openapi-schema", "", "specify a K8s OpenAPI v2 schema file used in offline mode, e.g. got by 'kubectl get --raw /openapi/v2', the schema of built-in resources is used by default")
+	cmd.SetOut(ioStreams.Out)
+	return cmd
+}
+
+// definitionLintResult is the lint result of a definition
+type definitionLintResult struct {
+	kind   string
+	name   string
+	issues []string
+}
+
+// lintDefinitions lints the CUE templates of the definitions, the patch of a trait is checked against the workloads
+// of the components it applies to, which are resolved by componentKinds
+func lintDefinitions(cds []v1beta1.ComponentDefinition, tds []v1beta1.TraitDefinition, pd *definition.PackageDiscover,
+	componentKinds map[string]metav1.GroupVersionKind) []definitionLintResult {
+	var results []definitionLintResult
+	lint := func(kind, name string, capability types.Capability, err error, targets []metav1.GroupVersionKind) {
+		r := definitionLintResult{kind: kind, name: name}
+		if err == nil && capability.CueTemplate == "" {
+			// only CUE templates are linted
+			return
+		}
+		var issues []definition.LintIssue
+		if err == nil {
+			issues, err = definition.LintTemplate(capability.CueTemplate, pd, targets)
+		}
+		if err != nil {
+			r.issues = append(r.issues, err.Error())
+		}
+		for _, issue := range issues {
+			r.issues = append(r.issues, issue.String())
+		}
+		results = append(results, r)
+	}
+	for _, cd := range cds {
+		capability, err := appfile.ConvertTemplateJSON2Object(cd.Name, cd.Spec.Extension, cd.Spec.Schematic)
+		lint("componentdefinition", cd.Name, capability, err, nil)
+	}
+	for _, td := range tds {
+		capability, err := appfile.ConvertTemplateJSON2Object(td.Name, td.Spec.Extension, td.Spec.Schematic)
+		lint("traitdefinition", td.Name, capability, err, pd.WorkloadKinds(td.Spec.AppliesToWorkloads, componentKinds))
+	}
+	return results
+}
+
+// decodeDefinitions picks ComponentDefinitions and TraitDefinitions from the objects
+func decodeDefinitions(objs []oam.Object) ([]v1beta1.ComponentDefinition, []v1beta1.TraitDefinition, error) {
+	var cds []v1beta1.ComponentDefinition
+	var tds []v1beta1.TraitDefinition
+	for _, obj := range objs {
+		u, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+		if err != nil {
+			return nil, nil, err
+		}
+		switch obj.GetObjectKind().GroupVersionKind().Kind {
+		case v1beta1.ComponentDefinitionKind:
+			cd := v1beta1.ComponentDefinition{}
+			if err := runtime.DefaultUnstructuredConverter.FromUnstructured(u, &cd); err != nil {
+				return nil, nil, errors.Wrapf(err, "invalid ComponentDefinition %s", obj.GetName())
+			}
+			cds = append(cds, cd)
+		case v1beta1.TraitDefinitionKind:
+			td := v1beta1.TraitDefinition{}
+			if err := runtime.DefaultUnstructuredConverter.FromUnstructured(u, &td); err != nil {
+				return nil, nil, errors.Wrapf(err, "invalid TraitDefinition %s", obj.GetName())
+			}
+			tds = append(tds, td)
+		}
+	}
+	return cds, tds, nil
+}
+
+// offlinePackageDiscover loads the K8s schemas from a file, the bundled schemas are used if the file is not specified
+func offlinePackageDiscover(openAPISchemaFile string) (*definition.PackageDiscover, error) {
+	schema, err := definition.BundledOpenAPISchema()
+	if openAPISchemaFile != "" {
+		var b []byte
+		b, err = ioutil.ReadFile(filepath.Clean(openAPISchemaFile))
+		schema = string(b)
+	}
+	if err != nil {
+		return nil, errors.WithMessage(err, "load OpenAPI schema")
+	}
+	return definition.NewPackageDiscoverFromOpenAPI(schema)
+}
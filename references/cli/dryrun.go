@@ -45,6 +45,14 @@ type DryRunCmdOptions struct {
 	cmdutil.IOStreams
 	ApplicationFile string
 	DefinitionFile  string
+	// Offline renders the application without a K8s cluster, definitions are only read from DefinitionFile
+	Offline bool
+	// OpenAPISchema is a file of K8s OpenAPI v2 schema used in offline mode, the bundled one is used if it's empty
+	OpenAPISchema string
+	// Estimate outputs the estimated resources of the application instead of the K8s resources
+	Estimate bool
+	// PriceTable is a file of resource prices used to estimate the cost
+	PriceTable string
 }
 
 // NewDryRunCommand creates `dry-run` command
@@ -55,8 +63,11 @@ func NewDryRunCommand(c common.Args, ioStreams cmdutil.IOStreams) *cobra.Command
 		DisableFlagsInUseLine: true,
 		Short:                 "Dry Run an application, and output the K8s resources as result to stdout",
 		Long:                  "Dry Run an application, and output the K8s resources as result to stdout, only CUE template supported for now",
-		Example:               "vela dry-run",
+		Example:               "vela system dry-run\nvela system dry-run --offline -d ./definitions\nvela system dry-run --estimate --price-table prices.yaml",
 		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			if o.Offline {
+				return nil
+			}
 			return c.SetConfig()
 		},
 		RunE: func(cmd *cobra.Command, args []string) error {
@@ -75,6 +86,8 @@ func NewDryRunCommand(c common.Args, ioStreams cmdutil.IOStreams) *cobra.Command
 
 	cmd.Flags().StringVarP(&o.ApplicationFile, "file", "f", "./app.yaml", "application file name")
 	cmd.Flags().StringVarP(&o.DefinitionFile, "definition", "d", "", "specify a definition file or directory, it will only be used in dry-run rather than applied to K8s cluster")
+	addOfflineFlags(cmd, &o.Offline, &o.OpenAPISchema)
+	addEstimateFlags(cmd, &o.Estimate, &o.PriceTable)
 	cmd.SetOut(ioStreams.Out)
 	return cmd
 }
@@ -83,38 +96,37 @@ func NewDryRunCommand(c common.Args, ioStreams cmdutil.IOStreams) *cobra.Command
 func DryRunApplication(cmdOption *DryRunCmdOptions, c common.Args, namespace string) (bytes.Buffer, error) {
 	var buff = bytes.Buffer{}
 
-	newClient, err := c.GetClient()
+	app, err := readApplicationFromFile(cmdOption.ApplicationFile)
 	if err != nil {
-		return buff, err
+		return buff, errors.WithMessagef(err, "read application file: %s", cmdOption.ApplicationFile)
 	}
 	objs := []oam.Object{}
 	if cmdOption.DefinitionFile != "" {
-		objs, err = ReadObjectsFromFile(cmdOption.DefinitionFile)
-		if err != nil {
+		if objs, err = ReadObjectsFromFile(cmdOption.DefinitionFile); err != nil {
 			return buff, err
 		}
 	}
-	pd, err := c.GetPackageDiscover()
-	if err != nil {
-		return buff, err
-	}
-
-	dm, err := discoverymapper.New(c.Config)
+	dryRunOpt, err := newDryRunOption(c, objs, cmdOption.Offline, cmdOption.OpenAPISchema)
 	if err != nil {
 		return buff, err
 	}
-
-	app, err := readApplicationFromFile(cmdOption.ApplicationFile)
-	if err != nil {
-		return buff, errors.WithMessagef(err, "read application file: %s", cmdOption.ApplicationFile)
-	}
-
-	dryRunOpt := dryrun.NewDryRunOption(newClient, dm, pd, objs)
 	ctx := oamutil.SetNamespaceInCtx(context.Background(), namespace)
 	ac, comps, err := dryRunOpt.ExecuteDryRun(ctx, app)
 	if err != nil {
 		return buff, errors.WithMessage(err, "generate OAM objects")
 	}
+	if cmdOption.Estimate {
+		prices, err := loadPriceTable(cmdOption.PriceTable)
+		if err != nil {
+			return buff, err
+		}
+		estimate, err := dryrun.EstimateApplication(ac, comps, prices)
+		if err != nil {
+			return buff, errors.WithMessage(err, "estimate resources")
+		}
+		buff.WriteString(formatEstimate(estimate, nil, prices))
+		return buff, nil
+	}
 
 	var components = make(map[string]runtime.RawExtension)
 	for _, comp := range comps {
@@ -141,6 +153,51 @@ func DryRunApplication(cmdOption *DryRunCmdOptions, c common.Args, namespace str
 	return buff, nil
 }
 
+// newDryRunOption creates the option to dry-run applications with the given definitions, no cluster is accessed in offline mode
+func newDryRunOption(c common.Args, objs []oam.Object, offline bool, openAPISchemaFile string) (*dryrun.Option, error) {
+	if offline {
+		var schema []byte
+		if openAPISchemaFile != "" {
+			var err error
+			if schema, err = ioutil.ReadFile(filepath.Clean(openAPISchemaFile)); err != nil {
+				return nil, errors.WithMessagef(err, "read OpenAPI schema file: %s", openAPISchemaFile)
+			}
+		}
+		return dryrun.NewOfflineDryRunOption(objs, string(schema))
+	}
+	newClient, err := c.GetClient()
+	if err != nil {
+		return nil, err
+	}
+	pd, err := c.GetPackageDiscover()
+	if err != nil {
+		return nil, err
+	}
+	dm, err := discoverymapper.New(c.Config)
+	if err != nil {
+		return nil, err
+	}
+	return dryrun.NewDryRunOption(newClient, dm, pd, objs), nil
+}
+
+func addEstimateFlags(cmd *cobra.Command, estimate *bool, priceTable *string) {
+	cmd.Flags().BoolVar(estimate, "estimate", false, "output the estimated CPU, memory, storage and replicas of each component")
+	cmd.Flags().StringVar(priceTable, "price-table", "", "specify a file of resource prices to estimate the cost, e.g. with fields currency, period, cpu (per core), memory and storage (per GiB)")
+}
+
+// loadPriceTable loads the price table if the file is specified
+func loadPriceTable(path string) (*dryrun.PriceTable, error) {
+	if path == "" {
+		return nil, nil
+	}
+	return dryrun.LoadPriceTable(path)
+}
+
+func addOfflineFlags(cmd *cobra.Command, offline *bool, openAPISchema *string) {
+	cmd.Flags().BoolVar(offline, "offline", false, "render without a K8s cluster, all definitions must be specified by --definition")
+	cmd.Flags().StringVar(openAPISchema, "openapi-schema", "", "specify a K8s OpenAPI v2 schema file used in offline mode, e.g. got by 'kubectl get --raw /openapi/v2', the schema of built-in resources is used by default")
+}
+
 // ReadObjectsFromFile will read objects from file or dir in the format of yaml
 func ReadObjectsFromFile(path string) ([]oam.Object, error) {
 	fi, err := os.Stat(path)
@@ -20,6 +20,7 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"strings"
 
 	"github.com/spf13/cobra"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -43,12 +44,13 @@ func NewEnvCommand(c common.Args, ioStream cmdutil.IOStreams) *cobra.Command {
 		},
 	}
 	cmd.SetOut(ioStream.Out)
-	cmd.AddCommand(NewEnvListCommand(ioStream), NewEnvInitCommand(c, ioStream), NewEnvSetCommand(ioStream), NewEnvDeleteCommand(ioStream))
+	cmd.AddCommand(NewEnvListCommand(c, ioStream), NewEnvInitCommand(c, ioStream), NewEnvSetCommand(c, ioStream), NewEnvDeleteCommand(c, ioStream))
 	return cmd
 }
 
 // NewEnvListCommand creates `env list` command for listing all environments
-func NewEnvListCommand(ioStream cmdutil.IOStreams) *cobra.Command {
+func NewEnvListCommand(c common.Args, ioStream cmdutil.IOStreams) *cobra.Command {
+	ctx := context.Background()
 	cmd := &cobra.Command{
 		Use:                   "ls",
 		Aliases:               []string{"list"},
@@ -56,8 +58,15 @@ func NewEnvListCommand(ioStream cmdutil.IOStreams) *cobra.Command {
 		Short:                 "List environments",
 		Long:                  "List all environments",
 		Example:               `vela env ls [env-name]`,
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			return c.SetConfig()
+		},
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return ListEnvs(args, ioStream)
+			newClient, err := c.GetClient()
+			if err != nil {
+				return err
+			}
+			return ListEnvs(ctx, newClient, args, ioStream)
 		},
 		Annotations: map[string]string{
 			types.TagCommandType: types.TypeStart,
@@ -76,7 +85,7 @@ func NewEnvInitCommand(c common.Args, ioStreams cmdutil.IOStreams) *cobra.Comman
 		DisableFlagsInUseLine: true,
 		Short:                 "Create environments",
 		Long:                  "Create environment and set the currently using environment",
-		Example:               `vela env init test --namespace test --email my@email.com`,
+		Example:               `vela env init test --namespace test --email my@email.com --clusters prod-1,prod-2`,
 		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
 			return c.SetConfig()
 		},
@@ -96,11 +105,12 @@ func NewEnvInitCommand(c common.Args, ioStreams cmdutil.IOStreams) *cobra.Comman
 	cmd.Flags().StringVar(&envArgs.Namespace, "namespace", "", "specify K8s namespace for env")
 	cmd.Flags().StringVar(&envArgs.Email, "email", "", "specify email for production TLS Certificate notification")
 	cmd.Flags().StringVar(&envArgs.Domain, "domain", "", "specify domain your applications")
+	cmd.Flags().StringSliceVar(&envArgs.Clusters, "clusters", nil, "specify default clusters your applications are deployed to")
 	return cmd
 }
 
 // NewEnvDeleteCommand creates `env delete` command for deleting environments
-func NewEnvDeleteCommand(ioStreams cmdutil.IOStreams) *cobra.Command {
+func NewEnvDeleteCommand(c common.Args, ioStreams cmdutil.IOStreams) *cobra.Command {
 	ctx := context.Background()
 	cmd := &cobra.Command{
 		Use:                   "delete",
@@ -108,8 +118,15 @@ func NewEnvDeleteCommand(ioStreams cmdutil.IOStreams) *cobra.Command {
 		Short:                 "Delete environment",
 		Long:                  "Delete environment",
 		Example:               `vela env delete test`,
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			return c.SetConfig()
+		},
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return DeleteEnv(ctx, args, ioStreams)
+			newClient, err := c.GetClient()
+			if err != nil {
+				return err
+			}
+			return DeleteEnv(ctx, newClient, args, ioStreams)
 		},
 		Annotations: map[string]string{
 			types.TagCommandType: types.TypeStart,
@@ -120,7 +137,8 @@ func NewEnvDeleteCommand(ioStreams cmdutil.IOStreams) *cobra.Command {
 }
 
 // NewEnvSetCommand creates `env set` command for setting current environment
-func NewEnvSetCommand(ioStreams cmdutil.IOStreams) *cobra.Command {
+func NewEnvSetCommand(c common.Args, ioStreams cmdutil.IOStreams) *cobra.Command {
+	ctx := context.Background()
 	cmd := &cobra.Command{
 		Use:                   "set",
 		Aliases:               []string{"sw"},
@@ -128,8 +146,15 @@ func NewEnvSetCommand(ioStreams cmdutil.IOStreams) *cobra.Command {
 		Short:                 "Set an environment",
 		Long:                  "Set an environment as the current using one",
 		Example:               `vela env set test`,
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			return c.SetConfig()
+		},
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return SetEnv(args, ioStreams)
+			newClient, err := c.GetClient()
+			if err != nil {
+				return err
+			}
+			return SetEnv(ctx, newClient, args, ioStreams)
 		},
 		Annotations: map[string]string{
 			types.TagCommandType: types.TypeStart,
@@ -140,31 +165,31 @@ func NewEnvSetCommand(ioStreams cmdutil.IOStreams) *cobra.Command {
 }
 
 // ListEnvs shows info of all environments
-func ListEnvs(args []string, ioStreams cmdutil.IOStreams) error {
+func ListEnvs(ctx context.Context, c client.Reader, args []string, ioStreams cmdutil.IOStreams) error {
 	table := newUITable()
-	table.AddRow("NAME", "CURRENT", "NAMESPACE", "EMAIL", "DOMAIN")
+	table.AddRow("NAME", "CURRENT", "NAMESPACE", "EMAIL", "DOMAIN", "CLUSTERS")
 	var envName = ""
 	if len(args) > 0 {
 		envName = args[0]
 	}
-	envList, err := env.ListEnvs(envName)
+	envList, err := env.ListEnvs(ctx, c, envName)
 	if err != nil {
 		return err
 	}
 	for _, env := range envList {
-		table.AddRow(env.Name, env.Current, env.Namespace, env.Email, env.Domain)
+		table.AddRow(env.Name, env.Current, env.Namespace, env.Email, env.Domain, strings.Join(env.Clusters, ","))
 	}
 	ioStreams.Info(table.String())
 	return nil
 }
 
 // DeleteEnv deletes an environment
-func DeleteEnv(ctx context.Context, args []string, ioStreams cmdutil.IOStreams) error {
+func DeleteEnv(ctx context.Context, c client.Client, args []string, ioStreams cmdutil.IOStreams) error {
 	if len(args) < 1 {
 		return fmt.Errorf("you must specify environment name for 'vela env delete' command")
 	}
 	for _, envName := range args {
-		msg, err := env.DeleteEnv(envName)
+		msg, err := env.DeleteEnv(ctx, c, envName)
 		if err != nil {
 			return err
 		}
@@ -189,12 +214,12 @@ func CreateOrUpdateEnv(ctx context.Context, c client.Client, envArgs *types.EnvM
 }
 
 // SetEnv sets current environment
-func SetEnv(args []string, ioStreams cmdutil.IOStreams) error {
+func SetEnv(ctx context.Context, c client.Reader, args []string, ioStreams cmdutil.IOStreams) error {
 	if len(args) < 1 {
 		return fmt.Errorf("you must specify environment name for vela env command")
 	}
 	envName := args[0]
-	msg, err := env.SetEnv(envName)
+	msg, err := env.SetEnv(ctx, c, envName)
 	if err != nil {
 		return err
 	}
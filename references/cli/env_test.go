@@ -19,13 +19,15 @@ package cli
 import (
 	"bytes"
 	"context"
+	"io/ioutil"
 	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
-	"github.com/crossplane/crossplane-runtime/pkg/test"
 	"github.com/spf13/cobra"
 	"github.com/stretchr/testify/assert"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 
 	"github.com/oam-dev/kubevela/apis/types"
 	"github.com/oam-dev/kubevela/pkg/utils/common"
@@ -62,7 +64,7 @@ func TestENV(t *testing.T) {
 		Namespace: "test1",
 		Name:      "env1",
 	}
-	client := test.NewMockClient()
+	client := fake.NewFakeClientWithScheme(common.Scheme)
 	// Create env1
 	err = CreateOrUpdateEnv(ctx, client, exp, []string{"env1"}, ioStream)
 	assert.NoError(t, err)
@@ -78,21 +80,35 @@ func TestENV(t *testing.T) {
 	// List all env
 	var b bytes.Buffer
 	ioStream.Out = &b
-	err = ListEnvs([]string{}, ioStream)
+	err = ListEnvs(ctx, client, []string{}, ioStream)
 	assert.NoError(t, err)
-	assert.Equal(t, "NAME   \tCURRENT\tNAMESPACE\tEMAIL\tDOMAIN\ndefault\t       \tdefault  \t     \t      \nenv1   \t*      \ttest1    \t     \t      \n", b.String())
+	assert.Equal(t, "NAME   \tCURRENT\tNAMESPACE\tEMAIL\tDOMAIN\tCLUSTERS\ndefault\t       \tdefault  \t     \t      \t        \nenv1   \t*      \ttest1    \t     \t      \t        \n", b.String())
 	b.Reset()
-	err = ListEnvs([]string{"env1"}, ioStream)
+	err = ListEnvs(ctx, client, []string{"env1"}, ioStream)
 	assert.NoError(t, err)
-	assert.Equal(t, "NAME\tCURRENT\tNAMESPACE\tEMAIL\tDOMAIN\nenv1\t       \ttest1    \t     \t      \n", b.String())
+	assert.Equal(t, "NAME\tCURRENT\tNAMESPACE\tEMAIL\tDOMAIN\tCLUSTERS\nenv1\t       \ttest1    \t     \t      \t        \n", b.String())
+
+	// the envs only cached locally are listed too
+	assert.NoError(t, os.MkdirAll(env.GetEnvDirByName("local"), 0750))
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(env.GetEnvDirByName("local"), system.EnvConfigName),
+		[]byte(`{"name":"local","namespace":"local-ns"}`), 0600))
+	b.Reset()
+	err = ListEnvs(ctx, client, []string{}, ioStream)
+	assert.NoError(t, err)
+	assert.Equal(t, "NAME   \tCURRENT\tNAMESPACE\tEMAIL\tDOMAIN\tCLUSTERS\ndefault\t       \tdefault  \t     \t      \t        \nenv1   \t*      \ttest1    \t     \t      \t        \nlocal  \t       \tlocal-ns \t     \t      \t        \n", b.String())
+	b.Reset()
+	err = ListEnvs(ctx, client, []string{"local"}, ioStream)
+	assert.NoError(t, err)
+	assert.Contains(t, b.String(), "local-ns")
+	assert.NoError(t, os.RemoveAll(env.GetEnvDirByName("local")))
 	ioStream.Out = os.Stdout
 
 	// can not delete current env
-	err = DeleteEnv(ctx, []string{"env1"}, ioStream)
+	err = DeleteEnv(ctx, client, []string{"env1"}, ioStream)
 	assert.Error(t, err)
 
 	// set as default env
-	err = SetEnv([]string{"default"}, ioStream)
+	err = SetEnv(ctx, client, []string{"default"}, ioStream)
 	assert.NoError(t, err)
 
 	// check env set success
@@ -104,15 +120,15 @@ func TestENV(t *testing.T) {
 	}, gotEnv)
 
 	// delete env
-	err = DeleteEnv(ctx, []string{"env1"}, ioStream)
+	err = DeleteEnv(ctx, client, []string{"env1"}, ioStream)
 	assert.NoError(t, err)
 
 	// can not set as a non-exist env
-	err = SetEnv([]string{"env1"}, ioStream)
+	err = SetEnv(ctx, client, []string{"env1"}, ioStream)
 	assert.Error(t, err)
 
 	// set success
-	err = SetEnv([]string{"default"}, ioStream)
+	err = SetEnv(ctx, client, []string{"default"}, ioStream)
 	assert.NoError(t, err)
 }
 
@@ -0,0 +1,124 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cli
+
+import (
+	"fmt"
+
+	"github.com/oam-dev/kubevela/references/appfile/dryrun"
+)
+
+// formatEstimate formats the estimated resources of an application in a table, the changes are shown if base is not nil
+func formatEstimate(target, base *dryrun.Estimate, prices *dryrun.PriceTable) string {
+	header := []interface{}{"COMPONENT", "REPLICAS", "CPU(REQ/LIMIT)", "MEMORY(REQ/LIMIT)", "STORAGE"}
+	if prices != nil {
+		costHeader := "COST"
+		if prices.Period != "" {
+			costHeader += "/" + prices.Period
+		}
+		header = append(header, costHeader)
+	}
+	table := newUITable()
+	table.AddRow(header...)
+	addRow := func(name string, t, b *dryrun.ComponentEstimate) {
+		row := []interface{}{name}
+		for _, format := range []func(*dryrun.ComponentEstimate) string{
+			formatReplicas, formatCPU, formatMemory, formatStorage} {
+			row = append(row, estimateChange(t, b, base != nil, format))
+		}
+		if prices != nil {
+			row = append(row, estimateChange(t, b, base != nil, func(e *dryrun.ComponentEstimate) string {
+				return formatCost(e, prices.Currency)
+			}))
+		}
+		table.AddRow(row...)
+	}
+	for i := range target.Components {
+		t := &target.Components[i]
+		var b *dryrun.ComponentEstimate
+		if base != nil {
+			b = base.Component(t.Name)
+		}
+		addRow(t.Name, t, b)
+	}
+	if base != nil {
+		for i := range base.Components {
+			if b := &base.Components[i]; target.Component(b.Name) == nil {
+				addRow(b.Name, nil, b)
+			}
+		}
+		addRow("TOTAL", &target.Total, &base.Total)
+	} else {
+		addRow("TOTAL", &target.Total, nil)
+	}
+	return table.String()
+}
+
+// estimateChange formats the estimate of the target, and the estimate of the base if it's compared and changed
+func estimateChange(target, base *dryrun.ComponentEstimate, compared bool, format func(*dryrun.ComponentEstimate) string) string {
+	t, b := "-", "-"
+	if target != nil {
+		t = format(target)
+	}
+	if base != nil {
+		b = format(base)
+	}
+	if !compared || t == b {
+		return t
+	}
+	return fmt.Sprintf("%s -> %s", b, t)
+}
+
+func formatReplicas(e *dryrun.ComponentEstimate) string {
+	replicas := fmt.Sprint(e.Replicas)
+	switch {
+	case e.MaxUnbounded:
+		replicas = fmt.Sprintf("%d-%d+", e.Replicas, e.MaxReplicas)
+	case e.MaxReplicas != e.Replicas:
+		replicas = fmt.Sprintf("%d-%d", e.Replicas, e.MaxReplicas)
+	}
+	if e.PerNode {
+		replicas += " per node"
+	}
+	return replicas
+}
+
+func formatCPU(e *dryrun.ComponentEstimate) string {
+	return fmt.Sprintf("%s/%s", e.Requests.CPU.String(), e.Limits.CPU.String())
+}
+
+func formatMemory(e *dryrun.ComponentEstimate) string {
+	return fmt.Sprintf("%s/%s", e.Requests.Memory.String(), e.Limits.Memory.String())
+}
+
+func formatStorage(e *dryrun.ComponentEstimate) string {
+	return e.Requests.Storage.String()
+}
+
+func formatCost(e *dryrun.ComponentEstimate, currency string) string {
+	cost := fmt.Sprintf("%.2f", e.Cost)
+	switch {
+	case e.MaxUnbounded:
+		cost = fmt.Sprintf("%.2f-%.2f+", e.Cost, e.MaxCost)
+	case e.MaxCost != e.Cost:
+		cost = fmt.Sprintf("%.2f-%.2f", e.Cost, e.MaxCost)
+	}
+	if currency != "" {
+		cost += " " + currency
+	}
+	return cost
+}
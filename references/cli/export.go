@@ -17,21 +17,42 @@ limitations under the License.
 package cli
 
 import (
+	"bytes"
+	"context"
+
+	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
+	"sigs.k8s.io/yaml"
 
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
 	"github.com/oam-dev/kubevela/apis/types"
+	"github.com/oam-dev/kubevela/pkg/oam"
+	oamutil "github.com/oam-dev/kubevela/pkg/oam/util"
 	common2 "github.com/oam-dev/kubevela/pkg/utils/common"
 	cmdutil "github.com/oam-dev/kubevela/pkg/utils/util"
+	"github.com/oam-dev/kubevela/references/appfile/dryrun"
+	"github.com/oam-dev/kubevela/references/appfile/template"
 	"github.com/oam-dev/kubevela/references/common"
 )
 
+// ExportCmdOptions contains export cmd options
+type ExportCmdOptions struct {
+	// Rendered exports the K8s resources rendered from the application rather than the Application object
+	Rendered       bool
+	DefinitionFile string
+	Offline        bool
+	OpenAPISchema  string
+}
+
 // NewExportCommand will create command for exporting deploy manifests from an AppFile
 func NewExportCommand(c common2.Args, ioStream cmdutil.IOStreams) *cobra.Command {
+	eo := &ExportCmdOptions{}
 	cmd := &cobra.Command{
 		Use:                   "export",
 		DisableFlagsInUseLine: true,
 		Short:                 "Export deploy manifests from appfile",
 		Long:                  "Export deploy manifests from appfile",
+		Example:               "vela export -f vela.yaml\nvela export -f vela.yaml --rendered --offline -d ./definitions",
 		Annotations: map[string]string{
 			types.TagCommandType: types.TypeStart,
 		},
@@ -48,10 +69,29 @@ func NewExportCommand(c common2.Args, ioStream cmdutil.IOStreams) *cobra.Command
 			if err != nil {
 				return err
 			}
-			_, data, err := o.Export(filePath, velaEnv.Namespace, true, c)
+			if o.Profile, err = cmd.Flags().GetString("profile"); err != nil {
+				return err
+			}
+			objs := []oam.Object{}
+			if eo.DefinitionFile != "" {
+				if objs, err = ReadObjectsFromFile(eo.DefinitionFile); err != nil {
+					return err
+				}
+			}
+			if eo.Offline {
+				if o.Templates, err = template.LoadFromDefinitions(objs); err != nil {
+					return err
+				}
+			}
+			result, data, err := o.Export(filePath, velaEnv.Namespace, true, c)
 			if err != nil {
 				return err
 			}
+			if eo.Rendered {
+				if data, err = renderApplication(c, result.GetApplication(), objs, eo); err != nil {
+					return err
+				}
+			}
 			_, err = ioStream.Out.Write(data)
 			return err
 		},
@@ -59,5 +99,36 @@ func NewExportCommand(c common2.Args, ioStream cmdutil.IOStreams) *cobra.Command
 	cmd.SetOut(ioStream.Out)
 
 	cmd.Flags().StringP(appFilePath, "f", "", "specify file path for appfile")
+	cmd.Flags().String("profile", "", "the profile to patch the appfile with, the profile named after current env is used by default")
+	cmd.Flags().BoolVar(&eo.Rendered, "rendered", false, "export the K8s resources rendered from the application rather than the Application object")
+	cmd.Flags().StringVarP(&eo.DefinitionFile, "definition", "d", "", "specify a definition file or directory used to render the appfile, required in offline mode")
+	addOfflineFlags(cmd, &eo.Offline, &eo.OpenAPISchema)
 	return cmd
 }
+
+// renderApplication dry-runs the application and returns the rendered K8s resources in YAML
+func renderApplication(c common2.Args, app *v1beta1.Application, objs []oam.Object, eo *ExportCmdOptions) ([]byte, error) {
+	dryRunOpt, err := newDryRunOption(c, objs, eo.Offline, eo.OpenAPISchema)
+	if err != nil {
+		return nil, err
+	}
+	ctx := oamutil.SetNamespaceInCtx(context.Background(), app.Namespace)
+	ac, comps, err := dryRunOpt.ExecuteDryRun(ctx, app)
+	if err != nil {
+		return nil, errors.WithMessage(err, "render application")
+	}
+	manifests, err := dryrun.RenderManifests(ac, comps)
+	if err != nil {
+		return nil, err
+	}
+	var buff bytes.Buffer
+	for _, m := range manifests {
+		data, err := yaml.Marshal(m.Object)
+		if err != nil {
+			return nil, errors.Wrapf(err, "marshal %s %s", m.GetKind(), m.GetName())
+		}
+		buff.WriteString("---\n")
+		buff.Write(data)
+	}
+	return buff.Bytes(), nil
+}
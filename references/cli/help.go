@@ -65,5 +65,5 @@ func PrintHelpByTag(cmd *cobra.Command, all []*cobra.Command, tag string) {
 
 // AddTokenVarFlags adds token flag to a command
 func AddTokenVarFlags(cmd *cobra.Command) {
-	cmd.PersistentFlags().StringP("token", "t", "", "Github Repo token")
+	cmd.PersistentFlags().StringP("token", "t", "", "Token of the capability center, e.g. Github token, bearer token or <username>:<password> of OCI registry")
 }
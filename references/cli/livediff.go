@@ -51,7 +51,7 @@ func NewLiveDiffCommand(c common.Args, ioStreams cmdutil.IOStreams) *cobra.Comma
 		DisableFlagsInUseLine: true,
 		Short:                 "Dry-run an application, and do diff on a specific app revison",
 		Long:                  "Dry-run an application, and do diff on a specific app revison. The provided capability definitions will be used during Dry-run. If any capabilities used in the app are not found in the provided ones, it will try to find from cluster.",
-		Example:               "vela live-diff -f app-v2.yaml -r app-v1 --context 10",
+		Example:               "vela live-diff -f app-v2.yaml -r app-v1 --context 10\nvela live-diff -f app-v2.yaml --estimate --price-table prices.yaml",
 		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
 			return c.SetConfig()
 		},
@@ -73,6 +73,7 @@ func NewLiveDiffCommand(c common.Args, ioStreams cmdutil.IOStreams) *cobra.Comma
 	cmd.Flags().StringVarP(&o.DefinitionFile, "definition", "d", "", "specify a file or directory containing capability definitions, they will only be used in dry-run rather than applied to K8s cluster")
 	cmd.Flags().StringVarP(&o.Revision, "Revision", "r", "", "specify an application Revision name, by default, it will compare with the latest Revision")
 	cmd.Flags().IntVarP(&o.Context, "context", "c", -1, "output number lines of context around changes, by default show all unchanged lines")
+	addEstimateFlags(cmd, &o.Estimate, &o.PriceTable)
 	cmd.SetOut(ioStreams.Out)
 	return cmd
 }
@@ -146,5 +147,18 @@ func LiveDiffApplication(cmdOption *LiveDiffCmdOptions, c common.Args, namespace
 	reportDiffOpt := dryrun.NewReportDiffOption(cmdOption.Context, &buff)
 	reportDiffOpt.PrintDiffReport(diffResult)
 
+	if cmdOption.Estimate {
+		prices, err := loadPriceTable(cmdOption.PriceTable)
+		if err != nil {
+			return buff, err
+		}
+		target, base, err := liveDiffOption.Estimate(context.Background(), app, appRevision, prices)
+		if err != nil {
+			return buff, err
+		}
+		buff.WriteString(fmt.Sprintf("\nEstimated resources compared with %s:\n", appRevision.Name))
+		buff.WriteString(formatEstimate(target, base, prices))
+	}
+
 	return buff, nil
 }
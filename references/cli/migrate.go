@@ -0,0 +1,185 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cli
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	ktypes "k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/yaml"
+
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1alpha2"
+	"github.com/oam-dev/kubevela/apis/types"
+	"github.com/oam-dev/kubevela/pkg/oam"
+	common2 "github.com/oam-dev/kubevela/pkg/utils/common"
+	cmdutil "github.com/oam-dev/kubevela/pkg/utils/util"
+	"github.com/oam-dev/kubevela/references/common"
+)
+
+// handoverInterval is the interval to check if the application has taken over the resources of the AppConfig
+const handoverInterval = 2 * time.Second
+
+// NewMigrateCommand creates `migrate` command
+func NewMigrateCommand(c common2.Args, ioStreams cmdutil.IOStreams) *cobra.Command {
+	var output string
+	var handover bool
+	var timeout time.Duration
+	cmd := &cobra.Command{
+		Use:                   "migrate <appconfig>",
+		DisableFlagsInUseLine: true,
+		Short:                 "Migrate an ApplicationConfiguration to an application",
+		Long: "Convert an ApplicationConfiguration and its Components into an application. Each workload becomes a " +
+			"component of the ComponentDefinition that renders exactly the same workload, otherwise a ComponentDefinition " +
+			"with a kube schematic is generated from the Component. Each trait becomes a trait of the TraitDefinition that " +
+			"renders exactly the same trait with the same name, otherwise a TraitDefinition is generated for its kind. The parts that the " +
+			"application cannot express are printed as warnings. With --handover, the definitions and the application are " +
+			"applied, and the AppConfig is deleted once the application has taken over its workloads and traits.",
+		Example: "vela migrate example-appconfig -o app.yaml\nvela migrate example-appconfig --handover",
+		Annotations: map[string]string{
+			types.TagCommandType: types.TypeApp,
+		},
+		Args: cobra.ExactArgs(1),
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			return c.SetConfig()
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			velaEnv, err := GetEnv(cmd)
+			if err != nil {
+				return err
+			}
+			newClient, err := c.GetClient()
+			if err != nil {
+				return err
+			}
+			dm, err := c.GetDiscoveryMapper()
+			if err != nil {
+				return err
+			}
+			pd, err := c.GetPackageDiscover()
+			if err != nil {
+				return err
+			}
+			ctx := context.Background()
+			ac := &v1alpha2.ApplicationConfiguration{}
+			if err := newClient.Get(ctx, ktypes.NamespacedName{Namespace: velaEnv.Namespace, Name: args[0]}, ac); err != nil {
+				return errors.Wrapf(err, "cannot get AppConfig %s", args[0])
+			}
+			migration, err := common.MigrateAppConfig(ctx, newClient, dm, pd, ac)
+			if err != nil {
+				return err
+			}
+			for _, w := range migration.Warnings {
+				ioStreams.Errorf("Warning: %s\n", w)
+			}
+
+			if handover {
+				if err := common.HandOverAppConfig(ctx, newClient, ac, migration.Application.Name); err != nil {
+					return err
+				}
+				if err := common.ApplyMigration(ctx, newClient, migration); err != nil {
+					return err
+				}
+				ioStreams.Infof("Waiting for application %s to take over the workloads and traits of AppConfig %s\n", migration.Application.Name, ac.Name)
+				deadline := time.Now().Add(timeout)
+				for {
+					handedOver, err := common.AppConfigHandedOver(ctx, newClient, ac)
+					if err != nil {
+						return err
+					}
+					if handedOver {
+						break
+					}
+					if time.Now().After(deadline) {
+						return errors.Errorf("application %s hasn't taken over the workloads and traits of AppConfig %s in %s, "+
+							"check the status of the application and run the command again", migration.Application.Name, ac.Name, timeout)
+					}
+					time.Sleep(handoverInterval)
+				}
+				if err := newClient.Delete(ctx, ac); err != nil {
+					return errors.Wrapf(err, "cannot delete AppConfig %s", ac.Name)
+				}
+				ioStreams.Infof("AppConfig %s is migrated to application %s, the Components are kept\n", ac.Name, migration.Application.Name)
+				return nil
+			}
+
+			data, err := marshalMigration(migration)
+			if err != nil {
+				return err
+			}
+			ioStreams.Errorf("Annotate AppConfig %s with %s=%s before applying the application, so it takes over the workloads and traits\n",
+				ac.Name, oam.AnnotationHandedOverTo, migration.Application.Name)
+			if output != "" {
+				if err := ioutil.WriteFile(output, data, 0600); err != nil {
+					return errors.Wrapf(err, "cannot write application to %s", output)
+				}
+				ioStreams.Infof("Application %s is written to %s\n", migration.Application.Name, output)
+				return nil
+			}
+			_, err = ioStreams.Out.Write(data)
+			return err
+		},
+	}
+	cmd.SetOut(ioStreams.Out)
+	cmd.Flags().StringVarP(&output, "output", "o", "", "write the definitions and application to the file instead of stdout")
+	cmd.Flags().BoolVar(&handover, "handover", false, "apply the definitions and application, and delete the AppConfig once the application has taken over its workloads and traits")
+	cmd.Flags().DurationVar(&timeout, "timeout", 5*time.Minute, "how long to wait for the application to take over the workloads and traits")
+	return cmd
+}
+
+// marshalMigration marshals the generated definitions and the application into a multi-document YAML
+func marshalMigration(migration *common.Migration) ([]byte, error) {
+	var objects []runtime.Object
+	for _, cd := range migration.ComponentDefinitions {
+		objects = append(objects, cd)
+	}
+	for _, td := range migration.TraitDefinitions {
+		objects = append(objects, td)
+	}
+	var buff bytes.Buffer
+	for _, obj := range objects {
+		data, err := marshalDefinition(obj)
+		if err != nil {
+			return nil, err
+		}
+		buff.Write(data)
+		buff.WriteString("---\n")
+	}
+	data, err := marshalApplication(migration.Application)
+	if err != nil {
+		return nil, err
+	}
+	buff.Write(data)
+	return buff.Bytes(), nil
+}
+
+// marshalDefinition marshals a definition into YAML without its status and empty metadata
+func marshalDefinition(obj runtime.Object) ([]byte, error) {
+	u, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+	if err != nil {
+		return nil, err
+	}
+	unstructured.RemoveNestedField(u, "status")
+	unstructured.RemoveNestedField(u, "metadata", "creationTimestamp")
+	return yaml.Marshal(u)
+}
@@ -0,0 +1,196 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cli
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/oam-dev/kubevela/apis/types"
+	common2 "github.com/oam-dev/kubevela/pkg/utils/common"
+	cmdutil "github.com/oam-dev/kubevela/pkg/utils/util"
+	"github.com/oam-dev/kubevela/references/common"
+)
+
+// NewRevisionCommand creates `revision` command and its nested children
+func NewRevisionCommand(c common2.Args, ioStreams cmdutil.IOStreams) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:                   "revision",
+		Aliases:               []string{"rev"},
+		DisableFlagsInUseLine: true,
+		Short:                 "Manage application revisions",
+		Long:                  "List, compare and rollback revisions of an application",
+		Annotations: map[string]string{
+			types.TagCommandType: types.TypeApp,
+		},
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			return c.SetConfig()
+		},
+	}
+	cmd.SetOut(ioStreams.Out)
+	cmd.AddCommand(
+		NewRevisionListCommand(c, ioStreams),
+		NewRevisionDiffCommand(c, ioStreams),
+		NewRevisionRollbackCommand(c, ioStreams),
+	)
+	return cmd
+}
+
+// NewRevisionListCommand creates `revision ls` command
+func NewRevisionListCommand(c common2.Args, ioStreams cmdutil.IOStreams) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:                   "ls <appName>",
+		Aliases:               []string{"list"},
+		DisableFlagsInUseLine: true,
+		Short:                 "List revisions of an application",
+		Long:                  "List revisions of an application with who made the change, what is changed and why",
+		Example:               `vela revision ls myapp`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) < 1 {
+				return fmt.Errorf("must specify application name, vela revision ls <appName>")
+			}
+			velaEnv, err := GetEnv(cmd)
+			if err != nil {
+				return err
+			}
+			newClient, err := c.GetClient()
+			if err != nil {
+				return err
+			}
+			return listRevisions(context.Background(), newClient, args[0], velaEnv.Namespace, ioStreams)
+		},
+	}
+	cmd.SetOut(ioStreams.Out)
+	return cmd
+}
+
+func listRevisions(ctx context.Context, c client.Reader, appName, namespace string, ioStreams cmdutil.IOStreams) error {
+	revisions, err := common.ListRevisions(ctx, c, appName, namespace)
+	if err != nil {
+		return err
+	}
+	table := newUITable()
+	table.AddRow("NAME", "REVISION", "LATEST", "COMPONENTS", "USER", "CHANGES", "MESSAGE", "CREATED-TIME")
+	for _, rev := range revisions {
+		latest := ""
+		if rev.Latest {
+			latest = "*"
+		}
+		var user, message string
+		if rev.Change != nil {
+			user, message = rev.Change.User, rev.Change.Message
+		}
+		table.AddRow(rev.Name, rev.Revision, latest, strings.Join(rev.Components, ","), user,
+			common.SummarizeChange(rev.Change), message, rev.CreatedTime)
+	}
+	ioStreams.Info(table.String())
+	return nil
+}
+
+// NewRevisionDiffCommand creates `revision diff` command
+func NewRevisionDiffCommand(c common2.Args, ioStreams cmdutil.IOStreams) *cobra.Command {
+	var diffContext int
+	var estimate bool
+	var priceTable string
+	cmd := &cobra.Command{
+		Use:                   "diff <appName> <baseRevision> [targetRevision]",
+		DisableFlagsInUseLine: true,
+		Short:                 "Compare two revisions of an application",
+		Long:                  "Compare two revisions of an application, the target revision is the latest one if it's not specified. Revisions could be specified by name or number.",
+		Example:               "vela revision diff myapp v1 v3\nvela revision diff myapp v1 --estimate --price-table prices.yaml",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) < 2 {
+				return fmt.Errorf("must specify application name and revision, vela revision diff <appName> <baseRevision> [targetRevision]")
+			}
+			var target string
+			if len(args) > 2 {
+				target = args[2]
+			}
+			velaEnv, err := GetEnv(cmd)
+			if err != nil {
+				return err
+			}
+			newClient, err := c.GetClient()
+			if err != nil {
+				return err
+			}
+			diff, err := common.DiffRevisions(context.Background(), newClient, args[0], velaEnv.Namespace, args[1], target, diffContext)
+			if err != nil {
+				return err
+			}
+			if !diff.Changed {
+				ioStreams.Infof("no difference between %s and %s\n", diff.Base, diff.Target)
+			} else {
+				ioStreams.Info(diff.Report)
+			}
+			if !estimate {
+				return nil
+			}
+			prices, err := loadPriceTable(priceTable)
+			if err != nil {
+				return err
+			}
+			baseEstimate, targetEstimate, err := common.EstimateRevisions(context.Background(), newClient, args[0], velaEnv.Namespace, diff.Base, diff.Target, prices)
+			if err != nil {
+				return err
+			}
+			ioStreams.Infof("\nEstimated resources of %s compared with %s:\n", diff.Target, diff.Base)
+			ioStreams.Info(formatEstimate(targetEstimate, baseEstimate, prices))
+			return nil
+		},
+	}
+	cmd.Flags().IntVarP(&diffContext, "context", "c", -1, "output number lines of context around changes, by default show all unchanged lines")
+	addEstimateFlags(cmd, &estimate, &priceTable)
+	cmd.SetOut(ioStreams.Out)
+	return cmd
+}
+
+// NewRevisionRollbackCommand creates `revision rollback` command
+func NewRevisionRollbackCommand(c common2.Args, ioStreams cmdutil.IOStreams) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:                   "rollback <appName> <revision>",
+		DisableFlagsInUseLine: true,
+		Short:                 "Rollback an application to a revision",
+		Long:                  "Restore the spec of an application from one of its revisions, a new revision will be created with the restored spec",
+		Example:               `vela revision rollback myapp v2`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) < 2 {
+				return fmt.Errorf("must specify application name and revision, vela revision rollback <appName> <revision>")
+			}
+			velaEnv, err := GetEnv(cmd)
+			if err != nil {
+				return err
+			}
+			newClient, err := c.GetClient()
+			if err != nil {
+				return err
+			}
+			rev, err := common.RollbackApplication(context.Background(), newClient, args[0], velaEnv.Namespace, args[1])
+			if err != nil {
+				return err
+			}
+			ioStreams.Infof("application %s is rolled back to revision %s %s\n", args[0], rev.Name, emojiSucceed)
+			return nil
+		},
+	}
+	cmd.SetOut(ioStreams.Out)
+	return cmd
+}
@@ -0,0 +1,216 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cli
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/spf13/cobra"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/oam-dev/kubevela/apis/types"
+	common2 "github.com/oam-dev/kubevela/pkg/utils/common"
+	cmdutil "github.com/oam-dev/kubevela/pkg/utils/util"
+	"github.com/oam-dev/kubevela/references/apiserver/apis"
+	"github.com/oam-dev/kubevela/references/common"
+)
+
+// NewRolloutCommand creates `rollout` command and its nested children
+func NewRolloutCommand(c common2.Args, ioStreams cmdutil.IOStreams) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:                   "rollout",
+		DisableFlagsInUseLine: true,
+		Short:                 "Operate rollouts",
+		Long:                  "Show the status of rollouts, pause, resume, approve, abort or rollback them",
+		Annotations: map[string]string{
+			types.TagCommandType: types.TypeApp,
+		},
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			return c.SetConfig()
+		},
+	}
+	cmd.SetOut(ioStreams.Out)
+	cmd.AddCommand(
+		NewRolloutStatusCommand(c, ioStreams),
+		newRolloutOperationCommand(c, ioStreams, "pause", "Pause a rollout",
+			"Pause a rollout, the current batch is finished and the next batches are held until it's resumed",
+			func(ctx context.Context, c client.Client, name, namespace string) (string, error) {
+				return fmt.Sprintf("rollout %s is paused", name), common.PauseRollout(ctx, c, name, namespace, true)
+			}),
+		newRolloutOperationCommand(c, ioStreams, "resume", "Resume a rollout", "Resume a paused rollout",
+			func(ctx context.Context, c client.Client, name, namespace string) (string, error) {
+				return fmt.Sprintf("rollout %s is resumed", name), common.PauseRollout(ctx, c, name, namespace, false)
+			}),
+		NewRolloutApproveNextCommand(c, ioStreams),
+		newRolloutOperationCommand(c, ioStreams, "abort", "Abort a rollout",
+			"Abort a rollout in progress, the rollout fails at the current batch and the upgraded batches are kept",
+			func(ctx context.Context, c client.Client, name, namespace string) (string, error) {
+				return fmt.Sprintf("rollout %s is aborted", name), common.AbortRollout(ctx, c, name, namespace)
+			}),
+		newRolloutOperationCommand(c, ioStreams, "rollback", "Rollback a rollout",
+			"Rollback a rollout to its source revision, the rollback is neither paused nor held for approval",
+			func(ctx context.Context, c client.Client, name, namespace string) (string, error) {
+				rollout, err := common.RollbackRollout(ctx, c, name, namespace)
+				if err != nil {
+					return "", err
+				}
+				return fmt.Sprintf("rollout %s is rolling back to revision %s", name, rollout.Spec.TargetAppRevisionName), nil
+			}),
+	)
+	return cmd
+}
+
+// newRolloutOperationCommand creates a command operating a rollout by its name
+func newRolloutOperationCommand(c common2.Args, ioStreams cmdutil.IOStreams, name, short, long string,
+	operate func(ctx context.Context, c client.Client, name, namespace string) (string, error)) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:                   name + " <rolloutName>",
+		DisableFlagsInUseLine: true,
+		Short:                 short,
+		Long:                  long,
+		Example:               fmt.Sprintf("vela rollout %s myrollout", name),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) < 1 {
+				return fmt.Errorf("must specify rollout name, vela rollout %s <rolloutName>", name)
+			}
+			velaEnv, err := GetEnv(cmd)
+			if err != nil {
+				return err
+			}
+			newClient, err := c.GetClient()
+			if err != nil {
+				return err
+			}
+			msg, err := operate(context.Background(), newClient, args[0], velaEnv.Namespace)
+			if err != nil {
+				return err
+			}
+			ioStreams.Infof("%s %s\n", msg, emojiSucceed)
+			return nil
+		},
+	}
+	cmd.SetOut(ioStreams.Out)
+	return cmd
+}
+
+// NewRolloutStatusCommand creates `rollout status` command
+func NewRolloutStatusCommand(c common2.Args, ioStreams cmdutil.IOStreams) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:                   "status <rolloutName>",
+		DisableFlagsInUseLine: true,
+		Short:                 "Show the status of a rollout",
+		Long:                  "Show the status of a rollout and its batches",
+		Example:               `vela rollout status myrollout`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) < 1 {
+				return fmt.Errorf("must specify rollout name, vela rollout status <rolloutName>")
+			}
+			velaEnv, err := GetEnv(cmd)
+			if err != nil {
+				return err
+			}
+			newClient, err := c.GetClient()
+			if err != nil {
+				return err
+			}
+			status, err := common.GetRolloutStatus(context.Background(), newClient, args[0], velaEnv.Namespace)
+			if err != nil {
+				return err
+			}
+			printRolloutStatus(status, ioStreams)
+			return nil
+		},
+	}
+	cmd.SetOut(ioStreams.Out)
+	return cmd
+}
+
+// NewRolloutApproveNextCommand creates `rollout approve-next` command
+func NewRolloutApproveNextCommand(c common2.Args, ioStreams cmdutil.IOStreams) *cobra.Command {
+	var force bool
+	cmd := &cobra.Command{
+		Use:                   "approve-next <rolloutName>",
+		DisableFlagsInUseLine: true,
+		Short:                 "Approve the next batch of a rollout",
+		Long: "Show the health of the current batch and approve the next batch by advancing the batch partition by one. " +
+			"The current batch must be ready unless --force is set.",
+		Example: `vela rollout approve-next myrollout`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) < 1 {
+				return fmt.Errorf("must specify rollout name, vela rollout approve-next <rolloutName>")
+			}
+			velaEnv, err := GetEnv(cmd)
+			if err != nil {
+				return err
+			}
+			newClient, err := c.GetClient()
+			if err != nil {
+				return err
+			}
+			ctx := context.Background()
+			status, err := common.GetRolloutStatus(ctx, newClient, args[0], velaEnv.Namespace)
+			if err != nil {
+				return err
+			}
+			printRolloutStatus(status, ioStreams)
+			batch, err := common.ApproveNextBatch(ctx, newClient, args[0], velaEnv.Namespace, force)
+			if err != nil {
+				return err
+			}
+			ioStreams.Infof("batch %d of rollout %s is approved %s\n", batch, args[0], emojiSucceed)
+			return nil
+		},
+	}
+	cmd.Flags().BoolVarP(&force, "force", "f", false, "approve the next batch even if the current batch is not ready")
+	cmd.SetOut(ioStreams.Out)
+	return cmd
+}
+
+func printRolloutStatus(status *apis.RolloutStatus, ioStreams cmdutil.IOStreams) {
+	ioStreams.Infof("Rollout:     %s\n", status.Name)
+	if status.SourceRevision != "" {
+		ioStreams.Infof("Source:      %s\n", status.SourceRevision)
+	}
+	ioStreams.Infof("Target:      %s\n", status.TargetRevision)
+	ioStreams.Infof("State:       %s\n", status.RollingState)
+	partition := "all"
+	if status.BatchPartition != nil {
+		partition = strconv.Itoa(int(*status.BatchPartition))
+	}
+	ioStreams.Infof("Partition:   %s\n", partition)
+	ioStreams.Infof("Paused:      %t\n", status.Paused)
+	ioStreams.Infof("Replicas:    %d upgraded, %d ready, %d in total\n", status.Upgraded, status.Ready, status.TargetSize)
+	ioStreams.Infof("Batch Ready: %t\n", status.BatchReady)
+	if status.WaitingForWindow != "" {
+		ioStreams.Infof("Window:      %s\n", status.WaitingForWindow)
+	}
+	if len(status.Batches) == 0 {
+		return
+	}
+	table := newUITable()
+	table.AddRow("BATCH", "REPLICAS", "APPROVAL", "STATE", "APPROVER")
+	for _, batch := range status.Batches {
+		approval := ""
+		if batch.RequireApproval {
+			approval = "required"
+		}
+		table.AddRow(batch.Index, batch.Replicas, approval, batch.State, batch.Approver)
+	}
+	ioStreams.Info(table.String())
+}
@@ -20,6 +20,7 @@ import (
 	"github.com/spf13/cobra"
 
 	"github.com/oam-dev/kubevela/apis/types"
+	"github.com/oam-dev/kubevela/pkg/appfile/config"
 	common2 "github.com/oam-dev/kubevela/pkg/utils/common"
 	cmdutil "github.com/oam-dev/kubevela/pkg/utils/util"
 	"github.com/oam-dev/kubevela/references/common"
@@ -52,20 +53,45 @@ func NewUpCommand(c common2.Args, ioStream cmdutil.IOStreams) *cobra.Command {
 				return err
 			}
 
+			storeType, err := cmd.Flags().GetString("config-store")
+			if err != nil {
+				return err
+			}
+			keyFile, err := cmd.Flags().GetString(encryptionKeyFileFlag)
+			if err != nil {
+				return err
+			}
+			configStore, err := newConfigStore(c, storeType, keyFile)
+			if err != nil {
+				return err
+			}
+			if secretStore, ok := configStore.(*config.Secret); ok {
+				if secretStore.AllowPlaintextExport, err = cmd.Flags().GetBool(allowPlaintextConfigFlag); err != nil {
+					return err
+				}
+			}
 			o := &common.AppfileOptions{
-				Kubecli: kubecli,
-				IO:      ioStream,
-				Env:     velaEnv,
+				Kubecli:     kubecli,
+				IO:          ioStream,
+				Env:         velaEnv,
+				ConfigStore: configStore,
 			}
 			filePath, err := cmd.Flags().GetString(appFilePath)
 			if err != nil {
 				return err
 			}
+			if o.Profile, err = cmd.Flags().GetString("profile"); err != nil {
+				return err
+			}
 			return o.Run(filePath, velaEnv.Namespace, c)
 		},
 	}
 	cmd.SetOut(ioStream.Out)
 
 	cmd.Flags().StringP(appFilePath, "f", "", "specify file path for appfile")
+	cmd.Flags().String("profile", "", "the profile to patch the appfile with, the profile named after current env is used by default")
+	cmd.Flags().String("config-store", config.TypeLocal, "the store that user configs of services are read from, one of local, configmap and secret")
+	cmd.Flags().String(encryptionKeyFileFlag, "", "the file of AES key to decrypt the configs in secret store")
+	cmd.Flags().Bool(allowPlaintextConfigFlag, false, "allow the configs encrypted in secret store to be stored in plaintext Secrets read by the application")
 	return cmd
 }
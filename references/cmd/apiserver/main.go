@@ -18,6 +18,7 @@ package main
 
 import (
 	"context"
+	"flag"
 	"io"
 	"os"
 	"os/signal"
@@ -29,12 +30,25 @@ import (
 
 	"github.com/oam-dev/kubevela/pkg/utils/common"
 	"github.com/oam-dev/kubevela/references/apiserver"
+	"github.com/oam-dev/kubevela/references/apiserver/auth"
 	"github.com/oam-dev/kubevela/references/apiserver/util"
 )
 
 // main will only start up API server
 func main() {
 	var development = true
+	var authOptions auth.Options
+	flag.StringVar(&authOptions.TokenAuthFile, "token-auth-file", "", "CSV file of static bearer tokens, each line is token,user,uid,\"group1,group2\"")
+	flag.StringVar(&authOptions.OIDCIssuer, "oidc-issuer", "", "the issuer of OIDC ID tokens")
+	flag.StringVar(&authOptions.OIDCClientID, "oidc-client-id", "", "the client id that OIDC ID tokens must be issued for")
+	flag.StringVar(&authOptions.OIDCJWKSFile, "oidc-jwks-file", "", "the JSON Web Key Set file to verify OIDC ID tokens")
+	flag.StringVar(&authOptions.OIDCUsernameClaim, "oidc-username-claim", "sub", "the claim of OIDC ID tokens used as user name")
+	flag.StringVar(&authOptions.OIDCGroupsClaim, "oidc-groups-claim", "", "the claim of OIDC ID tokens used as user groups")
+	flag.StringVar(&authOptions.OIDCUsernamePrefix, "oidc-username-prefix", auth.DefaultOIDCPrefix, "the prefix of user names of OIDC ID tokens, '-' disables the prefix")
+	flag.StringVar(&authOptions.OIDCGroupsPrefix, "oidc-groups-prefix", auth.DefaultOIDCPrefix, "the prefix of groups of OIDC ID tokens, '-' disables the prefix")
+	flag.BoolVar(&authOptions.TokenReview, "authentication-token-review", false, "authenticate bearer tokens by the TokenReview API of Kubernetes")
+	flag.StringVar(&authOptions.PolicyFile, "authorization-policy-file", "", "the policy file mapping users and groups to roles in envs and namespaces")
+	flag.Parse()
 	// setup logging
 	var w io.Writer = os.Stdout
 
@@ -48,7 +62,7 @@ func main() {
 		ctrl.Log.Error(err, "failed to init Kubernetes Config")
 		os.Exit(1)
 	}
-	apiServer, err := apiserver.New(c, util.DefaultAPIServerPort, "")
+	apiServer, err := apiserver.New(c, util.DefaultAPIServerPort, "", authOptions)
 	if err != nil {
 		ctrl.Log.Error(err, "failed to init dashboard server")
 		os.Exit(1)
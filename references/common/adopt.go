@@ -0,0 +1,526 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package common
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv1 "k8s.io/api/autoscaling/v1"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1beta1 "k8s.io/api/networking/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	corev1beta1 "github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
+	"github.com/oam-dev/kubevela/apis/types"
+	"github.com/oam-dev/kubevela/pkg/oam"
+	"github.com/oam-dev/kubevela/references/plugins"
+)
+
+const (
+	// LabelHelmManagedBy is the label set by Helm on the resources of its releases
+	LabelHelmManagedBy = "app.kubernetes.io/managed-by"
+	// AnnotationHelmReleaseName is the annotation set by Helm with the name of the release a resource belongs to
+	AnnotationHelmReleaseName = "meta.helm.sh/release-name"
+)
+
+// AdoptOptions selects the existing resources to adopt into an application
+type AdoptOptions struct {
+	// AppName is the name of the generated application. It's the Helm release or the first workload by default.
+	AppName   string
+	Namespace string
+	// Selector selects the resources by labels
+	Selector labels.Selector
+	// HelmRelease selects the resources installed by a Helm release
+	HelmRelease string
+}
+
+// adoptableWorkload is the pod template based workload to adopt as a component
+type adoptableWorkload struct {
+	apiVersion string
+	kind       string
+	name       string
+	replicas   *int32
+	template   corev1.PodTemplateSpec
+}
+
+// adoptableResources are the existing resources to adopt
+type adoptableResources struct {
+	workloads []adoptableWorkload
+	services  []corev1.Service
+	ingresses []networkingv1beta1.Ingress
+	hpas      []autoscalingv1.HorizontalPodAutoscaler
+}
+
+// candidate is a component or trait definition that could be matched with the existing resources
+type candidate struct {
+	capability types.Capability
+	apiVersion string
+	kind       string
+	appliesTo  []string
+}
+
+// AdoptResources generates an application from the existing resources selected by labels or a Helm release.
+// Each Deployment, StatefulSet or DaemonSet becomes a component of the ComponentDefinition whose workload and
+// parameters match it best. The HorizontalPodAutoscalers, Ingresses and replicas of the workloads are turned into
+// traits of the TraitDefinitions whose parameters match them best. The application adopts the existing resources,
+// so they are taken over by the application instead of being recreated. The resources or fields that cannot be
+// adopted are returned as warnings.
+func AdoptResources(ctx context.Context, c client.Reader, opts AdoptOptions) (*corev1beta1.Application, []string, error) {
+	if opts.Selector == nil && opts.HelmRelease == "" {
+		return nil, nil, errors.New("either a label selector or a Helm release must be specified")
+	}
+	resources, err := listAdoptableResources(ctx, c, opts)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(resources.workloads) == 0 {
+		return nil, nil, errors.New("no Deployment, StatefulSet or DaemonSet is found to adopt")
+	}
+	components, traits, err := listAdoptingDefinitions(ctx, c, opts.Namespace)
+	if err != nil {
+		return nil, nil, err
+	}
+	app, warnings := generateAdoptingApplication(opts, resources, components, traits)
+	return app, warnings, nil
+}
+
+func listAdoptableResources(ctx context.Context, c client.Reader, opts AdoptOptions) (*adoptableResources, error) {
+	selector := labels.Everything()
+	if opts.Selector != nil {
+		selector = opts.Selector
+	}
+	if opts.HelmRelease != "" {
+		requirement, err := labels.NewRequirement(LabelHelmManagedBy, "=", []string{"Helm"})
+		if err != nil {
+			return nil, err
+		}
+		selector = selector.Add(*requirement)
+	}
+	listOpts := &client.ListOptions{Namespace: opts.Namespace, LabelSelector: selector}
+	selected := func(o metav1.Object) bool {
+		return opts.HelmRelease == "" || o.GetAnnotations()[AnnotationHelmReleaseName] == opts.HelmRelease
+	}
+
+	resources := &adoptableResources{}
+	var deployments appsv1.DeploymentList
+	if err := c.List(ctx, &deployments, listOpts); err != nil {
+		return nil, errors.Wrap(err, "cannot list deployments")
+	}
+	for i := range deployments.Items {
+		d := deployments.Items[i]
+		if selected(&d) {
+			resources.workloads = append(resources.workloads, adoptableWorkload{apiVersion: "apps/v1",
+				kind: "Deployment", name: d.Name, replicas: d.Spec.Replicas, template: d.Spec.Template})
+		}
+	}
+	var statefulSets appsv1.StatefulSetList
+	if err := c.List(ctx, &statefulSets, listOpts); err != nil {
+		return nil, errors.Wrap(err, "cannot list statefulsets")
+	}
+	for i := range statefulSets.Items {
+		s := statefulSets.Items[i]
+		if selected(&s) {
+			resources.workloads = append(resources.workloads, adoptableWorkload{apiVersion: "apps/v1",
+				kind: "StatefulSet", name: s.Name, replicas: s.Spec.Replicas, template: s.Spec.Template})
+		}
+	}
+	var daemonSets appsv1.DaemonSetList
+	if err := c.List(ctx, &daemonSets, listOpts); err != nil {
+		return nil, errors.Wrap(err, "cannot list daemonsets")
+	}
+	for i := range daemonSets.Items {
+		d := daemonSets.Items[i]
+		if selected(&d) {
+			resources.workloads = append(resources.workloads, adoptableWorkload{apiVersion: "apps/v1",
+				kind: "DaemonSet", name: d.Name, template: d.Spec.Template})
+		}
+	}
+	var services corev1.ServiceList
+	if err := c.List(ctx, &services, listOpts); err != nil {
+		return nil, errors.Wrap(err, "cannot list services")
+	}
+	for _, s := range services.Items {
+		if selected(&s) {
+			resources.services = append(resources.services, s)
+		}
+	}
+	var ingresses networkingv1beta1.IngressList
+	if err := c.List(ctx, &ingresses, listOpts); err != nil {
+		return nil, errors.Wrap(err, "cannot list ingresses")
+	}
+	for _, i := range ingresses.Items {
+		if selected(&i) {
+			resources.ingresses = append(resources.ingresses, i)
+		}
+	}
+	var hpas autoscalingv1.HorizontalPodAutoscalerList
+	if err := c.List(ctx, &hpas, listOpts); err != nil {
+		return nil, errors.Wrap(err, "cannot list horizontal pod autoscalers")
+	}
+	for _, h := range hpas.Items {
+		if selected(&h) {
+			resources.hpas = append(resources.hpas, h)
+		}
+	}
+	return resources, nil
+}
+
+// listAdoptingDefinitions lists the component and trait definitions in the namespace and the system namespace,
+// definitions in the namespace take precedence over the ones with the same names in the system namespace.
+func listAdoptingDefinitions(ctx context.Context, c client.Reader, namespace string) ([]candidate, []candidate, error) {
+	componentDefs := map[string]corev1beta1.ComponentDefinition{}
+	traitDefs := map[string]corev1beta1.TraitDefinition{}
+	for _, ns := range []string{types.DefaultKubeVelaNS, namespace} {
+		var cds corev1beta1.ComponentDefinitionList
+		if err := c.List(ctx, &cds, client.InNamespace(ns)); err != nil {
+			return nil, nil, errors.Wrap(err, "cannot list component definitions")
+		}
+		for _, cd := range cds.Items {
+			componentDefs[cd.Name] = cd
+		}
+		var tds corev1beta1.TraitDefinitionList
+		if err := c.List(ctx, &tds, client.InNamespace(ns)); err != nil {
+			return nil, nil, errors.Wrap(err, "cannot list trait definitions")
+		}
+		for _, td := range tds.Items {
+			traitDefs[td.Name] = td
+		}
+	}
+
+	var components, traits []candidate
+	for _, cd := range componentDefs {
+		if cd.Spec.Schematic == nil || cd.Spec.Schematic.CUE == nil {
+			continue
+		}
+		capability, err := plugins.GetCapabilityByComponentDefinitionObject(cd, "")
+		if err != nil {
+			continue
+		}
+		components = append(components, candidate{capability: *capability,
+			apiVersion: cd.Spec.Workload.Definition.APIVersion, kind: cd.Spec.Workload.Definition.Kind})
+	}
+	for _, td := range traitDefs {
+		if td.Spec.Schematic == nil || td.Spec.Schematic.CUE == nil {
+			continue
+		}
+		capability, err := plugins.GetCapabilityByTraitDefinitionObject(td)
+		if err != nil {
+			continue
+		}
+		traits = append(traits, candidate{capability: *capability, appliesTo: td.Spec.AppliesToWorkloads})
+	}
+	sortCandidates(components)
+	sortCandidates(traits)
+	return components, traits, nil
+}
+
+func sortCandidates(candidates []candidate) {
+	sort.Slice(candidates, func(i, j int) bool {
+		return candidates[i].capability.Name < candidates[j].capability.Name
+	})
+}
+
+func generateAdoptingApplication(opts AdoptOptions, resources *adoptableResources, components, traits []candidate) (*corev1beta1.Application, []string) {
+	name := opts.AppName
+	if name == "" {
+		name = opts.HelmRelease
+	}
+	if name == "" {
+		name = resources.workloads[0].name
+	}
+	app := &corev1beta1.Application{
+		TypeMeta: metav1.TypeMeta{APIVersion: corev1beta1.SchemeGroupVersion.String(), Kind: corev1beta1.ApplicationKind},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        name,
+			Namespace:   opts.Namespace,
+			Annotations: map[string]string{oam.AnnotationAdoptResources: "true"},
+		},
+	}
+
+	var warnings []string
+	adoptedServices := map[string]bool{}
+	adoptedIngresses := map[string]bool{}
+	adoptedHPAs := map[string]bool{}
+	for _, w := range resources.workloads {
+		var workloadCandidates []candidate
+		for _, cd := range components {
+			if cd.apiVersion == w.apiVersion && cd.kind == w.kind {
+				workloadCandidates = append(workloadCandidates, cd)
+			}
+		}
+		pool, poolWarnings := workloadProperties(w, resources.services)
+		warnings = append(warnings, poolWarnings...)
+		def, properties := bestMatch(pool, workloadCandidates)
+		if def == nil {
+			warnings = append(warnings, fmt.Sprintf("%s %s is not adopted, no component definition matches it", w.kind, w.name))
+			continue
+		}
+		comp := corev1beta1.ApplicationComponent{Name: w.name, Type: def.capability.Name, Properties: rawProperties(properties)}
+
+		var traitPools []traitPool
+		hpa := findHPA(w, resources.hpas)
+		if hpa != nil {
+			traitPools = append(traitPools, traitPool{resource: "HorizontalPodAutoscaler " + hpa.Name, properties: hpaProperties(hpa),
+				adopted: func() { adoptedHPAs[hpa.Name] = true }})
+		} else if _, ok := properties["replicas"]; !ok && w.replicas != nil {
+			traitPools = append(traitPools, traitPool{resource: fmt.Sprintf("replicas of %s %s", w.kind, w.name),
+				properties: map[string]interface{}{"replicas": *w.replicas}, adopted: func() {}})
+		}
+		for _, svc := range selectingServices(w, resources.services) {
+			adoptedServices[svc.Name] = true
+			for i := range resources.ingresses {
+				ing := resources.ingresses[i]
+				if pool := ingressProperties(&ing, svc.Name); pool != nil {
+					traitPools = append(traitPools, traitPool{resource: "Ingress " + ing.Name, properties: pool,
+						adopted: func() { adoptedIngresses[ing.Name] = true }})
+				}
+			}
+		}
+		for _, tp := range traitPools {
+			var traitCandidates []candidate
+			for _, td := range traits {
+				if appliesTo(td, def.capability.Name, w.kind) {
+					traitCandidates = append(traitCandidates, td)
+				}
+			}
+			td, traitProperties := bestMatch(tp.properties, traitCandidates)
+			if td == nil {
+				warnings = append(warnings, fmt.Sprintf("%s is not adopted, no trait definition matches it", tp.resource))
+				continue
+			}
+			tp.adopted()
+			comp.Traits = append(comp.Traits, corev1beta1.ApplicationTrait{Type: td.capability.Name,
+				Properties: rawProperties(traitProperties)})
+		}
+		app.Spec.Components = append(app.Spec.Components, comp)
+	}
+
+	for _, h := range resources.hpas {
+		if !adoptedHPAs[h.Name] {
+			warnings = append(warnings, fmt.Sprintf("HorizontalPodAutoscaler %s is not adopted", h.Name))
+		} else {
+			warnings = append(warnings, fmt.Sprintf("HorizontalPodAutoscaler %s is replaced by a trait, delete it once the application is running", h.Name))
+		}
+	}
+	for _, i := range resources.ingresses {
+		if !adoptedIngresses[i.Name] {
+			warnings = append(warnings, fmt.Sprintf("Ingress %s is not adopted", i.Name))
+		} else {
+			warnings = append(warnings, fmt.Sprintf("Ingress %s is replaced by a trait, delete it once the application is running", i.Name))
+		}
+	}
+	for _, s := range resources.services {
+		if !adoptedServices[s.Name] {
+			warnings = append(warnings, fmt.Sprintf("Service %s is not adopted, it selects none of the workloads", s.Name))
+		}
+	}
+	return app, warnings
+}
+
+// traitPool is the properties of a trait found from an existing resource
+type traitPool struct {
+	resource   string
+	properties map[string]interface{}
+	adopted    func()
+}
+
+// workloadProperties collects the properties a component could have from a workload, keyed by the common
+// parameter names. Only the first container of the workload is adopted.
+func workloadProperties(w adoptableWorkload, services []corev1.Service) (map[string]interface{}, []string) {
+	var warnings []string
+	pool := map[string]interface{}{}
+	if w.replicas != nil {
+		pool["replicas"] = *w.replicas
+	}
+	containers := w.template.Spec.Containers
+	if len(containers) == 0 {
+		return pool, warnings
+	}
+	if len(containers) > 1 {
+		warnings = append(warnings, fmt.Sprintf("%s %s has %d containers, only the first one is adopted",
+			w.kind, w.name, len(containers)))
+	}
+	container := containers[0]
+	pool["image"] = container.Image
+	if len(container.Command) != 0 {
+		pool["cmd"] = container.Command
+		pool["command"] = container.Command
+	}
+	if len(container.Args) != 0 {
+		pool["args"] = container.Args
+	}
+	var env []map[string]interface{}
+	for _, e := range container.Env {
+		if e.ValueFrom != nil {
+			warnings = append(warnings, fmt.Sprintf("env %s of %s %s is not adopted, it's not a plain value", e.Name, w.kind, w.name))
+			continue
+		}
+		env = append(env, map[string]interface{}{"name": e.Name, "value": e.Value})
+	}
+	if len(env) != 0 {
+		pool["env"] = env
+	}
+	if len(container.Ports) != 0 {
+		pool["port"] = container.Ports[0].ContainerPort
+	} else {
+		for _, svc := range selectingServices(w, services) {
+			if len(svc.Spec.Ports) != 0 && svc.Spec.Ports[0].TargetPort.IntVal != 0 {
+				pool["port"] = svc.Spec.Ports[0].TargetPort.IntVal
+				break
+			}
+		}
+	}
+	if cpu, ok := container.Resources.Limits[corev1.ResourceCPU]; ok {
+		pool["cpu"] = cpu.String()
+	}
+	if memory, ok := container.Resources.Limits[corev1.ResourceMemory]; ok {
+		pool["memory"] = memory.String()
+	}
+	return pool, warnings
+}
+
+// hpaProperties collects the properties a trait could have from a HorizontalPodAutoscaler
+func hpaProperties(hpa *autoscalingv1.HorizontalPodAutoscaler) map[string]interface{} {
+	minReplicas := int32(1)
+	if hpa.Spec.MinReplicas != nil {
+		minReplicas = *hpa.Spec.MinReplicas
+	}
+	pool := map[string]interface{}{
+		"min": minReplicas, "minReplicas": minReplicas,
+		"max": hpa.Spec.MaxReplicas, "maxReplicas": hpa.Spec.MaxReplicas,
+	}
+	if util := hpa.Spec.TargetCPUUtilizationPercentage; util != nil {
+		pool["cpuUtil"] = *util
+		pool["cpuPercent"] = *util
+		pool["targetCPUUtilizationPercentage"] = *util
+	}
+	return pool
+}
+
+// ingressProperties collects the properties a trait could have from the rules of an Ingress routing to a service,
+// it returns nil if the Ingress doesn't route to the service
+func ingressProperties(ing *networkingv1beta1.Ingress, service string) map[string]interface{} {
+	var host string
+	http := map[string]interface{}{}
+	for _, rule := range ing.Spec.Rules {
+		if rule.HTTP == nil {
+			continue
+		}
+		for _, path := range rule.HTTP.Paths {
+			if path.Backend.ServiceName != service || path.Backend.ServicePort.IntVal == 0 ||
+				(host != "" && rule.Host != host) {
+				continue
+			}
+			host = rule.Host
+			p := path.Path
+			if p == "" {
+				p = "/"
+			}
+			http[p] = path.Backend.ServicePort.IntVal
+		}
+	}
+	if len(http) == 0 {
+		return nil
+	}
+	return map[string]interface{}{"domain": host, "host": host, "http": http}
+}
+
+// findHPA finds the HorizontalPodAutoscaler scaling a workload
+func findHPA(w adoptableWorkload, hpas []autoscalingv1.HorizontalPodAutoscaler) *autoscalingv1.HorizontalPodAutoscaler {
+	for i := range hpas {
+		ref := hpas[i].Spec.ScaleTargetRef
+		if ref.Kind == w.kind && ref.Name == w.name {
+			return &hpas[i]
+		}
+	}
+	return nil
+}
+
+// selectingServices finds the services selecting the pods of a workload
+func selectingServices(w adoptableWorkload, services []corev1.Service) []corev1.Service {
+	var selecting []corev1.Service
+	for _, svc := range services {
+		if len(svc.Spec.Selector) != 0 &&
+			labels.SelectorFromSet(svc.Spec.Selector).Matches(labels.Set(w.template.Labels)) {
+			selecting = append(selecting, svc)
+		}
+	}
+	return selecting
+}
+
+// appliesTo checks if a trait definition applies to the component definition or the kind of workload
+func appliesTo(trait candidate, componentType, kind string) bool {
+	if len(trait.appliesTo) == 0 {
+		return true
+	}
+	for _, a := range trait.appliesTo {
+		if a == "*" || a == componentType || strings.HasPrefix(a, strings.ToLower(kind)+"s.") {
+			return true
+		}
+	}
+	return false
+}
+
+// bestMatch finds the definition whose parameters match the properties best. A definition matches if all of its
+// required parameters are found in the properties. The one with the most matched parameters wins, and the one with
+// fewer unmatched parameters wins a tie, so that the more specific definition is preferred.
+func bestMatch(pool map[string]interface{}, candidates []candidate) (*candidate, map[string]interface{}) {
+	var best *candidate
+	var bestProperties map[string]interface{}
+	var bestUnmatched int
+	for i := range candidates {
+		properties, unmatched, ok := matchParameters(pool, candidates[i].capability.Parameters)
+		if !ok || len(properties) == 0 {
+			continue
+		}
+		if best == nil || len(properties) > len(bestProperties) ||
+			(len(properties) == len(bestProperties) && unmatched < bestUnmatched) {
+			best, bestProperties, bestUnmatched = &candidates[i], properties, unmatched
+		}
+	}
+	return best, bestProperties
+}
+
+func matchParameters(pool map[string]interface{}, parameters []types.Parameter) (map[string]interface{}, int, bool) {
+	properties := map[string]interface{}{}
+	var unmatched int
+	for _, p := range parameters {
+		if v, ok := pool[p.Name]; ok {
+			properties[p.Name] = v
+			continue
+		}
+		if p.Required {
+			return nil, 0, false
+		}
+		unmatched++
+	}
+	return properties, unmatched, true
+}
+
+func rawProperties(properties map[string]interface{}) runtime.RawExtension {
+	raw, _ := json.Marshal(properties)
+	return runtime.RawExtension{Raw: raw}
+}
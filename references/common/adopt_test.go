@@ -0,0 +1,166 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package common
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"gotest.tools/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv1 "k8s.io/api/autoscaling/v1"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1beta1 "k8s.io/api/networking/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/yaml"
+
+	corev1beta1 "github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
+	"github.com/oam-dev/kubevela/apis/types"
+	"github.com/oam-dev/kubevela/pkg/oam"
+)
+
+// loadBuiltinDefinition loads a built-in definition from the chart into the system namespace
+func loadBuiltinDefinition(t *testing.T, name string, obj runtime.Object) runtime.Object {
+	data, err := ioutil.ReadFile(filepath.Join("../../charts/vela-core/templates/defwithtemplate", name+".yaml"))
+	assert.NilError(t, err)
+	data = []byte(strings.ReplaceAll(string(data), "{{.Values.systemDefinitionNamespace}}", types.DefaultKubeVelaNS))
+	assert.NilError(t, yaml.Unmarshal(data, obj))
+	return obj
+}
+
+func TestAdoptResources(t *testing.T) {
+	scheme := runtime.NewScheme()
+	assert.NilError(t, corev1beta1.SchemeBuilder.AddToScheme(scheme))
+	assert.NilError(t, clientgoscheme.AddToScheme(scheme))
+	helm := func(name string) metav1.ObjectMeta {
+		return metav1.ObjectMeta{Name: name, Namespace: "default",
+			Labels:      map[string]string{LabelHelmManagedBy: "Helm", "app": "shop"},
+			Annotations: map[string]string{AnnotationHelmReleaseName: "shop"}}
+	}
+	podTemplate := func(app string, container corev1.Container) corev1.PodTemplateSpec {
+		return corev1.PodTemplateSpec{
+			ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"app": app}},
+			Spec:       corev1.PodSpec{Containers: []corev1.Container{container}},
+		}
+	}
+	frontend := &appsv1.Deployment{ObjectMeta: helm("frontend"), Spec: appsv1.DeploymentSpec{
+		Replicas: pointer.Int32Ptr(2),
+		Template: podTemplate("frontend", corev1.Container{
+			Name:  "frontend",
+			Image: "shop/frontend:v1",
+			Env: []corev1.EnvVar{{Name: "MODE", Value: "prod"},
+				{Name: "TOKEN", ValueFrom: &corev1.EnvVarSource{SecretKeyRef: &corev1.SecretKeySelector{Key: "token"}}}},
+		}),
+	}}
+	worker := &appsv1.Deployment{ObjectMeta: helm("worker"), Spec: appsv1.DeploymentSpec{
+		Replicas: pointer.Int32Ptr(1),
+		Template: podTemplate("worker", corev1.Container{Name: "worker", Image: "shop/worker:v1", Command: []string{"work"}}),
+	}}
+	service := &corev1.Service{ObjectMeta: helm("frontend"), Spec: corev1.ServiceSpec{
+		Selector: map[string]string{"app": "frontend"},
+		Ports:    []corev1.ServicePort{{Port: 80, TargetPort: intstr.FromInt(8080)}},
+	}}
+	ingress := &networkingv1beta1.Ingress{ObjectMeta: helm("frontend"), Spec: networkingv1beta1.IngressSpec{
+		Rules: []networkingv1beta1.IngressRule{{Host: "shop.example.com", IngressRuleValue: networkingv1beta1.IngressRuleValue{
+			HTTP: &networkingv1beta1.HTTPIngressRuleValue{Paths: []networkingv1beta1.HTTPIngressPath{{
+				Path:    "/",
+				Backend: networkingv1beta1.IngressBackend{ServiceName: "frontend", ServicePort: intstr.FromInt(80)},
+			}}},
+		}}},
+	}}
+	hpa := &autoscalingv1.HorizontalPodAutoscaler{ObjectMeta: helm("frontend"), Spec: autoscalingv1.HorizontalPodAutoscalerSpec{
+		ScaleTargetRef:                 autoscalingv1.CrossVersionObjectReference{Kind: "Deployment", Name: "frontend"},
+		MinReplicas:                    pointer.Int32Ptr(2),
+		MaxReplicas:                    5,
+		TargetCPUUtilizationPercentage: pointer.Int32Ptr(60),
+	}}
+	other := &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "other", Namespace: "default"},
+		Spec: appsv1.DeploymentSpec{Template: podTemplate("other", corev1.Container{Name: "other", Image: "other"})}}
+
+	c := fake.NewFakeClientWithScheme(scheme, frontend, worker, service, ingress, hpa, other,
+		loadBuiltinDefinition(t, "webservice", &corev1beta1.ComponentDefinition{}),
+		loadBuiltinDefinition(t, "worker", &corev1beta1.ComponentDefinition{}),
+		loadBuiltinDefinition(t, "scaler", &corev1beta1.TraitDefinition{}),
+		loadBuiltinDefinition(t, "cpuscaler", &corev1beta1.TraitDefinition{}),
+		loadBuiltinDefinition(t, "ingress", &corev1beta1.TraitDefinition{}),
+		loadBuiltinDefinition(t, "labels", &corev1beta1.TraitDefinition{}))
+	ctx := context.Background()
+
+	_, _, err := AdoptResources(ctx, c, AdoptOptions{Namespace: "default"})
+	assert.ErrorContains(t, err, "must be specified")
+
+	app, warnings, err := AdoptResources(ctx, c, AdoptOptions{Namespace: "default", HelmRelease: "shop"})
+	assert.NilError(t, err)
+	assert.Equal(t, app.Name, "shop")
+	assert.Equal(t, app.Annotations[oam.AnnotationAdoptResources], "true")
+	assert.Equal(t, len(app.Spec.Components), 2)
+
+	properties := func(raw runtime.RawExtension) map[string]interface{} {
+		var p map[string]interface{}
+		assert.NilError(t, json.Unmarshal(raw.Raw, &p))
+		return p
+	}
+	comp := app.Spec.Components[0]
+	assert.Equal(t, comp.Name, "frontend")
+	assert.Equal(t, comp.Type, "webservice")
+	assert.DeepEqual(t, properties(comp.Properties), map[string]interface{}{
+		"image": "shop/frontend:v1",
+		"port":  float64(8080),
+		"env":   []interface{}{map[string]interface{}{"name": "MODE", "value": "prod"}},
+	})
+	assert.Equal(t, len(comp.Traits), 2)
+	assert.Equal(t, comp.Traits[0].Type, "cpuscaler")
+	assert.DeepEqual(t, properties(comp.Traits[0].Properties),
+		map[string]interface{}{"min": float64(2), "max": float64(5), "cpuUtil": float64(60)})
+	assert.Equal(t, comp.Traits[1].Type, "ingress")
+	assert.DeepEqual(t, properties(comp.Traits[1].Properties), map[string]interface{}{
+		"domain": "shop.example.com",
+		"http":   map[string]interface{}{"/": float64(80)},
+	})
+
+	comp = app.Spec.Components[1]
+	assert.Equal(t, comp.Name, "worker")
+	assert.Equal(t, comp.Type, "worker")
+	assert.DeepEqual(t, properties(comp.Properties),
+		map[string]interface{}{"image": "shop/worker:v1", "cmd": []interface{}{"work"}})
+	assert.Equal(t, len(comp.Traits), 1)
+	assert.Equal(t, comp.Traits[0].Type, "scaler")
+	assert.DeepEqual(t, properties(comp.Traits[0].Properties), map[string]interface{}{"replicas": float64(1)})
+
+	assert.DeepEqual(t, warnings, []string{
+		"env TOKEN of Deployment frontend is not adopted, it's not a plain value",
+		"HorizontalPodAutoscaler frontend is replaced by a trait, delete it once the application is running",
+		"Ingress frontend is replaced by a trait, delete it once the application is running",
+	})
+
+	selector, err := labels.Parse("app=shop")
+	assert.NilError(t, err)
+	app, _, err = AdoptResources(ctx, c, AdoptOptions{Namespace: "default", Selector: selector, AppName: "myshop"})
+	assert.NilError(t, err)
+	assert.Equal(t, app.Name, "myshop")
+	assert.Equal(t, len(app.Spec.Components), 2)
+}
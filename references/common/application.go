@@ -40,6 +40,7 @@ import (
 	corev1alpha2 "github.com/oam-dev/kubevela/apis/core.oam.dev/v1alpha2"
 	corev1beta1 "github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
 	"github.com/oam-dev/kubevela/apis/types"
+	"github.com/oam-dev/kubevela/pkg/appfile/config"
 	"github.com/oam-dev/kubevela/pkg/oam"
 	oamutil "github.com/oam-dev/kubevela/pkg/oam/util"
 	"github.com/oam-dev/kubevela/pkg/utils/common"
@@ -66,6 +67,12 @@ type AppfileOptions struct {
 	Kubecli client.Client
 	IO      cmdutil.IOStreams
 	Env     *types.EnvMeta
+	// ConfigStore is the store that user configs are read from, the local store is used if it's nil
+	ConfigStore config.Store
+	// Templates are used to render the appfile instead of capabilities installed in cluster if it's set
+	Templates template.Manager
+	// Profile patches the appfile for an environment, the profile named after the env is used if it's empty
+	Profile string
 }
 
 // BuildResult is the export struct from AppFile yaml or AppFile object
@@ -75,6 +82,11 @@ type BuildResult struct {
 	scopes      []oam.Object
 }
 
+// GetApplication returns the Application built from the appfile
+func (r *BuildResult) GetApplication() *corev1beta1.Application {
+	return r.application
+}
+
 func (comps componentMetaList) Len() int {
 	return len(comps)
 }
@@ -366,11 +378,17 @@ func saveAndLoadRemoteAppfile(url string) (*api.AppFile, error) {
 
 // ExportFromAppFile exports Application from appfile object
 func (o *AppfileOptions) ExportFromAppFile(app *api.AppFile, namespace string, quiet bool, c common.Args) (*BuildResult, []byte, error) {
-	tm, err := template.Load(namespace, c)
-	if err != nil {
-		return nil, nil, err
+	tm := o.Templates
+	if tm == nil {
+		var err error
+		if tm, err = template.Load(namespace, c); err != nil {
+			return nil, nil, err
+		}
 	}
 
+	if app != nil && o.ConfigStore != nil {
+		app.SetConfigStore(o.ConfigStore)
+	}
 	appHandler := appfile.NewApplication(app, tm)
 
 	// new
@@ -413,14 +431,21 @@ func (o *AppfileOptions) Export(filePath, namespace string, quiet bool, c common
 	if !quiet {
 		o.IO.Info("Parsing vela appfile ...")
 	}
+	// the profile of current env is optional while the one specified explicitly must exist
+	profile, optional := o.Profile, false
+	if profile == "" && o.Env != nil {
+		profile, optional = o.Env.Name, true
+	}
 	if filePath != "" {
 		if strings.HasPrefix(filePath, "https://") || strings.HasPrefix(filePath, "http://") {
-			app, err = saveAndLoadRemoteAppfile(filePath)
+			if app, err = saveAndLoadRemoteAppfile(filePath); err == nil {
+				err = app.ApplyProfile(profile, optional)
+			}
 		} else {
-			app, err = api.LoadFromFile(filePath)
+			app, err = api.LoadFromFileWithProfile(filePath, profile, optional)
 		}
 	} else {
-		app, err = api.Load()
+		app, err = api.LoadWithProfile(profile, optional)
 	}
 	if err != nil {
 		return nil, nil, err
@@ -31,7 +31,9 @@ import (
 	corev1 "k8s.io/api/core/v1"
 
 	"github.com/ghodss/yaml"
+	crdv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -53,6 +55,12 @@ func AddCapabilityCenter(capName, capURL, capToken string) error {
 	if err != nil {
 		return err
 	}
+	if tp, _, err := plugins.Parse(capURL); err == nil && tp == plugins.TypeLocal && !strings.HasPrefix(capURL, "file://") {
+		// store the absolute path so that the center could be synced from any working directory
+		if capURL, err = filepath.Abs(capURL); err != nil {
+			return err
+		}
+	}
 	config := &plugins.CapCenterConfig{
 		Name:    capName,
 		Address: capURL,
@@ -84,8 +92,9 @@ func SyncCapabilityFromCenter(capName, capURL, capToken string) error {
 	return client.SyncCapabilityFromCenter()
 }
 
-// AddCapabilityIntoCluster will add a capability into K8s cluster, it is equal to apply a definition yaml and run `vela workloads/traits`
-func AddCapabilityIntoCluster(c client.Client, mapper discoverymapper.DiscoveryMapper, capability string) (string, error) {
+// AddCapabilityIntoCluster will add a capability into K8s cluster, it is equal to apply a definition yaml and run `vela workloads/traits`.
+// An existing definition is only overwritten if it's installed from the same cap center or force is set.
+func AddCapabilityIntoCluster(c client.Client, mapper discoverymapper.DiscoveryMapper, capability string, force bool) (string, error) {
 	ss := strings.Split(capability, "/")
 	if len(ss) < 2 {
 		return "", errors.New("invalid format for " + capability + ", please follow format <center>/<name>")
@@ -93,37 +102,126 @@ func AddCapabilityIntoCluster(c client.Client, mapper discoverymapper.DiscoveryM
 	repoName := ss[0]
 	name := ss[1]
 	ioStreams := cmdutil.IOStreams{In: os.Stdin, Out: os.Stdout, ErrOut: os.Stderr}
-	if err := InstallCapability(c, mapper, repoName, name, ioStreams); err != nil {
+	if err := InstallCapability(c, mapper, repoName, name, force, ioStreams); err != nil {
 		return "", err
 	}
 	return fmt.Sprintf("Successfully installed capability %s from %s", name, repoName), nil
 }
 
-// InstallCapability will add a cap into K8s cluster and install it's controller(helm charts)
-func InstallCapability(client client.Client, mapper discoverymapper.DiscoveryMapper, centerName, capabilityName string, ioStreams cmdutil.IOStreams) error {
+// InstallCapability will add a cap into K8s cluster and install it's controller(helm charts).
+// The capabilityName could be <name>@<version>, the version is an exact version or a constraint such as ">=1.0.0 <2.0.0",
+// the highest synced version is installed if it's omitted. Dependencies of the capability are installed first.
+// Definitions installed from other cap centers or by other means are not overwritten unless force is set.
+func InstallCapability(client client.Client, mapper discoverymapper.DiscoveryMapper, centerName, capabilityName string, force bool, ioStreams cmdutil.IOStreams) error {
+	name, version := SplitCapabilityVersion(capabilityName)
+	installer := &capabilityInstaller{
+		client:     client,
+		mapper:     mapper,
+		centerName: centerName,
+		force:      force,
+		ioStreams:  ioStreams,
+		installing: map[string]bool{},
+	}
+	return installer.install(context.Background(), name, version)
+}
+
+// SplitCapabilityVersion splits <name>@<version> into name and version
+func SplitCapabilityVersion(capabilityName string) (string, string) {
+	if i := strings.Index(capabilityName, "@"); i >= 0 {
+		return capabilityName[:i], capabilityName[i+1:]
+	}
+	return capabilityName, ""
+}
+
+// capabilityInstaller installs capabilities of a cap center with their dependencies
+type capabilityInstaller struct {
+	client     client.Client
+	mapper     discoverymapper.DiscoveryMapper
+	centerName string
+	// force overwrites definitions which are not installed from the cap center
+	force     bool
+	ioStreams cmdutil.IOStreams
+	// installing records capabilities being installed to detect circular dependencies
+	installing map[string]bool
+}
+
+func (i *capabilityInstaller) install(ctx context.Context, name, constraint string) error {
+	if i.installing[name] {
+		return fmt.Errorf("circular dependency detected on capability %s/%s", i.centerName, name)
+	}
+	i.installing[name] = true
+	defer delete(i.installing, name)
+
 	dir, _ := system.GetCapCenterDir()
-	repoDir := filepath.Join(dir, centerName)
-	tp, err := GetCapabilityFromCenter(mapper, centerName, capabilityName)
+	tp, defFile, err := plugins.FindCapabilityVersion(i.mapper, filepath.Join(dir, i.centerName), name, constraint)
 	if err != nil {
 		return err
 	}
-	tp.Source = &types.Source{RepoName: centerName}
+	// CRDs are checked after other dependencies as they are usually installed by a chart
+	for _, dep := range tp.Dependencies {
+		if dep.Type == types.DependencyCRD {
+			continue
+		}
+		if err := i.installDependency(ctx, dep); err != nil {
+			return fmt.Errorf("install dependency of %s err %w", name, err)
+		}
+	}
+	for _, dep := range tp.Dependencies {
+		if dep.Type != types.DependencyCRD {
+			continue
+		}
+		var crd crdv1.CustomResourceDefinition
+		if err := i.client.Get(ctx, client.ObjectKey{Name: dep.Name}, &crd); err != nil {
+			if apierrors.IsNotFound(err) {
+				return fmt.Errorf("CRD %s required by %s is not installed in the cluster", dep.Name, name)
+			}
+			return err
+		}
+	}
+	return i.installDefinition(ctx, tp, defFile)
+}
+
+func (i *capabilityInstaller) installDependency(ctx context.Context, dep types.Dependency) error {
+	switch dep.Type {
+	case types.DependencyDefinition:
+		installed, err := GetInstalledCapabilityPackage(ctx, i.client, dep.Name)
+		if err != nil {
+			return err
+		}
+		if installed != nil && installed.Version != "" && plugins.SatisfiesVersion(installed.Version, dep.Version) {
+			i.ioStreams.Infof("Dependency %s@%s is already installed\n", dep.Name, installed.Version)
+			return nil
+		}
+		return i.install(ctx, dep.Name, dep.Version)
+	case types.DependencyHelm:
+		return helm.InstallHelmChart(i.ioStreams, *dep.Helm)
+	case types.DependencyCRD:
+	}
+	return nil
+}
+
+func (i *capabilityInstaller) installDefinition(ctx context.Context, tp types.Capability, defFile string) error {
+	tp.Source = &types.Source{RepoName: i.centerName}
 	defDir, _ := system.GetCapabilityDir()
+	data, err := ioutil.ReadFile(filepath.Clean(defFile))
+	if err != nil {
+		return err
+	}
 	switch tp.Type {
 	case types.TypeComponentDefinition:
 		var cd v1beta1.ComponentDefinition
-		workloadData, err := ioutil.ReadFile(filepath.Clean(filepath.Join(repoDir, tp.Name+".yaml")))
-		if err != nil {
+		if err = yaml.Unmarshal(data, &cd); err != nil {
 			return err
 		}
-		if err = yaml.Unmarshal(workloadData, &cd); err != nil {
+		cd.Namespace = types.DefaultKubeVelaNS
+		if err = i.checkOverwrite(ctx, &cd, &v1beta1.ComponentDefinition{}); err != nil {
 			return err
 		}
-		cd.Namespace = types.DefaultKubeVelaNS
-		ioStreams.Info("Installing component capability " + cd.Name)
+		cd.Annotations = recordCapabilityPackage(cd.Annotations, i.centerName, tp.Version)
+		i.ioStreams.Info("Installing component capability " + cd.Name + versionSuffix(tp.Version))
 		if tp.Install != nil {
 			tp.Source.ChartName = tp.Install.Helm.Name
-			if err = helm.InstallHelmChart(ioStreams, tp.Install.Helm); err != nil {
+			if err = helm.InstallHelmChart(i.ioStreams, tp.Install.Helm); err != nil {
 				return err
 			}
 			err = addSourceIntoExtension(cd.Spec.Extension, tp.Source)
@@ -137,23 +235,23 @@ func InstallCapability(client client.Client, mapper discoverymapper.DiscoveryMap
 				Kind:       cd.Spec.Workload.Definition.Kind,
 			}
 		}
-		if err = client.Create(context.Background(), &cd); err != nil && !apierrors.IsAlreadyExists(err) {
+		if err = i.applyDefinition(ctx, &cd, &v1beta1.ComponentDefinition{}); err != nil {
 			return err
 		}
 	case types.TypeTrait:
 		var td v1beta1.TraitDefinition
-		traitdata, err := ioutil.ReadFile(filepath.Clean(filepath.Join(repoDir, tp.Name+".yaml")))
-		if err != nil {
+		if err = yaml.Unmarshal(data, &td); err != nil {
 			return err
 		}
-		if err = yaml.Unmarshal(traitdata, &td); err != nil {
+		td.Namespace = types.DefaultKubeVelaNS
+		if err = i.checkOverwrite(ctx, &td, &v1beta1.TraitDefinition{}); err != nil {
 			return err
 		}
-		td.Namespace = types.DefaultKubeVelaNS
-		ioStreams.Info("Installing trait capability " + td.Name)
+		td.Annotations = recordCapabilityPackage(td.Annotations, i.centerName, tp.Version)
+		i.ioStreams.Info("Installing trait capability " + td.Name + versionSuffix(tp.Version))
 		if tp.Install != nil {
 			tp.Source.ChartName = tp.Install.Helm.Name
-			if err = helm.InstallHelmChart(ioStreams, tp.Install.Helm); err != nil {
+			if err = helm.InstallHelmChart(i.ioStreams, tp.Install.Helm); err != nil {
 				return err
 			}
 			err = addSourceIntoExtension(td.Spec.Extension, tp.Source)
@@ -161,10 +259,10 @@ func InstallCapability(client client.Client, mapper discoverymapper.DiscoveryMap
 				return err
 			}
 		}
-		if err = HackForStandardTrait(tp, client); err != nil {
+		if err = HackForStandardTrait(tp, i.client); err != nil {
 			return err
 		}
-		gvk, err := util.GetGVKFromDefinition(mapper, td.Spec.Reference)
+		gvk, err := util.GetGVKFromDefinition(i.mapper, td.Spec.Reference)
 		if err != nil {
 			return err
 		}
@@ -172,7 +270,7 @@ func InstallCapability(client client.Client, mapper discoverymapper.DiscoveryMap
 			APIVersion: gvk.GroupVersion().String(),
 			Kind:       gvk.Kind,
 		}
-		if err = client.Create(context.Background(), &td); err != nil && !apierrors.IsAlreadyExists(err) {
+		if err = i.applyDefinition(ctx, &td, &v1beta1.TraitDefinition{}); err != nil {
 			return err
 		}
 	case types.TypeScope:
@@ -183,11 +281,96 @@ func InstallCapability(client client.Client, mapper discoverymapper.DiscoveryMap
 
 	success := plugins.SinkTemp2Local([]types.Capability{tp}, defDir)
 	if success == 1 {
-		ioStreams.Infof("Successfully installed capability %s from %s\n", capabilityName, centerName)
+		i.ioStreams.Infof("Successfully installed capability %s%s from %s\n", tp.Name, versionSuffix(tp.Version), i.centerName)
+	}
+	return nil
+}
+
+// recordCapabilityPackage records the cap center and version of an installed definition in its annotations,
+// so that everyone working on the cluster sees the same installed capabilities
+func recordCapabilityPackage(annotations map[string]string, centerName, version string) map[string]string {
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	annotations[types.AnnSourceCenter] = centerName
+	if version != "" {
+		annotations[types.AnnVersion] = version
+	}
+	return annotations
+}
+
+// checkOverwrite checks whether the definition could be overwritten before anything is installed for it,
+// existing is an empty object of the same type
+func (i *capabilityInstaller) checkOverwrite(ctx context.Context, obj, existing runtime.Object) error {
+	key, err := client.ObjectKeyFromObject(obj)
+	if err != nil {
+		return err
+	}
+	if err := i.client.Get(ctx, key, existing); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+	return i.canOverwrite(existing)
+}
+
+// canOverwrite only allows overwriting definitions installed from the same cap center unless force is set
+func (i *capabilityInstaller) canOverwrite(existing runtime.Object) error {
+	if i.force {
+		return nil
+	}
+	existingMeta, err := meta.Accessor(existing)
+	if err != nil {
+		return err
+	}
+	if center := existingMeta.GetAnnotations()[types.AnnSourceCenter]; center != i.centerName {
+		source := "not installed from any cap center"
+		if center != "" {
+			source = "installed from cap center " + center
+		}
+		return fmt.Errorf("definition %s already exists and is %s, use --force to overwrite it", existingMeta.GetName(), source)
 	}
 	return nil
 }
 
+// applyDefinition creates the definition or updates it if it already exists and could be overwritten,
+// existing is an empty object of the same type
+func (i *capabilityInstaller) applyDefinition(ctx context.Context, obj, existing runtime.Object) error {
+	err := i.client.Create(ctx, obj)
+	if err == nil || !apierrors.IsAlreadyExists(err) {
+		return err
+	}
+	key, err := client.ObjectKeyFromObject(obj)
+	if err != nil {
+		return err
+	}
+	if err := i.client.Get(ctx, key, existing); err != nil {
+		return err
+	}
+	// the update is rejected if the definition is changed after the check as the resource version is kept
+	if err := i.canOverwrite(existing); err != nil {
+		return err
+	}
+	objMeta, err := meta.Accessor(obj)
+	if err != nil {
+		return err
+	}
+	existingMeta, err := meta.Accessor(existing)
+	if err != nil {
+		return err
+	}
+	objMeta.SetResourceVersion(existingMeta.GetResourceVersion())
+	return i.client.Update(ctx, obj)
+}
+
+func versionSuffix(version string) string {
+	if version == "" {
+		return ""
+	}
+	return "@" + version
+}
+
 // HackForStandardTrait will do some hack install for standard capability
 func HackForStandardTrait(tp types.Capability, client client.Client) error {
 	switch tp.Name {
@@ -393,8 +576,23 @@ func listCenterCapabilities(userNamespace string, c common.Args, repoDir string)
 	}
 	baseDir := filepath.Base(repoDir)
 	workloads := gatherComponents(userNamespace, c, templates)
+	installed := map[string]CapabilityPackage{}
+	if k8sClient, err := c.GetClient(); err == nil {
+		packages, _ := ListInstalledCapabilityPackages(context.Background(), k8sClient)
+		for _, p := range packages {
+			if p.Center == baseDir {
+				installed[p.Name] = p
+			}
+		}
+	}
 	for i, p := range templates {
 		status := checkInstallStatus(userNamespace, c, baseDir, p)
+		if pkg, ok := installed[p.Name]; ok {
+			status = "installed"
+			if pkg.Version != "" {
+				status = "installed@" + pkg.Version
+			}
+		}
 		convertedApplyTo := ConvertApplyTo(p.AppliesTo, workloads)
 		templates[i].Center = baseDir
 		templates[i].Status = status
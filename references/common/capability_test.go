@@ -17,13 +17,28 @@ limitations under the License.
 package common
 
 import (
+	"context"
 	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"reflect"
 	"testing"
 
+	"github.com/stretchr/testify/assert"
+	crdv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
 	"github.com/oam-dev/kubevela/apis/types"
+	"github.com/oam-dev/kubevela/pkg/oam/mock"
+	"github.com/oam-dev/kubevela/pkg/utils/common"
+	"github.com/oam-dev/kubevela/pkg/utils/system"
+	cmdutil "github.com/oam-dev/kubevela/pkg/utils/util"
+	"github.com/oam-dev/kubevela/references/plugins"
 )
 
 func TestAddSourceIntoDefinition(t *testing.T) {
@@ -51,3 +66,106 @@ func TestAddSourceIntoDefinition(t *testing.T) {
 		t.Errorf("error result want %s, got %s", result, testcase)
 	}
 }
+
+func writeCenterTrait(t *testing.T, centerDir, name, version, dependencies string) {
+	def := fmt.Sprintf(`apiVersion: core.oam.dev/v1beta1
+kind: TraitDefinition
+metadata:
+  name: %s
+  annotations:
+    definition.oam.dev/version: "%s"
+    definition.oam.dev/dependencies: '%s'
+spec:
+  schematic:
+    cue:
+      template: |
+        parameter: replicas: *1 | int
+`, name, version, dependencies)
+	versionDir := filepath.Join(centerDir, plugins.VersionsDir, name)
+	assert.NoError(t, os.MkdirAll(versionDir, 0750))
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(versionDir, version+".yaml"), []byte(def), 0600))
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(centerDir, name+".yaml"), []byte(def), 0600))
+}
+
+func TestInstallCapabilityWithDependencies(t *testing.T) {
+	home, err := ioutil.TempDir("", "vela-home")
+	assert.NoError(t, err)
+	defer os.RemoveAll(home)
+	assert.NoError(t, os.Setenv(system.VelaHomeEnv, home))
+	defer os.Unsetenv(system.VelaHomeEnv)
+	centerDir := filepath.Join(home, "centers", "mycenter")
+	writeCenterTrait(t, centerDir, "base", "1.0.0", "")
+	writeCenterTrait(t, centerDir, "scaler", "1.0.0", `[{type: definition, name: base, version: "^1.0.0"}, {type: crd, name: foos.example.com}]`)
+	writeCenterTrait(t, centerDir, "loop", "1.0.0", `[{type: definition, name: loop}]`)
+
+	ctx := context.Background()
+	ioStreams := cmdutil.IOStreams{In: os.Stdin, Out: ioutil.Discard, ErrOut: ioutil.Discard}
+	k8sClient := fake.NewFakeClientWithScheme(common.Scheme)
+	err = InstallCapability(k8sClient, mock.NewMockDiscoveryMapper(), "mycenter", "scaler@1.0.0", false, ioStreams)
+	assert.EqualError(t, err, "CRD foos.example.com required by scaler is not installed in the cluster")
+
+	assert.NoError(t, k8sClient.Create(ctx, &crdv1.CustomResourceDefinition{ObjectMeta: v1.ObjectMeta{Name: "foos.example.com"}}))
+	assert.NoError(t, InstallCapability(k8sClient, mock.NewMockDiscoveryMapper(), "mycenter", "scaler@1.0.0", false, ioStreams))
+	packages, err := ListInstalledCapabilityPackages(ctx, k8sClient)
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []CapabilityPackage{
+		{Name: "base", Type: types.TypeTrait, Center: "mycenter", Version: "1.0.0"},
+		{Name: "scaler", Type: types.TypeTrait, Center: "mycenter", Version: "1.0.0"},
+	}, packages)
+
+	err = InstallCapability(k8sClient, mock.NewMockDiscoveryMapper(), "mycenter", "loop", false, ioStreams)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "circular dependency")
+
+	// a newer version is synced, the installed one is outdated and could be upgraded in place
+	writeCenterTrait(t, centerDir, "base", "1.1.0", "")
+	outdated, err := ListOutdatedCapabilities(ctx, k8sClient)
+	assert.NoError(t, err)
+	assert.Equal(t, []CapabilityPackage{{Name: "base", Type: types.TypeTrait, Center: "mycenter", Version: "1.0.0", Latest: "1.1.0"}}, outdated)
+	assert.NoError(t, UpgradeCapability(k8sClient, mock.NewMockDiscoveryMapper(), "", ioStreams))
+	installed, err := GetInstalledCapabilityPackage(ctx, k8sClient, "base")
+	assert.NoError(t, err)
+	assert.Equal(t, "1.1.0", installed.Version)
+	outdated, err = ListOutdatedCapabilities(ctx, k8sClient)
+	assert.NoError(t, err)
+	assert.Empty(t, outdated)
+}
+
+func TestInstallCapabilityOverwrite(t *testing.T) {
+	home, err := ioutil.TempDir("", "vela-home")
+	assert.NoError(t, err)
+	defer os.RemoveAll(home)
+	assert.NoError(t, os.Setenv(system.VelaHomeEnv, home))
+	defer os.Unsetenv(system.VelaHomeEnv)
+	writeCenterTrait(t, filepath.Join(home, "centers", "mycenter"), "base", "1.0.0", "")
+	writeCenterTrait(t, filepath.Join(home, "centers", "other"), "base", "1.0.0", "")
+
+	ctx := context.Background()
+	ioStreams := cmdutil.IOStreams{In: os.Stdin, Out: ioutil.Discard, ErrOut: ioutil.Discard}
+	k8sClient := fake.NewFakeClientWithScheme(common.Scheme)
+	assert.NoError(t, k8sClient.Create(ctx, &v1beta1.TraitDefinition{ObjectMeta: v1.ObjectMeta{Name: "base", Namespace: types.DefaultKubeVelaNS}}))
+
+	// a definition not installed from any cap center is kept
+	err = InstallCapability(k8sClient, mock.NewMockDiscoveryMapper(), "mycenter", "base", false, ioStreams)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "use --force to overwrite it")
+	assert.NoError(t, InstallCapability(k8sClient, mock.NewMockDiscoveryMapper(), "mycenter", "base", true, ioStreams))
+
+	// a definition installed from the same cap center is upgraded in place, but not from others
+	assert.NoError(t, InstallCapability(k8sClient, mock.NewMockDiscoveryMapper(), "mycenter", "base", false, ioStreams))
+	err = InstallCapability(k8sClient, mock.NewMockDiscoveryMapper(), "other", "base", false, ioStreams)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "installed from cap center mycenter")
+	installed, err := GetInstalledCapabilityPackage(ctx, k8sClient, "base")
+	assert.NoError(t, err)
+	assert.Equal(t, "mycenter", installed.Center)
+}
+
+func TestSplitCapabilityVersion(t *testing.T) {
+	name, version := SplitCapabilityVersion("route@>=1.0.0 <2.0.0")
+	assert.Equal(t, "route", name)
+	assert.Equal(t, ">=1.0.0 <2.0.0", version)
+	name, version = SplitCapabilityVersion("route")
+	assert.Equal(t, "route", name)
+	assert.Equal(t, "", version)
+}
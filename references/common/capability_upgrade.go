@@ -0,0 +1,154 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package common
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
+	"github.com/oam-dev/kubevela/apis/types"
+	"github.com/oam-dev/kubevela/pkg/oam/discoverymapper"
+	"github.com/oam-dev/kubevela/pkg/utils/system"
+	cmdutil "github.com/oam-dev/kubevela/pkg/utils/util"
+	"github.com/oam-dev/kubevela/references/plugins"
+)
+
+// CapabilityPackage is a capability installed from a cap center, it's recorded by annotations of the definition in cluster
+type CapabilityPackage struct {
+	Name    string
+	Type    types.CapType
+	Center  string
+	Version string
+	// Latest is the highest version synced from the cap center, it's only set by ListOutdatedCapabilities
+	Latest string
+}
+
+// ListInstalledCapabilityPackages lists all capabilities installed from cap centers
+func ListInstalledCapabilityPackages(ctx context.Context, c client.Client) ([]CapabilityPackage, error) {
+	var packages []CapabilityPackage
+	var components v1beta1.ComponentDefinitionList
+	if err := c.List(ctx, &components, client.InNamespace(types.DefaultKubeVelaNS)); err != nil {
+		return nil, err
+	}
+	for _, cd := range components.Items {
+		if center := cd.Annotations[types.AnnSourceCenter]; center != "" {
+			packages = append(packages, CapabilityPackage{
+				Name:    cd.Name,
+				Type:    types.TypeComponentDefinition,
+				Center:  center,
+				Version: cd.Annotations[types.AnnVersion],
+			})
+		}
+	}
+	var traits v1beta1.TraitDefinitionList
+	if err := c.List(ctx, &traits, client.InNamespace(types.DefaultKubeVelaNS)); err != nil {
+		return nil, err
+	}
+	for _, td := range traits.Items {
+		if center := td.Annotations[types.AnnSourceCenter]; center != "" {
+			packages = append(packages, CapabilityPackage{
+				Name:    td.Name,
+				Type:    types.TypeTrait,
+				Center:  center,
+				Version: td.Annotations[types.AnnVersion],
+			})
+		}
+	}
+	return packages, nil
+}
+
+// GetInstalledCapabilityPackage gets a capability installed from cap center by name, nil is returned if it's not installed
+func GetInstalledCapabilityPackage(ctx context.Context, c client.Client, name string) (*CapabilityPackage, error) {
+	packages, err := ListInstalledCapabilityPackages(ctx, c)
+	if err != nil {
+		return nil, err
+	}
+	for i := range packages {
+		if packages[i].Name == name {
+			return &packages[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// ListOutdatedCapabilities lists installed capabilities which have newer versions synced from their cap centers
+func ListOutdatedCapabilities(ctx context.Context, c client.Client) ([]CapabilityPackage, error) {
+	packages, err := ListInstalledCapabilityPackages(ctx, c)
+	if err != nil {
+		return nil, err
+	}
+	dir, err := system.GetCapCenterDir()
+	if err != nil {
+		return nil, err
+	}
+	var outdated []CapabilityPackage
+	for _, p := range packages {
+		versions, err := plugins.ListCapabilityVersions(filepath.Join(dir, p.Center), p.Name)
+		if err != nil {
+			return nil, err
+		}
+		if len(versions) == 0 {
+			continue
+		}
+		if latest := versions[len(versions)-1]; plugins.IsNewerVersion(latest, p.Version) {
+			p.Latest = latest
+			outdated = append(outdated, p)
+		}
+	}
+	return outdated, nil
+}
+
+// UpgradeCapability upgrades an installed capability to the highest version synced from its cap center,
+// all outdated capabilities are upgraded if the name is empty
+func UpgradeCapability(c client.Client, mapper discoverymapper.DiscoveryMapper, name string, ioStreams cmdutil.IOStreams) error {
+	ctx := context.Background()
+	outdated, err := ListOutdatedCapabilities(ctx, c)
+	if err != nil {
+		return err
+	}
+	if name != "" {
+		installed, err := GetInstalledCapabilityPackage(ctx, c, name)
+		if err != nil {
+			return err
+		}
+		if installed == nil {
+			return fmt.Errorf("%s is not installed from any capability center", name)
+		}
+		var found []CapabilityPackage
+		for _, p := range outdated {
+			if p.Name == name {
+				found = append(found, p)
+			}
+		}
+		outdated = found
+	}
+	if len(outdated) == 0 {
+		ioStreams.Info("All capabilities are up to date")
+		return nil
+	}
+	for _, p := range outdated {
+		ioStreams.Infof("Upgrading %s/%s from %q to %s\n", p.Center, p.Name, p.Version, p.Latest)
+		if err := InstallCapability(c, mapper, p.Center, p.Name+"@"+p.Latest, false, ioStreams); err != nil {
+			return err
+		}
+	}
+	return nil
+}
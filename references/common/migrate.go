@@ -0,0 +1,819 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package common
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	runtimev1alpha1 "github.com/crossplane/crossplane-runtime/apis/core/v1alpha1"
+	"github.com/crossplane/crossplane-runtime/pkg/fieldpath"
+	"github.com/pkg/errors"
+	appsv1 "k8s.io/api/apps/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	ktypes "k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/common"
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1alpha2"
+	corev1beta1 "github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
+	"github.com/oam-dev/kubevela/apis/types"
+	"github.com/oam-dev/kubevela/pkg/controller/utils"
+	"github.com/oam-dev/kubevela/pkg/dsl/definition"
+	"github.com/oam-dev/kubevela/pkg/oam"
+	"github.com/oam-dev/kubevela/pkg/oam/discoverymapper"
+	"github.com/oam-dev/kubevela/pkg/oam/util"
+	"github.com/oam-dev/kubevela/references/appfile"
+	"github.com/oam-dev/kubevela/references/appfile/dryrun"
+	"github.com/oam-dev/kubevela/references/plugins"
+)
+
+// Migration is an application converted from an ApplicationConfiguration and its Components
+type Migration struct {
+	Application *corev1beta1.Application
+	// ComponentDefinitions are generated for the workloads no existing ComponentDefinition reproduces
+	ComponentDefinitions []*corev1beta1.ComponentDefinition
+	// TraitDefinitions are generated for the traits no existing TraitDefinition reproduces
+	TraitDefinitions []*corev1beta1.TraitDefinition
+	// Warnings are the parts of the AppConfig that the application cannot express
+	Warnings []string
+}
+
+// migrator converts an ApplicationConfiguration into an application
+type migrator struct {
+	client client.Client
+	dm     discoverymapper.DiscoveryMapper
+	pd     *definition.PackageDiscover
+	ac     *v1alpha2.ApplicationConfiguration
+
+	componentDefs []*corev1beta1.ComponentDefinition
+	traitDefs     []*corev1beta1.TraitDefinition
+	workloadDefs  map[string]corev1beta1.WorkloadDefinition
+	scopeDefs     map[string]bool
+	// auxiliaries are the definitions to render the application with
+	auxiliaries []oam.Object
+
+	migration *Migration
+}
+
+// MigrateAppConfig converts an ApplicationConfiguration and its Components into an application.
+// Each workload becomes a component of the existing ComponentDefinition that renders exactly the same workload,
+// otherwise a ComponentDefinition with a kube schematic is generated from the Component, whose parameters are kept.
+// Each trait becomes a trait of the existing TraitDefinition that renders exactly the same trait with its spec as
+// properties and its name, otherwise a TraitDefinition is generated for its kind. Components are named after their
+// workloads and traits keep their names, and the application adopts the existing resources, so they're handed over
+// without being recreated.
+// The parts of the AppConfig the application cannot express, such as dataInputs and dataOutputs, are returned as
+// warnings.
+func MigrateAppConfig(ctx context.Context, c client.Client, dm discoverymapper.DiscoveryMapper, pd *definition.PackageDiscover,
+	ac *v1alpha2.ApplicationConfiguration) (*Migration, error) {
+	for _, owner := range ac.GetOwnerReferences() {
+		if owner.Kind == corev1beta1.ApplicationKind && owner.Controller != nil && *owner.Controller {
+			return nil, errors.Errorf("AppConfig %s is generated by application %s, it needn't be migrated", ac.Name, owner.Name)
+		}
+	}
+	m := &migrator{client: c, dm: dm, pd: pd, ac: ac, migration: &Migration{}}
+	if err := m.loadDefinitions(ctx); err != nil {
+		return nil, err
+	}
+
+	annotations := map[string]string{}
+	for k, v := range ac.GetAnnotations() {
+		if k != "kubectl.kubernetes.io/last-applied-configuration" {
+			annotations[k] = v
+		}
+	}
+	annotations[oam.AnnotationAdoptResources] = "true"
+	app := &corev1beta1.Application{
+		TypeMeta: metav1.TypeMeta{APIVersion: corev1beta1.SchemeGroupVersion.String(), Kind: corev1beta1.ApplicationKind},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        ac.Name,
+			Namespace:   ac.Namespace,
+			Labels:      ac.GetLabels(),
+			Annotations: annotations,
+		},
+	}
+	for _, acc := range ac.Spec.Components {
+		comp, err := m.migrateComponent(ctx, acc)
+		if err != nil {
+			return nil, err
+		}
+		app.Spec.Components = append(app.Spec.Components, *comp)
+	}
+	m.migration.Application = app
+	return m.migration, nil
+}
+
+// loadDefinitions lists the definitions in the namespace of the AppConfig and the system namespace, definitions
+// in the namespace take precedence over the ones with the same names in the system namespace.
+func (m *migrator) loadDefinitions(ctx context.Context) error {
+	componentDefs := map[string]corev1beta1.ComponentDefinition{}
+	traitDefs := map[string]corev1beta1.TraitDefinition{}
+	m.workloadDefs = map[string]corev1beta1.WorkloadDefinition{}
+	m.scopeDefs = map[string]bool{}
+	for _, ns := range []string{types.DefaultKubeVelaNS, m.ac.Namespace} {
+		var cds corev1beta1.ComponentDefinitionList
+		if err := m.client.List(ctx, &cds, client.InNamespace(ns)); err != nil {
+			return errors.Wrap(err, "cannot list component definitions")
+		}
+		for _, cd := range cds.Items {
+			componentDefs[cd.Name] = cd
+		}
+		var tds corev1beta1.TraitDefinitionList
+		if err := m.client.List(ctx, &tds, client.InNamespace(ns)); err != nil {
+			return errors.Wrap(err, "cannot list trait definitions")
+		}
+		for _, td := range tds.Items {
+			traitDefs[td.Name] = td
+		}
+		var wds corev1beta1.WorkloadDefinitionList
+		if err := m.client.List(ctx, &wds, client.InNamespace(ns)); err != nil {
+			return errors.Wrap(err, "cannot list workload definitions")
+		}
+		for _, wd := range wds.Items {
+			m.workloadDefs[wd.Name] = wd
+		}
+		var sds corev1beta1.ScopeDefinitionList
+		if err := m.client.List(ctx, &sds, client.InNamespace(ns)); err != nil {
+			return errors.Wrap(err, "cannot list scope definitions")
+		}
+		for _, sd := range sds.Items {
+			m.scopeDefs[sd.Name] = true
+		}
+	}
+
+	var cdNames, tdNames []string
+	for name := range componentDefs {
+		cdNames = append(cdNames, name)
+	}
+	for name := range traitDefs {
+		tdNames = append(tdNames, name)
+	}
+	sort.Strings(cdNames)
+	sort.Strings(tdNames)
+	for _, name := range cdNames {
+		cd := componentDefs[name]
+		if err := m.addComponentDefinition(&cd); err != nil {
+			return err
+		}
+	}
+	for _, name := range tdNames {
+		td := traitDefs[name]
+		if err := m.addTraitDefinition(&td); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (m *migrator) addComponentDefinition(cd *corev1beta1.ComponentDefinition) error {
+	u, err := toUnstructuredDefinition(cd, corev1beta1.ComponentDefinitionKind)
+	if err != nil {
+		return err
+	}
+	m.componentDefs = append(m.componentDefs, cd)
+	m.auxiliaries = append(m.auxiliaries, u)
+	return nil
+}
+
+func (m *migrator) addTraitDefinition(td *corev1beta1.TraitDefinition) error {
+	u, err := toUnstructuredDefinition(td, corev1beta1.TraitDefinitionKind)
+	if err != nil {
+		return err
+	}
+	m.traitDefs = append(m.traitDefs, td)
+	m.auxiliaries = append(m.auxiliaries, u)
+	return nil
+}
+
+// toUnstructuredDefinition converts a definition to the unstructured one used to render applications in dry-run
+func toUnstructuredDefinition(obj runtime.Object, kind string) (*unstructured.Unstructured, error) {
+	data, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot convert definition")
+	}
+	u := &unstructured.Unstructured{Object: data}
+	u.SetGroupVersionKind(corev1beta1.SchemeGroupVersion.WithKind(kind))
+	return u, nil
+}
+
+func (m *migrator) warnf(format string, args ...interface{}) {
+	m.migration.Warnings = append(m.migration.Warnings, fmt.Sprintf(format, args...))
+}
+
+func (m *migrator) migrateComponent(ctx context.Context, acc v1alpha2.ApplicationConfigurationComponent) (*corev1beta1.ApplicationComponent, error) {
+	componentName := acc.ComponentName
+	if acc.RevisionName != "" {
+		componentName = utils.ExtractComponentName(acc.RevisionName)
+	}
+	c, _, err := util.GetComponent(ctx, m.client, acc, m.ac.Namespace)
+	if err != nil {
+		return nil, err
+	}
+	workload, err := renderComponentWorkload(c, acc.ParameterValues)
+	if err != nil {
+		return nil, errors.WithMessagef(err, "cannot render the workload of component %s", componentName)
+	}
+	if len(acc.DataInputs) != 0 || len(acc.DataOutputs) != 0 {
+		m.warnf("dataInputs and dataOutputs of component %s are dropped, the fields filled by dataInputs are no longer set", componentName)
+	}
+
+	name := m.workloadName(componentName, workload)
+	if name != componentName {
+		m.warnf("component %s is named %s after its workload to adopt it", componentName, name)
+	}
+	var comp *corev1beta1.ApplicationComponent
+	if c.Spec.Helm != nil {
+		comp, err = m.migrateHelmComponent(name, c, workload, acc.ParameterValues)
+	} else {
+		comp, err = m.migrateWorkload(ctx, name, c, workload, acc.ParameterValues)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	for _, ct := range acc.Traits {
+		trait, err := util.RawExtension2Unstructured(&ct.Trait)
+		if err != nil {
+			return nil, errors.WithMessagef(err, "cannot decode the trait of component %s", componentName)
+		}
+		if len(ct.DataInputs) != 0 || len(ct.DataOutputs) != 0 {
+			m.warnf("dataInputs and dataOutputs of trait %s of component %s are dropped", trait.GetKind(), componentName)
+		}
+		if len(trait.GetLabels()) != 0 || len(trait.GetAnnotations()) != 0 {
+			m.warnf("labels and annotations of trait %s of component %s are dropped", trait.GetKind(), componentName)
+		}
+		appTrait, err := m.migrateTrait(ctx, comp, workload, trait, m.traitName(componentName, trait))
+		if err != nil {
+			return nil, err
+		}
+		comp.Traits = append(comp.Traits, *appTrait)
+	}
+
+	for _, cs := range acc.Scopes {
+		ref := cs.ScopeReference
+		scope := &unstructured.Unstructured{}
+		scope.SetAPIVersion(ref.APIVersion)
+		scope.SetKind(ref.Kind)
+		scopeType, err := util.GetDefinitionName(m.dm, scope, "")
+		if err != nil || !m.scopeDefs[scopeType] {
+			m.warnf("scope %s %s of component %s is dropped, no ScopeDefinition is found for it", ref.Kind, ref.Name, componentName)
+			continue
+		}
+		if comp.Scopes == nil {
+			comp.Scopes = map[string]string{}
+		}
+		comp.Scopes[scopeType] = ref.Name
+	}
+	return comp, nil
+}
+
+// renderComponentWorkload renders the workload of a Component with the parameter values like the AppConfig does
+func renderComponentWorkload(c *v1alpha2.Component, values []v1alpha2.ComponentParameterValue) (*unstructured.Unstructured, error) {
+	workload, err := util.RawExtension2Unstructured(&c.Spec.Workload)
+	if err != nil {
+		return nil, err
+	}
+	paved := fieldpath.Pave(workload.Object)
+	for _, v := range values {
+		var param *v1alpha2.ComponentParameter
+		for i := range c.Spec.Parameters {
+			if c.Spec.Parameters[i].Name == v.Name {
+				param = &c.Spec.Parameters[i]
+			}
+		}
+		if param == nil {
+			return nil, errors.Errorf("unsupported parameter %q", v.Name)
+		}
+		for _, path := range param.FieldPaths {
+			if err := paved.SetValue(path, parameterValue(v.Value)); err != nil {
+				return nil, errors.Wrapf(err, "cannot set parameter %q", v.Name)
+			}
+		}
+	}
+	return workload, nil
+}
+
+func parameterValue(v intstr.IntOrString) interface{} {
+	if v.Type == intstr.Int {
+		return int64(v.IntVal)
+	}
+	return v.StrVal
+}
+
+// workloadName finds the name of the workload the AppConfig has created for a component
+func (m *migrator) workloadName(componentName string, workload *unstructured.Unstructured) string {
+	for _, w := range m.ac.Status.Workloads {
+		if w.ComponentName == componentName && w.Reference.Name != "" {
+			return w.Reference.Name
+		}
+	}
+	if workload.GetName() != "" {
+		return workload.GetName()
+	}
+	return componentName
+}
+
+// traitName finds the name of the trait the AppConfig has created for a component like the AppConfig does, it's
+// empty if the trait is not created yet
+func (m *migrator) traitName(componentName string, trait *unstructured.Unstructured) string {
+	if trait.GetName() != "" {
+		return trait.GetName()
+	}
+	var name string
+	for _, w := range m.ac.Status.Workloads {
+		if w.ComponentName != componentName {
+			continue
+		}
+		for _, t := range w.Traits {
+			if t.Reference.APIVersion == trait.GetAPIVersion() && t.Reference.Kind == trait.GetKind() {
+				name = t.Reference.Name
+			}
+		}
+	}
+	return name
+}
+
+// migrateWorkload finds the ComponentDefinition reproducing the workload, or generates one with a kube schematic
+func (m *migrator) migrateWorkload(ctx context.Context, name string, c *v1alpha2.Component, workload *unstructured.Unstructured,
+	values []v1alpha2.ComponentParameterValue) (*corev1beta1.ApplicationComponent, error) {
+	pool := podTemplateProperties(workload)
+	for _, cd := range m.componentDefs {
+		def := cd.Spec.Workload.Definition
+		if def.APIVersion != workload.GetAPIVersion() || def.Kind != workload.GetKind() {
+			continue
+		}
+		properties, ok := componentProperties(cd, pool, values)
+		if !ok {
+			continue
+		}
+		comp := &corev1beta1.ApplicationComponent{Name: name, Type: cd.Name, Properties: rawProperties(properties)}
+		rendered, _, err := m.render(ctx, *comp)
+		if err == nil && sameObject(workload, rendered) {
+			return comp, nil
+		}
+	}
+
+	cd, err := m.generateComponentDefinition(c, workload)
+	if err != nil {
+		return nil, err
+	}
+	properties, _ := componentProperties(cd, nil, values)
+	comp := &corev1beta1.ApplicationComponent{Name: name, Type: cd.Name, Properties: rawProperties(properties)}
+	if rendered, _, err := m.render(ctx, *comp); err != nil || !sameObject(workload, rendered) {
+		m.warnf("component %s cannot be verified to render the same workload, review ComponentDefinition %s", name, cd.Name)
+	}
+	return comp, nil
+}
+
+// podTemplateProperties collects the properties a component could have from a pod template based workload
+func podTemplateProperties(workload *unstructured.Unstructured) map[string]interface{} {
+	if workload.GetAPIVersion() != "apps/v1" {
+		return nil
+	}
+	w := adoptableWorkload{apiVersion: workload.GetAPIVersion(), kind: workload.GetKind(), name: workload.GetName()}
+	switch workload.GetKind() {
+	case "Deployment":
+		var d appsv1.Deployment
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(workload.Object, &d); err != nil {
+			return nil
+		}
+		w.replicas, w.template = d.Spec.Replicas, d.Spec.Template
+	case "StatefulSet":
+		var s appsv1.StatefulSet
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(workload.Object, &s); err != nil {
+			return nil
+		}
+		w.replicas, w.template = s.Spec.Replicas, s.Spec.Template
+	case "DaemonSet":
+		var d appsv1.DaemonSet
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(workload.Object, &d); err != nil {
+			return nil
+		}
+		w.template = d.Spec.Template
+	default:
+		return nil
+	}
+	pool, _ := workloadProperties(w, nil)
+	return pool
+}
+
+// componentProperties finds the properties of a component of the ComponentDefinition. Parameter values are the
+// properties of a kube schematic, and the matched properties of the pod template are the ones of a CUE schematic.
+func componentProperties(cd *corev1beta1.ComponentDefinition, pool map[string]interface{},
+	values []v1alpha2.ComponentParameterValue) (map[string]interface{}, bool) {
+	if cd.Spec.Schematic == nil {
+		return nil, false
+	}
+	if cd.Spec.Schematic.KUBE != nil {
+		properties := map[string]interface{}{}
+		for _, v := range values {
+			properties[v.Name] = parameterValue(v.Value)
+		}
+		return properties, true
+	}
+	if cd.Spec.Schematic.CUE == nil {
+		return nil, false
+	}
+	capability, err := plugins.GetCapabilityByComponentDefinitionObject(*cd, "")
+	if err != nil {
+		return nil, false
+	}
+	properties, _, ok := matchParameters(pool, capability.Parameters)
+	return properties, ok
+}
+
+// generateComponentDefinition generates a ComponentDefinition with a kube schematic from the Component
+func (m *migrator) generateComponentDefinition(c *v1alpha2.Component, workload *unstructured.Unstructured) (*corev1beta1.ComponentDefinition, error) {
+	template, err := util.RawExtension2Unstructured(&c.Spec.Workload)
+	if err != nil {
+		return nil, err
+	}
+	unstructured.RemoveNestedField(template.Object, "metadata", "name")
+	unstructured.RemoveNestedField(template.Object, "metadata", "namespace")
+	unstructured.RemoveNestedField(template.Object, "status")
+	if len(template.GetLabels()) == 0 && len(template.GetAnnotations()) == 0 {
+		unstructured.RemoveNestedField(template.Object, "metadata")
+	}
+
+	var parameters []common.KubeParameter
+	for _, p := range c.Spec.Parameters {
+		parameters = append(parameters, common.KubeParameter{
+			Name:        p.Name,
+			ValueType:   kubeParameterType(p, template, workload),
+			FieldPaths:  p.FieldPaths,
+			Required:    p.Required,
+			Description: p.Description,
+		})
+	}
+	cd := &corev1beta1.ComponentDefinition{
+		TypeMeta: metav1.TypeMeta{APIVersion: corev1beta1.SchemeGroupVersion.String(), Kind: corev1beta1.ComponentDefinitionKind},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      m.definitionName(c.Name, m.componentDefinitionExists),
+			Namespace: m.ac.Namespace,
+			Annotations: map[string]string{
+				types.AnnDescription: fmt.Sprintf("%s migrated from Component %s", workload.GetKind(), c.Name),
+			},
+		},
+		Spec: corev1beta1.ComponentDefinitionSpec{
+			Workload: common.WorkloadTypeDescriptor{
+				Definition: common.WorkloadGVK{APIVersion: workload.GetAPIVersion(), Kind: workload.GetKind()},
+			},
+			Schematic: &common.Schematic{KUBE: &common.Kube{
+				Template:   util.Object2RawExtension(template.Object),
+				Parameters: parameters,
+			}},
+		},
+	}
+	m.copyWorkloadDefinition(cd, workload)
+	if err := m.addComponentDefinition(cd); err != nil {
+		return nil, err
+	}
+	m.migration.ComponentDefinitions = append(m.migration.ComponentDefinitions, cd)
+	return cd, nil
+}
+
+// kubeParameterType infers the type of a parameter from the value set by the AppConfig, or the field it overwrites
+func kubeParameterType(p v1alpha2.ComponentParameter, template, workload *unstructured.Unstructured) common.ParameterValueType {
+	for _, obj := range []*unstructured.Unstructured{workload, template} {
+		for _, path := range p.FieldPaths {
+			v, err := fieldpath.Pave(obj.Object).GetValue(path)
+			if err != nil {
+				continue
+			}
+			switch v.(type) {
+			case bool:
+				return common.BooleanType
+			case int64, float64:
+				return common.NumberType
+			case map[string]interface{}:
+				return common.ObjectType
+			case []interface{}:
+				return common.ArrayType
+			default:
+				return common.StringType
+			}
+		}
+	}
+	return common.StringType
+}
+
+// copyWorkloadDefinition copies the characteristics of the workload from the WorkloadDefinition of its kind
+func (m *migrator) copyWorkloadDefinition(cd *corev1beta1.ComponentDefinition, workload *unstructured.Unstructured) {
+	name, err := util.GetDefinitionName(m.dm, workload, "")
+	if err != nil {
+		return
+	}
+	wd, ok := m.workloadDefs[name]
+	if !ok {
+		return
+	}
+	cd.Spec.ChildResourceKinds = wd.Spec.ChildResourceKinds
+	cd.Spec.RevisionLabel = wd.Spec.RevisionLabel
+	cd.Spec.PodSpecPath = wd.Spec.PodSpecPath
+	cd.Spec.Status = wd.Spec.Status
+}
+
+// migrateHelmComponent generates a ComponentDefinition with a Helm schematic from the Component
+func (m *migrator) migrateHelmComponent(name string, c *v1alpha2.Component, workload *unstructured.Unstructured,
+	values []v1alpha2.ComponentParameterValue) (*corev1beta1.ApplicationComponent, error) {
+	if len(values) != 0 {
+		m.warnf("parameter values of Helm component %s are dropped, set them in the values of ComponentDefinition %s", c.Name, c.Name)
+	}
+	cd := &corev1beta1.ComponentDefinition{
+		TypeMeta: metav1.TypeMeta{APIVersion: corev1beta1.SchemeGroupVersion.String(), Kind: corev1beta1.ComponentDefinitionKind},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      m.definitionName(c.Name, m.componentDefinitionExists),
+			Namespace: m.ac.Namespace,
+			Annotations: map[string]string{
+				types.AnnDescription: fmt.Sprintf("Helm release migrated from Component %s", c.Name),
+			},
+		},
+		Spec: corev1beta1.ComponentDefinitionSpec{
+			Workload: common.WorkloadTypeDescriptor{
+				Definition: common.WorkloadGVK{APIVersion: workload.GetAPIVersion(), Kind: workload.GetKind()},
+			},
+			Schematic: &common.Schematic{HELM: c.Spec.Helm.DeepCopy()},
+		},
+	}
+	m.copyWorkloadDefinition(cd, workload)
+	if err := m.addComponentDefinition(cd); err != nil {
+		return nil, err
+	}
+	m.migration.ComponentDefinitions = append(m.migration.ComponentDefinitions, cd)
+	return &corev1beta1.ApplicationComponent{Name: name, Type: cd.Name}, nil
+}
+
+// migrateTrait finds the TraitDefinition reproducing the trait with its spec as properties and its name, or generates
+// one. The name is kept so that the application takes over the trait instead of creating another one.
+func (m *migrator) migrateTrait(ctx context.Context, comp *corev1beta1.ApplicationComponent, workload,
+	trait *unstructured.Unstructured, name string) (*corev1beta1.ApplicationTrait, error) {
+	spec, _, err := unstructured.NestedMap(trait.Object, "spec")
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid spec of trait %s", trait.GetKind())
+	}
+	if spec == nil {
+		spec = map[string]interface{}{}
+	}
+	expected := trait.DeepCopy()
+	expected.SetLabels(nil)
+	expected.SetAnnotations(nil)
+	reproduces := func(td *corev1beta1.TraitDefinition) bool {
+		c := *comp.DeepCopy()
+		c.Traits = []corev1beta1.ApplicationTrait{{Type: td.Name, Properties: rawProperties(spec)}}
+		rendered, traits, err := m.render(ctx, c)
+		// sameObject ignores the names, the trait must have the same name to be taken over
+		return err == nil && len(traits) == 1 && sameObject(workload, rendered) && sameObject(expected, traits[0]) &&
+			traits[0].GetName() == name
+	}
+
+	for _, td := range m.traitDefs {
+		if td.Spec.Schematic == nil || td.Spec.Schematic.CUE == nil {
+			continue
+		}
+		if reproduces(td) {
+			return &corev1beta1.ApplicationTrait{Type: td.Name, Properties: rawProperties(spec)}, nil
+		}
+	}
+	td, err := m.generateTraitDefinition(trait, name)
+	if err != nil {
+		return nil, err
+	}
+	if !reproduces(td) {
+		m.warnf("trait %s of component %s cannot be verified to render the same trait, review TraitDefinition %s",
+			trait.GetKind(), comp.Name, td.Name)
+	}
+	return &corev1beta1.ApplicationTrait{Type: td.Name, Properties: rawProperties(spec)}, nil
+}
+
+// generateTraitDefinition generates a TraitDefinition outputting the trait with the properties as its spec, the
+// characteristics of the trait are copied from the TraitDefinition of its kind. The trait is named by traitName if
+// it's not empty, so the TraitDefinition only reproduces the trait of the same name.
+func (m *migrator) generateTraitDefinition(trait *unstructured.Unstructured, traitName string) (*corev1beta1.TraitDefinition, error) {
+	name := strings.ToLower(trait.GetKind())
+	fields := []string{
+		fmt.Sprintf("apiVersion: %q", trait.GetAPIVersion()),
+		fmt.Sprintf("kind: %q", trait.GetKind()),
+	}
+	if traitName != "" {
+		fields = append(fields, fmt.Sprintf("metadata: name: %q", traitName))
+	}
+	var keys []string
+	for k := range trait.Object {
+		if k != "apiVersion" && k != "kind" && k != "metadata" {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		if k == "spec" {
+			fields = append(fields, "spec: parameter")
+			continue
+		}
+		// other fields than spec are kept as they are
+		v, err := json.Marshal(trait.Object[k])
+		if err != nil {
+			return nil, errors.Wrapf(err, "cannot marshal field %s of trait %s", k, trait.GetKind())
+		}
+		fields = append(fields, fmt.Sprintf("%q: %s", k, v))
+	}
+	template := fmt.Sprintf("outputs: %s: {\n\t%s\n}\nparameter: {...}\n", name, strings.Join(fields, "\n\t"))
+
+	td := &corev1beta1.TraitDefinition{
+		TypeMeta: metav1.TypeMeta{APIVersion: corev1beta1.SchemeGroupVersion.String(), Kind: corev1beta1.TraitDefinitionKind},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      m.definitionName(name, m.traitDefinitionExists),
+			Namespace: m.ac.Namespace,
+			Annotations: map[string]string{
+				types.AnnDescription: fmt.Sprintf("%s migrated from ApplicationConfiguration", trait.GetKind()),
+			},
+		},
+		Spec: corev1beta1.TraitDefinitionSpec{
+			Schematic: &common.Schematic{CUE: &common.CUE{Template: template}},
+		},
+	}
+	if legacyName, err := util.GetDefinitionName(m.dm, trait, ""); err == nil {
+		for _, legacy := range m.traitDefs {
+			if legacy.Name == legacyName {
+				td.Spec.WorkloadRefPath = legacy.Spec.WorkloadRefPath
+				td.Spec.RevisionEnabled = legacy.Spec.RevisionEnabled
+				td.Spec.PodDisruptive = legacy.Spec.PodDisruptive
+				td.Spec.Status = legacy.Spec.Status
+			}
+		}
+	}
+	if err := m.addTraitDefinition(td); err != nil {
+		return nil, err
+	}
+	m.migration.TraitDefinitions = append(m.migration.TraitDefinitions, td)
+	return td, nil
+}
+
+func (m *migrator) componentDefinitionExists(name string) bool {
+	for _, cd := range m.componentDefs {
+		if cd.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+func (m *migrator) traitDefinitionExists(name string) bool {
+	for _, td := range m.traitDefs {
+		if td.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// definitionName finds a name not used by existing definitions for a generated definition
+func (m *migrator) definitionName(name string, exists func(string) bool) string {
+	if !exists(name) {
+		return name
+	}
+	candidate := name + "-migrated"
+	for i := 2; exists(candidate); i++ {
+		candidate = fmt.Sprintf("%s-migrated-%d", name, i)
+	}
+	return candidate
+}
+
+// render renders the workload and traits of a component with the definitions in dry-run
+func (m *migrator) render(ctx context.Context, comp corev1beta1.ApplicationComponent) (*unstructured.Unstructured, []*unstructured.Unstructured, error) {
+	app := &corev1beta1.Application{
+		ObjectMeta: metav1.ObjectMeta{Name: m.ac.Name, Namespace: m.ac.Namespace},
+		Spec:       corev1beta1.ApplicationSpec{Components: []corev1beta1.ApplicationComponent{comp}},
+	}
+	ac, comps, err := dryrun.NewDryRunOption(m.client, m.dm, m.pd, m.auxiliaries).ExecuteDryRun(ctx, app)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(comps) != 1 || len(ac.Spec.Components) != 1 {
+		return nil, nil, errors.Errorf("component %s is not rendered", comp.Name)
+	}
+	workload, err := util.RawExtension2Unstructured(&comps[0].Spec.Workload)
+	if err != nil {
+		return nil, nil, err
+	}
+	var traits []*unstructured.Unstructured
+	for i := range ac.Spec.Components[0].Traits {
+		t, err := util.RawExtension2Unstructured(&ac.Spec.Components[0].Traits[i].Trait)
+		if err != nil {
+			return nil, nil, err
+		}
+		traits = append(traits, t)
+	}
+	return workload, traits, nil
+}
+
+// sameObject checks if the rendered object is the same as the expected one. The names, namespaces and status are
+// ignored, and the rendered object could have more labels and annotations, which are added by the application.
+func sameObject(expected, rendered *unstructured.Unstructured) bool {
+	for k, v := range expected.GetLabels() {
+		if l, ok := rendered.GetLabels()[k]; !ok || l != v {
+			return false
+		}
+	}
+	for k, v := range expected.GetAnnotations() {
+		if a, ok := rendered.GetAnnotations()[k]; !ok || a != v {
+			return false
+		}
+	}
+	normalize := func(u *unstructured.Unstructured) (interface{}, error) {
+		obj := u.DeepCopy()
+		for _, field := range []string{"name", "namespace", "labels", "annotations"} {
+			unstructured.RemoveNestedField(obj.Object, "metadata", field)
+		}
+		if metadata, _, _ := unstructured.NestedMap(obj.Object, "metadata"); len(metadata) == 0 {
+			unstructured.RemoveNestedField(obj.Object, "metadata")
+		}
+		unstructured.RemoveNestedField(obj.Object, "status")
+		// numbers are compared after being encoded the same way
+		data, err := json.Marshal(obj.Object)
+		if err != nil {
+			return nil, err
+		}
+		var v interface{}
+		return v, json.Unmarshal(data, &v)
+	}
+	e, err := normalize(expected)
+	if err != nil {
+		return false
+	}
+	r, err := normalize(rendered)
+	if err != nil {
+		return false
+	}
+	return reflect.DeepEqual(e, r)
+}
+
+// ApplyMigration creates or updates the generated definitions and the application of the migration
+func ApplyMigration(ctx context.Context, c client.Client, migration *Migration) error {
+	var definitions []oam.Object
+	for _, cd := range migration.ComponentDefinitions {
+		definitions = append(definitions, cd)
+	}
+	for _, td := range migration.TraitDefinitions {
+		definitions = append(definitions, td)
+	}
+	return appfile.Run(ctx, c, migration.Application, definitions)
+}
+
+// HandOverAppConfig annotates the AppConfig to hand over its workloads and traits to the application, the application
+// only takes over the resources controlled by the AppConfigs handed over to it
+func HandOverAppConfig(ctx context.Context, c client.Client, ac *v1alpha2.ApplicationConfiguration, appName string) error {
+	patch := client.MergeFrom(ac.DeepCopy())
+	util.AddAnnotations(ac, map[string]string{oam.AnnotationHandedOverTo: appName})
+	return errors.Wrapf(c.Patch(ctx, ac, patch), "cannot hand over AppConfig %s", ac.Name)
+}
+
+// AppConfigHandedOver checks if all the workloads and traits of the AppConfig are controlled by others, so that the
+// AppConfig could be deleted without deleting its workloads and traits
+func AppConfigHandedOver(ctx context.Context, c client.Reader, ac *v1alpha2.ApplicationConfiguration) (bool, error) {
+	var refs []runtimev1alpha1.TypedReference
+	for _, w := range ac.Status.Workloads {
+		refs = append(refs, w.Reference)
+		for _, t := range w.Traits {
+			refs = append(refs, t.Reference)
+		}
+	}
+	for _, ref := range refs {
+		obj := &unstructured.Unstructured{}
+		obj.SetAPIVersion(ref.APIVersion)
+		obj.SetKind(ref.Kind)
+		key := ktypes.NamespacedName{Namespace: ac.Namespace, Name: ref.Name}
+		if err := c.Get(ctx, key, obj); err != nil {
+			if apierrors.IsNotFound(err) {
+				return false, nil
+			}
+			return false, errors.Wrapf(err, "cannot get %s %s", ref.Kind, ref.Name)
+		}
+		if controller := metav1.GetControllerOf(obj); controller == nil || controller.UID == ac.UID {
+			return false, nil
+		}
+	}
+	return true, nil
+}
@@ -0,0 +1,247 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package common
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	runtimev1alpha1 "github.com/crossplane/crossplane-runtime/apis/core/v1alpha1"
+	"gotest.tools/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv1 "k8s.io/api/autoscaling/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	ktypes "k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/common"
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1alpha2"
+	corev1beta1 "github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
+	"github.com/oam-dev/kubevela/apis/types"
+	"github.com/oam-dev/kubevela/pkg/dsl/definition"
+	"github.com/oam-dev/kubevela/pkg/oam"
+	"github.com/oam-dev/kubevela/pkg/oam/discoverymapper"
+	"github.com/oam-dev/kubevela/pkg/oam/util"
+)
+
+func TestMigrateAppConfig(t *testing.T) {
+	scheme := runtime.NewScheme()
+	assert.NilError(t, corev1beta1.SchemeBuilder.AddToScheme(scheme))
+	assert.NilError(t, v1alpha2.SchemeBuilder.AddToScheme(scheme))
+	assert.NilError(t, clientgoscheme.AddToScheme(scheme))
+	dm := discoverymapper.NewStaticDiscoveryMapper(scheme)
+	schema, err := definition.BundledOpenAPISchema()
+	assert.NilError(t, err)
+	pd, err := definition.NewPackageDiscoverFromOpenAPI(schema)
+	assert.NilError(t, err)
+
+	deployment := func(name string, hostNetwork bool) runtime.RawExtension {
+		labels := map[string]interface{}{"app.oam.dev/component": name}
+		podSpec := map[string]interface{}{
+			"containers": []interface{}{map[string]interface{}{"name": name, "image": "busybox"}},
+		}
+		if hostNetwork {
+			podSpec["hostNetwork"] = true
+		}
+		return util.Object2RawExtension(map[string]interface{}{
+			"apiVersion": "apps/v1",
+			"kind":       "Deployment",
+			"spec": map[string]interface{}{
+				"selector": map[string]interface{}{"matchLabels": labels},
+				"template": map[string]interface{}{"metadata": map[string]interface{}{"labels": labels}, "spec": podSpec},
+			},
+		})
+	}
+	imageParameter := []v1alpha2.ComponentParameter{{Name: "image", FieldPaths: []string{"spec.template.spec.containers[0].image"}}}
+	backend := &v1alpha2.Component{ObjectMeta: metav1.ObjectMeta{Name: "backend", Namespace: "default"},
+		Spec: v1alpha2.ComponentSpec{Workload: deployment("backend", false), Parameters: imageParameter}}
+	frontend := &v1alpha2.Component{ObjectMeta: metav1.ObjectMeta{Name: "frontend", Namespace: "default"},
+		Spec: v1alpha2.ComponentSpec{Workload: deployment("frontend", true), Parameters: imageParameter}}
+	legacyScaler := &corev1beta1.TraitDefinition{
+		ObjectMeta: metav1.ObjectMeta{Name: "manualscalertraits.core.oam.dev", Namespace: types.DefaultKubeVelaNS},
+		Spec: corev1beta1.TraitDefinitionSpec{
+			Reference:       common.DefinitionReference{Name: "manualscalertraits.core.oam.dev"},
+			WorkloadRefPath: "spec.workloadRef",
+		},
+	}
+	healthScope := &corev1beta1.ScopeDefinition{
+		ObjectMeta: metav1.ObjectMeta{Name: "healthscopes.core.oam.dev", Namespace: types.DefaultKubeVelaNS},
+		Spec:       corev1beta1.ScopeDefinitionSpec{Reference: common.DefinitionReference{Name: "healthscopes.core.oam.dev"}},
+	}
+	scalerTrait := func(replicas int) runtime.RawExtension {
+		return util.Object2RawExtension(map[string]interface{}{
+			"apiVersion": "core.oam.dev/v1alpha2",
+			"kind":       "ManualScalerTrait",
+			"spec":       map[string]interface{}{"replicaCount": replicas},
+		})
+	}
+	ac := &v1alpha2.ApplicationConfiguration{
+		ObjectMeta: metav1.ObjectMeta{Name: "shop", Namespace: "default", UID: "ac-uid",
+			Annotations: map[string]string{"team": "shop"}},
+		Spec: v1alpha2.ApplicationConfigurationSpec{Components: []v1alpha2.ApplicationConfigurationComponent{{
+			ComponentName:   "backend",
+			ParameterValues: []v1alpha2.ComponentParameterValue{{Name: "image", Value: intstr.FromString("busybox:2")}},
+			Traits:          []v1alpha2.ComponentTrait{{Trait: scalerTrait(2)}},
+			Scopes: []v1alpha2.ComponentScope{{ScopeReference: runtimev1alpha1.TypedReference{
+				APIVersion: "core.oam.dev/v1alpha2", Kind: "HealthScope", Name: "health"}}},
+		}, {
+			ComponentName: "frontend",
+			DataOutputs:   []v1alpha2.DataOutput{{Name: "ready", FieldPath: "status.readyReplicas"}},
+			Scopes: []v1alpha2.ComponentScope{{ScopeReference: runtimev1alpha1.TypedReference{
+				APIVersion: "apps/v1", Kind: "Deployment", Name: "unknown"}}},
+		}}},
+		Status: v1alpha2.ApplicationConfigurationStatus{Workloads: []v1alpha2.WorkloadStatus{{
+			ComponentName: "backend",
+			Reference:     runtimev1alpha1.TypedReference{APIVersion: "apps/v1", Kind: "Deployment", Name: "backend"},
+			Traits: []v1alpha2.WorkloadTrait{{Reference: runtimev1alpha1.TypedReference{
+				APIVersion: "core.oam.dev/v1alpha2", Kind: "ManualScalerTrait", Name: "backend-scaler"}}},
+		}, {
+			ComponentName: "frontend",
+			Reference:     runtimev1alpha1.TypedReference{APIVersion: "apps/v1", Kind: "Deployment", Name: "frontend-v1"},
+		}}},
+	}
+	c := fake.NewFakeClientWithScheme(scheme, backend, frontend, legacyScaler, healthScope,
+		loadBuiltinDefinition(t, "webservice", &corev1beta1.ComponentDefinition{}),
+		loadBuiltinDefinition(t, "worker", &corev1beta1.ComponentDefinition{}),
+		loadBuiltinDefinition(t, "scaler", &corev1beta1.TraitDefinition{}))
+	ctx := context.Background()
+
+	m, err := MigrateAppConfig(ctx, c, dm, pd, ac)
+	assert.NilError(t, err)
+	app := m.Application
+	assert.Equal(t, app.Name, "shop")
+	assert.DeepEqual(t, app.Annotations, map[string]string{"team": "shop", oam.AnnotationAdoptResources: "true"})
+	assert.Equal(t, len(app.Spec.Components), 2)
+
+	properties := func(raw runtime.RawExtension) map[string]interface{} {
+		var p map[string]interface{}
+		assert.NilError(t, json.Unmarshal(raw.Raw, &p))
+		return p
+	}
+	comp := app.Spec.Components[0]
+	assert.Equal(t, comp.Name, "backend")
+	assert.Equal(t, comp.Type, "worker")
+	assert.DeepEqual(t, properties(comp.Properties), map[string]interface{}{"image": "busybox:2"})
+	assert.DeepEqual(t, comp.Scopes, map[string]string{"healthscopes.core.oam.dev": "health"})
+	assert.Equal(t, len(comp.Traits), 1)
+	assert.Equal(t, comp.Traits[0].Type, "manualscalertrait")
+	assert.DeepEqual(t, properties(comp.Traits[0].Properties), map[string]interface{}{"replicaCount": float64(2)})
+
+	comp = app.Spec.Components[1]
+	assert.Equal(t, comp.Name, "frontend-v1")
+	assert.Equal(t, comp.Type, "frontend")
+	assert.DeepEqual(t, properties(comp.Properties), map[string]interface{}{})
+
+	assert.Equal(t, len(m.ComponentDefinitions), 1)
+	cd := m.ComponentDefinitions[0]
+	assert.Equal(t, cd.Name, "frontend")
+	assert.Equal(t, cd.Namespace, "default")
+	assert.DeepEqual(t, cd.Spec.Workload.Definition, common.WorkloadGVK{APIVersion: "apps/v1", Kind: "Deployment"})
+	assert.Equal(t, len(cd.Spec.Schematic.KUBE.Parameters), 1)
+	assert.Equal(t, cd.Spec.Schematic.KUBE.Parameters[0].ValueType, common.StringType)
+	assert.Equal(t, len(m.TraitDefinitions), 1)
+	td := m.TraitDefinitions[0]
+	assert.Equal(t, td.Name, "manualscalertrait")
+	assert.Equal(t, td.Spec.WorkloadRefPath, "spec.workloadRef")
+	// the trait keeps the name given by the AppConfig, it's verified by rendering without warnings
+	assert.Assert(t, strings.Contains(td.Spec.Schematic.CUE.Template, `metadata: name: "backend-scaler"`))
+
+	assert.DeepEqual(t, m.Warnings, []string{
+		"dataInputs and dataOutputs of component frontend are dropped, the fields filled by dataInputs are no longer set",
+		"component frontend is named frontend-v1 after its workload to adopt it",
+		"scope Deployment unknown of component frontend is dropped, no ScopeDefinition is found for it",
+	})
+
+	// the generated definitions are reused by the AppConfigs migrated later
+	assert.NilError(t, c.Create(ctx, cd))
+	assert.NilError(t, c.Create(ctx, td))
+	another := &v1alpha2.ApplicationConfiguration{
+		ObjectMeta: metav1.ObjectMeta{Name: "another", Namespace: "default"},
+		Spec: v1alpha2.ApplicationConfigurationSpec{Components: []v1alpha2.ApplicationConfigurationComponent{{
+			ComponentName:   "frontend",
+			ParameterValues: []v1alpha2.ComponentParameterValue{{Name: "image", Value: intstr.FromString("busybox:3")}},
+			Traits:          []v1alpha2.ComponentTrait{{Trait: scalerTrait(3)}},
+		}}},
+	}
+	m, err = MigrateAppConfig(ctx, c, dm, pd, another)
+	assert.NilError(t, err)
+	assert.Equal(t, len(m.ComponentDefinitions), 0)
+	assert.Equal(t, len(m.Warnings), 0)
+	comp = m.Application.Spec.Components[0]
+	assert.Equal(t, comp.Type, "frontend")
+	assert.DeepEqual(t, properties(comp.Properties), map[string]interface{}{"image": "busybox:3"})
+	// the generated TraitDefinition names the trait backend-scaler, it's not reused by a trait of another name
+	assert.Equal(t, len(m.TraitDefinitions), 1)
+	assert.Equal(t, comp.Traits[0].Type, "manualscalertrait-migrated")
+
+	ac.OwnerReferences = []metav1.OwnerReference{{APIVersion: corev1beta1.SchemeGroupVersion.String(),
+		Kind: corev1beta1.ApplicationKind, Name: "shop", Controller: pointer.BoolPtr(true)}}
+	_, err = MigrateAppConfig(ctx, c, dm, pd, ac)
+	assert.ErrorContains(t, err, "generated by application shop")
+}
+
+func TestAppConfigHandedOver(t *testing.T) {
+	scheme := runtime.NewScheme()
+	assert.NilError(t, clientgoscheme.AddToScheme(scheme))
+	ac := &v1alpha2.ApplicationConfiguration{
+		ObjectMeta: metav1.ObjectMeta{Name: "shop", Namespace: "default", UID: "ac-uid"},
+		Status: v1alpha2.ApplicationConfigurationStatus{Workloads: []v1alpha2.WorkloadStatus{{
+			Reference: runtimev1alpha1.TypedReference{APIVersion: "apps/v1", Kind: "Deployment", Name: "backend"},
+			Traits: []v1alpha2.WorkloadTrait{{Reference: runtimev1alpha1.TypedReference{
+				APIVersion: "autoscaling/v1", Kind: "HorizontalPodAutoscaler", Name: "backend-hpa"}}},
+		}}},
+	}
+	ownedBy := func(uid string) metav1.ObjectMeta {
+		return metav1.ObjectMeta{Namespace: "default",
+			OwnerReferences: []metav1.OwnerReference{{Name: "owner", UID: ktypes.UID(uid), Controller: pointer.BoolPtr(true)}}}
+	}
+	workload := func(uid string) *appsv1.Deployment {
+		d := &appsv1.Deployment{ObjectMeta: ownedBy(uid)}
+		d.Name = "backend"
+		return d
+	}
+	trait := func(uid string) *autoscalingv1.HorizontalPodAutoscaler {
+		hpa := &autoscalingv1.HorizontalPodAutoscaler{ObjectMeta: ownedBy(uid)}
+		hpa.Name = "backend-hpa"
+		return hpa
+	}
+	ctx := context.Background()
+
+	testCases := map[string]struct {
+		objs       []runtime.Object
+		handedOver bool
+	}{
+		"NotFound":               {objs: nil, handedOver: false},
+		"WorkloadControlledByAC": {objs: []runtime.Object{workload("ac-uid"), trait("app-uid")}, handedOver: false},
+		"TraitNotFound":          {objs: []runtime.Object{workload("app-uid")}, handedOver: false},
+		"TraitControlledByAC":    {objs: []runtime.Object{workload("app-uid"), trait("ac-uid")}, handedOver: false},
+		"AllControlledByOthers":  {objs: []runtime.Object{workload("app-uid"), trait("app-uid")}, handedOver: true},
+	}
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			handedOver, err := AppConfigHandedOver(ctx, fake.NewFakeClientWithScheme(scheme, tc.objs...), ac)
+			assert.NilError(t, err)
+			assert.Equal(t, handedOver, tc.handedOver)
+		})
+	}
+}
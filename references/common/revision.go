@@ -0,0 +1,206 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package common
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	corev1beta1 "github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
+	"github.com/oam-dev/kubevela/pkg/oam"
+	oamutil "github.com/oam-dev/kubevela/pkg/oam/util"
+	"github.com/oam-dev/kubevela/references/apiserver/apis"
+	"github.com/oam-dev/kubevela/references/appfile/dryrun"
+)
+
+// ListRevisions lists all the revisions of an application, ordered by revision number
+func ListRevisions(ctx context.Context, c client.Reader, appName, namespace string) ([]apis.RevisionMeta, error) {
+	app := new(corev1beta1.Application)
+	if err := c.Get(ctx, client.ObjectKey{Name: appName, Namespace: namespace}, app); err != nil {
+		return nil, err
+	}
+	var latest string
+	if app.Status.LatestRevision != nil {
+		latest = app.Status.LatestRevision.Name
+	}
+	revs := new(corev1beta1.ApplicationRevisionList)
+	if err := c.List(ctx, revs, client.InNamespace(namespace), client.MatchingLabels{oam.LabelAppName: appName}); err != nil {
+		return nil, err
+	}
+	revisions := make([]apis.RevisionMeta, 0, len(revs.Items))
+	for _, rev := range revs.Items {
+		num, _ := oamutil.ExtractRevisionNum(rev.Name, "-")
+		meta := apis.RevisionMeta{
+			Name:        rev.Name,
+			Revision:    num,
+			Hash:        rev.GetLabels()[oam.LabelAppRevisionHash],
+			Latest:      rev.Name == latest,
+			CreatedTime: rev.CreationTimestamp.String(),
+			Change:      rev.Spec.Change,
+		}
+		for _, comp := range rev.Spec.Application.Spec.Components {
+			meta.Components = append(meta.Components, comp.Name)
+		}
+		revisions = append(revisions, meta)
+	}
+	sort.Slice(revisions, func(i, j int) bool {
+		return revisions[i].Revision < revisions[j].Revision
+	})
+	return revisions, nil
+}
+
+// SummarizeChange describes the changes of a revision in one line, e.g. "web: properties.image; +db; -cache"
+func SummarizeChange(change *corev1beta1.RevisionChange) string {
+	if change == nil {
+		return ""
+	}
+	var items []string
+	for _, comp := range change.Components {
+		switch comp.Action {
+		case corev1beta1.ComponentAdded:
+			items = append(items, "+"+comp.Name)
+		case corev1beta1.ComponentRemoved:
+			items = append(items, "-"+comp.Name)
+		default:
+			items = append(items, fmt.Sprintf("%s: %s", comp.Name, strings.Join(comp.Fields, ",")))
+		}
+	}
+	items = append(items, change.Definitions...)
+	return strings.Join(items, "; ")
+}
+
+// GetRevision gets a revision of an application.
+// The revision could be specified by its name, or by its number such as `3` or `v3`.
+// The latest revision is returned if the revision is empty.
+func GetRevision(ctx context.Context, c client.Reader, appName, namespace, revision string) (*corev1beta1.ApplicationRevision, error) {
+	if revision == "" {
+		app := new(corev1beta1.Application)
+		if err := c.Get(ctx, client.ObjectKey{Name: appName, Namespace: namespace}, app); err != nil {
+			return nil, err
+		}
+		if app.Status.LatestRevision == nil {
+			return nil, fmt.Errorf("the application %q has no revision in the cluster", appName)
+		}
+		revision = app.Status.LatestRevision.Name
+	}
+	name := RevisionName(appName, revision)
+	rev := new(corev1beta1.ApplicationRevision)
+	if err := c.Get(ctx, client.ObjectKey{Name: name, Namespace: namespace}, rev); err != nil {
+		return nil, errors.Wrapf(err, "cannot get application revision %q", name)
+	}
+	if owner := rev.GetLabels()[oam.LabelAppName]; owner != appName {
+		return nil, fmt.Errorf("revision %q doesn't belong to application %q", name, appName)
+	}
+	return rev, nil
+}
+
+// RevisionName returns the full name of a revision, the revision could be a full name or a number such as `3` or `v3`
+func RevisionName(appName, revision string) string {
+	if _, err := strconv.Atoi(strings.TrimPrefix(revision, "v")); err == nil {
+		return fmt.Sprintf("%s-v%s", appName, strings.TrimPrefix(revision, "v"))
+	}
+	return revision
+}
+
+// DiffRevisions compares two revisions of an application, the target is the latest revision if it's empty.
+// The diffContext is the number of unchanged lines shown around changes in the report, -1 shows all lines.
+func DiffRevisions(ctx context.Context, c client.Reader, appName, namespace, base, target string, diffContext int) (*apis.RevisionDiff, error) {
+	baseRev, err := GetRevision(ctx, c, appName, namespace, base)
+	if err != nil {
+		return nil, err
+	}
+	targetRev, err := GetRevision(ctx, c, appName, namespace, target)
+	if err != nil {
+		return nil, err
+	}
+	entry, err := dryrun.DiffRevisions(baseRev, targetRev)
+	if err != nil {
+		return nil, errors.WithMessage(err, "cannot calculate diff")
+	}
+	var buff bytes.Buffer
+	dryrun.NewReportDiffOption(diffContext, &buff).PrintDiffReport(entry)
+	return &apis.RevisionDiff{
+		Base:    baseRev.Name,
+		Target:  targetRev.Name,
+		Changed: hasDiff(entry),
+		Report:  buff.String(),
+	}, nil
+}
+
+// EstimateRevisions estimates the resources of the base and target revisions of an application, the cost is
+// calculated if the price table is not nil
+func EstimateRevisions(ctx context.Context, c client.Reader, appName, namespace, base, target string,
+	prices *dryrun.PriceTable) (*dryrun.Estimate, *dryrun.Estimate, error) {
+	baseRev, err := GetRevision(ctx, c, appName, namespace, base)
+	if err != nil {
+		return nil, nil, err
+	}
+	targetRev, err := GetRevision(ctx, c, appName, namespace, target)
+	if err != nil {
+		return nil, nil, err
+	}
+	baseEstimate, err := dryrun.EstimateAppRevision(baseRev, prices)
+	if err != nil {
+		return nil, nil, errors.WithMessagef(err, "cannot estimate resources of revision %s", baseRev.Name)
+	}
+	targetEstimate, err := dryrun.EstimateAppRevision(targetRev, prices)
+	if err != nil {
+		return nil, nil, errors.WithMessagef(err, "cannot estimate resources of revision %s", targetRev.Name)
+	}
+	return baseEstimate, targetEstimate, nil
+}
+
+func hasDiff(entry *dryrun.DiffEntry) bool {
+	if entry.DiffType != dryrun.NoDiff {
+		return true
+	}
+	for _, sub := range entry.Subs {
+		if hasDiff(sub) {
+			return true
+		}
+	}
+	return false
+}
+
+// RollbackApplication restores the spec of an application from one of its revisions,
+// the controller will then create a new revision with the restored spec.
+func RollbackApplication(ctx context.Context, c client.Client, appName, namespace, revision string) (*corev1beta1.ApplicationRevision, error) {
+	rev, err := GetRevision(ctx, c, appName, namespace, revision)
+	if err != nil {
+		return nil, err
+	}
+	app := new(corev1beta1.Application)
+	if err := c.Get(ctx, client.ObjectKey{Name: appName, Namespace: namespace}, app); err != nil {
+		return nil, err
+	}
+	// the rollout plan is not recorded in revisions, keep the current one
+	rolloutPlan := app.Spec.RolloutPlan
+	app.Spec = *rev.Spec.Application.Spec.DeepCopy()
+	app.Spec.RolloutPlan = rolloutPlan
+	oamutil.AddAnnotations(app, map[string]string{oam.AnnotationChangeMessage: "rollback to revision " + rev.Name})
+	if err := c.Update(ctx, app); err != nil {
+		return nil, errors.Wrapf(err, "cannot rollback application %q", appName)
+	}
+	return rev, nil
+}
@@ -0,0 +1,138 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package common
+
+import (
+	"context"
+	"testing"
+
+	"gotest.tools/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	commontypes "github.com/oam-dev/kubevela/apis/core.oam.dev/common"
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1alpha2"
+	corev1beta1 "github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
+	"github.com/oam-dev/kubevela/pkg/oam"
+	"github.com/oam-dev/kubevela/pkg/oam/util"
+)
+
+func newRevision(name, image string) *corev1beta1.ApplicationRevision {
+	comp := &v1alpha2.Component{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "core.oam.dev/v1alpha2", Kind: "Component"},
+		ObjectMeta: metav1.ObjectMeta{Name: "web"},
+		Spec: v1alpha2.ComponentSpec{Workload: runtime.RawExtension{
+			Raw: []byte(`{"apiVersion":"apps/v1","kind":"Deployment","spec":{"image":"` + image + `"}}`),
+		}},
+	}
+	ac := &v1alpha2.ApplicationConfiguration{
+		TypeMeta: metav1.TypeMeta{APIVersion: "core.oam.dev/v1alpha2", Kind: "ApplicationConfiguration"},
+		Spec: v1alpha2.ApplicationConfigurationSpec{Components: []v1alpha2.ApplicationConfigurationComponent{
+			{ComponentName: "web"},
+		}},
+	}
+	return &corev1beta1.ApplicationRevision{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", Labels: map[string]string{oam.LabelAppName: "myapp"}},
+		Spec: corev1beta1.ApplicationRevisionSpec{
+			Application: corev1beta1.Application{Spec: corev1beta1.ApplicationSpec{Components: []corev1beta1.ApplicationComponent{{
+				Name:       "web",
+				Type:       "webservice",
+				Properties: runtime.RawExtension{Raw: []byte(`{"image":"` + image + `"}`)},
+			}}}},
+			Components:               []commontypes.RawComponent{{Raw: util.Object2RawExtension(comp)}},
+			ApplicationConfiguration: util.Object2RawExtension(ac),
+		},
+	}
+}
+
+func TestRevisions(t *testing.T) {
+	scheme := runtime.NewScheme()
+	assert.NilError(t, corev1beta1.SchemeBuilder.AddToScheme(scheme))
+	app := &corev1beta1.Application{
+		ObjectMeta: metav1.ObjectMeta{Name: "myapp", Namespace: "default"},
+		Spec: corev1beta1.ApplicationSpec{Components: []corev1beta1.ApplicationComponent{{
+			Name:       "web",
+			Type:       "webservice",
+			Properties: runtime.RawExtension{Raw: []byte(`{"image":"nginx:3"}`)},
+		}}},
+		Status: commontypes.AppStatus{LatestRevision: &commontypes.Revision{Name: "myapp-v2", Revision: 2}},
+	}
+	other := newRevision("other-v1", "busybox")
+	other.Labels[oam.LabelAppName] = "other"
+	rev2 := newRevision("myapp-v2", "nginx:2")
+	rev2.Spec.Change = &corev1beta1.RevisionChange{User: "alice", PreviousRevision: "myapp-v1"}
+	c := fake.NewFakeClientWithScheme(scheme, app, rev2, newRevision("myapp-v1", "nginx:1"), other)
+	ctx := context.Background()
+
+	revisions, err := ListRevisions(ctx, c, "myapp", "default")
+	assert.NilError(t, err)
+	assert.Equal(t, 2, len(revisions))
+	assert.Equal(t, "myapp-v1", revisions[0].Name)
+	assert.Equal(t, 1, revisions[0].Revision)
+	assert.Equal(t, false, revisions[0].Latest)
+	assert.Equal(t, "myapp-v2", revisions[1].Name)
+	assert.Equal(t, true, revisions[1].Latest)
+	assert.DeepEqual(t, []string{"web"}, revisions[1].Components)
+	assert.Equal(t, "alice", revisions[1].Change.User)
+
+	assert.Equal(t, "myapp-v3", RevisionName("myapp", "3"))
+	assert.Equal(t, "myapp-v3", RevisionName("myapp", "v3"))
+	assert.Equal(t, "myapp-v3", RevisionName("myapp", "myapp-v3"))
+
+	_, err = GetRevision(ctx, c, "myapp", "default", "other-v1")
+	assert.ErrorContains(t, err, "doesn't belong to")
+
+	diff, err := DiffRevisions(ctx, c, "myapp", "default", "1", "", -1)
+	assert.NilError(t, err)
+	assert.Equal(t, "myapp-v1", diff.Base)
+	assert.Equal(t, "myapp-v2", diff.Target)
+	assert.Equal(t, true, diff.Changed)
+	diff, err = DiffRevisions(ctx, c, "myapp", "default", "v2", "myapp-v2", -1)
+	assert.NilError(t, err)
+	assert.Equal(t, false, diff.Changed)
+
+	baseEstimate, targetEstimate, err := EstimateRevisions(ctx, c, "myapp", "default", "1", "", nil)
+	assert.NilError(t, err)
+	assert.Equal(t, 1, len(baseEstimate.Components))
+	assert.Equal(t, "web", targetEstimate.Components[0].Name)
+	// the workload has no pod template, so no pods are counted
+	assert.Equal(t, int32(0), targetEstimate.Total.Replicas)
+
+	rev, err := RollbackApplication(ctx, c, "myapp", "default", "v1")
+	assert.NilError(t, err)
+	assert.Equal(t, "myapp-v1", rev.Name)
+	got := new(corev1beta1.Application)
+	assert.NilError(t, c.Get(ctx, client.ObjectKey{Name: "myapp", Namespace: "default"}, got))
+	assert.Equal(t, `{"image":"nginx:1"}`, string(got.Spec.Components[0].Properties.Raw))
+	assert.Equal(t, "rollback to revision myapp-v1", got.Annotations[oam.AnnotationChangeMessage])
+}
+
+func TestSummarizeChange(t *testing.T) {
+	assert.Equal(t, "", SummarizeChange(nil))
+	change := &corev1beta1.RevisionChange{
+		User: "alice",
+		Components: []corev1beta1.ComponentChange{
+			{Name: "web", Action: corev1beta1.ComponentModified, Fields: []string{"properties.image", "traits.scaler"}},
+			{Name: "db", Action: corev1beta1.ComponentAdded},
+			{Name: "cache", Action: corev1beta1.ComponentRemoved},
+		},
+		Definitions: []string{"traitDefinition/scaler"},
+	}
+	assert.Equal(t, "web: properties.image,traits.scaler; +db; -cache; traitDefinition/scaler", SummarizeChange(change))
+}
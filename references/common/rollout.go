@@ -0,0 +1,234 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package common
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	corev1beta1 "github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
+	"github.com/oam-dev/kubevela/apis/standard.oam.dev/v1alpha1"
+	"github.com/oam-dev/kubevela/pkg/oam"
+	oamutil "github.com/oam-dev/kubevela/pkg/oam/util"
+	"github.com/oam-dev/kubevela/references/apiserver/apis"
+)
+
+// Batch states of a rollout besides the batch rolling states
+const (
+	BatchStateReady              = "ready"
+	BatchStatePending            = "pending"
+	BatchStateWaitingForApproval = "waitingForApproval"
+	BatchStateWaitingForWindow   = "waitingForWindow"
+)
+
+// GetRollout gets an AppRollout
+func GetRollout(ctx context.Context, c client.Reader, name, namespace string) (*corev1beta1.AppRollout, error) {
+	rollout := new(corev1beta1.AppRollout)
+	if err := c.Get(ctx, client.ObjectKey{Name: name, Namespace: namespace}, rollout); err != nil {
+		return nil, errors.Wrapf(err, "cannot get rollout %q", name)
+	}
+	return rollout, nil
+}
+
+// GetRolloutStatus summarizes the status of a rollout and its batches
+func GetRolloutStatus(ctx context.Context, c client.Reader, name, namespace string) (*apis.RolloutStatus, error) {
+	rollout, err := GetRollout(ctx, c, name, namespace)
+	if err != nil {
+		return nil, err
+	}
+	return RolloutStatusOf(rollout), nil
+}
+
+// RolloutStatusOf summarizes the status of a rollout and its batches
+func RolloutStatusOf(rollout *corev1beta1.AppRollout) *apis.RolloutStatus {
+	plan, status := rollout.Spec.RolloutPlan, rollout.Status
+	result := &apis.RolloutStatus{
+		Name:              rollout.Name,
+		SourceRevision:    rollout.Spec.SourceAppRevisionName,
+		TargetRevision:    rollout.Spec.TargetAppRevisionName,
+		RollingState:      string(status.RollingState),
+		BatchRollingState: string(status.BatchRollingState),
+		CurrentBatch:      status.CurrentBatch,
+		BatchPartition:    plan.BatchPartition,
+		Paused:            plan.Paused,
+		TargetSize:        status.RolloutTargetSize,
+		Upgraded:          status.UpgradedReplicas,
+		Ready:             status.UpgradedReadyReplicas,
+		BatchReady:        BatchReady(rollout),
+	}
+	if cond := status.GetCondition(v1alpha1.BatchWaitingForWindow); cond.Status == corev1.ConditionTrue &&
+		status.BatchRollingState == v1alpha1.BatchInitializingState {
+		result.WaitingForWindow = cond.Message
+	}
+	approvers := make(map[int32]string, len(status.BatchApprovals))
+	for _, approval := range status.BatchApprovals {
+		approvers[approval.Batch] = approval.Approver
+	}
+	for i, batch := range plan.RolloutBatches {
+		index := int32(i)
+		batchStatus := apis.RolloutBatchStatus{
+			Index:           index,
+			Replicas:        batch.Replicas.String(),
+			RequireApproval: batch.RequireApproval,
+			Approver:        approvers[index],
+		}
+		if len(batch.PodList) != 0 {
+			batchStatus.Replicas = fmt.Sprintf("%d pods", len(batch.PodList))
+		}
+		switch {
+		case status.RollingState == v1alpha1.RolloutSucceedState, index < status.CurrentBatch,
+			index == status.CurrentBatch && status.BatchRollingState == v1alpha1.BatchReadyState:
+			batchStatus.State = BatchStateReady
+		case index > status.CurrentBatch:
+			batchStatus.State = BatchStatePending
+		case result.WaitingForWindow != "":
+			batchStatus.State = BatchStateWaitingForWindow
+		case plan.WaitingForApproval(index):
+			batchStatus.State = BatchStateWaitingForApproval
+		default:
+			batchStatus.State = string(status.BatchRollingState)
+		}
+		result.Batches = append(result.Batches, batchStatus)
+	}
+	return result
+}
+
+// BatchReady returns true if the current batch of a rollout is rolled out and ready, or it's waiting for approval
+func BatchReady(rollout *corev1beta1.AppRollout) bool {
+	status := rollout.Status
+	if status.RollingState != v1alpha1.RollingInBatchesState {
+		return false
+	}
+	return status.BatchRollingState == v1alpha1.BatchReadyState ||
+		(status.BatchRollingState == v1alpha1.BatchInitializingState &&
+			rollout.Spec.RolloutPlan.WaitingForApproval(status.CurrentBatch))
+}
+
+// checkRolloutInProgress returns an error if a rollout is finished or being finalized
+func checkRolloutInProgress(rollout *corev1beta1.AppRollout) error {
+	switch state := rollout.Status.RollingState; state {
+	case v1alpha1.RolloutSucceedState, v1alpha1.RolloutFailedState, v1alpha1.RolloutFailingState,
+		v1alpha1.RolloutAbandoningState, v1alpha1.RolloutDeletingState:
+		return fmt.Errorf("rollout %s is not in progress, its state is %s", rollout.Name, state)
+	default:
+		return nil
+	}
+}
+
+// PauseRollout pauses or resumes a rollout
+func PauseRollout(ctx context.Context, c client.Client, name, namespace string, paused bool) error {
+	rollout, err := GetRollout(ctx, c, name, namespace)
+	if err != nil {
+		return err
+	}
+	if err := checkRolloutInProgress(rollout); err != nil {
+		return err
+	}
+	rollout.Spec.RolloutPlan.Paused = paused
+	return c.Update(ctx, rollout)
+}
+
+// NextBatchToApprove returns the next batch to approve of a rollout. It's the one after the batch partition,
+// or the first batch waiting for approval if the rollout has no batch partition.
+func NextBatchToApprove(rollout *corev1beta1.AppRollout) (int32, error) {
+	plan := rollout.Spec.RolloutPlan
+	lastBatch := int32(len(plan.RolloutBatches) - 1)
+	if plan.BatchPartition != nil {
+		if *plan.BatchPartition >= lastBatch {
+			return 0, fmt.Errorf("all batches of rollout %s are approved", rollout.Name)
+		}
+		return *plan.BatchPartition + 1, nil
+	}
+	for batch := rollout.Status.CurrentBatch; batch <= lastBatch; batch++ {
+		if plan.WaitingForApproval(batch) {
+			return batch, nil
+		}
+	}
+	return 0, fmt.Errorf("no batch of rollout %s is waiting for approval", rollout.Name)
+}
+
+// ApproveNextBatch advances the batch partition of a rollout to the next batch. The current batch must be ready
+// unless force is true. A rollout without batch partition is approved until the next batch requiring approval.
+func ApproveNextBatch(ctx context.Context, c client.Client, name, namespace string, force bool) (int32, error) {
+	rollout, err := GetRollout(ctx, c, name, namespace)
+	if err != nil {
+		return 0, err
+	}
+	if err := checkRolloutInProgress(rollout); err != nil {
+		return 0, err
+	}
+	batch, err := NextBatchToApprove(rollout)
+	if err != nil {
+		return 0, err
+	}
+	if !force && !BatchReady(rollout) {
+		return 0, fmt.Errorf("batch %d of rollout %s is not ready, its state is %s/%s", rollout.Status.CurrentBatch,
+			name, rollout.Status.RollingState, rollout.Status.BatchRollingState)
+	}
+	plan := &rollout.Spec.RolloutPlan
+	partition := batch
+	if plan.BatchPartition == nil {
+		for int(partition)+1 < len(plan.RolloutBatches) && !plan.RolloutBatches[partition+1].RequireApproval {
+			partition++
+		}
+	}
+	plan.BatchPartition = &partition
+	if err := c.Update(ctx, rollout); err != nil {
+		return 0, errors.Wrapf(err, "cannot approve batch %d of rollout %s", batch, name)
+	}
+	return batch, nil
+}
+
+// AbortRollout stops a rollout in progress, the rollout is finalized as failed without reverting the upgraded batches
+func AbortRollout(ctx context.Context, c client.Client, name, namespace string) error {
+	rollout, err := GetRollout(ctx, c, name, namespace)
+	if err != nil {
+		return err
+	}
+	if err := checkRolloutInProgress(rollout); err != nil {
+		return err
+	}
+	oamutil.AddAnnotations(rollout, map[string]string{oam.AnnotationAbortedTarget: rollout.Spec.TargetAppRevisionName})
+	return c.Update(ctx, rollout)
+}
+
+// RollbackRollout rolls back a rollout to its source revision by swapping the source and the target.
+// The rollback is neither paused nor held by the batch partition.
+func RollbackRollout(ctx context.Context, c client.Client, name, namespace string) (*corev1beta1.AppRollout, error) {
+	rollout, err := GetRollout(ctx, c, name, namespace)
+	if err != nil {
+		return nil, err
+	}
+	if rollout.Spec.SourceAppRevisionName == "" {
+		return nil, fmt.Errorf("rollout %s has no source revision to rollback to", name)
+	}
+	spec := &rollout.Spec
+	spec.SourceAppRevisionName, spec.TargetAppRevisionName = spec.TargetAppRevisionName, spec.SourceAppRevisionName
+	spec.RolloutPlan.Paused = false
+	spec.RolloutPlan.BatchPartition = nil
+	if lastBatch := int32(len(spec.RolloutPlan.RolloutBatches) - 1); lastBatch >= 0 {
+		spec.RolloutPlan.BatchPartition = &lastBatch
+	}
+	if err := c.Update(ctx, rollout); err != nil {
+		return nil, errors.Wrapf(err, "cannot rollback rollout %s", name)
+	}
+	return rollout, nil
+}
@@ -0,0 +1,189 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package common
+
+import (
+	"context"
+	"testing"
+
+	"gotest.tools/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	commontypes "github.com/oam-dev/kubevela/apis/core.oam.dev/common"
+	corev1beta1 "github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
+	"github.com/oam-dev/kubevela/apis/standard.oam.dev/v1alpha1"
+	"github.com/oam-dev/kubevela/pkg/oam"
+)
+
+func newRollout(partition *int32, requireApproval ...bool) *corev1beta1.AppRollout {
+	rollout := &corev1beta1.AppRollout{
+		ObjectMeta: metav1.ObjectMeta{Name: "myapp", Namespace: "default"},
+		Spec: corev1beta1.AppRolloutSpec{
+			SourceAppRevisionName: "myapp-v1",
+			TargetAppRevisionName: "myapp-v2",
+		},
+		Status: commontypes.AppRolloutStatus{
+			RolloutStatus: v1alpha1.RolloutStatus{RollingState: v1alpha1.RollingInBatchesState},
+		},
+	}
+	rollout.Spec.RolloutPlan.BatchPartition = partition
+	for _, require := range requireApproval {
+		rollout.Spec.RolloutPlan.RolloutBatches = append(rollout.Spec.RolloutPlan.RolloutBatches,
+			v1alpha1.RolloutBatch{Replicas: intstr.FromInt(2), RequireApproval: require})
+	}
+	return rollout
+}
+
+func TestRolloutStatusOf(t *testing.T) {
+	rollout := newRollout(nil, false, true, false)
+	rollout.Status.CurrentBatch = 1
+	rollout.Status.BatchRollingState = v1alpha1.BatchInitializingState
+	rollout.Status.BatchApprovals = []v1alpha1.BatchApproval{{Batch: 1, Approver: "alice"}}
+	status := RolloutStatusOf(rollout)
+	assert.Equal(t, status.TargetRevision, "myapp-v2")
+	assert.Equal(t, status.BatchReady, true)
+	assert.Equal(t, len(status.Batches), 3)
+	assert.Equal(t, status.Batches[0].State, BatchStateReady)
+	assert.Equal(t, status.Batches[1].State, BatchStateWaitingForApproval)
+	assert.Equal(t, status.Batches[1].Approver, "alice")
+	assert.Equal(t, status.Batches[2].State, BatchStatePending)
+
+	rollout.Spec.RolloutPlan.BatchPartition = pointer.Int32Ptr(1)
+	status = RolloutStatusOf(rollout)
+	assert.Equal(t, status.BatchReady, false)
+	assert.Equal(t, status.Batches[1].State, string(v1alpha1.BatchInitializingState))
+
+	cond := v1alpha1.NewPositiveCondition(v1alpha1.BatchWaitingForWindow)
+	cond.Message = "waiting for window: outside of the allowed deployment windows"
+	rollout.Status.SetConditions(cond)
+	status = RolloutStatusOf(rollout)
+	assert.Equal(t, status.WaitingForWindow, cond.Message)
+	assert.Equal(t, status.Batches[1].State, BatchStateWaitingForWindow)
+}
+
+func TestApproveNextBatch(t *testing.T) {
+	scheme := runtime.NewScheme()
+	assert.NilError(t, corev1beta1.SchemeBuilder.AddToScheme(scheme))
+	ctx := context.Background()
+
+	testCases := map[string]struct {
+		rollout       *corev1beta1.AppRollout
+		force         bool
+		wantBatch     int32
+		wantPartition int32
+		wantErr       bool
+	}{
+		"advance the batch partition": {
+			rollout: func() *corev1beta1.AppRollout {
+				r := newRollout(pointer.Int32Ptr(0), false, false, false)
+				r.Status.BatchRollingState = v1alpha1.BatchReadyState
+				return r
+			}(),
+			wantBatch:     1,
+			wantPartition: 1,
+		},
+		"approve until the next batch requiring approval": {
+			rollout: func() *corev1beta1.AppRollout {
+				r := newRollout(nil, false, true, false, true)
+				r.Status.CurrentBatch = 1
+				r.Status.BatchRollingState = v1alpha1.BatchInitializingState
+				return r
+			}(),
+			wantBatch:     1,
+			wantPartition: 2,
+		},
+		"current batch not ready": {
+			rollout: func() *corev1beta1.AppRollout {
+				r := newRollout(pointer.Int32Ptr(0), false, false)
+				r.Status.BatchRollingState = v1alpha1.BatchVerifyingState
+				return r
+			}(),
+			wantErr: true,
+		},
+		"force approving": {
+			rollout: func() *corev1beta1.AppRollout {
+				r := newRollout(pointer.Int32Ptr(0), false, false)
+				r.Status.BatchRollingState = v1alpha1.BatchVerifyingState
+				return r
+			}(),
+			force:         true,
+			wantBatch:     1,
+			wantPartition: 1,
+		},
+		"all batches approved": {
+			rollout: func() *corev1beta1.AppRollout {
+				r := newRollout(pointer.Int32Ptr(1), false, false)
+				r.Status.BatchRollingState = v1alpha1.BatchReadyState
+				return r
+			}(),
+			force:   true,
+			wantErr: true,
+		},
+		"rollout finished": {
+			rollout: func() *corev1beta1.AppRollout {
+				r := newRollout(pointer.Int32Ptr(0), false, false)
+				r.Status.RollingState = v1alpha1.RolloutSucceedState
+				return r
+			}(),
+			force:   true,
+			wantErr: true,
+		},
+	}
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			c := fake.NewFakeClientWithScheme(scheme, tc.rollout)
+			batch, err := ApproveNextBatch(ctx, c, "myapp", "default", tc.force)
+			if tc.wantErr {
+				assert.Assert(t, err != nil)
+				return
+			}
+			assert.NilError(t, err)
+			assert.Equal(t, batch, tc.wantBatch)
+			rollout, err := GetRollout(ctx, c, "myapp", "default")
+			assert.NilError(t, err)
+			assert.Equal(t, *rollout.Spec.RolloutPlan.BatchPartition, tc.wantPartition)
+		})
+	}
+}
+
+func TestRolloutOperations(t *testing.T) {
+	scheme := runtime.NewScheme()
+	assert.NilError(t, corev1beta1.SchemeBuilder.AddToScheme(scheme))
+	ctx := context.Background()
+	c := fake.NewFakeClientWithScheme(scheme, newRollout(pointer.Int32Ptr(0), false, false))
+
+	assert.NilError(t, PauseRollout(ctx, c, "myapp", "default", true))
+	rollout, err := GetRollout(ctx, c, "myapp", "default")
+	assert.NilError(t, err)
+	assert.Equal(t, rollout.Spec.RolloutPlan.Paused, true)
+
+	assert.NilError(t, AbortRollout(ctx, c, "myapp", "default"))
+	rollout, err = GetRollout(ctx, c, "myapp", "default")
+	assert.NilError(t, err)
+	assert.Equal(t, rollout.Annotations[oam.AnnotationAbortedTarget], "myapp-v2")
+
+	rollout, err = RollbackRollout(ctx, c, "myapp", "default")
+	assert.NilError(t, err)
+	assert.Equal(t, rollout.Spec.SourceAppRevisionName, "myapp-v2")
+	assert.Equal(t, rollout.Spec.TargetAppRevisionName, "myapp-v1")
+	assert.Equal(t, rollout.Spec.RolloutPlan.Paused, false)
+	assert.Equal(t, *rollout.Spec.RolloutPlan.BatchPartition, int32(1))
+}
@@ -18,7 +18,6 @@ package plugins
 
 import (
 	"context"
-	"encoding/base64"
 	"errors"
 	"fmt"
 	"io/ioutil"
@@ -37,7 +36,6 @@ import (
 	"github.com/oam-dev/kubevela/apis/types"
 	"github.com/oam-dev/kubevela/pkg/oam/discoverymapper"
 	"github.com/oam-dev/kubevela/pkg/oam/util"
-	"github.com/oam-dev/kubevela/pkg/utils/common"
 	"github.com/oam-dev/kubevela/pkg/utils/system"
 )
 
@@ -61,22 +59,50 @@ type CenterClient interface {
 	SyncCapabilityFromCenter() error
 }
 
+// Repository defines the storage of a cap center, capabilities are listed and fetched from it
+type Repository interface {
+	// List lists all the capabilities in the repository
+	List(ctx context.Context) ([]RemoteCapability, error)
+	// Fetch fetches the definition of a capability
+	Fetch(ctx context.Context, capability RemoteCapability) ([]byte, error)
+}
+
 // NewCenterClient create a client from type
 func NewCenterClient(ctx context.Context, name, address, token string) (CenterClient, error) {
 	Type, cfg, err := Parse(address)
 	if err != nil {
 		return nil, err
 	}
+	var repo Repository
 	switch Type {
 	case TypeGithub:
 		return NewGithubCenter(ctx, token, name, cfg)
+	case TypeHTTPIndex:
+		repo = &IndexRepository{IndexURL: address, Token: token}
+	case TypeOCI:
+		repo, err = NewOCIRegistryRepository(address, token)
+	case TypeLocal:
+		repo, err = NewLocalRepository(address)
 	default:
+		return nil, fmt.Errorf("unsupported capability center address %s, it should be a github URL, "+
+			"an HTTP URL of an index file, an OCI reference or a local directory", address)
+	}
+	if err != nil {
+		return nil, err
 	}
-	return nil, errors.New("we only support github as repository now")
+	return &RepositoryCenter{repo: repo, centerName: name, ctx: ctx}, nil
 }
 
-// TypeGithub represents github
-const TypeGithub = "github"
+const (
+	// TypeGithub represents github
+	TypeGithub = "github"
+	// TypeHTTPIndex represents a static index file served over HTTP
+	TypeHTTPIndex = "http"
+	// TypeOCI represents an OCI registry
+	TypeOCI = "oci"
+	// TypeLocal represents a local directory, it could be a plain directory, a directory with an index file or an OCI image layout
+	TypeLocal = "local"
+)
 
 // TypeUnknown represents parse failed
 const TypeUnknown = "unknown"
@@ -87,6 +113,12 @@ func Parse(addr string) (string, *GithubContent, error) {
 	if err != nil {
 		return "", nil, err
 	}
+	switch url.Scheme {
+	case "oci":
+		return TypeOCI, nil, nil
+	case "", "file":
+		return TypeLocal, nil, nil
+	}
 	l := strings.Split(strings.TrimPrefix(url.Path, "/"), "/")
 	switch url.Host {
 	case "github.com":
@@ -127,7 +159,10 @@ func Parse(addr string) (string, *GithubContent, error) {
 			Ref:   url.Query().Get("ref"),
 		}, nil
 	default:
-		// TODO(wonderflow): support raw url and oss format in the future
+		if isIndexFile(url.Path) {
+			// https://<host>/<path>/index.yaml
+			return TypeHTTPIndex, nil, nil
+		}
 	}
 	return TypeUnknown, nil, nil
 }
@@ -140,6 +175,10 @@ type RemoteCapability struct {
 	Sha  string `json:"sha"`
 	// Type MUST be file
 	Type string `json:"type"`
+	// Version is the version of the capability, it's empty if the repository doesn't version capabilities
+	Version string `json:"version,omitempty"`
+	// Digest is the checksum of the definition in the format of <algorithm>:<hex>, it's verified after fetched
+	Digest string `json:"digest,omitempty"`
 }
 
 // RemoteCapabilities is slice of cap center
@@ -212,6 +251,20 @@ func ParseAndSyncCapability(mapper discoverymapper.DiscoveryMapper, data []byte)
 	return types.Capability{}, fmt.Errorf("unknown definition Type %s", obj.GetKind())
 }
 
+// RepositoryCenter implementation of cap center which syncs capabilities from a Repository
+type RepositoryCenter struct {
+	repo       Repository
+	centerName string
+	ctx        context.Context
+}
+
+var _ CenterClient = &RepositoryCenter{}
+
+// SyncCapabilityFromCenter will sync capability from the repository of cap center
+func (r *RepositoryCenter) SyncCapabilityFromCenter() error {
+	return syncCapabilityFromRepository(r.ctx, r.repo, r.centerName)
+}
+
 // GithubCenter implementation of cap center
 type GithubCenter struct {
 	client     *github.Client
@@ -221,6 +274,7 @@ type GithubCenter struct {
 }
 
 var _ CenterClient = &GithubCenter{}
+var _ Repository = &GithubCenter{}
 
 // NewGithubCenter will create client by github center implementation
 func NewGithubCenter(ctx context.Context, token, centerName string, r *GithubContent) (*GithubCenter, error) {
@@ -235,53 +289,40 @@ func NewGithubCenter(ctx context.Context, token, centerName string, r *GithubCon
 }
 
 // SyncCapabilityFromCenter will sync capability from github cap center
-// TODO(wonderflow): currently we only sync by create, we also need to delete which not exist remotely.
 func (g *GithubCenter) SyncCapabilityFromCenter() error {
-	_, dirs, _, err := g.client.Repositories.GetContents(g.ctx, g.cfg.Owner, g.cfg.Repo, g.cfg.Path, &github.RepositoryContentGetOptions{Ref: g.cfg.Ref})
-	if err != nil {
-		return err
-	}
-	dir, err := system.GetCapCenterDir()
-	if err != nil {
-		return err
-	}
-	repoDir := filepath.Join(dir, g.centerName)
-	_, _ = system.CreateIfNotExist(repoDir)
-	c := &common.Args{}
-	dm, err := c.GetDiscoveryMapper()
+	return syncCapabilityFromRepository(g.ctx, g, g.centerName)
+}
+
+// List lists files in the github directory
+func (g *GithubCenter) List(ctx context.Context) ([]RemoteCapability, error) {
+	_, dirs, _, err := g.client.Repositories.GetContents(ctx, g.cfg.Owner, g.cfg.Repo, g.cfg.Path, &github.RepositoryContentGetOptions{Ref: g.cfg.Ref})
 	if err != nil {
-		return err
+		return nil, err
 	}
-	var success, total int
+	var caps []RemoteCapability
 	for _, addon := range dirs {
-		if *addon.Type != "file" {
-			continue
-		}
-		total++
-		fileContent, _, _, err := g.client.Repositories.GetContents(g.ctx, g.cfg.Owner, g.cfg.Repo, *addon.Path, &github.RepositoryContentGetOptions{Ref: g.cfg.Ref})
-		if err != nil {
-			return err
-		}
-		var data = []byte(*fileContent.Content)
-		if *fileContent.Encoding == "base64" {
-			data, err = base64.StdEncoding.DecodeString(*fileContent.Content)
-			if err != nil {
-				return fmt.Errorf("decode github content %s err %w", *fileContent.Path, err)
-			}
-		}
-		tmp, err := ParseAndSyncCapability(dm, data)
-		if err != nil {
-			fmt.Printf("parse definition of %s err %v\n", *fileContent.Name, err)
+		if addon.GetType() != "file" {
 			continue
 		}
-		//nolint:gosec
-		err = ioutil.WriteFile(filepath.Join(repoDir, tmp.Name+".yaml"), data, 0644)
-		if err != nil {
-			fmt.Printf("write definition %s to %s err %v\n", tmp.Name+".yaml", repoDir, err)
-			continue
-		}
-		success++
+		caps = append(caps, RemoteCapability{
+			Name: addon.GetName(),
+			URL:  addon.GetPath(),
+			Sha:  addon.GetSHA(),
+			Type: addon.GetType(),
+		})
+	}
+	return caps, nil
+}
+
+// Fetch fetches the content of a file from github
+func (g *GithubCenter) Fetch(ctx context.Context, capability RemoteCapability) ([]byte, error) {
+	fileContent, _, _, err := g.client.Repositories.GetContents(ctx, g.cfg.Owner, g.cfg.Repo, capability.URL, &github.RepositoryContentGetOptions{Ref: g.cfg.Ref})
+	if err != nil {
+		return nil, err
+	}
+	content, err := fileContent.GetContent()
+	if err != nil {
+		return nil, fmt.Errorf("decode github content %s err %w", fileContent.GetPath(), err)
 	}
-	fmt.Printf("successfully sync %d/%d from %s remote center\n", success, total, g.centerName)
-	return nil
+	return []byte(content), nil
 }
@@ -0,0 +1,318 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package plugins
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	ociLayoutFile = "oci-layout"
+	ociIndexFile  = "index.json"
+
+	ociManifestMediaType = "application/vnd.oci.image.manifest.v1+json"
+
+	// ociAnnotationTitle is the file name of a layer, only layers with a title are treated as definitions
+	ociAnnotationTitle = "org.opencontainers.image.title"
+	// ociAnnotationVersion is the version of a layer or the whole artifact
+	ociAnnotationVersion = "org.opencontainers.image.version"
+	// ociAnnotationRefName is the reference name of a manifest in an image layout
+	ociAnnotationRefName = "org.opencontainers.image.ref.name"
+
+	defaultOCITag = "latest"
+)
+
+type ociDescriptor struct {
+	MediaType   string            `json:"mediaType,omitempty"`
+	Digest      string            `json:"digest"`
+	Size        int64             `json:"size"`
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+type ociManifest struct {
+	SchemaVersion int               `json:"schemaVersion"`
+	Config        ociDescriptor     `json:"config"`
+	Layers        []ociDescriptor   `json:"layers"`
+	Annotations   map[string]string `json:"annotations,omitempty"`
+}
+
+type ociIndex struct {
+	SchemaVersion int             `json:"schemaVersion"`
+	Manifests     []ociDescriptor `json:"manifests"`
+}
+
+// ociStore provides the manifest and blobs of an OCI artifact
+type ociStore interface {
+	// manifest returns the manifest of the artifact and its reference
+	manifest(ctx context.Context) (*ociManifest, string, error)
+	blob(ctx context.Context, digest string) ([]byte, error)
+}
+
+// OCIRepository is a cap center stored as an OCI artifact, every layer with a title annotation is a definition file
+type OCIRepository struct {
+	store ociStore
+}
+
+var _ Repository = &OCIRepository{}
+
+// NewOCIRegistryRepository creates a repository of an artifact in OCI registry, the address is oci://<registry>/<repository>[:<tag>].
+// The token is sent as a bearer token, or used as basic auth if it's in the format of <username>:<password>.
+func NewOCIRegistryRepository(address, token string) (*OCIRepository, error) {
+	ref := strings.TrimPrefix(address, "oci://")
+	slash := strings.Index(ref, "/")
+	if slash <= 0 || slash == len(ref)-1 {
+		return nil, fmt.Errorf("invalid OCI reference %s, it should be oci://<registry>/<repository>[:<tag>]", address)
+	}
+	host, repository, tag := ref[:slash], ref[slash+1:], defaultOCITag
+	if i := strings.LastIndex(repository, ":"); i > 0 {
+		repository, tag = repository[:i], repository[i+1:]
+	}
+	scheme := "https"
+	// registries on localhost are usually served over plain HTTP, same as docker does
+	if hostname := strings.Split(host, ":")[0]; hostname == "localhost" || hostname == "127.0.0.1" {
+		scheme = "http"
+	}
+	return &OCIRepository{store: &ociRegistryStore{
+		endpoint:   scheme + "://" + host,
+		repository: repository,
+		tag:        tag,
+		token:      token,
+	}}, nil
+}
+
+// List lists the layers of the artifact
+func (r *OCIRepository) List(ctx context.Context) ([]RemoteCapability, error) {
+	manifest, ref, err := r.store.manifest(ctx)
+	if err != nil {
+		return nil, err
+	}
+	version := manifest.Annotations[ociAnnotationVersion]
+	if version == "" {
+		version = ref
+	}
+	var caps []RemoteCapability
+	for _, layer := range manifest.Layers {
+		title := layer.Annotations[ociAnnotationTitle]
+		if title == "" {
+			continue
+		}
+		capVersion := layer.Annotations[ociAnnotationVersion]
+		if capVersion == "" {
+			capVersion = version
+		}
+		caps = append(caps, RemoteCapability{
+			Name:    title,
+			URL:     layer.Digest,
+			Type:    "file",
+			Version: capVersion,
+			Digest:  layer.Digest,
+		})
+	}
+	return caps, nil
+}
+
+// Fetch fetches the layer of a capability
+func (r *OCIRepository) Fetch(ctx context.Context, capability RemoteCapability) ([]byte, error) {
+	return r.store.blob(ctx, capability.URL)
+}
+
+// ociLayoutStore reads an artifact from an OCI image layout on disk
+type ociLayoutStore struct {
+	dir string
+	ref string
+}
+
+func (s *ociLayoutStore) manifest(ctx context.Context) (*ociManifest, string, error) {
+	data, err := ioutil.ReadFile(filepath.Clean(filepath.Join(s.dir, ociIndexFile)))
+	if err != nil {
+		return nil, "", err
+	}
+	var index ociIndex
+	if err := json.Unmarshal(data, &index); err != nil {
+		return nil, "", fmt.Errorf("parse OCI index of %s err %w", s.dir, err)
+	}
+	var desc *ociDescriptor
+	for i, m := range index.Manifests {
+		name := m.Annotations[ociAnnotationRefName]
+		if (s.ref != "" && name == s.ref) || (s.ref == "" && (len(index.Manifests) == 1 || name == defaultOCITag)) {
+			desc = &index.Manifests[i]
+			break
+		}
+	}
+	if desc == nil {
+		return nil, "", fmt.Errorf("cannot find manifest %q in OCI image layout %s", s.ref, s.dir)
+	}
+	data, err = s.blob(ctx, desc.Digest)
+	if err != nil {
+		return nil, "", err
+	}
+	if err := verifyDigest(data, desc.Digest); err != nil {
+		return nil, "", err
+	}
+	manifest := &ociManifest{}
+	if err := json.Unmarshal(data, manifest); err != nil {
+		return nil, "", fmt.Errorf("parse OCI manifest %s err %w", desc.Digest, err)
+	}
+	return manifest, desc.Annotations[ociAnnotationRefName], nil
+}
+
+func (s *ociLayoutStore) blob(_ context.Context, digest string) ([]byte, error) {
+	parts := strings.SplitN(digest, ":", 2)
+	if len(parts) != 2 {
+		return nil, fmt.Errorf("invalid digest %s", digest)
+	}
+	return ioutil.ReadFile(filepath.Clean(filepath.Join(s.dir, "blobs", parts[0], parts[1])))
+}
+
+// ociRegistryStore pulls an artifact from an OCI registry through the distribution API
+type ociRegistryStore struct {
+	endpoint   string
+	repository string
+	tag        string
+	token      string
+	// bearer is the token got from the auth service of the registry
+	bearer string
+}
+
+func (s *ociRegistryStore) manifest(ctx context.Context) (*ociManifest, string, error) {
+	data, err := s.get(ctx, fmt.Sprintf("/v2/%s/manifests/%s", s.repository, s.tag), ociManifestMediaType)
+	if err != nil {
+		return nil, "", err
+	}
+	manifest := &ociManifest{}
+	if err := json.Unmarshal(data, manifest); err != nil {
+		return nil, "", fmt.Errorf("parse OCI manifest %s:%s err %w", s.repository, s.tag, err)
+	}
+	return manifest, s.tag, nil
+}
+
+func (s *ociRegistryStore) blob(ctx context.Context, digest string) ([]byte, error) {
+	return s.get(ctx, fmt.Sprintf("/v2/%s/blobs/%s", s.repository, digest), "")
+}
+
+func (s *ociRegistryStore) get(ctx context.Context, path, accept string) ([]byte, error) {
+	resp, err := s.do(ctx, s.endpoint+path, accept)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode == http.StatusUnauthorized && s.bearer == "" {
+		challenge := resp.Header.Get("WWW-Authenticate")
+		_ = resp.Body.Close()
+		if s.bearer, err = s.fetchBearerToken(ctx, challenge); err != nil {
+			return nil, err
+		}
+		if resp, err = s.do(ctx, s.endpoint+path, accept); err != nil {
+			return nil, err
+		}
+	}
+	//nolint:errcheck
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("fetch %s from OCI registry err: %s", path, resp.Status)
+	}
+	return ioutil.ReadAll(resp.Body)
+}
+
+func (s *ociRegistryStore) do(ctx context.Context, location, accept string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, location, nil)
+	if err != nil {
+		return nil, err
+	}
+	if accept != "" {
+		req.Header.Set("Accept", accept)
+	}
+	s.setAuth(req, s.bearer)
+	return capCenterClient.Do(req)
+}
+
+func (s *ociRegistryStore) setAuth(req *http.Request, bearer string) {
+	switch {
+	case bearer != "":
+		req.Header.Set("Authorization", "Bearer "+bearer)
+	case strings.Contains(s.token, ":"):
+		user, password := splitCredential(s.token)
+		req.SetBasicAuth(user, password)
+	case s.token != "":
+		req.Header.Set("Authorization", "Bearer "+s.token)
+	}
+}
+
+// fetchBearerToken gets a token from the auth service specified by the challenge, e.g.
+// Bearer realm="https://auth.docker.io/token",service="registry.docker.io",scope="repository:library/busybox:pull"
+func (s *ociRegistryStore) fetchBearerToken(ctx context.Context, challenge string) (string, error) {
+	if !strings.HasPrefix(challenge, "Bearer ") {
+		return "", fmt.Errorf("unauthorized to access OCI registry %s", s.endpoint)
+	}
+	params := map[string]string{}
+	for _, item := range strings.Split(strings.TrimPrefix(challenge, "Bearer "), ",") {
+		kv := strings.SplitN(strings.TrimSpace(item), "=", 2)
+		if len(kv) == 2 {
+			params[kv[0]] = strings.Trim(kv[1], `"`)
+		}
+	}
+	realm, err := url.Parse(params["realm"])
+	if err != nil || params["realm"] == "" {
+		return "", fmt.Errorf("invalid auth challenge %q of OCI registry %s", challenge, s.endpoint)
+	}
+	query := realm.Query()
+	for _, key := range []string{"service", "scope"} {
+		if params[key] != "" {
+			query.Set(key, params[key])
+		}
+	}
+	realm.RawQuery = query.Encode()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, realm.String(), nil)
+	if err != nil {
+		return "", err
+	}
+	s.setAuth(req, "")
+	resp, err := capCenterClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	//nolint:errcheck
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("get token of OCI registry %s err: %s", s.endpoint, resp.Status)
+	}
+	var token struct {
+		Token       string `json:"token"`
+		AccessToken string `json:"access_token"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&token); err != nil {
+		return "", err
+	}
+	if token.Token != "" {
+		return token.Token, nil
+	}
+	if token.AccessToken != "" {
+		return token.AccessToken, nil
+	}
+	return "", fmt.Errorf("no token is returned by the auth service of OCI registry %s", s.endpoint)
+}
+
+func splitCredential(credential string) (string, string) {
+	parts := strings.SplitN(credential, ":", 2)
+	return parts[0], parts[1]
+}
@@ -0,0 +1,377 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package plugins
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/ghodss/yaml"
+
+	"github.com/oam-dev/kubevela/pkg/oam/discoverymapper"
+	"github.com/oam-dev/kubevela/pkg/utils/common"
+	"github.com/oam-dev/kubevela/pkg/utils/system"
+)
+
+// SyncedIndexFile records the versions and digests of capabilities synced from a cap center,
+// it's stored in the directory of the cap center.
+const SyncedIndexFile = ".index.yaml"
+
+// CapabilityIndex is the index file of a cap center, it lists capabilities with versions and checksums
+type CapabilityIndex struct {
+	Capabilities []CapabilityIndexEntry `json:"capabilities"`
+}
+
+// CapabilityIndexEntry is a capability listed in the index file
+type CapabilityIndexEntry struct {
+	Name    string `json:"name"`
+	Version string `json:"version,omitempty"`
+	// URL is the location of the definition file, a relative URL is resolved against the index file
+	URL string `json:"url"`
+	// Digest is the checksum of the definition file, e.g. sha256:<hex>
+	Digest string `json:"digest,omitempty"`
+}
+
+// IndexRepository is a cap center described by an index file, the index file could be served over HTTP or from a local directory
+type IndexRepository struct {
+	IndexURL string
+	Token    string
+}
+
+var _ Repository = &IndexRepository{}
+
+// List lists the capabilities in the index file
+func (r *IndexRepository) List(ctx context.Context) ([]RemoteCapability, error) {
+	data, err := readLocation(ctx, r.IndexURL, r.Token)
+	if err != nil {
+		return nil, err
+	}
+	var index CapabilityIndex
+	if err := yaml.Unmarshal(data, &index); err != nil {
+		return nil, fmt.Errorf("parse index file %s err %w", r.IndexURL, err)
+	}
+	var caps []RemoteCapability
+	for _, entry := range index.Capabilities {
+		if entry.URL == "" {
+			return nil, fmt.Errorf("url of capability %s is not set in index file %s", entry.Name, r.IndexURL)
+		}
+		location, err := r.resolve(entry.URL)
+		if err != nil {
+			return nil, err
+		}
+		caps = append(caps, RemoteCapability{
+			Name:    path.Base(entry.URL),
+			URL:     location,
+			Type:    "file",
+			Version: entry.Version,
+			Digest:  entry.Digest,
+		})
+	}
+	return caps, nil
+}
+
+// Fetch fetches the definition file of a capability, the token is only sent to the host of the index file
+func (r *IndexRepository) Fetch(ctx context.Context, capability RemoteCapability) ([]byte, error) {
+	if isHTTPURL(r.IndexURL) && !isHTTPURL(capability.URL) {
+		return nil, fmt.Errorf("capability %s of remote index file %s must be fetched over HTTP", capability.URL, r.IndexURL)
+	}
+	token := ""
+	if sameOrigin(r.IndexURL, capability.URL) {
+		token = r.Token
+	}
+	return readLocation(ctx, capability.URL, token)
+}
+
+func (r *IndexRepository) resolve(ref string) (string, error) {
+	refURL, err := url.Parse(ref)
+	if err != nil {
+		return "", err
+	}
+	if !isHTTPURL(r.IndexURL) {
+		if refURL.IsAbs() || filepath.IsAbs(ref) {
+			return ref, nil
+		}
+		return filepath.Join(filepath.Dir(r.IndexURL), filepath.FromSlash(ref)), nil
+	}
+	// a remote index must not point to local files
+	if (refURL.IsAbs() && !isHTTPURL(ref)) || filepath.IsAbs(ref) || strings.HasPrefix(ref, "/") {
+		return "", fmt.Errorf("url %s in remote index file %s must be an HTTP URL or a relative path", ref, r.IndexURL)
+	}
+	base, err := url.Parse(r.IndexURL)
+	if err != nil {
+		return "", err
+	}
+	return base.ResolveReference(refURL).String(), nil
+}
+
+// sameOrigin checks whether both HTTP URLs have the same scheme and host
+func sameOrigin(a, b string) bool {
+	ua, err := url.Parse(a)
+	if err != nil || !isHTTPURL(a) {
+		return false
+	}
+	ub, err := url.Parse(b)
+	if err != nil || !isHTTPURL(b) {
+		return false
+	}
+	return ua.Scheme == ub.Scheme && strings.EqualFold(ua.Host, ub.Host)
+}
+
+// DirectoryRepository is a cap center of a plain local directory, every yaml file in it is a definition
+type DirectoryRepository struct {
+	Dir string
+}
+
+var _ Repository = &DirectoryRepository{}
+
+// List lists the definition files in the directory
+func (r *DirectoryRepository) List(_ context.Context) ([]RemoteCapability, error) {
+	files, err := ioutil.ReadDir(r.Dir)
+	if err != nil {
+		return nil, err
+	}
+	var caps []RemoteCapability
+	for _, f := range files {
+		if f.IsDir() || strings.HasPrefix(f.Name(), ".") {
+			continue
+		}
+		if ext := filepath.Ext(f.Name()); ext != ".yaml" && ext != ".yml" {
+			continue
+		}
+		caps = append(caps, RemoteCapability{
+			Name: f.Name(),
+			URL:  filepath.Join(r.Dir, f.Name()),
+			Type: "file",
+		})
+	}
+	return caps, nil
+}
+
+// Fetch reads the definition file
+func (r *DirectoryRepository) Fetch(_ context.Context, capability RemoteCapability) ([]byte, error) {
+	return ioutil.ReadFile(filepath.Clean(capability.URL))
+}
+
+// NewLocalRepository creates a repository from a local directory.
+// A directory with an "oci-layout" file is an OCI image layout, the reference could be set by the "ref" query of a file URL,
+// e.g. file:///mirror/caps?ref=v1.0.0; a directory with an index file is an index repository; otherwise it's a plain directory.
+func NewLocalRepository(address string) (Repository, error) {
+	dir, ref := address, ""
+	if strings.HasPrefix(address, "file://") {
+		u, err := url.Parse(address)
+		if err != nil {
+			return nil, err
+		}
+		dir, ref = filepath.FromSlash(u.Path), u.Query().Get("ref")
+	}
+	info, err := os.Stat(dir)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		if isIndexFile(dir) {
+			return &IndexRepository{IndexURL: dir}, nil
+		}
+		return nil, fmt.Errorf("%s is not a directory or an index file", dir)
+	}
+	if _, err := os.Stat(filepath.Join(dir, ociLayoutFile)); err == nil {
+		return &OCIRepository{store: &ociLayoutStore{dir: dir, ref: ref}}, nil
+	}
+	for _, name := range indexFileNames {
+		if _, err := os.Stat(filepath.Join(dir, name)); err == nil {
+			return &IndexRepository{IndexURL: filepath.Join(dir, name)}, nil
+		}
+	}
+	return &DirectoryRepository{Dir: dir}, nil
+}
+
+var indexFileNames = []string{"index.yaml", "index.yml", "index.json"}
+
+func isIndexFile(p string) bool {
+	base := path.Base(filepath.ToSlash(p))
+	for _, name := range indexFileNames {
+		if base == name {
+			return true
+		}
+	}
+	return false
+}
+
+func isHTTPURL(location string) bool {
+	return strings.HasPrefix(location, "http://") || strings.HasPrefix(location, "https://")
+}
+
+// capCenterClient fetches index and definition files, the timeout keeps an unresponsive cap center from blocking the sync
+var capCenterClient = &http.Client{Timeout: time.Minute}
+
+// readLocation reads a file from an HTTP URL or a local path
+func readLocation(ctx context.Context, location, token string) ([]byte, error) {
+	if !isHTTPURL(location) {
+		return ioutil.ReadFile(filepath.Clean(location))
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, location, nil)
+	if err != nil {
+		return nil, err
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	resp, err := capCenterClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	//nolint:errcheck
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("fetch %s err: %s", location, resp.Status)
+	}
+	return ioutil.ReadAll(resp.Body)
+}
+
+// verifyDigest verifies data against the digest in the format of sha256:<hex> or <hex>
+func verifyDigest(data []byte, digest string) error {
+	if digest == "" {
+		return nil
+	}
+	algorithm, expected := "sha256", digest
+	if i := strings.Index(digest, ":"); i >= 0 {
+		algorithm, expected = digest[:i], digest[i+1:]
+	}
+	if algorithm != "sha256" {
+		return fmt.Errorf("unsupported digest algorithm %s", algorithm)
+	}
+	sum := sha256.Sum256(data)
+	if actual := hex.EncodeToString(sum[:]); !strings.EqualFold(actual, expected) {
+		return fmt.Errorf("digest mismatch, expected %s but got sha256:%s", digest, actual)
+	}
+	return nil
+}
+
+func syncCapabilityFromRepository(ctx context.Context, repo Repository, centerName string) error {
+	dir, err := system.GetCapCenterDir()
+	if err != nil {
+		return err
+	}
+	repoDir := filepath.Join(dir, centerName)
+	_, _ = system.CreateIfNotExist(repoDir)
+	c := &common.Args{}
+	dm, err := c.GetDiscoveryMapper()
+	if err != nil {
+		return err
+	}
+	success, total, err := syncCapabilities(ctx, repo, dm, repoDir)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("successfully sync %d/%d from %s remote center\n", success, total, centerName)
+	return nil
+}
+
+// syncCapabilities fetches all capabilities from the repository into repoDir and records their versions and digests.
+// A versioned capability is also kept as versions/<name>/<version>.yaml, and <name>.yaml is always the highest version.
+// TODO(wonderflow): currently we only sync by create, we also need to delete which not exist remotely.
+func syncCapabilities(ctx context.Context, repo Repository, mapper discoverymapper.DiscoveryMapper, repoDir string) (int, int, error) {
+	caps, err := repo.List(ctx)
+	if err != nil {
+		return 0, 0, err
+	}
+	var success int
+	var synced CapabilityIndex
+	latest := map[string]string{}
+	for _, capability := range caps {
+		data, err := repo.Fetch(ctx, capability)
+		if err != nil {
+			return success, len(caps), err
+		}
+		if err := verifyDigest(data, capability.Digest); err != nil {
+			return success, len(caps), fmt.Errorf("verify %s err %w", capability.Name, err)
+		}
+		tmp, err := ParseAndSyncCapability(mapper, data)
+		if err != nil {
+			fmt.Printf("parse definition of %s err %v\n", capability.Name, err)
+			continue
+		}
+		// the version declared by the definition itself takes precedence over the one in the repository
+		version := tmp.Version
+		if version == "" {
+			version = capability.Version
+		}
+		if version != "" {
+			if _, err := semver.NewVersion(version); err != nil {
+				fmt.Printf("invalid version %s of %s err %v\n", version, capability.Name, err)
+				continue
+			}
+			versionDir := filepath.Join(repoDir, VersionsDir, tmp.Name)
+			_, _ = system.CreateIfNotExist(versionDir)
+			//nolint:gosec
+			if err = ioutil.WriteFile(filepath.Join(versionDir, version+".yaml"), data, 0644); err != nil {
+				fmt.Printf("write definition %s@%s to %s err %v\n", tmp.Name, version, repoDir, err)
+				continue
+			}
+		}
+		if prev, ok := latest[tmp.Name]; !ok || (prev == "" && version == "") || IsNewerVersion(version, prev) {
+			latest[tmp.Name] = version
+			//nolint:gosec
+			err = ioutil.WriteFile(filepath.Join(repoDir, tmp.Name+".yaml"), data, 0644)
+			if err != nil {
+				fmt.Printf("write definition %s to %s err %v\n", tmp.Name+".yaml", repoDir, err)
+				continue
+			}
+		}
+		sum := sha256.Sum256(data)
+		synced.Capabilities = append(synced.Capabilities, CapabilityIndexEntry{
+			Name:    tmp.Name,
+			Version: version,
+			URL:     capability.URL,
+			Digest:  "sha256:" + hex.EncodeToString(sum[:]),
+		})
+		success++
+	}
+	data, err := yaml.Marshal(synced)
+	if err != nil {
+		return success, len(caps), err
+	}
+	//nolint:gosec
+	return success, len(caps), ioutil.WriteFile(filepath.Join(repoDir, SyncedIndexFile), data, 0644)
+}
+
+// LoadSyncedIndex loads the versions and digests of capabilities synced from a cap center
+func LoadSyncedIndex(repoDir string) (*CapabilityIndex, error) {
+	data, err := ioutil.ReadFile(filepath.Clean(filepath.Join(repoDir, SyncedIndexFile)))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return &CapabilityIndex{}, nil
+		}
+		return nil, err
+	}
+	index := &CapabilityIndex{}
+	if err := yaml.Unmarshal(data, index); err != nil {
+		return nil, err
+	}
+	return index, nil
+}
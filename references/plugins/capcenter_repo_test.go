@@ -0,0 +1,302 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package plugins
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/oam-dev/kubevela/pkg/oam/mock"
+)
+
+func sha256Digest(data []byte) string {
+	sum := sha256.Sum256(data)
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+func TestIndexRepository(t *testing.T) {
+	def, err := ioutil.ReadFile("testdata/ingressDef.yaml")
+	assert.NoError(t, err)
+	index := fmt.Sprintf(`capabilities:
+- name: ingress.test
+  version: 1.0.0
+  url: defs/ingress.yaml
+  digest: %s
+`, sha256Digest(def))
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer my-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		switch r.URL.Path {
+		case "/caps/index.yaml":
+			_, _ = w.Write([]byte(index))
+		case "/caps/defs/ingress.yaml":
+			_, _ = w.Write(def)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	tp, _, err := Parse(server.URL + "/caps/index.yaml")
+	assert.NoError(t, err)
+	assert.Equal(t, TypeHTTPIndex, tp)
+
+	repo := &IndexRepository{IndexURL: server.URL + "/caps/index.yaml", Token: "my-token"}
+	caps, err := repo.List(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, []RemoteCapability{{
+		Name:    "ingress.yaml",
+		URL:     server.URL + "/caps/defs/ingress.yaml",
+		Type:    "file",
+		Version: "1.0.0",
+		Digest:  sha256Digest(def),
+	}}, caps)
+	data, err := repo.Fetch(context.Background(), caps[0])
+	assert.NoError(t, err)
+	assert.Equal(t, def, data)
+
+	_, err = (&IndexRepository{IndexURL: server.URL + "/caps/index.yaml"}).List(context.Background())
+	assert.Error(t, err)
+
+	// the token is only sent to the host of the index file
+	var authorization string
+	other := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorization = r.Header.Get("Authorization")
+		_, _ = w.Write(def)
+	}))
+	defer other.Close()
+	data, err = repo.Fetch(context.Background(), RemoteCapability{Name: "ingress.yaml", URL: other.URL + "/ingress.yaml"})
+	assert.NoError(t, err)
+	assert.Equal(t, def, data)
+	assert.Empty(t, authorization)
+}
+
+func TestRemoteIndexRejectsLocalFiles(t *testing.T) {
+	repo := &IndexRepository{IndexURL: "https://caps.example.com/index.yaml", Token: "my-token"}
+	for _, ref := range []string{"file:///etc/passwd", "/etc/passwd", "//evil.example.com/def.yaml"} {
+		_, err := repo.resolve(ref)
+		assert.Error(t, err, ref)
+	}
+	location, err := repo.resolve("https://mirror.example.com/def.yaml")
+	assert.NoError(t, err)
+	assert.Equal(t, "https://mirror.example.com/def.yaml", location)
+
+	_, err = repo.Fetch(context.Background(), RemoteCapability{Name: "passwd", URL: "/etc/passwd"})
+	assert.Error(t, err)
+}
+
+func TestLocalRepository(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cap-center")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	def, err := ioutil.ReadFile("testdata/ingressDef.yaml")
+	assert.NoError(t, err)
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, "ingress.yaml"), def, 0600))
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, "README.md"), []byte("readme"), 0600))
+
+	tp, _, err := Parse(dir)
+	assert.NoError(t, err)
+	assert.Equal(t, TypeLocal, tp)
+	tp, _, err = Parse("file://" + dir)
+	assert.NoError(t, err)
+	assert.Equal(t, TypeLocal, tp)
+
+	repo, err := NewLocalRepository(dir)
+	assert.NoError(t, err)
+	assert.Equal(t, &DirectoryRepository{Dir: dir}, repo)
+	caps, err := repo.List(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, []RemoteCapability{{Name: "ingress.yaml", URL: filepath.Join(dir, "ingress.yaml"), Type: "file"}}, caps)
+
+	// a local mirror with index file
+	index := "capabilities:\n- name: ingress.test\n  version: 1.0.0\n  url: ingress.yaml\n  digest: sha256:0000\n"
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, "index.yaml"), []byte(index), 0600))
+	repo, err = NewLocalRepository("file://" + dir)
+	assert.NoError(t, err)
+	caps, err = repo.List(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, filepath.Join(dir, "ingress.yaml"), caps[0].URL)
+	assert.Equal(t, "1.0.0", caps[0].Version)
+	data, err := repo.Fetch(context.Background(), caps[0])
+	assert.NoError(t, err)
+	assert.Error(t, verifyDigest(data, caps[0].Digest))
+	assert.NoError(t, verifyDigest(data, sha256Digest(def)))
+}
+
+// writeOCIBlob writes a blob into an OCI image layout and returns its descriptor
+func writeOCIBlob(t *testing.T, dir string, data []byte, annotations map[string]string) ociDescriptor {
+	digest := sha256Digest(data)
+	blobDir := filepath.Join(dir, "blobs", "sha256")
+	assert.NoError(t, os.MkdirAll(blobDir, 0750))
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(blobDir, strings.TrimPrefix(digest, "sha256:")), data, 0600))
+	return ociDescriptor{Digest: digest, Size: int64(len(data)), Annotations: annotations}
+}
+
+func newOCILayout(t *testing.T, def []byte) (string, []byte) {
+	dir, err := ioutil.TempDir("", "cap-center-oci")
+	assert.NoError(t, err)
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, ociLayoutFile), []byte(`{"imageLayoutVersion":"1.0.0"}`), 0600))
+	manifest, err := json.Marshal(ociManifest{
+		SchemaVersion: 2,
+		Config:        writeOCIBlob(t, dir, []byte("{}"), nil),
+		Layers: []ociDescriptor{
+			writeOCIBlob(t, dir, def, map[string]string{ociAnnotationTitle: "ingress.yaml"}),
+			writeOCIBlob(t, dir, []byte("untitled"), nil),
+		},
+		Annotations: map[string]string{ociAnnotationVersion: "1.2.0"},
+	})
+	assert.NoError(t, err)
+	desc := writeOCIBlob(t, dir, manifest, map[string]string{ociAnnotationRefName: "v1.2.0"})
+	desc.MediaType = ociManifestMediaType
+	index, err := json.Marshal(ociIndex{SchemaVersion: 2, Manifests: []ociDescriptor{desc}})
+	assert.NoError(t, err)
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, ociIndexFile), index, 0600))
+	return dir, manifest
+}
+
+func TestOCILayoutRepository(t *testing.T) {
+	def, err := ioutil.ReadFile("testdata/ingressDef.yaml")
+	assert.NoError(t, err)
+	dir, _ := newOCILayout(t, def)
+	defer os.RemoveAll(dir)
+
+	for _, address := range []string{dir, "file://" + dir + "?ref=v1.2.0"} {
+		repo, err := NewLocalRepository(address)
+		assert.NoError(t, err)
+		caps, err := repo.List(context.Background())
+		assert.NoError(t, err)
+		assert.Equal(t, []RemoteCapability{{
+			Name:    "ingress.yaml",
+			URL:     sha256Digest(def),
+			Type:    "file",
+			Version: "1.2.0",
+			Digest:  sha256Digest(def),
+		}}, caps)
+		data, err := repo.Fetch(context.Background(), caps[0])
+		assert.NoError(t, err)
+		assert.Equal(t, def, data)
+	}
+
+	repo, err := NewLocalRepository("file://" + dir + "?ref=v2.0.0")
+	assert.NoError(t, err)
+	_, err = repo.List(context.Background())
+	assert.Error(t, err)
+}
+
+func TestOCIRegistryRepository(t *testing.T) {
+	def, err := ioutil.ReadFile("testdata/ingressDef.yaml")
+	assert.NoError(t, err)
+	dir, manifest := newOCILayout(t, def)
+	defer os.RemoveAll(dir)
+
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/token" {
+			assert.Equal(t, "repository:caps:pull", r.URL.Query().Get("scope"))
+			_, _ = w.Write([]byte(`{"token":"anonymous"}`))
+			return
+		}
+		if r.Header.Get("Authorization") != "Bearer anonymous" {
+			w.Header().Set("WWW-Authenticate", fmt.Sprintf(`Bearer realm="%s/token",service="registry",scope="repository:caps:pull"`, server.URL))
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		switch {
+		case r.URL.Path == "/v2/caps/manifests/v1.2.0":
+			assert.Equal(t, ociManifestMediaType, r.Header.Get("Accept"))
+			_, _ = w.Write(manifest)
+		case strings.HasPrefix(r.URL.Path, "/v2/caps/blobs/sha256:"):
+			http.ServeFile(w, r, filepath.Join(dir, "blobs", "sha256", strings.TrimPrefix(r.URL.Path, "/v2/caps/blobs/sha256:")))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	address := "oci://" + strings.TrimPrefix(server.URL, "http://") + "/caps:v1.2.0"
+	tp, _, err := Parse(address)
+	assert.NoError(t, err)
+	assert.Equal(t, TypeOCI, tp)
+	repo, err := NewOCIRegistryRepository(address, "")
+	assert.NoError(t, err)
+	caps, err := repo.List(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(caps))
+	assert.Equal(t, "1.2.0", caps[0].Version)
+	data, err := repo.Fetch(context.Background(), caps[0])
+	assert.NoError(t, err)
+	assert.Equal(t, def, data)
+
+	_, err = NewOCIRegistryRepository("oci://registry.example.com", "")
+	assert.Error(t, err)
+}
+
+func TestSyncCapabilities(t *testing.T) {
+	src, err := ioutil.TempDir("", "cap-center-src")
+	assert.NoError(t, err)
+	defer os.RemoveAll(src)
+	dst, err := ioutil.TempDir("", "cap-center-dst")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dst)
+	def, err := ioutil.ReadFile("testdata/ingressDef.yaml")
+	assert.NoError(t, err)
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(src, "ingress.yaml"), def, 0600))
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(src, "invalid.yaml"), []byte("kind: Unknown"), 0600))
+	index := fmt.Sprintf("capabilities:\n- {name: ingress.test, version: 1.0.0, url: ingress.yaml, digest: %s}\n- {name: invalid, url: invalid.yaml}\n", sha256Digest(def))
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(src, "index.yaml"), []byte(index), 0600))
+
+	repo, err := NewLocalRepository(src)
+	assert.NoError(t, err)
+	success, total, err := syncCapabilities(context.Background(), repo, mock.NewMockDiscoveryMapper(), dst)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, success)
+	assert.Equal(t, 2, total)
+	synced, err := ioutil.ReadFile(filepath.Join(dst, "ingress.test.yaml"))
+	assert.NoError(t, err)
+	assert.Equal(t, def, synced)
+	syncedIndex, err := LoadSyncedIndex(dst)
+	assert.NoError(t, err)
+	assert.Equal(t, []CapabilityIndexEntry{{
+		Name:    "ingress.test",
+		Version: "1.0.0",
+		URL:     filepath.Join(src, "ingress.yaml"),
+		Digest:  sha256Digest(def),
+	}}, syncedIndex.Capabilities)
+
+	caps, err := LoadCapabilityFromSyncedCenter(mock.NewMockDiscoveryMapper(), dst)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(caps))
+
+	// the definition is tampered
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(src, "ingress.yaml"), append(def, '\n'), 0600))
+	_, _, err = syncCapabilities(context.Background(), repo, mock.NewMockDiscoveryMapper(), dst)
+	assert.Error(t, err)
+}
@@ -0,0 +1,152 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package plugins
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/Masterminds/semver/v3"
+
+	"github.com/oam-dev/kubevela/apis/types"
+	"github.com/oam-dev/kubevela/pkg/oam/discoverymapper"
+)
+
+// VersionsDir is the directory in a synced cap center which keeps every synced version of capabilities,
+// a version is stored as versions/<name>/<version>.yaml
+const VersionsDir = "versions"
+
+// ListCapabilityVersions lists the synced versions of a capability in ascending order
+func ListCapabilityVersions(repoDir, name string) ([]string, error) {
+	files, err := ioutil.ReadDir(filepath.Join(repoDir, VersionsDir, name))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var versions []*semver.Version
+	for _, f := range files {
+		if f.IsDir() || filepath.Ext(f.Name()) != ".yaml" {
+			continue
+		}
+		v, err := semver.NewVersion(strings.TrimSuffix(f.Name(), ".yaml"))
+		if err != nil {
+			continue
+		}
+		versions = append(versions, v)
+	}
+	sort.Sort(semver.Collection(versions))
+	var result []string
+	for _, v := range versions {
+		result = append(result, v.Original())
+	}
+	return result, nil
+}
+
+// FindCapabilityVersion finds the highest synced version of a capability matching the constraint and returns the
+// capability with the path of its definition file. An empty constraint matches any version. If the cap center has
+// no versioned capabilities, only an empty constraint could be satisfied by the capability synced last.
+func FindCapabilityVersion(mapper discoverymapper.DiscoveryMapper, repoDir, name, constraint string) (types.Capability, string, error) {
+	versions, err := ListCapabilityVersions(repoDir, name)
+	if err != nil {
+		return types.Capability{}, "", err
+	}
+	centerName := filepath.Base(repoDir)
+	defFile, version := filepath.Join(repoDir, name+".yaml"), ""
+	switch {
+	case len(versions) > 0:
+		if version, err = MatchVersion(versions, constraint); err != nil {
+			return types.Capability{}, "", fmt.Errorf("%s/%s: %w", centerName, name, err)
+		}
+		defFile = filepath.Join(repoDir, VersionsDir, name, version+".yaml")
+	case constraint != "":
+		return types.Capability{}, "", fmt.Errorf("no version of %s/%s is synced, it could not satisfy %q", centerName, name, constraint)
+	}
+	data, err := ioutil.ReadFile(filepath.Clean(defFile))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return types.Capability{}, "", fmt.Errorf("%s/%s not exist, try 'vela cap center sync %s' to sync from remote", centerName, name, centerName)
+		}
+		return types.Capability{}, "", err
+	}
+	tmp, err := ParseAndSyncCapability(mapper, data)
+	if err != nil {
+		return types.Capability{}, "", err
+	}
+	if version != "" {
+		tmp.Version = version
+	}
+	return tmp, defFile, nil
+}
+
+// MatchVersion returns the highest version matching the constraint, an empty constraint matches any version
+func MatchVersion(versions []string, constraint string) (string, error) {
+	var c *semver.Constraints
+	if constraint != "" {
+		var err error
+		if c, err = semver.NewConstraint(constraint); err != nil {
+			return "", fmt.Errorf("invalid version constraint %q: %w", constraint, err)
+		}
+	}
+	var best *semver.Version
+	for _, version := range versions {
+		v, err := semver.NewVersion(version)
+		if err != nil {
+			continue
+		}
+		if c != nil && !c.Check(v) {
+			continue
+		}
+		if best == nil || v.GreaterThan(best) {
+			best = v
+		}
+	}
+	if best == nil {
+		if constraint == "" {
+			return "", fmt.Errorf("no valid version in %v", versions)
+		}
+		return "", fmt.Errorf("no version matches %q, available versions are %v", constraint, versions)
+	}
+	return best.Original(), nil
+}
+
+// SatisfiesVersion checks whether the version satisfies the constraint, an empty constraint is satisfied by any version
+func SatisfiesVersion(version, constraint string) bool {
+	if constraint == "" {
+		return true
+	}
+	_, err := MatchVersion([]string{version}, constraint)
+	return err == nil
+}
+
+// IsNewerVersion checks whether version a is newer than version b, any valid version is newer than an empty one
+func IsNewerVersion(a, b string) bool {
+	va, err := semver.NewVersion(a)
+	if err != nil {
+		return false
+	}
+	vb, err := semver.NewVersion(b)
+	if err != nil {
+		return true
+	}
+	return va.GreaterThan(vb)
+}
@@ -0,0 +1,133 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package plugins
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/oam-dev/kubevela/apis/types"
+	"github.com/oam-dev/kubevela/pkg/oam/mock"
+)
+
+func versionedTrait(name, version, dependencies string) []byte {
+	return []byte(fmt.Sprintf(`apiVersion: core.oam.dev/v1beta1
+kind: TraitDefinition
+metadata:
+  name: %s
+  annotations:
+    definition.oam.dev/version: "%s"
+    definition.oam.dev/dependencies: |
+%s
+spec:
+  schematic:
+    cue:
+      template: |
+        parameter: replicas: *1 | int
+`, name, version, dependencies))
+}
+
+func TestSyncCapabilityVersions(t *testing.T) {
+	src, err := ioutil.TempDir("", "cap-center-src")
+	assert.NoError(t, err)
+	defer os.RemoveAll(src)
+	dst, err := ioutil.TempDir("", "cap-center-dst")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dst)
+	deps := "      - {type: definition, name: base, version: \">=1.0.0\"}\n      - {type: crd, name: foos.example.com}"
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(src, "scaler-1.1.0.yaml"), versionedTrait("scaler", "1.1.0", deps), 0600))
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(src, "scaler-1.0.0.yaml"), versionedTrait("scaler", "1.0.0", ""), 0600))
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(src, "scaler-2.0.0-beta.1.yaml"), versionedTrait("scaler", "2.0.0-beta.1", ""), 0600))
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(src, "broken.yaml"), versionedTrait("broken", "latest", ""), 0600))
+
+	success, total, err := syncCapabilities(context.Background(), &DirectoryRepository{Dir: src}, mock.NewMockDiscoveryMapper(), dst)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, success)
+	assert.Equal(t, 4, total)
+
+	versions, err := ListCapabilityVersions(dst, "scaler")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"1.0.0", "1.1.0", "2.0.0-beta.1"}, versions)
+
+	caps, err := LoadCapabilityFromSyncedCenter(mock.NewMockDiscoveryMapper(), dst)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(caps))
+	assert.Equal(t, "2.0.0-beta.1", caps[0].Version)
+
+	tp, defFile, err := FindCapabilityVersion(mock.NewMockDiscoveryMapper(), dst, "scaler", "^1.0.0")
+	assert.NoError(t, err)
+	assert.Equal(t, "1.1.0", tp.Version)
+	assert.Equal(t, filepath.Join(dst, VersionsDir, "scaler", "1.1.0.yaml"), defFile)
+	assert.Equal(t, []types.Dependency{
+		{Type: types.DependencyDefinition, Name: "base", Version: ">=1.0.0"},
+		{Type: types.DependencyCRD, Name: "foos.example.com"},
+	}, tp.Dependencies)
+
+	tp, _, err = FindCapabilityVersion(mock.NewMockDiscoveryMapper(), dst, "scaler", "")
+	assert.NoError(t, err)
+	assert.Equal(t, "2.0.0-beta.1", tp.Version)
+	_, _, err = FindCapabilityVersion(mock.NewMockDiscoveryMapper(), dst, "scaler", "3.0.0")
+	assert.Error(t, err)
+	_, _, err = FindCapabilityVersion(mock.NewMockDiscoveryMapper(), dst, "unknown", "")
+	assert.Error(t, err)
+}
+
+func TestMatchVersion(t *testing.T) {
+	versions := []string{"1.0.0", "1.2.0", "v1.10.0", "2.0.0"}
+	for constraint, expected := range map[string]string{
+		"":               "2.0.0",
+		"1.2.0":          "1.2.0",
+		"~1.2":           "1.2.0",
+		">=1.0.0 <2.0.0": "v1.10.0",
+	} {
+		version, err := MatchVersion(versions, constraint)
+		assert.NoError(t, err, constraint)
+		assert.Equal(t, expected, version, constraint)
+	}
+	_, err := MatchVersion(versions, ">2.0.0")
+	assert.Error(t, err)
+	_, err = MatchVersion(versions, "not a constraint")
+	assert.Error(t, err)
+
+	assert.True(t, SatisfiesVersion("1.2.0", ""))
+	assert.False(t, SatisfiesVersion("1.2.0", "^2.0.0"))
+	assert.True(t, IsNewerVersion("1.10.0", "1.9.0"))
+	assert.True(t, IsNewerVersion("1.0.0", ""))
+	assert.False(t, IsNewerVersion("", "1.0.0"))
+}
+
+func TestGetDependencies(t *testing.T) {
+	deps, err := GetDependencies(map[string]string{types.AnnDependencies: `
+- type: helm
+  helm: {repo: stable, url: https://charts.example.com, name: foo, version: 1.0.0}
+`})
+	assert.NoError(t, err)
+	assert.Equal(t, "foo", deps[0].Helm.Name)
+	deps, err = GetDependencies(nil)
+	assert.NoError(t, err)
+	assert.Nil(t, deps)
+	for _, invalid := range []string{"- {type: helm}", "- {type: definition}", "- {type: unknown, name: foo}", "foo: bar"} {
+		_, err = GetDependencies(map[string]string{types.AnnDependencies: invalid})
+		assert.Error(t, err, invalid)
+	}
+}
@@ -22,6 +22,7 @@ import (
 	"os"
 	"strings"
 
+	"github.com/ghodss/yaml"
 	"github.com/pkg/errors"
 	kerrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/labels"
@@ -211,9 +212,40 @@ func HandleDefinition(name, crdName string, annotation map[string]string, extens
 	}
 	tmp.CrdName = crdName
 	tmp.Description = GetDescription(annotation)
+	tmp.Version = annotation[types.AnnVersion]
+	if tmp.Dependencies, err = GetDependencies(annotation); err != nil {
+		return types.Capability{}, errors.WithMessagef(err, "definition %s", name)
+	}
 	return tmp, nil
 }
 
+// GetDependencies get dependencies of a capability package from annotation
+func GetDependencies(annotation map[string]string) ([]types.Dependency, error) {
+	raw, ok := annotation[types.AnnDependencies]
+	if !ok || strings.TrimSpace(raw) == "" {
+		return nil, nil
+	}
+	var deps []types.Dependency
+	if err := yaml.Unmarshal([]byte(raw), &deps); err != nil {
+		return nil, errors.Wrapf(err, "invalid annotation %s", types.AnnDependencies)
+	}
+	for _, dep := range deps {
+		switch dep.Type {
+		case types.DependencyDefinition, types.DependencyCRD:
+			if dep.Name == "" {
+				return nil, errors.Errorf("name of %s dependency is not set", dep.Type)
+			}
+		case types.DependencyHelm:
+			if dep.Helm == nil || dep.Helm.Name == "" {
+				return nil, errors.Errorf("chart of helm dependency is not set")
+			}
+		default:
+			return nil, errors.Errorf("unknown dependency type %q", dep.Type)
+		}
+	}
+	return deps, nil
+}
+
 // GetDescription get description from annotation
 func GetDescription(annotation map[string]string) string {
 	if annotation == nil {
@@ -131,7 +131,7 @@ func loadInstalledCapability(dir string, name string) ([]types.Capability, error
 		if f.IsDir() {
 			continue
 		}
-		if strings.HasSuffix(f.Name(), ".cue") {
+		if strings.HasSuffix(f.Name(), ".cue") || f.Name() == SyncedIndexFile {
 			continue
 		}
 		data, err := ioutil.ReadFile(filepath.Clean(filepath.Join(dir, f.Name())))
@@ -245,7 +245,7 @@ func LoadCapabilityFromSyncedCenter(mapper discoverymapper.DiscoveryMapper, dir
 		if f.IsDir() {
 			continue
 		}
-		if strings.HasSuffix(f.Name(), ".cue") {
+		if strings.HasSuffix(f.Name(), ".cue") || f.Name() == SyncedIndexFile {
 			continue
 		}
 		data, err := ioutil.ReadFile(filepath.Clean(filepath.Join(dir, f.Name())))
@@ -258,6 +258,12 @@ func LoadCapabilityFromSyncedCenter(mapper discoverymapper.DiscoveryMapper, dir
 			fmt.Printf("get definition of %s err %v\n", f.Name(), err)
 			continue
 		}
+		if tmp.Version == "" {
+			// the synced file is always the latest version of the capability
+			if versions, _ := ListCapabilityVersions(dir, tmp.Name); len(versions) > 0 {
+				tmp.Version = versions[len(versions)-1]
+			}
+		}
 		tmps = append(tmps, tmp)
 	}
 	return tmps, nil
@@ -463,11 +463,12 @@ var _ = Describe("Test application cross namespace resource", func() {
 			if len(trait.OwnerReferences) != 1 || trait.OwnerReferences[0].UID != resourceTracker.UID {
 				return fmt.Errorf("trait owner reference missmatch")
 			}
-			if len(resourceTracker.Status.TrackedResources) != 1 {
+			// the same namespace workload is also tracked
+			if len(resourceTracker.Status.TrackedResources) != 2 {
 				return fmt.Errorf("resourceTracker status recode trackedResource length missmatch")
 			}
-			if resourceTracker.Status.TrackedResources[0].Name != trait.Name {
-				return fmt.Errorf("resourceTracker status recode trackedResource name mismatch recorded %s, actually %s", resourceTracker.Status.TrackedResources[0].Name, trait.Name)
+			if resourceTracker.Status.TrackedResources[1].Name != trait.Name {
+				return fmt.Errorf("resourceTracker status recode trackedResource name mismatch recorded %s, actually %s", resourceTracker.Status.TrackedResources[1].Name, trait.Name)
 			}
 			return nil
 		}, time.Second*60, time.Microsecond*300).Should(BeNil())
@@ -543,11 +544,12 @@ var _ = Describe("Test application cross namespace resource", func() {
 			if len(trait.OwnerReferences) != 1 || trait.OwnerReferences[0].UID != resourceTracker.UID {
 				return fmt.Errorf("trait owner reference missmatch")
 			}
-			if len(resourceTracker.Status.TrackedResources) != 1 {
+			// the same namespace workload is also tracked
+			if len(resourceTracker.Status.TrackedResources) != 2 {
 				return fmt.Errorf("resourceTracker status recode trackedResource length missmatch")
 			}
-			if resourceTracker.Status.TrackedResources[0].Name != trait.Name {
-				return fmt.Errorf("resourceTracker status recode trackedResource name mismatch recorded %s, actually %s", resourceTracker.Status.TrackedResources[0].Name, trait.Name)
+			if resourceTracker.Status.TrackedResources[1].Name != trait.Name {
+				return fmt.Errorf("resourceTracker status recode trackedResource name mismatch recorded %s, actually %s", resourceTracker.Status.TrackedResources[1].Name, trait.Name)
 			}
 			return nil
 		}, time.Second*60, time.Microsecond*300).Should(BeNil())
@@ -569,8 +571,11 @@ var _ = Describe("Test application cross namespace resource", func() {
 				return fmt.Errorf("application status not running")
 			}
 			err := k8sClient.Get(ctx, generateResourceTrackerKey(app.Namespace, app.Name), resourceTracker)
-			if err == nil {
-				return fmt.Errorf("resourceTracker still exist")
+			if err != nil {
+				return fmt.Errorf("error to get resourceTracker %v", err)
+			}
+			if len(resourceTracker.Status.TrackedResources) != 1 {
+				return fmt.Errorf("removed trait still recorded in resourceTracker")
 			}
 			mts := new(v1alpha2.ManualScalerTraitList)
 			opts := []client.ListOption{
@@ -583,8 +588,8 @@ var _ = Describe("Test application cross namespace resource", func() {
 			if err != nil || len(mts.Items) != 0 {
 				return fmt.Errorf("cross ns trait still exist")
 			}
-			if app.Status.ResourceTracker != nil {
-				return fmt.Errorf("application status resourceTracker field still exist %s", string(util.JSONMarshal(app.Status.ResourceTracker)))
+			if app.Status.ResourceTracker == nil || app.Status.ResourceTracker.UID != resourceTracker.UID {
+				return fmt.Errorf("app status resourceTracker error")
 			}
 			return nil
 		}, time.Second*60, time.Microsecond*300).Should(BeNil())
@@ -679,11 +684,12 @@ var _ = Describe("Test application cross namespace resource", func() {
 			if app.Status.ResourceTracker == nil || app.Status.ResourceTracker.UID != resourceTracker.UID {
 				return fmt.Errorf("app status resourceTracker error")
 			}
-			if len(resourceTracker.Status.TrackedResources) != 1 {
+			// the same namespace workload is also tracked
+			if len(resourceTracker.Status.TrackedResources) != 2 {
 				return fmt.Errorf("resourceTracker status recode trackedResource length missmatch")
 			}
-			if resourceTracker.Status.TrackedResources[0].Name != crossDeplpoy.Name {
-				return fmt.Errorf("resourceTracker status recode trackedResource name mismatch recorded %s, actually %s", resourceTracker.Status.TrackedResources[0].Name, crossDeplpoy.Name)
+			if resourceTracker.Status.TrackedResources[1].Name != crossDeplpoy.Name {
+				return fmt.Errorf("resourceTracker status recode trackedResource name mismatch recorded %s, actually %s", resourceTracker.Status.TrackedResources[1].Name, crossDeplpoy.Name)
 			}
 			return nil
 		}, time.Second*60, time.Microsecond*300).Should(BeNil())
@@ -703,8 +709,11 @@ var _ = Describe("Test application cross namespace resource", func() {
 				return fmt.Errorf("application status not running")
 			}
 			err := k8sClient.Get(ctx, generateResourceTrackerKey(app.Namespace, app.Name), resourceTracker)
-			if err == nil {
-				return fmt.Errorf("resourceTracker still exist")
+			if err != nil {
+				return fmt.Errorf("error to get resourceTracker %v", err)
+			}
+			if len(resourceTracker.Status.TrackedResources) != 1 {
+				return fmt.Errorf("removed workload still recorded in resourceTracker")
 			}
 			sameOpts := []client.ListOption{
 				client.InNamespace(namespace),
@@ -731,7 +740,7 @@ var _ = Describe("Test application cross namespace resource", func() {
 			if err != nil || len(cross.Items) != 0 {
 				return fmt.Errorf("error : cross namespace workload still exist")
 			}
-			if app.Status.ResourceTracker != nil {
+			if app.Status.ResourceTracker == nil || app.Status.ResourceTracker.UID != resourceTracker.UID {
 				return fmt.Errorf("error app status resourceTracker")
 			}
 			return nil
@@ -1308,7 +1317,7 @@ var _ = Describe("Test application cross namespace resource", func() {
 			return nil
 		}, time.Second*60, time.Microsecond*300).Should(BeNil())
 
-		By("update application modify workload namespace  will remove resourceTracker and related old workload will be removed")
+		By("update application modify workload namespace, related old workload will be removed")
 		time.Sleep(3 * time.Second) // wait informer cache to be synced
 		Eventually(func() error {
 			app = new(v1beta1.Application)
@@ -1325,12 +1334,12 @@ var _ = Describe("Test application cross namespace resource", func() {
 		}, time.Second*30, time.Microsecond).Should(BeNil())
 		Eventually(func() error {
 			err := k8sClient.Get(ctx, generateResourceTrackerKey(app.Namespace, app.Name), resourceTracker)
-			if err == nil {
-				return fmt.Errorf("resourceTracker still exist")
-			}
-			if !apierrors.IsNotFound(err) {
+			if err != nil {
 				return err
 			}
+			if len(resourceTracker.Status.TrackedResources) != 1 || resourceTracker.Status.TrackedResources[0].Namespace != namespace {
+				return fmt.Errorf("old workload still recorded in resourceTracker")
+			}
 			err = k8sClient.Get(ctx, types.NamespacedName{Namespace: crossNamespace, Name: workload.GetName()}, &workload)
 			if err == nil {
 				return fmt.Errorf("wrokload still exist")